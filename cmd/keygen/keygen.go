@@ -50,7 +50,12 @@ func main() {
 		return
 	}
 
-	partyIDs := helpers.GenerateSet(party.ID(n))
+	partyIDs, err := party.NewIDSliceRange(1, party.Size(n))
+	if err != nil {
+		fmt.Println(err)
+		usage()
+		return
+	}
 
 	// structure holding parties' state and output
 	states := map[party.ID]*state.State{}
@@ -1,8 +1,16 @@
 module github.com/taurusgroup/frost-ed25519
 
-go 1.14
+go 1.18
 
 require (
 	filippo.io/edwards25519 v1.0.0-rc.1
 	github.com/stretchr/testify v1.6.1
+	golang.org/x/crypto v0.24.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
 )
@@ -0,0 +1,141 @@
+// Package auth authenticates the messages of a protocol execution with long-term Ed25519 identity keys.
+//
+// FROST assumes that the parties communicate over authenticated channels.
+// When the transport does not provide them, State wraps a state.State so that every outgoing message is signed
+// by the sender's identity key, and every incoming message is verified against the identity key of the party
+// it claims to come from.
+//
+// A Certificate assembles the endorsements of the outcome of a key generation by all its parties,
+// signed with the same identity keys, so that outsiders can check that the ceremony was completed.
+package auth
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// ErrInvalidSignature is wrapped by the error returned when an envelope's signature does not verify
+// with the identity key of the party the message claims to come from.
+var ErrInvalidSignature = errors.New("auth: invalid envelope signature")
+
+var signatureDomain = []byte("FROST-Ed25519 envelope")
+
+// State wraps a state.State, and exchanges messages in signed envelopes.
+//
+// An envelope is the signature followed by the message encoded with the state's codec:
+//
+//	Envelope  = Signature ∥ Payload
+//	Signature = Ed25519.Sign(sk, "FROST-Ed25519 envelope" ∥ SessionID ∥ Type ∥ Payload)
+//
+// Since the session ID is not taken from the payload but from the receiving state, envelopes of another
+// execution are rejected, even if they were signed by a party of this one.
+// The session ID should therefore be set with state.WithSessionID.
+type State struct {
+	*state.State
+
+	privateKey ed25519.PrivateKey
+	keys       map[party.ID]ed25519.PublicKey
+}
+
+// Wrap returns a State which signs the messages generated by s with privateKey,
+// and verifies incoming messages using keys, which maps the ID of every party to its identity key.
+//
+// The embedded state.State can still be used to follow the progress of the protocol,
+// but messages should only be exchanged with HandleEnvelope and ProcessAll.
+func Wrap(s *state.State, privateKey ed25519.PrivateKey, keys map[party.ID]ed25519.PublicKey) (*State, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("auth.Wrap: invalid private key")
+	}
+	for _, id := range s.PartyIDs() {
+		if len(keys[id]) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("auth.Wrap: missing identity key for party %d", id)
+		}
+	}
+	if !keys[s.SelfID()].Equal(privateKey.Public()) {
+		return nil, errors.New("auth.Wrap: private key does not match our identity key")
+	}
+
+	copied := make(map[party.ID]ed25519.PublicKey, len(keys))
+	for id, key := range keys {
+		copied[id] = key
+	}
+	return &State{
+		State:      s,
+		privateKey: privateKey,
+		keys:       copied,
+	}, nil
+}
+
+// signedData returns the data signed for payload, which encodes a message of type msgType.
+func (s *State) signedData(msgType messages.MessageType, payload []byte) []byte {
+	sessionID := s.SessionID()
+	data := make([]byte, 0, len(signatureDomain)+len(sessionID)+1+len(payload))
+	data = append(data, signatureDomain...)
+	data = append(data, sessionID[:]...)
+	data = append(data, byte(msgType))
+	return append(data, payload...)
+}
+
+// Seal encodes msg with the codec of the state, and returns it in an envelope signed with our identity key.
+func (s *State) Seal(msg *messages.Message) ([]byte, error) {
+	if msg.From != s.SelfID() {
+		return nil, fmt.Errorf("auth.Seal: message from party %d cannot be signed by party %d", msg.From, s.SelfID())
+	}
+	payload, err := s.Codec().Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("auth.Seal: %w", err)
+	}
+	signature := ed25519.Sign(s.privateKey, s.signedData(msg.Type, payload))
+	return append(signature, payload...), nil
+}
+
+// Open verifies the envelope, and returns the message it contains.
+// The signature must have been produced by the party given as the sender of the message.
+func (s *State) Open(envelope []byte) (*messages.Message, error) {
+	if len(envelope) < ed25519.SignatureSize {
+		return nil, fmt.Errorf("auth.Open: envelope is too short: %w", messages.ErrInvalidMessage)
+	}
+	signature, payload := envelope[:ed25519.SignatureSize], envelope[ed25519.SignatureSize:]
+
+	var msg messages.Message
+	if err := s.Codec().Unmarshal(payload, &msg); err != nil {
+		return nil, fmt.Errorf("auth.Open: %w", err)
+	}
+	key, ok := s.keys[msg.From]
+	if !ok {
+		return nil, fmt.Errorf("auth.Open: %w", state.ErrUnexpectedSender)
+	}
+	if !ed25519.Verify(key, s.signedData(msg.Type, payload), signature) {
+		return nil, fmt.Errorf("auth.Open: message from party %d: %w", msg.From, ErrInvalidSignature)
+	}
+	return &msg, nil
+}
+
+// HandleEnvelope verifies the envelope, and gives the message it contains to state.State.HandleMessage.
+func (s *State) HandleEnvelope(envelope []byte) error {
+	msg, err := s.Open(envelope)
+	if err != nil {
+		return err
+	}
+	return s.HandleMessage(msg)
+}
+
+// ProcessAll calls state.State.ProcessAll, and returns the generated messages in signed envelopes,
+// together with the messages themselves so that they can be routed.
+func (s *State) ProcessAll() ([][]byte, []*messages.Message, error) {
+	msgs := s.State.ProcessAll()
+	envelopes := make([][]byte, 0, len(msgs))
+	for _, msg := range msgs {
+		envelope, err := s.Seal(msg)
+		if err != nil {
+			return nil, nil, err
+		}
+		envelopes = append(envelopes, envelope)
+	}
+	return envelopes, msgs, nil
+}
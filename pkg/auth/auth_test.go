@@ -0,0 +1,224 @@
+package auth_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/auth"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/sign"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+var message = []byte("Hello Everybody")
+
+// identityKeys generates a long-term identity key pair for each party.
+func identityKeys(t *testing.T, partyIDs party.IDSlice) (map[party.ID]ed25519.PublicKey, map[party.ID]ed25519.PrivateKey) {
+	public := make(map[party.ID]ed25519.PublicKey, len(partyIDs))
+	private := make(map[party.ID]ed25519.PrivateKey, len(partyIDs))
+	for _, id := range partyIDs {
+		pk, sk, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		public[id], private[id] = pk, sk
+	}
+	return public, private
+}
+
+// setupSign returns authenticated signing states for all parties, using the given session nonce.
+func setupSign(t *testing.T, partyIDs party.IDSlice, nonce []byte, public map[party.ID]ed25519.PublicKey, private map[party.ID]ed25519.PrivateKey) (map[party.ID]*auth.State, map[party.ID]*sign.Output) {
+	_, secrets := helpers.GenerateSecrets(partyIDs, partyIDs.N()-1)
+	shares := helpers.GeneratePublic(partyIDs.N()-1, secrets)
+	sessionID := messages.DeriveSessionID(partyIDs, nonce)
+
+	states := make(map[party.ID]*auth.State, len(partyIDs))
+	outputs := make(map[party.ID]*sign.Output, len(partyIDs))
+	for _, id := range partyIDs {
+		s, output, err := frost.NewSignState(partyIDs, secrets[id], shares, message, 0, state.WithSessionID(sessionID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if states[id], err = auth.Wrap(s, private[id], public); err != nil {
+			t.Fatal(err)
+		}
+		outputs[id] = output
+	}
+	return states, outputs
+}
+
+// processAll calls ProcessAll on every state, and returns the resulting envelopes.
+func processAll(t *testing.T, states map[party.ID]*auth.State) [][]byte {
+	var envelopes [][]byte
+	for _, s := range states {
+		out, _, err := s.ProcessAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		envelopes = append(envelopes, out...)
+	}
+	return envelopes
+}
+
+// deliver gives every envelope to all parties other than its sender.
+func deliver(t *testing.T, states map[party.ID]*auth.State, envelopes [][]byte) {
+	for _, envelope := range envelopes {
+		for id, s := range states {
+			msg, err := s.Open(envelope)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg.From == id {
+				continue
+			}
+			if err = s.HandleEnvelope(envelope); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	partyIDs := helpers.GenerateSet(3)
+	public, private := identityKeys(t, partyIDs)
+	states, outputs := setupSign(t, partyIDs, []byte("nonce"), public, private)
+
+	for round := 0; round < 3; round++ {
+		deliver(t, states, processAll(t, states))
+	}
+	for id, s := range states {
+		if err := s.WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+		if outputs[id].Signature == nil {
+			t.Fatal("missing signature")
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	partyIDs := helpers.GenerateSet(3)
+	public, private := identityKeys(t, partyIDs)
+	_, secrets := helpers.GenerateSecrets(partyIDs, 2)
+	shares := helpers.GeneratePublic(2, secrets)
+	id := partyIDs[0]
+	s, _, err := frost.NewSignState(partyIDs, secrets[id], shares, message, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = auth.Wrap(s, private[partyIDs[1]], public); err == nil {
+		t.Error("Wrap should fail when the private key belongs to another party")
+	}
+	missing := map[party.ID]ed25519.PublicKey{id: public[id]}
+	if _, err = auth.Wrap(s, private[id], missing); err == nil {
+		t.Error("Wrap should fail when identity keys are missing")
+	}
+	if _, err = auth.Wrap(s, private[id], public); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOpen_ForgedFrom(t *testing.T) {
+	partyIDs := helpers.GenerateSet(3)
+	public, private := identityKeys(t, partyIDs)
+	states, _ := setupSign(t, partyIDs, []byte("nonce"), public, private)
+	attacker, victim, receiver := partyIDs[0], partyIDs[1], partyIDs[2]
+
+	_, msgs, err := states[victim].ProcessAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The attacker signs the victim's message with its own identity key, by registering it as the victim's key
+	forgedKeys := make(map[party.ID]ed25519.PublicKey, len(public))
+	for id, key := range public {
+		forgedKeys[id] = key
+	}
+	forgedKeys[victim] = public[attacker]
+	forger, err := auth.Wrap(states[victim].State, private[attacker], forgedKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, err := forger.Seal(msgs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = states[receiver].HandleEnvelope(forged); !errors.Is(err, auth.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+	if _, err = states[attacker].Seal(msgs[0]); err == nil {
+		t.Error("Seal should refuse to sign a message from another party")
+	}
+}
+
+func TestOpen_SwappedPayloads(t *testing.T) {
+	partyIDs := helpers.GenerateSet(3)
+	public, private := identityKeys(t, partyIDs)
+	states, _ := setupSign(t, partyIDs, []byte("nonce"), public, private)
+	receiver := partyIDs[2]
+
+	envelope1, _, err := states[partyIDs[0]].ProcessAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope2, _, err := states[partyIDs[1]].ProcessAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Signature of the first envelope with the payload of the second
+	swapped := append(append([]byte{}, envelope1[0][:ed25519.SignatureSize]...), envelope2[0][ed25519.SignatureSize:]...)
+	if err = states[receiver].HandleEnvelope(swapped); !errors.Is(err, auth.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	// A single flipped bit in the signature
+	tampered := append([]byte{}, envelope1[0]...)
+	tampered[0] ^= 1
+	if err = states[receiver].HandleEnvelope(tampered); !errors.Is(err, auth.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	if err = states[receiver].HandleEnvelope(envelope1[0][:ed25519.SignatureSize-1]); !errors.Is(err, messages.ErrInvalidMessage) {
+		t.Errorf("expected ErrInvalidMessage, got %v", err)
+	}
+
+	// The genuine envelopes are still accepted
+	if err = states[receiver].HandleEnvelope(envelope1[0]); err != nil {
+		t.Error(err)
+	}
+	if err = states[receiver].HandleEnvelope(envelope2[0]); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOpen_CrossSessionReplay(t *testing.T) {
+	partyIDs := helpers.GenerateSet(3)
+	public, private := identityKeys(t, partyIDs)
+	first, _ := setupSign(t, partyIDs, []byte("first"), public, private)
+	second, _ := setupSign(t, partyIDs, []byte("second"), public, private)
+
+	for _, envelope := range processAll(t, first) {
+		for _, s := range second {
+			if err := s.HandleEnvelope(envelope); !errors.Is(err, auth.ErrInvalidSignature) {
+				t.Errorf("expected ErrInvalidSignature, got %v", err)
+			}
+		}
+	}
+
+	// The second session is unaffected by the replayed envelopes
+	for round := 0; round < 3; round++ {
+		deliver(t, second, processAll(t, second))
+	}
+	for _, s := range second {
+		if err := s.WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+)
+
+// ErrInvalidCertificate is wrapped by the error returned when a Certificate is not endorsed by all its parties.
+var ErrInvalidCertificate = errors.New("auth: invalid certificate")
+
+var certificateDomain = []byte("FROST-Ed25519 certificate")
+
+// certificateVersion is the first byte of the binary encoding of Statement and Certificate.
+const certificateVersion = 1
+
+// Statement is the outcome of a key generation which its parties endorse with their identity key.
+type Statement struct {
+	// SessionID is the session ID of the key generation.
+	SessionID messages.SessionID
+
+	// PartyIDs are the parties holding a share of the key, and Threshold its threshold.
+	PartyIDs  party.IDSlice
+	Threshold party.Size
+
+	// GroupKey is the resulting group key.
+	GroupKey ed25519.PublicKey
+}
+
+// NewStatement returns the Statement for the output public of a key generation, whose session ID was sessionID.
+func NewStatement(public *eddsa.Public, sessionID messages.SessionID) *Statement {
+	return &Statement{
+		SessionID: sessionID,
+		PartyIDs:  party.NewIDSlice(public.PartyIDs),
+		Threshold: public.Threshold,
+		GroupKey:  public.GroupKeyEd25519(),
+	}
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The encoding is
+//
+//	version ∥ sessionID ∥ threshold ∥ n ∥ id₁ ∥ ... ∥ idₙ ∥ groupKey
+//
+// where the threshold, n and the IDs are 4 bytes big endian, the IDs are sorted,
+// and the group key is the 32 byte Ed25519 encoding. It is the canonical statement signed by the parties.
+func (s *Statement) MarshalBinary() ([]byte, error) {
+	if len(s.GroupKey) != ed25519.PublicKeySize {
+		return nil, errors.New("auth.Statement: invalid group key")
+	}
+	ids, err := s.PartyIDs.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("auth.Statement: %w", err)
+	}
+	data := make([]byte, 0, s.size())
+	data = append(data, certificateVersion)
+	data = append(data, s.SessionID[:]...)
+	data = append(data, s.Threshold.Bytes()...)
+	data = append(data, ids...)
+	return append(data, s.GroupKey...), nil
+}
+
+// size returns the length of the binary encoding of s.
+func (s *Statement) size() int {
+	return 1 + messages.SessionIDSize + (len(s.PartyIDs)+2)*party.IDByteSize + ed25519.PublicKeySize
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (s *Statement) UnmarshalBinary(data []byte) error {
+	rest, err := s.unmarshal(data)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return errors.New("auth.Statement: trailing data")
+	}
+	return nil
+}
+
+// unmarshal decodes a Statement at the beginning of data, and returns the remaining bytes.
+func (s *Statement) unmarshal(data []byte) ([]byte, error) {
+	const headerSize = 1 + messages.SessionIDSize + 2*party.IDByteSize
+	if len(data) < headerSize || data[0] != certificateVersion {
+		return nil, errors.New("auth.Statement: invalid encoding")
+	}
+	var out Statement
+	copy(out.SessionID[:], data[1:])
+	data = data[1+messages.SessionIDSize:]
+	out.Threshold = party.Size(binary.BigEndian.Uint32(data))
+	n := int(binary.BigEndian.Uint32(data[party.IDByteSize:]))
+	if n > (len(data)-2*party.IDByteSize-ed25519.PublicKeySize)/party.IDByteSize {
+		return nil, errors.New("auth.Statement: data is too short")
+	}
+	idsSize := (n + 1) * party.IDByteSize
+	if err := out.PartyIDs.UnmarshalBinary(data[party.IDByteSize : party.IDByteSize+idsSize]); err != nil {
+		return nil, fmt.Errorf("auth.Statement: %w", err)
+	}
+	data = data[party.IDByteSize+idsSize:]
+	out.GroupKey = append(ed25519.PublicKey(nil), data[:ed25519.PublicKeySize]...)
+	if err := out.check(); err != nil {
+		return nil, err
+	}
+	*s = out
+	return data[ed25519.PublicKeySize:], nil
+}
+
+// check returns an error if s cannot be the outcome of a key generation.
+func (s *Statement) check() error {
+	if s.Threshold == 0 || s.Threshold >= s.PartyIDs.N() {
+		return fmt.Errorf("auth.Statement: threshold %d is invalid for %d parties", s.Threshold, s.PartyIDs.N())
+	}
+	if _, err := eddsa.NewPublicKeyFromEd25519(s.GroupKey); err != nil {
+		return fmt.Errorf("auth.Statement: %w", err)
+	}
+	return nil
+}
+
+// signedData returns the data signed by the parties endorsing s.
+func (s *Statement) signedData() ([]byte, error) {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, certificateDomain...), data...), nil
+}
+
+// Endorse returns the signature of s with the identity key privateKey, which is the endorsement of a party.
+func (s *Statement) Endorse(privateKey ed25519.PrivateKey) ([]byte, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("auth.Endorse: invalid private key")
+	}
+	data, err := s.signedData()
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.Sign(privateKey, data), nil
+}
+
+// Certificate proves that all the parties of a key generation agree on its outcome.
+// It contains the Statement and the endorsement of every party, and can be verified by outsiders
+// knowing the identity keys of the parties.
+type Certificate struct {
+	Statement Statement
+
+	// Endorsements maps the ID of each party to its signature of the Statement.
+	Endorsements map[party.ID][]byte
+}
+
+// NewCertificate returns the Certificate assembling the endorsements of statement.
+// The endorsements are not verified, see Verify.
+func NewCertificate(statement *Statement, endorsements map[party.ID][]byte) *Certificate {
+	c := &Certificate{
+		Statement:    *statement,
+		Endorsements: make(map[party.ID][]byte, len(endorsements)),
+	}
+	c.Statement.PartyIDs = statement.PartyIDs.Copy()
+	c.Statement.GroupKey = append(ed25519.PublicKey(nil), statement.GroupKey...)
+	for id, endorsement := range endorsements {
+		c.Endorsements[id] = append([]byte(nil), endorsement...)
+	}
+	return c
+}
+
+// CertificateError is returned by Certificate.Verify, and lists the parties whose endorsement is not valid.
+// It matches ErrInvalidCertificate with errors.Is.
+type CertificateError struct {
+	// Missing are the parties of the statement without endorsement, or without identity key.
+	Missing party.IDSlice
+	// Invalid are the parties whose endorsement does not verify with their identity key,
+	// and the endorsers which are not parties of the statement.
+	Invalid party.IDSlice
+}
+
+// Error implements error
+func (e *CertificateError) Error() string {
+	var reasons []string
+	if len(e.Missing) > 0 {
+		reasons = append(reasons, fmt.Sprintf("missing endorsements from %v", e.Missing))
+	}
+	if len(e.Invalid) > 0 {
+		reasons = append(reasons, fmt.Sprintf("invalid endorsements from %v", e.Invalid))
+	}
+	return ErrInvalidCertificate.Error() + ": " + strings.Join(reasons, ", ")
+}
+
+// Is returns true if target is ErrInvalidCertificate.
+func (e *CertificateError) Is(target error) bool {
+	return target == ErrInvalidCertificate
+}
+
+// Verify checks that every party of the statement endorsed it with its identity key in identityKeys.
+// If some endorsements are missing or invalid, a *CertificateError lists the parties responsible.
+func (c *Certificate) Verify(identityKeys map[party.ID]ed25519.PublicKey) error {
+	data, err := c.Statement.signedData()
+	if err != nil {
+		return err
+	}
+	if err = c.Statement.check(); err != nil {
+		return err
+	}
+
+	var certErr CertificateError
+	for _, id := range c.Statement.PartyIDs {
+		endorsement, ok := c.Endorsements[id]
+		key := identityKeys[id]
+		if !ok || len(key) != ed25519.PublicKeySize {
+			certErr.Missing = append(certErr.Missing, id)
+			continue
+		}
+		if !ed25519.Verify(key, data, endorsement) {
+			certErr.Invalid = append(certErr.Invalid, id)
+		}
+	}
+	for id := range c.Endorsements {
+		if !c.Statement.PartyIDs.Contains(id) {
+			certErr.Invalid = append(certErr.Invalid, id)
+		}
+	}
+	if len(certErr.Missing) > 0 || len(certErr.Invalid) > 0 {
+		certErr.Invalid = party.NewIDSlice(certErr.Invalid)
+		return &certErr
+	}
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The encoding is
+//
+//	statement ∥ m ∥ (ID ∥ endorsement)...
+//
+// where m and the IDs are 4 bytes big endian, and the m endorsements are 64 bytes long and sorted by ID.
+func (c *Certificate) MarshalBinary() ([]byte, error) {
+	data, err := c.Statement.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	ids := c.endorsers()
+	data = append(data, ids.N().Bytes()...)
+	for _, id := range ids {
+		endorsement := c.Endorsements[id]
+		if len(endorsement) != ed25519.SignatureSize {
+			return nil, fmt.Errorf("auth.Certificate: endorsement of party %d has an invalid size", id)
+		}
+		data = append(data, id.Bytes()...)
+		data = append(data, endorsement...)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// The endorsements are not verified, see Verify.
+func (c *Certificate) UnmarshalBinary(data []byte) error {
+	var out Certificate
+	data, err := out.Statement.unmarshal(data)
+	if err != nil {
+		return err
+	}
+	const entrySize = party.IDByteSize + ed25519.SignatureSize
+	if len(data) < party.IDByteSize {
+		return errors.New("auth.Certificate: data is too short")
+	}
+	m := int(binary.BigEndian.Uint32(data))
+	data = data[party.IDByteSize:]
+	if m > len(data)/entrySize || len(data) != m*entrySize {
+		return errors.New("auth.Certificate: invalid number of endorsements")
+	}
+	out.Endorsements = make(map[party.ID][]byte, m)
+	for ; len(data) > 0; data = data[entrySize:] {
+		id := party.ID(binary.BigEndian.Uint32(data))
+		if _, ok := out.Endorsements[id]; ok || id == 0 {
+			return fmt.Errorf("auth.Certificate: invalid endorser %d", id)
+		}
+		out.Endorsements[id] = append([]byte(nil), data[party.IDByteSize:entrySize]...)
+	}
+	*c = out
+	return nil
+}
+
+// endorsers returns the IDs of the parties which endorsed the statement, in increasing order.
+func (c *Certificate) endorsers() party.IDSlice {
+	ids := make(party.IDSlice, 0, len(c.Endorsements))
+	for id := range c.Endorsements {
+		ids = append(ids, id)
+	}
+	return party.NewIDSlice(ids)
+}
+
+// certificateJSON is the JSON encoding of Certificate, which looks like
+//
+//	{
+//	  "session_id": "<hex>",
+//	  "threshold": 2,
+//	  "participants": [1, 2, 3],
+//	  "groupkey": "<hex>",
+//	  "endorsements": {"1": "<hex>", "2": "<hex>", "3": "<hex>"}
+//	}
+//
+// where the group key is the hex encoding of its Ed25519 encoding.
+type certificateJSON struct {
+	SessionID    string              `json:"session_id"`
+	Threshold    uint32              `json:"threshold"`
+	Participants party.IDSlice       `json:"participants"`
+	GroupKey     string              `json:"groupkey"`
+	Endorsements map[party.ID]string `json:"endorsements"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (c *Certificate) MarshalJSON() ([]byte, error) {
+	// Perform the same checks as MarshalBinary
+	if _, err := c.MarshalBinary(); err != nil {
+		return nil, err
+	}
+	out := certificateJSON{
+		SessionID:    hex.EncodeToString(c.Statement.SessionID[:]),
+		Threshold:    uint32(c.Statement.Threshold),
+		Participants: c.Statement.PartyIDs,
+		GroupKey:     hex.EncodeToString(c.Statement.GroupKey),
+		Endorsements: make(map[party.ID]string, len(c.Endorsements)),
+	}
+	for id, endorsement := range c.Endorsements {
+		out.Endorsements[id] = hex.EncodeToString(endorsement)
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The certificate is validated in the same way as in UnmarshalBinary.
+func (c *Certificate) UnmarshalJSON(data []byte) error {
+	var out certificateJSON
+	if err := json.Unmarshal(data, &out); err != nil {
+		return fmt.Errorf("auth.Certificate: %w", err)
+	}
+	sessionID, err := hex.DecodeString(out.SessionID)
+	if err != nil || len(sessionID) != messages.SessionIDSize {
+		return errors.New("auth.Certificate: invalid session ID")
+	}
+	groupKey, err := hex.DecodeString(out.GroupKey)
+	if err != nil || len(groupKey) != ed25519.PublicKeySize {
+		return errors.New("auth.Certificate: invalid group key")
+	}
+	certificate := Certificate{
+		Statement: Statement{
+			PartyIDs:  out.Participants,
+			Threshold: party.Size(out.Threshold),
+			GroupKey:  groupKey,
+		},
+		Endorsements: make(map[party.ID][]byte, len(out.Endorsements)),
+	}
+	copy(certificate.Statement.SessionID[:], sessionID)
+	for id, encoded := range out.Endorsements {
+		endorsement, err := hex.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("auth.Certificate: endorsement of party %d: %w", id, err)
+		}
+		certificate.Endorsements[id] = endorsement
+	}
+
+	// Decoding the binary encoding performs all checks
+	encoded, err := certificate.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return c.UnmarshalBinary(encoded)
+}
@@ -0,0 +1,142 @@
+package auth_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/auth"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/keygen"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// ceremony runs an authenticated 3-of-5 key generation, and returns the endorsements of its outcome by all parties.
+func ceremony(t *testing.T, partyIDs party.IDSlice, public map[party.ID]ed25519.PublicKey, private map[party.ID]ed25519.PrivateKey) (*auth.Statement, map[party.ID][]byte) {
+	sessionID := messages.DeriveSessionID(partyIDs, []byte("ceremony"))
+	states := make(map[party.ID]*auth.State, len(partyIDs))
+	outputs := make(map[party.ID]*keygen.Output, len(partyIDs))
+	for _, id := range partyIDs {
+		s, output, err := frost.NewKeygenState(id, partyIDs, 2, 0, state.WithSessionID(sessionID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if states[id], err = auth.Wrap(s, private[id], public); err != nil {
+			t.Fatal(err)
+		}
+		outputs[id] = output
+	}
+	for round := 0; round < 3; round++ {
+		for _, s := range states {
+			envelopes, msgs, err := s.ProcessAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the shares of the second round are only delivered to their recipient
+			for i, msg := range msgs {
+				for id, receiver := range states {
+					if id == msg.From || (msg.To != 0 && msg.To != id) {
+						continue
+					}
+					if err = receiver.HandleEnvelope(envelopes[i]); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+		}
+	}
+
+	var statement *auth.Statement
+	endorsements := make(map[party.ID][]byte, len(partyIDs))
+	for id, s := range states {
+		if err := s.WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+		// Each party endorses the outcome it computed
+		ours := auth.NewStatement(outputs[id].Public, s.SessionID())
+		endorsement, err := ours.Endorse(private[id])
+		if err != nil {
+			t.Fatal(err)
+		}
+		endorsements[id] = endorsement
+		statement = ours
+	}
+	return statement, endorsements
+}
+
+func TestCertificate(t *testing.T) {
+	partyIDs := helpers.GenerateSet(5)
+	public, private := identityKeys(t, partyIDs)
+	statement, endorsements := ceremony(t, partyIDs, public, private)
+
+	certificate := auth.NewCertificate(statement, endorsements)
+	if err := certificate.Verify(public); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := certificate.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded auth.Certificate
+	if err = decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if err = decoded.Verify(public); err != nil {
+		t.Errorf("decoded binary certificate: %v", err)
+	}
+	if err = decoded.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Error("UnmarshalBinary() should fail on truncated data")
+	}
+
+	jsonData, err := json.Marshal(certificate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decodedJSON auth.Certificate
+	if err = json.Unmarshal(jsonData, &decodedJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err = decodedJSON.Verify(public); err != nil {
+		t.Errorf("decoded JSON certificate: %v", err)
+	}
+	if data2, _ := decodedJSON.MarshalBinary(); !bytes.Equal(data, data2) {
+		t.Error("the JSON and binary encodings differ")
+	}
+
+	// The endorsements do not verify for another statement
+	other := *statement
+	other.Threshold = 3
+	if err = auth.NewCertificate(&other, endorsements).Verify(public); !errors.Is(err, auth.ErrInvalidCertificate) {
+		t.Errorf("Verify() error = %v, want ErrInvalidCertificate", err)
+	}
+}
+
+func TestCertificate_Forged(t *testing.T) {
+	partyIDs := helpers.GenerateSet(5)
+	public, private := identityKeys(t, partyIDs)
+	statement, endorsements := ceremony(t, partyIDs, public, private)
+	forger, victim, absent := partyIDs[0], partyIDs[2], partyIDs[4]
+
+	// The endorsement of the victim is forged by another party, and another one is missing
+	forged, err := statement.Endorse(private[forger])
+	if err != nil {
+		t.Fatal(err)
+	}
+	endorsements[victim] = forged
+	delete(endorsements, absent)
+
+	var certErr *auth.CertificateError
+	err = auth.NewCertificate(statement, endorsements).Verify(public)
+	if !errors.As(err, &certErr) || !errors.Is(err, auth.ErrInvalidCertificate) {
+		t.Fatalf("Verify() error = %v, want a CertificateError", err)
+	}
+	if !certErr.Invalid.Equal(party.IDSlice{victim}) || !certErr.Missing.Equal(party.IDSlice{absent}) {
+		t.Errorf("invalid = %v, missing = %v", certErr.Invalid, certErr.Missing)
+	}
+}
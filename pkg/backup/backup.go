@@ -0,0 +1,408 @@
+// Package backup escrows the SecretShare of a party with a custodian, in a verifiable encrypted backup.
+//
+// The share is encrypted to the public key of a recovery key pair, whose private key is kept offline.
+// The backup also proves that it contains the secret of the public share of the party in its eddsa.Public,
+// so that the custodian can check it with Verify when receiving it, without being able to decrypt it.
+//
+// The share s is encrypted bit by bit, with an ElGamal encryption (Rᵢ, Cᵢ) = ([rᵢ]•G, [bᵢ]•G + [rᵢ]•Y) of each bit bᵢ,
+// where Y is the recovery public key. Each encryption comes with a disjunctive Chaum-Pedersen proof that bᵢ is 0 or 1,
+// and a final Chaum-Pedersen proof shows that ∑ 2ⁱ•Cᵢ - A and ∑ 2ⁱ•Rᵢ have the same discrete logarithm with
+// respect to Y and G, where A = [s]•G is the public share of the party. Together, they show that the bits
+// decrypt to s, and Decrypt recovers each bit by checking whether Cᵢ - [y]•Rᵢ is the identity or G.
+//
+// The challenges are computed with hashing.BackupChallenge, which binds the group key, the ID of the party and
+// the recovery key, so that a backup does not verify for another group, party or recovery key.
+package backup
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/hashing"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+var (
+	// ErrInvalidProof is returned by Verify when a proof of the backup does not verify, for instance because
+	// the backup was modified, or was made for another group, party or recovery key.
+	ErrInvalidProof = errors.New("backup: invalid proof")
+
+	// ErrInvalidBackup is returned by Decrypt when a bit does not decrypt to 0 or 1,
+	// which can only happen if the backup does not verify or the recovery key is wrong.
+	ErrInvalidBackup = errors.New("backup: the backup does not decrypt to a share")
+)
+
+const (
+	backupMagic   = "FROSTBK"
+	backupVersion = 1
+
+	// bits is the number of encrypted bits, enough for any scalar modulo ℓ < 2²⁵³.
+	bits = 253
+
+	// encryptedBitSize is the size of R ∥ C ∥ c₀ ∥ c₁ ∥ z₀ ∥ z₁.
+	encryptedBitSize = 6 * 32
+
+	// Size is the size in bytes of the binary encoding of a Backup.
+	Size = len(backupMagic) + 1 + party.IDByteSize + bits*encryptedBitSize + 2*32
+)
+
+// PrivateKey is the recovery key which can decrypt backups.
+type PrivateKey struct {
+	secret ristretto.Scalar
+	public PublicKey
+}
+
+// PublicKey is the recovery public key to which backups are encrypted.
+type PublicKey struct {
+	point ristretto.Element
+}
+
+// GenerateKey returns a new random recovery key.
+func GenerateKey() *PrivateKey {
+	var sk PrivateKey
+	scalar.SetScalarRandom(&sk.secret)
+	sk.public.point.ScalarBaseMult(&sk.secret)
+	return &sk
+}
+
+// Public returns the public key of sk.
+func (sk *PrivateKey) Public() *PublicKey {
+	var pk PublicKey
+	pk.point.Set(&sk.public.point)
+	return &pk
+}
+
+// Wipe clears the secret of sk.
+func (sk *PrivateKey) Wipe() {
+	sk.secret.Set(ristretto.NewScalar())
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The encoding is the 32 byte secret scalar.
+func (sk *PrivateKey) MarshalBinary() ([]byte, error) {
+	return sk.secret.Bytes(), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (sk *PrivateKey) UnmarshalBinary(data []byte) error {
+	if len(data) != 32 {
+		return errors.New("backup.PrivateKey: data is not the right size")
+	}
+	if _, err := sk.secret.SetCanonicalBytes(data); err != nil {
+		return fmt.Errorf("backup.PrivateKey: %w", err)
+	}
+	if sk.secret.Equal(ristretto.NewScalar()) == 1 {
+		return errors.New("backup.PrivateKey: secret is 0")
+	}
+	sk.public.point.ScalarBaseMult(&sk.secret)
+	return nil
+}
+
+// Equal returns true if pk and other are the same key.
+func (pk *PublicKey) Equal(other *PublicKey) bool {
+	return pk.point.Equal(&other.point) == 1
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The encoding is the 32 byte Ristretto encoding of the point.
+func (pk *PublicKey) MarshalBinary() ([]byte, error) {
+	return pk.point.Bytes(), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (pk *PublicKey) UnmarshalBinary(data []byte) error {
+	if len(data) != 32 {
+		return errors.New("backup.PublicKey: data is not the right size")
+	}
+	if _, err := pk.point.SetCanonicalBytes(data); err != nil {
+		return fmt.Errorf("backup.PublicKey: %w", err)
+	}
+	if pk.point.Equal(ristretto.NewIdentityElement()) == 1 {
+		return errors.New("backup.PublicKey: point is the identity")
+	}
+	return nil
+}
+
+// Backup is the verifiable encryption of the SecretShare of a party to a recovery key.
+type Backup struct {
+	// ID is the party whose share is encrypted.
+	ID party.ID
+
+	bits [bits]encryptedBit
+
+	// c and z are the Chaum-Pedersen proof that the bits decrypt to the secret of the public share of ID.
+	c, z ristretto.Scalar
+}
+
+// encryptedBit is the encryption (R, C) of a bit b, with the disjunctive proof (c₀, c₁, z₀, z₁)
+// that C - [b]•G and R have the same discrete logarithm with respect to Y and G, for b = 0 or b = 1.
+type encryptedBit struct {
+	R, C           ristretto.Element
+	c0, c1, z0, z1 ristretto.Scalar
+}
+
+// Encrypt returns a backup of secret, encrypted to recoveryKey.
+// secret must belong to public, as checked by SecretShare.Validate.
+//
+// The bits of the share only select values in constant time, but the backup should still be created on the
+// machine holding the share, and the intermediate values are cleared before returning.
+func Encrypt(secret *eddsa.SecretShare, public *eddsa.Public, recoveryKey *PublicKey) (*Backup, error) {
+	if err := secret.Validate(public); err != nil {
+		return nil, fmt.Errorf("backup.Encrypt: %w", err)
+	}
+	return encrypt(secret.ID, &secret.Secret, public, recoveryKey), nil
+}
+
+// encrypt returns a backup of s for party id, which is valid only if [s]•G is the public share of id in public.
+func encrypt(id party.ID, s *ristretto.Scalar, public *eddsa.Public, recoveryKey *PublicKey) *Backup {
+	groupKey := public.GroupKeyEd25519()
+	Y := &recoveryKey.point
+	G := ristretto.NewGeneratorElement()
+	identity := ristretto.NewIdentityElement()
+
+	backup := &Backup{ID: id}
+	secretBytes := s.Bytes()
+	defer wipe(secretBytes)
+
+	// rSum = ∑ 2ⁱ•rᵢ is the discrete logarithm of ∑ 2ⁱ•Rᵢ
+	var r, w, cSim, zSim, cReal, zReal, rSum, weight ristretto.Scalar
+	defer func() {
+		zero := ristretto.NewScalar()
+		for _, x := range []*ristretto.Scalar{&r, &w, &cSim, &zSim, &cReal, &zReal, &rSum} {
+			x.Set(zero)
+		}
+	}()
+	scalar.SetScalarUInt32(&weight, 1)
+
+	var C1, real1, real2, sim01, sim02, sim11, sim12, T01, T02, T11, T12 ristretto.Element
+	for i := range backup.bits {
+		e := &backup.bits[i]
+		b := int(secretBytes[i/8]>>(i%8)) & 1
+
+		scalar.SetScalarRandom(&r)
+		scalar.SetScalarRandom(&w)
+		scalar.SetScalarRandom(&cSim)
+		scalar.SetScalarRandom(&zSim)
+
+		// R = [r]•G, C = [r]•Y + [b]•G
+		e.R.ScalarBaseMult(&r)
+		e.C.ScalarMult(&r, Y)
+		e.C.Add(&e.C, selectElement(identity, G, b))
+		C1.Subtract(&e.C, G)
+
+		// The commitments of the real branch b, and the simulated ones of both branches, of which 1-b is used.
+		real1.ScalarBaseMult(&w)
+		real2.ScalarMult(&w, Y)
+		simulate(&sim01, &sim02, &cSim, &zSim, &e.R, &e.C, Y)
+		simulate(&sim11, &sim12, &cSim, &zSim, &e.R, &C1, Y)
+		T01.Set(selectElement(&real1, &sim01, b))
+		T02.Set(selectElement(&real2, &sim02, b))
+		T11.Set(selectElement(&sim11, &real1, b))
+		T12.Set(selectElement(&sim12, &real2, b))
+
+		c := hashing.BackupChallenge(groupKey, id, Y, uint32(i), &e.R, &e.C, &T01, &T02, &T11, &T12)
+		cReal.Subtract(c, &cSim)
+		zReal.MultiplyAdd(&cReal, &r, &w)
+		e.c0.Set(selectScalar(&cReal, &cSim, b))
+		e.c1.Set(selectScalar(&cSim, &cReal, b))
+		e.z0.Set(selectScalar(&zReal, &zSim, b))
+		e.z1.Set(selectScalar(&zSim, &zReal, b))
+
+		rSum.MultiplyAdd(&weight, &r, &rSum)
+		weight.Add(&weight, &weight)
+	}
+
+	R, D := backup.statement(public)
+	var K1, K2 ristretto.Element
+	scalar.SetScalarRandom(&w)
+	K1.ScalarBaseMult(&w)
+	K2.ScalarMult(&w, Y)
+	backup.c.Set(hashing.BackupChallenge(groupKey, id, Y, bits, R, D, &K1, &K2))
+	backup.z.MultiplyAdd(&backup.c, &rSum, &w)
+	return backup
+}
+
+// Verify checks that backup contains the secret share of the party backup.ID, whose public share is in public,
+// encrypted to recoveryKey. It returns an error wrapping ErrInvalidProof if it does not.
+func Verify(backup *Backup, public *eddsa.Public, recoveryKey *PublicKey) error {
+	if _, ok := public.Shares[backup.ID]; !ok || public.GroupKey == nil {
+		return fmt.Errorf("backup.Verify: party %d has no public share", backup.ID)
+	}
+	groupKey := public.GroupKeyEd25519()
+	Y := &recoveryKey.point
+	G := ristretto.NewGeneratorElement()
+
+	var c ristretto.Scalar
+	var C1, T01, T02, T11, T12 ristretto.Element
+	for i := range backup.bits {
+		e := &backup.bits[i]
+		C1.Subtract(&e.C, G)
+		simulate(&T01, &T02, &e.c0, &e.z0, &e.R, &e.C, Y)
+		simulate(&T11, &T12, &e.c1, &e.z1, &e.R, &C1, Y)
+		expected := hashing.BackupChallenge(groupKey, backup.ID, Y, uint32(i), &e.R, &e.C, &T01, &T02, &T11, &T12)
+		if c.Add(&e.c0, &e.c1).Equal(expected) != 1 {
+			return fmt.Errorf("backup.Verify: bit %d: %w", i, ErrInvalidProof)
+		}
+	}
+
+	R, D := backup.statement(public)
+	var K1, K2 ristretto.Element
+	simulate(&K1, &K2, &backup.c, &backup.z, R, D, Y)
+	if hashing.BackupChallenge(groupKey, backup.ID, Y, bits, R, D, &K1, &K2).Equal(&backup.c) != 1 {
+		return fmt.Errorf("backup.Verify: %w", ErrInvalidProof)
+	}
+	return nil
+}
+
+// Decrypt returns the SecretShare encrypted in backup with the public key of recoveryKey.
+// The backup should have been checked with Verify, and the result can be checked with SecretShare.Validate.
+// Its group is not set.
+func Decrypt(backup *Backup, recoveryKey *PrivateKey) (*eddsa.SecretShare, error) {
+	G := ristretto.NewGeneratorElement()
+	identity := ristretto.NewIdentityElement()
+
+	// The bits are little endian, and ∑ 2ⁱ•bᵢ < 2²⁵³ is reduced modulo ℓ.
+	var encoded [64]byte
+	defer wipe(encoded[:])
+	var M ristretto.Element
+	for i := range backup.bits {
+		e := &backup.bits[i]
+		M.ScalarMult(&recoveryKey.secret, &e.R)
+		M.Subtract(&e.C, &M)
+		switch {
+		case M.Equal(G) == 1:
+			encoded[i/8] |= 1 << (i % 8)
+		case M.Equal(identity) != 1:
+			return nil, fmt.Errorf("backup.Decrypt: bit %d: %w", i, ErrInvalidBackup)
+		}
+	}
+	var s ristretto.Scalar
+	if _, err := s.SetUniformBytes(encoded[:]); err != nil {
+		return nil, fmt.Errorf("backup.Decrypt: %w", err)
+	}
+	share := eddsa.NewSecretShare(backup.ID, &s)
+	s.Set(ristretto.NewScalar())
+	return share, nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The encoding is
+//
+//	"FROSTBK" ∥ version ∥ ID ∥ (R ∥ C ∥ c₀ ∥ c₁ ∥ z₀ ∥ z₁)... ∥ c ∥ z
+//
+// with the 253 encrypted bits from the least significant one, and its length is Size.
+func (backup *Backup) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, Size)
+	data = append(data, backupMagic...)
+	data = append(data, backupVersion)
+	data = append(data, backup.ID.Bytes()...)
+	for i := range backup.bits {
+		e := &backup.bits[i]
+		data = append(data, e.R.Bytes()...)
+		data = append(data, e.C.Bytes()...)
+		for _, x := range []*ristretto.Scalar{&e.c0, &e.c1, &e.z0, &e.z1} {
+			data = append(data, x.Bytes()...)
+		}
+	}
+	data = append(data, backup.c.Bytes()...)
+	data = append(data, backup.z.Bytes()...)
+	return data, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// It only checks that the points and scalars are canonical, and the backup must then be checked with Verify.
+func (backup *Backup) UnmarshalBinary(data []byte) error {
+	if len(data) != Size || string(data[:len(backupMagic)]) != backupMagic {
+		return errors.New("backup.Backup: invalid encoding")
+	}
+	data = data[len(backupMagic):]
+	if data[0] != backupVersion {
+		return fmt.Errorf("backup.Backup: unsupported version %d", data[0])
+	}
+	var out Backup
+	out.ID, _ = party.FromBytes(data[1:])
+	if out.ID == 0 {
+		return fmt.Errorf("backup.Backup: %w", party.ErrZeroID)
+	}
+	data = data[1+party.IDByteSize:]
+	for i := range out.bits {
+		e := &out.bits[i]
+		for j, p := range []*ristretto.Element{&e.R, &e.C} {
+			if _, err := p.SetCanonicalBytes(data[32*j : 32*j+32]); err != nil {
+				return fmt.Errorf("backup.Backup: bit %d: %w", i, err)
+			}
+		}
+		for j, x := range []*ristretto.Scalar{&e.c0, &e.c1, &e.z0, &e.z1} {
+			if _, err := x.SetCanonicalBytes(data[64+32*j : 96+32*j]); err != nil {
+				return fmt.Errorf("backup.Backup: bit %d: %w", i, err)
+			}
+		}
+		data = data[encryptedBitSize:]
+	}
+	if _, err := out.c.SetCanonicalBytes(data[:32]); err != nil {
+		return fmt.Errorf("backup.Backup: %w", err)
+	}
+	if _, err := out.z.SetCanonicalBytes(data[32:]); err != nil {
+		return fmt.Errorf("backup.Backup: %w", err)
+	}
+	*backup = out
+	return nil
+}
+
+// statement returns R = ∑ 2ⁱ•Rᵢ and D = ∑ 2ⁱ•Cᵢ - A, which satisfy D = [r]•Y and R = [r]•G if the bits encrypt
+// the discrete logarithm of the public share A of the party.
+func (backup *Backup) statement(public *eddsa.Public) (R, D *ristretto.Element) {
+	weights := make([]*ristretto.Scalar, bits)
+	Rs := make([]*ristretto.Element, bits)
+	Cs := make([]*ristretto.Element, bits)
+	weight := scalar.NewScalarUInt32(1)
+	for i := range backup.bits {
+		weights[i] = new(ristretto.Scalar).Set(weight)
+		Rs[i], Cs[i] = &backup.bits[i].R, &backup.bits[i].C
+		weight.Add(weight, weight)
+	}
+	R = new(ristretto.Element).VarTimeMultiScalarMult(weights, Rs)
+	D = new(ristretto.Element).VarTimeMultiScalarMult(weights, Cs)
+	D.Subtract(D, public.Shares[backup.ID])
+	return R, D
+}
+
+// simulate sets T1 = [z]•G - [c]•R and T2 = [z]•Y - [c]•D, the commitments of a Chaum-Pedersen proof (c, z)
+// that R = [r]•G and D = [r]•Y. They are the verification equations, and also simulate a proof for a challenge c.
+func simulate(T1, T2 *ristretto.Element, c, z *ristretto.Scalar, R, D, Y *ristretto.Element) {
+	var negC ristretto.Scalar
+	var tmp ristretto.Element
+	negC.Negate(c)
+	T1.VarTimeDoubleScalarBaseMult(&negC, R, z)
+	T2.ScalarMult(z, Y)
+	T2.Add(T2, tmp.ScalarMult(&negC, D))
+}
+
+// selectElement returns a if choice is 0 and b if it is 1, in constant time.
+func selectElement(a, b *ristretto.Element, choice int) *ristretto.Element {
+	encoded := a.Bytes()
+	subtle.ConstantTimeCopy(choice, encoded, b.Bytes())
+	var e ristretto.Element
+	_, _ = e.SetCanonicalBytes(encoded)
+	return &e
+}
+
+// selectScalar returns a if choice is 0 and b if it is 1, in constant time.
+func selectScalar(a, b *ristretto.Scalar, choice int) *ristretto.Scalar {
+	encoded := a.Bytes()
+	subtle.ConstantTimeCopy(choice, encoded, b.Bytes())
+	var s ristretto.Scalar
+	_, _ = s.SetCanonicalBytes(encoded)
+	wipe(encoded)
+	return &s
+}
+
+func wipe(data []byte) {
+	for i := range data {
+		data[i] = 0
+	}
+}
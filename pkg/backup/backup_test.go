@@ -0,0 +1,179 @@
+package backup
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+func setup(t *testing.T) (map[party.ID]*eddsa.SecretShare, *eddsa.Public, *PrivateKey) {
+	_, secrets := helpers.GenerateSecrets(party.IDSlice{1, 2, 3}, 1)
+	return secrets, helpers.GeneratePublic(1, secrets), GenerateKey()
+}
+
+func TestBackup(t *testing.T) {
+	secrets, public, recoveryKey := setup(t)
+
+	for id, secret := range secrets {
+		backup, err := Encrypt(secret, public, recoveryKey.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = Verify(backup, public, recoveryKey.Public()); err != nil {
+			t.Fatalf("party %d: %v", id, err)
+		}
+
+		data, err := backup.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != Size {
+			t.Fatalf("the encoding has %d bytes instead of %d", len(data), Size)
+		}
+		var decoded Backup
+		if err = decoded.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if err = Verify(&decoded, public, recoveryKey.Public()); err != nil {
+			t.Fatalf("party %d: decoded backup: %v", id, err)
+		}
+
+		recovered, err := Decrypt(&decoded, recoveryKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !recovered.Equal(secret) {
+			t.Errorf("party %d: the recovered share is different", id)
+		}
+		if err = recovered.Validate(public); err != nil {
+			t.Errorf("party %d: %v", id, err)
+		}
+	}
+}
+
+func TestVerify_Invalid(t *testing.T) {
+	secrets, public, recoveryKey := setup(t)
+	backup, err := Encrypt(secrets[1], public, recoveryKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := backup.MarshalBinary()
+	decode := func(data []byte) *Backup {
+		var b Backup
+		if err := b.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		return &b
+	}
+	G := ristretto.NewGeneratorElement()
+
+	// A bit whose ciphertext was flipped
+	flipped := decode(data)
+	flipped.bits[7].C.Add(&flipped.bits[7].C, G)
+	// Two bits exchanged with their proofs
+	swapped := decode(data)
+	swapped.bits[3], swapped.bits[4] = swapped.bits[4], swapped.bits[3]
+	// A modified response
+	response := decode(data)
+	response.z.Add(&response.z, scalar.NewScalarUInt32(1))
+	// The backup of party 1 presented as the one of party 2
+	substituted := decode(data)
+	substituted.ID = 2
+	// A valid encryption of another scalar, with valid proofs for its bits
+	other := encrypt(1, scalar.NewScalarRandom(), public, recoveryKey.Public())
+
+	for name, b := range map[string]*Backup{
+		"flipped bit":  flipped,
+		"swapped bits": swapped,
+		"response":     response,
+		"other party":  substituted,
+		"other scalar": other,
+	} {
+		if err := Verify(b, public, recoveryKey.Public()); !errors.Is(err, ErrInvalidProof) {
+			t.Errorf("%s: Verify() error = %v, want ErrInvalidProof", name, err)
+		}
+	}
+
+	// Another group or recovery key
+	otherSecrets, otherPublic, otherRecoveryKey := setup(t)
+	if err := Verify(backup, otherPublic, recoveryKey.Public()); !errors.Is(err, ErrInvalidProof) {
+		t.Errorf("other group: Verify() error = %v, want ErrInvalidProof", err)
+	}
+	if err := Verify(backup, public, otherRecoveryKey.Public()); !errors.Is(err, ErrInvalidProof) {
+		t.Errorf("other recovery key: Verify() error = %v, want ErrInvalidProof", err)
+	}
+	if _, err := Encrypt(otherSecrets[1], public, recoveryKey.Public()); err == nil {
+		t.Error("Encrypt() should reject a share of another group")
+	}
+	missing := decode(data)
+	missing.ID = 9
+	if err := Verify(missing, public, recoveryKey.Public()); err == nil {
+		t.Error("Verify() should reject a party without a public share")
+	}
+
+	// The wrong recovery key cannot decrypt
+	if _, err := Decrypt(backup, otherRecoveryKey); !errors.Is(err, ErrInvalidBackup) {
+		t.Errorf("Decrypt() with another key: error = %v, want ErrInvalidBackup", err)
+	}
+}
+
+func TestBackup_UnmarshalBinary_Invalid(t *testing.T) {
+	secrets, public, recoveryKey := setup(t)
+	backup, err := Encrypt(secrets[2], public, recoveryKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := backup.MarshalBinary()
+	corrupt := func(offset int, value ...byte) []byte {
+		corrupted := append([]byte(nil), data...)
+		copy(corrupted[offset:], value)
+		return corrupted
+	}
+	header := len(backupMagic) + 1 + party.IDByteSize
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"truncated": data[:len(data)-1],
+		"magic":     corrupt(0, 'X'),
+		"version":   corrupt(len(backupMagic), 2),
+		"zero ID":   corrupt(len(backupMagic)+1, 0, 0, 0, 0),
+		"point":     corrupt(header, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff),
+		"scalar":    corrupt(len(data)-1, 0xff),
+	} {
+		if err := new(Backup).UnmarshalBinary(data); err == nil {
+			t.Errorf("%s: UnmarshalBinary() should fail", name)
+		}
+	}
+}
+
+func TestKeys_MarshalBinary(t *testing.T) {
+	sk := GenerateKey()
+	data, _ := sk.MarshalBinary()
+	var decoded PrivateKey
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Public().Equal(sk.Public()) {
+		t.Error("the decoded private key has a different public key")
+	}
+
+	data, _ = sk.Public().MarshalBinary()
+	var pk PublicKey
+	if err := pk.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !pk.Equal(sk.Public()) {
+		t.Error("the decoded public key is different")
+	}
+
+	if err := new(PrivateKey).UnmarshalBinary(make([]byte, 32)); err == nil {
+		t.Error("UnmarshalBinary() should reject a zero private key")
+	}
+	if err := new(PublicKey).UnmarshalBinary(make([]byte, 32)); err == nil {
+		t.Error("UnmarshalBinary() should reject the identity")
+	}
+}
@@ -0,0 +1,147 @@
+package eddsa
+
+import (
+	"crypto/ed25519"
+	cryptorand "crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// batchRandReader is the source of the randomizers of VerifyBatch. It is only replaced by tests.
+var batchRandReader io.Reader = cryptorand.Reader
+
+// ErrBatchInvalid is wrapped by a *BatchError.
+var ErrBatchInvalid = errors.New("invalid signatures in batch")
+
+// BatchError is returned by VerifyBatch when some signatures are invalid.
+type BatchError struct {
+	// Invalid contains the indices of the invalid signatures, in increasing order.
+	Invalid []int
+}
+
+// Error implements error
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("eddsa.VerifyBatch: %s at indices %v", ErrBatchInvalid.Error(), e.Invalid)
+}
+
+// Unwrap returns ErrBatchInvalid.
+func (e *BatchError) Unwrap() error {
+	return ErrBatchInvalid
+}
+
+// VerifyBatch verifies that sigs[i] is a valid signature of messages[i] by pubs[i] for all i,
+// and accepts exactly the same signatures as PublicKey.Verify.
+//
+// The signatures are checked together by verifying a random linear combination of the verification equations
+//
+//	[∑ zᵢ•sᵢ]•G - ∑ [zᵢ•kᵢ]•Aᵢ - ∑ [zᵢ]•Rᵢ = 0
+//
+// where the 128 bit randomizers zᵢ are drawn from crypto/rand, which is faster than verifying each signature.
+// When the combination does not hold, the batch is split in halves until the invalid signatures are found,
+// and a *BatchError with their indices is returned.
+// Other errors indicate that the arguments are malformed, or that the randomness could not be read.
+func VerifyBatch(pubs []*PublicKey, messages [][]byte, sigs []*Signature) (bool, error) {
+	if len(pubs) != len(sigs) || len(messages) != len(sigs) {
+		return false, fmt.Errorf("eddsa.VerifyBatch: got %d keys, %d messages and %d signatures", len(pubs), len(messages), len(sigs))
+	}
+	for i := range sigs {
+		if pubs[i] == nil || sigs[i] == nil {
+			return false, fmt.Errorf("eddsa.VerifyBatch: nil key or signature at index %d", i)
+		}
+	}
+
+	indices := make([]int, len(sigs))
+	for i := range indices {
+		indices[i] = i
+	}
+	var invalid []int
+	if err := verifyBatch(pubs, messages, sigs, indices, &invalid); err != nil {
+		return false, err
+	}
+	if len(invalid) > 0 {
+		return false, &BatchError{Invalid: invalid}
+	}
+	return true, nil
+}
+
+// verifyBatch appends to invalid the indices of the invalid signatures among the given indices, in increasing order.
+func verifyBatch(pubs []*PublicKey, messages [][]byte, sigs []*Signature, indices []int, invalid *[]int) error {
+	switch len(indices) {
+	case 0:
+		return nil
+	case 1:
+		i := indices[0]
+		if !pubs[i].Verify(messages[i], sigs[i]) {
+			*invalid = append(*invalid, i)
+		}
+		return nil
+	}
+
+	valid, err := verifyCombination(pubs, messages, sigs, indices)
+	if err != nil || valid {
+		return err
+	}
+	half := len(indices) / 2
+	if err = verifyBatch(pubs, messages, sigs, indices[:half], invalid); err != nil {
+		return err
+	}
+	return verifyBatch(pubs, messages, sigs, indices[half:], invalid)
+}
+
+// verifyCombination checks a random linear combination of the verification equations of the signatures at indices.
+func verifyCombination(pubs []*PublicKey, messages [][]byte, sigs []*Signature, indices []int) (bool, error) {
+	n := len(indices)
+	randomness := make([]byte, 16*n)
+	if _, err := io.ReadFull(batchRandReader, randomness); err != nil {
+		return false, fmt.Errorf("eddsa.VerifyBatch: %w", err)
+	}
+
+	// scalars and points are [∑ zᵢ•sᵢ, -z₁, ..., -zₙ, -∑ zᵢ•kᵢ, ...] and [G, R₁, ..., Rₙ, A, ...],
+	// where the terms of signatures by the same key A are combined.
+	scalarValues := make([]ristretto.Scalar, 1+2*n)
+	scalars := make([]*ristretto.Scalar, 1+n, 1+2*n)
+	points := make([]*ristretto.Element, 1+n, 1+2*n)
+	for i := range scalars {
+		scalars[i] = &scalarValues[i]
+	}
+	points[0] = ristretto.NewGeneratorElement()
+
+	// Batches often contain many signatures by the same group, so each key is only encoded once.
+	type keyTerm struct {
+		encoded ed25519.PublicKey
+		scalar  *ristretto.Scalar
+	}
+	keys := make(map[*PublicKey]keyTerm)
+
+	var z, zk ristretto.Scalar
+	var zBytes [32]byte
+	for j, i := range indices {
+		copy(zBytes[:16], randomness[16*j:])
+		// A 128 bit integer is always a canonical encoding
+		_, _ = z.SetCanonicalBytes(zBytes[:])
+
+		key, ok := keys[pubs[i]]
+		if !ok {
+			key = keyTerm{
+				encoded: pubs[i].ToEd25519(),
+				scalar:  &scalarValues[len(scalars)],
+			}
+			keys[pubs[i]] = key
+			scalars = append(scalars, key.scalar)
+			points = append(points, &pubs[i].pk)
+		}
+		k := computeChallenge(&sigs[i].R, key.encoded, messages[i])
+		scalars[0].MultiplyAdd(&z, &sigs[i].S, scalars[0])
+		zk.Multiply(&z, k)
+		key.scalar.Subtract(key.scalar, &zk)
+		scalars[1+j].Negate(&z)
+		points[1+j] = &sigs[i].R
+	}
+
+	var result ristretto.Element
+	result.VarTimeMultiScalarMult(scalars, points)
+	return result.Equal(ristretto.NewIdentityElement()) == 1, nil
+}
@@ -0,0 +1,195 @@
+package eddsa
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// batch returns n valid signatures of distinct messages, by n distinct keys.
+func batch(n int) ([]*PublicKey, [][]byte, []*Signature) {
+	pubs := make([]*PublicKey, n)
+	msgs := make([][]byte, n)
+	sigs := make([]*Signature, n)
+	for i := range sigs {
+		sk := NewSecretShare(1, scalar.NewScalarRandom())
+		pubs[i] = &PublicKey{pk: sk.Public}
+		msgs[i] = []byte(fmt.Sprintf("message %d", i))
+		sigs[i] = sk.sign(msgs[i])
+	}
+	return pubs, msgs, sigs
+}
+
+// identitySignature returns a valid signature of message whose R is the identity.
+// Since R = [s]•G - [k]•A, it is obtained by setting s = k•a.
+func identitySignature(message []byte) (*PublicKey, *Signature) {
+	a := scalar.NewScalarRandom()
+	pk := NewPublicKeyFromPoint(new(ristretto.Element).ScalarBaseMult(a))
+	var sig Signature
+	sig.R.Set(ristretto.NewIdentityElement())
+	k := ComputeChallenge(&sig.R, pk, message)
+	sig.S.Multiply(k, a)
+	return pk, &sig
+}
+
+func TestVerifyBatch(t *testing.T) {
+	pubs, msgs, sigs := batch(16)
+	valid, err := VerifyBatch(pubs, msgs, sigs)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = VerifyBatch(nil, nil, nil)
+	assert.NoError(t, err)
+	assert.True(t, valid, "an empty batch is valid")
+
+	// Invalidate a signature, a message and a key
+	sigs[3] = &Signature{R: sigs[3].R}
+	sigs[3].S.Add(&sigs[4].S, scalar.NewScalarUInt32(1))
+	msgs[9] = []byte("other message")
+	pubs[15] = pubs[0]
+	valid, err = VerifyBatch(pubs, msgs, sigs)
+	assert.False(t, valid)
+	var batchErr *BatchError
+	require.True(t, errors.As(err, &batchErr), "error %v is not a *BatchError", err)
+	assert.True(t, errors.Is(err, ErrBatchInvalid))
+	assert.Equal(t, []int{3, 9, 15}, batchErr.Invalid)
+
+	_, err = VerifyBatch(pubs[:2], msgs, sigs)
+	assert.Error(t, err)
+	sigs[0] = nil
+	_, err = VerifyBatch(pubs, msgs, sigs)
+	assert.Error(t, err)
+}
+
+func TestVerifyBatch_AgreesWithVerify(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pubs, msgs, sigs := batch(40)
+	for iteration := 0; iteration < 20; iteration++ {
+		// Copy the batch, and corrupt a random subset of it
+		p := append([]*PublicKey(nil), pubs...)
+		m := append([][]byte(nil), msgs...)
+		s := append([]*Signature(nil), sigs...)
+		for i := range s {
+			switch r.Intn(8) {
+			case 0:
+				m[i] = []byte("corrupted")
+			case 1:
+				p[i] = pubs[(i+1)%len(pubs)]
+			case 2:
+				s[i] = &Signature{S: sigs[i].S}
+				s[i].R.Add(&sigs[i].R, ristretto.NewGeneratorElement())
+			case 3:
+				p[i], s[i] = identitySignature(m[i])
+			}
+		}
+
+		var want []int
+		for i := range s {
+			if !p[i].Verify(m[i], s[i]) {
+				want = append(want, i)
+			}
+		}
+		valid, err := VerifyBatch(p, m, s)
+		if len(want) == 0 {
+			assert.NoError(t, err)
+			assert.True(t, valid)
+			continue
+		}
+		assert.False(t, valid)
+		var batchErr *BatchError
+		require.True(t, errors.As(err, &batchErr), "error %v is not a *BatchError", err)
+		assert.Equal(t, want, batchErr.Invalid)
+	}
+}
+
+func TestVerifyBatch_IdentityR(t *testing.T) {
+	message := []byte(sampleMessage)
+	pk, sig := identitySignature(message)
+	require.True(t, pk.Verify(message, sig))
+
+	pubs, msgs, sigs := batch(3)
+	pubs = append(pubs, pk)
+	msgs = append(msgs, message)
+	sigs = append(sigs, sig)
+	valid, err := VerifyBatch(pubs, msgs, sigs)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	// With R the identity and S = 0, the signature is only valid for a key whose challenge is 0, so it is rejected.
+	sigs[3] = &Signature{}
+	sigs[3].R.Set(ristretto.NewIdentityElement())
+	assert.False(t, pk.Verify(message, sigs[3]))
+	valid, err = VerifyBatch(pubs, msgs, sigs)
+	assert.False(t, valid)
+	var batchErr *BatchError
+	require.True(t, errors.As(err, &batchErr))
+	assert.Equal(t, []int{3}, batchErr.Invalid)
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
+
+func TestVerifyBatch_Randomness(t *testing.T) {
+	pubs, msgs, sigs := batch(4)
+
+	original := batchRandReader
+	defer func() { batchRandReader = original }()
+	batchRandReader = failingReader{}
+	_, err := VerifyBatch(pubs, msgs, sigs)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF), "error %v does not wrap the reader's error", err)
+
+	// A deterministic reader gives the same result
+	batchRandReader = rand.New(rand.NewSource(1))
+	valid, err := VerifyBatch(pubs, msgs, sigs)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func benchmarkBatch(b *testing.B, pubs []*PublicKey, msgs [][]byte, sigs []*Signature) {
+	b.Run("VerifyBatch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if valid, err := VerifyBatch(pubs, msgs, sigs); !valid || err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Verify", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j := range sigs {
+				if !pubs[j].Verify(msgs[j], sigs[j]) {
+					b.Fatal("invalid signature")
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkVerifyBatch64(b *testing.B) {
+	pubs, msgs, sigs := batch(64)
+	benchmarkBatch(b, pubs, msgs, sigs)
+}
+
+// BenchmarkVerifyBatch64SameKey verifies 64 signatures by the same group.
+func BenchmarkVerifyBatch64SameKey(b *testing.B) {
+	sk := NewSecretShare(1, scalar.NewScalarRandom())
+	pk := &PublicKey{pk: sk.Public}
+	pubs := make([]*PublicKey, 64)
+	msgs := make([][]byte, 64)
+	sigs := make([]*Signature, 64)
+	for i := range sigs {
+		pubs[i] = pk
+		msgs[i] = []byte(fmt.Sprintf("message %d", i))
+		sigs[i] = sk.sign(msgs[i])
+	}
+	benchmarkBatch(b, pubs, msgs, sigs)
+}
@@ -0,0 +1,217 @@
+package eddsa
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// ErrCommitmentsMismatch is returned by Public.VerifyCommitments when the public shares or the group key
+// differ from the ones computed from the commitments.
+var ErrCommitmentsMismatch = errors.New("public shares do not match the commitments")
+
+// commitmentsBinaryVersion is the first byte of the binary encoding of Commitments.
+const commitmentsBinaryVersion = 1
+
+// Commitments contains the commitments to the polynomials of all parties of a keygen, as sent in its first round.
+// They determine the public shares and the group key, so that the result of a keygen can be audited long after it
+// finished, with PublicFromCommitments or Public.VerifyCommitments.
+// Its encoding contains N·(T+1) points, which is why keygen only exports it with keygen.WithCommitments.
+type Commitments struct {
+	// Threshold is the degree of the polynomials.
+	Threshold party.Size
+
+	// Parties maps the ID of each party to the commitments to its polynomial.
+	Parties map[party.ID]*polynomial.Exponent
+
+	// Sum is the sum of the commitments of all parties, whose evaluation at a party's ID is its public share,
+	// and whose constant term is the group key.
+	Sum *polynomial.Exponent
+}
+
+// NewCommitments returns the Commitments of parties, whose polynomials must have degree threshold,
+// and computes their Sum. The polynomials are not copied.
+func NewCommitments(threshold party.Size, parties map[party.ID]*polynomial.Exponent) (*Commitments, error) {
+	c := &Commitments{
+		Threshold: threshold,
+		Parties:   parties,
+	}
+	if err := c.validateParties(); err != nil {
+		return nil, err
+	}
+	c.Sum = c.sum()
+	return c, nil
+}
+
+// PartyIDs returns the sorted IDs of the parties.
+func (c *Commitments) PartyIDs() party.IDSlice {
+	ids := make([]party.ID, 0, len(c.Parties))
+	for id := range c.Parties {
+		ids = append(ids, id)
+	}
+	return party.NewIDSlice(ids)
+}
+
+// validateParties checks that there are more than Threshold parties, with non-zero IDs and polynomials of degree Threshold.
+func (c *Commitments) validateParties() error {
+	if c.Threshold == 0 {
+		return errors.New("Commitments: threshold must be at least 1")
+	}
+	if len(c.Parties) <= int(c.Threshold) {
+		return fmt.Errorf("Commitments: got %d parties for threshold %d", len(c.Parties), c.Threshold)
+	}
+	for id, p := range c.Parties {
+		if id == 0 {
+			return fmt.Errorf("Commitments: %w", party.ErrZeroID)
+		}
+		if p == nil || p.Degree() != c.Threshold {
+			return fmt.Errorf("Commitments: commitments of party %d do not have degree %d", id, c.Threshold)
+		}
+	}
+	return nil
+}
+
+// sum returns the sum of the commitments of all parties, which must have the same degree.
+func (c *Commitments) sum() *polynomial.Exponent {
+	var sum *polynomial.Exponent
+	for _, id := range c.PartyIDs() {
+		if sum == nil {
+			sum = c.Parties[id].Copy()
+			continue
+		}
+		_ = sum.Add(c.Parties[id])
+	}
+	return sum
+}
+
+// Validate checks that there are more than Threshold parties with non-zero IDs,
+// that all polynomials have degree Threshold, and that Sum is their sum.
+func (c *Commitments) Validate() error {
+	if err := c.validateParties(); err != nil {
+		return err
+	}
+	if c.Sum == nil || !c.Sum.Equal(c.sum()) {
+		return errors.New("Commitments: sum does not match the commitments of the parties")
+	}
+	return nil
+}
+
+// PublicFromCommitments returns the Public computed from c, after validating it:
+// the public share of each party is the evaluation of c.Sum at its ID, and the group key is its constant term.
+func PublicFromCommitments(c *Commitments) (*Public, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	partyIDs := c.PartyIDs()
+	shares := make(map[party.ID]*ristretto.Element, len(partyIDs))
+	for _, id := range partyIDs {
+		shares[id] = c.Sum.Evaluate(id.Scalar())
+	}
+	return &Public{
+		PartyIDs:  partyIDs,
+		Threshold: c.Threshold,
+		Shares:    shares,
+		GroupKey:  NewPublicKeyFromPoint(c.Sum.Constant()),
+	}, nil
+}
+
+// VerifyCommitments returns nil if s is the Public computed from c by PublicFromCommitments,
+// and ErrCommitmentsMismatch if the parties, threshold, shares or group key differ.
+func (s *Public) VerifyCommitments(c *Commitments) error {
+	public, err := PublicFromCommitments(c)
+	if err != nil {
+		return err
+	}
+	if s.GroupKey == nil || !s.Equal(public) {
+		return ErrCommitmentsMismatch
+	}
+	return nil
+}
+
+// Copy returns a deep copy of c.
+func (c *Commitments) Copy() *Commitments {
+	parties := make(map[party.ID]*polynomial.Exponent, len(c.Parties))
+	for id, p := range c.Parties {
+		parties[id] = p.Copy()
+	}
+	out := &Commitments{
+		Threshold: c.Threshold,
+		Parties:   parties,
+	}
+	if c.Sum != nil {
+		out.Sum = c.Sum.Copy()
+	}
+	return out
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The encoding is
+//
+//	version ∥ threshold ∥ n ∥ (ID ∥ commitment_0 ∥ … ∥ commitment_threshold)...
+//
+// where version is a single byte, threshold, n and the IDs are 4 bytes big endian,
+// and the parties are sorted by ID. The Sum is not encoded, since it is recomputed when decoding.
+func (c *Commitments) MarshalBinary() ([]byte, error) {
+	if err := c.validateParties(); err != nil {
+		return nil, err
+	}
+	partyIDs := c.PartyIDs()
+	data := make([]byte, 0, 1+2*party.IDByteSize+len(partyIDs)*(party.IDByteSize+32*(int(c.Threshold)+1)))
+	data = append(data, commitmentsBinaryVersion)
+	data = append(data, c.Threshold.Bytes()...)
+	data = append(data, partyIDs.N().Bytes()...)
+	for _, id := range partyIDs {
+		data = append(data, id.Bytes()...)
+		// The encoding of an Exponent starts with its degree, which is the threshold
+		p, err := c.Parties[id].MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, p[party.IDByteSize:]...)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface, and recomputes the Sum.
+func (c *Commitments) UnmarshalBinary(data []byte) error {
+	if len(data) < 1+2*party.IDByteSize {
+		return errors.New("Commitments: data is too short")
+	}
+	if data[0] != commitmentsBinaryVersion {
+		return fmt.Errorf("Commitments: unknown version %d", data[0])
+	}
+	threshold, _ := party.FromBytes(data[1:])
+	n, _ := party.FromBytes(data[1+party.IDByteSize:])
+	data = data[1+2*party.IDByteSize:]
+
+	size := 32 * (uint64(threshold) + 1)
+	// n is checked before the multiplication, which could overflow for large thresholds
+	if uint64(n) > uint64(len(data))/(party.IDByteSize+size) || uint64(len(data)) != uint64(n)*(party.IDByteSize+size) {
+		return fmt.Errorf("Commitments: got %d bytes for %d parties with threshold %d", len(data), n, threshold)
+	}
+	parties := make(map[party.ID]*polynomial.Exponent, n)
+	var previous party.ID
+	for i := party.Size(0); i < n; i++ {
+		id, _ := party.FromBytes(data)
+		if id <= previous {
+			return errors.New("Commitments: parties must be sorted by ID, without duplicates")
+		}
+		previous = id
+		var p polynomial.Exponent
+		if err := p.UnmarshalBinary(append(threshold.Bytes(), data[party.IDByteSize:party.IDByteSize+size]...)); err != nil {
+			return fmt.Errorf("Commitments: commitments of party %d: %w", id, err)
+		}
+		parties[id] = &p
+		data = data[party.IDByteSize+size:]
+	}
+
+	commitments, err := NewCommitments(threshold, parties)
+	if err != nil {
+		return err
+	}
+	*c = *commitments
+	return nil
+}
@@ -0,0 +1,117 @@
+package eddsa
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// fakeCommitments returns the commitments of a keygen between partyIDs, and the Public it produces.
+func fakeCommitments(t *testing.T, partyIDs party.IDSlice, threshold party.Size) (*Commitments, *Public) {
+	var secret ristretto.Scalar
+	polynomials := make(map[party.ID]*polynomial.Polynomial, len(partyIDs))
+	parties := make(map[party.ID]*polynomial.Exponent, len(partyIDs))
+	for _, id := range partyIDs {
+		polynomials[id] = polynomial.NewPolynomial(threshold, scalar.NewScalarRandom())
+		parties[id] = polynomial.NewPolynomialExponent(polynomials[id])
+		secret.Add(&secret, polynomials[id].Constant())
+	}
+
+	shares := make(map[party.ID]*ristretto.Element, len(partyIDs))
+	for _, id := range partyIDs {
+		var share ristretto.Scalar
+		for _, p := range polynomials {
+			share.Add(&share, p.Evaluate(id.Scalar()))
+		}
+		shares[id] = new(ristretto.Element).ScalarBaseMult(&share)
+	}
+	public, err := NewPublic(shares, threshold)
+	require.NoError(t, err)
+	require.True(t, public.GroupKey.Equal(NewPublicKeyFromPoint(new(ristretto.Element).ScalarBaseMult(&secret))))
+
+	commitments, err := NewCommitments(threshold, parties)
+	require.NoError(t, err)
+	return commitments, public
+}
+
+func TestPublicFromCommitments(t *testing.T) {
+	commitments, public := fakeCommitments(t, party.IDSlice{1, 4, 5, 9}, 2)
+
+	computed, err := PublicFromCommitments(commitments)
+	require.NoError(t, err)
+	assert.True(t, computed.Equal(public))
+	assert.NoError(t, computed.Validate())
+	assert.NoError(t, public.VerifyCommitments(commitments))
+
+	other, _ := fakeCommitments(t, party.IDSlice{1, 4, 5, 9}, 2)
+	assert.True(t, errors.Is(public.VerifyCommitments(other), ErrCommitmentsMismatch))
+
+	// The sum must match the commitments of the parties
+	tampered := commitments.Copy()
+	tampered.Sum = other.Sum
+	_, err = PublicFromCommitments(tampered)
+	assert.Error(t, err)
+}
+
+func TestCommitments_MarshalBinary(t *testing.T) {
+	commitments, public := fakeCommitments(t, party.IDSlice{2, 3, 300}, 1)
+
+	data, err := commitments.MarshalBinary()
+	require.NoError(t, err)
+	assert.Len(t, data, 1+2*party.IDByteSize+3*(party.IDByteSize+2*32))
+
+	var decoded Commitments
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.NoError(t, public.VerifyCommitments(&decoded))
+	assert.True(t, decoded.Sum.Equal(commitments.Sum))
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(commitments))
+	var gobDecoded Commitments
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&gobDecoded))
+	assert.NoError(t, public.VerifyCommitments(&gobDecoded))
+}
+
+func TestCommitments_UnmarshalBinary_Invalid(t *testing.T) {
+	commitments, _ := fakeCommitments(t, party.IDSlice{1, 2, 3}, 2)
+	valid, err := commitments.MarshalBinary()
+	require.NoError(t, err)
+	entry := party.IDByteSize + 3*32
+	header := 1 + 2*party.IDByteSize
+
+	withN := func(n byte) []byte {
+		data := append([]byte(nil), valid...)
+		data[header-1] = n
+		return data
+	}
+	swapped := append(append(append([]byte(nil), valid[:header]...), valid[header+entry:header+2*entry]...), valid[header:header+entry]...)
+	swapped = append(swapped, valid[header+2*entry:]...)
+	version := append([]byte{2}, valid[1:]...)
+	largeThreshold := append([]byte(nil), valid...)
+	copy(largeThreshold[1:], []byte{0xff, 0xff, 0xff, 0xff})
+
+	tests := map[string][]byte{
+		"short":           valid[:header-1],
+		"version":         version,
+		"truncated":       valid[:len(valid)-1],
+		"trailing data":   append(append([]byte(nil), valid...), 0),
+		"count":           withN(2),
+		"too few parties": withN(0)[:header],
+		"unsorted":        swapped,
+		"large threshold": largeThreshold,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			var c Commitments
+			assert.Error(t, c.UnmarshalBinary(data))
+		})
+	}
+}
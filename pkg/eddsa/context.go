@@ -0,0 +1,81 @@
+package eddsa
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// MaxContextSize is the maximum size of the context string of Ed25519ctx.
+const MaxContextSize = 255
+
+// ErrInvalidContext is wrapped by a *ContextError.
+var ErrInvalidContext = errors.New("invalid context")
+
+// ContextError is returned when an Ed25519ctx context string is empty or longer than MaxContextSize.
+type ContextError struct {
+	// Length is the size of the invalid context.
+	Length int
+}
+
+// Error implements error
+func (e *ContextError) Error() string {
+	return fmt.Sprintf("eddsa: %s of %d bytes, it must have between 1 and %d bytes", ErrInvalidContext.Error(), e.Length, MaxContextSize)
+}
+
+// Unwrap returns ErrInvalidContext.
+func (e *ContextError) Unwrap() error {
+	return ErrInvalidContext
+}
+
+// ValidateContext returns a *ContextError if context cannot be used with Ed25519ctx.
+func ValidateContext(context []byte) error {
+	if len(context) == 0 || len(context) > MaxContextSize {
+		return &ContextError{Length: len(context)}
+	}
+	return nil
+}
+
+// dom2 returns the prefix of the Ed25519 variants defined in RFC 8032, Section 5.1:
+//
+//	dom2(flag, context) = "SigEd25519 no Ed25519 collisions" ∥ flag ∥ len(context) ∥ context
+//
+// where flag is 1 for Ed25519ph and 0 for Ed25519ctx. The context must be at most MaxContextSize bytes.
+func dom2(flag byte, context []byte) []byte {
+	const domain = "SigEd25519 no Ed25519 collisions"
+	prefix := make([]byte, 0, len(domain)+2+len(context))
+	prefix = append(prefix, domain...)
+	prefix = append(prefix, flag, byte(len(context)))
+	return append(prefix, context...)
+}
+
+// ComputeChallengeWithContext computes the challenge of Ed25519ctx H(dom2(0, context) ∥ R ∥ A ∥ M).
+// The resulting signatures are accepted by ed25519.VerifyWithOptions with ed25519.Options{Context: context}.
+func ComputeChallengeWithContext(R *ristretto.Element, groupKey *PublicKey, context, message []byte) (*ristretto.Scalar, error) {
+	if err := ValidateContext(context); err != nil {
+		return nil, err
+	}
+	return computeChallengeWithPrefix(dom2(0, context), R, groupKey.ToEd25519(), message), nil
+}
+
+// VerifyWithContext reports whether sig is a valid Ed25519ctx signature of message by pk with the given context.
+// It returns false if the context is invalid.
+func (pk *PublicKey) VerifyWithContext(message []byte, sig *Signature, context []byte) bool {
+	challenge, err := ComputeChallengeWithContext(&sig.R, pk, context, message)
+	if err != nil {
+		return false
+	}
+	return pk.verifyChallenge(challenge, sig)
+}
+
+// VerifyWithContext reports whether sig is a valid Ed25519ctx signature of message by pub with the given context.
+// For keys generated by crypto/ed25519, it gives the same result as ed25519.VerifyWithOptions with ed25519.Options{Context: context}.
+func VerifyWithContext(pub ed25519.PublicKey, message []byte, sig *Signature, context []byte) bool {
+	pk, err := NewPublicKeyFromEd25519(pub)
+	if err != nil {
+		return false
+	}
+	return pk.VerifyWithContext(message, sig, context)
+}
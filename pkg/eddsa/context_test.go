@@ -0,0 +1,58 @@
+package eddsa
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// RFC 8032, Section 7.2, TEST foo
+const (
+	rfc8032CtxPublic    = "dfc9425e4f968f7f0c29f0259cf5f9aed6851c2bb4ad8bfb860cfee0ab248292"
+	rfc8032CtxMessage   = "f726936d19c800494e3fdaff20b276a8"
+	rfc8032CtxContext   = "foo"
+	rfc8032CtxSignature = "55a4cc2f70a54e04288c5f4cd1e45a7bb520b36292911876cada7323198dd87a" +
+		"8b36950b95130022907a7fb7c4e9b2d5f6cca685a587b4b21f4b888e4e7edb0d"
+)
+
+func TestVerifyWithContext_RFC8032(t *testing.T) {
+	publicKey, _ := hex.DecodeString(rfc8032CtxPublic)
+	message, _ := hex.DecodeString(rfc8032CtxMessage)
+	sigBytes, _ := hex.DecodeString(rfc8032CtxSignature)
+	var sig Signature
+	_, err := sig.R.SetEd25519Bytes(sigBytes[:32])
+	require.NoError(t, err)
+	_, err = sig.S.SetCanonicalBytes(sigBytes[32:])
+	require.NoError(t, err)
+
+	assert.True(t, VerifyWithContext(publicKey, message, &sig, []byte(rfc8032CtxContext)))
+	assert.False(t, VerifyWithContext(publicKey, message, &sig, []byte("bar")))
+	assert.False(t, VerifyWithContext(publicKey, message, &sig, nil), "the empty context is not valid")
+	assert.False(t, Verify(publicKey, message, &sig))
+
+	pk, err := NewPublicKeyFromEd25519(publicKey)
+	require.NoError(t, err)
+	assert.True(t, pk.VerifyWithContext(message, &sig, []byte(rfc8032CtxContext)))
+	assert.False(t, pk.VerifyPrehashed(message, &sig))
+}
+
+func TestValidateContext(t *testing.T) {
+	assert.NoError(t, ValidateContext([]byte{0}))
+	assert.NoError(t, ValidateContext([]byte(strings.Repeat("c", MaxContextSize))))
+	for _, context := range [][]byte{nil, {}, []byte(strings.Repeat("c", MaxContextSize+1))} {
+		err := ValidateContext(context)
+		var contextErr *ContextError
+		require.True(t, errors.As(err, &contextErr), "error %v is not a *ContextError", err)
+		assert.True(t, errors.Is(err, ErrInvalidContext))
+		assert.Equal(t, len(context), contextErr.Length)
+
+		generator := ristretto.NewGeneratorElement()
+		_, err = ComputeChallengeWithContext(generator, NewPublicKeyFromPoint(generator), context, nil)
+		assert.True(t, errors.Is(err, ErrInvalidContext))
+	}
+}
@@ -0,0 +1,96 @@
+package eddsa
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/hashing"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// Child keys are derived without hardening: the child of the group key Y at index i is
+//
+//	Y' = Y + [δ]•G,   δ = SHA-512("FROST-Ed25519 derive" ∥ Y ∥ i) mod ℓ
+//
+// where Y is the Ed25519 encoding of the group key and i is encoded as 4 bytes in big endian.
+// Since δ only depends on public values, anyone who knows Y can derive the child group keys,
+// without taking part in the key generation.
+//
+// Every party adds δ to its secret share sᵢ, and every public share Aᵢ becomes Aᵢ + [δ]•G.
+// The shares are then those of the polynomial f + δ, whose constant term is s + δ,
+// since the Lagrange coefficients of any set of signers sum to 1.
+// The existing sign protocol can therefore be run unchanged with the derived SecretShare and Public.
+
+// HardenedIndex is the first index of a hardened derivation, which is not supported.
+const HardenedIndex uint32 = 1 << 31
+
+// ErrHardenedDerivation is returned when deriving a child key at an index larger or equal to HardenedIndex.
+// Hardened derivation hashes the secret key, which is not possible when it is shared between the parties.
+var ErrHardenedDerivation = errors.New("hardened derivation is not possible with a shared key")
+
+// Derive returns the Public of the child group key at the given path, where each index is derived from the previous key.
+// The public shares are shifted so that they correspond to the SecretShare returned by SecretShare.Derive.
+// An empty path returns a copy of s.
+func (s *Public) Derive(path ...uint32) (*Public, error) {
+	groupKey, tweak, err := derivePath(s.GroupKey, path)
+	if err != nil {
+		return nil, err
+	}
+	var shift ristretto.Element
+	shift.ScalarBaseMult(tweak)
+	shares := make(map[party.ID]*ristretto.Element, len(s.Shares))
+	for id, share := range s.Shares {
+		shares[id] = new(ristretto.Element).Add(share, &shift)
+	}
+	return &Public{
+		PartyIDs:  s.PartyIDs.Copy(),
+		Threshold: s.Threshold,
+		Shares:    shares,
+		GroupKey:  groupKey,
+	}, nil
+}
+
+// Derive returns the share of the child key at the given path of groupKey, which must be the group key of sk.
+// If the group of sk was set by SetGroup, ErrWrongGroup is returned for another group key, and the group of the child share is set.
+// Every party must derive its own share, and sign with the Public returned by Public.Derive for the same path.
+func (sk *SecretShare) Derive(groupKey *PublicKey, path ...uint32) (*SecretShare, error) {
+	if sk.group != nil && !bytes.Equal(sk.group, groupKey.fingerprint()) {
+		return nil, fmt.Errorf("SecretShare: %w", ErrWrongGroup)
+	}
+	child, tweak, err := derivePath(groupKey, path)
+	if err != nil {
+		return nil, err
+	}
+	var secret ristretto.Scalar
+	secret.Add(&sk.Secret, tweak)
+	derived := NewSecretShare(sk.ID, &secret)
+	if sk.group != nil {
+		derived.SetGroup(child)
+	}
+	return derived, nil
+}
+
+// derivePath returns the child of groupKey at path, and the sum of the tweaks δ along the path.
+func derivePath(groupKey *PublicKey, path []uint32) (*PublicKey, *ristretto.Scalar, error) {
+	child := NewPublicKeyFromPoint(&groupKey.pk)
+	var tweak ristretto.Scalar
+	for _, index := range path {
+		delta, err := derivationTweak(child, index)
+		if err != nil {
+			return nil, nil, err
+		}
+		tweak.Add(&tweak, delta)
+		child = child.Tweak(delta)
+	}
+	return child, &tweak, nil
+}
+
+// derivationTweak returns δ = SHA-512("FROST-Ed25519 derive" ∥ Y ∥ index) mod ℓ.
+func derivationTweak(groupKey *PublicKey, index uint32) (*ristretto.Scalar, error) {
+	if index >= HardenedIndex {
+		return nil, fmt.Errorf("eddsa.Derive: index %d: %w", index, ErrHardenedDerivation)
+	}
+	return hashing.DerivationTweak(groupKey.ToEd25519(), index), nil
+}
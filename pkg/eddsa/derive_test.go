@@ -0,0 +1,107 @@
+package eddsa
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+func TestDerive(t *testing.T) {
+	const n, threshold = 5, 2
+	secret := scalar.NewScalarRandom()
+	poly := polynomial.NewPolynomial(threshold, secret)
+	secrets := make(map[party.ID]*SecretShare, n)
+	shares := make(map[party.ID]*ristretto.Element, n)
+	for id := party.ID(1); id <= n; id++ {
+		secrets[id] = NewSecretShare(id, poly.Evaluate(id.Scalar()))
+		shares[id] = &secrets[id].Public
+	}
+	public, err := NewPublic(shares, threshold)
+	require.NoError(t, err)
+
+	path := []uint32{0, 7, HardenedIndex - 1}
+	child, err := public.Derive(path...)
+	require.NoError(t, err)
+	assert.False(t, child.GroupKey.Equal(public.GroupKey))
+	assert.Equal(t, public.Threshold, child.Threshold)
+	assert.True(t, public.PartyIDs.Equal(child.PartyIDs))
+
+	// The derived public shares are those of the derived secret shares,
+	// and are consistent with the derived group key.
+	childSecrets := make(map[party.ID]*SecretShare, n)
+	for id, sk := range secrets {
+		childSecrets[id], err = sk.Derive(public.GroupKey, path...)
+		require.NoError(t, err)
+		assert.Equal(t, 1, childSecrets[id].Public.Equal(child.Shares[id]))
+	}
+	recomputed, err := NewPublic(child.Shares, threshold)
+	require.NoError(t, err)
+	assert.True(t, recomputed.GroupKey.Equal(child.GroupKey))
+
+	// Any threshold+1 derived secret shares interpolate to the derived secret key
+	signers := party.IDSlice{2, 4, 5}
+	var childSecret, tmp ristretto.Scalar
+	for _, id := range signers {
+		lagrange, err := id.Lagrange(signers)
+		require.NoError(t, err)
+		childSecret.Add(&childSecret, tmp.Multiply(lagrange, &childSecrets[id].Secret))
+	}
+	assert.True(t, NewPublicKeyFromPoint(new(ristretto.Element).ScalarBaseMult(&childSecret)).Equal(child.GroupKey))
+
+	// A signature with the derived key is valid for the derived group key only
+	sig := NewSecretShare(0, &childSecret).sign([]byte(sampleMessage))
+	assert.True(t, child.GroupKey.Verify([]byte(sampleMessage), sig))
+	assert.False(t, public.GroupKey.Verify([]byte(sampleMessage), sig))
+
+	// Deriving along a path is the same as deriving each index in turn
+	step := public
+	for _, index := range path {
+		step, err = step.Derive(index)
+		require.NoError(t, err)
+	}
+	assert.True(t, step.Equal(child))
+	assert.True(t, step.GroupKey.Equal(child.GroupKey))
+
+	// The group key can be derived from the Ed25519 key alone
+	external, err := PublicFromEd25519(public.GroupKeyEd25519())
+	require.NoError(t, err)
+	externalChild, err := external.Derive(path...)
+	require.NoError(t, err)
+	assert.Equal(t, child.GroupKeyEd25519(), externalChild.GroupKeyEd25519())
+
+	// The empty path returns the same keys
+	same, err := public.Derive()
+	require.NoError(t, err)
+	assert.True(t, same.Equal(public))
+	sameSecret, err := secrets[1].Derive(public.GroupKey)
+	require.NoError(t, err)
+	assert.True(t, sameSecret.Equal(secrets[1]))
+}
+
+func TestDerive_Hardened(t *testing.T) {
+	public, _ := fakeShares(3, 1)
+	sk := NewSecretShare(1, scalar.NewScalarRandom())
+	for _, path := range [][]uint32{{HardenedIndex}, {1, HardenedIndex + 5}, {^uint32(0)}} {
+		_, err := public.Derive(path...)
+		assert.True(t, errors.Is(err, ErrHardenedDerivation), "path %v: error %v", path, err)
+		_, err = sk.Derive(public.GroupKey, path...)
+		assert.True(t, errors.Is(err, ErrHardenedDerivation), "path %v: error %v", path, err)
+	}
+}
+
+func TestDerive_Vector(t *testing.T) {
+	// The derivation is part of the addresses of the users, so it must never change.
+	groupKey := NewPublicKeyFromPoint(new(ristretto.Element).ScalarBaseMult(scalar.NewScalarUInt32(42)))
+	public, err := PublicFromEd25519(groupKey.ToEd25519())
+	require.NoError(t, err)
+	child, err := public.Derive(0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "275ac99743ab237f20635578de6a327c9ed72d65727c5fe46d392999e337380f", hex.EncodeToString(child.GroupKeyEd25519()))
+}
@@ -0,0 +1,56 @@
+package eddsa
+
+// The types of this package contain unexported Ristretto values, which encoding/gob cannot encode directly.
+// They implement gob.GobEncoder and gob.GobDecoder by delegating to their binary encodings,
+// so that they can be embedded in larger gob-encoded structures.
+// Invalid data is rejected with the same errors as UnmarshalBinary, instead of decoding to zero values.
+
+// GobEncode implements the gob.GobEncoder interface.
+func (pk *PublicKey) GobEncode() ([]byte, error) {
+	return pk.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (pk *PublicKey) GobDecode(data []byte) error {
+	return pk.UnmarshalBinary(data)
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+func (s *Public) GobEncode() ([]byte, error) {
+	return s.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (s *Public) GobDecode(data []byte) error {
+	return s.UnmarshalBinary(data)
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+func (sk *SecretShare) GobEncode() ([]byte, error) {
+	return sk.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (sk *SecretShare) GobDecode(data []byte) error {
+	return sk.UnmarshalBinary(data)
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+func (sig *Signature) GobEncode() ([]byte, error) {
+	return sig.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (sig *Signature) GobDecode(data []byte) error {
+	return sig.UnmarshalBinary(data)
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+func (c *Commitments) GobEncode() ([]byte, error) {
+	return c.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (c *Commitments) GobDecode(data []byte) error {
+	return c.UnmarshalBinary(data)
+}
@@ -0,0 +1,85 @@
+package eddsa
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+)
+
+// gobArtifacts is an application defined structure containing the types of this package.
+type gobArtifacts struct {
+	Name      string
+	Publics   map[party.ID]*Public
+	GroupKey  PublicKey
+	Secret    *SecretShare
+	Signature *Signature
+}
+
+func TestGob_RoundTrip(t *testing.T) {
+	public1, _ := fakeShares(5, 2)
+	public2, _ := fakeShares(3, 1)
+	sig, pk, err := generateSignature()
+	require.NoError(t, err)
+
+	in := gobArtifacts{
+		Name:      "keys",
+		Publics:   map[party.ID]*Public{1: public1, 2: public2},
+		GroupKey:  *pk,
+		Secret:    NewSecretShare(42, scalar.NewScalarRandom()),
+		Signature: sig,
+	}
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(&in))
+
+	var out gobArtifacts
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&out))
+	assert.Equal(t, in.Name, out.Name)
+	require.Len(t, out.Publics, 2)
+	for id, public := range in.Publics {
+		assert.True(t, public.Equal(out.Publics[id]), "public %d differs", id)
+	}
+	assert.True(t, in.GroupKey.Equal(&out.GroupKey))
+	assert.True(t, in.Secret.Equal(out.Secret))
+	assert.Equal(t, in.Secret.Public.Bytes(), out.Secret.Public.Bytes())
+	assert.True(t, in.Signature.Equal(out.Signature))
+}
+
+func TestGob_Corrupted(t *testing.T) {
+	sig, pk, err := generateSignature()
+	require.NoError(t, err)
+	public, _ := fakeShares(3, 1)
+
+	for name, v := range map[string]interface{}{
+		"PublicKey": pk,
+		"Public":    public,
+		"Signature": sig,
+		"Secret":    NewSecretShare(1, scalar.NewScalarRandom()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, gob.NewEncoder(&buf).Encode(v))
+			data := buf.Bytes()
+
+			// Replace the last 32 bytes, which are a point or a scalar, by a non canonical encoding
+			corrupted := append([]byte(nil), data...)
+			for i := len(corrupted) - 32; i < len(corrupted); i++ {
+				corrupted[i] = 0xff
+			}
+			decoded := map[string]interface{}{
+				"PublicKey": new(PublicKey),
+				"Public":    new(Public),
+				"Signature": new(Signature),
+				"Secret":    new(SecretShare),
+			}[name]
+			assert.Error(t, gob.NewDecoder(bytes.NewReader(corrupted)).Decode(decoded))
+
+			// Truncated data
+			assert.Error(t, gob.NewDecoder(bytes.NewReader(data[:len(data)-1])).Decode(decoded))
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package eddsa
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// ErrKeyShareMismatch is returned by KeyShare.Validate when the secret share does not match its public share.
+var ErrKeyShareMismatch = errors.New("the secret share does not match its public share")
+
+// KeyShare bundles everything a party needs to sign after a key generation:
+// its SecretShare, and the Public of the group, which contains the threshold, the public shares and the group key.
+type KeyShare struct {
+	Secret *SecretShare
+	Public *Public
+}
+
+// NewKeyShare returns a KeyShare for secret and public, or an error if they are not consistent.
+// The values are not copied.
+func NewKeyShare(secret *SecretShare, public *Public) (*KeyShare, error) {
+	k := &KeyShare{Secret: secret, Public: public}
+	if err := k.Validate(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// ID returns the ID of the party owning the KeyShare.
+func (k *KeyShare) ID() party.ID {
+	return k.Secret.ID
+}
+
+// Threshold returns the threshold of the key generation.
+func (k *KeyShare) Threshold() party.Size {
+	return k.Public.Threshold
+}
+
+// MinSigners returns the number of parties required to sign, Threshold()+1.
+func (k *KeyShare) MinSigners() party.Size {
+	return k.Public.MinSigners()
+}
+
+// GroupKey returns the public key of the group.
+func (k *KeyShare) GroupKey() *PublicKey {
+	return k.Public.GroupKey
+}
+
+// Validate checks that the SecretShare belongs to the Public, as described in SecretShare.Validate.
+// A *ShareMismatchError is returned if the secret share belongs to another group or party.
+func (k *KeyShare) Validate() error {
+	if k.Secret == nil || k.Public == nil {
+		return errors.New("eddsa.KeyShare: missing secret or public shares")
+	}
+	id := k.Secret.ID
+	if err := k.Secret.Validate(k.Public); err != nil {
+		return err
+	}
+	var expected ristretto.Element
+	expected.ScalarBaseMult(&k.Secret.Secret)
+	if expected.Equal(&k.Secret.Public) != 1 {
+		return fmt.Errorf("eddsa.KeyShare: party %d: the public key of the SecretShare is not [s]•G", id)
+	}
+	return nil
+}
+
+// Equal reports whether k and other hold the same shares.
+func (k *KeyShare) Equal(other *KeyShare) bool {
+	return k.Secret.Equal(other.Secret) && k.Public.Equal(other.Public)
+}
+
+// Copy returns a deep copy of k.
+func (k *KeyShare) Copy() *KeyShare {
+	return &KeyShare{
+		Secret: k.Secret.Copy(),
+		Public: k.Public.Copy(),
+	}
+}
+
+// Wipe clears the secret share, as described in SecretShare.Wipe.
+func (k *KeyShare) Wipe() {
+	k.Secret.Wipe()
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The encoding is the one of the SecretShare without its group, followed by the one of the Public.
+func (k *KeyShare) MarshalBinary() ([]byte, error) {
+	secret := k.Secret.marshalBinary(false)
+	defer wipe(secret)
+	public, err := k.Public.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 0, len(secret)+len(public))
+	data = append(data, secret...)
+	data = append(data, public...)
+	return data, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// The decoded KeyShare is validated, and the group of its SecretShare is set.
+func (k *KeyShare) UnmarshalBinary(data []byte) error {
+	const secretSize = party.IDByteSize + 32
+	if len(data) < secretSize {
+		return errors.New("eddsa.KeyShare: data is too short")
+	}
+	var out KeyShare
+	out.Secret, out.Public = new(SecretShare), new(Public)
+	if err := out.Secret.UnmarshalBinary(data[:secretSize]); err != nil {
+		return fmt.Errorf("eddsa.KeyShare: %w", err)
+	}
+	if err := out.Public.UnmarshalBinary(data[secretSize:]); err != nil {
+		return fmt.Errorf("eddsa.KeyShare: %w", err)
+	}
+	if err := out.Validate(); err != nil {
+		return err
+	}
+	out.Secret.SetGroup(out.Public.GroupKey)
+	*k = out
+	return nil
+}
+
+type jsonKeyShare struct {
+	Secret *SecretShare `json:"secret"`
+	Public *Public      `json:"public"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (k *KeyShare) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonKeyShare{Secret: k.Secret, Public: k.Public})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The decoded KeyShare is validated, and the group of its SecretShare is set.
+func (k *KeyShare) UnmarshalJSON(data []byte) error {
+	var out jsonKeyShare
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	decoded := KeyShare(out)
+	if err := decoded.Validate(); err != nil {
+		return err
+	}
+	decoded.Secret.SetGroup(decoded.Public.GroupKey)
+	*k = decoded
+	return nil
+}
+
+// MarshalEncrypted returns k with its SecretShare encrypted under passphrase, as described in SecretShare.MarshalEncrypted.
+// The encoding is
+//
+//	length ∥ public ∥ secret
+//
+// where public is the encoding of the Public by MarshalBinary, length its length in 4 bytes big endian,
+// and secret the encrypted SecretShare. The Public is not encrypted.
+func (k *KeyShare) MarshalEncrypted(passphrase []byte) ([]byte, error) {
+	return k.marshalEncrypted(passphrase, defaultArgon2Params)
+}
+
+func (k *KeyShare) marshalEncrypted(passphrase []byte, params argon2Params) ([]byte, error) {
+	public, err := k.Public.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	// The SecretShare is bound to the group of the Public, unless SetGroup was already called on it.
+	share := k.Secret
+	if share.group == nil {
+		share = share.Copy()
+		defer share.Wipe()
+		share.SetGroup(k.Public.GroupKey)
+	}
+	secret, err := share.marshalEncrypted(passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 4, 4+len(public)+len(secret))
+	binary.BigEndian.PutUint32(data, uint32(len(public)))
+	data = append(data, public...)
+	data = append(data, secret...)
+	return data, nil
+}
+
+// UnmarshalEncryptedKeyShare decrypts a KeyShare encrypted with MarshalEncrypted, and validates it.
+// The errors are those of UnmarshalEncryptedSecretShare and NewKeyShare.
+func UnmarshalEncryptedKeyShare(data, passphrase []byte) (*KeyShare, error) {
+	if len(data) < 4 {
+		return nil, errors.New("eddsa.KeyShare: data is too short")
+	}
+	length := binary.BigEndian.Uint32(data)
+	data = data[4:]
+	if uint64(len(data)) < uint64(length) {
+		return nil, errors.New("eddsa.KeyShare: data is too short")
+	}
+	var public Public
+	if err := public.UnmarshalBinary(data[:length]); err != nil {
+		return nil, fmt.Errorf("eddsa.KeyShare: %w", err)
+	}
+	secret, err := UnmarshalEncryptedSecretShare(data[length:], passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyShare(secret, &public)
+}
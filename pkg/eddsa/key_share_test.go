@@ -0,0 +1,99 @@
+package eddsa
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+)
+
+func TestKeyShare_Validate(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	_, secrets, public := sharedKey(t, partyIDs, 1)
+	_, otherSecrets, otherPublic := sharedKey(t, partyIDs, 1)
+
+	k, err := NewKeyShare(secrets[2], public)
+	require.NoError(t, err)
+	assert.Equal(t, party.ID(2), k.ID())
+	assert.Equal(t, party.Size(1), k.Threshold())
+	assert.True(t, k.GroupKey().Equal(public.GroupKey))
+
+	// The share of another group, or of another party
+	for _, mismatched := range []*KeyShare{
+		{Secret: secrets[2], Public: otherPublic},
+		{Secret: otherSecrets[2], Public: public},
+		{Secret: NewSecretShare(2, &secrets[3].Secret), Public: public},
+	} {
+		err = mismatched.Validate()
+		assert.True(t, errors.Is(err, ErrKeyShareMismatch), err)
+	}
+
+	unknown := NewSecretShare(4, scalar.NewScalarUInt32(42))
+	assert.Error(t, (&KeyShare{Secret: unknown, Public: public}).Validate())
+	assert.Error(t, (&KeyShare{Secret: secrets[1]}).Validate())
+
+	// SecretShare.Public must be [s]•G
+	stale := secrets[1].Copy()
+	stale.Public.Set(&secrets[2].Public)
+	assert.Error(t, (&KeyShare{Secret: stale, Public: public}).Validate())
+}
+
+func TestKeyShare_Marshal(t *testing.T) {
+	_, secrets, public := sharedKey(t, party.IDSlice{1, 2, 3, 4}, 2)
+	k, err := NewKeyShare(secrets[3], public)
+	require.NoError(t, err)
+
+	data, err := k.MarshalBinary()
+	require.NoError(t, err)
+	var fromBinary KeyShare
+	require.NoError(t, fromBinary.UnmarshalBinary(data))
+	assert.True(t, k.Equal(&fromBinary))
+	assert.Error(t, fromBinary.UnmarshalBinary(data[:len(data)-1]))
+	assert.Error(t, fromBinary.UnmarshalBinary(data[:10]))
+
+	data, err = json.Marshal(k)
+	require.NoError(t, err)
+	var fromJSON KeyShare
+	require.NoError(t, json.Unmarshal(data, &fromJSON))
+	assert.True(t, k.Equal(&fromJSON))
+	assert.Error(t, json.Unmarshal([]byte(`{"public":{}}`), &fromJSON))
+
+	passphrase := []byte("correct horse battery staple")
+	data, err = k.marshalEncrypted(passphrase, testArgon2Params)
+	require.NoError(t, err)
+	decrypted, err := UnmarshalEncryptedKeyShare(data, passphrase)
+	require.NoError(t, err)
+	assert.True(t, k.Equal(decrypted))
+	_, err = UnmarshalEncryptedKeyShare(data, []byte("wrong"))
+	assert.True(t, errors.Is(err, ErrWrongPassphrase), err)
+	_, err = UnmarshalEncryptedKeyShare(data[:20], passphrase)
+	assert.Error(t, err)
+}
+
+func TestKeyShare_Marshal_Mismatch(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	_, secrets, public := sharedKey(t, partyIDs, 1)
+	_, _, otherPublic := sharedKey(t, partyIDs, 1)
+	mismatched := &KeyShare{Secret: secrets[1], Public: otherPublic}
+
+	data, err := mismatched.MarshalBinary()
+	require.NoError(t, err)
+	var k KeyShare
+	assert.True(t, errors.Is(k.UnmarshalBinary(data), ErrKeyShareMismatch))
+
+	data, err = json.Marshal(mismatched)
+	require.NoError(t, err)
+	assert.True(t, errors.Is(json.Unmarshal(data, &k), ErrKeyShareMismatch))
+
+	data, err = mismatched.marshalEncrypted([]byte("passphrase"), testArgon2Params)
+	require.NoError(t, err)
+	_, err = UnmarshalEncryptedKeyShare(data, []byte("passphrase"))
+	assert.True(t, errors.Is(err, ErrKeyShareMismatch), err)
+
+	_, err = NewKeyShare(secrets[1], public)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,163 @@
+package eddsa
+
+import (
+	"bytes"
+	"crypto/ed25519"
+
+	"filippo.io/edwards25519"
+	"github.com/taurusgroup/frost-ed25519/pkg/hashing"
+)
+
+// VerifyPolicy is a set of rules for the verification of Ed25519 signatures.
+// Implementations agree on the signatures produced by honest signers, including every signature of this library,
+// but not on signatures crafted with non-canonical encodings or points of small order.
+// When signatures are checked by a third party, such as the consensus rules of a blockchain,
+// the policy must be the one of that verifier.
+//
+// Every policy requires S < ℓ, and computes k = SHA-512(R ∥ A ∥ M) with the encodings of R and A from the signature and key.
+// They differ in the following way, where a point of small order is one whose order divides 8:
+//
+//	                        Standard   Strict     Cofactored   ZIP215
+//	non-canonical A         accepted   rejected   rejected     accepted
+//	non-canonical R         rejected   rejected   rejected     accepted
+//	A or R of small order   accepted   rejected   accepted     accepted
+//	cofactored equation     no         no         yes          yes
+//
+// The cofactorless equation is [S]B = R + [k]A, and the cofactored one [8][S]B = [8]R + [8][k]A,
+// which also accepts signatures whose R or A has a component of small order.
+type VerifyPolicy uint8
+
+const (
+	// PolicyStandard is the policy of crypto/ed25519, which gives the same result as ed25519.Verify.
+	PolicyStandard VerifyPolicy = iota
+
+	// PolicyStrict rejects every encoding and point that honest signers never produce,
+	// like libsodium's crypto_sign_verify_detached.
+	PolicyStrict
+
+	// PolicyCofactored is the cofactored verification of RFC 8032, Section 5.1.7, with canonical encodings.
+	PolicyCofactored
+
+	// PolicyZIP215 is the policy of ZIP 215, used by Zcash and some consensus protocols,
+	// which accepts any encoding which decodes to a point, so that all verifiers agree on the valid signatures
+	// and batch verification gives the same result as individual verification.
+	PolicyZIP215
+)
+
+// String implements fmt.Stringer
+func (p VerifyPolicy) String() string {
+	switch p {
+	case PolicyStandard:
+		return "standard"
+	case PolicyStrict:
+		return "strict"
+	case PolicyCofactored:
+		return "cofactored"
+	case PolicyZIP215:
+		return "ZIP-215"
+	default:
+		return "unknown"
+	}
+}
+
+// Verify reports whether sig is a valid Ed25519 signature of message by pub under the policy p.
+// It returns false for an unknown policy.
+func (p VerifyPolicy) Verify(pub ed25519.PublicKey, message, sig []byte) bool {
+	return p.verify(nil, pub, message, sig)
+}
+
+// VerifyPrehashed is Verify for an Ed25519ph signature of the message whose SHA-512 digest is digest.
+func (p VerifyPolicy) VerifyPrehashed(pub ed25519.PublicKey, digest, sig []byte) bool {
+	if len(digest) != PrehashSize {
+		return false
+	}
+	return p.verify(prehashPrefix, pub, digest, sig)
+}
+
+// VerifyWithContext is Verify for an Ed25519ctx signature with the given context.
+// It returns false if the context is invalid.
+func (p VerifyPolicy) VerifyWithContext(pub ed25519.PublicKey, message, sig, context []byte) bool {
+	if ValidateContext(context) != nil {
+		return false
+	}
+	return p.verify(dom2(0, context), pub, message, sig)
+}
+
+// VerifyStrict is PolicyStrict.Verify.
+func VerifyStrict(pub ed25519.PublicKey, message, sig []byte) bool {
+	return PolicyStrict.Verify(pub, message, sig)
+}
+
+// VerifyCofactored is PolicyCofactored.Verify.
+func VerifyCofactored(pub ed25519.PublicKey, message, sig []byte) bool {
+	return PolicyCofactored.Verify(pub, message, sig)
+}
+
+// VerifyZIP215 is PolicyZIP215.Verify.
+func VerifyZIP215(pub ed25519.PublicKey, message, sig []byte) bool {
+	return PolicyZIP215.Verify(pub, message, sig)
+}
+
+// verify implements Verify, where prefix is the dom2 prefix of the Ed25519 variant.
+func (p VerifyPolicy) verify(prefix []byte, pub ed25519.PublicKey, message, sig []byte) bool {
+	if p > PolicyZIP215 || len(pub) != ed25519.PublicKeySize || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+	canonical := p == PolicyStrict || p == PolicyCofactored
+	cofactored := p == PolicyCofactored || p == PolicyZIP215
+
+	A, ok := decodePoint(pub, canonical)
+	if !ok {
+		return false
+	}
+	R, ok := decodePoint(sig[:32], canonical)
+	if !ok {
+		return false
+	}
+	if p == PolicyStrict && (hasSmallOrder(A) || hasSmallOrder(R)) {
+		return false
+	}
+	S, err := edwards25519.NewScalar().SetCanonicalBytes(sig[32:])
+	if err != nil {
+		return false
+	}
+	k := hashing.ChallengeBytes(prefix, sig[:32], pub, message)
+
+	// R' = [S]B - [k]A
+	var negA, RPrime edwards25519.Point
+	negA.Negate(A)
+	RPrime.VarTimeDoubleScalarBaseMult(k, &negA, S)
+
+	switch {
+	case cofactored:
+		// [8](R' - R) = 0
+		RPrime.Subtract(&RPrime, R)
+		RPrime.MultByCofactor(&RPrime)
+		return RPrime.Equal(edwards25519.NewIdentityPoint()) == 1
+	case p == PolicyStandard:
+		// crypto/ed25519 compares the encodings, so that a non-canonical R is rejected
+		return bytes.Equal(RPrime.Bytes(), sig[:32])
+	default:
+		return RPrime.Equal(R) == 1
+	}
+}
+
+// decodePoint returns the point encoded by data, which accepts the non-canonical encodings described in
+// edwards25519.Point.SetBytes unless canonical is set.
+func decodePoint(data []byte, canonical bool) (*edwards25519.Point, bool) {
+	point, err := new(edwards25519.Point).SetBytes(data)
+	if err != nil {
+		return nil, false
+	}
+	if canonical && !bytes.Equal(point.Bytes(), data) {
+		return nil, false
+	}
+	return point, true
+}
+
+// hasSmallOrder reports whether the order of point divides 8.
+func hasSmallOrder(point *edwards25519.Point) bool {
+	var p edwards25519.Point
+	p.MultByCofactor(point)
+	return p.Equal(edwards25519.NewIdentityPoint()) == 1
+}
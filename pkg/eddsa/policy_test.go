@@ -0,0 +1,149 @@
+package eddsa
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/hashing"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+)
+
+var policies = []VerifyPolicy{PolicyStandard, PolicyStrict, PolicyCofactored, PolicyZIP215}
+
+// policyVector is a signature with the expected result of each policy, in the order of policies.
+type policyVector struct {
+	name              string
+	pub, message, sig []byte
+	accepted          [4]bool
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	data, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return data
+}
+
+func mustPoint(t *testing.T, data []byte) *edwards25519.Point {
+	p, err := new(edwards25519.Point).SetBytes(data)
+	require.NoError(t, err)
+	return p
+}
+
+// forgeSignature returns a message such that k = H(R ∥ A ∥ M) satisfies accept(k mod 8),
+// and S = r + k⋅a, so that [S]B = [r]B + [k][a]B.
+func forgeSignature(t *testing.T, R, A []byte, r, a *edwards25519.Scalar, accept func(kMod8 byte) bool) (message, sig []byte) {
+	for i := 0; ; i++ {
+		message = []byte("policy " + strconv.Itoa(i))
+		k := hashing.ChallengeBytes(nil, R, A, message)
+		if !accept(k.Bytes()[0] & 7) {
+			continue
+		}
+		S := edwards25519.NewScalar().MultiplyAdd(k, a, r)
+		return message, append(append([]byte(nil), R...), S.Bytes()...)
+	}
+}
+
+func policyVectors(t *testing.T) []policyVector {
+	// A point of order 8, and the identity with the non-canonical encodings y = 1 + p and x = -0.
+	order8 := mustPoint(t, mustHex(t, "c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a"))
+	identity := edwards25519.NewIdentityPoint().Bytes()
+	identityNonCanonicalY := mustHex(t, "eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	identityNegativeZero := mustHex(t, "0100000000000000000000000000000000000000000000000000000000000080")
+
+	a, r := scalar.NewScalarRandom(), scalar.NewScalarRandom()
+	zero := edwards25519.NewScalar()
+	A := new(edwards25519.Point).ScalarBaseMult(a).Bytes()
+	R := new(edwards25519.Point).ScalarBaseMult(r).Bytes()
+	any := func(byte) bool { return true }
+
+	var vectors []policyVector
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	message := []byte("message")
+	vectors = append(vectors, policyVector{"valid", pub, message, ed25519.Sign(priv, message), [4]bool{true, true, true, true}})
+
+	sig := ed25519.Sign(priv, message)
+	S := new(big.Int).SetBytes(reverse(sig[32:]))
+	order, _ := new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+	S.Add(S, order)
+	sig = append(sig[:32:32], reverse(S.FillBytes(make([]byte, 32)))...)
+	vectors = append(vectors, policyVector{"S not reduced", pub, message, sig, [4]bool{}})
+
+	// A = [a]B + T, with k ≢ 0 mod 8, so that [S]B - [k]A - R = -[k]T has order 8
+	mixedA := new(edwards25519.Point).Add(mustPoint(t, A), order8).Bytes()
+	message, sig = forgeSignature(t, R, mixedA, r, a, func(k byte) bool { return k != 0 })
+	vectors = append(vectors, policyVector{"mixed-order A", mixedA, message, sig, [4]bool{false, false, true, true}})
+
+	// R = [r]B + T, so that [S]B - [k]A - R = -T
+	mixedR := new(edwards25519.Point).Add(mustPoint(t, R), order8).Bytes()
+	message, sig = forgeSignature(t, mixedR, A, r, a, any)
+	vectors = append(vectors, policyVector{"mixed-order R", A, message, sig, [4]bool{false, false, true, true}})
+
+	// A = T and R = -T with k ≡ 1 mod 8 and S = 0, so that R + [k]A = 0 = [S]B
+	smallA := order8.Bytes()
+	smallR := new(edwards25519.Point).Negate(order8).Bytes()
+	message, sig = forgeSignature(t, smallR, smallA, zero, zero, func(k byte) bool { return k == 1 })
+	vectors = append(vectors, policyVector{"small-order A and R", smallA, message, sig, [4]bool{true, false, true, true}})
+
+	// R is the identity encoded with y = 1 + p, and S = k⋅a
+	message, sig = forgeSignature(t, identityNonCanonicalY, A, zero, a, any)
+	vectors = append(vectors, policyVector{"non-canonical R", A, message, sig, [4]bool{false, false, false, true}})
+
+	// A is the identity encoded with x = -0, R the identity and S = 0
+	message, sig = forgeSignature(t, identity, identityNegativeZero, zero, zero, any)
+	vectors = append(vectors, policyVector{"non-canonical A", identityNegativeZero, message, sig, [4]bool{true, false, false, true}})
+
+	return vectors
+}
+
+// reverse returns a reversed copy of b, to convert between little and big endian.
+func reverse(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[len(b)-1-i] = b[i]
+	}
+	return out
+}
+
+func TestVerifyPolicy(t *testing.T) {
+	order8 := mustPoint(t, mustHex(t, "c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a"))
+	require.True(t, hasSmallOrder(order8))
+	var order4 edwards25519.Point
+	order4.Add(order8, order8).Add(&order4, &order4)
+	require.Equal(t, 0, order4.Equal(edwards25519.NewIdentityPoint()), "the point should have order 8")
+
+	for _, v := range policyVectors(t) {
+		for i, policy := range policies {
+			assert.Equal(t, v.accepted[i], policy.Verify(v.pub, v.message, v.sig), "%s: %v", v.name, policy)
+		}
+		assert.Equal(t, ed25519.Verify(v.pub, v.message, v.sig), PolicyStandard.Verify(v.pub, v.message, v.sig),
+			"%s: PolicyStandard should match crypto/ed25519", v.name)
+		assert.Equal(t, v.accepted[1], VerifyStrict(v.pub, v.message, v.sig), v.name)
+		assert.Equal(t, v.accepted[2], VerifyCofactored(v.pub, v.message, v.sig), v.name)
+		assert.Equal(t, v.accepted[3], VerifyZIP215(v.pub, v.message, v.sig), v.name)
+	}
+}
+
+func TestVerifyPolicy_Variants(t *testing.T) {
+	sig, pk, err := generateSignature()
+	require.NoError(t, err)
+	pub, sigBytes := pk.ToEd25519(), sig.ToEd25519()
+	for _, policy := range policies {
+		assert.True(t, policy.Verify(pub, []byte(sampleMessage), sigBytes), policy.String())
+		assert.False(t, policy.Verify(pub, []byte("other message"), sigBytes), policy.String())
+		assert.False(t, policy.Verify(pub[:31], []byte(sampleMessage), sigBytes), policy.String())
+		assert.False(t, policy.Verify(pub, []byte(sampleMessage), sigBytes[:63]), policy.String())
+		assert.False(t, policy.VerifyPrehashed(pub, []byte(sampleMessage), sigBytes), policy.String())
+		assert.False(t, policy.VerifyWithContext(pub, []byte(sampleMessage), sigBytes, nil), policy.String())
+	}
+	assert.False(t, VerifyPolicy(42).Verify(pub, []byte(sampleMessage), sigBytes))
+	assert.Equal(t, "unknown", VerifyPolicy(42).String())
+}
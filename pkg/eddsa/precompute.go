@@ -0,0 +1,169 @@
+package eddsa
+
+import (
+	"container/list"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// Precomputed holds the values of a Public which only depend on a set of signers:
+// the Lagrange coefficients 𝛌ᵢ of the signers, and their public shares multiplied by them 𝛌ᵢ•Aᵢ,
+// which are the points against which their signature shares are verified.
+//
+// It is immutable, and can be shared between concurrent signing sessions and verifications with the same signers.
+type Precomputed struct {
+	signers party.IDSlice
+	// key identifies the group key and the public shares of the signers p was computed from
+	key          [32]byte
+	coefficients map[party.ID]*ristretto.Scalar
+	shares       map[party.ID]*ristretto.Element
+}
+
+// Precompute returns the Precomputed values of s for signers,
+// or an error if signers is empty, or contains a party without a share in s.
+func (s *Public) Precompute(signers party.IDSlice) (*Precomputed, error) {
+	signers = party.NewIDSlice(signers)
+	if !signers.IsSubsetOf(s.PartyIDs) {
+		return nil, fmt.Errorf("eddsa.Precompute: parties %v do not have a share", signers.Difference(s.PartyIDs))
+	}
+	coefficients, err := signers.LagrangeAll()
+	if err != nil {
+		return nil, fmt.Errorf("eddsa.Precompute: %w", err)
+	}
+	p := &Precomputed{
+		signers:      signers,
+		key:          precomputeCacheKey(s, signers),
+		coefficients: coefficients,
+		shares:       make(map[party.ID]*ristretto.Element, len(signers)),
+	}
+	for _, id := range signers {
+		p.shares[id] = new(ristretto.Element).ScalarMult(coefficients[id], s.Shares[id])
+	}
+	return p, nil
+}
+
+// Signers returns a copy of the signers p was computed for.
+func (p *Precomputed) Signers() party.IDSlice {
+	return p.signers.Copy()
+}
+
+// Matches reports whether p was computed for signers and a Public with the same group key
+// and public shares of the signers as public.
+func (p *Precomputed) Matches(signers party.IDSlice, public *Public) bool {
+	signers = party.NewIDSlice(signers)
+	if !p.signers.Equal(signers) || !signers.IsSubsetOf(public.PartyIDs) {
+		return false
+	}
+	return precomputeCacheKey(public, signers) == p.key
+}
+
+// Coefficient returns a copy of the Lagrange coefficient 𝛌ᵢ of the signer id, or nil if id is not a signer.
+func (p *Precomputed) Coefficient(id party.ID) *ristretto.Scalar {
+	c, ok := p.coefficients[id]
+	if !ok {
+		return nil
+	}
+	return ristretto.NewScalar().Set(c)
+}
+
+// Share returns a copy of 𝛌ᵢ•Aᵢ for the signer id, or nil if id is not a signer.
+func (p *Precomputed) Share(id party.ID) *ristretto.Element {
+	share, ok := p.shares[id]
+	if !ok {
+		return nil
+	}
+	return ristretto.NewIdentityElement().Set(share)
+}
+
+// ErrInvalidCacheSize is returned by NewPrecomputeCache when the size is not positive.
+var ErrInvalidCacheSize = errors.New("the cache size must be positive")
+
+// PrecomputeCache keeps the most recently used Precomputed values, so that the setup of signing sessions
+// between the same signers is only done once. It is safe for concurrent use.
+type PrecomputeCache struct {
+	mtx     sync.Mutex
+	size    int
+	entries *list.List
+	index   map[[32]byte]*list.Element
+}
+
+type precomputeCacheEntry struct {
+	key   [32]byte
+	value *Precomputed
+}
+
+// NewPrecomputeCache returns a PrecomputeCache holding at most size values.
+func NewPrecomputeCache(size int) (*PrecomputeCache, error) {
+	if size <= 0 {
+		return nil, ErrInvalidCacheSize
+	}
+	return &PrecomputeCache{
+		size:    size,
+		entries: list.New(),
+		index:   make(map[[32]byte]*list.Element, size),
+	}, nil
+}
+
+// Precompute returns public.Precompute(signers), computing it only if it is not already in the cache.
+// Values are identified by the signers, the group key of public and the public shares of the signers.
+func (c *PrecomputeCache) Precompute(public *Public, signers party.IDSlice) (*Precomputed, error) {
+	signers = party.NewIDSlice(signers)
+	if !signers.IsSubsetOf(public.PartyIDs) {
+		// public.Precompute returns the error
+		return public.Precompute(signers)
+	}
+	key := precomputeCacheKey(public, signers)
+
+	c.mtx.Lock()
+	if e, ok := c.index[key]; ok {
+		c.entries.MoveToFront(e)
+		c.mtx.Unlock()
+		return e.Value.(*precomputeCacheEntry).value, nil
+	}
+	c.mtx.Unlock()
+
+	// The computation is done without the lock, so it may happen concurrently for the same key.
+	p, err := public.Precompute(signers)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if e, ok := c.index[key]; ok {
+		c.entries.MoveToFront(e)
+		return e.Value.(*precomputeCacheEntry).value, nil
+	}
+	c.index[key] = c.entries.PushFront(&precomputeCacheEntry{key: key, value: p})
+	if c.entries.Len() > c.size {
+		oldest := c.entries.Back()
+		c.entries.Remove(oldest)
+		delete(c.index, oldest.Value.(*precomputeCacheEntry).key)
+	}
+	return p, nil
+}
+
+// Len returns the number of values in the cache.
+func (c *PrecomputeCache) Len() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.entries.Len()
+}
+
+// precomputeCacheKey returns SHA-512/256(Y ∥ ID₁ ∥ A₁ ∥ ... ∥ IDₙ ∥ Aₙ), where Y is the encoding of the group key,
+// and Aᵢ the public share of the signer IDᵢ.
+// signers must be a subset of public.PartyIDs.
+func precomputeCacheKey(public *Public, signers party.IDSlice) [32]byte {
+	buffer := make([]byte, 0, 32+len(signers)*(party.IDByteSize+32))
+	buffer = append(buffer, public.GroupKey.pk.Bytes()...)
+	for _, id := range signers {
+		buffer = append(buffer, id.Bytes()...)
+		buffer = append(buffer, public.Shares[id].Bytes()...)
+	}
+	return sha512.Sum512_256(buffer)
+}
@@ -0,0 +1,105 @@
+package eddsa
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+func TestPublic_Precompute(t *testing.T) {
+	public := goldenPublic(t)
+	signers := party.IDSlice{3, 1}
+
+	p, err := public.Precompute(signers)
+	require.NoError(t, err)
+	assert.True(t, p.Signers().Equal(party.IDSlice{1, 3}))
+	assert.True(t, p.Matches(party.IDSlice{1, 3}, public))
+	assert.False(t, p.Matches(party.IDSlice{1, 2}, public))
+	assert.False(t, p.Matches(party.IDSlice{1, 3}, otherShare(public, 1)))
+	tweaked := *public
+	tweaked.GroupKey = public.GroupKey.Tweak(scalar.NewScalarUInt32(1))
+	assert.False(t, p.Matches(party.IDSlice{1, 3}, &tweaked))
+	// The share of a party which is not a signer does not matter
+	assert.True(t, p.Matches(party.IDSlice{1, 3}, otherShare(public, 2)))
+
+	// ∑ 𝛌ᵢ•Aᵢ is the group key
+	sum := ristretto.NewIdentityElement()
+	for _, id := range p.Signers() {
+		coefficient, err := party.IDSlice{1, 3}.Lagrange(id)
+		require.NoError(t, err)
+		assert.Equal(t, 1, p.Coefficient(id).Equal(coefficient))
+		expected := new(ristretto.Element).ScalarMult(coefficient, public.Shares[id])
+		assert.Equal(t, 1, p.Share(id).Equal(expected))
+		sum.Add(sum, p.Share(id))
+	}
+	assert.True(t, NewPublicKeyFromPoint(sum).Equal(public.GroupKey))
+	assert.Nil(t, p.Coefficient(2))
+	assert.Nil(t, p.Share(2))
+
+	// The returned values are copies
+	p.Share(1).Set(ristretto.NewIdentityElement())
+	p.Coefficient(1).Set(ristretto.NewScalar())
+	assert.Equal(t, 0, p.Share(1).Equal(ristretto.NewIdentityElement()))
+
+	_, err = public.Precompute(party.IDSlice{1, 4})
+	assert.Error(t, err)
+	_, err = public.Precompute(nil)
+	assert.Error(t, err)
+}
+
+func TestPrecomputeCache(t *testing.T) {
+	public := goldenPublic(t)
+
+	_, err := NewPrecomputeCache(0)
+	assert.True(t, errors.Is(err, ErrInvalidCacheSize))
+
+	cache, err := NewPrecomputeCache(2)
+	require.NoError(t, err)
+
+	p12, err := cache.Precompute(public, party.IDSlice{1, 2})
+	require.NoError(t, err)
+	again, err := cache.Precompute(public, party.IDSlice{2, 1})
+	require.NoError(t, err)
+	assert.Same(t, p12, again, "the cached value should be returned")
+
+	p13, err := cache.Precompute(public, party.IDSlice{1, 3})
+	require.NoError(t, err)
+	// {1, 2} was used more recently than {1, 3}, so {1, 3} is evicted
+	_, err = cache.Precompute(public, party.IDSlice{1, 2})
+	require.NoError(t, err)
+	_, err = cache.Precompute(public, party.IDSlice{2, 3})
+	require.NoError(t, err)
+	assert.Equal(t, 2, cache.Len())
+
+	again, err = cache.Precompute(public, party.IDSlice{1, 3})
+	require.NoError(t, err)
+	assert.NotSame(t, p13, again, "the least recently used value should have been evicted")
+
+	_, err = cache.Precompute(public, party.IDSlice{1, 4})
+	assert.Error(t, err)
+	assert.Equal(t, 2, cache.Len())
+
+	// A Public with the same group key but another share of a signer has its own values
+	other := otherShare(public, 1)
+	p13Other, err := cache.Precompute(other, party.IDSlice{1, 3})
+	require.NoError(t, err)
+	assert.NotSame(t, again, p13Other)
+	assert.True(t, p13Other.Matches(party.IDSlice{1, 3}, other))
+	assert.False(t, p13Other.Matches(party.IDSlice{1, 3}, public))
+}
+
+// otherShare returns a copy of public with the same group key, in which the share of id is replaced.
+func otherShare(public *Public, id party.ID) *Public {
+	other := *public
+	other.Shares = make(map[party.ID]*ristretto.Element, len(public.Shares))
+	for i, share := range public.Shares {
+		other.Shares[i] = share
+	}
+	other.Shares[id] = new(ristretto.Element).Add(public.Shares[id], ristretto.NewGeneratorElement())
+	return &other
+}
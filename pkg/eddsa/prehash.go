@@ -0,0 +1,28 @@
+package eddsa
+
+import (
+	"crypto/sha512"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// PrehashSize is the size of the SHA-512 digest of the message signed in the Ed25519ph mode.
+const PrehashSize = sha512.Size
+
+// prehashPrefix is dom2(1, ""), the prefix of Ed25519ph with an empty context.
+var prehashPrefix = dom2(1, nil)
+
+// ComputeChallengePrehashed computes the challenge of Ed25519ph H(dom2(1, "") ∥ R ∥ A ∥ PH(M)),
+// where digest = PH(M) is the SHA-512 digest of the message.
+// The resulting signatures are accepted by ed25519.VerifyWithOptions with ed25519.Options{Hash: crypto.SHA512}.
+func ComputeChallengePrehashed(R *ristretto.Element, groupKey *PublicKey, digest []byte) *ristretto.Scalar {
+	return computeChallengeWithPrefix(prehashPrefix, R, groupKey.ToEd25519(), digest)
+}
+
+// VerifyPrehashed reports whether sig is a valid Ed25519ph signature by pk of the message whose SHA-512 digest is digest.
+func (pk *PublicKey) VerifyPrehashed(digest []byte, sig *Signature) bool {
+	if len(digest) != PrehashSize {
+		return false
+	}
+	return pk.verifyChallenge(ComputeChallengePrehashed(&sig.R, pk, digest), sig)
+}
@@ -0,0 +1,56 @@
+package eddsa
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// RFC 8032, Section 7.3, TEST abc
+const (
+	rfc8032PhSecret    = "833fe62409237b9d62ec77587520911e9a759cec1d19755b7da901b96dca3d42"
+	rfc8032PhPublic    = "ec172b93ad5e563bf4932c70e1245034c35467ef2efd4d64ebf819683467e2bf"
+	rfc8032PhMessage   = "abc"
+	rfc8032PhSignature = "98a70222f0b8121aa9d30f813d683f809e462b469c7ff87639499bb94e6dae41" +
+		"31f85042463c2a355a2003d062adf5aaa10b8c61e636062aaad11c2a26083406"
+)
+
+func TestVerifyPrehashed_RFC8032(t *testing.T) {
+	publicBytes, _ := hex.DecodeString(rfc8032PhPublic)
+	pk, err := NewPublicKeyFromEd25519(publicBytes)
+	require.NoError(t, err)
+
+	sigBytes, _ := hex.DecodeString(rfc8032PhSignature)
+	var sig Signature
+	_, err = sig.R.SetEd25519Bytes(sigBytes[:32])
+	require.NoError(t, err)
+	_, err = sig.S.SetCanonicalBytes(sigBytes[32:])
+	require.NoError(t, err)
+	assert.Equal(t, sigBytes, sig.ToEd25519())
+
+	digest := sha512.Sum512([]byte(rfc8032PhMessage))
+	assert.True(t, pk.VerifyPrehashed(digest[:], &sig))
+	assert.False(t, pk.Verify(digest[:], &sig), "an Ed25519ph signature is not a valid Ed25519 signature")
+	assert.False(t, pk.Verify([]byte(rfc8032PhMessage), &sig))
+	assert.False(t, pk.VerifyPrehashed(digest[:32], &sig))
+	other := sha512.Sum512([]byte("abd"))
+	assert.False(t, pk.VerifyPrehashed(other[:], &sig))
+
+	// The signature is deterministic, so it can be recomputed from the secret key
+	seed, _ := hex.DecodeString(rfc8032PhSecret)
+	expanded := sha512.Sum512(seed)
+	var secret ristretto.Scalar
+	_, _ = secret.SetBytesWithClamping(expanded[:32])
+	prefix := []byte("SigEd25519 no Ed25519 collisions\x01\x00")
+	nonceDigest := sha512.Sum512(append(append(append([]byte(nil), prefix...), expanded[32:]...), digest[:]...))
+	var r ristretto.Scalar
+	_, _ = r.SetUniformBytes(nonceDigest[:])
+	var recomputed Signature
+	recomputed.R.ScalarBaseMult(&r)
+	recomputed.S.MultiplyAdd(ComputeChallengePrehashed(&recomputed.R, pk, digest[:]), &secret, &r)
+	assert.Equal(t, sigBytes, recomputed.ToEd25519())
+}
@@ -1,8 +1,9 @@
 package eddsa
 
 import (
-	"encoding/json"
+	"crypto/ed25519"
 	"errors"
+	"fmt"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
 	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
@@ -14,7 +15,8 @@ type Public struct {
 	// PartyIDs is a party.Set that represents all parties with a share.
 	PartyIDs party.IDSlice
 
-	// Threshold returns the integer which defines the maximum number of parties that may be corrupted
+	// Threshold is the maximum number of parties that may be corrupted, so that MinSigners() = Threshold+1
+	// parties are required to sign.
 	Threshold party.Size
 
 	// Shares maps ID's to the threshold Shamir shares of the public GroupKey
@@ -26,6 +28,8 @@ type Public struct {
 }
 
 // NewPublic creates a Public structure given a map of public key shares as ristretto.Element, the threshold used.
+// The group key is the interpolation of all the shares, and their consistency with the threshold is not checked,
+// see Validate.
 func NewPublic(shares map[party.ID]*ristretto.Element, threshold party.Size) (*Public, error) {
 	n := len(shares)
 	IDs := make([]party.ID, 0, n)
@@ -49,6 +53,39 @@ func NewPublic(shares map[party.ID]*ristretto.Element, threshold party.Size) (*P
 	return s, nil
 }
 
+// MinSigners returns Threshold+1, the number of parties required to sign.
+func (s *Public) MinSigners() party.Size {
+	return party.MinSigners(s.Threshold)
+}
+
+// PublicFromEd25519 returns a Public which only contains the group key key, without any share.
+// It can be used to verify signatures of a group whose key generation output is not available,
+// but not to start a signing protocol, nor be encoded with MarshalBinary.
+func PublicFromEd25519(key ed25519.PublicKey) (*Public, error) {
+	groupKey, err := NewPublicKeyFromEd25519(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Public{
+		Shares:   map[party.ID]*ristretto.Element{},
+		GroupKey: groupKey,
+	}, nil
+}
+
+// GroupKeyEd25519 returns the group key as an ed25519.PublicKey.
+func (s *Public) GroupKeyEd25519() ed25519.PublicKey {
+	return s.GroupKey.ToEd25519()
+}
+
+// Share returns a copy of the public share of party id, or an error if id has no share.
+func (s *Public) Share(id party.ID) (*ristretto.Element, error) {
+	share, ok := s.Shares[id]
+	if !ok || share == nil {
+		return nil, fmt.Errorf("PublicShares: no share for party %d", id)
+	}
+	return new(ristretto.Element).Set(share), nil
+}
+
 // computeGroupKey computes the interpolation of the shares with regards to the partyIDs
 func computeGroupKey(partyIDs party.IDSlice, shares map[party.ID]*ristretto.Element) *PublicKey {
 	var tmp ristretto.Element
@@ -62,41 +99,188 @@ func computeGroupKey(partyIDs party.IDSlice, shares map[party.ID]*ristretto.Elem
 	return NewPublicKeyFromPoint(groupKey)
 }
 
-type sharesJSON struct {
-	Threshold int                             `json:"t"`
-	GroupKey  *PublicKey                      `json:"groupkey"`
-	Shares    map[party.ID]*ristretto.Element `json:"shares"`
-}
+// publicBinaryVersion is the first byte of the binary encoding of Public.
+const publicBinaryVersion = 1
 
-// MarshalJSON implements the json.Marshaler interface.
-func (s *Public) MarshalJSON() ([]byte, error) {
-	return json.Marshal(sharesJSON{
-		Threshold: int(s.Threshold),
-		Shares:    s.Shares,
-		GroupKey:  s.GroupKey,
-	})
+// publicBinaryHeaderSize is the size of version ∥ threshold ∥ n in the binary encoding of Public.
+const publicBinaryHeaderSize = 1 + 2*party.IDByteSize
+
+// PublicSizeBytes returns the size of the binary encoding of a Public with n parties,
+// so that callers can preallocate it.
+func PublicSizeBytes(n party.Size) int {
+	return publicBinaryHeaderSize + int(n)*(party.IDByteSize+32) + 32
 }
 
-// UnmarshalJSON implements the json.Unmarshaler interface.
-func (s *Public) UnmarshalJSON(data []byte) error {
-	var out sharesJSON
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The encoding is
+//
+//	version ∥ threshold ∥ n ∥ (ID ∥ share)... ∥ groupKey
+//
+// where version is a single byte, threshold, n and the IDs are 4 bytes big endian,
+// the n pairs are sorted by ID, and the shares and group key are 32 byte Ristretto encodings.
+// Its length is PublicSizeBytes(n).
+// The encoding only depends on the IDs and shares, so parties holding the same key produce the same bytes.
+func (s *Public) MarshalBinary() ([]byte, error) {
+	if len(s.PartyIDs) == 0 {
+		return nil, errors.New("PublicShares: no shares")
+	}
+	if s.GroupKey == nil {
+		return nil, errors.New("PublicShares: missing group key")
+	}
+	partyIDs := party.NewIDSlice(s.PartyIDs)
+	data := make([]byte, 0, PublicSizeBytes(partyIDs.N()))
+	data = append(data, publicBinaryVersion)
+	data = append(data, s.Threshold.Bytes()...)
+	data = append(data, partyIDs.N().Bytes()...)
+	for i, id := range partyIDs {
+		if id == 0 {
+			return nil, fmt.Errorf("PublicShares: %w", party.ErrZeroID)
+		}
+		if i > 0 && partyIDs[i-1] == id {
+			return nil, fmt.Errorf("PublicShares: duplicate party %d", id)
+		}
+		share, ok := s.Shares[id]
+		if !ok || share == nil {
+			return nil, fmt.Errorf("PublicShares: missing share for party %d", id)
+		}
+		data = append(data, id.Bytes()...)
+		data = append(data, share.Bytes()...)
+	}
+	data = append(data, s.GroupKey.pk.Bytes()...)
+	return data, nil
+}
 
-	if err := json.Unmarshal(data, &out); err != nil {
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// It is the same as UnmarshalPublicBinary with validate set to true.
+func (s *Public) UnmarshalBinary(data []byte) error {
+	public, err := UnmarshalPublicBinary(data, true)
+	if err != nil {
 		return err
 	}
+	*s = *public
+	return nil
+}
+
+// UnmarshalPublicBinary decodes a Public encoded with MarshalBinary.
+// The IDs must be non-zero and strictly increasing, the threshold must be smaller than n,
+// and the shares and group key must be canonical encodings.
+// The errors name the offending field, such as the party whose share is invalid.
+//
+// When validate is true, the decoded Public is checked with Validate, as in UnmarshalPublicJSON,
+// so that every set of threshold+1 parties interpolates the group key.
+// Otherwise, the group key is trusted, and no interpolation is computed.
+//
+// Data in the encoding used before the version byte, without the group key, is also accepted.
+// Its first byte is the one of the threshold, which is always 0.
+func UnmarshalPublicBinary(data []byte, validate bool) (public *Public, err error) {
+	defer recoverPanic(&err)
 
-	newS, err := NewPublic(out.Shares, party.Size(out.Threshold))
+	if len(data) == 0 {
+		return nil, errors.New("PublicShares: data is too short")
+	}
+	switch data[0] {
+	case 0:
+		public, err = unmarshalLegacyPublicBinary(data)
+	case publicBinaryVersion:
+		public, err = unmarshalPublicBinary(data)
+	default:
+		return nil, fmt.Errorf("PublicShares: unsupported binary version %d", data[0])
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	computedGroupKey := computeGroupKey(newS.PartyIDs, out.Shares)
-	if !computedGroupKey.Equal(out.GroupKey) {
-		return errors.New("PublicShares: inconsistent group key")
+	if validate {
+		if err = public.Validate(); err != nil {
+			return nil, err
+		}
 	}
+	return public, nil
+}
 
-	*s = *newS
+// unmarshalPublicBinary decodes the encoding of MarshalBinary.
+func unmarshalPublicBinary(data []byte) (*Public, error) {
+	if len(data) < publicBinaryHeaderSize {
+		return nil, errors.New("PublicShares: data is too short")
+	}
+	threshold, _ := party.FromBytes(data[1:])
+	n, _ := party.FromBytes(data[1+party.IDByteSize:])
+	if size := uint64(publicBinaryHeaderSize) + uint64(n)*(party.IDByteSize+32) + 32; uint64(len(data)) != size {
+		return nil, fmt.Errorf("PublicShares: data has %d bytes, but %d shares need %d", len(data), n, size)
+	}
+	data = data[publicBinaryHeaderSize:]
+	if n == 0 {
+		return nil, errors.New("PublicShares: no shares")
+	}
+	if uint64(threshold)+1 > uint64(n) {
+		return nil, fmt.Errorf("PublicShares: threshold %d requires more than %d parties", threshold, n)
+	}
 
-	return nil
+	partyIDs := make(party.IDSlice, 0, n)
+	shares := make(map[party.ID]*ristretto.Element, n)
+	for i := 0; i < int(n); i++ {
+		id, _ := party.FromBytes(data)
+		if id == 0 {
+			return nil, fmt.Errorf("PublicShares: ID of share %d: %w", i, party.ErrZeroID)
+		}
+		if i > 0 && partyIDs[i-1] >= id {
+			return nil, fmt.Errorf("PublicShares: ID of share %d: party %d is not greater than %d", i, id, partyIDs[i-1])
+		}
+		var share ristretto.Element
+		if _, err := share.SetCanonicalBytes(data[party.IDByteSize : party.IDByteSize+32]); err != nil {
+			return nil, fmt.Errorf("PublicShares: share of party %d: %w", id, err)
+		}
+		partyIDs = append(partyIDs, id)
+		shares[id] = &share
+		data = data[party.IDByteSize+32:]
+	}
+
+	var groupKey PublicKey
+	if _, err := groupKey.pk.SetCanonicalBytes(data); err != nil {
+		return nil, fmt.Errorf("PublicShares: group key: %w", err)
+	}
+	return &Public{
+		PartyIDs:  partyIDs,
+		Threshold: party.Size(threshold),
+		Shares:    shares,
+		GroupKey:  &groupKey,
+	}, nil
+}
+
+// unmarshalLegacyPublicBinary decodes the encoding
+//
+//	threshold ∥ partyIDs ∥ share...
+//
+// used before the version byte, where partyIDs is encoded by party.IDSlice.MarshalBinary and the shares are sorted by ID.
+// The group key is computed from the shares.
+func unmarshalLegacyPublicBinary(data []byte) (*Public, error) {
+	if len(data) < 2*party.IDByteSize {
+		return nil, errors.New("PublicShares: data is too short")
+	}
+	threshold, _ := party.FromBytes(data)
+	size, _ := party.FromBytes(data[party.IDByteSize:])
+	data = data[party.IDByteSize:]
+	idsLength := (uint64(size) + 1) * party.IDByteSize
+	if uint64(len(data)) != idsLength+uint64(size)*32 {
+		return nil, errors.New("PublicShares: data is not the right size")
+	}
+
+	var partyIDs party.IDSlice
+	if err := partyIDs.UnmarshalBinary(data[:idsLength]); err != nil {
+		return nil, fmt.Errorf("PublicShares: %w", err)
+	}
+	data = data[idsLength:]
+
+	shares := make(map[party.ID]*ristretto.Element, len(partyIDs))
+	for _, id := range partyIDs {
+		var share ristretto.Element
+		if _, err := share.SetCanonicalBytes(data[:32]); err != nil {
+			return nil, fmt.Errorf("PublicShares: share of party %d: %w", id, err)
+		}
+		shares[id] = &share
+		data = data[32:]
+	}
+
+	return NewPublic(shares, party.Size(threshold))
 }
 
 func (s *Public) Equal(s2 *Public) bool {
@@ -126,3 +310,17 @@ func (s *Public) Equal(s2 *Public) bool {
 
 	return true
 }
+
+// Copy returns a deep copy of s.
+func (s *Public) Copy() *Public {
+	shares := make(map[party.ID]*ristretto.Element, len(s.Shares))
+	for id, share := range s.Shares {
+		shares[id] = new(ristretto.Element).Set(share)
+	}
+	return &Public{
+		PartyIDs:  s.PartyIDs.Copy(),
+		Threshold: s.Threshold,
+		Shares:    shares,
+		GroupKey:  NewPublicKeyFromPoint(&s.GroupKey.pk),
+	}
+}
@@ -0,0 +1,147 @@
+package eddsa
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// The group key can be exported in the formats of other tools, so that they can verify the signatures of the group.
+// MarshalSSH returns an OpenSSH authorized_keys line, whose base64 blob is the encoding of RFC 8709
+//
+//	string "ssh-ed25519" ∥ string key
+//
+// where a string is encoded as its length in 4 bytes big endian, followed by its bytes.
+// MarshalPKIX returns a PEM block "PUBLIC KEY" containing the SubjectPublicKeyInfo of RFC 8410, as written by openssl.
+// Both are implemented with the standard library, and match the encodings of golang.org/x/crypto/ssh and crypto/x509.
+// Keys parsed from these formats only contain the group key, like those returned by PublicFromEd25519.
+
+const (
+	sshKeyAlgoEd25519 = "ssh-ed25519"
+	pemTypePublicKey  = "PUBLIC KEY"
+)
+
+// ErrUnsupportedKey is returned when parsing a key which is not an Ed25519 key.
+var ErrUnsupportedKey = errors.New("the key is not an Ed25519 key")
+
+// MarshalSSH returns the group key as a line of an OpenSSH authorized_keys file, ending with a newline.
+func (s *Public) MarshalSSH() []byte {
+	blob := appendSSHString(nil, []byte(sshKeyAlgoEd25519))
+	blob = appendSSHString(blob, s.GroupKeyEd25519())
+
+	line := make([]byte, 0, len(sshKeyAlgoEd25519)+2+base64.StdEncoding.EncodedLen(len(blob)))
+	line = append(line, sshKeyAlgoEd25519...)
+	line = append(line, ' ')
+	line = append(line, base64.StdEncoding.EncodeToString(blob)...)
+	return append(line, '\n')
+}
+
+// ParseSSH parses an Ed25519 key in the OpenSSH authorized_keys format, such as the output of MarshalSSH
+// or the content of id_ed25519.pub, and returns a Public which only contains the group key.
+// The comment following the key is ignored, but the line must not start with options.
+func ParseSSH(line []byte) (*Public, error) {
+	fields := bytes.Fields(line)
+	if len(fields) < 2 {
+		return nil, errors.New("eddsa.ParseSSH: missing key")
+	}
+	if string(fields[0]) != sshKeyAlgoEd25519 {
+		return nil, fmt.Errorf("eddsa.ParseSSH: %w: %q", ErrUnsupportedKey, fields[0])
+	}
+	blob, err := base64.StdEncoding.DecodeString(string(fields[1]))
+	if err != nil {
+		return nil, fmt.Errorf("eddsa.ParseSSH: %w", err)
+	}
+	algo, blob, err := readSSHString(blob)
+	if err != nil {
+		return nil, fmt.Errorf("eddsa.ParseSSH: %w", err)
+	}
+	if string(algo) != sshKeyAlgoEd25519 {
+		return nil, fmt.Errorf("eddsa.ParseSSH: %w: %q", ErrUnsupportedKey, algo)
+	}
+	key, blob, err := readSSHString(blob)
+	if err != nil || len(blob) != 0 || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("eddsa.ParseSSH: invalid key encoding")
+	}
+	return PublicFromEd25519(key)
+}
+
+// MarshalSSHSignature returns sig in the SSH wire format of RFC 8709, Section 6,
+// which is the Blob of an ssh.Signature with the format "ssh-ed25519".
+func MarshalSSHSignature(sig *Signature) []byte {
+	data := appendSSHString(nil, []byte(sshKeyAlgoEd25519))
+	return appendSSHString(data, sig.ToEd25519())
+}
+
+// ParseSSHSignature parses a signature in the SSH wire format, such as the output of MarshalSSHSignature.
+func ParseSSHSignature(data []byte) (*Signature, error) {
+	algo, data, err := readSSHString(data)
+	if err != nil {
+		return nil, fmt.Errorf("eddsa.ParseSSHSignature: %w", err)
+	}
+	if string(algo) != sshKeyAlgoEd25519 {
+		return nil, fmt.Errorf("eddsa.ParseSSHSignature: %w: %q", ErrUnsupportedKey, algo)
+	}
+	blob, data, err := readSSHString(data)
+	if err != nil || len(data) != 0 || len(blob) != MessageLengthSig {
+		return nil, errors.New("eddsa.ParseSSHSignature: invalid signature encoding")
+	}
+	var sig Signature
+	if err = sig.setEd25519(blob); err != nil {
+		return nil, fmt.Errorf("eddsa.ParseSSHSignature: %w", err)
+	}
+	return &sig, nil
+}
+
+// MarshalPKIX returns the group key as a PEM block of type "PUBLIC KEY",
+// containing its PKIX encoding given by x509.MarshalPKIXPublicKey.
+func (s *Public) MarshalPKIX() ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(s.GroupKeyEd25519())
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypePublicKey, Bytes: der}), nil
+}
+
+// ParsePKIX parses the first PEM block of data, which must be an Ed25519 key of type "PUBLIC KEY",
+// such as the output of MarshalPKIX or of `openssl pkey -pubout`, and returns a Public which only contains the group key.
+func ParsePKIX(data []byte) (*Public, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != pemTypePublicKey {
+		return nil, errors.New("eddsa.ParsePKIX: no PUBLIC KEY block found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("eddsa.ParsePKIX: %w", err)
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("eddsa.ParsePKIX: %w: %T", ErrUnsupportedKey, key)
+	}
+	return PublicFromEd25519(edKey)
+}
+
+// appendSSHString appends s to data as an SSH string.
+func appendSSHString(data, s []byte) []byte {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(s)))
+	data = append(data, length[:]...)
+	return append(data, s...)
+}
+
+// readSSHString returns the SSH string at the start of data, and the rest of data.
+func readSSHString(data []byte) ([]byte, []byte, error) {
+	if len(data) < 4 {
+		return nil, nil, errors.New("string is too short")
+	}
+	length := binary.BigEndian.Uint32(data)
+	data = data[4:]
+	if uint64(len(data)) < uint64(length) {
+		return nil, nil, errors.New("string is too short")
+	}
+	return data[:length], data[length:], nil
+}
@@ -0,0 +1,123 @@
+package eddsa
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The vectors were generated by ssh-keygen -t ed25519 and openssl pkey -pubout for the same key,
+// and the signature by ssh-keygen -Y sign -n file of the message "FROST-Ed25519".
+const (
+	sshVectorKey       = "477ba6a9b53328ddd3765d624514ad70888788fde80c2aa337feaceed95d7484"
+	sshVectorPublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEd7pqm1Myjd03ZdYkUUrXCIh4j96Awqozf+rO7ZXXSE frost\n"
+	sshVectorSignature = "0000000b7373682d6564323535313900000040ced39d0a4d601b576a9b6f01afbc3e99" +
+		"7131ddb967ee61c67536d480cd651e42d7c66b11f82fcf2b2e4f2864504207789f10d9ca796a66da150c3b71e2ac320b"
+	pkixVector = "-----BEGIN PUBLIC KEY-----\nMCowBQYDK2VwAyEAR3umqbUzKN3Tdl1iRRStcIiHiP3oDCqjN/6s7tlddIQ=\n-----END PUBLIC KEY-----\n"
+)
+
+func TestPublic_MarshalSSH(t *testing.T) {
+	key, _ := hex.DecodeString(sshVectorKey)
+	public, err := PublicFromEd25519(key)
+	require.NoError(t, err)
+
+	line := public.MarshalSSH()
+	assert.Equal(t, strings.TrimSuffix(sshVectorPublicKey, " frost\n")+"\n", string(line))
+
+	parsed, err := ParseSSH([]byte(sshVectorPublicKey))
+	require.NoError(t, err)
+	assert.True(t, parsed.GroupKey.Equal(public.GroupKey))
+	parsed, err = ParseSSH(line)
+	require.NoError(t, err)
+	assert.True(t, parsed.GroupKey.Equal(public.GroupKey))
+
+	for _, invalid := range []string{
+		"",
+		"ssh-ed25519",
+		"ssh-rsa AAAAC3NzaC1lZDI1NTE5AAAAIEd7pqm1Myjd03ZdYkUUrXCIh4j96Awqozf+rO7ZXXSE",
+		"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEd7pqm1Myjd03ZdYkUUrXCIh4j96Awqozf+rO7ZXX",
+		"ssh-ed25519 !!!!",
+		"ssh-ed25519 AAAAB3NzaC1yc2EAAAADAQABAAABAQ==",
+	} {
+		_, err = ParseSSH([]byte(invalid))
+		assert.Error(t, err, invalid)
+	}
+	_, err = ParseSSH([]byte("ssh-rsa AAAA"))
+	assert.True(t, errors.Is(err, ErrUnsupportedKey))
+}
+
+func TestParseSSHSignature(t *testing.T) {
+	public, err := ParseSSH([]byte(sshVectorPublicKey))
+	require.NoError(t, err)
+	data, _ := hex.DecodeString(sshVectorSignature)
+
+	sig, err := ParseSSHSignature(data)
+	require.NoError(t, err)
+	assert.Equal(t, data, MarshalSSHSignature(sig))
+
+	// The data signed by ssh-keygen -Y sign, as defined in OpenSSH's PROTOCOL.sshsig
+	digest := sha512.Sum512([]byte("FROST-Ed25519"))
+	signed := []byte("SSHSIG")
+	signed = appendSSHString(signed, []byte("file"))
+	signed = appendSSHString(signed, nil)
+	signed = appendSSHString(signed, []byte("sha512"))
+	signed = appendSSHString(signed, digest[:])
+	assert.True(t, public.GroupKey.Verify(signed, sig))
+	assert.True(t, ed25519.Verify(public.GroupKeyEd25519(), signed, sig.ToEd25519()))
+	assert.False(t, public.GroupKey.Verify([]byte("FROST-Ed25519"), sig))
+
+	_, err = ParseSSHSignature(data[:len(data)-1])
+	assert.Error(t, err)
+	_, err = ParseSSHSignature(append(data[:len(data):len(data)], 0))
+	assert.Error(t, err)
+	_, err = ParseSSHSignature(appendSSHString(appendSSHString(nil, []byte("ssh-rsa")), data[19:]))
+	assert.True(t, errors.Is(err, ErrUnsupportedKey))
+}
+
+func TestPublic_MarshalPKIX(t *testing.T) {
+	key, _ := hex.DecodeString(sshVectorKey)
+	public, err := PublicFromEd25519(key)
+	require.NoError(t, err)
+
+	data, err := public.MarshalPKIX()
+	require.NoError(t, err)
+	assert.Equal(t, pkixVector, string(data))
+
+	block, _ := pem.Decode(data)
+	require.NotNil(t, block)
+	parsedKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	require.NoError(t, err)
+	assert.Equal(t, ed25519.PublicKey(key), parsedKey)
+
+	parsed, err := ParsePKIX(data)
+	require.NoError(t, err)
+	assert.True(t, parsed.GroupKey.Equal(public.GroupKey))
+
+	// The group key of a Public with shares
+	golden := goldenPublic(t)
+	data, err = golden.MarshalPKIX()
+	require.NoError(t, err)
+	parsed, err = ParsePKIX(data)
+	require.NoError(t, err)
+	assert.True(t, parsed.GroupKey.Equal(golden.GroupKey))
+	parsed, err = ParseSSH(golden.MarshalSSH())
+	require.NoError(t, err)
+	assert.True(t, parsed.GroupKey.Equal(golden.GroupKey))
+
+	_, err = ParsePKIX([]byte("not a pem"))
+	assert.Error(t, err)
+	// An ECDSA P-256 key generated by openssl
+	ecdsa := "MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE1cTnq9paxTIaFKIPsuf4j7DUVB5S5/yvRr/xRtYsPex07rWJiJKRXmBfvzH3Cy4ugke/9S6tBflMxaJTsunJpg=="
+	der, _ := base64.StdEncoding.DecodeString(ecdsa)
+	_, err = ParsePKIX(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	assert.True(t, errors.Is(err, ErrUnsupportedKey), err)
+}
@@ -0,0 +1,24 @@
+//go:build go1.23
+
+package eddsa
+
+import (
+	"iter"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// All returns an iterator over the parties of s and their public shares, in increasing order of ID,
+// unlike ranging over the map s.Shares.
+// The shares are those of s, and must not be modified.
+func (s *Public) All() iter.Seq2[party.ID, *ristretto.Element] {
+	partyIDs := party.NewIDSlice(s.PartyIDs)
+	return func(yield func(party.ID, *ristretto.Element) bool) {
+		for _, id := range partyIDs {
+			if !yield(id, s.Shares[id]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+//go:build go1.23
+
+package eddsa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+func TestPublic_All(t *testing.T) {
+	public, _ := fakeShares(10, 3)
+	var ids party.IDSlice
+	for id, share := range public.All() {
+		assert.Same(t, public.Shares[id], share)
+		ids = append(ids, id)
+	}
+	assert.True(t, ids.Equal(party.NewIDSlice(ids)), "All() should yield the IDs in increasing order")
+	assert.True(t, ids.Equal(public.PartyIDs))
+
+	ids = ids[:0]
+	for id := range public.All() {
+		if len(ids) == 2 {
+			break
+		}
+		ids = append(ids, id)
+	}
+	assert.True(t, ids.Equal(public.PartyIDs[:2]))
+}
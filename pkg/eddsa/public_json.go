@@ -0,0 +1,193 @@
+package eddsa
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// publicJSONVersion is the version of the JSON encoding of Public.
+const publicJSONVersion = 1
+
+// publicJSON is the JSON encoding of Public, which looks like
+//
+//	{
+//	  "version": 1,
+//	  "threshold": 1,
+//	  "participants": [1, 2, 3],
+//	  "groupkey": "<hex>",
+//	  "shares": {"1": "<hex>", "2": "<hex>", "3": "<hex>"}
+//	}
+//
+// where the points are the hex encoding of their 32 byte Ristretto encoding.
+type publicJSON struct {
+	Version      int                 `json:"version"`
+	Threshold    *uint32             `json:"threshold"`
+	Participants party.IDSlice       `json:"participants"`
+	GroupKey     string              `json:"groupkey"`
+	Shares       map[party.ID]string `json:"shares"`
+}
+
+// legacyPublicJSON is the encoding of Public before the version field was introduced.
+// It can still be decoded, but is no longer produced.
+type legacyPublicJSON struct {
+	Threshold int                             `json:"t"`
+	GroupKey  *PublicKey                      `json:"groupkey"`
+	Shares    map[party.ID]*ristretto.Element `json:"shares"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// As with MarshalBinary, the participants are sorted, so that parties holding the same key produce the same bytes.
+func (s *Public) MarshalJSON() ([]byte, error) {
+	if len(s.PartyIDs) == 0 {
+		return nil, errors.New("PublicShares: no shares")
+	}
+	partyIDs := party.NewIDSlice(s.PartyIDs)
+	threshold := uint32(s.Threshold)
+	out := publicJSON{
+		Version:      publicJSONVersion,
+		Threshold:    &threshold,
+		Participants: partyIDs,
+		GroupKey:     hex.EncodeToString(s.GroupKey.pk.Bytes()),
+		Shares:       make(map[party.ID]string, len(s.PartyIDs)),
+	}
+	for i, id := range partyIDs {
+		if i > 0 && partyIDs[i-1] == id {
+			return nil, fmt.Errorf("PublicShares: duplicate party %d", id)
+		}
+		share, ok := s.Shares[id]
+		if !ok {
+			return nil, fmt.Errorf("PublicShares: missing share for party %d", id)
+		}
+		out.Shares[id] = hex.EncodeToString(share.Bytes())
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// It is the same as UnmarshalPublicJSON with validate set to true.
+func (s *Public) UnmarshalJSON(data []byte) error {
+	public, err := UnmarshalPublicJSON(data, true)
+	if err != nil {
+		return err
+	}
+	*s = *public
+	return nil
+}
+
+// UnmarshalPublicJSON decodes a Public encoded with MarshalJSON.
+// The participants must be distinct non-zero IDs, and every share must be a canonical point,
+// and the group key must be the interpolation of the shares.
+//
+// When validate is true, the decoded Public is also checked with Validate,
+// so that every set of threshold+1 parties interpolates the group key.
+// This requires an interpolation for each of the N - threshold extra shares,
+// and may be disabled for large groups when the shares were produced by a key generation the caller took part in.
+func UnmarshalPublicJSON(data []byte, validate bool) (public *Public, err error) {
+	defer recoverPanic(&err)
+
+	var out publicJSON
+	if err = json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("PublicShares: %w", err)
+	}
+	switch out.Version {
+	case 0:
+		public, err = unmarshalLegacyPublicJSON(data)
+	case publicJSONVersion:
+		public, err = out.decode()
+	default:
+		return nil, fmt.Errorf("PublicShares: unsupported JSON version %d", out.Version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if validate {
+		if err = public.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return public, nil
+}
+
+func (out *publicJSON) decode() (*Public, error) {
+	if out.Threshold == nil {
+		return nil, errors.New("PublicShares: missing threshold")
+	}
+	if len(out.Participants) == 0 {
+		return nil, errors.New("PublicShares: no participants")
+	}
+	if len(out.Shares) != len(out.Participants) {
+		return nil, fmt.Errorf("PublicShares: got %d shares for %d participants", len(out.Shares), len(out.Participants))
+	}
+	var groupKey PublicKey
+	if err := decodeHexPoint(&groupKey.pk, out.GroupKey); err != nil {
+		return nil, fmt.Errorf("PublicShares: group key: %w", err)
+	}
+
+	shares := make(map[party.ID]*ristretto.Element, len(out.Participants))
+	for _, id := range out.Participants {
+		encoded, ok := out.Shares[id]
+		if !ok {
+			return nil, fmt.Errorf("PublicShares: missing share for party %d", id)
+		}
+		var share ristretto.Element
+		if err := decodeHexPoint(&share, encoded); err != nil {
+			return nil, fmt.Errorf("PublicShares: share of party %d: %w", id, err)
+		}
+		shares[id] = &share
+	}
+
+	return newPublicWithGroupKey(shares, party.Size(*out.Threshold), &groupKey)
+}
+
+func unmarshalLegacyPublicJSON(data []byte) (*Public, error) {
+	var out legacyPublicJSON
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("PublicShares: %w", err)
+	}
+	if out.Threshold < 0 || uint64(out.Threshold) > uint64(party.MaxID) {
+		return nil, errors.New("PublicShares: invalid threshold")
+	}
+	if out.GroupKey == nil {
+		return nil, errors.New("PublicShares: missing group key")
+	}
+	for id, share := range out.Shares {
+		if id == 0 {
+			return nil, errors.New("PublicShares: invalid party ID 0")
+		}
+		if share == nil {
+			return nil, fmt.Errorf("PublicShares: missing share for party %d", id)
+		}
+	}
+	return newPublicWithGroupKey(out.Shares, party.Size(out.Threshold), out.GroupKey)
+}
+
+// newPublicWithGroupKey returns NewPublic(shares, threshold),
+// after checking that the interpolation of the shares is the expected groupKey.
+func newPublicWithGroupKey(shares map[party.ID]*ristretto.Element, threshold party.Size, groupKey *PublicKey) (*Public, error) {
+	public, err := NewPublic(shares, threshold)
+	if err != nil {
+		return nil, err
+	}
+	if !public.GroupKey.Equal(groupKey) {
+		return nil, errors.New("PublicShares: inconsistent group key")
+	}
+	return public, nil
+}
+
+// decodeHexPoint sets e to the point whose canonical encoding is the hex string encoded.
+func decodeHexPoint(e *ristretto.Element, encoded string) error {
+	data, err := hex.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+	if len(data) != 32 {
+		return fmt.Errorf("point has %d bytes", len(data))
+	}
+	_, err = e.SetCanonicalBytes(data)
+	return err
+}
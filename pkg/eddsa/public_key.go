@@ -2,16 +2,27 @@ package eddsa
 
 import (
 	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 )
 
 // PublicKey represents a FROST-Ed25519 verification key.
+// It is the type of the group key of a Public, and should be passed around instead of its point.
 type PublicKey struct {
 	pk ristretto.Element
 }
 
+// fingerprintSize is the size in bytes of the fingerprint of a group key.
+const fingerprintSize = 8
+
+// fingerprintEncoding is the lowercase base32 encoding without padding used by PublicKey.Fingerprint.
+var fingerprintEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
 // NewPublicKeyFromPoint returns a PublicKey given an ristretto.Element.
 func NewPublicKeyFromPoint(public *ristretto.Element) *PublicKey {
 	var pk PublicKey
@@ -20,8 +31,11 @@ func NewPublicKeyFromPoint(public *ristretto.Element) *PublicKey {
 }
 
 func (pk *PublicKey) Verify(message []byte, sig *Signature) bool {
-	challenge := ComputeChallenge(&sig.R, pk, message)
+	return pk.verifyChallenge(ComputeChallenge(&sig.R, pk, message), sig)
+}
 
+// verifyChallenge checks that [s]•B = R + [c]•A.
+func (pk *PublicKey) verifyChallenge(challenge *ristretto.Scalar, sig *Signature) bool {
 	// Verify the full signature here too.
 	var publicNeg, RPrime ristretto.Element
 	publicNeg.Negate(&pk.pk)
@@ -30,17 +44,106 @@ func (pk *PublicKey) Verify(message []byte, sig *Signature) bool {
 	return RPrime.Equal(&sig.R) == 1
 }
 
-// Equal returns true if the public key is equal to pk0
+// Equal returns true if the public key is equal to pkOther.
+// The comparison runs in constant time.
 func (pk *PublicKey) Equal(pkOther *PublicKey) bool {
 	return pk.pk.Equal(&pkOther.pk) == 1
 }
 
+// Point returns a copy of the Ristretto point of pk.
+func (pk *PublicKey) Point() *ristretto.Element {
+	return new(ristretto.Element).Set(&pk.pk)
+}
+
+// Fingerprint returns a short identifier of pk for humans, such as "tzkj5ilrm3ldy":
+// the first 8 bytes of SHA-256 of the Ed25519 encoding of pk, in lowercase base32 without padding.
+// It is the group recorded by SecretShare.SetGroup, and the one checked when decrypting a SecretShare.
+// It is stable, and must not be used instead of Equal to compare keys.
+func (pk *PublicKey) Fingerprint() string {
+	return formatFingerprint(pk.fingerprint())
+}
+
+// fingerprint returns the first bytes of SHA-256 of the Ed25519 encoding of pk.
+func (pk *PublicKey) fingerprint() []byte {
+	digest := sha256.Sum256(pk.ToEd25519())
+	return digest[:fingerprintSize]
+}
+
+// formatFingerprint returns the encoding of a fingerprint used by PublicKey.Fingerprint.
+func formatFingerprint(fingerprint []byte) string {
+	return fingerprintEncoding.EncodeToString(fingerprint)
+}
+
+// Tweak returns the PublicKey pk + [t]•G, whose secret key is the secret key of pk plus t.
+func (pk *PublicKey) Tweak(t *ristretto.Scalar) *PublicKey {
+	var tweaked PublicKey
+	tweaked.pk.ScalarBaseMult(t)
+	tweaked.pk.Add(&tweaked.pk, &pk.pk)
+	return &tweaked
+}
+
 // ToEd25519 converts the PublicKey to an ed25519 compatible format
 func (pk *PublicKey) ToEd25519() ed25519.PublicKey {
 	return pk.pk.BytesEd25519()
 }
 
+// NewPublicKeyFromEd25519 returns the PublicKey corresponding to an ed25519.PublicKey, so that ToEd25519 returns key.
+// An error is returned if key is not a valid point in the prime order subgroup,
+// which never happens for keys generated by crypto/ed25519.
+func NewPublicKeyFromEd25519(key ed25519.PublicKey) (*PublicKey, error) {
+	var pk PublicKey
+	if _, err := pk.pk.SetEd25519Bytes(key); err != nil {
+		return nil, fmt.Errorf("PublicKey: %w", err)
+	}
+	return &pk, nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The encoding is the 32 byte encoding of the Ristretto point.
+func (pk *PublicKey) MarshalBinary() ([]byte, error) {
+	return pk.pk.Bytes(), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (pk *PublicKey) UnmarshalBinary(data []byte) (err error) {
+	defer recoverPanic(&err)
+
+	if len(data) != 32 {
+		return fmt.Errorf("PublicKey: %w", ErrInvalidMessage)
+	}
+	if _, err = pk.pk.SetCanonicalBytes(data); err != nil {
+		return fmt.Errorf("PublicKey: %w", err)
+	}
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The encoding is the lowercase hex encoding of ToEd25519, which is the usual text form of an Ed25519 public key.
+func (pk *PublicKey) MarshalText() ([]byte, error) {
+	encoded := make([]byte, hex.EncodedLen(ed25519.PublicKeySize))
+	hex.Encode(encoded, pk.ToEd25519())
+	return encoded, nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface, as with NewPublicKeyFromEd25519.
+func (pk *PublicKey) UnmarshalText(text []byte) error {
+	if len(text) != hex.EncodedLen(ed25519.PublicKeySize) {
+		return fmt.Errorf("PublicKey: text has %d characters instead of %d", len(text), hex.EncodedLen(ed25519.PublicKeySize))
+	}
+	key := make([]byte, ed25519.PublicKeySize)
+	if _, err := hex.Decode(key, text); err != nil {
+		return fmt.Errorf("PublicKey: %w", err)
+	}
+	decoded, err := NewPublicKeyFromEd25519(key)
+	if err != nil {
+		return err
+	}
+	*pk = *decoded
+	return nil
+}
+
 // MarshalJSON implements the json.Marshaler interface.
+// It is the JSON encoding of the Ristretto point, and not the text encoding.
 func (pk PublicKey) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&pk.pk)
 }
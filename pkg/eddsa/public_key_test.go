@@ -1,12 +1,15 @@
 package eddsa
 
 import (
+	"bytes"
 	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/sha512"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
 	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 )
 
@@ -38,3 +41,89 @@ func TestPrivateKey_ToEd25519(t *testing.T) {
 
 	assert.Equal(t, pk.ToEd25519(), pkbytes)
 }
+
+func TestNewPublicKeyFromEd25519(t *testing.T) {
+	pkBytes, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	pk, err := NewPublicKeyFromEd25519(pkBytes)
+	require.NoError(t, err)
+	assert.Equal(t, pkBytes, pk.ToEd25519())
+
+	public, err := PublicFromEd25519(pkBytes)
+	require.NoError(t, err)
+	assert.Equal(t, pkBytes, public.GroupKeyEd25519())
+	assert.True(t, public.GroupKey.Equal(pk))
+	assert.Empty(t, public.PartyIDs)
+	_, err = public.MarshalBinary()
+	assert.Error(t, err, "a Public without shares cannot be encoded")
+
+	// A point of order 2, and a point which is not on the curve
+	smallOrder := append([]byte{0xec}, bytes.Repeat([]byte{0xff}, 30)...)
+	smallOrder = append(smallOrder, 0x7f)
+	notOnCurve := make([]byte, 32)
+	notOnCurve[0] = 2
+	for _, invalid := range [][]byte{nil, pkBytes[:31], smallOrder, notOnCurve} {
+		_, err = NewPublicKeyFromEd25519(invalid)
+		assert.Error(t, err)
+		_, err = PublicFromEd25519(invalid)
+		assert.Error(t, err)
+	}
+}
+
+func TestPublicKey_Tweak(t *testing.T) {
+	a, tweak := scalar.NewScalarRandom(), scalar.NewScalarRandom()
+	pk := NewPublicKeyFromPoint(new(ristretto.Element).ScalarBaseMult(a))
+
+	var sum ristretto.Scalar
+	sum.Add(a, tweak)
+	assert.True(t, pk.Tweak(tweak).Equal(NewPublicKeyFromPoint(new(ristretto.Element).ScalarBaseMult(&sum))))
+	assert.True(t, pk.Tweak(ristretto.NewScalar()).Equal(pk))
+}
+
+func TestPublicKey_Fingerprint(t *testing.T) {
+	// The group key [42]•G of goldenPublic, whose Ed25519 encoding is
+	// ce1a32994e835c193e2bf33909f44373ae2cf94ddef0fd922035c483670637c2
+	public := goldenPublic(t)
+	assert.Equal(t, "bldii5r4ufeyg", public.GroupKey.Fingerprint())
+	assert.Equal(t, public.GroupKey.Fingerprint(), public.Copy().GroupKey.Fingerprint())
+
+	// The group recorded in a SecretShare is the same fingerprint
+	secret := NewSecretShare(1, scalar.NewScalarUInt32(49))
+	secret.SetGroup(public.GroupKey)
+	assert.Equal(t, public.GroupKey.Fingerprint(), formatFingerprint(secret.Group()))
+
+	other := public.GroupKey.Tweak(scalar.NewScalarUInt32(1))
+	assert.NotEqual(t, public.GroupKey.Fingerprint(), other.Fingerprint())
+	assert.False(t, public.GroupKey.Equal(other))
+}
+
+func TestPublicKey_Point(t *testing.T) {
+	public := goldenPublic(t)
+	point := public.GroupKey.Point()
+	assert.Equal(t, 1, point.Equal(new(ristretto.Element).ScalarBaseMult(scalar.NewScalarUInt32(42))))
+	point.Add(point, point)
+	assert.Equal(t, "bldii5r4ufeyg", public.GroupKey.Fingerprint(), "Point should return a copy")
+}
+
+func TestPublicKey_MarshalText(t *testing.T) {
+	const golden = "ce1a32994e835c193e2bf33909f44373ae2cf94ddef0fd922035c483670637c2"
+	public := goldenPublic(t)
+	text, err := public.GroupKey.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, golden, string(text))
+
+	var decoded PublicKey
+	require.NoError(t, decoded.UnmarshalText(text))
+	assert.True(t, decoded.Equal(public.GroupKey))
+
+	invalid := bytes.Repeat([]byte("f"), len(golden))
+	for name, text := range map[string][]byte{
+		"empty":     nil,
+		"short":     text[:62],
+		"not hex":   append([]byte("zz"), text[2:]...),
+		"not point": invalid,
+	} {
+		assert.Error(t, new(PublicKey).UnmarshalText(text), name)
+	}
+}
@@ -0,0 +1,38 @@
+package eddsa
+
+import (
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// WithParty returns a copy of s to which the party id was added, with the threshold and group key of s.
+// Its public share f(xᵢ)•B is interpolated from the shares of the first Threshold+1 parties of s,
+// so that it matches the secret share which the enrollment protocol gives to the new party.
+// The shares of s must be consistent, see Validate.
+func (s *Public) WithParty(id party.ID) (*Public, error) {
+	if id == 0 {
+		return nil, party.ErrZeroID
+	}
+	if s.PartyIDs.Contains(id) {
+		return nil, fmt.Errorf("PublicShares: party %d already has a share", id)
+	}
+	if s.PartyIDs.N() < s.Threshold+1 {
+		return nil, fmt.Errorf("PublicShares: %d shares are required to add a party", s.Threshold+1)
+	}
+
+	ids := s.PartyIDs[:s.Threshold+1]
+	points := make([]*ristretto.Element, 0, len(ids))
+	for _, j := range ids {
+		points = append(points, s.Shares[j])
+	}
+	share := new(ristretto.Element).VarTimeMultiScalarMult(lagrangeAt(ids, id.Scalar()), points)
+
+	public := s.Copy()
+	if err := public.PartyIDs.Add(id); err != nil {
+		return nil, err
+	}
+	public.Shares[id] = share
+	return public, nil
+}
@@ -0,0 +1,37 @@
+package eddsa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+func TestPublic_WithParty(t *testing.T) {
+	poly := polynomial.NewPolynomial(2, scalar.NewScalarRandom())
+	shares := make(map[party.ID]*ristretto.Element, 5)
+	for _, id := range []party.ID{1, 2, 3, 4, 5} {
+		shares[id] = new(ristretto.Element).ScalarBaseMult(poly.Evaluate(id.Scalar()))
+	}
+	public, err := NewPublic(shares, 2)
+	require.NoError(t, err)
+
+	extended, err := public.WithParty(8)
+	require.NoError(t, err)
+	assert.True(t, extended.PartyIDs.Equal(party.IDSlice{1, 2, 3, 4, 5, 8}))
+	assert.Equal(t, public.Threshold, extended.Threshold)
+	assert.True(t, extended.GroupKey.Equal(public.GroupKey))
+	want := new(ristretto.Element).ScalarBaseMult(poly.Evaluate(party.ID(8).Scalar()))
+	assert.Equal(t, 1, extended.Shares[8].Equal(want), "the new share is not on the polynomial")
+	assert.NoError(t, extended.Validate())
+	assert.Len(t, public.PartyIDs, 5, "public should not be modified")
+
+	_, err = public.WithParty(3)
+	assert.Error(t, err)
+	_, err = public.WithParty(0)
+	assert.Error(t, err)
+}
@@ -1,11 +1,16 @@
 package eddsa
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
 	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
 	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
@@ -75,3 +80,404 @@ func TestShares_MarshalJSON(t *testing.T) {
 		t.Error("unmarshalled is not equal")
 	}
 }
+
+func TestPublic_MarshalBinary(t *testing.T) {
+	public, _ := fakeShares(5, 2)
+	data, err := public.MarshalBinary()
+	require.NoError(t, err)
+	// version ∥ threshold ∥ n ∥ 5 × (ID ∥ share) ∥ group key
+	require.Len(t, data, 1+2*party.IDByteSize+5*(party.IDByteSize+32)+32)
+	require.Len(t, data, PublicSizeBytes(5))
+	assert.Equal(t, byte(1), data[0])
+	for i, id := range public.PartyIDs {
+		offset := 1 + 2*party.IDByteSize + i*(party.IDByteSize+32)
+		assert.Equal(t, id.Bytes(), data[offset:offset+party.IDByteSize])
+	}
+	assert.Equal(t, public.GroupKey.pk.Bytes(), data[len(data)-32:])
+
+	var decoded Public
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.True(t, public.Equal(&decoded))
+	assert.True(t, public.PartyIDs.Equal(decoded.PartyIDs))
+	encoded, err := decoded.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, data, encoded)
+
+	shareOffset := func(i int) int { return 1 + 2*party.IDByteSize + i*(party.IDByteSize+32) }
+	corrupt := func(offset int, value []byte) []byte {
+		corrupted := append([]byte(nil), data...)
+		copy(corrupted[offset:], value)
+		return corrupted
+	}
+	for name, tc := range map[string]struct {
+		data []byte
+		err  string
+	}{
+		"empty":           {nil, "too short"},
+		"header":          {data[:5], "too short"},
+		"truncated":       {data[:len(data)-1], "bytes"},
+		"trailing":        {append(append([]byte(nil), data...), 0), "bytes"},
+		"version":         {corrupt(0, []byte{2}), "version"},
+		"wrong count":     {corrupt(1+party.IDByteSize, party.Size(4).Bytes()), "bytes"},
+		"no shares":       {corrupt(1+party.IDByteSize, party.Size(0).Bytes())[:PublicSizeBytes(0)], "no shares"},
+		"threshold":       {corrupt(1, party.Size(5).Bytes()), "threshold"},
+		"zero ID":         {corrupt(shareOffset(0), party.ID(0).Bytes()), "ID of share 0"},
+		"unsorted IDs":    {corrupt(shareOffset(3), public.PartyIDs[1].Bytes()), "ID of share 3"},
+		"duplicate IDs":   {corrupt(shareOffset(2), public.PartyIDs[1].Bytes()), "ID of share 2"},
+		"invalid share":   {corrupt(shareOffset(4)+party.IDByteSize, bytes.Repeat([]byte{0xff}, 32)), "share of party"},
+		"invalid group":   {corrupt(len(data)-32, bytes.Repeat([]byte{0xff}, 32)), "group key"},
+		"wrong group key": {corrupt(len(data)-32, ristretto.NewGeneratorElement().Bytes()), "inconsistent"},
+	} {
+		err := new(Public).UnmarshalBinary(tc.data)
+		if assert.Error(t, err, name) {
+			assert.Contains(t, err.Error(), tc.err, name)
+		}
+	}
+
+	// Without validation, the group key is not checked against the shares
+	wrongGroupKey := corrupt(len(data)-32, ristretto.NewGeneratorElement().Bytes())
+	_, err = UnmarshalPublicBinary(wrongGroupKey, false)
+	assert.NoError(t, err)
+
+	_, err = (&Public{PartyIDs: party.IDSlice{1, 1}, Shares: public.Shares, GroupKey: public.GroupKey}).MarshalBinary()
+	assert.Error(t, err)
+	_, err = (&Public{PartyIDs: party.IDSlice{0, 1}, Shares: public.Shares, GroupKey: public.GroupKey}).MarshalBinary()
+	assert.Error(t, err)
+	_, err = (&Public{PartyIDs: public.PartyIDs, Shares: public.Shares}).MarshalBinary()
+	assert.Error(t, err)
+}
+
+func TestPublic_MarshalBinary_Golden(t *testing.T) {
+	const golden = "01" + "00000001" + "00000003" +
+		"00000001" + "cec1426a33965eb2a7d82b281964ad39f06d6fba7d8e57f8da4fcfefd946d855" +
+		"00000002" + "98022f4b1192d39e659014767392257440b0146dee7fd3b62d595c5f161b2521" +
+		"00000003" + "de370cffd8bd5ffd152f733fc5b4d226dc0dcb7e8e5b538717110b2d6267132e" +
+		"e00af9c74d9edb8ebcc160ceec97d531cbd6e2956f9e9162b8e9eda260e82e43"
+
+	public := goldenPublic(t)
+	data, err := public.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, golden, hex.EncodeToString(data))
+
+	decoded, err := UnmarshalPublicBinary(data, true)
+	require.NoError(t, err)
+	assert.True(t, public.Equal(decoded))
+}
+
+func TestUnmarshalPublicBinary_Legacy(t *testing.T) {
+	// threshold ∥ n ∥ IDs ∥ shares, without the version and group key
+	const legacy = "00000001" + "00000003" + "00000001" + "00000002" + "00000003" +
+		"cec1426a33965eb2a7d82b281964ad39f06d6fba7d8e57f8da4fcfefd946d855" +
+		"98022f4b1192d39e659014767392257440b0146dee7fd3b62d595c5f161b2521" +
+		"de370cffd8bd5ffd152f733fc5b4d226dc0dcb7e8e5b538717110b2d6267132e"
+	data, err := hex.DecodeString(legacy)
+	require.NoError(t, err)
+
+	var decoded Public
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.True(t, goldenPublic(t).Equal(&decoded))
+	encoded, err := decoded.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, byte(publicBinaryVersion), encoded[0])
+
+	assert.Error(t, new(Public).UnmarshalBinary(data[:len(data)-1]))
+}
+
+func FuzzPublic_UnmarshalBinary(f *testing.F) {
+	public, _ := fakeShares(5, 2)
+	data, err := public.MarshalBinary()
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(data)
+	f.Add(data[:PublicSizeBytes(0)])
+	f.Add([]byte{1, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		public, err := UnmarshalPublicBinary(data, false)
+		if err != nil {
+			return
+		}
+		encoded, err := public.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data[0] == publicBinaryVersion && !bytes.Equal(data, encoded) {
+			t.Fatalf("MarshalBinary() = %x, want %x", encoded, data)
+		}
+		public2, err := UnmarshalPublicBinary(encoded, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, public.Equal(public2))
+	})
+}
+
+func FuzzPublic_UnmarshalJSON(f *testing.F) {
+	public, _ := fakeShares(5, 2)
+	data, err := json.Marshal(public)
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(data)
+	f.Add([]byte(`{"t":1,"groupkey":null,"shares":{"1":null,"2":null}}`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var public Public
+		if err := json.Unmarshal(data, &public); err != nil {
+			return
+		}
+		encoded, err := json.Marshal(&public)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var public2 Public
+		if err = json.Unmarshal(encoded, &public2); err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, public.Equal(&public2))
+	})
+}
+
+// goldenPublic returns the Public of the shares f(1), f(2), f(3) with f(x) = 42 + 7x.
+func goldenPublic(t *testing.T) *Public {
+	shares := make(map[party.ID]*ristretto.Element, 3)
+	for id := party.ID(1); id <= 3; id++ {
+		shares[id] = new(ristretto.Element).ScalarBaseMult(scalar.NewScalarUInt32(42 + 7*uint32(id)))
+	}
+	public, err := NewPublic(shares, 1)
+	require.NoError(t, err)
+	return public
+}
+
+func TestPublic_MarshalJSON_Golden(t *testing.T) {
+	const golden = `{"version":1,"threshold":1,"participants":[1,2,3],` +
+		`"groupkey":"e00af9c74d9edb8ebcc160ceec97d531cbd6e2956f9e9162b8e9eda260e82e43",` +
+		`"shares":{` +
+		`"1":"cec1426a33965eb2a7d82b281964ad39f06d6fba7d8e57f8da4fcfefd946d855",` +
+		`"2":"98022f4b1192d39e659014767392257440b0146dee7fd3b62d595c5f161b2521",` +
+		`"3":"de370cffd8bd5ffd152f733fc5b4d226dc0dcb7e8e5b538717110b2d6267132e"}}`
+
+	public := goldenPublic(t)
+	data, err := json.Marshal(public)
+	require.NoError(t, err)
+	assert.Equal(t, golden, string(data))
+
+	decoded, err := UnmarshalPublicJSON([]byte(golden), true)
+	require.NoError(t, err)
+	assert.True(t, public.Equal(decoded))
+	assert.True(t, decoded.GroupKey.Equal(NewPublicKeyFromPoint(new(ristretto.Element).ScalarBaseMult(scalar.NewScalarUInt32(42)))))
+}
+
+func TestUnmarshalPublicJSON_Invalid(t *testing.T) {
+	public := goldenPublic(t)
+	data, err := json.Marshal(public)
+	require.NoError(t, err)
+
+	// modify decodes the golden encoding, applies f, and encodes it again
+	modify := func(f func(m map[string]interface{})) []byte {
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &m))
+		f(m)
+		result, err := json.Marshal(m)
+		require.NoError(t, err)
+		return result
+	}
+	shares := func(m map[string]interface{}) map[string]interface{} {
+		return m["shares"].(map[string]interface{})
+	}
+	identity := hex.EncodeToString(ristretto.NewIdentityElement().Bytes())
+	nonCanonical := "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f"
+
+	for name, invalid := range map[string][]byte{
+		"version":            modify(func(m map[string]interface{}) { m["version"] = 2 }),
+		"missing threshold":  modify(func(m map[string]interface{}) { delete(m, "threshold") }),
+		"threshold too high": modify(func(m map[string]interface{}) { m["threshold"] = 3 }),
+		"negative threshold": modify(func(m map[string]interface{}) { m["threshold"] = -1 }),
+		"no participants":    modify(func(m map[string]interface{}) { m["participants"] = []int{} }),
+		"zero participant":   modify(func(m map[string]interface{}) { m["participants"] = []int{0, 1, 2} }),
+		"duplicate participant": modify(func(m map[string]interface{}) {
+			m["participants"] = []int{1, 2, 2}
+		}),
+		"unknown participant": modify(func(m map[string]interface{}) { m["participants"] = []int{1, 2, 4} }),
+		"missing share":       modify(func(m map[string]interface{}) { delete(shares(m), "3") }),
+		"extra share":         modify(func(m map[string]interface{}) { shares(m)["4"] = identity }),
+		"wrong share":         modify(func(m map[string]interface{}) { shares(m)["3"] = identity }),
+		"non-canonical share": modify(func(m map[string]interface{}) { shares(m)["3"] = nonCanonical }),
+		"short share":         modify(func(m map[string]interface{}) { shares(m)["3"] = "00" }),
+		"not hex":             modify(func(m map[string]interface{}) { shares(m)["3"] = "zz" }),
+		"wrong group key":     modify(func(m map[string]interface{}) { m["groupkey"] = identity }),
+		"missing group key":   modify(func(m map[string]interface{}) { delete(m, "groupkey") }),
+		"not an object":       []byte(`[]`),
+	} {
+		_, err = UnmarshalPublicJSON(invalid, false)
+		assert.Error(t, err, name)
+		assert.Error(t, new(Public).UnmarshalJSON(invalid), name)
+	}
+}
+
+func TestUnmarshalPublicJSON_VerifyShares(t *testing.T) {
+	// The interpolation of the shares of a polynomial of degree 2 is a valid group key,
+	// but they are not consistent with the threshold 1.
+	shares := make(map[party.ID]*ristretto.Element, 4)
+	for id := party.ID(1); id <= 4; id++ {
+		f := 42 + 7*uint32(id) + uint32(id*id)
+		shares[id] = new(ristretto.Element).ScalarBaseMult(scalar.NewScalarUInt32(f))
+	}
+	public, err := NewPublic(shares, 1)
+	require.NoError(t, err)
+	data, err := json.Marshal(public)
+	require.NoError(t, err)
+
+	decoded, err := UnmarshalPublicJSON(data, false)
+	require.NoError(t, err)
+	assert.True(t, public.Equal(decoded))
+	_, err = UnmarshalPublicJSON(data, true)
+	assert.True(t, errors.Is(err, ErrInconsistentShare))
+	assert.Error(t, new(Public).UnmarshalJSON(data))
+
+	public.Threshold = 2
+	data, err = json.Marshal(public)
+	require.NoError(t, err)
+	_, err = UnmarshalPublicJSON(data, true)
+	assert.NoError(t, err)
+
+	large, _ := fakeShares(20, 7)
+	data, err = json.Marshal(large)
+	require.NoError(t, err)
+	decoded, err = UnmarshalPublicJSON(data, true)
+	require.NoError(t, err)
+	assert.True(t, large.Equal(decoded))
+}
+
+func TestUnmarshalPublicJSON_Legacy(t *testing.T) {
+	public := goldenPublic(t)
+	legacy, err := json.Marshal(legacyPublicJSON{
+		Threshold: int(public.Threshold),
+		GroupKey:  public.GroupKey,
+		Shares:    public.Shares,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(legacy), `"t":1`)
+
+	var decoded Public
+	require.NoError(t, json.Unmarshal(legacy, &decoded))
+	assert.True(t, public.Equal(&decoded))
+	encoded, err := json.Marshal(&decoded)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"version":1`)
+}
+
+func TestPublic_Validate(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3, 4, 5, 6, 7}
+	_, _, public := sharedKey(t, partyIDs, 2)
+	require.NoError(t, public.Validate())
+
+	for _, corrupted := range partyIDs {
+		p := public.Copy()
+		p.Shares[corrupted].Add(p.Shares[corrupted], ristretto.NewGeneratorElement())
+		err := p.Validate()
+		var shareErr *ShareError
+		require.True(t, errors.As(err, &shareErr), "party %d: %v", corrupted, err)
+		assert.Equal(t, corrupted, shareErr.ID)
+		assert.True(t, errors.Is(err, ErrInconsistentShare))
+
+		// The binary encoding includes the group key, which is only checked against the shares when validating.
+		data, err := p.MarshalBinary()
+		require.NoError(t, err)
+		_, err = UnmarshalPublicBinary(data, false)
+		require.NoError(t, err)
+		_, err = UnmarshalPublicBinary(data, true)
+		require.True(t, errors.As(err, &shareErr), "party %d: %v", corrupted, err)
+		assert.Equal(t, corrupted, shareErr.ID)
+		assert.Error(t, new(Public).UnmarshalBinary(data))
+	}
+
+	p := public.Copy()
+	p.Shares[partyIDs[4]] = ristretto.NewIdentityElement()
+	err := p.Validate()
+	var shareErr *ShareError
+	require.True(t, errors.As(err, &shareErr))
+	assert.Equal(t, partyIDs[4], shareErr.ID)
+	assert.True(t, errors.Is(err, ErrIdentityShare))
+
+	// With threshold+1 shares, an inconsistent share cannot be told apart from the others.
+	_, _, small := sharedKey(t, partyIDs[:3], 2)
+	small.Shares[partyIDs[1]].Add(small.Shares[partyIDs[1]], ristretto.NewGeneratorElement())
+	err = small.Validate()
+	assert.True(t, errors.Is(err, ErrInconsistentShare))
+	assert.False(t, errors.As(err, &shareErr))
+
+	for name, modify := range map[string]func(p *Public){
+		"threshold too high": func(p *Public) { p.Threshold = party.Size(len(partyIDs)) },
+		"missing group key":  func(p *Public) { p.GroupKey = nil },
+		"missing share":      func(p *Public) { delete(p.Shares, partyIDs[2]) },
+		"unsorted parties":   func(p *Public) { p.PartyIDs[0], p.PartyIDs[1] = p.PartyIDs[1], p.PartyIDs[0] },
+		"no parties":         func(p *Public) { p.PartyIDs, p.Shares = nil, nil },
+	} {
+		p := public.Copy()
+		modify(p)
+		assert.Error(t, p.Validate(), name)
+	}
+}
+
+func TestPublic_Share(t *testing.T) {
+	public, _ := fakeShares(5, 2)
+	for _, id := range public.PartyIDs {
+		share, err := public.Share(id)
+		require.NoError(t, err)
+		assert.Equal(t, 1, share.Equal(public.Shares[id]))
+		assert.NotSame(t, public.Shares[id], share, "Share should return a copy")
+	}
+	_, err := public.Share(0)
+	assert.Error(t, err)
+}
+
+func TestPublic_DeterministicEncoding(t *testing.T) {
+	reference, _ := fakeShares(20, 7)
+
+	// Publics holding the same shares, built independently
+	var publics []*Public
+	for i := 0; i < 5; i++ {
+		// The shares map is filled in a different order each time
+		shares := make(map[party.ID]*ristretto.Element, len(reference.PartyIDs))
+		for j := range reference.PartyIDs {
+			id := reference.PartyIDs[(i*3+j)%len(reference.PartyIDs)]
+			shares[id] = new(ristretto.Element).Set(reference.Shares[id])
+		}
+		public, err := NewPublic(shares, reference.Threshold)
+		require.NoError(t, err)
+		publics = append(publics, public)
+	}
+	reversed := reference.Copy()
+	for i, j := 0, len(reversed.PartyIDs)-1; i < j; i, j = i+1, j-1 {
+		reversed.PartyIDs[i], reversed.PartyIDs[j] = reversed.PartyIDs[j], reversed.PartyIDs[i]
+	}
+	publics = append(publics, reversed)
+	data, err := json.Marshal(reference)
+	require.NoError(t, err)
+	decoded, err := UnmarshalPublicJSON(data, true)
+	require.NoError(t, err)
+	publics = append(publics, decoded)
+
+	digests := func(public *Public) (binaryDigest, jsonDigest [32]byte) {
+		data, err := public.MarshalBinary()
+		require.NoError(t, err)
+		binaryDigest = sha256.Sum256(data)
+		data, err = public.MarshalJSON()
+		require.NoError(t, err)
+		jsonDigest = sha256.Sum256(data)
+		return
+	}
+	binaryDigest, jsonDigest := digests(reference)
+	for i, public := range publics {
+		b, j := digests(public)
+		assert.Equal(t, binaryDigest, b, "binary encoding of Public %d", i)
+		assert.Equal(t, jsonDigest, j, "JSON encoding of Public %d", i)
+	}
+
+	duplicate := reference.Copy()
+	duplicate.PartyIDs[1] = duplicate.PartyIDs[0]
+	_, err = duplicate.MarshalJSON()
+	assert.Error(t, err)
+	_, err = duplicate.MarshalBinary()
+	assert.Error(t, err)
+}
@@ -0,0 +1,139 @@
+package eddsa
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+var (
+	// ErrIdentityShare is wrapped by a *ShareError when the public share of a party is the identity.
+	ErrIdentityShare = errors.New("public share is the identity")
+
+	// ErrInconsistentShare is returned when the public shares do not lie on a polynomial of degree threshold
+	// whose constant term is the group key. It is wrapped by a *ShareError when a single share is responsible.
+	ErrInconsistentShare = errors.New("public share is inconsistent with the group key")
+)
+
+// ShareError is returned by Public.Validate when the public share of a party is invalid.
+type ShareError struct {
+	// ID is the party whose share is invalid.
+	ID party.ID
+	// Err is ErrIdentityShare or ErrInconsistentShare.
+	Err error
+}
+
+// Error implements error
+func (e *ShareError) Error() string {
+	return fmt.Sprintf("PublicShares: share of party %d: %v", e.ID, e.Err)
+}
+
+// Unwrap returns the reason.
+func (e *ShareError) Unwrap() error {
+	return e.Err
+}
+
+// Validate checks that s can be used to sign with any threshold+1 of its parties:
+//   - the parties are distinct non-zero IDs in increasing order, each with a share,
+//     and there are more than threshold of them;
+//   - no share is the identity;
+//   - every subset of threshold+1 shares interpolates in the exponent to the group key.
+//
+// The last check requires N - threshold multi-scalar multiplications of size threshold+1.
+// When a single share is inconsistent with the others, the error is a *ShareError with its ID.
+//
+// Validate is called when decoding a Public, and NewPublic does not call it.
+func (s *Public) Validate() error {
+	if s.GroupKey == nil {
+		return errors.New("PublicShares: missing group key")
+	}
+	n := len(s.PartyIDs)
+	if n == 0 {
+		return errors.New("PublicShares: no shares")
+	}
+	if len(s.Shares) != n {
+		return fmt.Errorf("PublicShares: got %d shares for %d parties", len(s.Shares), n)
+	}
+	for i, id := range s.PartyIDs {
+		if id == 0 {
+			return errors.New("PublicShares: invalid party ID 0")
+		}
+		if i > 0 && s.PartyIDs[i-1] >= id {
+			return errors.New("PublicShares: party IDs are not sorted and distinct")
+		}
+	}
+	if uint64(s.Threshold)+1 > uint64(n) {
+		return fmt.Errorf("PublicShares: threshold %d requires more than %d parties", s.Threshold, n)
+	}
+
+	identity := ristretto.NewIdentityElement()
+	for _, id := range s.PartyIDs {
+		share, ok := s.Shares[id]
+		if !ok || share == nil {
+			return fmt.Errorf("PublicShares: missing share for party %d", id)
+		}
+		if share.Equal(identity) == 1 {
+			return &ShareError{ID: id, Err: ErrIdentityShare}
+		}
+	}
+
+	t := int(s.Threshold)
+	if s.consistent(s.PartyIDs, &s.GroupKey.pk) {
+		return nil
+	}
+	// Look for a share without which the others lie on a polynomial of degree t.
+	// When the group key is correct, the others must interpolate to it, so one extra share is enough to find it.
+	// Otherwise, as when the group key was computed from the shares, the others only need to agree with each other,
+	// and two extra shares are needed since any t+1 shares lie on a polynomial of degree t.
+	if n >= t+2 {
+		for _, id := range s.PartyIDs {
+			if s.consistent(s.PartyIDs.Difference(party.IDSlice{id}), &s.GroupKey.pk) {
+				return &ShareError{ID: id, Err: ErrInconsistentShare}
+			}
+		}
+	}
+	if n >= t+3 {
+		for _, id := range s.PartyIDs {
+			others := s.PartyIDs.Difference(party.IDSlice{id})
+			if s.consistent(others, s.interpolate(others[:t+1])) {
+				return &ShareError{ID: id, Err: ErrInconsistentShare}
+			}
+		}
+	}
+	return fmt.Errorf("PublicShares: %w", ErrInconsistentShare)
+}
+
+// consistent reports whether the shares of ids lie on a polynomial f of degree s.Threshold, where f(0) is groupKey.
+//
+// The first t+1 shares define a polynomial f of degree t, whose interpolation at 0 must be the group key.
+// Any other share Aⱼ is f(xⱼ) if and only if the interpolation at 0 over x₂, ..., xₜ₊₁, xⱼ is also the group key,
+// since the Lagrange coefficient of xⱼ is not 0.
+func (s *Public) consistent(ids party.IDSlice, groupKey *ristretto.Element) bool {
+	t := int(s.Threshold)
+	if s.interpolate(ids[:t+1]).Equal(groupKey) != 1 {
+		return false
+	}
+	subset := make(party.IDSlice, t+1)
+	copy(subset, ids[1:t+1])
+	for _, id := range ids[t+1:] {
+		subset[t] = id
+		if s.interpolate(party.NewIDSlice(subset)).Equal(groupKey) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// interpolate returns ∑ 𝛌ⱼ•Aⱼ, for the Lagrange coefficients 𝛌ⱼ of ids.
+func (s *Public) interpolate(ids party.IDSlice) *ristretto.Element {
+	coefficients, _ := ids.LagrangeAll()
+	scalars := make([]*ristretto.Scalar, 0, len(ids))
+	points := make([]*ristretto.Element, 0, len(ids))
+	for _, j := range ids {
+		scalars = append(scalars, coefficients[j])
+		points = append(points, s.Shares[j])
+	}
+	return new(ristretto.Element).VarTimeMultiScalarMult(scalars, points)
+}
@@ -0,0 +1,140 @@
+package eddsa
+
+import (
+	"crypto"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+var (
+	// ErrInsufficientShares is returned by Reconstruct when it is given less than threshold+1 shares.
+	ErrInsufficientShares = errors.New("not enough shares to reconstruct the secret key")
+
+	// ErrShareMismatch is returned by Reconstruct when a SecretShare does not match its public share,
+	// for instance because it belongs to another group.
+	ErrShareMismatch = errors.New("secret share does not match the public share")
+)
+
+// nonceDomainSeparation is used to derive the nonce prefix of a reconstructed PrivateKey.
+var nonceDomainSeparation = []byte("FROST-Ed25519 reconstructed nonce")
+
+// PrivateKey is the full secret key of a group, obtained with Reconstruct.
+// It implements crypto.Signer, and its signatures are accepted by ed25519.Verify with the group key.
+//
+// Unlike an ed25519.PrivateKey, it is not derived from a seed: the secret scalar s was shared during the key generation,
+// and no seed hashes to it. The nonces are derived deterministically as r = SHA-512(prefix ∥ M), like in RFC 8032,
+// but with prefix = SHA-512("FROST-Ed25519 reconstructed nonce" ∥ s), so the signatures differ from those of crypto/ed25519.
+type PrivateKey struct {
+	secret ristretto.Scalar
+	prefix [32]byte
+	public PublicKey
+}
+
+// Reconstruct interpolates the secret key of the group from at least public.Threshold+1 SecretShares.
+//
+// WARNING: the whole point of a threshold key is that the secret key never exists in a single place.
+// Reconstructing it destroys this property, since whoever holds the result can sign alone, and a compromise
+// of this one machine compromises the group. It should only be used for disaster recovery,
+// and the shares should be considered as exposed afterwards.
+//
+// Every share is checked against its public share in public before it is used, and the result must be the group key.
+// The intermediate values are cleared before returning, and the caller should call PrivateKey.Wipe when it is done.
+func Reconstruct(shares map[party.ID]*SecretShare, public *Public) (*PrivateKey, error) {
+	if public == nil || public.GroupKey == nil || len(public.PartyIDs) == 0 {
+		return nil, errors.New("eddsa.Reconstruct: missing public shares")
+	}
+	if uint64(len(shares)) < uint64(public.Threshold)+1 {
+		return nil, fmt.Errorf("eddsa.Reconstruct: %w: got %d, need %d", ErrInsufficientShares, len(shares), uint64(public.Threshold)+1)
+	}
+
+	ids := make([]party.ID, 0, len(shares))
+	var expected ristretto.Element
+	for id, share := range shares {
+		if share == nil || share.ID != id {
+			return nil, fmt.Errorf("eddsa.Reconstruct: share of party %d has a different ID", id)
+		}
+		publicShare, ok := public.Shares[id]
+		if !ok {
+			return nil, fmt.Errorf("eddsa.Reconstruct: party %d: %w", id, ErrShareMismatch)
+		}
+		// The Public field of the share is not trusted
+		if expected.ScalarBaseMult(&share.Secret).Equal(publicShare) != 1 {
+			return nil, fmt.Errorf("eddsa.Reconstruct: party %d: %w", id, ErrShareMismatch)
+		}
+		ids = append(ids, id)
+	}
+
+	coefficients, err := party.NewIDSlice(ids).LagrangeAll()
+	if err != nil {
+		return nil, fmt.Errorf("eddsa.Reconstruct: %w", err)
+	}
+	var sk PrivateKey
+	var term ristretto.Scalar
+	for id, coefficient := range coefficients {
+		// s += lⱼ(0) • sⱼ
+		sk.secret.Add(&sk.secret, term.Multiply(coefficient, &shares[id].Secret))
+		coefficient.Set(ristretto.NewScalar())
+	}
+	term.Set(ristretto.NewScalar())
+
+	sk.public.pk.ScalarBaseMult(&sk.secret)
+	if !sk.public.Equal(public.GroupKey) {
+		sk.Wipe()
+		return nil, errors.New("eddsa.Reconstruct: the shares do not interpolate to the group key")
+	}
+
+	buffer := make([]byte, 0, len(nonceDomainSeparation)+32)
+	buffer = append(buffer, nonceDomainSeparation...)
+	buffer = append(buffer, sk.secret.Bytes()...)
+	digest := sha512.Sum512(buffer)
+	copy(sk.prefix[:], digest[:32])
+	wipe(buffer)
+	wipe(digest[:])
+	return &sk, nil
+}
+
+// Public returns the group key as an ed25519.PublicKey.
+func (sk *PrivateKey) Public() crypto.PublicKey {
+	return sk.public.ToEd25519()
+}
+
+// Sign returns an Ed25519 signature of message, which is accepted by ed25519.Verify.
+// As for ed25519.PrivateKey, opts.HashFunc() must be crypto.Hash(0), and rand is ignored since the signature is deterministic.
+func (sk *PrivateKey) Sign(_ io.Reader, message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if opts.HashFunc() != crypto.Hash(0) {
+		return nil, errors.New("eddsa: cannot sign hashed message")
+	}
+	return sk.sign(message).ToEd25519(), nil
+}
+
+func (sk *PrivateKey) sign(message []byte) *Signature {
+	buffer := make([]byte, 0, len(sk.prefix)+len(message))
+	buffer = append(buffer, sk.prefix[:]...)
+	buffer = append(buffer, message...)
+	digest := sha512.Sum512(buffer)
+	wipe(buffer[:len(sk.prefix)])
+
+	var r ristretto.Scalar
+	_, _ = r.SetUniformBytes(digest[:])
+	wipe(digest[:])
+
+	var sig Signature
+	sig.R.ScalarBaseMult(&r)
+	// S = r + k • s
+	sig.S.MultiplyAdd(ComputeChallenge(&sig.R, &sk.public, message), &sk.secret, &r)
+	r.Set(ristretto.NewScalar())
+	return &sig
+}
+
+// Wipe clears the secret key, which can no longer be used.
+func (sk *PrivateKey) Wipe() {
+	sk.secret.Set(ristretto.NewScalar())
+	wipe(sk.prefix[:])
+}
+
+var _ crypto.Signer = (*PrivateKey)(nil)
@@ -0,0 +1,97 @@
+package eddsa
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// sharedKey returns the secret key, the SecretShares and the Public of a key shared between partyIDs.
+func sharedKey(t *testing.T, partyIDs party.IDSlice, threshold party.Size) (*ristretto.Scalar, map[party.ID]*SecretShare, *Public) {
+	secret := scalar.NewScalarRandom()
+	poly := polynomial.NewPolynomial(threshold, secret)
+	secrets := make(map[party.ID]*SecretShare, len(partyIDs))
+	shares := make(map[party.ID]*ristretto.Element, len(partyIDs))
+	for _, id := range partyIDs {
+		secrets[id] = NewSecretShare(id, poly.Evaluate(id.Scalar()))
+		shares[id] = &secrets[id].Public
+	}
+	public, err := NewPublic(shares, threshold)
+	require.NoError(t, err)
+	return secret, secrets, public
+}
+
+func TestReconstruct(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3, 4, 5}
+	secret, secrets, public := sharedKey(t, partyIDs, 2)
+
+	for _, subset := range []party.IDSlice{{1, 2, 3}, {2, 4, 5}, partyIDs} {
+		shares := make(map[party.ID]*SecretShare)
+		for _, id := range subset {
+			shares[id] = secrets[id]
+		}
+		sk, err := Reconstruct(shares, public)
+		require.NoError(t, err, subset)
+		assert.Equal(t, 1, sk.secret.Equal(secret), subset)
+		assert.Equal(t, public.GroupKeyEd25519(), sk.Public())
+
+		message := []byte("disaster recovery drill")
+		sig, err := sk.Sign(nil, message, crypto.Hash(0))
+		require.NoError(t, err)
+		assert.True(t, ed25519.Verify(public.GroupKeyEd25519(), message, sig))
+		again, _ := sk.Sign(nil, message, crypto.Hash(0))
+		assert.Equal(t, sig, again, "signatures are deterministic")
+
+		_, err = sk.Sign(nil, message, crypto.SHA512)
+		assert.Error(t, err)
+
+		sk.Wipe()
+		assert.Equal(t, 1, sk.secret.Equal(ristretto.NewScalar()))
+	}
+}
+
+func TestReconstruct_Invalid(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3, 4}
+	_, secrets, public := sharedKey(t, partyIDs, 2)
+	_, otherSecrets, _ := sharedKey(t, partyIDs, 2)
+
+	_, err := Reconstruct(map[party.ID]*SecretShare{1: secrets[1], 2: secrets[2]}, public)
+	assert.True(t, errors.Is(err, ErrInsufficientShares), "error %v", err)
+	_, err = Reconstruct(nil, public)
+	assert.True(t, errors.Is(err, ErrInsufficientShares), "error %v", err)
+
+	// A share of another group
+	_, err = Reconstruct(map[party.ID]*SecretShare{1: secrets[1], 2: secrets[2], 3: otherSecrets[3]}, public)
+	assert.True(t, errors.Is(err, ErrShareMismatch), "error %v", err)
+
+	// The Public field of a share is not trusted
+	forged := otherSecrets[3].Copy()
+	forged.Public.Set(&secrets[3].Public)
+	_, err = Reconstruct(map[party.ID]*SecretShare{1: secrets[1], 2: secrets[2], 3: forged}, public)
+	assert.True(t, errors.Is(err, ErrShareMismatch), "error %v", err)
+
+	// A share of a party which is not in the group
+	_, err = Reconstruct(map[party.ID]*SecretShare{1: secrets[1], 2: secrets[2], 9: NewSecretShare(9, &secrets[3].Secret)}, public)
+	assert.True(t, errors.Is(err, ErrShareMismatch), "error %v", err)
+
+	// A share stored under the wrong ID
+	_, err = Reconstruct(map[party.ID]*SecretShare{1: secrets[1], 2: secrets[2], 4: secrets[3]}, public)
+	assert.Error(t, err)
+
+	// Public shares which are not consistent with the threshold interpolate to another key
+	inconsistent := *public
+	inconsistent.Threshold = 1
+	_, err = Reconstruct(map[party.ID]*SecretShare{1: secrets[1], 2: secrets[2]}, &inconsistent)
+	assert.Error(t, err)
+
+	_, err = Reconstruct(secrets, nil)
+	assert.Error(t, err)
+}
@@ -1,8 +1,11 @@
 package eddsa
 
 import (
+	"bytes"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
+	"fmt"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
 	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
@@ -18,6 +21,30 @@ type SecretShare struct {
 
 	// Public is the Shamir share of the group's public key
 	Public ristretto.Element
+
+	// group is the fingerprint of the group key set by SetGroup, or nil if it is unknown.
+	group []byte
+}
+
+// ShareMismatchError is returned by SecretShare.Validate when the SecretShare does not belong to a Public.
+type ShareMismatchError struct {
+	// ID is the party of the SecretShare.
+	ID party.ID
+	// Group is the fingerprint of the group key of the Public.
+	Group []byte
+	// Err is ErrWrongGroup when the SecretShare was set to a different group,
+	// and ErrKeyShareMismatch when the party has no public share or a different one.
+	Err error
+}
+
+// Error implements error
+func (e *ShareMismatchError) Error() string {
+	return fmt.Sprintf("SecretShare: party %d in group %s: %v", e.ID, formatFingerprint(e.Group), e.Err)
+}
+
+// Unwrap returns the reason.
+func (e *ShareMismatchError) Unwrap() error {
+	return e.Err
 }
 
 // NewSecretShare returns a SecretShare given a party.ID and ristretto.Scalar.
@@ -30,35 +57,106 @@ func NewSecretShare(id party.ID, secret *ristretto.Scalar) *SecretShare {
 	return &share
 }
 
+// Wipe overwrites the secret with zero, and the public share with the identity, so that sk can no longer be used.
+// It should be called once sk is no longer needed. Go may have made copies of sk which are not wiped,
+// for instance when it was passed by value, so this is only a best effort.
+func (sk *SecretShare) Wipe() {
+	sk.Secret.Set(ristretto.NewScalar())
+	sk.Public.Set(ristretto.NewIdentityElement())
+}
+
+// SetGroup records the fingerprint of groupKey in sk, so that Validate and the decoding of sk
+// reject it for a different group before any other check. It is set on the shares returned by the key generation.
+func (sk *SecretShare) SetGroup(groupKey *PublicKey) {
+	sk.group = groupKey.fingerprint()
+}
+
+// Group returns the fingerprint of the group key set by SetGroup, or nil if it was not set.
+// It is the first 8 bytes of SHA-256 of the Ed25519 encoding of the group key.
+func (sk *SecretShare) Group() []byte {
+	if sk.group == nil {
+		return nil
+	}
+	return append([]byte(nil), sk.group...)
+}
+
+// Validate checks that sk belongs to public: the group set by SetGroup, if any, must be the one of public,
+// which must contain [s]•G as the public share of sk.ID.
+// Otherwise, the error is a *ShareMismatchError.
+func (sk *SecretShare) Validate(public *Public) error {
+	group := public.GroupKey.fingerprint()
+	if sk.group != nil && !bytes.Equal(sk.group, group) {
+		return &ShareMismatchError{ID: sk.ID, Group: group, Err: ErrWrongGroup}
+	}
+	share, ok := public.Shares[sk.ID]
+	if !ok || !public.PartyIDs.Contains(sk.ID) {
+		return &ShareMismatchError{ID: sk.ID, Group: group, Err: ErrKeyShareMismatch}
+	}
+	var expected ristretto.Element
+	expected.ScalarBaseMult(&sk.Secret)
+	if expected.Equal(share) != 1 {
+		return &ShareMismatchError{ID: sk.ID, Group: group, Err: ErrKeyShareMismatch}
+	}
+	return nil
+}
+
 // MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The encoding is
+//
+//	id ∥ secret ∥ group
+//
+// where group is the fingerprint set by SetGroup, and is omitted if it was not set.
 func (sk *SecretShare) MarshalBinary() ([]byte, error) {
-	data := make([]byte, 0, party.IDByteSize+32)
+	return sk.marshalBinary(true), nil
+}
+
+// marshalBinary returns the encoding of sk, without its group when withGroup is false.
+func (sk *SecretShare) marshalBinary(withGroup bool) []byte {
+	data := make([]byte, 0, party.IDByteSize+32+fingerprintSize)
 	data = append(data, sk.ID.Bytes()...)
 	data = append(data, sk.Secret.Bytes()...)
-	return data, nil
+	if withGroup {
+		data = append(data, sk.group...)
+	}
+	return data
 }
 
+// shortIDSize is the size of the party IDs in the encoding of SecretShare used before they were widened to 4 bytes.
+const shortIDSize = 2
+
 // UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
-func (sk *SecretShare) UnmarshalBinary(data []byte) error {
-	var err error
-	if len(data) != party.IDByteSize+32 {
+// Shares encoded by earlier versions of this library, in which the ID is 2 bytes long, are also accepted.
+func (sk *SecretShare) UnmarshalBinary(data []byte) (err error) {
+	defer recoverPanic(&err)
+
+	switch len(data) {
+	case party.IDByteSize + 32, party.IDByteSize + 32 + fingerprintSize:
+		if sk.ID, err = party.FromBytes(data); err != nil {
+			return err
+		}
+		data = data[party.IDByteSize:]
+	case shortIDSize + 32, shortIDSize + 32 + fingerprintSize:
+		sk.ID = party.ID(binary.BigEndian.Uint16(data))
+		data = data[shortIDSize:]
+	default:
 		return errors.New("SecretShare: data is not the right size")
 	}
-	if sk.ID, err = party.FromBytes(data); err != nil {
-		return err
-	}
-	data = data[party.IDByteSize:]
 
-	if _, err = sk.Secret.SetCanonicalBytes(data); err != nil {
+	if _, err = sk.Secret.SetCanonicalBytes(data[:32]); err != nil {
 		return err
 	}
 	sk.Public.ScalarBaseMult(&sk.Secret)
+	sk.group = nil
+	if len(data) > 32 {
+		sk.group = append([]byte(nil), data[32:]...)
+	}
 	return nil
 }
 
 type jsonSecretShare struct {
 	ID          int    `json:"id"`
 	SecretShare []byte `json:"secret"`
+	Group       []byte `json:"group,omitempty"`
 }
 
 // MarshalJSON implements the json.Marshaler interface.
@@ -66,20 +164,30 @@ func (sk *SecretShare) MarshalJSON() ([]byte, error) {
 	return json.Marshal(jsonSecretShare{
 		ID:          int(sk.ID),
 		SecretShare: sk.Secret.Bytes(),
+		Group:       sk.group,
 	})
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
-func (sk *SecretShare) UnmarshalJSON(data []byte) error {
+func (sk *SecretShare) UnmarshalJSON(data []byte) (err error) {
+	defer recoverPanic(&err)
+
 	var out jsonSecretShare
-	if err := json.Unmarshal(data, &out); err != nil {
+	if err = json.Unmarshal(data, &out); err != nil {
 		return err
 	}
+	if out.ID < 0 || uint64(out.ID) > uint64(party.MaxID) {
+		return errors.New("SecretShare: invalid ID")
+	}
+	if out.Group != nil && len(out.Group) != fingerprintSize {
+		return errors.New("SecretShare: invalid group")
+	}
 	sk.ID = party.ID(out.ID)
 	if _, err := sk.Secret.SetCanonicalBytes(out.SecretShare); err != nil {
 		return err
 	}
 	sk.Public.ScalarBaseMult(&sk.Secret)
+	sk.group = out.Group
 	return nil
 }
 
@@ -89,3 +197,13 @@ func (sk *SecretShare) Equal(sk2 *SecretShare) bool {
 	}
 	return sk.Secret.Equal(&sk2.Secret) == 1
 }
+
+// Copy returns a deep copy of sk.
+func (sk *SecretShare) Copy() *SecretShare {
+	var share SecretShare
+	share.ID = sk.ID
+	share.Secret.Set(&sk.Secret)
+	share.Public.Set(&sk.Public)
+	share.group = sk.Group()
+	return &share
+}
@@ -0,0 +1,189 @@
+package eddsa
+
+import (
+	"crypto/cipher"
+	cryptorand "crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// A SecretShare encrypted with MarshalEncrypted is encoded as
+//
+//	"FROSTSK" ∥ version ∥ time ∥ memory ∥ threads ∥ salt ∥ nonce ∥ id ∥ group ∥ check ∥ XChaCha20-Poly1305(share)
+//
+// where share is the binary encoding of the SecretShare without its group, and group is the first 8 bytes of SHA-256 of the group key set by SetGroup.
+// time and memory are 4 bytes big endian, and threads 1 byte.
+// Argon2id(passphrase, salt, time, memory, threads) gives 64 bytes, the first half being the XChaCha20-Poly1305 key,
+// and the first 16 bytes of the second half being the check value,
+// which is only used to tell a wrong passphrase apart from a modified ciphertext.
+// The whole header is the associated data, so that the ID, the group and the parameters cannot be changed.
+
+var (
+	// ErrWrongPassphrase is returned when decrypting a SecretShare with a different passphrase than the one used to encrypt it.
+	// It is also returned when the salt or the Argon2id parameters were modified.
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+
+	// ErrTamperedShare is returned when an encrypted SecretShare was modified.
+	ErrTamperedShare = errors.New("encrypted share was modified")
+
+	// ErrWrongGroup is returned when a SecretShare whose group was set by SetGroup, for example when it was decrypted,
+	// belongs to a different group than the one expected.
+	ErrWrongGroup = errors.New("share belongs to a different group")
+)
+
+const (
+	encryptedShareMagic   = "FROSTSK"
+	encryptedShareVersion = 1
+
+	// EncryptionTime is the number of passes over the memory of Argon2id used by MarshalEncrypted.
+	EncryptionTime = 3
+	// EncryptionMemory is the memory in KiB of Argon2id used by MarshalEncrypted.
+	EncryptionMemory = 64 * 1024
+	// EncryptionThreads is the degree of parallelism of Argon2id used by MarshalEncrypted.
+	EncryptionThreads = 4
+
+	// maxEncryptionTime and maxEncryptionMemory bound the work done when decrypting untrusted data
+	// to 4 times the work of MarshalEncrypted.
+	maxEncryptionTime   = 2 * EncryptionTime
+	maxEncryptionMemory = 2 * EncryptionMemory
+
+	encryptedShareParamsSize = 4 + 4 + 1
+	encryptedShareSaltSize   = 16
+	encryptedShareNonceSize  = chacha20poly1305.NonceSizeX
+	encryptedShareCheckSize  = 16
+	encryptedShareHeaderSize = len(encryptedShareMagic) + 1 + encryptedShareParamsSize + encryptedShareSaltSize +
+		encryptedShareNonceSize + party.IDByteSize + fingerprintSize + encryptedShareCheckSize
+)
+
+// argon2Params are the parameters of Argon2id embedded in the encryption of a SecretShare.
+type argon2Params struct {
+	time, memory uint32
+	threads      uint8
+}
+
+// defaultArgon2Params are the parameters used by MarshalEncrypted.
+var defaultArgon2Params = argon2Params{time: EncryptionTime, memory: EncryptionMemory, threads: EncryptionThreads}
+
+// encryptionRandReader is the source of the salts and nonces of MarshalEncrypted. It is only replaced by tests.
+var encryptionRandReader io.Reader = cryptorand.Reader
+
+// wipe clears key material. It is only replaced by tests.
+var wipe = func(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// MarshalEncrypted returns sk encrypted under passphrase, and bound to sk.ID and to the group set by SetGroup,
+// so that it can be stored at rest. It can be decrypted with UnmarshalEncryptedSecretShare.
+// An error is returned if the group of sk was not set, which is the case of the shares returned by the key generation.
+func (sk *SecretShare) MarshalEncrypted(passphrase []byte) ([]byte, error) {
+	return sk.marshalEncrypted(passphrase, defaultArgon2Params)
+}
+
+func (sk *SecretShare) marshalEncrypted(passphrase []byte, params argon2Params) ([]byte, error) {
+	if sk.group == nil {
+		return nil, errors.New("SecretShare: the group must be set with SetGroup before encrypting")
+	}
+	random := make([]byte, encryptedShareSaltSize+encryptedShareNonceSize)
+	if _, err := io.ReadFull(encryptionRandReader, random); err != nil {
+		return nil, fmt.Errorf("SecretShare: %w", err)
+	}
+	salt, nonce := random[:encryptedShareSaltSize], random[encryptedShareSaltSize:]
+
+	header := make([]byte, 0, encryptedShareHeaderSize)
+	header = append(header, encryptedShareMagic...)
+	header = append(header, encryptedShareVersion)
+	header = append(header, 0, 0, 0, 0, 0, 0, 0, 0, params.threads)
+	binary.BigEndian.PutUint32(header[len(header)-9:], params.time)
+	binary.BigEndian.PutUint32(header[len(header)-5:], params.memory)
+	header = append(header, random...)
+	header = append(header, sk.ID.Bytes()...)
+	header = append(header, sk.group...)
+
+	aead, check, err := encryptedShareKeys(passphrase, salt, params)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(check)
+	header = append(header, check...)
+
+	plaintext := sk.marshalBinary(false)
+	defer wipe(plaintext)
+	return aead.Seal(header, nonce, plaintext, header), nil
+}
+
+// UnmarshalEncryptedSecretShare decrypts a SecretShare encrypted by MarshalEncrypted, whose group is set to the one
+// it was encrypted with. Validate then rejects it with ErrWrongGroup if it is used with the Public of another group.
+// ErrWrongPassphrase and ErrTamperedShare indicate a wrong passphrase and a modified encryption respectively.
+func UnmarshalEncryptedSecretShare(data, passphrase []byte) (*SecretShare, error) {
+	if len(data) < encryptedShareHeaderSize || string(data[:len(encryptedShareMagic)]) != encryptedShareMagic {
+		return nil, fmt.Errorf("SecretShare: %w", ErrInvalidMessage)
+	}
+	header, ciphertext := data[:encryptedShareHeaderSize], data[encryptedShareHeaderSize:]
+	fields := header[len(encryptedShareMagic):]
+	if version := fields[0]; version != encryptedShareVersion {
+		return nil, fmt.Errorf("SecretShare: unsupported encryption version %d", version)
+	}
+	params := argon2Params{
+		time:    binary.BigEndian.Uint32(fields[1:]),
+		memory:  binary.BigEndian.Uint32(fields[5:]),
+		threads: fields[9],
+	}
+	switch {
+	case params.time == 0 || params.time > maxEncryptionTime:
+		return nil, fmt.Errorf("SecretShare: invalid Argon2id time %d", params.time)
+	case params.threads == 0:
+		return nil, errors.New("SecretShare: invalid Argon2id threads 0")
+	case params.memory < 8*uint32(params.threads) || params.memory > maxEncryptionMemory:
+		return nil, fmt.Errorf("SecretShare: invalid Argon2id memory %d KiB", params.memory)
+	}
+	fields = fields[1+encryptedShareParamsSize:]
+	salt, fields := fields[:encryptedShareSaltSize], fields[encryptedShareSaltSize:]
+	nonce, fields := fields[:encryptedShareNonceSize], fields[encryptedShareNonceSize:]
+	id, _ := party.FromBytes(fields)
+	fields = fields[party.IDByteSize:]
+	group, expectedCheck := fields[:fingerprintSize], fields[fingerprintSize:]
+
+	aead, check, err := encryptedShareKeys(passphrase, salt, params)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(check)
+	if subtle.ConstantTimeCompare(check, expectedCheck) != 1 {
+		return nil, fmt.Errorf("SecretShare: %w", ErrWrongPassphrase)
+	}
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, fmt.Errorf("SecretShare: %w", ErrTamperedShare)
+	}
+	defer wipe(plaintext)
+	var sk SecretShare
+	if err = sk.UnmarshalBinary(plaintext); err != nil || sk.ID != id {
+		return nil, fmt.Errorf("SecretShare: %w", ErrTamperedShare)
+	}
+	sk.group = append([]byte(nil), group...)
+	return &sk, nil
+}
+
+// encryptedShareKeys derives the AEAD and the check value from the passphrase.
+// The derived bytes are wiped before returning, and the caller should wipe the check value.
+func encryptedShareKeys(passphrase, salt []byte, params argon2Params) (cipher.AEAD, []byte, error) {
+	keys := argon2.IDKey(passphrase, salt, params.time, params.memory, params.threads, 64)
+	defer wipe(keys)
+	aead, err := chacha20poly1305.NewX(keys[:chacha20poly1305.KeySize])
+	if err != nil {
+		return nil, nil, fmt.Errorf("SecretShare: %w", err)
+	}
+	check := make([]byte, encryptedShareCheckSize)
+	copy(check, keys[chacha20poly1305.KeySize:])
+	return aead, check, nil
+}
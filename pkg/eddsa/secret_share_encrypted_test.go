@@ -0,0 +1,181 @@
+package eddsa
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// testArgon2Params keeps the tests fast, the parameters are read from the encoding.
+var testArgon2Params = argon2Params{time: 1, memory: 64, threads: 1}
+
+// newGroupSecretShare returns a SecretShare whose group is set to its own public key.
+func newGroupSecretShare(id party.ID, secret *ristretto.Scalar) (*SecretShare, *PublicKey) {
+	sk := NewSecretShare(id, secret)
+	groupKey := NewPublicKeyFromPoint(&sk.Public)
+	sk.SetGroup(groupKey)
+	return sk, groupKey
+}
+
+func TestSecretShare_MarshalEncrypted(t *testing.T) {
+	sk, groupKey := newGroupSecretShare(42, scalar.NewScalarRandom())
+	passphrase := []byte("correct horse battery staple")
+
+	data, err := sk.MarshalEncrypted(passphrase)
+	require.NoError(t, err)
+	decrypted, err := UnmarshalEncryptedSecretShare(data, passphrase)
+	require.NoError(t, err)
+	assert.True(t, sk.Equal(decrypted))
+	assert.Equal(t, 1, sk.Public.Equal(&decrypted.Public))
+	assert.Equal(t, groupKey.fingerprint(), decrypted.Group())
+
+	// The salt and nonce are random
+	other, err := sk.marshalEncrypted(passphrase, testArgon2Params)
+	require.NoError(t, err)
+	again, err := sk.marshalEncrypted(passphrase, testArgon2Params)
+	require.NoError(t, err)
+	assert.NotEqual(t, other, again)
+
+	// The group must be set
+	_, err = NewSecretShare(42, scalar.NewScalarRandom()).MarshalEncrypted(passphrase)
+	assert.Error(t, err)
+}
+
+func TestSecretShare_MarshalEncrypted_Errors(t *testing.T) {
+	sk, _ := newGroupSecretShare(42, scalar.NewScalarRandom())
+	passphrase := []byte("passphrase")
+	data, err := sk.marshalEncrypted(passphrase, testArgon2Params)
+	require.NoError(t, err)
+
+	modified := func(offset int) []byte {
+		result := append([]byte(nil), data...)
+		result[offset] ^= 1
+		return result
+	}
+	paramsOffset := len(encryptedShareMagic) + 1
+	saltOffset := paramsOffset + encryptedShareParamsSize
+	idOffset := saltOffset + encryptedShareSaltSize + encryptedShareNonceSize
+	groupOffset := idOffset + party.IDByteSize
+
+	tests := []struct {
+		name       string
+		data       []byte
+		passphrase []byte
+		want       error
+	}{
+		{"wrong passphrase", data, []byte("passphrasf"), ErrWrongPassphrase},
+		{"empty passphrase", data, nil, ErrWrongPassphrase},
+		{"modified memory", modified(paramsOffset + 7), passphrase, ErrWrongPassphrase},
+		{"modified salt", modified(saltOffset), passphrase, ErrWrongPassphrase},
+		{"modified nonce", modified(saltOffset + encryptedShareSaltSize), passphrase, ErrTamperedShare},
+		{"modified ID", modified(idOffset + 1), passphrase, ErrTamperedShare},
+		{"modified group", modified(groupOffset), passphrase, ErrTamperedShare},
+		{"modified ciphertext", modified(encryptedShareHeaderSize), passphrase, ErrTamperedShare},
+		{"modified tag", modified(len(data) - 1), passphrase, ErrTamperedShare},
+		{"truncated", data[:len(data)-1], passphrase, ErrTamperedShare},
+		{"header only", data[:encryptedShareHeaderSize], passphrase, ErrTamperedShare},
+		{"too short", data[:encryptedShareHeaderSize-1], passphrase, ErrInvalidMessage},
+		{"wrong magic", modified(0), passphrase, ErrInvalidMessage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := UnmarshalEncryptedSecretShare(tt.data, tt.passphrase)
+			assert.True(t, errors.Is(err, tt.want), "error %v, want %v", err, tt.want)
+		})
+	}
+
+	// The parameters are bounded, so that decrypting untrusted data does not exhaust the memory or the CPU
+	invalid := map[string]argon2Params{
+		"zero time":       {time: 0, memory: 64, threads: 1},
+		"large time":      {time: maxEncryptionTime + 1, memory: 64, threads: 1},
+		"zero threads":    {time: 1, memory: 64, threads: 0},
+		"small memory":    {time: 1, memory: 7, threads: 1},
+		"large memory":    {time: 1, memory: maxEncryptionMemory + 1, threads: 1},
+		"memory per lane": {time: 1, memory: 64, threads: 9},
+	}
+	for name, params := range invalid {
+		modified := append([]byte(nil), data...)
+		binary.BigEndian.PutUint32(modified[paramsOffset:], params.time)
+		binary.BigEndian.PutUint32(modified[paramsOffset+4:], params.memory)
+		modified[paramsOffset+8] = params.threads
+		_, err = UnmarshalEncryptedSecretShare(modified, passphrase)
+		assert.Error(t, err, name)
+	}
+	version := append([]byte(nil), data...)
+	version[len(encryptedShareMagic)] = 0
+	_, err = UnmarshalEncryptedSecretShare(version, passphrase)
+	assert.Error(t, err)
+}
+
+func TestUnmarshalEncryptedSecretShare_Validate(t *testing.T) {
+	sk, groupKey := newGroupSecretShare(1, scalar.NewScalarRandom())
+	data, err := sk.marshalEncrypted([]byte("passphrase"), testArgon2Params)
+	require.NoError(t, err)
+	decrypted, err := UnmarshalEncryptedSecretShare(data, []byte("passphrase"))
+	require.NoError(t, err)
+
+	public := &Public{
+		PartyIDs:  party.IDSlice{1},
+		Threshold: 0,
+		Shares:    map[party.ID]*ristretto.Element{1: &sk.Public},
+		GroupKey:  groupKey,
+	}
+	assert.NoError(t, decrypted.Validate(public))
+	public.GroupKey = NewPublicKeyFromPoint(ristretto.NewGeneratorElement())
+	assert.True(t, errors.Is(decrypted.Validate(public), ErrWrongGroup))
+}
+
+func TestSecretShare_MarshalEncrypted_Vector(t *testing.T) {
+	original := encryptionRandReader
+	defer func() { encryptionRandReader = original }()
+	encryptionRandReader = bytes.NewReader(make([]byte, encryptedShareSaltSize+encryptedShareNonceSize))
+
+	sk := NewSecretShare(7, scalar.NewScalarUInt32(42))
+	sk.SetGroup(NewPublicKeyFromPoint(ristretto.NewGeneratorElement()))
+	passphrase := []byte("passphrase")
+	data, err := sk.marshalEncrypted(passphrase, testArgon2Params)
+	require.NoError(t, err)
+
+	const vector = "46524f5354534b01" + "00000001" + "00000040" + "01" +
+		"00000000000000000000000000000000" + "000000000000000000000000000000000000000000000000" +
+		"00000007" + "cb05c9fac26332f9" + "a815c9740491874a16aabc782566bb72" +
+		"12ae5a314d92453b07bf7cea2b1d1c36835f873629e726ed9b0bcde6451bd234dd96a669fa85497a8874a49d6a51f5fa8e9d3c53"
+	assert.Equal(t, vector, hex.EncodeToString(data))
+
+	encoded, _ := hex.DecodeString(vector)
+	decrypted, err := UnmarshalEncryptedSecretShare(encoded, passphrase)
+	require.NoError(t, err)
+	assert.True(t, sk.Equal(decrypted))
+}
+
+func TestUnmarshalEncryptedSecretShare_Wipe(t *testing.T) {
+	sk, _ := newGroupSecretShare(42, scalar.NewScalarRandom())
+	data, err := sk.marshalEncrypted([]byte("passphrase"), testArgon2Params)
+	require.NoError(t, err)
+
+	var wiped [][]byte
+	original := wipe
+	defer func() { wipe = original }()
+	wipe = func(b []byte) {
+		original(b)
+		wiped = append(wiped, b)
+	}
+	_, err = UnmarshalEncryptedSecretShare(data, []byte("passphrase"))
+	require.NoError(t, err)
+
+	// The derived keys, the check value and the plaintext
+	var sizes []int
+	for _, b := range wiped {
+		sizes = append(sizes, len(b))
+		assert.Equal(t, make([]byte, len(b)), b, "key material was not cleared")
+	}
+	assert.ElementsMatch(t, []int{64, encryptedShareCheckSize, len(data) - encryptedShareHeaderSize - 16}, sizes)
+}
@@ -1,9 +1,15 @@
 package eddsa
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"testing"
 
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
 	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 )
 
 // sign generates an Ed25519 compatible signature for the message.
@@ -57,3 +63,208 @@ func TestSecretShare_MarshalBinary(t *testing.T) {
 		t.Error("unmarshalled share is not the same")
 	}
 }
+
+func TestSecretShare_UnmarshalBinary_Legacy(t *testing.T) {
+	// id = 3, secret = 42, encoded by the version of this library in which IDs are 2 bytes long
+	legacy, err := hex.DecodeString("0003" + "2a00000000000000000000000000000000000000000000000000000000000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NewSecretShare(3, scalar.NewScalarUInt32(42))
+
+	var s SecretShare
+	if err = s.UnmarshalBinary(legacy); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Equal(want) || s.Group() != nil {
+		t.Error("unmarshalled share is not the same")
+	}
+
+	group := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	if err = s.UnmarshalBinary(append(legacy, group...)); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Equal(want) || !bytes.Equal(s.Group(), group) {
+		t.Error("unmarshalled share with a group is not the same")
+	}
+
+	// The share is encoded again with the current encoding
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != party.IDByteSize+32+len(group) {
+		t.Errorf("encoding has %d bytes", len(data))
+	}
+	for _, l := range []int{len(legacy) - 1, len(legacy) + 1, len(legacy) + len(group) - 1} {
+		if err = s.UnmarshalBinary(append(legacy, group...)[:l]); err == nil {
+			t.Errorf("an encoding of %d bytes should be rejected", l)
+		}
+	}
+}
+
+func TestSecretShare_Wipe(t *testing.T) {
+	secret := scalar.NewScalarUInt32(42)
+	s := NewSecretShare(42, secret)
+	s.Wipe()
+	if s.Secret.Equal(ristretto.NewScalar()) != 1 {
+		t.Error("the secret was not zeroed")
+	}
+	if s.Public.Equal(ristretto.NewIdentityElement()) != 1 {
+		t.Error("the public share was not reset")
+	}
+	if secret.Equal(scalar.NewScalarUInt32(42)) != 1 {
+		t.Error("the scalar given to NewSecretShare should not be wiped")
+	}
+}
+
+func FuzzSecretShare_UnmarshalBinary(f *testing.F) {
+	s := NewSecretShare(42, scalar.NewScalarUInt32(42))
+	data, err := s.MarshalBinary()
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(data)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var s SecretShare
+		if err := s.UnmarshalBinary(data); err != nil {
+			return
+		}
+		encoded, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, encoded) {
+			t.Error("encoding is not canonical")
+		}
+	})
+}
+
+func FuzzSecretShare_UnmarshalJSON(f *testing.F) {
+	s := NewSecretShare(42, scalar.NewScalarUInt32(42))
+	data, err := s.MarshalJSON()
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(data)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var s SecretShare
+		if err := s.UnmarshalJSON(data); err != nil {
+			return
+		}
+		encoded, err := s.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var s2 SecretShare
+		if err = s2.UnmarshalJSON(encoded); err != nil {
+			t.Fatal(err)
+		}
+		if !s.Equal(&s2) {
+			t.Error("unmarshalled share is not the same")
+		}
+	})
+}
+
+func TestSecretShare_Validate(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	_, secrets, public := sharedKey(t, partyIDs, 1)
+	_, otherSecrets, otherPublic := sharedKey(t, partyIDs, 1)
+
+	for _, id := range partyIDs {
+		if err := secrets[id].Validate(public); err != nil {
+			t.Errorf("party %d: %v", id, err)
+		}
+	}
+
+	var mismatchErr *ShareMismatchError
+	swapped := NewSecretShare(1, &secrets[2].Secret)
+	err := swapped.Validate(public)
+	if !errors.As(err, &mismatchErr) || mismatchErr.ID != 1 || !errors.Is(err, ErrKeyShareMismatch) {
+		t.Fatalf("swapped share: error = %v, want a *ShareMismatchError for party 1", err)
+	}
+	if !bytes.Equal(mismatchErr.Group, public.GroupKey.fingerprint()) {
+		t.Error("the error should contain the fingerprint of the group key")
+	}
+	if err = otherSecrets[1].Validate(public); !errors.Is(err, ErrKeyShareMismatch) {
+		t.Errorf("share of another group: error = %v, want ErrKeyShareMismatch", err)
+	}
+	if err = NewSecretShare(4, &secrets[1].Secret).Validate(public); !errors.Is(err, ErrKeyShareMismatch) {
+		t.Errorf("unknown party: error = %v, want ErrKeyShareMismatch", err)
+	}
+
+	// Once the group is set, it is checked before the public share.
+	bound := otherSecrets[1].Copy()
+	bound.SetGroup(otherPublic.GroupKey)
+	if err = bound.Validate(otherPublic); err != nil {
+		t.Error(err)
+	}
+	if err = bound.Validate(public); !errors.Is(err, ErrWrongGroup) {
+		t.Errorf("share set to another group: error = %v, want ErrWrongGroup", err)
+	}
+}
+
+func TestSecretShare_MarshalGroup(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	_, secrets, public := sharedKey(t, partyIDs, 1)
+	s := secrets[2].Copy()
+	s.SetGroup(public.GroupKey)
+
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != party.IDByteSize+32+8 {
+		t.Fatalf("encoding has %d bytes", len(data))
+	}
+	var decoded SecretShare
+	if err = decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Equal(s) || !bytes.Equal(decoded.Group(), s.Group()) || decoded.Validate(public) != nil {
+		t.Error("unmarshalled share is not the same")
+	}
+
+	dataJSON, err := s.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded = SecretShare{}
+	if err = decoded.UnmarshalJSON(dataJSON); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.Group(), s.Group()) {
+		t.Error("the group was not decoded from JSON")
+	}
+
+	// A modified group is caught by Validate without any scalar multiplication.
+	tampered := append([]byte(nil), data...)
+	tampered[len(tampered)-1] ^= 1
+	if err = decoded.UnmarshalBinary(tampered); err != nil {
+		t.Fatal(err)
+	}
+	if err = decoded.Validate(public); !errors.Is(err, ErrWrongGroup) {
+		t.Errorf("tampered group: error = %v, want ErrWrongGroup", err)
+	}
+	// A modified secret is caught by the comparison with the public share.
+	tampered = append([]byte(nil), data...)
+	tampered[party.IDByteSize] ^= 1
+	if err = decoded.UnmarshalBinary(tampered); err != nil {
+		t.Fatal(err)
+	}
+	if err = decoded.Validate(public); !errors.Is(err, ErrKeyShareMismatch) {
+		t.Errorf("tampered secret: error = %v, want ErrKeyShareMismatch", err)
+	}
+	if err = decoded.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Error("a truncated group should be rejected")
+	}
+	if err = decoded.UnmarshalJSON([]byte(`{"id":2,"secret":"` + base64.StdEncoding.EncodeToString(s.Secret.Bytes()) + `","group":"AA=="}`)); err == nil {
+		t.Error("a short group should be rejected")
+	}
+
+	// Shares encoded before the group was added can still be decoded.
+	legacy, _ := secrets[2].MarshalBinary()
+	if err = decoded.UnmarshalBinary(legacy); err != nil || decoded.Group() != nil || decoded.Validate(public) != nil {
+		t.Errorf("legacy encoding: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package eddsa
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// NewKeyShareFromShamir imports the share of party id of an Ed25519 key split by another Shamir secret sharing tool,
+// so that the parties can sign with the sign protocol without a new key generation.
+//
+// The secret scalar s of the key must have been shared with a polynomial f of degree threshold, where f(0) = s,
+// and share = f(id) is the share of the party whose index is id (0 is not a valid index).
+// For a key of crypto/ed25519, s is not the seed but the clamped first half of SHA-512(seed), reduced modulo ℓ.
+// Shares of the seed cannot be imported, since the seed is hashed before being used.
+//
+// publicShares maps every party to its public share [f(j)]•B, encoded as an Ed25519 public key,
+// which InterpolatePublicShares computes when only threshold+1 of them are known.
+// The shares are checked with Public.Validate against groupKey, and share against the public share of id,
+// so that the returned KeyShare can only sign for groupKey. Its group is set to groupKey.
+func NewKeyShareFromShamir(id party.ID, share *ristretto.Scalar, publicShares map[party.ID]ed25519.PublicKey, threshold party.Size, groupKey ed25519.PublicKey) (*KeyShare, error) {
+	expected, err := NewPublicKeyFromEd25519(groupKey)
+	if err != nil {
+		return nil, fmt.Errorf("eddsa.NewKeyShareFromShamir: group key: %w", err)
+	}
+	shares, err := decodePublicShares(publicShares)
+	if err != nil {
+		return nil, fmt.Errorf("eddsa.NewKeyShareFromShamir: %w", err)
+	}
+	ids := make([]party.ID, 0, len(shares))
+	for j := range shares {
+		ids = append(ids, j)
+	}
+	public := &Public{
+		PartyIDs:  party.NewIDSlice(ids),
+		Threshold: threshold,
+		Shares:    shares,
+		GroupKey:  expected,
+	}
+	if err = public.Validate(); err != nil {
+		return nil, fmt.Errorf("eddsa.NewKeyShareFromShamir: %w", err)
+	}
+
+	key, err := NewKeyShare(NewSecretShare(id, share), public)
+	if err != nil {
+		return nil, fmt.Errorf("eddsa.NewKeyShareFromShamir: %w", err)
+	}
+	key.Secret.SetGroup(expected)
+	return key, nil
+}
+
+// InterpolatePublicShares returns the public shares of all partyIDs, given the public shares of threshold+1 of them,
+// where threshold is the degree of the polynomial used to share the key.
+// The known shares are returned unchanged, and the others are interpolated in the exponent.
+//
+// The interpolated shares are consistent with the known ones by construction, so a wrong known share
+// can only be detected by checking the result against the group key, as NewKeyShareFromShamir does.
+func InterpolatePublicShares(known map[party.ID]ed25519.PublicKey, partyIDs party.IDSlice) (map[party.ID]ed25519.PublicKey, error) {
+	if len(known) == 0 {
+		return nil, errors.New("eddsa.InterpolatePublicShares: no public shares")
+	}
+	shares, err := decodePublicShares(known)
+	if err != nil {
+		return nil, fmt.Errorf("eddsa.InterpolatePublicShares: %w", err)
+	}
+	ids := make([]party.ID, 0, len(shares))
+	for id := range shares {
+		ids = append(ids, id)
+	}
+	knownIDs := party.NewIDSlice(ids)
+
+	result := make(map[party.ID]ed25519.PublicKey, len(partyIDs))
+	scalars := make([]*ristretto.Scalar, len(knownIDs))
+	points := make([]*ristretto.Element, len(knownIDs))
+	for _, id := range partyIDs {
+		if id == 0 {
+			return nil, fmt.Errorf("eddsa.InterpolatePublicShares: %w", party.ErrZeroID)
+		}
+		if key, ok := known[id]; ok {
+			result[id] = append(ed25519.PublicKey(nil), key...)
+			continue
+		}
+		coefficients := lagrangeAt(knownIDs, id.Scalar())
+		for i, j := range knownIDs {
+			scalars[i], points[i] = coefficients[i], shares[j]
+		}
+		result[id] = new(ristretto.Element).VarTimeMultiScalarMult(scalars, points).BytesEd25519()
+	}
+	return result, nil
+}
+
+// decodePublicShares returns the Ristretto points of the public shares given as Ed25519 public keys.
+func decodePublicShares(publicShares map[party.ID]ed25519.PublicKey) (map[party.ID]*ristretto.Element, error) {
+	shares := make(map[party.ID]*ristretto.Element, len(publicShares))
+	for id, key := range publicShares {
+		if id == 0 {
+			return nil, party.ErrZeroID
+		}
+		var share ristretto.Element
+		if _, err := share.SetEd25519Bytes(key); err != nil {
+			return nil, fmt.Errorf("public share of party %d: %w", id, err)
+		}
+		shares[id] = &share
+	}
+	return shares, nil
+}
+
+// lagrangeAt returns the Lagrange coefficients lⱼ(x) of the distinct non-zero ids, in the same order,
+// for the interpolation at x over ids:
+//
+//	        (x - x₀) ... (x - xₖ)
+//	lⱼ(x) = ---------------------
+//	        (xⱼ - x₀) ... (xⱼ - xₖ)
+//
+// where the factors for xⱼ are omitted. x must not be one of the ids.
+func lagrangeAt(ids party.IDSlice, x *ristretto.Scalar) []*ristretto.Scalar {
+	xs := ids.Scalars()
+	coefficients := make([]*ristretto.Scalar, len(ids))
+	one := scalar.NewScalarUInt32(1)
+	var num, denum, diff ristretto.Scalar
+	for j := range xs {
+		num.Set(one)
+		denum.Set(one)
+		for m := range xs {
+			if m == j {
+				continue
+			}
+			num.Multiply(&num, diff.Subtract(x, &xs[m]))
+			denum.Multiply(&denum, diff.Subtract(&xs[j], &xs[m]))
+		}
+		coefficients[j] = new(ristretto.Scalar).Multiply(&num, denum.Invert(&denum))
+	}
+	return coefficients
+}
@@ -0,0 +1,138 @@
+package eddsa
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"errors"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// splitEd25519 shares the secret scalar of the crypto/ed25519 key of seed with a polynomial of degree threshold,
+// as an external Shamir tool would, and returns the public key, the shares and the public shares of partyIDs.
+func splitEd25519(t *testing.T, seed []byte, partyIDs party.IDSlice, threshold int) (ed25519.PublicKey, map[party.ID]*ristretto.Scalar, map[party.ID]ed25519.PublicKey) {
+	publicKey := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+
+	// s is the clamped first half of SHA-512(seed), reduced modulo ℓ
+	digest := sha512.Sum512(seed)
+	s, err := edwards25519.NewScalar().SetBytesWithClamping(digest[:32])
+	require.NoError(t, err)
+	coefficients := []*edwards25519.Scalar{s}
+	for i := 0; i < threshold; i++ {
+		coefficients = append(coefficients, scalar.NewScalarRandom())
+	}
+
+	shares := make(map[party.ID]*ristretto.Scalar, len(partyIDs))
+	publicShares := make(map[party.ID]ed25519.PublicKey, len(partyIDs))
+	for _, id := range partyIDs {
+		// Horner's method for f(id)
+		x := scalar.NewScalarUInt32(uint32(id))
+		share := edwards25519.NewScalar()
+		for i := len(coefficients) - 1; i >= 0; i-- {
+			share.MultiplyAdd(share, x, coefficients[i])
+		}
+		shares[id] = share
+		publicShares[id] = new(edwards25519.Point).ScalarBaseMult(share).Bytes()
+	}
+	return publicKey, shares, publicShares
+}
+
+func TestNewKeyShareFromShamir(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3, 4, 5}
+	publicKey, shares, publicShares := splitEd25519(t, make([]byte, ed25519.SeedSize), partyIDs, 2)
+
+	for _, id := range partyIDs {
+		key, err := NewKeyShareFromShamir(id, shares[id], publicShares, 2, publicKey)
+		require.NoError(t, err, "party %d", id)
+		assert.Equal(t, publicKey, key.Public.GroupKeyEd25519())
+		assert.Equal(t, party.Size(2), key.Threshold())
+		assert.True(t, key.Public.PartyIDs.Equal(partyIDs))
+		assert.Equal(t, key.GroupKey().fingerprint(), key.Secret.Group())
+	}
+
+	// The secret key is the one of crypto/ed25519
+	keys := make(map[party.ID]*SecretShare, 3)
+	var public *Public
+	for _, id := range []party.ID{1, 3, 5} {
+		key, err := NewKeyShareFromShamir(id, shares[id], publicShares, 2, publicKey)
+		require.NoError(t, err)
+		keys[id], public = key.Secret, key.Public
+	}
+	sk, err := Reconstruct(keys, public)
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, sk.Public())
+}
+
+func TestInterpolatePublicShares(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3, 4, 5, 6}
+	publicKey, shares, publicShares := splitEd25519(t, make([]byte, ed25519.SeedSize), partyIDs, 2)
+
+	known := map[party.ID]ed25519.PublicKey{2: publicShares[2], 4: publicShares[4], 5: publicShares[5]}
+	interpolated, err := InterpolatePublicShares(known, partyIDs)
+	require.NoError(t, err)
+	require.Len(t, interpolated, len(partyIDs))
+	for _, id := range partyIDs {
+		assert.Equal(t, publicShares[id], interpolated[id], "party %d", id)
+	}
+	_, err = NewKeyShareFromShamir(1, shares[1], interpolated, 2, publicKey)
+	assert.NoError(t, err)
+
+	// A wrong known share is only detected with the group key
+	known[4] = publicShares[1]
+	interpolated, err = InterpolatePublicShares(known, partyIDs)
+	require.NoError(t, err)
+	_, err = NewKeyShareFromShamir(1, shares[1], interpolated, 2, publicKey)
+	assert.True(t, errors.Is(err, ErrInconsistentShare), err)
+
+	_, err = InterpolatePublicShares(nil, partyIDs)
+	assert.Error(t, err)
+	_, err = InterpolatePublicShares(known, party.IDSlice{0, 1})
+	assert.True(t, errors.Is(err, party.ErrZeroID), err)
+}
+
+func TestNewKeyShareFromShamir_Invalid(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3, 4, 5}
+	publicKey, shares, publicShares := splitEd25519(t, make([]byte, ed25519.SeedSize), partyIDs, 2)
+	otherKey, otherShares, otherPublicShares := splitEd25519(t, []byte("an other seed of thirty-two byte"), partyIDs, 2)
+
+	// Another group key
+	_, err := NewKeyShareFromShamir(1, shares[1], publicShares, 2, otherKey)
+	assert.True(t, errors.Is(err, ErrInconsistentShare), err)
+
+	// A wrong threshold
+	_, err = NewKeyShareFromShamir(1, shares[1], publicShares, 1, publicKey)
+	assert.True(t, errors.Is(err, ErrInconsistentShare), err)
+
+	// A single wrong public share is found with the group key
+	corrupted := make(map[party.ID]ed25519.PublicKey, len(publicShares))
+	for id, share := range publicShares {
+		corrupted[id] = share
+	}
+	corrupted[3] = otherPublicShares[3]
+	_, err = NewKeyShareFromShamir(1, shares[1], corrupted, 2, publicKey)
+	var shareErr *ShareError
+	require.True(t, errors.As(err, &shareErr), err)
+	assert.Equal(t, party.ID(3), shareErr.ID)
+
+	// The secret share of another party or group
+	var mismatchErr *ShareMismatchError
+	_, err = NewKeyShareFromShamir(1, shares[2], publicShares, 2, publicKey)
+	assert.True(t, errors.As(err, &mismatchErr) && mismatchErr.ID == 1, err)
+	_, err = NewKeyShareFromShamir(1, otherShares[1], publicShares, 2, publicKey)
+	assert.True(t, errors.Is(err, ErrKeyShareMismatch), err)
+	_, err = NewKeyShareFromShamir(6, shares[1], publicShares, 2, publicKey)
+	assert.True(t, errors.Is(err, ErrKeyShareMismatch), err)
+
+	// A public share which is not in the prime order subgroup
+	corrupted[3] = new(edwards25519.Point).Add(edwards25519.NewGeneratorPoint(), mustPoint(t, mustHex(t, "c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a"))).Bytes()
+	_, err = NewKeyShareFromShamir(1, shares[1], corrupted, 2, publicKey)
+	assert.Error(t, err)
+	_, err = NewKeyShareFromShamir(1, shares[1], publicShares, 2, ed25519.PublicKey{1, 2, 3})
+	assert.Error(t, err)
+}
@@ -1,10 +1,11 @@
 package eddsa
 
 import (
-	"crypto/sha512"
+	"crypto/ed25519"
 	"errors"
 	"fmt"
 
+	"github.com/taurusgroup/frost-ed25519/pkg/hashing"
 	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 )
 
@@ -12,6 +13,15 @@ const MessageLengthSig = 32 + 32
 
 var ErrInvalidMessage = errors.New("invalid message")
 
+// recoverPanic converts a panic occurring while decoding into an error wrapping ErrInvalidMessage,
+// and should be deferred by the decoding functions of this package.
+// Decoders validate their input and are not expected to panic, but invalid data must never crash the process.
+func recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("eddsa: decoding panicked (%v): %w", r, ErrInvalidMessage)
+	}
+}
+
 // Signature represents an EdDSA signature.
 // When converted to bytes with .ToEd25519(), the signature is compatible with
 // the standard ed25519 library.
@@ -28,19 +38,29 @@ func (sig *Signature) ToEd25519() []byte {
 	return out
 }
 
+// Verify reports whether sig is a valid signature of message by pub, as checked by ed25519.Verify.
+// It gives the same result as PublicKey.Verify for the corresponding PublicKey.
+func Verify(pub ed25519.PublicKey, message []byte, sig *Signature) bool {
+	if len(pub) != ed25519.PublicKeySize {
+		return false
+	}
+	return ed25519.Verify(pub, message, sig.ToEd25519())
+}
+
 // ComputeChallenge computes the value H(R, A, M), and assumes nothing about whether M is hashed.
 func ComputeChallenge(R *ristretto.Element, groupKey *PublicKey, message []byte) *ristretto.Scalar {
-	var s ristretto.Scalar
-	data := make([]byte, 0, 64+len(message))
-	data = append(data, R.BytesEd25519()...)
-	data = append(data, groupKey.ToEd25519()...)
-	data = append(data, message...)
-	digest := sha512.Sum512(data)
-	_, err := s.SetUniformBytes(digest[:])
-	if err != nil {
-		panic(err)
-	}
-	return &s
+	return computeChallenge(R, groupKey.ToEd25519(), message)
+}
+
+// computeChallenge is ComputeChallenge with the Ed25519 encoding of the group key,
+// which is expensive to compute since it requires a scalar multiplication.
+func computeChallenge(R *ristretto.Element, groupKey ed25519.PublicKey, message []byte) *ristretto.Scalar {
+	return computeChallengeWithPrefix(nil, R, groupKey, message)
+}
+
+// computeChallengeWithPrefix computes H(prefix ∥ R ∥ A ∥ M), where prefix is the dom2 prefix of the Ed25519 variants.
+func computeChallengeWithPrefix(prefix []byte, R *ristretto.Element, groupKey ed25519.PublicKey, message []byte) *ristretto.Scalar {
+	return hashing.Challenge(prefix, R, groupKey, message)
 }
 
 //
@@ -54,9 +74,10 @@ func (sig *Signature) MarshalBinary() ([]byte, error) {
 }
 
 // UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
-func (sig *Signature) UnmarshalBinary(data []byte) error {
-	var err error
-	if len(data) < MessageLengthSig {
+func (sig *Signature) UnmarshalBinary(data []byte) (err error) {
+	defer recoverPanic(&err)
+
+	if len(data) != MessageLengthSig {
 		return fmt.Errorf("sig: %w", ErrInvalidMessage)
 	}
 
@@ -95,3 +116,11 @@ func (sig *Signature) Equal(other interface{}) bool {
 	}
 	return true
 }
+
+// Copy returns a deep copy of sig.
+func (sig *Signature) Copy() *Signature {
+	var sigCopy Signature
+	sigCopy.R.Set(&sig.R)
+	sigCopy.S.Set(&sig.S)
+	return &sigCopy
+}
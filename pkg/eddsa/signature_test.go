@@ -32,6 +32,9 @@ func TestSignature_Verify(t *testing.T) {
 
 	// Check using ed25519.Verify
 	assert.True(t, ed25519.Verify(pk.ToEd25519(), []byte(sampleMessage), sig.ToEd25519()))
+	assert.True(t, Verify(pk.ToEd25519(), []byte(sampleMessage), sig))
+	assert.False(t, Verify(pk.ToEd25519(), []byte("other message"), sig))
+	assert.False(t, Verify(pk.ToEd25519()[:31], []byte(sampleMessage), sig))
 }
 
 func TestSignatureEncode_Decode(t *testing.T) {
@@ -45,3 +48,20 @@ func TestSignatureEncode_Decode(t *testing.T) {
 	assert.Equal(t, 1, signature.R.Equal(&signatureOutput.R))
 	assert.Equal(t, 1, signature.S.Equal(&signatureOutput.S))
 }
+
+func FuzzSignature_UnmarshalBinary(f *testing.F) {
+	signature, _, err := generateSignature()
+	require.NoError(f, err)
+	data, err := signature.MarshalBinary()
+	require.NoError(f, err)
+	f.Add(data)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var sig Signature
+		if err := sig.UnmarshalBinary(data); err != nil {
+			return
+		}
+		encoded, err := sig.MarshalBinary()
+		require.NoError(t, err)
+		assert.Equal(t, data, encoded)
+	})
+}
@@ -0,0 +1,81 @@
+package eddsa
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+)
+
+// The text encoding of a Signature is the lowercase hex encoding of the 64 bytes returned by ToEd25519,
+// so that it can be checked by any Ed25519 implementation. It is used for JSON, and by database/sql,
+// where a Signature can be stored in a text column, or in a binary column as the 64 bytes themselves.
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (sig Signature) MarshalText() ([]byte, error) {
+	text := make([]byte, hex.EncodedLen(MessageLengthSig))
+	hex.Encode(text, sig.ToEd25519())
+	return text, nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The text must be the hex encoding of an Ed25519 signature whose R is a point of the prime order subgroup,
+// and S is canonical, which is the case for every signature produced by this library or by crypto/ed25519.
+func (sig *Signature) UnmarshalText(text []byte) error {
+	if len(text) != hex.EncodedLen(MessageLengthSig) {
+		return fmt.Errorf("sig: %w", ErrInvalidMessage)
+	}
+	data := make([]byte, MessageLengthSig)
+	if _, err := hex.Decode(data, text); err != nil {
+		return fmt.Errorf("sig: %w", err)
+	}
+	return sig.setEd25519(data)
+}
+
+// String implements the fmt.Stringer interface, and returns the text encoding of sig.
+func (sig Signature) String() string {
+	text, _ := sig.MarshalText()
+	return string(text)
+}
+
+// Value implements the driver.Valuer interface, and returns the text encoding of sig.
+func (sig Signature) Value() (driver.Value, error) {
+	return sig.String(), nil
+}
+
+// Scan implements the sql.Scanner interface.
+// It accepts the text encoding as a string or []byte, and the 64 bytes returned by ToEd25519 as a []byte.
+// sig is only modified if src is a valid signature.
+func (sig *Signature) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		return sig.UnmarshalText([]byte(src))
+	case []byte:
+		if len(src) == MessageLengthSig {
+			return sig.setEd25519(src)
+		}
+		return sig.UnmarshalText(src)
+	case nil:
+		return fmt.Errorf("sig: cannot scan NULL: %w", ErrInvalidMessage)
+	default:
+		return fmt.Errorf("sig: cannot scan %T: %w", src, ErrInvalidMessage)
+	}
+}
+
+// setEd25519 sets sig to the Ed25519 signature data, which is the output of ToEd25519.
+// sig is only modified if data is valid.
+func (sig *Signature) setEd25519(data []byte) (err error) {
+	defer recoverPanic(&err)
+
+	if len(data) != MessageLengthSig {
+		return fmt.Errorf("sig: %w", ErrInvalidMessage)
+	}
+	var out Signature
+	if _, err = out.R.SetEd25519Bytes(data[:32]); err != nil {
+		return fmt.Errorf("sig.R: %w", err)
+	}
+	if _, err = out.S.SetCanonicalBytes(data[32:]); err != nil {
+		return fmt.Errorf("sig.S: %w", err)
+	}
+	*sig = out
+	return nil
+}
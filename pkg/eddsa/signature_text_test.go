@@ -0,0 +1,165 @@
+package eddsa
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubDriver is a database/sql driver with a single column of a single row,
+// set by any Exec and returned by any Query.
+type stubDriver struct {
+	value driver.Value
+}
+
+type stubConn struct{ d *stubDriver }
+
+type stubStmt struct{ d *stubDriver }
+
+type stubRows struct {
+	value driver.Value
+	done  bool
+}
+
+func (d *stubDriver) Open(string) (driver.Conn, error) { return stubConn{d}, nil }
+
+func (c stubConn) Prepare(string) (driver.Stmt, error) { return stubStmt(c), nil }
+func (stubConn) Close() error                          { return nil }
+func (stubConn) Begin() (driver.Tx, error)             { return nil, errors.New("not supported") }
+
+func (stubStmt) Close() error  { return nil }
+func (stubStmt) NumInput() int { return -1 }
+func (s stubStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.value = args[0]
+	return driver.RowsAffected(1), nil
+}
+func (s stubStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &stubRows{value: s.d.value}, nil
+}
+
+func (*stubRows) Columns() []string { return []string{"signature"} }
+func (*stubRows) Close() error      { return nil }
+func (r *stubRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.value
+	return nil
+}
+
+var stubDriverCount int
+
+// openStubDB returns a sql.DB backed by a new stubDriver.
+func openStubDB(t *testing.T) (*sql.DB, *stubDriver) {
+	d := &stubDriver{}
+	stubDriverCount++
+	name := fmt.Sprintf("eddsa-stub-%d", stubDriverCount)
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db, d
+}
+
+func TestSignature_MarshalText(t *testing.T) {
+	sig, pk, err := generateSignature()
+	require.NoError(t, err)
+
+	text, err := sig.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(sig.ToEd25519()), string(text))
+	assert.Equal(t, string(text), sig.String())
+	assert.Equal(t, string(text), fmt.Sprint(sig))
+
+	var decoded Signature
+	require.NoError(t, decoded.UnmarshalText(text))
+	assert.True(t, sig.Equal(&decoded))
+	require.NoError(t, decoded.UnmarshalText([]byte(strings.ToUpper(string(text)))))
+	assert.True(t, pk.Verify([]byte(sampleMessage), &decoded))
+
+	type record struct {
+		Signature  Signature  `json:"signature"`
+		Optional   *Signature `json:"optional,omitempty"`
+		Signatures []Signature
+	}
+	data, err := json.Marshal(record{Signature: *sig, Optional: sig, Signatures: []Signature{*sig}})
+	require.NoError(t, err)
+	assert.Equal(t, `{"signature":"`+string(text)+`","optional":"`+string(text)+`","Signatures":["`+string(text)+`"]}`, string(data))
+	var r record
+	require.NoError(t, json.Unmarshal(data, &r))
+	assert.True(t, sig.Equal(&r.Signature))
+	assert.True(t, sig.Equal(r.Optional))
+	assert.True(t, sig.Equal(&r.Signatures[0]))
+}
+
+func TestSignature_UnmarshalText_Invalid(t *testing.T) {
+	sig, _, err := generateSignature()
+	require.NoError(t, err)
+	valid := sig.ToEd25519()
+	withR := func(r string) []byte {
+		data, _ := hex.DecodeString(r)
+		return append(data, valid[32:]...)
+	}
+	withS := func(s string) []byte {
+		data, _ := hex.DecodeString(s)
+		return append(append([]byte(nil), valid[:32]...), data...)
+	}
+
+	for name, text := range map[string]string{
+		"empty":     "",
+		"short":     hex.EncodeToString(valid[:63]),
+		"long":      hex.EncodeToString(append(valid, 0)),
+		"not hex":   strings.Repeat("zz", 64),
+		"odd":       hex.EncodeToString(valid)[:127] + " ",
+		"invalid R": hex.EncodeToString(withR(strings.Repeat("ff", 31) + "7f")),
+		// The point of order 2 (0, -1), which is not in the prime order subgroup.
+		"torsion R":       hex.EncodeToString(withR("ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")),
+		"non-canonical S": hex.EncodeToString(withS("edd3f55c1a631258d69cf7a2def9de1400000000000000000000000000000010")),
+		"large S":         hex.EncodeToString(withS(strings.Repeat("ff", 32))),
+	} {
+		decoded := *sig.Copy()
+		assert.Error(t, decoded.UnmarshalText([]byte(text)), name)
+		assert.True(t, sig.Equal(&decoded), "%s: the signature was modified", name)
+		assert.Error(t, decoded.Scan(text), name)
+	}
+}
+
+func TestSignature_Scan(t *testing.T) {
+	sig, pk, err := generateSignature()
+	require.NoError(t, err)
+	db, d := openStubDB(t)
+
+	_, err = db.Exec("INSERT INTO signatures VALUES (?)", sig)
+	require.NoError(t, err)
+	assert.Equal(t, sig.String(), d.value, "signatures are stored as text")
+
+	var decoded Signature
+	require.NoError(t, db.QueryRow("SELECT signature FROM signatures").Scan(&decoded))
+	assert.True(t, sig.Equal(&decoded))
+	assert.True(t, pk.Verify([]byte(sampleMessage), &decoded))
+
+	// Binary columns holding the Ed25519 signature, and text columns returned as []byte
+	for _, value := range []driver.Value{sig.ToEd25519(), []byte(sig.String())} {
+		d.value = value
+		decoded = Signature{}
+		require.NoError(t, db.QueryRow("SELECT signature FROM signatures").Scan(&decoded))
+		assert.True(t, sig.Equal(&decoded))
+	}
+
+	d.value = nil
+	assert.Error(t, db.QueryRow("SELECT signature FROM signatures").Scan(&decoded))
+	d.value = int64(42)
+	assert.Error(t, db.QueryRow("SELECT signature FROM signatures").Scan(&decoded))
+	d.value = sig.ToEd25519()[:63]
+	assert.Error(t, db.QueryRow("SELECT signature FROM signatures").Scan(&decoded))
+}
@@ -0,0 +1,99 @@
+package eddsa
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// ErrInvalidPrivateKey is wrapped by a *PrivateKeyError.
+var ErrInvalidPrivateKey = errors.New("invalid Ed25519 private key")
+
+// PrivateKeyError is returned by SplitEd25519 when the secret scalar of an ed25519.PrivateKey cannot be shared
+// so that the group key is its public key.
+type PrivateKeyError struct {
+	// Reason describes why the key cannot be split.
+	Reason string
+}
+
+// Error implements error
+func (e *PrivateKeyError) Error() string {
+	return fmt.Sprintf("eddsa: %s: %s", ErrInvalidPrivateKey.Error(), e.Reason)
+}
+
+// Unwrap returns ErrInvalidPrivateKey.
+func (e *PrivateKeyError) Unwrap() error {
+	return ErrInvalidPrivateKey
+}
+
+// SplitEd25519 shares the secret scalar of key between partyIDs with a polynomial of degree threshold,
+// as a trusted dealer, and returns the SecretShare of every party and the public shares.
+// The group key of the result is the public key of key, so that the parties can sign for it with the sign protocol.
+//
+// The shared secret is the scalar s of RFC 8032, Section 5.1.5: the first half of SHA-512(seed), clamped,
+// and reduced modulo ℓ, which does not change [s]•B. The public half of key must be [s]•B,
+// otherwise the error is a *PrivateKeyError, as it is when key does not have ed25519.PrivateKeySize bytes.
+//
+// The full key exists on the machine running SplitEd25519, which should be the one already holding key.
+// The shares must then be sent to their owner over confidential channels, and key deleted.
+// The secret values computed here are cleared before returning.
+func SplitEd25519(key ed25519.PrivateKey, partyIDs party.IDSlice, threshold party.Size) (map[party.ID]*SecretShare, *Public, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, nil, &PrivateKeyError{Reason: fmt.Sprintf("it has %d bytes instead of %d", len(key), ed25519.PrivateKeySize)}
+	}
+	partyIDs = party.NewIDSlice(partyIDs)
+	if err := party.ValidateThreshold(threshold, partyIDs.N()); err != nil {
+		return nil, nil, fmt.Errorf("eddsa.SplitEd25519: %w", err)
+	}
+	for i, id := range partyIDs {
+		if id == 0 {
+			return nil, nil, fmt.Errorf("eddsa.SplitEd25519: %w", party.ErrZeroID)
+		}
+		if i > 0 && id == partyIDs[i-1] {
+			return nil, nil, fmt.Errorf("eddsa.SplitEd25519: duplicate ID %d", id)
+		}
+	}
+
+	digest := sha512.Sum512(key.Seed())
+	defer wipe(digest[:])
+	var s ristretto.Scalar
+	defer s.Set(ristretto.NewScalar())
+	if _, err := s.SetBytesWithClamping(digest[:32]); err != nil {
+		return nil, nil, &PrivateKeyError{Reason: err.Error()}
+	}
+	if s.Equal(ristretto.NewScalar()) == 1 {
+		return nil, nil, &PrivateKeyError{Reason: "its secret scalar is zero"}
+	}
+	groupKey := NewPublicKeyFromPoint(new(ristretto.Element).ScalarBaseMult(&s))
+	if !bytes.Equal(groupKey.ToEd25519(), key.Public().(ed25519.PublicKey)) {
+		return nil, nil, &PrivateKeyError{Reason: "its public key does not match its seed"}
+	}
+
+	poly := polynomial.NewPolynomial(threshold, &s)
+	defer poly.Reset()
+	shares := make(map[party.ID]*SecretShare, partyIDs.N())
+	publicShares := make(map[party.ID]*ristretto.Element, partyIDs.N())
+	for _, id := range partyIDs {
+		share := poly.Evaluate(id.Scalar())
+		shares[id] = NewSecretShare(id, share)
+		share.Set(ristretto.NewScalar())
+		publicShares[id] = new(ristretto.Element).Set(&shares[id].Public)
+	}
+	public, err := NewPublic(publicShares, threshold)
+	if err != nil {
+		return nil, nil, fmt.Errorf("eddsa.SplitEd25519: %w", err)
+	}
+	if !public.GroupKey.Equal(groupKey) {
+		return nil, nil, errors.New("eddsa.SplitEd25519: the shares do not interpolate to the public key")
+	}
+	for _, share := range shares {
+		share.SetGroup(public.GroupKey)
+	}
+	return shares, public, nil
+}
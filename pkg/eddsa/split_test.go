@@ -0,0 +1,80 @@
+package eddsa
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+func TestSplitEd25519(t *testing.T) {
+	// The key of test 1 of RFC 8032, Section 7.1, and a random one
+	_, random, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rfcKey := ed25519.NewKeyFromSeed(mustHex(t, "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"))
+	assert.Equal(t, mustHex(t, "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"), []byte(rfcKey.Public().(ed25519.PublicKey)))
+
+	partyIDs := party.IDSlice{1, 2, 3, 4, 5}
+	for _, key := range []ed25519.PrivateKey{rfcKey, random} {
+		publicKey := key.Public().(ed25519.PublicKey)
+		shares, public, err := SplitEd25519(key, partyIDs, 2)
+		require.NoError(t, err)
+
+		assert.Equal(t, []byte(publicKey), []byte(public.GroupKeyEd25519()))
+		assert.True(t, public.PartyIDs.Equal(partyIDs))
+		assert.Equal(t, party.Size(2), public.Threshold)
+		require.NoError(t, public.Validate())
+		for id, share := range shares {
+			assert.NoError(t, share.Validate(public), "party %d", id)
+		}
+
+		// Any threshold+1 shares sign for the original public key
+		subset := map[party.ID]*SecretShare{2: shares[2], 4: shares[4], 5: shares[5]}
+		sk, err := Reconstruct(subset, public)
+		require.NoError(t, err)
+		sig, err := sk.Sign(nil, []byte("message"), crypto.Hash(0))
+		require.NoError(t, err)
+		assert.True(t, ed25519.Verify(publicKey, []byte("message"), sig))
+	}
+}
+
+func TestSplitEd25519_Invalid(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, other, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	partyIDs := party.IDSlice{1, 2, 3}
+
+	// The public half of another key
+	mismatched := append(append(ed25519.PrivateKey(nil), key.Seed()...), other[32:]...)
+	for name, key := range map[string]ed25519.PrivateKey{
+		"seed only":    key.Seed(),
+		"truncated":    key[:63],
+		"other public": mismatched,
+		"nil":          nil,
+	} {
+		_, _, err := SplitEd25519(key, partyIDs, 1)
+		var keyErr *PrivateKeyError
+		assert.True(t, errors.As(err, &keyErr), "%s: error = %v, want *PrivateKeyError", name, err)
+		assert.True(t, errors.Is(err, ErrInvalidPrivateKey), name)
+	}
+
+	for name, tc := range map[string]struct {
+		partyIDs  party.IDSlice
+		threshold party.Size
+	}{
+		"zero threshold":     {partyIDs, 0},
+		"threshold too high": {partyIDs, 3},
+		"zero ID":            {party.IDSlice{0, 1, 2}, 1},
+		"duplicate ID":       {party.IDSlice{1, 2, 2}, 1},
+	} {
+		_, _, err := SplitEd25519(key, tc.partyIDs, tc.threshold)
+		assert.Error(t, err, name)
+		assert.False(t, errors.Is(err, ErrInvalidPrivateKey), name)
+	}
+}
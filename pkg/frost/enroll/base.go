@@ -0,0 +1,198 @@
+// Package enroll implements the enrollment of a new party to an existing key, without changing the group key
+// nor the shares of the other parties, for example to extend a 3-of-5 committee to 3-of-6.
+//
+// The key is shared with a polynomial f of degree t, and the new party j must obtain f(j).
+// The helpers are at least t+1 parties holding a share sᵢ = f(i), so that f(j) = ∑ λᵢ•sᵢ,
+// where λᵢ is the Lagrange coefficient of helper i for the interpolation at j over the helpers.
+// Sending wᵢ = λᵢ•sᵢ to the new party would reveal sᵢ, so each helper i instead shares wᵢ between the helpers,
+// with a polynomial hᵢ of degree n-1 where n is the number of helpers, and commits to it.
+// Since the public share Aᵢ of each helper is known, the other parties check that the commitment to hᵢ(0) is λᵢ•Aᵢ.
+// Each helper k verifies and sums the shares hᵢ(k) it receives, and sends μₖ•∑ᵢ hᵢ(k) to the new party in an Enroll message,
+// where μₖ is its Lagrange coefficient for the interpolation at 0 over the helpers.
+// The new party verifies each value against the commitments, and their sum is ∑ᵢ hᵢ(0) = f(j).
+// It only learns the sum of the polynomials hᵢ, and any n-1 helpers only learn n-1 values of the polynomial of the last one.
+//
+// The protocol reuses the KeyGen1 and KeyGen2 messages between the helpers, as in package reshare.
+// Once it has finished, all parties obtain the public shares extended with the one of the new party,
+// and the other parties holding a share can compute them with eddsa.Public.WithParty.
+package enroll
+
+import (
+	"errors"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+type (
+	round0 struct {
+		*state.BaseRound
+
+		// Helpers are the parties contributing to the share of the new party NewID.
+		Helpers party.IDSlice
+		NewID   party.ID
+
+		// Public contains the public shares extended with the one of NewID.
+		Public *eddsa.Public
+
+		// HelperShares maps each helper i to λᵢ•Aᵢ, the commitment to the constant of its polynomial.
+		HelperShares map[party.ID]*ristretto.Element
+
+		// Coefficients maps each helper k to its Lagrange coefficient μₖ for the interpolation at 0 over the helpers,
+		// by which it multiplies the value it sends in its Enroll message.
+		Coefficients map[party.ID]*ristretto.Scalar
+
+		// Secret is first set to our share multiplied by our Lagrange coefficient λ at NewID, if we are a helper.
+		// It is then set to the sum of the shares we receive from the helpers.
+		// If we are the new party, it is finally set to the sum of the Enroll messages, which is our share.
+		Secret ristretto.Scalar
+
+		// Polynomial used to share λ•s between the helpers, if we are a helper.
+		Polynomial *polynomial.Polynomial
+
+		// Commitments contains the commitment polynomials of all helpers, including ours.
+		Commitments map[party.ID]*polynomial.Exponent
+
+		// CommitmentsSum is the sum of all commitments, against which the Enroll messages are verified.
+		CommitmentsSum *polynomial.Exponent
+
+		Output *Output
+	}
+	round1 struct {
+		*round0
+	}
+	round2 struct {
+		*round1
+	}
+	round3 struct {
+		*round2
+	}
+)
+
+// NewRound returns the first round of the enrollment of newID to the key of public by the helpers,
+// and the Output which is filled once it has finished.
+//
+// helpers must contain at least public.Threshold+1 of public.PartyIDs, and newID must not be one of them.
+// All parties must know public, and secret is our share if we are a helper, or nil if we are the new party.
+// The parties of the protocol are the helpers and the new party.
+func NewRound(selfID party.ID, secret *eddsa.SecretShare, public *eddsa.Public, helpers party.IDSlice, newID party.ID) (state.Round, *Output, error) {
+	helpers = party.NewIDSlice(helpers)
+
+	if newID == 0 {
+		return nil, nil, party.ErrZeroID
+	}
+	if public.PartyIDs.Contains(newID) {
+		return nil, nil, errors.New("the new party already has a share of the key")
+	}
+	if !helpers.IsSubsetOf(public.PartyIDs) {
+		return nil, nil, errors.New("the helpers must be parties of the key")
+	}
+	if helpers.N() < public.Threshold+1 {
+		return nil, nil, errors.New("there must be at least T+1 helpers")
+	}
+
+	extended, err := public.WithParty(newID)
+	if err != nil {
+		return nil, nil, err
+	}
+	partyIDs := helpers.Copy()
+	if err = partyIDs.Add(newID); err != nil {
+		return nil, nil, err
+	}
+	baseRound, err := state.NewBaseRound(selfID, partyIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	coefficients, err := helpers.LagrangeAllAt(newID)
+	if err != nil {
+		return nil, nil, err
+	}
+	enrollCoefficients, err := helpers.LagrangeAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	r := round0{
+		BaseRound:    baseRound,
+		Helpers:      helpers,
+		NewID:        newID,
+		Public:       extended,
+		HelperShares: make(map[party.ID]*ristretto.Element, helpers.N()),
+		Coefficients: enrollCoefficients,
+		Commitments:  make(map[party.ID]*polynomial.Exponent, helpers.N()),
+		Output:       &Output{},
+	}
+	for _, id := range helpers {
+		r.HelperShares[id] = new(ristretto.Element).ScalarMult(coefficients[id], public.Shares[id])
+	}
+
+	if r.isHelper() {
+		if secret == nil || secret.ID != selfID {
+			return nil, nil, errors.New("the secret share of a helper must be given")
+		}
+		if err = secret.Validate(public); err != nil {
+			return nil, nil, err
+		}
+		r.Secret.Multiply(coefficients[selfID], &secret.Secret)
+	}
+	return &r, r.Output, nil
+}
+
+// Reset is called by the State when the protocol finishes or is aborted.
+// It zeroes the secret Secret, which holds the share of the new party once the protocol finished,
+// and the coefficients of our Polynomial, and sets the commitments to the identity.
+// The SecretShare of the Output is a copy and is not wiped.
+func (round *round0) Reset() {
+	round.Secret.Set(ristretto.NewScalar())
+	if round.Polynomial != nil {
+		round.Polynomial.Reset()
+	}
+	if round.CommitmentsSum != nil {
+		round.CommitmentsSum.Reset()
+	}
+	for _, p := range round.Commitments {
+		p.Reset()
+	}
+	round.Output = nil
+}
+
+// isHelper returns true if we contribute to the share of the new party.
+func (round *round0) isHelper() bool {
+	return round.Helpers.Contains(round.SelfID())
+}
+
+// isNewParty returns true if we are the party which is added.
+func (round *round0) isNewParty() bool {
+	return round.SelfID() == round.NewID
+}
+
+// ---
+// Messages
+// ---
+
+func (round *round0) AcceptedMessageTypes() []messages.MessageType {
+	return []messages.MessageType{messages.MessageTypeNone, messages.MessageTypeKeyGen1, messages.MessageTypeKeyGen2, messages.MessageTypeEnroll}
+}
+
+// Senders implements state.SenderFilter, since only the helpers send messages,
+// KeyGen2 messages are only sent to the other helpers, and Enroll messages to the new party.
+func (round *round0) Senders(msgType messages.MessageType) party.IDSlice {
+	switch {
+	case msgType == messages.MessageTypeKeyGen2 && !round.isHelper():
+		return party.IDSlice{}
+	case msgType == messages.MessageTypeEnroll && !round.isNewParty():
+		return party.IDSlice{}
+	}
+	return round.Helpers
+}
+
+// MessageLimits implements state.Limiter, since the commitments in KeyGen1 messages depend on the number of helpers.
+func (round *round0) MessageLimits() messages.Limits {
+	return messages.Limits{
+		Threshold: round.Helpers.N() - 1,
+	}
+}
@@ -0,0 +1,236 @@
+package enroll
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/sign"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+var (
+	oldIDs = party.IDSlice{1, 2, 3, 4, 5}
+	newID  = party.ID(6)
+)
+
+// setup returns the states and rounds of the enrollment of party 6 to a 3-of-5 key, as well as its secret and public shares.
+func setup(t *testing.T, helperIDs party.IDSlice) (map[party.ID]*state.State, map[party.ID]*round0, map[party.ID]*Output,
+	map[party.ID]*eddsa.SecretShare, *eddsa.Public) {
+	_, secrets := helpers.GenerateSecrets(oldIDs, 2)
+	public := helpers.GeneratePublic(2, secrets)
+
+	partyIDs := helperIDs.Union(party.IDSlice{newID})
+	states := make(map[party.ID]*state.State, partyIDs.N())
+	rounds := make(map[party.ID]*round0, partyIDs.N())
+	outputs := make(map[party.ID]*Output, partyIDs.N())
+	for _, id := range partyIDs {
+		r, output, err := NewRound(id, secrets[id], public, helperIDs, newID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rounds[id], outputs[id] = r.(*round0), output
+		if states[id], err = state.NewBaseState(r, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return states, rounds, outputs, secrets, public
+}
+
+// run executes the protocol with helpers.RunProtocol.
+func run(t *testing.T, states map[party.ID]*state.State, tamper func(*messages.Message)) {
+	if err := helpers.RunProtocol(states, tamper); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// signWith runs a signing protocol between signers, and checks the signature under the group key of public.
+func signWith(t *testing.T, signers party.IDSlice, secrets map[party.ID]*eddsa.SecretShare, public *eddsa.Public, message []byte) *eddsa.Signature {
+	states := make(map[party.ID]*state.State, signers.N())
+	outputs := make(map[party.ID]*sign.Output, signers.N())
+	for _, id := range signers {
+		r, output, err := sign.NewRound(signers, secrets[id], public, message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[id] = output
+		if states[id], err = state.NewBaseState(r, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run(t, states, nil)
+	for _, id := range signers {
+		if err := states[id].WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+		if !ed25519.Verify(public.GroupKeyEd25519(), message, outputs[id].Signature.ToEd25519()) {
+			t.Fatalf("party %d: the signature of %v is invalid", id, signers)
+		}
+	}
+	return outputs[signers[0]].Signature
+}
+
+func TestEnroll(t *testing.T) {
+	for _, helperIDs := range []party.IDSlice{oldIDs, {1, 3, 5}} {
+		states, rounds, outputs, secrets, public := setup(t, helperIDs)
+
+		// A signature produced before the enrollment
+		message := []byte("signed before the enrollment")
+		oldSignature := signWith(t, party.IDSlice{1, 2, 3}, secrets, public, message)
+
+		run(t, states, nil)
+
+		expected, err := public.WithParty(newID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for id, s := range states {
+			if err := s.WaitForError(); err != nil {
+				t.Fatalf("party %d: %v", id, err)
+			}
+			output := outputs[id]
+			if !expected.Equal(output.Public) {
+				t.Fatalf("party %d: the public shares differ from WithParty", id)
+			}
+			if rounds[id].Secret.Equal(scalar.NewScalarUInt32(0)) != 1 {
+				t.Errorf("party %d: the secret of the round was not zeroed", id)
+			}
+			if id != newID {
+				if output.SecretKey != nil {
+					t.Errorf("party %d is a helper, but obtained a share", id)
+				}
+				continue
+			}
+			if err := output.SecretKey.Validate(expected); err != nil {
+				t.Fatalf("party %d: %v", id, err)
+			}
+			if _, err := output.KeyShare(); err != nil {
+				t.Errorf("party %d: %v", id, err)
+			}
+		}
+
+		newPublic := outputs[newID].Public
+		if !newPublic.GroupKey.Equal(public.GroupKey) {
+			t.Fatal("the group key changed")
+		}
+		if newPublic.Threshold != public.Threshold || !newPublic.PartyIDs.Equal(party.IDSlice{1, 2, 3, 4, 5, 6}) {
+			t.Fatalf("the new committee is %d-of-%v", newPublic.Threshold+1, newPublic.PartyIDs)
+		}
+		if err := newPublic.Validate(); err != nil {
+			t.Fatal(err)
+		}
+
+		// The old signature is still valid under the group key of the extended committee
+		if !ed25519.Verify(newPublic.GroupKeyEd25519(), message, oldSignature.ToEd25519()) {
+			t.Error("the signature produced before the enrollment is no longer valid")
+		}
+
+		// The old parties still sign with their unchanged shares, and the new party signs with them
+		secrets[newID] = outputs[newID].SecretKey
+		message = []byte("signed after the enrollment")
+		signWith(t, party.IDSlice{1, 2, 3}, secrets, newPublic, message)
+		signWith(t, party.IDSlice{2, 4, 6}, secrets, newPublic, message)
+	}
+}
+
+func TestEnroll_Masked(t *testing.T) {
+	// The value sent by a helper to the new party is not its bare contribution λᵢ•sᵢ
+	states, _, _, secrets, _ := setup(t, party.IDSlice{1, 3, 5})
+	coefficients, err := party.IDSlice{1, 3, 5}.LagrangeAllAt(newID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var enrolls int
+	run(t, states, func(msg *messages.Message) {
+		if msg.Type != messages.MessageTypeEnroll {
+			return
+		}
+		enrolls++
+		var bare ristretto.Scalar
+		bare.Multiply(coefficients[msg.From], &secrets[msg.From].Secret)
+		if msg.Enroll.Share.Equal(&bare) == 1 {
+			t.Errorf("party %d sent its bare contribution", msg.From)
+		}
+	})
+	if enrolls != 3 {
+		t.Fatalf("%d Enroll messages were delivered, want 3", enrolls)
+	}
+}
+
+func TestEnroll_CheatingHelper(t *testing.T) {
+	// Helper 2 sends a wrong value to the new party, which aborts and blames it
+	states, _, _, _, _ := setup(t, oldIDs)
+	run(t, states, func(msg *messages.Message) {
+		if msg.Type == messages.MessageTypeEnroll && msg.From == 2 {
+			msg.Enroll.Share.Add(&msg.Enroll.Share, scalar.NewScalarUInt32(1))
+		}
+	})
+	var stateErr *state.Error
+	if err := states[newID].WaitForError(); !errors.As(err, &stateErr) || !errors.Is(err, ErrInvalidEnroll) {
+		t.Fatalf("party %d: error = %v, want ErrInvalidEnroll", newID, err)
+	}
+	if !stateErr.Culprits().Equal(party.IDSlice{2}) || stateErr.Kind() != state.KindVSSFailure {
+		t.Errorf("culprits = %v, kind = %v", stateErr.Culprits(), stateErr.Kind())
+	}
+
+	// Helper 4 sends a wrong share to helper 1, which aborts and blames it
+	states, _, _, _, _ = setup(t, oldIDs)
+	run(t, states, func(msg *messages.Message) {
+		if msg.Type == messages.MessageTypeKeyGen2 && msg.From == 4 && msg.To == 1 {
+			msg.KeyGen2.Share.Add(&msg.KeyGen2.Share, scalar.NewScalarUInt32(1))
+		}
+	})
+	if err := states[1].WaitForError(); !errors.As(err, &stateErr) || !errors.Is(err, ErrValidateShare) {
+		t.Fatalf("party 1: error = %v, want ErrValidateShare", err)
+	}
+	if !stateErr.Culprits().Equal(party.IDSlice{4}) {
+		t.Errorf("culprits = %v", stateErr.Culprits())
+	}
+
+	// Helper 3 contributes another secret than its share, and all other parties blame it
+	states, rounds, _, _, _ := setup(t, oldIDs)
+	scalar.SetScalarRandom(&rounds[3].Secret)
+	run(t, states, nil)
+	for id, s := range states {
+		if id == 3 {
+			continue
+		}
+		err := s.WaitForError()
+		if !errors.As(err, &stateErr) || !errors.Is(err, ErrInvalidCommitment) {
+			t.Fatalf("party %d: error = %v, want ErrInvalidCommitment", id, err)
+		}
+		if !stateErr.Culprits().Equal(party.IDSlice{3}) || stateErr.Kind() != state.KindInvalidCommitment {
+			t.Errorf("party %d: culprits = %v, kind = %v", id, stateErr.Culprits(), stateErr.Kind())
+		}
+	}
+}
+
+func TestNewRound_Invalid(t *testing.T) {
+	_, secrets := helpers.GenerateSecrets(oldIDs, 2)
+	public := helpers.GeneratePublic(2, secrets)
+
+	for name, tc := range map[string]struct {
+		selfID  party.ID
+		secret  *eddsa.SecretShare
+		helpers party.IDSlice
+		newID   party.ID
+	}{
+		"zero ID":        {1, secrets[1], oldIDs, 0},
+		"existing party": {1, secrets[1], oldIDs, 3},
+		"too few":        {1, secrets[1], party.IDSlice{1, 2}, newID},
+		"unknown helper": {1, secrets[1], party.IDSlice{1, 2, 11}, newID},
+		"missing secret": {1, nil, oldIDs, newID},
+		"other secret":   {1, secrets[2], oldIDs, newID},
+		"not a party":    {11, nil, oldIDs, newID},
+	} {
+		if _, _, err := NewRound(tc.selfID, tc.secret, public, tc.helpers, tc.newID); err == nil {
+			t.Errorf("%s: NewRound() should fail", name)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package enroll
+
+import (
+	"errors"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+)
+
+// Output contains the public shares extended with the one of the new party, and its share if we are the new party.
+// Helpers only obtain Public, since their own share is unchanged, and SecretKey is nil.
+type Output struct {
+	Public    *eddsa.Public
+	SecretKey *eddsa.SecretShare
+}
+
+// KeyShare returns a copy of the output as an eddsa.KeyShare, once the protocol has finished.
+func (o *Output) KeyShare() (*eddsa.KeyShare, error) {
+	if o.Public == nil {
+		return nil, errors.New("enroll.Output: the protocol has not finished")
+	}
+	if o.SecretKey == nil {
+		return nil, errors.New("enroll.Output: the party is not the new party")
+	}
+	return eddsa.NewKeyShare(o.SecretKey.Copy(), o.Public.Copy())
+}
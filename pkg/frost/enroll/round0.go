@@ -0,0 +1,37 @@
+package enroll
+
+import (
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/zk"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+func (round *round0) ProcessMessage(*messages.Message) *state.Error {
+	return nil
+}
+
+func (round *round0) GenerateMessages() ([]*messages.Message, *state.Error) {
+	if !round.isHelper() {
+		return nil, nil
+	}
+
+	// Share λᵢ•sᵢ between the n helpers with a polynomial of degree n-1, and commit to it
+	round.Polynomial = polynomial.NewPolynomial(round.Helpers.N()-1, &round.Secret)
+	commitments := polynomial.NewPolynomialExponent(round.Polynomial)
+	round.Commitments[round.SelfID()] = commitments
+
+	// The session ID is used as context, to prevent the proof from being replayed in another execution
+	sessionID := round.SessionID()
+	proof := zk.NewSchnorrProof(round.SelfID(), commitments.Constant(), sessionID[:], &round.Secret)
+
+	// Secret now holds the sum of the shares we receive from the helpers, starting with our own.
+	// This overwrites λᵢ•sᵢ, which is no longer needed.
+	round.Secret.Set(round.Polynomial.Evaluate(round.SelfID().Scalar()))
+
+	return []*messages.Message{messages.NewKeyGen1(round.SelfID(), proof, commitments.Copy())}, nil
+}
+
+func (round *round0) NextRound() state.Round {
+	return &round1{round}
+}
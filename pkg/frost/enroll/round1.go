@@ -0,0 +1,72 @@
+package enroll
+
+import (
+	"errors"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+var (
+	ErrValidateProof = errors.New("ZK Schnorr failed")
+
+	// ErrInvalidCommitment is returned when the commitment of a helper to the constant of its polynomial
+	// is not its public share multiplied by its Lagrange coefficient at the new party,
+	// so that it does not contribute its share.
+	ErrInvalidCommitment = errors.New("commitment does not match the public share of the helper")
+)
+
+func (round *round1) ProcessMessage(msg *messages.Message) *state.Error {
+	sessionID := round.SessionID()
+	from := msg.From
+
+	public := msg.KeyGen1.Commitments.Constant()
+	if !msg.KeyGen1.Proof.Verify(from, public, sessionID[:]) {
+		return state.NewErrorWithKind(from, state.KindInvalidProof, ErrValidateProof)
+	}
+	if public.Equal(round.HelperShares[from]) != 1 {
+		return state.NewErrorWithKind(from, state.KindInvalidCommitment, ErrInvalidCommitment)
+	}
+
+	round.Commitments[from] = msg.KeyGen1.Commitments
+	return nil
+}
+
+func (round *round1) GenerateMessages() ([]*messages.Message, *state.Error) {
+	// The commitments are summed in the order of the helpers, and their constant is the public share of the new party.
+	commitments := make([]*polynomial.Exponent, 0, len(round.Helpers))
+	for _, id := range round.Helpers {
+		commitments = append(commitments, round.Commitments[id])
+	}
+	sum, err := polynomial.Sum(commitments)
+	if err != nil {
+		return nil, state.NewError(0, err)
+	}
+	round.CommitmentsSum = sum
+
+	if !round.isHelper() {
+		return nil, nil
+	}
+	msgsOut := make([]*messages.Message, 0, len(round.Helpers)-1)
+	var x ristretto.Scalar
+	for _, id := range round.Helpers {
+		if id == round.SelfID() {
+			continue
+		}
+		msgsOut = append(msgsOut, messages.NewKeyGen2(round.SelfID(), id, round.Polynomial.Evaluate(id.ScalarTo(&x))))
+	}
+
+	// All shares were sent, so we no longer require the polynomial
+	round.Polynomial.Reset()
+	return msgsOut, nil
+}
+
+func (round *round1) NextRound() state.Round {
+	return &round2{round}
+}
+
+func (round *round1) MessageType() messages.MessageType {
+	return messages.MessageTypeKeyGen1
+}
@@ -0,0 +1,49 @@
+package enroll
+
+import (
+	"errors"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+var ErrValidateShare = errors.New("VSS failed to validate")
+
+func (round *round2) ProcessMessage(msg *messages.Message) *state.Error {
+	id := msg.From
+	share := &msg.KeyGen2.Share
+
+	var computedShareExp ristretto.Element
+	computedShareExp.ScalarBaseMult(share)
+
+	shareExp := round.Commitments[id].Evaluate(round.SelfID().Scalar())
+
+	if computedShareExp.Equal(shareExp) != 1 {
+		return state.NewErrorWithKind(id, state.KindVSSFailure, ErrValidateShare)
+	}
+	round.Secret.Add(&round.Secret, share)
+	return nil
+}
+
+func (round *round2) GenerateMessages() ([]*messages.Message, *state.Error) {
+	if !round.isHelper() {
+		return nil, nil
+	}
+
+	// Secret is the evaluation at our ID of the sum of the helpers' polynomials,
+	// which the new party interpolates at 0.
+	var share ristretto.Scalar
+	share.Multiply(round.Coefficients[round.SelfID()], &round.Secret)
+	round.Secret.Set(ristretto.NewScalar())
+
+	return []*messages.Message{messages.NewEnroll(round.SelfID(), round.NewID, &share)}, nil
+}
+
+func (round *round2) NextRound() state.Round {
+	return &round3{round}
+}
+
+func (round *round2) MessageType() messages.MessageType {
+	return messages.MessageTypeKeyGen2
+}
@@ -0,0 +1,53 @@
+package enroll
+
+import (
+	"errors"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// ErrInvalidEnroll is returned when the value sent by a helper in its Enroll message
+// does not match the sum of the commitments evaluated at its ID.
+var ErrInvalidEnroll = errors.New("enroll share does not match the commitments")
+
+func (round *round3) ProcessMessage(msg *messages.Message) *state.Error {
+	id := msg.From
+	share := &msg.Enroll.Share
+
+	var computedShareExp ristretto.Element
+	computedShareExp.ScalarBaseMult(share)
+
+	shareExp := round.CommitmentsSum.Evaluate(id.Scalar())
+	shareExp.ScalarMult(round.Coefficients[id], shareExp)
+
+	if computedShareExp.Equal(shareExp) != 1 {
+		return state.NewErrorWithKind(id, state.KindVSSFailure, ErrInvalidEnroll)
+	}
+	round.Secret.Add(&round.Secret, share)
+	return nil
+}
+
+func (round *round3) GenerateMessages() ([]*messages.Message, *state.Error) {
+	// This holds since the constant of each helper's commitments was checked, unless public was not consistent.
+	if round.CommitmentsSum.Constant().Equal(round.Public.Shares[round.NewID]) != 1 {
+		return nil, state.NewError(0, errors.New("the new share differs from its interpolation from public"))
+	}
+
+	round.Output.Public = round.Public
+	if round.isNewParty() {
+		round.Output.SecretKey = eddsa.NewSecretShare(round.SelfID(), &round.Secret)
+		round.Output.SecretKey.SetGroup(round.Public.GroupKey)
+	}
+	return nil, nil
+}
+
+func (round *round3) NextRound() state.Round {
+	return nil
+}
+
+func (round *round3) MessageType() messages.MessageType {
+	return messages.MessageTypeEnroll
+}
@@ -4,8 +4,11 @@ import (
 	"time"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/enroll"
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/keygen"
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/repair"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/reshare"
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/sign"
 	"github.com/taurusgroup/frost-ed25519/pkg/state"
 )
@@ -13,12 +16,13 @@ import (
 // NewKeygenState returns a state.State which coordinates the multiple rounds.
 // The second parameter is the output of the protocol and will be filled with the output once the protocol has finished executing.
 // It is safe to use the output when State.WaitForError() returns nil.
-func NewKeygenState(selfID party.ID, partyIDs party.IDSlice, threshold party.Size, timeout time.Duration) (*state.State, *keygen.Output, error) {
+// The options opts are passed on to state.NewBaseState.
+func NewKeygenState(selfID party.ID, partyIDs party.IDSlice, threshold party.Size, timeout time.Duration, opts ...state.Option) (*state.State, *keygen.Output, error) {
 	round, output, err := keygen.NewRound(selfID, partyIDs, threshold)
 	if err != nil {
 		return nil, nil, err
 	}
-	s, err := state.NewBaseState(round, timeout)
+	s, err := state.NewBaseState(round, timeout, opts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -27,15 +31,229 @@ func NewKeygenState(selfID party.ID, partyIDs party.IDSlice, threshold party.Siz
 	return s, output, nil
 }
 
+// NewEncryptedKeygenState is like NewKeygenState, but the shares sent in the second round are encrypted to their
+// recipient, so that the messages can be relayed by an untrusted party, as described by keygen.WithEncryptedShares.
+// All parties must be created with the same constructor, since the messages of both modes are not compatible.
+func NewEncryptedKeygenState(selfID party.ID, partyIDs party.IDSlice, threshold party.Size, timeout time.Duration, opts ...state.Option) (*state.State, *keygen.Output, error) {
+	return NewKeygenStateWithOptions(selfID, partyIDs, threshold, timeout, []keygen.Option{keygen.WithEncryptedShares()}, opts...)
+}
+
+// NewKeygenStateWithOptions is like NewKeygenState, but the keygen protocol is modified by keygenOpts,
+// such as keygen.WithEncryptedShares, keygen.WithEchoRound, keygen.WithBlame, keygen.WithConfirmation and keygen.WithRobust.
+// All parties must be created with the same keygen options, since they determine the messages that are exchanged.
+func NewKeygenStateWithOptions(selfID party.ID, partyIDs party.IDSlice, threshold party.Size, timeout time.Duration, keygenOpts []keygen.Option, opts ...state.Option) (*state.State, *keygen.Output, error) {
+	round, output, err := keygen.NewRound(selfID, partyIDs, threshold, keygenOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := state.NewBaseState(round, timeout, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, output, nil
+}
+
+// NewObserverKeygenState returns a state.State which follows the keygen between partyIDs as the observer selfID,
+// without receiving a share, as described by keygen.NewObserverRound. keygenOpts must contain keygen.WithObservers
+// with selfID, and the options of the shareholders, which must also be given keygen.WithObservers.
+// The output contains the eddsa.Public computed by the observer once the protocol has finished.
+func NewObserverKeygenState(selfID party.ID, partyIDs party.IDSlice, threshold party.Size, timeout time.Duration, keygenOpts []keygen.Option, opts ...state.Option) (*state.State, *keygen.ObserverOutput, error) {
+	round, output, err := keygen.NewObserverRound(selfID, partyIDs, threshold, keygenOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := state.NewBaseState(round, timeout, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, output, nil
+}
+
+// NewBatchKeygenState is like NewKeygenState, but generates k independent keys in a single execution,
+// as described by keygen.NewBatchRound. All parties must use the same k.
+func NewBatchKeygenState(selfID party.ID, partyIDs party.IDSlice, threshold party.Size, k int, timeout time.Duration, opts ...state.Option) (*state.State, *keygen.BatchOutput, error) {
+	round, output, err := keygen.NewBatchRound(selfID, partyIDs, threshold, k)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := state.NewBaseState(round, timeout, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, output, nil
+}
+
+// NewReshareState returns a state.State which reshares the key of public from dealers to newPartyIDs,
+// with the new threshold, as described in package reshare. secret is our share if we are a dealer, or nil otherwise.
+// The group key is unchanged, and the output contains our new share if we are one of newPartyIDs.
+// The options opts are passed on to state.NewBaseState.
+func NewReshareState(selfID party.ID, secret *eddsa.SecretShare, public *eddsa.Public, dealers, newPartyIDs party.IDSlice, threshold party.Size, timeout time.Duration, opts ...state.Option) (*state.State, *reshare.Output, error) {
+	round, output, err := reshare.NewRound(selfID, secret, public, dealers, newPartyIDs, threshold)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := state.NewBaseState(round, timeout, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, output, nil
+}
+
+// NewRemovalState returns a state.State which removes the parties removed from the committee of key,
+// by resharing the key between the remaining parties, as described by reshare.NewRemovalRound.
+// The group key and the threshold are unchanged, and the output contains our new share.
+// The options opts are passed on to state.NewBaseState.
+func NewRemovalState(key *eddsa.KeyShare, removed party.IDSlice, timeout time.Duration, opts ...state.Option) (*state.State, *reshare.Output, error) {
+	round, output, err := reshare.NewRemovalRound(key, removed)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := state.NewBaseState(round, timeout, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, output, nil
+}
+
+// NewEnrollState returns a state.State which adds the party newID to the key of public, with the help of helpers,
+// as described in package enroll. secret is our share if we are a helper, or nil if we are the new party.
+// The group key and the shares of the other parties are unchanged, and the output contains the share of the new party.
+// The options opts are passed on to state.NewBaseState.
+func NewEnrollState(selfID party.ID, secret *eddsa.SecretShare, public *eddsa.Public, helpers party.IDSlice, newID party.ID, timeout time.Duration, opts ...state.Option) (*state.State, *enroll.Output, error) {
+	round, output, err := enroll.NewRound(selfID, secret, public, helpers, newID)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := state.NewBaseState(round, timeout, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, output, nil
+}
+
+// NewRepairState returns a state.State which recovers the share of lostID in the key of public, with the help of helpers,
+// as described in package repair. secret is our share if we are a helper, or nil if we are the party recovering its share.
+// The group key and the shares of the other parties are unchanged, and the output contains the recovered share.
+// The options opts are passed on to state.NewBaseState.
+func NewRepairState(selfID party.ID, secret *eddsa.SecretShare, public *eddsa.Public, helpers party.IDSlice, lostID party.ID, timeout time.Duration, opts ...state.Option) (*state.State, *repair.Output, error) {
+	return NewRepairStateWithOptions(selfID, secret, public, helpers, lostID, timeout, nil, opts...)
+}
+
+// NewRepairStateWithOptions is like NewRepairState, but the repair protocol is modified by repairOpts, such as repair.WithRandom.
+func NewRepairStateWithOptions(selfID party.ID, secret *eddsa.SecretShare, public *eddsa.Public, helpers party.IDSlice, lostID party.ID, timeout time.Duration, repairOpts []repair.Option, opts ...state.Option) (*state.State, *repair.Output, error) {
+	round, output, err := repair.NewRound(selfID, secret, public, helpers, lostID, repairOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := state.NewBaseState(round, timeout, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, output, nil
+}
+
 // NewSignState returns a state.State which coordinates the multiple rounds.
 // The second parameter is the output of the protocol and will be filled with the output once the protocol has finished executing.
 // It is safe to use the output when State.WaitForError() returns nil.
-func NewSignState(partyIDs party.IDSlice, secret *eddsa.SecretShare, shares *eddsa.Public, message []byte, timeout time.Duration) (*state.State, *sign.Output, error) {
+// The options opts are passed on to state.NewBaseState.
+func NewSignState(partyIDs party.IDSlice, secret *eddsa.SecretShare, shares *eddsa.Public, message []byte, timeout time.Duration, opts ...state.Option) (*state.State, *sign.Output, error) {
 	round, output, err := sign.NewRound(partyIDs, secret, shares, message)
 	if err != nil {
 		return nil, nil, err
 	}
-	s, _ := state.NewBaseState(round, timeout)
+	s, _ := state.NewBaseState(round, timeout, opts...)
+
+	return s, output, nil
+}
 
+// NewSignStateWithOptions is like NewSignState, but the sign protocol is modified by signOpts, such as sign.WithTweak.
+// All signers must be created with the same sign options.
+func NewSignStateWithOptions(partyIDs party.IDSlice, secret *eddsa.SecretShare, shares *eddsa.Public, message []byte, timeout time.Duration, signOpts []sign.Option, opts ...state.Option) (*state.State, *sign.Output, error) {
+	round, output, err := sign.NewRound(partyIDs, secret, shares, message, signOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := state.NewBaseState(round, timeout, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
 	return s, output, nil
 }
+
+// NewSignStateFromKeyShare is like NewSignStateWithOptions, with the secret and public shares of key.
+func NewSignStateFromKeyShare(partyIDs party.IDSlice, key *eddsa.KeyShare, message []byte, timeout time.Duration, signOpts []sign.Option, opts ...state.Option) (*state.State, *sign.Output, error) {
+	return NewSignStateWithOptions(partyIDs, key.Secret, key.Public, message, timeout, signOpts, opts...)
+}
+
+// NewSignStateWithShareSigner is like NewSignStateWithOptions, for the signer selfID whose nonces and secret key share
+// are held by signer, as with sign.NewRoundWithShareSigner.
+func NewSignStateWithShareSigner(partyIDs party.IDSlice, selfID party.ID, shares *eddsa.Public, message []byte, signer sign.ShareSigner, timeout time.Duration, signOpts []sign.Option, opts ...state.Option) (*state.State, *sign.Output, error) {
+	round, output, err := sign.NewRoundWithShareSigner(partyIDs, selfID, shares, message, signer, signOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := state.NewBaseState(round, timeout, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, output, nil
+}
+
+// NewOnlineSignState is like NewSignStateWithOptions, for a session whose nonces were preprocessed, as with sign.NewOnlineRound.
+// It consists of a single round, in which each signer sends its Sign2 message.
+func NewOnlineSignState(partyIDs party.IDSlice, secret *eddsa.SecretShare, shares *eddsa.Public, message []byte, nonces *sign.NoncePair, commitments map[party.ID]*sign.NonceCommitment, timeout time.Duration, signOpts []sign.Option, opts ...state.Option) (*state.State, *sign.Output, error) {
+	round, output, err := sign.NewOnlineRound(partyIDs, secret, shares, message, nonces, commitments, signOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := state.NewBaseState(round, timeout, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, output, nil
+}
+
+// RestoreKeygenState returns a state.State which resumes a keygen protocol execution from data,
+// which was obtained by marshalling the result of State.Snapshot.
+// The returned Output is a new one, and will be filled once the protocol has finished executing.
+func RestoreKeygenState(data []byte, timeout time.Duration, opts ...state.Option) (*state.State, *keygen.Output, error) {
+	var snapshot state.Snapshot
+	if err := snapshot.UnmarshalBinary(data); err != nil {
+		return nil, nil, err
+	}
+	round, output, err := keygen.RestoreRound(snapshot.RoundNumber, snapshot.Round)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := state.RestoreState(round, &snapshot, timeout, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, output, nil
+}
+
+// NewKeygenOutput returns a state.Output which gives access to a copy of output,
+// once the keygen protocol executed by s has finished.
+// s and output should be the values returned by NewKeygenState.
+func NewKeygenOutput(s *state.State, output *keygen.Output) *state.Output[*keygen.Output] {
+	return state.NewOutput(s, func() *keygen.Output {
+		out := &keygen.Output{
+			Public:    output.Public.Copy(),
+			SecretKey: output.SecretKey.Copy(),
+			Excluded:  output.Excluded.Copy(),
+		}
+		if output.Commitments != nil {
+			out.Commitments = output.Commitments.Copy()
+		}
+		return out
+	})
+}
+
+// NewSignOutput returns a state.Output which gives access to a copy of the signature,
+// once the sign protocol executed by s has finished.
+// s and output should be the values returned by NewSignState.
+func NewSignOutput(s *state.State, output *sign.Output) *state.Output[*eddsa.Signature] {
+	return state.NewOutput(s, func() *eddsa.Signature {
+		return output.Signature.Copy()
+	})
+}
@@ -2,6 +2,7 @@ package keygen
 
 import (
 	"errors"
+	"io"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
 	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
@@ -29,9 +30,75 @@ type (
 		// CommitmentsSum is the sum of all commitments, we use it to compute public key shares
 		CommitmentsSum *polynomial.Exponent
 
-		// Commitments contains all other parties commitment polynomials
+		// Commitments contains the commitment polynomials of all parties, including ours,
+		// when a later round needs them: with an echo round, so that the digest of all commitments can be computed,
+		// with a blame round or a robust keygen, so that the complaints can be checked,
+		// and when they are exported in the Output. Otherwise, they are discarded once added to CommitmentsSum.
 		Commitments map[party.ID]*polynomial.Exponent
 
+		// ShareChecks contains, for each other party j, the point [fⱼ(i)]•B computed from its commitments,
+		// against which the share fⱼ(i) it sends us is checked.
+		ShareChecks map[party.ID]*ristretto.Element
+
+		// Encrypted indicates that the shares are encrypted to their recipient, as set by WithEncryptedShares.
+		Encrypted bool
+
+		// EncryptionSecret is our ephemeral secret key, which is only used if Encrypted is set.
+		EncryptionSecret ristretto.Scalar
+
+		// EncryptionKeys contains the ephemeral public keys of all parties, including ours.
+		EncryptionKeys map[party.ID]*ristretto.Element
+
+		// Echo indicates that the parties compare the commitments they received in an echo round, as set by WithEchoRound.
+		Echo bool
+
+		// EchoDigest is the digest of the commitments we received, which is sent in the echo round.
+		EchoDigest [messages.SizeEchoDigest]byte
+
+		// Packed indicates that the KeyGen2 messages are sent as a single Packed message, as set by WithPackedShares.
+		Packed bool
+
+		// Blame indicates that invalid shares are revealed in a blame round, as set by WithBlame.
+		Blame bool
+
+		// Complaints contains the invalid shares we received, indexed by sender, which are revealed in the blame round.
+		Complaints map[party.ID]*ristretto.Scalar
+
+		// Robust indicates that the parties which do not send a valid share are excluded instead of aborting,
+		// as set by WithRobust.
+		Robust bool
+
+		// Shares contains the valid shares received in the second round of a robust keygen, indexed by sender.
+		// They are only added to Secret once the qualified parties are known.
+		Shares map[party.ID]*ristretto.Scalar
+
+		// Excluded contains the parties excluded from a robust keygen, either because a party complained about them,
+		// or because their complaints were not received.
+		Excluded map[party.ID]bool
+
+		// Complainers contains the parties whose complaints were received in the complaint round of a robust keygen.
+		Complainers map[party.ID]bool
+
+		// Confirm indicates that the parties compare the public keys they computed in a final round,
+		// as set by WithConfirmation.
+		Confirm bool
+
+		// ConfirmDigest is the digest of the public keys we computed, which is sent in the confirmation round.
+		ConfirmDigest [messages.SizeConfirmDigest]byte
+
+		// ExportCommitments indicates that the Commitments are copied to the Output, as set by WithCommitments.
+		ExportCommitments bool
+
+		// Context is the context of the ceremony, as set by WithContext, to which the proofs of knowledge are bound.
+		// If the keygen has observers, NewRound appends them to it, so that they are also bound to the proofs.
+		Context []byte
+
+		// Observers are the parties which follow the keygen without receiving a share, as set by WithObservers.
+		Observers party.IDSlice
+
+		// Rand is the source of the secret values we sample, as set by WithRandom. crypto/rand is used if it is nil.
+		Rand io.Reader
+
 		Output *Output
 	}
 	round1 struct {
@@ -42,14 +109,32 @@ type (
 	}
 )
 
-func NewRound(selfID party.ID, partyIDs party.IDSlice, threshold party.Size) (state.Round, *Output, error) {
+// Parameters are the public parameters of a key generation, on which all parties must agree.
+type Parameters struct {
+	// PartyIDs are the parties receiving a share of the key.
+	PartyIDs party.IDSlice
+	// Threshold is the maximum number of corrupted parties tolerated by the key.
+	Threshold party.Size
+}
+
+// MinSigners returns Threshold+1, the number of parties required to sign with the generated key.
+func (p Parameters) MinSigners() party.Size {
+	return party.MinSigners(p.Threshold)
+}
+
+// Validate returns a *party.ThresholdError unless the threshold is between 1 and the number of parties minus 1.
+func (p Parameters) Validate() error {
+	return party.ValidateThreshold(p.Threshold, p.PartyIDs.N())
+}
+
+// NewRound returns the first round of the keygen protocol, and the Output which is filled once it has finished.
+// If the threshold is 0, or not smaller than the number of parties, the error is a *party.ThresholdError.
+// The options opts must be the same for all parties.
+func NewRound(selfID party.ID, partyIDs party.IDSlice, threshold party.Size, opts ...Option) (state.Round, *Output, error) {
 	N := partyIDs.N()
 
-	if threshold == 0 {
-		return nil, nil, errors.New("threshold must be at least 1, or a minimum of T+1=2 signers")
-	}
-	if threshold > N-1 {
-		return nil, nil, errors.New("threshold must be at most N-1, or a maximum of T+1=N signers")
+	if err := (Parameters{PartyIDs: partyIDs, Threshold: threshold}).Validate(); err != nil {
+		return nil, nil, err
 	}
 
 	baseRound, err := state.NewBaseRound(selfID, partyIDs)
@@ -61,19 +146,54 @@ func NewRound(selfID party.ID, partyIDs party.IDSlice, threshold party.Size) (st
 		BaseRound:   baseRound,
 		Threshold:   threshold,
 		Commitments: make(map[party.ID]*polynomial.Exponent, N),
+		ShareChecks: make(map[party.ID]*ristretto.Element, N),
 		Output:      &Output{},
 	}
+	for _, opt := range opts {
+		opt(&r)
+	}
+	if r.Encrypted {
+		r.EncryptionKeys = make(map[party.ID]*ristretto.Element, N)
+	}
+	if r.Robust && r.Blame {
+		return nil, nil, errors.New("keygen: WithRobust cannot be combined with WithBlame")
+	}
+	if r.Robust && !r.Confirm {
+		return nil, nil, errors.New("keygen: WithRobust requires WithConfirmation")
+	}
+	if err = r.bindObservers(); err != nil {
+		return nil, nil, err
+	}
 
 	return &r, r.Output, nil
 }
 
+// Reset is called by the State when the protocol finishes or is aborted.
+// It zeroes the secret Secret, which holds our share once the protocol finished, the ephemeral EncryptionSecret,
+// the coefficients of our Polynomial, the invalid shares in Complaints and the shares of a robust keygen,
+// and sets the commitments to the identity.
+// The SecretShare of the Output is a copy and is not wiped: the caller should call eddsa.SecretShare.Wipe
+// once it has been stored. The shares contained in the messages are zeroed by the State.
 func (round *round0) Reset() {
 	round.Secret.Set(ristretto.NewScalar())
-	round.Polynomial.Reset()
-	round.CommitmentsSum.Reset()
+	round.EncryptionSecret.Set(ristretto.NewScalar())
+	// The polynomial and commitments are only set once round 0 was processed,
+	// and the protocol may be aborted before, for example when the session expires.
+	if round.Polynomial != nil {
+		round.Polynomial.Reset()
+	}
+	if round.CommitmentsSum != nil {
+		round.CommitmentsSum.Reset()
+	}
 	for _, p := range round.Commitments {
 		p.Reset()
 	}
+	for _, share := range round.Complaints {
+		share.Set(ristretto.NewScalar())
+	}
+	for _, share := range round.Shares {
+		share.Set(ristretto.NewScalar())
+	}
 	round.Output = nil
 }
 
@@ -82,5 +202,41 @@ func (round *round0) Reset() {
 // ---
 
 func (round *round0) AcceptedMessageTypes() []messages.MessageType {
-	return []messages.MessageType{messages.MessageTypeNone, messages.MessageTypeKeyGen1, messages.MessageTypeKeyGen2}
+	types := []messages.MessageType{messages.MessageTypeNone, messages.MessageTypeKeyGen1}
+	if round.Echo {
+		types = append(types, messages.MessageTypeKeyGenEcho)
+	}
+	types = append(types, messages.MessageTypeKeyGen2)
+	if round.Blame {
+		types = append(types, messages.MessageTypeKeyGenBlame)
+	}
+	if round.Robust {
+		types = append(types, messages.MessageTypeKeyGenComplaint)
+	}
+	if round.Confirm {
+		types = append(types, messages.MessageTypeKeyGenConfirm)
+	}
+	return types
+}
+
+// retainCommitments returns true if the commitments of all parties are needed after the first round.
+func (round *round0) retainCommitments() bool {
+	return round.Echo || round.Blame || round.Robust || round.ExportCommitments
+}
+
+// ProcessIncrementally implements state.IncrementalRound, so that the commitments of a KeyGen1 message
+// are checked and added to CommitmentsSum as soon as it arrives. The State then does not hold the KeyGen1 messages
+// of all parties at the same time, and unless retainCommitments is true, the party only holds O(t) points
+// in addition to one per party.
+func (round *round0) ProcessIncrementally(msgType messages.MessageType) bool {
+	return msgType == messages.MessageTypeKeyGen1
+}
+
+// MessageLimits implements state.Limiter, since the commitments in KeyGen1 messages depend on the threshold.
+func (round *round0) MessageLimits() messages.Limits {
+	return messages.Limits{
+		Threshold:       round.Threshold,
+		EncryptedShares: round.Encrypted,
+		Parties:         round.PartyIDs().N(),
+	}
 }
@@ -0,0 +1,137 @@
+package keygen
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+func TestReset_Finished(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	zero := ristretto.NewScalar()
+
+	for _, opts := range [][]Option{nil, {WithEncryptedShares()}, {WithBlame()}, {WithConfirmation()}, {WithBlame(), WithConfirmation()}, {WithRobust(), WithConfirmation()}} {
+		states := make(map[party.ID]*state.State, partyIDs.N())
+		rounds := make(map[party.ID]*round0, partyIDs.N())
+		outputs := make(map[party.ID]*Output, partyIDs.N())
+		for _, id := range partyIDs {
+			r, output, err := NewRound(id, partyIDs, 1, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rounds[id], outputs[id] = r.(*round0), output
+			if states[id], err = state.NewBaseState(r, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// The last iterations only run the blame and confirmation rounds, and are no-ops for the finished states
+		var delivered []*messages.Message
+		for i := 0; i < 5; i++ {
+			var out []*messages.Message
+			for _, id := range partyIDs {
+				out = append(out, states[id].ProcessAll()...)
+			}
+			for _, msg := range out {
+				data, err := msg.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, id := range partyIDs {
+					if msg.To != 0 && msg.To != id {
+						continue
+					}
+					var msgCopy messages.Message
+					if err = msgCopy.UnmarshalBinary(data); err != nil {
+						t.Fatal(err)
+					}
+					if err = states[id].HandleMessage(&msgCopy); err != nil {
+						t.Fatal(err)
+					}
+					delivered = append(delivered, &msgCopy)
+				}
+			}
+		}
+
+		for _, id := range partyIDs {
+			if err := states[id].WaitForError(); err != nil {
+				t.Fatal(err)
+			}
+			r := rounds[id]
+			if r.Secret.Equal(zero) != 1 || r.EncryptionSecret.Equal(zero) != 1 {
+				t.Errorf("party %d: the secrets of the round were not zeroed", id)
+			}
+			if r.Polynomial.Evaluate(party.ID(42).Scalar()).Equal(zero) != 1 {
+				t.Errorf("party %d: the polynomial was not zeroed", id)
+			}
+
+			secret := outputs[id].SecretKey
+			if secret.Secret.Equal(zero) == 1 {
+				t.Errorf("party %d: the output SecretShare was wiped", id)
+			}
+			var public ristretto.Element
+			public.ScalarBaseMult(&secret.Secret)
+			if public.Equal(outputs[id].Public.Shares[id]) != 1 {
+				t.Errorf("party %d: the output SecretShare does not match its public share", id)
+			}
+
+			for roundNumber := 0; roundNumber < 5; roundNumber++ {
+				if _, err := states[id].ResendMessages(roundNumber); !errors.Is(err, state.ErrNoMessages) {
+					t.Errorf("party %d: the messages of round %d can still be resent", id, roundNumber)
+				}
+			}
+		}
+
+		for _, msg := range delivered {
+			if msg.KeyGen2 == nil {
+				continue
+			}
+			if msg.KeyGen2.Share.Equal(zero) != 1 {
+				t.Errorf("the share sent by %d to %d was not zeroed", msg.From, msg.To)
+			}
+			for _, b := range msg.KeyGen2.SealedShare {
+				if b != 0 {
+					t.Errorf("the sealed share sent by %d to %d was not zeroed", msg.From, msg.To)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestNewRound_Threshold(t *testing.T) {
+	for _, tc := range []struct {
+		partyIDs  party.IDSlice
+		threshold party.Size
+		valid     bool
+	}{
+		{party.IDSlice{1, 2}, 0, false},
+		{party.IDSlice{1, 2}, 1, true},
+		{party.IDSlice{1, 2}, 2, false},
+		{party.IDSlice{1, 2, 3, 4, 5}, 0, false},
+		{party.IDSlice{1, 2, 3, 4, 5}, 1, true},
+		{party.IDSlice{1, 2, 3, 4, 5}, 4, true},
+		{party.IDSlice{1, 2, 3, 4, 5}, 5, false},
+		{party.IDSlice{1, 2, 3, 4, 5}, 6, false},
+	} {
+		params := Parameters{PartyIDs: tc.partyIDs, Threshold: tc.threshold}
+		if params.MinSigners() != tc.threshold+1 {
+			t.Errorf("MinSigners() = %d for threshold %d", params.MinSigners(), tc.threshold)
+		}
+		_, _, err := NewRound(1, tc.partyIDs, tc.threshold)
+		if tc.valid {
+			if err != nil || params.Validate() != nil {
+				t.Errorf("t=%d, n=%d: %v", tc.threshold, tc.partyIDs.N(), err)
+			}
+			continue
+		}
+		var thresholdErr *party.ThresholdError
+		if !errors.As(err, &thresholdErr) || !errors.Is(params.Validate(), party.ErrInvalidThreshold) {
+			t.Errorf("t=%d, n=%d: error = %v, want a *party.ThresholdError", tc.threshold, tc.partyIDs.N(), err)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package keygen
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/hashing"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/zk"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// A batch keygen generates k independent keys between the same parties in a single execution,
+// so that it takes the same number of round trips as a single keygen, and only the computation grows with k.
+// Each party samples k polynomials, broadcasts the commitments and proofs of knowledge of all of them
+// in a single KeyGenBatch1 message, and sends the k shares of each other party in a single KeyGenBatch2 message.
+// The keys are independent: each one is the result of a keygen with the same parties and threshold,
+// and its proofs of knowledge are bound to its index in the batch, so that the entries cannot be reordered.
+// The options which change the messages of the keygen are not supported.
+
+// batchContextDomainSeparation is appended to the context of a batch keygen, before the size of the batch
+// and the index of the key, to obtain the context of the proofs of knowledge of each key.
+const batchContextDomainSeparation = "FROST-Ed25519 batch keygen"
+
+type (
+	batchRound0 struct {
+		*state.BaseRound
+
+		// Threshold is the degree of the polynomials used for Shamir.
+		Threshold party.Size
+
+		// Batch is the number of keys generated.
+		Batch int
+
+		// Secrets contains, for each key, first the zero coefficient of our polynomial,
+		// and then the sum of the shares we received, which is our final secret share.
+		Secrets []ristretto.Scalar
+
+		// Polynomials contains the polynomial we use to sample the shares of each key.
+		Polynomials []*polynomial.Polynomial
+
+		// CommitmentsSums contains, for each key, the sum of the commitments of all parties.
+		CommitmentsSums []*polynomial.Exponent
+
+		// ShareChecks contains, for each other party j, the points [fⱼₖ(i)]•B against which the shares
+		// it sends us are checked, where fⱼₖ is its polynomial for the key k.
+		ShareChecks map[party.ID][]ristretto.Element
+
+		// Context is the context of the ceremony, as set by WithContext.
+		Context []byte
+
+		// Rand is the source of the secret values we sample, as set by WithRandom. crypto/rand is used if it is nil.
+		Rand io.Reader
+
+		Output *BatchOutput
+	}
+	batchRound1 struct {
+		*batchRound0
+	}
+	batchRound2 struct {
+		*batchRound1
+	}
+)
+
+// BatchError is the error wrapped by the state.Error of a batch keygen when a party sent an invalid proof or share
+// for one of the keys. It can be retrieved with errors.As.
+type BatchError struct {
+	// Index is the index of the key in the batch.
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("key %d: %s", e.Index, e.Err.Error())
+}
+
+// Unwrap returns the underlying error, such as ErrValidateProof or ErrValidateShare.
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// Result is the output of a batch keygen for a single key.
+type Result struct {
+	Public    *eddsa.Public
+	SecretKey *eddsa.SecretShare
+}
+
+// KeyShare returns a copy of the result as an eddsa.KeyShare.
+func (r *Result) KeyShare() (*eddsa.KeyShare, error) {
+	return eddsa.NewKeyShare(r.SecretKey.Copy(), r.Public.Copy())
+}
+
+// BatchOutput is filled once a batch keygen has finished.
+type BatchOutput struct {
+	// Results contains the output of each key, in the order of the batch.
+	Results []*Result
+}
+
+// NewBatchRound returns the first round of a keygen generating k independent keys, and the BatchOutput which is filled
+// once it has finished. The parameters are the same as for NewRound, and all parties must use the same k.
+// Only WithContext and WithRandom are supported.
+func NewBatchRound(selfID party.ID, partyIDs party.IDSlice, threshold party.Size, k int, opts ...Option) (state.Round, *BatchOutput, error) {
+	if err := (Parameters{PartyIDs: partyIDs, Threshold: threshold}).Validate(); err != nil {
+		return nil, nil, err
+	}
+	if k < 1 || uint64(k) > uint64(party.MaxID) {
+		return nil, nil, fmt.Errorf("keygen: invalid number of keys %d", k)
+	}
+
+	// The options are applied to a single keygen, from which the supported ones are read
+	var options round0
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.retainCommitments() || options.Encrypted || options.Packed || options.Confirm || len(options.Observers) > 0 {
+		return nil, nil, errors.New("keygen: only WithContext and WithRandom are supported by a batch keygen")
+	}
+
+	baseRound, err := state.NewBaseRound(selfID, partyIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	r := batchRound0{
+		BaseRound:       baseRound,
+		Threshold:       threshold,
+		Batch:           k,
+		Secrets:         make([]ristretto.Scalar, k),
+		Polynomials:     make([]*polynomial.Polynomial, 0, k),
+		CommitmentsSums: make([]*polynomial.Exponent, 0, k),
+		ShareChecks:     make(map[party.ID][]ristretto.Element, partyIDs.N()),
+		Context:         options.Context,
+		Rand:            options.Rand,
+		Output:          &BatchOutput{},
+	}
+	return &r, r.Output, nil
+}
+
+// Reset is called by the State when the protocol finishes or is aborted.
+// It zeroes the Secrets, the coefficients of the Polynomials, and sets the commitments to the identity.
+// The SecretShares of the Results are copies and are not wiped.
+func (round *batchRound0) Reset() {
+	for i := range round.Secrets {
+		round.Secrets[i].Set(ristretto.NewScalar())
+	}
+	for _, p := range round.Polynomials {
+		p.Reset()
+	}
+	for _, p := range round.CommitmentsSums {
+		p.Reset()
+	}
+	round.Output = nil
+}
+
+// proofContext returns the context of the proofs of knowledge of all parties for the key at index in the batch.
+func (round *batchRound0) proofContext(index int) []byte {
+	context := make([]byte, 0, len(round.Context)+len(batchContextDomainSeparation)+2*party.IDByteSize)
+	context = append(context, round.Context...)
+	context = append(context, batchContextDomainSeparation...)
+	context = append(context, party.Size(round.Batch).Bytes()...)
+	context = append(context, party.Size(index).Bytes()...)
+	ctx := hashing.KeygenContext(round.SessionID(), round.PartyIDs(), round.Threshold, context)
+	return ctx[:]
+}
+
+func (round *batchRound0) AcceptedMessageTypes() []messages.MessageType {
+	return []messages.MessageType{messages.MessageTypeNone, messages.MessageTypeKeyGenBatch1, messages.MessageTypeKeyGenBatch2}
+}
+
+// ProcessIncrementally implements state.IncrementalRound, so that the commitments of a KeyGenBatch1 message
+// are checked and added as soon as it arrives.
+func (round *batchRound0) ProcessIncrementally(msgType messages.MessageType) bool {
+	return msgType == messages.MessageTypeKeyGenBatch1
+}
+
+// MessageLimits implements state.Limiter, since the messages contain an entry for each key.
+func (round *batchRound0) MessageLimits() messages.Limits {
+	return messages.Limits{
+		Threshold: round.Threshold,
+		Parties:   round.PartyIDs().N(),
+		Batch:     round.Batch,
+	}
+}
+
+func (round *batchRound0) ProcessMessage(*messages.Message) *state.Error {
+	return nil
+}
+
+func (round *batchRound0) GenerateMessages() ([]*messages.Message, *state.Error) {
+	keys := make([]messages.KeyGen1, round.Batch)
+	self := round.SelfID().Scalar()
+	for i := range keys {
+		secret := &round.Secrets[i]
+		if _, err := scalar.SetScalarRandomFrom(secret, round.Rand); err != nil {
+			return nil, state.NewError(0, fmt.Errorf("keygen: failed to sample secret: %w", err))
+		}
+		p, err := polynomial.NewPolynomialFrom(round.Threshold, secret, round.Rand)
+		if err != nil {
+			return nil, state.NewError(0, fmt.Errorf("keygen: failed to sample polynomial: %w", err))
+		}
+		round.Polynomials = append(round.Polynomials, p)
+		commitments := polynomial.NewPolynomialExponent(p)
+		round.CommitmentsSums = append(round.CommitmentsSums, commitments)
+
+		proof, err := zk.NewSchnorrProofFrom(round.SelfID(), commitments.Constant(), round.proofContext(i), secret, round.Rand)
+		if err != nil {
+			return nil, state.NewError(0, fmt.Errorf("keygen: %w", err))
+		}
+		keys[i] = messages.KeyGen1{Proof: proof, Commitments: commitments.Copy()}
+
+		// As in a single keygen, the secret is replaced by the share we would send to ourselves
+		p.EvaluateTo(secret, self)
+	}
+	return []*messages.Message{messages.NewKeyGenBatch1(round.SelfID(), keys)}, nil
+}
+
+func (round *batchRound0) NextRound() state.Round {
+	return &batchRound1{round}
+}
+
+func (round *batchRound1) ProcessMessage(msg *messages.Message) *state.Error {
+	from := msg.From
+	keys := msg.KeyGenBatch1.Keys
+	// The number of keys and the degree of their commitments are checked by the State,
+	// using the limits given by MessageLimits
+	for i := range keys {
+		if !keys[i].Proof.Verify(from, keys[i].Commitments.Constant(), round.proofContext(i)) {
+			return state.NewErrorWithKind(from, state.KindInvalidProof, &BatchError{Index: i, Err: ErrValidateProof})
+		}
+	}
+
+	self := round.SelfID().Scalar()
+	checks := make([]ristretto.Element, len(keys))
+	for i := range keys {
+		checks[i].Set(keys[i].Commitments.Evaluate(self))
+		_ = round.CommitmentsSums[i].Add(keys[i].Commitments)
+	}
+	round.ShareChecks[from] = checks
+	return nil
+}
+
+func (round *batchRound1) GenerateMessages() ([]*messages.Message, *state.Error) {
+	msgsOut := make([]*messages.Message, 0, len(round.PartyIDs())-1)
+	var x ristretto.Scalar
+	for _, id := range round.PartyIDs() {
+		if id == round.SelfID() {
+			continue
+		}
+		id.ScalarTo(&x)
+		shares := make([]ristretto.Scalar, round.Batch)
+		for i, p := range round.Polynomials {
+			p.EvaluateTo(&shares[i], &x)
+		}
+		msgsOut = append(msgsOut, messages.NewKeyGenBatch2(round.SelfID(), id, shares))
+	}
+
+	// The polynomials are no longer needed once the shares were computed
+	for _, p := range round.Polynomials {
+		p.Reset()
+	}
+	return msgsOut, nil
+}
+
+func (round *batchRound1) NextRound() state.Round {
+	return &batchRound2{round}
+}
+
+func (round *batchRound1) MessageType() messages.MessageType {
+	return messages.MessageTypeKeyGenBatch1
+}
+
+func (round *batchRound2) ProcessMessage(msg *messages.Message) *state.Error {
+	from := msg.From
+	shares := msg.KeyGenBatch2.Shares
+	checks := round.ShareChecks[from]
+
+	var computedShareExp ristretto.Element
+	for i := range shares {
+		if computedShareExp.ScalarBaseMult(&shares[i]).Equal(&checks[i]) != 1 {
+			return state.NewErrorWithKind(from, state.KindVSSFailure, &BatchError{Index: i, Err: ErrValidateShare})
+		}
+	}
+	for i := range shares {
+		round.Secrets[i].Add(&round.Secrets[i], &shares[i])
+	}
+	return nil
+}
+
+func (round *batchRound2) GenerateMessages() ([]*messages.Message, *state.Error) {
+	results := make([]*Result, round.Batch)
+	for i, commitments := range round.CommitmentsSums {
+		public := computePublic(round.PartyIDs(), round.Threshold, commitments)
+		secret := eddsa.NewSecretShare(round.SelfID(), &round.Secrets[i])
+		secret.SetGroup(public.GroupKey)
+		results[i] = &Result{Public: public, SecretKey: secret}
+	}
+	round.Output.Results = results
+	return nil, nil
+}
+
+func (round *batchRound2) NextRound() state.Round {
+	return nil
+}
+
+func (round *batchRound2) MessageType() messages.MessageType {
+	return messages.MessageTypeKeyGenBatch2
+}
@@ -0,0 +1,186 @@
+package keygen
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// runBatch executes a batch keygen of k keys with States, calls tamper on each message before it is delivered,
+// and returns the States, the outputs, and the number of rounds of communication.
+func runBatch(tb testing.TB, partyIDs party.IDSlice, threshold party.Size, k int, tamper func(*messages.Message)) (map[party.ID]*state.State, map[party.ID]*BatchOutput, int) {
+	states := make(map[party.ID]*state.State, partyIDs.N())
+	outputs := make(map[party.ID]*BatchOutput, partyIDs.N())
+	for _, id := range partyIDs {
+		r, output, err := NewBatchRound(id, partyIDs, threshold, k)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		outputs[id] = output
+		if states[id], err = state.NewBaseState(r, 0); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return states, outputs, runAll(tb, partyIDs, states, tamper)
+}
+
+// runAll delivers the messages of the states to each other until none is sent, and returns the number of rounds.
+func runAll(tb testing.TB, partyIDs party.IDSlice, states map[party.ID]*state.State, tamper func(*messages.Message)) int {
+	for rounds := 0; ; rounds++ {
+		var out []*messages.Message
+		for _, id := range partyIDs {
+			out = append(out, states[id].ProcessAll()...)
+		}
+		if len(out) == 0 {
+			return rounds
+		}
+		for _, msg := range out {
+			data, err := msg.MarshalBinary()
+			if err != nil {
+				tb.Fatal(err)
+			}
+			for _, id := range partyIDs {
+				if msg.From == id || msg.To != 0 && msg.To != id {
+					continue
+				}
+				var msgCopy messages.Message
+				if err = msgCopy.UnmarshalBinary(data); err != nil {
+					tb.Fatal(err)
+				}
+				if tamper != nil {
+					tamper(&msgCopy)
+				}
+				_ = states[id].HandleMessage(&msgCopy)
+			}
+		}
+	}
+}
+
+func TestBatchKeygen(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 4, 8}
+	const k = 5
+	states, outputs, _ := runBatch(t, partyIDs, 2, k, nil)
+
+	groupKeys := make(map[string]bool, k)
+	for _, id := range partyIDs {
+		if err := states[id].WaitForError(); err != nil {
+			t.Fatalf("party %d: %v", id, err)
+		}
+		results := outputs[id].Results
+		if len(results) != k {
+			t.Fatalf("party %d: %d results, want %d", id, len(results), k)
+		}
+		for i, result := range results {
+			if err := result.SecretKey.Validate(result.Public); err != nil {
+				t.Errorf("party %d, key %d: %v", id, i, err)
+			}
+			if !result.Public.Equal(outputs[partyIDs[0]].Results[i].Public) {
+				t.Errorf("party %d, key %d: the public keys differ", id, i)
+			}
+			if _, err := result.KeyShare(); err != nil {
+				t.Error(err)
+			}
+			groupKeys[string(result.Public.GroupKey.ToEd25519())] = true
+		}
+	}
+	if len(groupKeys) != k {
+		t.Errorf("%d distinct group keys, want %d", len(groupKeys), k)
+	}
+}
+
+func TestBatchKeygen_Culprit(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+
+	// Party 2 sends an invalid share of the key 3 to party 1
+	states, _, _ := runBatch(t, partyIDs, 1, 4, func(msg *messages.Message) {
+		if msg.Type == messages.MessageTypeKeyGenBatch2 && msg.From == 2 && msg.To == 1 {
+			msg.KeyGenBatch2.Shares[3].Add(&msg.KeyGenBatch2.Shares[3], scalar.NewScalarUInt32(1))
+		}
+	})
+	var stateErr *state.Error
+	var batchErr *BatchError
+	err := states[1].WaitForError()
+	if !errors.As(err, &stateErr) || !errors.As(err, &batchErr) || !errors.Is(err, ErrValidateShare) {
+		t.Fatalf("error = %v, want a BatchError", err)
+	}
+	if stateErr.Culprit() != 2 || stateErr.Kind() != state.KindVSSFailure || batchErr.Index != 3 {
+		t.Errorf("culprit = %d, kind = %v, index = %d", stateErr.Culprit(), stateErr.Kind(), batchErr.Index)
+	}
+
+	// Party 3 swaps the first two keys, whose proofs are bound to their index
+	states, _, _ = runBatch(t, partyIDs, 1, 2, func(msg *messages.Message) {
+		if msg.Type == messages.MessageTypeKeyGenBatch1 && msg.From == 3 {
+			keys := msg.KeyGenBatch1.Keys
+			keys[0], keys[1] = keys[1], keys[0]
+		}
+	})
+	for _, id := range []party.ID{1, 2} {
+		err = states[id].WaitForError()
+		if !errors.As(err, &stateErr) || !errors.As(err, &batchErr) || !errors.Is(err, ErrValidateProof) {
+			t.Fatalf("party %d: error = %v, want a BatchError", id, err)
+		}
+		if stateErr.Culprit() != 3 || stateErr.Kind() != state.KindInvalidProof || batchErr.Index != 0 {
+			t.Errorf("party %d: culprit = %d, kind = %v, index = %d", id, stateErr.Culprit(), stateErr.Kind(), batchErr.Index)
+		}
+	}
+}
+
+func TestNewBatchRound_Invalid(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	for name, tc := range map[string]struct {
+		threshold party.Size
+		k         int
+		opts      []Option
+	}{
+		"threshold":  {3, 1, nil},
+		"no key":     {1, 0, nil},
+		"encrypted":  {1, 2, []Option{WithEncryptedShares()}},
+		"echo":       {1, 2, []Option{WithEchoRound()}},
+		"robust":     {1, 2, []Option{WithRobust()}},
+		"confirmed":  {1, 2, []Option{WithConfirmation()}},
+		"packed":     {1, 2, []Option{WithPackedShares()}},
+		"exportable": {1, 2, []Option{WithCommitments()}},
+	} {
+		if _, _, err := NewBatchRound(1, partyIDs, tc.threshold, tc.k, tc.opts...); err == nil {
+			t.Errorf("%s: NewBatchRound() should fail", name)
+		}
+	}
+	if _, _, err := NewBatchRound(1, partyIDs, 1, 2, WithContext([]byte("ceremony")), WithRandom(nil)); err != nil {
+		t.Error(err)
+	}
+}
+
+// The batch keygen of 100 keys takes as many rounds of communication as a single keygen,
+// and its computation is about 100 times that of a single one.
+
+func BenchmarkKeygen(b *testing.B) {
+	partyIDs := party.IDSlice{1, 2, 3, 4, 5}
+	var rounds int
+	for i := 0; i < b.N; i++ {
+		states := make(map[party.ID]*state.State, partyIDs.N())
+		for _, id := range partyIDs {
+			r, _, err := NewRound(id, partyIDs, 2)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if states[id], err = state.NewBaseState(r, 0); err != nil {
+				b.Fatal(err)
+			}
+		}
+		rounds = runAll(b, partyIDs, states, nil)
+	}
+	b.ReportMetric(float64(rounds), "rounds")
+}
+
+func BenchmarkBatchKeygen100(b *testing.B) {
+	partyIDs := party.IDSlice{1, 2, 3, 4, 5}
+	var rounds int
+	for i := 0; i < b.N; i++ {
+		_, _, rounds = runBatch(b, partyIDs, 2, 100, nil)
+	}
+	b.ReportMetric(float64(rounds), "rounds")
+}
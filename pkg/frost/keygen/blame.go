@@ -0,0 +1,140 @@
+package keygen
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// Without a blame round, a party which receives an invalid share aborts with an error naming the sender,
+// but the other parties cannot tell whether the sender cheated, or whether the victim is lying.
+// When the keygen is run with a blame round, the parties do not abort when a share is invalid.
+// Instead, each party broadcasts a KeyGenBlame message containing the invalid shares it received,
+// and every party checks them against the commitments of the accused party:
+//
+//   - if the share does not match the commitments, the accused party is blamed,
+//     and the error wraps a *BlameError;
+//   - if the share matches, the accusation is false and the accuser is the culprit.
+//
+// Revealing an invalid share is safe, since it is not used in the final key.
+// The verdict against the accused party relies on the word of the accuser, since the revealed share is not signed by
+// the accused party: a malicious accuser can reveal any invalid share in order to blame an honest party.
+// Authenticated channels do not prevent this, since they only convince the recipient of a message,
+// and the accused party cannot prove which share it actually sent.
+// A blamed party is therefore either the culprit, or the victim of its accuser.
+
+// ErrFalseAccusation is returned when a party accuses another one with a share that is valid,
+// or a party which does not participate in the protocol.
+var ErrFalseAccusation = errors.New("share revealed in a complaint is valid")
+
+// WithBlame returns an Option which adds a blame round after the second round, in which invalid shares are revealed,
+// so that all parties agree on the party which caused the abort.
+// Since it changes the messages that are exchanged, all parties must use this option, or none.
+func WithBlame() Option {
+	return func(round *round0) {
+		round.Blame = true
+		round.Complaints = make(map[party.ID]*ristretto.Scalar)
+	}
+}
+
+// Blame is the claim of the Accuser that the Accused party sent it an invalid share.
+// It is not a proof, since the Share is revealed by the Accuser alone.
+type Blame struct {
+	// Accuser is the party which received the share.
+	Accuser party.ID
+	// Accused is the party which sent the share.
+	Accused party.ID
+	// Share is the share revealed by the Accuser.
+	Share ristretto.Scalar
+}
+
+// Verify returns true if the Share does not match the commitments of the Accused at the Accuser's index.
+// commitments must be the commitments sent by the Accused in its KeyGen1 message.
+// It only shows that the accusation is consistent, and not that the Accused sent the Share.
+func (b *Blame) Verify(commitments *polynomial.Exponent) bool {
+	if commitments == nil {
+		return false
+	}
+	var computed ristretto.Element
+	computed.ScalarBaseMult(&b.Share)
+	return computed.Equal(commitments.Evaluate(b.Accuser.Scalar())) != 1
+}
+
+// BlameError is wrapped by the error returned when a party was accused of sending an invalid share in a keygen
+// with a blame round, and the accusation was consistent with its commitments. It unwraps to ErrValidateShare.
+type BlameError struct {
+	Blame *Blame
+}
+
+// Error implements error
+func (e *BlameError) Error() string {
+	return fmt.Sprintf("keygen: party %d revealed an invalid share from party %d: %s", e.Blame.Accuser, e.Blame.Accused, ErrValidateShare.Error())
+}
+
+// Unwrap returns ErrValidateShare.
+func (e *BlameError) Unwrap() error {
+	return ErrValidateShare
+}
+
+type roundBlame struct {
+	*round2
+}
+
+// complaints returns our complaints, sorted by accused party.
+func (round *round0) complaints() []messages.Complaint {
+	complaints := make([]messages.Complaint, 0, len(round.Complaints))
+	for _, id := range round.PartyIDs() {
+		if share, ok := round.Complaints[id]; ok {
+			c := messages.Complaint{Accused: id}
+			c.Share.Set(share)
+			complaints = append(complaints, c)
+		}
+	}
+	return complaints
+}
+
+func (round *roundBlame) ProcessMessage(msg *messages.Message) *state.Error {
+	from := msg.From
+	complaints := msg.KeyGenBlame.Complaints
+
+	// A single false complaint makes the accuser the culprit, even if its other complaints are justified
+	for i := range complaints {
+		accused := complaints[i].Accused
+		if accused == from || !round.PartyIDs().Contains(accused) {
+			return state.NewErrorWithKind(from, state.KindFalseAccusation, fmt.Errorf("complaint against party %d: %w", accused, ErrFalseAccusation))
+		}
+		blame := Blame{Accuser: from, Accused: accused, Share: complaints[i].Share}
+		if !blame.Verify(round.Commitments[accused]) {
+			return state.NewErrorWithKind(from, state.KindFalseAccusation, fmt.Errorf("complaint against party %d: %w", accused, ErrFalseAccusation))
+		}
+	}
+	if len(complaints) > 0 {
+		blame := &Blame{Accuser: from, Accused: complaints[0].Accused, Share: complaints[0].Share}
+		return state.NewErrorWithKind(blame.Accused, state.KindVSSFailure, &BlameError{Blame: blame})
+	}
+	return nil
+}
+
+func (round *roundBlame) GenerateMessages() ([]*messages.Message, *state.Error) {
+	if complaints := round.complaints(); len(complaints) > 0 {
+		blame := &Blame{Accuser: round.SelfID(), Accused: complaints[0].Accused, Share: complaints[0].Share}
+		return nil, state.NewErrorWithKind(blame.Accused, state.KindVSSFailure, &BlameError{Blame: blame})
+	}
+	return round.finish()
+}
+
+func (round *roundBlame) NextRound() state.Round {
+	if round.Confirm {
+		return &roundConfirm{round.round2}
+	}
+	return nil
+}
+
+func (round *roundBlame) MessageType() messages.MessageType {
+	return messages.MessageTypeKeyGenBlame
+}
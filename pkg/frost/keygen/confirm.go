@@ -0,0 +1,102 @@
+package keygen
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// Each party computes the public shares and the group key on its own, from the commitments it received.
+// If they differ, for example because a party equivocated in a way the other rounds did not detect,
+// the mistake is normally only noticed when a signature fails to verify.
+// When the keygen is run with a confirmation round, each party broadcasts the digest
+//
+//	SHA-256("FROST-Ed25519 keygen confirmation" ∥ session ID ∥ public)
+//
+// where public is the binary encoding of the eddsa.Public it computed,
+// and the Output is only set once all digests were found to be equal to ours.
+// Otherwise, the error names all parties whose digest differs.
+
+// ErrConfirmMismatch is returned when a party computed a different group key or public shares than we did.
+var ErrConfirmMismatch = errors.New("computed public keys differ")
+
+const confirmContext = "FROST-Ed25519 keygen confirmation"
+
+// WithConfirmation returns an Option which adds a final round, in which the parties check
+// that they computed the same group key and public shares before the Output is set.
+// Since it changes the messages that are exchanged, all parties must use this option, or none.
+func WithConfirmation() Option {
+	return func(round *round0) {
+		round.Confirm = true
+	}
+}
+
+type roundConfirm struct {
+	*round2
+}
+
+// confirmDigest returns the digest of the public shares and group key we computed.
+func (round *round2) confirmDigest() [messages.SizeConfirmDigest]byte {
+	return computeConfirmDigest(round.SessionID(), round.public())
+}
+
+// computeConfirmDigest returns the digest of public.
+func computeConfirmDigest(sessionID messages.SessionID, public *eddsa.Public) [messages.SizeConfirmDigest]byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte(confirmContext))
+	_, _ = h.Write(sessionID[:])
+	// The public shares are computed from valid commitments, and can be marshalled
+	data, _ := public.MarshalBinary()
+	_, _ = h.Write(data)
+	var digest [messages.SizeConfirmDigest]byte
+	copy(digest[:], h.Sum(nil))
+	return digest
+}
+
+// finish sets the Output, or broadcasts our digest if the keygen has a confirmation round,
+// in which case the Output is set by roundConfirm.
+func (round *round2) finish() ([]*messages.Message, *state.Error) {
+	if round.Confirm {
+		round.ConfirmDigest = round.confirmDigest()
+		return []*messages.Message{messages.NewKeyGenConfirm(round.SelfID(), &round.ConfirmDigest)}, nil
+	}
+	round.setOutput()
+	return nil, nil
+}
+
+// AcceptMissing implements state.PartialRound, since the parties excluded from a robust keygen do not confirm.
+func (round *roundConfirm) AcceptMissing(missing party.IDSlice) bool {
+	for _, id := range missing {
+		if !round.Excluded[id] {
+			return false
+		}
+	}
+	return round.Robust
+}
+
+func (round *roundConfirm) ProcessMessage(msg *messages.Message) *state.Error {
+	if round.Excluded[msg.From] {
+		return nil
+	}
+	if msg.KeyGenConfirm.Digest != round.ConfirmDigest {
+		return state.NewErrorWithKind(msg.From, state.KindInconsistentBroadcast, ErrConfirmMismatch)
+	}
+	return nil
+}
+
+func (round *roundConfirm) GenerateMessages() ([]*messages.Message, *state.Error) {
+	round.setOutput()
+	return nil, nil
+}
+
+func (round *roundConfirm) NextRound() state.Round {
+	return nil
+}
+
+func (round *roundConfirm) MessageType() messages.MessageType {
+	return messages.MessageTypeKeyGenConfirm
+}
@@ -0,0 +1,28 @@
+package keygen
+
+import (
+	"github.com/taurusgroup/frost-ed25519/pkg/hashing"
+)
+
+// The proof of knowledge sent in KeyGen1 messages is computed with the context
+//
+//	hashing.KeygenContext(session ID, partyIDs, threshold, context)
+//
+// so that a KeyGen1 message recorded in another ceremony is rejected, even if its header is rewritten
+// with the session ID of this one. The sender of such a message is reported with the kind state.KindInvalidProof.
+
+// WithContext returns an Option which binds the keygen to context, an application-defined description of the ceremony,
+// such as its purpose and date. The proofs of knowledge of parties which use another context fail to verify.
+// Unlike the session ID of the State, the context does not need to be unique, but all parties must use the same one.
+func WithContext(context []byte) Option {
+	context = append([]byte(nil), context...)
+	return func(round *round0) {
+		round.Context = context
+	}
+}
+
+// proofContext returns the context of the proofs of knowledge of all parties.
+func (round *round0) proofContext() []byte {
+	ctx := hashing.KeygenContext(round.SessionID(), round.PartyIDs(), round.Threshold, round.Context)
+	return ctx[:]
+}
@@ -0,0 +1,99 @@
+// Package counterfile implements a keygen.ExportCounter backed by a file, so that ImportState refuses the states
+// exported before the last one across restarts of the process.
+//
+// The file contains the value of the counter in 8 bytes big endian, and is replaced atomically by every increment.
+// It is created by the first increment, and a missing file is a counter of 0.
+package counterfile
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrCorrupted is returned when the file does not contain a counter.
+var ErrCorrupted = errors.New("counterfile: corrupted file")
+
+// Counter is a keygen.ExportCounter which records its value in a file.
+// It is safe for concurrent use.
+type Counter struct {
+	mtx  sync.Mutex
+	path string
+}
+
+// Open returns a Counter stored at path, after checking that the file, if it exists, contains a counter.
+func Open(path string) (*Counter, error) {
+	c := &Counter{path: path}
+	if _, err := c.read(); err != nil {
+		return nil, fmt.Errorf("counterfile.Open: %w", err)
+	}
+	return c, nil
+}
+
+// Current implements keygen.ExportCounter.
+// The file is read again by every call, so that a counter which was rolled back is detected by keygen.ImportState.
+func (c *Counter) Current() (uint64, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	value, err := c.read()
+	if err != nil {
+		return 0, fmt.Errorf("counterfile.Current: %w", err)
+	}
+	return value, nil
+}
+
+// Increment implements keygen.ExportCounter.
+// The new value is written to a temporary file which is synced, and renamed over the file.
+func (c *Counter) Increment() (uint64, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	value, err := c.read()
+	if err != nil {
+		return 0, fmt.Errorf("counterfile.Increment: %w", err)
+	}
+	value++
+
+	var data [8]byte
+	binary.BigEndian.PutUint64(data[:], value)
+	tmp := c.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, fmt.Errorf("counterfile.Increment: %w", err)
+	}
+	if _, err = file.Write(data[:]); err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, c.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return 0, fmt.Errorf("counterfile.Increment: %w", err)
+	}
+	if dir, err := os.Open(filepath.Dir(c.path)); err == nil {
+		_ = dir.Sync()
+		_ = dir.Close()
+	}
+	return value, nil
+}
+
+// read returns the value stored in the file, and should be called with the lock held.
+func (c *Counter) read() (uint64, error) {
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 8 {
+		return 0, ErrCorrupted
+	}
+	return binary.BigEndian.Uint64(data), nil
+}
@@ -0,0 +1,90 @@
+package counterfile_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/keygen"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/keygen/counterfile"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+var _ keygen.ExportCounter = (*counterfile.Counter)(nil)
+
+func TestCounter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter")
+	c, err := counterfile.Open(path)
+	require.NoError(t, err)
+	value, err := c.Current()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), value)
+	_, err = os.Stat(path)
+	assert.True(t, errors.Is(err, os.ErrNotExist), "the file is only created by the first increment")
+
+	for i := uint64(1); i <= 3; i++ {
+		value, err = c.Increment()
+		require.NoError(t, err)
+		assert.Equal(t, i, value)
+	}
+
+	// The value is read again from the file
+	c, err = counterfile.Open(path)
+	require.NoError(t, err)
+	value, err = c.Current()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), value)
+	_, err = os.Stat(path + ".tmp")
+	assert.True(t, errors.Is(err, os.ErrNotExist), "the temporary file was not renamed")
+}
+
+func TestCounter_Corrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter")
+	c, err := counterfile.Open(path)
+	require.NoError(t, err)
+	_, err = c.Increment()
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte{1}, 0600))
+	_, err = c.Current()
+	assert.True(t, errors.Is(err, counterfile.ErrCorrupted), "error = %v", err)
+	_, err = c.Increment()
+	assert.True(t, errors.Is(err, counterfile.ErrCorrupted), "error = %v", err)
+	_, err = counterfile.Open(path)
+	assert.True(t, errors.Is(err, counterfile.ErrCorrupted), "error = %v", err)
+}
+
+func TestCounter_ImportState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter")
+	key := bytes.Repeat([]byte{42}, keygen.StateKeySize)
+	r, _, err := keygen.NewRound(1, party.IDSlice{1, 2, 3}, 1)
+	require.NoError(t, err)
+	s, err := state.NewBaseState(r, 0)
+	require.NoError(t, err)
+	s.ProcessAll()
+
+	c, err := counterfile.Open(path)
+	require.NoError(t, err)
+	older, err := keygen.ExportState(s, key, c)
+	require.NoError(t, err)
+	newer, err := keygen.ExportState(s, key, c)
+	require.NoError(t, err)
+
+	// A new process only resumes from the last state
+	c, err = counterfile.Open(path)
+	require.NoError(t, err)
+	_, _, err = keygen.ImportState(older, key, c, 0)
+	assert.True(t, errors.Is(err, keygen.ErrRoundCompleted), "error = %v", err)
+	_, _, err = keygen.ImportState(newer, key, c, 0)
+	assert.NoError(t, err)
+
+	// A file which was rolled back is detected
+	require.NoError(t, os.WriteFile(path, make([]byte, 8), 0600))
+	_, _, err = keygen.ImportState(newer, key, c, 0)
+	assert.True(t, errors.Is(err, keygen.ErrExportCounter), "error = %v", err)
+}
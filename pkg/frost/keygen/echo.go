@@ -0,0 +1,82 @@
+package keygen
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// With a point-to-point transport, a malicious party can send different commitments to different parties,
+// which would then compute different group keys.
+// When the keygen is run with an echo round, each party broadcasts the digest
+//
+//	SHA-256("FROST-Ed25519 keygen echo" ∥ session ID ∥ (id ∥ commitments)...)
+//
+// of the commitments it received in KeyGen1 messages, including its own, sorted by ID.
+// The shares are only sent once all digests were found to be equal to ours.
+//
+// A party whose digest differs either received different commitments, or lied about them.
+// The digests alone do not reveal which party equivocated, so the error names the parties whose digest differs,
+// and the application must decide whether to trust them.
+
+// ErrEchoMismatch is returned when a party received different commitments than we did.
+var ErrEchoMismatch = errors.New("received commitments differ")
+
+const echoContext = "FROST-Ed25519 keygen echo"
+
+// WithEchoRound returns an Option which adds an echo round between the first and second rounds,
+// in which the parties check that they received the same commitments.
+// Since it changes the messages that are exchanged, all parties must use this option, or none.
+func WithEchoRound() Option {
+	return func(round *round0) {
+		round.Echo = true
+	}
+}
+
+type roundEcho struct {
+	*round1
+}
+
+// echoDigest returns the digest of the commitments of all parties.
+func (round *round0) echoDigest() [messages.SizeEchoDigest]byte {
+	return computeEchoDigest(round.SessionID(), round.PartyIDs(), round.Commitments)
+}
+
+// computeEchoDigest returns the digest of the commitments of the parties partyIDs.
+func computeEchoDigest(sessionID messages.SessionID, partyIDs party.IDSlice, commitments map[party.ID]*polynomial.Exponent) [messages.SizeEchoDigest]byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte(echoContext))
+	_, _ = h.Write(sessionID[:])
+	for _, id := range partyIDs {
+		_, _ = h.Write(id.Bytes())
+		// The commitments of every party were set in ProcessMessage, and can be marshalled
+		data, _ := commitments[id].MarshalBinary()
+		_, _ = h.Write(data)
+	}
+	var digest [messages.SizeEchoDigest]byte
+	copy(digest[:], h.Sum(nil))
+	return digest
+}
+
+func (round *roundEcho) ProcessMessage(msg *messages.Message) *state.Error {
+	if msg.KeyGenEcho.Digest != round.EchoDigest {
+		return state.NewErrorWithKind(msg.From, state.KindInconsistentBroadcast, ErrEchoMismatch)
+	}
+	return nil
+}
+
+func (round *roundEcho) GenerateMessages() ([]*messages.Message, *state.Error) {
+	return round.generateShares()
+}
+
+func (round *roundEcho) NextRound() state.Round {
+	return &round2{round.round1}
+}
+
+func (round *roundEcho) MessageType() messages.MessageType {
+	return messages.MessageTypeKeyGenEcho
+}
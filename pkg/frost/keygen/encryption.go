@@ -0,0 +1,131 @@
+package keygen
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/hkdf"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// When the keygen is run with encrypted shares, the shares of the second round are encrypted to their recipient,
+// so that KeyGen2 messages can be relayed by parties which must not learn them, such as an untrusted broadcast server.
+//
+// In round 0, each party i samples an ephemeral secret eᵢ, and publishes Eᵢ = [eᵢ] B in its KeyGen1 message.
+// The share sent by i to j is then encrypted with AES-256-GCM under the key
+//
+//	HKDF-SHA256(secret = [eᵢ] Eⱼ, salt = session ID, info = "FROST-Ed25519 keygen share" ∥ i ∥ j ∥ Eᵢ ∥ Eⱼ)
+//
+// with the associated data session ID ∥ i ∥ j. Since each key encrypts a single share, the nonce is zero.
+// The ephemeral keys belong to the same group as the rest of the protocol, so that no other curve is needed.
+//
+// The encryption only ensures the confidentiality of the shares: a relay able to modify the KeyGen1 messages
+// could replace the ephemeral keys with its own, so the messages should also be authenticated.
+
+var (
+	// ErrDecryptShare is returned when a sealed share cannot be decrypted.
+	ErrDecryptShare = errors.New("failed to decrypt share")
+
+	// ErrInvalidEncryptionKey is returned when a party's ephemeral key is the identity.
+	ErrInvalidEncryptionKey = errors.New("invalid encryption key")
+)
+
+const shareEncryptionContext = "FROST-Ed25519 keygen share"
+
+// An Option modifies the keygen protocol, and must be the same for all parties.
+type Option func(*round0)
+
+// WithEncryptedShares returns an Option which makes the parties encrypt the shares sent in KeyGen2 messages
+// to their recipient, using ephemeral keys exchanged in KeyGen1 messages.
+// Since the messages of the two modes are not compatible, all parties must use this option, or none.
+func WithEncryptedShares() Option {
+	return func(round *round0) {
+		round.Encrypted = true
+	}
+}
+
+// shareCipher returns the AEAD used to encrypt the share sent from from to to, and the associated data.
+// One of them must be our ID.
+func (round *round0) shareCipher(from, to party.ID) (cipher.AEAD, []byte, error) {
+	fromKey, toKey := round.EncryptionKeys[from], round.EncryptionKeys[to]
+	if fromKey == nil || toKey == nil {
+		return nil, nil, errors.New("missing encryption key")
+	}
+	peerKey := toKey
+	if to == round.SelfID() {
+		peerKey = fromKey
+	}
+
+	var shared ristretto.Element
+	shared.ScalarMult(&round.EncryptionSecret, peerKey)
+	defer shared.Set(ristretto.NewIdentityElement())
+
+	sessionID := round.SessionID()
+	ad := make([]byte, 0, len(sessionID)+2*party.IDByteSize)
+	ad = append(ad, sessionID[:]...)
+	ad = append(ad, from.Bytes()...)
+	ad = append(ad, to.Bytes()...)
+
+	info := make([]byte, 0, len(shareEncryptionContext)+2*party.IDByteSize+2*32)
+	info = append(info, shareEncryptionContext...)
+	info = append(info, from.Bytes()...)
+	info = append(info, to.Bytes()...)
+	info = append(info, fromKey.Bytes()...)
+	info = append(info, toKey.Bytes()...)
+
+	sharedBytes := shared.Bytes()
+	defer wipe(sharedBytes)
+	key := hkdf.Key(sharedBytes, sessionID[:], info)
+	defer wipe(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aead, ad, nil
+}
+
+// sealShare encrypts the share for the party to.
+func (round *round0) sealShare(to party.ID, share *ristretto.Scalar) ([]byte, error) {
+	aead, ad, err := round.shareCipher(round.SelfID(), to)
+	if err != nil {
+		return nil, fmt.Errorf("keygen: failed to encrypt share for party %d: %w", to, err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	plaintext := share.Bytes()
+	defer wipe(plaintext)
+	return aead.Seal(make([]byte, 0, messages.SizeSealedShare), nonce, plaintext, ad), nil
+}
+
+// openShare decrypts the share sent to us by the party from.
+func (round *round0) openShare(from party.ID, sealed []byte) (*ristretto.Scalar, error) {
+	aead, ad, err := round.shareCipher(from, round.SelfID())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecryptShare, err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	data, err := aead.Open(nil, nonce, sealed, ad)
+	if err != nil {
+		return nil, ErrDecryptShare
+	}
+	defer wipe(data)
+	var share ristretto.Scalar
+	if _, err = share.SetCanonicalBytes(data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecryptShare, err)
+	}
+	return &share, nil
+}
+
+// wipe overwrites b with zeros.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,208 @@
+package keygen
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	cryptorand "crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// An exported state is encoded as
+//
+//	"FROSTKG" ∥ version ∥ roundNumber ∥ counter ∥ nonce ∥ AES-256-GCM(snapshot)
+//
+// where roundNumber is a uint16, counter is the uint64 value of the ExportCounter of the party after the export,
+// and snapshot is the binary encoding of the state.Snapshot of the keygen.
+// The header is the associated data, so that the round number and the counter cannot be changed.
+
+var (
+	// ErrStateKeySize is returned when the key given to ExportState or ImportState does not have StateKeySize bytes.
+	ErrStateKeySize = fmt.Errorf("keygen: the state key must have %d bytes", StateKeySize)
+
+	// ErrTamperedState is returned by ImportState when the state was modified, or encrypted with another key.
+	ErrTamperedState = errors.New("keygen: exported state was modified or encrypted with another key")
+
+	// ErrRoundCompleted is returned by ImportState when a newer state of the party was exported,
+	// so that the round of the exported state may have been completed.
+	// It is also returned for the last stored state if the process stopped before storing the result of ExportState.
+	ErrRoundCompleted = errors.New("keygen: the round of the exported state was already completed")
+
+	// ErrExportCounter is returned by ImportState when the state was exported with a counter larger than the current
+	// value of the ExportCounter, which was therefore rolled back.
+	ErrExportCounter = errors.New("keygen: the export counter is behind the exported state")
+)
+
+const (
+	// StateKeySize is the size of the keys of ExportState and ImportState.
+	StateKeySize = 32
+
+	exportedStateMagic      = "FROSTKG"
+	exportedStateVersion    = 2
+	exportedStateNonceSize  = 12
+	exportedStateHeaderSize = len(exportedStateMagic) + 1 + 2 + 8 + exportedStateNonceSize
+)
+
+// An ExportCounter durably records the number of states exported by a party, so that ImportState only resumes
+// from the last one. Otherwise, resuming from an older state would generate the messages of a completed round again,
+// and send different shares if the polynomial was not yet sampled.
+//
+// The package counterfile provides an implementation backed by a file.
+type ExportCounter interface {
+	// Increment increases the counter, and returns its new value once it is durably recorded.
+	Increment() (uint64, error)
+
+	// Current returns the value returned by the last call to Increment, or 0 if it was never called.
+	Current() (uint64, error)
+}
+
+// exportRandReader is the source of the nonces of ExportState. It is only replaced by tests.
+var exportRandReader io.Reader = cryptorand.Reader
+
+// ExportState returns the state of the keygen executed by s, encrypted under key with AES-256-GCM,
+// so that the ceremony can be resumed by another process with ImportState.
+// It contains the secret polynomial, the sum of the shares received so far, the sum of the commitments received so far
+// with the points against which the shares of their senders are checked, and the messages received for the current
+// and future rounds.
+//
+// It should be called after the messages returned by ProcessAll have been sent, and the result stored durably,
+// since counter is incremented by every export, and ImportState refuses the states exported before the last one.
+// If the result could not be stored, the state must be exported again.
+// The counter is incremented before the result is returned, so if the process stops before storing it,
+// ImportState refuses the state stored before with ErrRoundCompleted. Resuming from it could send different messages
+// for a round whose messages were already sent, so the keygen must then be started again with all parties.
+// key must have StateKeySize bytes, and must never be used to encrypt anything else.
+func ExportState(s *state.State, key []byte, counter ExportCounter) ([]byte, error) {
+	if len(key) != StateKeySize {
+		return nil, ErrStateKeySize
+	}
+	if counter == nil {
+		return nil, errors.New("keygen.ExportState: no ExportCounter")
+	}
+	snapshot, err := s.Snapshot()
+	if err != nil {
+		return nil, fmt.Errorf("keygen.ExportState: %w", err)
+	}
+	plaintext, err := snapshot.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("keygen.ExportState: %w", err)
+	}
+	defer wipe(plaintext)
+	defer wipe(snapshot.Round)
+
+	data := make([]byte, exportedStateHeaderSize, exportedStateHeaderSize+len(plaintext)+16)
+	copy(data, exportedStateMagic)
+	data[len(exportedStateMagic)] = exportedStateVersion
+	binary.BigEndian.PutUint16(data[len(exportedStateMagic)+1:], uint16(snapshot.RoundNumber))
+	if _, err = io.ReadFull(exportRandReader, data[exportedStateHeaderSize-exportedStateNonceSize:]); err != nil {
+		return nil, fmt.Errorf("keygen.ExportState: %w", err)
+	}
+
+	aead, err := stateCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	exported, err := counter.Increment()
+	if err != nil {
+		return nil, fmt.Errorf("keygen.ExportState: %w", err)
+	}
+	binary.BigEndian.PutUint64(data[len(exportedStateMagic)+3:], exported)
+	nonce := data[exportedStateHeaderSize-exportedStateNonceSize : exportedStateHeaderSize]
+	return aead.Seal(data, nonce, plaintext, data), nil
+}
+
+// ExportedRound returns the number of the round which was waiting for messages when data was exported,
+// which is also the number of rounds the party had completed.
+// It does not require the key, nor does it check that data is authentic.
+func ExportedRound(data []byte) (int, error) {
+	if err := checkExportedHeader(data); err != nil {
+		return 0, err
+	}
+	return int(binary.BigEndian.Uint16(data[len(exportedStateMagic)+1:])), nil
+}
+
+// ImportState decrypts the state exported with ExportState, and returns a state.State which resumes the keygen.
+// The returned Output is a new one, and will be filled once the protocol has finished executing.
+// timeout and opts have the same meaning as in state.NewBaseState.
+//
+// counter must be the ExportCounter given to ExportState. If another state was exported after this one,
+// resuming from it would generate the messages of a completed round again, so the error wraps ErrRoundCompleted.
+// If counter is behind the state, the error wraps ErrExportCounter.
+func ImportState(data, key []byte, counter ExportCounter, timeout time.Duration, opts ...state.Option) (*state.State, *Output, error) {
+	if len(key) != StateKeySize {
+		return nil, nil, ErrStateKeySize
+	}
+	if counter == nil {
+		return nil, nil, errors.New("keygen.ImportState: no ExportCounter")
+	}
+	roundNumber, err := ExportedRound(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aead, err := stateCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	header := data[:exportedStateHeaderSize]
+	plaintext, err := aead.Open(nil, header[exportedStateHeaderSize-exportedStateNonceSize:], data[exportedStateHeaderSize:], header)
+	if err != nil {
+		return nil, nil, ErrTamperedState
+	}
+	defer wipe(plaintext)
+
+	// The counter is only trusted once the header is authenticated
+	exported := binary.BigEndian.Uint64(header[len(exportedStateMagic)+3:])
+	current, err := counter.Current()
+	if err != nil {
+		return nil, nil, fmt.Errorf("keygen.ImportState: %w", err)
+	}
+	if exported < current {
+		return nil, nil, fmt.Errorf("%w: the state is export %d, but %d states were exported", ErrRoundCompleted, exported, current)
+	}
+	if exported > current {
+		return nil, nil, fmt.Errorf("%w: the state is export %d, but the counter is %d", ErrExportCounter, exported, current)
+	}
+
+	var snapshot state.Snapshot
+	if err = snapshot.UnmarshalBinary(plaintext); err != nil {
+		return nil, nil, fmt.Errorf("keygen.ImportState: %w", err)
+	}
+	defer wipe(snapshot.Round)
+	if snapshot.RoundNumber != roundNumber {
+		return nil, nil, ErrTamperedState
+	}
+
+	round, output, err := RestoreRound(snapshot.RoundNumber, snapshot.Round)
+	if err != nil {
+		return nil, nil, fmt.Errorf("keygen.ImportState: %w", err)
+	}
+	s, err := state.RestoreState(round, &snapshot, timeout, opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("keygen.ImportState: %w", err)
+	}
+	return s, output, nil
+}
+
+func checkExportedHeader(data []byte) error {
+	if len(data) < exportedStateHeaderSize || string(data[:len(exportedStateMagic)]) != exportedStateMagic {
+		return errors.New("keygen: data is not an exported keygen state")
+	}
+	if version := data[len(exportedStateMagic)]; version != exportedStateVersion {
+		return fmt.Errorf("keygen: unsupported exported state version %d", version)
+	}
+	return nil
+}
+
+func stateCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,216 @@
+package keygen
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// memoryCounter is an ExportCounter kept in memory, whose methods return err if it is set.
+type memoryCounter struct {
+	value uint64
+	err   error
+}
+
+func (c *memoryCounter) Increment() (uint64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	c.value++
+	return c.value, nil
+}
+
+func (c *memoryCounter) Current() (uint64, error) {
+	return c.value, c.err
+}
+
+func TestExportState(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	key := bytes.Repeat([]byte{42}, StateKeySize)
+
+	for _, opts := range [][]Option{nil, {WithEncryptedShares(), WithConfirmation()}} {
+		counter := &memoryCounter{}
+		states := make(map[party.ID]*state.State, partyIDs.N())
+		outputs := make(map[party.ID]*Output, partyIDs.N())
+		for _, id := range partyIDs {
+			r, output, err := NewRound(id, partyIDs, 1, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			outputs[id] = output
+			if states[id], err = state.NewBaseState(r, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var exports [][]byte
+		for i := 0; i < 5; i++ {
+			var out []*messages.Message
+			for _, id := range partyIDs {
+				out = append(out, states[id].ProcessAll()...)
+			}
+
+			// Party 1 stops after sending its messages, and resumes in a new State
+			if !states[1].IsFinished() {
+				data, err := ExportState(states[1], key, counter)
+				if err != nil {
+					t.Fatal(err)
+				}
+				exports = append(exports, data)
+				if states[1], outputs[1], err = ImportState(data, key, counter, 0); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for _, msg := range out {
+				data, err := msg.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, id := range partyIDs {
+					if msg.To != 0 && msg.To != id {
+						continue
+					}
+					var msgCopy messages.Message
+					if err = msgCopy.UnmarshalBinary(data); err != nil {
+						t.Fatal(err)
+					}
+					if err = states[id].HandleMessage(&msgCopy); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+		}
+		for _, id := range partyIDs {
+			if err := states[id].WaitForError(); err != nil {
+				t.Fatalf("party %d: %v", id, err)
+			}
+			if !outputs[id].Public.Equal(outputs[1].Public) {
+				t.Fatalf("party %d computed other public shares", id)
+			}
+		}
+		if err := outputs[1].SecretKey.Validate(outputs[1].Public); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(exports) < 2 {
+			t.Fatalf("party 1 was only exported %d times", len(exports))
+		}
+
+		// Resuming from an older state would generate the messages of a completed round again
+		for _, data := range exports[:len(exports)-1] {
+			if _, _, err := ImportState(data, key, counter, 0); !errors.Is(err, ErrRoundCompleted) {
+				t.Errorf("ImportState() of an older state: error = %v, want ErrRoundCompleted", err)
+			}
+		}
+		if _, _, err := ImportState(exports[len(exports)-1], key, counter, 0); err != nil {
+			t.Errorf("ImportState() of the last state: %v", err)
+		}
+	}
+}
+
+func TestImportState_Invalid(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	key := bytes.Repeat([]byte{42}, StateKeySize)
+
+	r, _, err := NewRound(1, partyIDs, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := state.NewBaseState(r, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.ProcessAll()
+	counter := &memoryCounter{}
+	if _, err = ExportState(s, key[:16], counter); !errors.Is(err, ErrStateKeySize) {
+		t.Errorf("ExportState() with a short key: error = %v", err)
+	}
+	if _, err = ExportState(s, key, nil); err == nil {
+		t.Error("ExportState() should fail without an ExportCounter")
+	}
+	data, err := ExportState(s, key, counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, r.(*round0).Secret.Bytes()) {
+		t.Error("the exported state contains the secret in the clear")
+	}
+
+	otherKey := bytes.Repeat([]byte{43}, StateKeySize)
+	if _, _, err = ImportState(data, otherKey, counter, 0); !errors.Is(err, ErrTamperedState) {
+		t.Errorf("ImportState() with another key: error = %v", err)
+	}
+	// The bytes of the round number, of the counter, and of the ciphertext
+	for _, i := range []int{len(exportedStateMagic) + 2, len(exportedStateMagic) + 10, exportedStateHeaderSize, len(data) - 1} {
+		tampered := append([]byte(nil), data...)
+		tampered[i] ^= 1
+		if _, _, err = ImportState(tampered, key, counter, 0); !errors.Is(err, ErrTamperedState) {
+			t.Errorf("ImportState() accepted a state modified at byte %d", i)
+		}
+	}
+	if _, _, err = ImportState(data[:exportedStateHeaderSize-1], key, counter, 0); err == nil {
+		t.Error("ImportState() accepted a truncated state")
+	}
+	if _, _, err = ImportState(data, key, nil, 0); err == nil {
+		t.Error("ImportState() should fail without an ExportCounter")
+	}
+	if _, _, err = ImportState(data, key, counter, 0); err != nil {
+		t.Errorf("ImportState() = %v", err)
+	}
+
+	// A counter which was rolled back is detected
+	counter.value = 0
+	if _, _, err = ImportState(data, key, counter, 0); !errors.Is(err, ErrExportCounter) {
+		t.Errorf("ImportState() with a rolled back counter: error = %v", err)
+	}
+	counter.err = errors.New("corrupted counter")
+	if _, _, err = ImportState(data, key, counter, 0); !errors.Is(err, counter.err) {
+		t.Errorf("ImportState() with a failing counter: error = %v", err)
+	}
+	if _, err = ExportState(s, key, counter); !errors.Is(err, counter.err) {
+		t.Errorf("ExportState() with a failing counter: error = %v", err)
+	}
+}
+
+func TestImportState_Stale(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	key := bytes.Repeat([]byte{42}, StateKeySize)
+	counter := &memoryCounter{}
+
+	r, _, err := NewRound(1, partyIDs, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := state.NewBaseState(r, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.ProcessAll()
+	older, err := ExportState(s, key, counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newer, err := ExportState(s, key, counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both states are in the same round, so only the counter distinguishes them
+	olderRound, _ := ExportedRound(older)
+	newerRound, _ := ExportedRound(newer)
+	if olderRound != newerRound {
+		t.Fatalf("the states were exported in rounds %d and %d", olderRound, newerRound)
+	}
+	// The older state is refused even if the newer one was never stored
+	if _, _, err = ImportState(older, key, counter, 0); !errors.Is(err, ErrRoundCompleted) {
+		t.Errorf("ImportState() of the older state: error = %v, want ErrRoundCompleted", err)
+	}
+	if _, _, err = ImportState(newer, key, counter, 0); err != nil {
+		t.Errorf("ImportState() of the newer state: %v", err)
+	}
+}
@@ -0,0 +1,329 @@
+package keygen
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"runtime"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// deterministic returns the options of party id, which samples its secrets from a reader seeded with its ID.
+func deterministic(id party.ID, opts []Option) []Option {
+	return append([]Option{WithRandom(rand.New(rand.NewSource(int64(id))))}, opts...)
+}
+
+// runRounds executes the keygen by calling the methods of the rounds directly,
+// so that all messages of a round are processed at once, after they were all received.
+func runRounds(t *testing.T, partyIDs party.IDSlice, threshold party.Size, opts []Option) map[party.ID]*Output {
+	rounds := make(map[party.ID]state.Round, partyIDs.N())
+	outputs := make(map[party.ID]*Output, partyIDs.N())
+	for _, id := range partyIDs {
+		r, output, err := NewRound(id, partyIDs, threshold, deterministic(id, opts)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rounds[id], outputs[id] = r, output
+	}
+	var in []*messages.Message
+	for rounds[partyIDs[0]] != nil {
+		var out []*messages.Message
+		for _, id := range partyIDs {
+			r := rounds[id]
+			for _, msg := range in {
+				if msg.From != id && (msg.To == 0 || msg.To == id) {
+					if err := r.ProcessMessage(msg); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			msgs, err := r.GenerateMessages()
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, msgs...)
+			rounds[id] = r.NextRound()
+		}
+		in = out
+	}
+	return outputs
+}
+
+// runStates executes the keygen with States, and delivers the messages of each round before the recipient
+// has processed the previous one if early is true.
+func runStates(t *testing.T, partyIDs party.IDSlice, threshold party.Size, opts []Option, early bool) map[party.ID]*Output {
+	states := make(map[party.ID]*state.State, partyIDs.N())
+	outputs := make(map[party.ID]*Output, partyIDs.N())
+	for _, id := range partyIDs {
+		r, output, err := NewRound(id, partyIDs, threshold, deterministic(id, opts)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[id] = output
+		if states[id], err = state.NewBaseState(r, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deliver := func(msgs []*messages.Message) {
+		for _, msg := range msgs {
+			for _, id := range partyIDs {
+				if msg.From == id || msg.To != 0 && msg.To != id {
+					continue
+				}
+				var msgCopy messages.Message
+				if err := msgCopy.UnmarshalBinary(marshal(t, msg)); err != nil {
+					t.Fatal(err)
+				}
+				if err := states[id].HandleMessage(&msgCopy); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+	for i := 0; i < 6; i++ {
+		if early {
+			// Each party sends its messages to the others before they processed their previous round
+			for _, id := range partyIDs {
+				deliver(states[id].ProcessAll())
+			}
+			continue
+		}
+		var out []*messages.Message
+		for _, id := range partyIDs {
+			out = append(out, states[id].ProcessAll()...)
+		}
+		deliver(out)
+	}
+	for _, id := range partyIDs {
+		if err := states[id].WaitForError(); err != nil {
+			t.Fatalf("party %d: %v", id, err)
+		}
+	}
+	return outputs
+}
+
+func marshal(t *testing.T, msg *messages.Message) []byte {
+	data, err := msg.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestIncremental_SameOutput(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3, 4, 5}
+	for _, opts := range [][]Option{nil, {WithCommitments()}, {WithEncryptedShares(), WithEchoRound()}, {WithBlame()}} {
+		expected := runRounds(t, partyIDs, 2, opts)
+		for _, early := range []bool{false, true} {
+			outputs := runStates(t, partyIDs, 2, opts, early)
+			for _, id := range partyIDs {
+				if !outputs[id].Public.Equal(expected[id].Public) || !outputs[id].SecretKey.Equal(expected[id].SecretKey) {
+					t.Errorf("early = %t, party %d: the output differs from the one of the rounds processed at once", early, id)
+				}
+				if expected[id].Commitments == nil {
+					continue
+				}
+				got, _ := outputs[id].Commitments.MarshalBinary()
+				want, _ := expected[id].Commitments.MarshalBinary()
+				if !bytes.Equal(got, want) {
+					t.Errorf("early = %t, party %d: the exported commitments differ", early, id)
+				}
+			}
+		}
+	}
+}
+
+func TestIncremental_Retransmission(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	states := make(map[party.ID]*state.State, partyIDs.N())
+	keyGen1 := make(map[party.ID]*messages.Message, partyIDs.N())
+	for _, id := range partyIDs {
+		r, _, err := NewRound(id, partyIDs, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if states[id], err = state.NewBaseState(r, 0); err != nil {
+			t.Fatal(err)
+		}
+		keyGen1[id] = states[id].ProcessAll()[0]
+	}
+	s := states[1]
+	if err := s.HandleMessage(keyGen1[2]); err != nil {
+		t.Fatal(err)
+	}
+	if received, _ := s.ReceivedFrom(); !received.Equal(party.IDSlice{2}) {
+		t.Errorf("ReceivedFrom() = %v, want [2]", received)
+	}
+
+	// The message was processed, and the round can be snapshotted and restored without it
+	snapshot, err := s.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.Messages) != 0 || len(snapshot.Processed) != 1 {
+		t.Fatalf("the snapshot contains %d messages and %d processed", len(snapshot.Messages), len(snapshot.Processed))
+	}
+	data, err := snapshot.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded state.Snapshot
+	if err = decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	r, output, err := RestoreRound(decoded.RoundNumber, decoded.Round)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := state.RestoreState(r, &decoded, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A retransmission is ignored, and another message is an equivocation
+	if err = restored.HandleMessage(keyGen1[2]); err != nil {
+		t.Errorf("retransmission: %v", err)
+	}
+	other, _, _ := NewRound(2, partyIDs, 1)
+	otherMsgs, _ := other.GenerateMessages()
+	if err = s.HandleMessage(otherMsgs[0]); !errors.Is(err, state.ErrEquivocation) {
+		t.Errorf("equivocation: error = %v", err)
+	}
+
+	s, states[1] = restored, restored
+	if err = s.HandleMessage(keyGen1[3]); err != nil {
+		t.Fatal(err)
+	}
+	var out []*messages.Message
+	for _, id := range partyIDs {
+		for _, from := range partyIDs {
+			if from != id && id != 1 {
+				if err = states[id].HandleMessage(keyGen1[from]); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		out = append(out, states[id].ProcessAll()...)
+	}
+	for _, msg := range out {
+		if err = states[msg.To].HandleMessage(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range partyIDs {
+		states[id].ProcessAll()
+		if err = states[id].WaitForError(); err != nil {
+			t.Fatalf("party %d: %v", id, err)
+		}
+	}
+	if err = output.SecretKey.Validate(output.Public); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// encodedRounds returns the encoded KeyGen1 messages of parties 2, ..., n, and their KeyGen2 messages for party 1.
+func encodedRounds(tb testing.TB, n, threshold party.Size) (keyGen1, keyGen2 [][]byte) {
+	partyIDs := make(party.IDSlice, n)
+	for i := range partyIDs {
+		partyIDs[i] = party.ID(i + 1)
+	}
+	var x party.ID = 1
+	for _, id := range partyIDs[1:] {
+		r, _, err := NewRound(id, partyIDs, threshold)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		round := r.(*round0)
+		msgs, stateErr := round.GenerateMessages()
+		if stateErr != nil {
+			tb.Fatal(stateErr)
+		}
+		data, err := msgs[0].MarshalBinary()
+		if err != nil {
+			tb.Fatal(err)
+		}
+		keyGen1 = append(keyGen1, data)
+
+		share := round.Polynomial.Evaluate(x.Scalar())
+		if data, err = messages.NewKeyGen2(id, x, share).MarshalBinary(); err != nil {
+			tb.Fatal(err)
+		}
+		keyGen2 = append(keyGen2, data)
+	}
+	return keyGen1, keyGen2
+}
+
+// heapInUse returns the size of the live heap after a garbage collection.
+func heapInUse() uint64 {
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// benchmarkKeygenMemory runs the keygen of party 1 among n parties, and reports the largest live heap
+// of the party while it waits for the messages of a round, in addition to the one before it started.
+func benchmarkKeygenMemory(b *testing.B, n, threshold party.Size) {
+	keyGen1, keyGen2 := encodedRounds(b, n, threshold)
+	partyIDs := make(party.IDSlice, n)
+	for i := range partyIDs {
+		partyIDs[i] = party.ID(i + 1)
+	}
+
+	deliver := func(s *state.State, encoded [][]byte) {
+		for _, data := range encoded {
+			var msg messages.Message
+			if err := msg.UnmarshalBinary(data); err != nil {
+				b.Fatal(err)
+			}
+			if err := s.HandleMessage(&msg); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	var peak uint64
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		base := heapInUse()
+		b.StartTimer()
+
+		r, output, err := NewRound(1, partyIDs, threshold)
+		if err != nil {
+			b.Fatal(err)
+		}
+		s, err := state.NewBaseState(r, 0)
+		if err != nil {
+			b.Fatal(err)
+		}
+		s.ProcessAll()
+		for _, encoded := range [][][]byte{keyGen1, keyGen2} {
+			deliver(s, encoded)
+
+			b.StopTimer()
+			if live := heapInUse() - base; live > peak {
+				peak = live
+			}
+			b.StartTimer()
+
+			s.ProcessAll()
+		}
+		if err = s.WaitForError(); err != nil {
+			b.Fatal(err)
+		}
+		if output.SecretKey == nil {
+			b.Fatal("no output")
+		}
+	}
+	b.ReportMetric(float64(peak), "peak-B")
+}
+
+func BenchmarkKeygenMemory256(b *testing.B) {
+	benchmarkKeygenMemory(b, 256, 170)
+}
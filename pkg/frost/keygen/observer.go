@@ -0,0 +1,339 @@
+package keygen
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/hashing"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// An observer, such as an auditor, follows a keygen without receiving a share, and computes the eddsa.Public
+// of the generated key on its own. It receives the messages broadcast by the shareholders, that is the KeyGen1 messages
+// and, if the keygen has them, the KeyGenEcho and KeyGenConfirm messages, but no KeyGen2 message, and it sends nothing.
+//
+// The observer checks the proofs of knowledge of the shareholders, and compares the digests of the echo and confirmation
+// rounds with the ones it computes from the commitments it received. A shareholder which sent the observer different
+// commitments than to the others, or which computed another public key, is then named in the error.
+// Without WithEchoRound or WithConfirmation, the observer cannot detect such an inconsistency.
+//
+// The observers are declared to the shareholders with WithObservers, and are bound to the proofs of knowledge,
+// so that the shareholders and the observers agree on who observes the ceremony.
+// They are not part of the shareholders given to NewRound, so that no share is sent to them.
+
+// observersContextDomainSeparation is appended to the context of a keygen with observers, before the observers.
+const observersContextDomainSeparation = "FROST-Ed25519 keygen observers"
+
+// WithObservers returns an Option which declares observers, parties which verify the keygen with NewObserverRound
+// without receiving a share. They must be distinct from the shareholders.
+// Since it changes the proofs of knowledge, all shareholders and observers must use this option with the same observers.
+func WithObservers(observers party.IDSlice) Option {
+	observers = party.NewIDSlice(observers)
+	return func(round *round0) {
+		round.Observers = observers
+	}
+}
+
+// observersContext returns context followed by the observers, after checking that they are distinct from partyIDs.
+func observersContext(context []byte, partyIDs, observers party.IDSlice) ([]byte, error) {
+	if len(observers) == 0 {
+		return context, nil
+	}
+	for i, id := range observers {
+		if id == 0 || i > 0 && id == observers[i-1] {
+			return nil, errors.New("keygen: the observers must be distinct and non-zero")
+		}
+	}
+	if !observers.IsDisjoint(partyIDs) {
+		return nil, errors.New("keygen: a party cannot be both a shareholder and an observer")
+	}
+	bound := make([]byte, 0, len(context)+len(observersContextDomainSeparation)+(len(observers)+1)*party.IDByteSize)
+	bound = append(bound, context...)
+	bound = append(bound, observersContextDomainSeparation...)
+	bound = append(bound, observers.N().Bytes()...)
+	for _, id := range observers {
+		bound = append(bound, id.Bytes()...)
+	}
+	return bound, nil
+}
+
+// bindObservers appends the observers to the Context of the keygen.
+func (round *round0) bindObservers() error {
+	context, err := observersContext(round.Context, round.PartyIDs(), round.Observers)
+	if err != nil {
+		return err
+	}
+	round.Context = context
+	return nil
+}
+
+type (
+	observerRound0 struct {
+		*state.BaseRound
+
+		// Shareholders are the parties receiving a share of the key, from which all messages are received.
+		Shareholders party.IDSlice
+
+		// Threshold is the degree of the polynomials of the shareholders.
+		Threshold party.Size
+
+		// Echo, Confirm and Encrypted are set by the same options as for the shareholders.
+		Echo, Confirm, Encrypted bool
+
+		// ExportCommitments indicates that the Commitments are copied to the Output, as set by WithCommitments.
+		ExportCommitments bool
+
+		// Context is the context of the ceremony, followed by the observers.
+		Context []byte
+
+		// CommitmentsSum is the sum of the commitments of all shareholders.
+		CommitmentsSum *polynomial.Exponent
+
+		// Commitments contains the commitments of all shareholders, if the keygen has an echo round,
+		// or if they are exported.
+		Commitments map[party.ID]*polynomial.Exponent
+
+		// EchoDigest and ConfirmDigest are the digests we compute, against which those of the shareholders are checked.
+		EchoDigest    [messages.SizeEchoDigest]byte
+		ConfirmDigest [messages.SizeConfirmDigest]byte
+
+		Output *ObserverOutput
+	}
+	observerRound1 struct {
+		*observerRound0
+	}
+	observerRoundEcho struct {
+		*observerRound1
+	}
+	observerRoundConfirm struct {
+		*observerRound1
+	}
+)
+
+// ObserverOutput is filled once an observed keygen has finished.
+type ObserverOutput struct {
+	// Public contains the group key and public shares computed from the commitments of the shareholders.
+	Public *eddsa.Public
+
+	// Commitments contains the commitments of the shareholders, if the observer used WithCommitments.
+	Commitments *eddsa.Commitments
+}
+
+// NewObserverRound returns the first round of the keygen between partyIDs as seen by the observer selfID,
+// and the ObserverOutput which is filled once it has finished.
+// opts must contain WithObservers with selfID, and the same options as the shareholders, except that WithRandom
+// and WithCommitments may differ. WithBlame and WithRobust are not supported.
+// The State of the observer expects messages from all shareholders, and its party set contains them and selfID.
+func NewObserverRound(selfID party.ID, partyIDs party.IDSlice, threshold party.Size, opts ...Option) (state.Round, *ObserverOutput, error) {
+	partyIDs = party.NewIDSlice(partyIDs)
+	if err := (Parameters{PartyIDs: partyIDs, Threshold: threshold}).Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	// The options are applied to a keygen, from which the supported ones are read
+	var options round0
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.Blame || options.Robust {
+		return nil, nil, errors.New("keygen: WithBlame and WithRobust are not supported by an observer")
+	}
+	if !options.Observers.Contains(selfID) {
+		return nil, nil, fmt.Errorf("keygen: party %d is not one of the observers", selfID)
+	}
+	context, err := observersContext(options.Context, partyIDs, options.Observers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	baseRound, err := state.NewBaseRound(selfID, append(partyIDs.Copy(), selfID))
+	if err != nil {
+		return nil, nil, err
+	}
+	r := observerRound0{
+		BaseRound:         baseRound,
+		Shareholders:      partyIDs,
+		Threshold:         threshold,
+		Echo:              options.Echo,
+		Confirm:           options.Confirm,
+		Encrypted:         options.Encrypted,
+		ExportCommitments: options.ExportCommitments,
+		Context:           context,
+		Output:            &ObserverOutput{},
+	}
+	if r.Echo || r.ExportCommitments {
+		r.Commitments = make(map[party.ID]*polynomial.Exponent, partyIDs.N())
+	}
+	return &r, r.Output, nil
+}
+
+// Reset is called by the State when the protocol finishes or is aborted.
+// The observer holds no secret, and only sets the commitments to the identity.
+func (round *observerRound0) Reset() {
+	if round.CommitmentsSum != nil {
+		round.CommitmentsSum.Reset()
+	}
+	for _, p := range round.Commitments {
+		p.Reset()
+	}
+	round.Output = nil
+}
+
+func (round *observerRound0) AcceptedMessageTypes() []messages.MessageType {
+	types := []messages.MessageType{messages.MessageTypeNone, messages.MessageTypeKeyGen1}
+	if round.Echo {
+		types = append(types, messages.MessageTypeKeyGenEcho)
+	}
+	if round.Confirm {
+		types = append(types, messages.MessageTypeKeyGenConfirm)
+	}
+	return types
+}
+
+// Senders implements state.SenderFilter, since only the shareholders send messages.
+func (round *observerRound0) Senders(messages.MessageType) party.IDSlice {
+	return round.Shareholders
+}
+
+// MessageLimits implements state.Limiter, since the commitments in KeyGen1 messages depend on the threshold.
+func (round *observerRound0) MessageLimits() messages.Limits {
+	return messages.Limits{
+		Threshold:       round.Threshold,
+		EncryptedShares: round.Encrypted,
+		Parties:         round.Shareholders.N(),
+	}
+}
+
+func (round *observerRound0) GenerateMessages() ([]*messages.Message, *state.Error) {
+	return nil, nil
+}
+
+func (round *observerRound0) NextRound() state.Round {
+	return &observerRound1{round}
+}
+
+// public returns the public shares of the shareholders and the group key.
+func (round *observerRound0) public() *eddsa.Public {
+	return computePublic(round.Shareholders, round.Threshold, round.CommitmentsSum)
+}
+
+// finish sets the Output, or computes the digest of the confirmation round, in which case the Output is set
+// by observerRoundConfirm.
+func (round *observerRound0) finish() ([]*messages.Message, *state.Error) {
+	if round.Confirm {
+		round.ConfirmDigest = computeConfirmDigest(round.SessionID(), round.public())
+		return nil, nil
+	}
+	round.setOutput()
+	return nil, nil
+}
+
+func (round *observerRound0) setOutput() {
+	round.Output.Public = round.public()
+	if round.ExportCommitments {
+		parties := make(map[party.ID]*polynomial.Exponent, round.Shareholders.N())
+		for _, id := range round.Shareholders {
+			parties[id] = round.Commitments[id].Copy()
+		}
+		round.Output.Commitments = &eddsa.Commitments{
+			Threshold: round.Threshold,
+			Parties:   parties,
+			Sum:       round.CommitmentsSum.Copy(),
+		}
+	}
+}
+
+func (round *observerRound1) ProcessMessage(msg *messages.Message) *state.Error {
+	from := msg.From
+	commitments := msg.KeyGen1.Commitments
+	ctx := hashing.KeygenContext(round.SessionID(), round.Shareholders, round.Threshold, round.Context)
+	if !msg.KeyGen1.Proof.Verify(from, commitments.Constant(), ctx[:]) {
+		return state.NewErrorWithKind(from, state.KindInvalidProof, ErrValidateProof)
+	}
+	if round.Encrypted {
+		// The shareholders abort if the encryption key is invalid, and so does the observer
+		key := msg.KeyGen1.EncryptionKey
+		if key == nil || key.Equal(ristretto.NewIdentityElement()) == 1 {
+			return state.NewError(from, ErrInvalidEncryptionKey)
+		}
+	}
+
+	if round.Commitments != nil {
+		round.Commitments[from] = commitments
+	}
+	if round.CommitmentsSum == nil {
+		round.CommitmentsSum = commitments.Copy()
+		return nil
+	}
+	_ = round.CommitmentsSum.Add(commitments)
+	return nil
+}
+
+func (round *observerRound1) GenerateMessages() ([]*messages.Message, *state.Error) {
+	if round.Echo {
+		round.EchoDigest = computeEchoDigest(round.SessionID(), round.Shareholders, round.Commitments)
+		return nil, nil
+	}
+	return round.finish()
+}
+
+func (round *observerRound1) NextRound() state.Round {
+	if round.Echo {
+		return &observerRoundEcho{round}
+	}
+	if round.Confirm {
+		return &observerRoundConfirm{round}
+	}
+	return nil
+}
+
+func (round *observerRound1) MessageType() messages.MessageType {
+	return messages.MessageTypeKeyGen1
+}
+
+func (round *observerRoundEcho) ProcessMessage(msg *messages.Message) *state.Error {
+	if msg.KeyGenEcho.Digest != round.EchoDigest {
+		return state.NewErrorWithKind(msg.From, state.KindInconsistentBroadcast, ErrEchoMismatch)
+	}
+	return nil
+}
+
+func (round *observerRoundEcho) GenerateMessages() ([]*messages.Message, *state.Error) {
+	return round.finish()
+}
+
+func (round *observerRoundEcho) NextRound() state.Round {
+	if round.Confirm {
+		return &observerRoundConfirm{round.observerRound1}
+	}
+	return nil
+}
+
+func (round *observerRoundEcho) MessageType() messages.MessageType {
+	return messages.MessageTypeKeyGenEcho
+}
+
+func (round *observerRoundConfirm) ProcessMessage(msg *messages.Message) *state.Error {
+	if msg.KeyGenConfirm.Digest != round.ConfirmDigest {
+		return state.NewErrorWithKind(msg.From, state.KindInconsistentBroadcast, ErrConfirmMismatch)
+	}
+	return nil
+}
+
+func (round *observerRoundConfirm) GenerateMessages() ([]*messages.Message, *state.Error) {
+	round.setOutput()
+	return nil, nil
+}
+
+func (round *observerRoundConfirm) NextRound() state.Round {
+	return nil
+}
+
+func (round *observerRoundConfirm) MessageType() messages.MessageType {
+	return messages.MessageTypeKeyGenConfirm
+}
@@ -0,0 +1,184 @@
+package keygen
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+const observerID party.ID = 9
+
+// runObserved executes a keygen between partyIDs observed by observerID, and calls tamper on each message
+// before it is delivered to the party to.
+func runObserved(t *testing.T, partyIDs party.IDSlice, opts []Option, tamper func(to party.ID, msg *messages.Message)) (map[party.ID]*state.State, map[party.ID]*Output, *ObserverOutput) {
+	opts = append([]Option{WithObservers(party.IDSlice{observerID})}, opts...)
+	states := make(map[party.ID]*state.State, partyIDs.N()+1)
+	outputs := make(map[party.ID]*Output, partyIDs.N())
+	for _, id := range partyIDs {
+		r, output, err := NewRound(id, partyIDs, 1, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[id] = output
+		if states[id], err = state.NewBaseState(r, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r, observed, err := NewObserverRound(observerID, partyIDs, 1, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if states[observerID], err = state.NewBaseState(r, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	all := append(partyIDs.Copy(), observerID)
+	for i := 0; i < 5; i++ {
+		var out []*messages.Message
+		for _, id := range all {
+			out = append(out, states[id].ProcessAll()...)
+		}
+		for _, msg := range out {
+			if msg.From == observerID {
+				t.Fatal("the observer sent a message")
+			}
+			if msg.To == observerID {
+				t.Fatalf("party %d sent a %s message to the observer", msg.From, msg.Type)
+			}
+			for _, id := range all {
+				if msg.From == id || msg.To != 0 && msg.To != id {
+					continue
+				}
+				var msgCopy messages.Message
+				if err = msgCopy.UnmarshalBinary(marshal(t, msg)); err != nil {
+					t.Fatal(err)
+				}
+				if tamper != nil {
+					tamper(id, &msgCopy)
+				}
+				_ = states[id].HandleMessage(&msgCopy)
+			}
+		}
+	}
+	return states, outputs, observed
+}
+
+func TestObserver(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	for _, opts := range [][]Option{nil, {WithEchoRound()}, {WithConfirmation(), WithEncryptedShares()}, {WithEchoRound(), WithConfirmation(), WithContext([]byte("audit"))}} {
+		states, outputs, observed := runObserved(t, partyIDs, append(opts, WithCommitments()), nil)
+		for id, s := range states {
+			if err := s.WaitForError(); err != nil {
+				t.Fatalf("party %d: %v", id, err)
+			}
+		}
+		for _, id := range partyIDs {
+			if !observed.Public.Equal(outputs[id].Public) {
+				t.Errorf("the public key of the observer differs from the one of party %d", id)
+			}
+		}
+		if err := outputs[1].Public.VerifyCommitments(observed.Commitments); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestObserver_Inconsistency(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	opts := []Option{WithEchoRound(), WithConfirmation()}
+	var stateErr *state.Error
+
+	// Party 2 sends the observer another echo digest than to the other parties
+	states, _, observed := runObserved(t, partyIDs, opts, func(to party.ID, msg *messages.Message) {
+		if to == observerID && msg.Type == messages.MessageTypeKeyGenEcho && msg.From == 2 {
+			msg.KeyGenEcho.Digest[0] ^= 1
+		}
+	})
+	err := states[observerID].WaitForError()
+	if !errors.As(err, &stateErr) || !errors.Is(err, ErrEchoMismatch) {
+		t.Fatalf("error = %v, want ErrEchoMismatch", err)
+	}
+	if !stateErr.Culprits().Equal(party.IDSlice{2}) || stateErr.Kind() != state.KindInconsistentBroadcast {
+		t.Errorf("culprits = %v, kind = %v", stateErr.Culprits(), stateErr.Kind())
+	}
+	if observed.Public != nil {
+		t.Error("the observer output a public key")
+	}
+
+	// Party 3 sends the observer other commitments with the same constant, for which its proof is still valid,
+	// and the observer's view differs from the echo digests of all parties
+	states, _, observed = runObserved(t, partyIDs, opts, func(to party.ID, msg *messages.Message) {
+		if to == observerID && msg.Type == messages.MessageTypeKeyGen1 && msg.From == 3 {
+			_ = msg.KeyGen1.Commitments.Add(polynomial.NewPolynomialExponent(polynomial.NewPolynomial(1, ristretto.NewScalar())))
+		}
+	})
+	err = states[observerID].WaitForError()
+	if !errors.As(err, &stateErr) || !errors.Is(err, ErrEchoMismatch) {
+		t.Fatalf("error = %v, want ErrEchoMismatch", err)
+	}
+	if !stateErr.Culprits().Equal(partyIDs) {
+		t.Errorf("culprits = %v", stateErr.Culprits())
+	}
+	if observed.Public != nil {
+		t.Error("the observer output a public key")
+	}
+	for _, id := range partyIDs {
+		if err = states[id].WaitForError(); err != nil {
+			t.Errorf("party %d: %v", id, err)
+		}
+	}
+
+	// Party 1 sends the observer another confirmation digest
+	states, _, _ = runObserved(t, partyIDs, []Option{WithConfirmation()}, func(to party.ID, msg *messages.Message) {
+		if to == observerID && msg.Type == messages.MessageTypeKeyGenConfirm && msg.From == 1 {
+			msg.KeyGenConfirm.Digest[0] ^= 1
+		}
+	})
+	if err = states[observerID].WaitForError(); !errors.As(err, &stateErr) || !errors.Is(err, ErrConfirmMismatch) ||
+		!stateErr.Culprits().Equal(party.IDSlice{1}) {
+		t.Fatalf("error = %v, want ErrConfirmMismatch from party 1", err)
+	}
+}
+
+func TestObserver_Invalid(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	observers := WithObservers(party.IDSlice{observerID})
+
+	// An observer cannot be a shareholder
+	if _, _, err := NewRound(1, partyIDs, 1, WithObservers(party.IDSlice{3})); err == nil {
+		t.Error("NewRound() should fail with a shareholder as observer")
+	}
+	if _, _, err := NewRound(1, partyIDs, 1, WithObservers(party.IDSlice{7, 7})); err == nil {
+		t.Error("NewRound() should fail with duplicate observers")
+	}
+	for name, opts := range map[string][]Option{
+		"not an observer": nil,
+		"blame":           {observers, WithBlame()},
+		"robust":          {observers, WithRobust()},
+		"shareholder":     {WithObservers(party.IDSlice{observerID, 2})},
+	} {
+		if _, _, err := NewObserverRound(observerID, partyIDs, 1, opts...); err == nil {
+			t.Errorf("%s: NewObserverRound() should fail", name)
+		}
+	}
+	if _, _, err := NewBatchRound(1, partyIDs, 1, 2, observers); err == nil {
+		t.Error("NewBatchRound() should fail with observers")
+	}
+
+	// The shareholders bind the observers to their proofs, which an observer declaring others rejects
+	r, _, err := NewObserverRound(observerID, partyIDs, 1, WithObservers(party.IDSlice{observerID, 10}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	round := r.(*observerRound0)
+	shareholder, _, _ := NewRound(1, partyIDs, 1, observers)
+	msgs, _ := shareholder.GenerateMessages()
+	if err := (&observerRound1{round}).ProcessMessage(msgs[0]); err == nil || err.Kind() != state.KindInvalidProof {
+		t.Errorf("error = %v, want an invalid proof", err)
+	}
+}
@@ -1,8 +1,38 @@
 package keygen
 
-import "github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+import (
+	"errors"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
 
 type Output struct {
 	Public    *eddsa.Public
 	SecretKey *eddsa.SecretShare
+
+	// Commitments contains the commitments received in the first round, from which Public can be recomputed.
+	// It is only set with WithCommitments, and can be stored with its MarshalBinary method.
+	Commitments *eddsa.Commitments
+
+	// Excluded contains the sorted IDs of the parties excluded from a keygen with WithRobust,
+	// which are not part of Public.
+	Excluded party.IDSlice
+}
+
+// WithCommitments returns an Option which sets the Commitments of the Output, so that the public shares
+// can be audited later with eddsa.Public.VerifyCommitments.
+// Unlike the other options, it does not change the messages, and parties may use it independently.
+func WithCommitments() Option {
+	return func(round *round0) {
+		round.ExportCommitments = true
+	}
+}
+
+// KeyShare returns a copy of the output as an eddsa.KeyShare, once the protocol has finished.
+func (o *Output) KeyShare() (*eddsa.KeyShare, error) {
+	if o.Public == nil || o.SecretKey == nil {
+		return nil, errors.New("keygen.Output: the protocol has not finished")
+	}
+	return eddsa.NewKeyShare(o.SecretKey.Copy(), o.Public.Copy())
 }
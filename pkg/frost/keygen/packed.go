@@ -0,0 +1,14 @@
+package keygen
+
+// WithPackedShares returns an Option which makes the parties send the shares of the second round in a single
+// broadcast Packed message, instead of one KeyGen2 message per party.
+// Each party extracts its own share when the message is handled by the State.
+// This is useful with a broadcast-only transport, but it reveals the shares to every party unless
+// WithEncryptedShares is also used.
+//
+// Parties accept both forms of KeyGen2 messages, so the option only affects the messages we send.
+func WithPackedShares() Option {
+	return func(round *round0) {
+		round.Packed = true
+	}
+}
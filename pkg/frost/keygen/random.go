@@ -0,0 +1,14 @@
+package keygen
+
+import "io"
+
+// WithRandom returns an Option which makes the party read the secret values it samples from r instead of crypto/rand:
+// the coefficients of its polynomial, the nonce of its proof of knowledge, and its ephemeral encryption key.
+// It can be used to obtain entropy from an HSM, or to produce deterministic test vectors.
+// If r fails or returns too few bytes, the protocol aborts with an error whose culprit is 0.
+// Unlike the other options, it does not change the messages, and parties may use it independently.
+func WithRandom(r io.Reader) Option {
+	return func(round *round0) {
+		round.Rand = r
+	}
+}
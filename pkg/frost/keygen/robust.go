@@ -0,0 +1,172 @@
+package keygen
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// Without robustness, a single party which stops responding after the first round aborts the keygen with a timeout.
+// When the keygen is robust, the second round is processed when it times out, with the shares received until then,
+// and each party broadcasts a KeyGenComplaint message accusing the parties from which it did not receive a valid share.
+// The complaint round also tolerates missing messages, and every party then excludes
+//
+//   - the parties accused by at least one complaint;
+//   - the parties whose complaints were not received in time, since they dropped out as well.
+//
+// The commitments and shares of the remaining, qualified parties are summed as in the normal protocol,
+// so that the result is a keygen between the qualified parties only, which must be more than the threshold.
+//
+// The excluded parties depend on the local timers of each party, and on the complaints it received before they expired,
+// so the parties may disagree on them even when using the same timeout. A robust keygen therefore requires
+// WithConfirmation: the parties check that they computed the same public keys once the excluded parties are removed,
+// and abort in the confirmation round otherwise, instead of producing different keys.
+// Since a party can exclude any other one by accusing it, robustness only tolerates failures, not malicious parties.
+
+var (
+	// ErrExcluded is returned when other parties complained about us, so that we are not part of the qualified parties.
+	ErrExcluded = errors.New("excluded by the complaints of other parties")
+
+	// ErrTooManyExcluded is returned when too many parties were excluded to produce a key with the threshold.
+	ErrTooManyExcluded = errors.New("not enough qualified parties remain")
+)
+
+// WithRobust returns an Option which makes the keygen tolerate parties which do not send valid shares in the second round,
+// as long as more than threshold parties remain. The Output then only contains the qualified parties,
+// and the excluded ones are listed in Output.Excluded.
+//
+// Missing messages are only detected by the round timeout, which must therefore be positive.
+// The first round and the echo round of WithEchoRound still require the messages of all parties.
+// Since it changes the messages that are exchanged, all parties must use this option, or none.
+// It requires WithConfirmation, and cannot be combined with WithBlame.
+func WithRobust() Option {
+	return func(round *round0) {
+		round.Robust = true
+		round.Shares = make(map[party.ID]*ristretto.Scalar)
+		round.Excluded = make(map[party.ID]bool)
+		round.Complainers = make(map[party.ID]bool)
+	}
+}
+
+type roundComplaint struct {
+	*round2
+}
+
+// qualified returns the parties which were not excluded, which are all parties unless the keygen is robust.
+func (round *round0) qualified() party.IDSlice {
+	if len(round.Excluded) == 0 {
+		return round.PartyIDs()
+	}
+	qualified := make(party.IDSlice, 0, len(round.PartyIDs()))
+	for _, id := range round.PartyIDs() {
+		if !round.Excluded[id] {
+			qualified = append(qualified, id)
+		}
+	}
+	return qualified
+}
+
+// excluded returns the sorted IDs of the excluded parties.
+func (round *round0) excluded() party.IDSlice {
+	var excluded party.IDSlice
+	for _, id := range round.PartyIDs() {
+		if round.Excluded[id] {
+			excluded = append(excluded, id)
+		}
+	}
+	return excluded
+}
+
+// remaining returns the number of parties which are neither excluded nor in missing.
+func (round *round0) remaining(missing party.IDSlice) party.Size {
+	var n party.Size
+	for _, id := range round.PartyIDs() {
+		if !round.Excluded[id] && !missing.Contains(id) {
+			n++
+		}
+	}
+	return n
+}
+
+// AcceptMissing implements state.PartialRound. The shares of a robust keygen can be missing
+// if enough parties remain, since their senders are accused in the complaint round.
+func (round *round2) AcceptMissing(missing party.IDSlice) bool {
+	return round.Robust && round.remaining(missing) > round.Threshold
+}
+
+// accuse excludes the parties from which we did not receive a valid share, and returns the complaint accusing them.
+func (round *round2) accuse() *messages.Message {
+	var accused party.IDSlice
+	for _, id := range round.PartyIDs() {
+		if _, ok := round.Shares[id]; !ok && id != round.SelfID() {
+			round.Excluded[id] = true
+			accused = append(accused, id)
+		}
+	}
+	return messages.NewKeyGenComplaint(round.SelfID(), accused)
+}
+
+// AcceptMissing implements state.PartialRound. The parties whose complaints are missing are excluded,
+// if enough parties remain.
+func (round *roundComplaint) AcceptMissing(missing party.IDSlice) bool {
+	return round.remaining(missing) > round.Threshold
+}
+
+func (round *roundComplaint) ProcessMessage(msg *messages.Message) *state.Error {
+	from := msg.From
+	for _, id := range msg.KeyGenComplaint.Accused {
+		if id == from || !round.PartyIDs().Contains(id) {
+			return state.NewError(from, fmt.Errorf("keygen: complaint against party %d which cannot be accused", id))
+		}
+	}
+	for _, id := range msg.KeyGenComplaint.Accused {
+		round.Excluded[id] = true
+	}
+	round.Complainers[from] = true
+	return nil
+}
+
+func (round *roundComplaint) GenerateMessages() ([]*messages.Message, *state.Error) {
+	for _, id := range round.PartyIDs() {
+		if id != round.SelfID() && !round.Complainers[id] {
+			round.Excluded[id] = true
+		}
+	}
+	if round.Excluded[round.SelfID()] {
+		return nil, state.NewError(0, fmt.Errorf("keygen: %w", ErrExcluded))
+	}
+	qualified := round.qualified()
+	if qualified.N() <= round.Threshold {
+		return nil, state.NewError(0, fmt.Errorf("keygen: %w: parties %v were excluded with threshold %d",
+			ErrTooManyExcluded, round.excluded(), round.Threshold))
+	}
+
+	// The commitments and shares of the excluded parties are discarded
+	sum := round.Commitments[qualified[0]].Copy()
+	for _, id := range qualified[1:] {
+		_ = sum.Add(round.Commitments[id])
+	}
+	round.CommitmentsSum.Reset()
+	round.CommitmentsSum = sum
+	for _, id := range qualified {
+		if id != round.SelfID() {
+			round.Secret.Add(&round.Secret, round.Shares[id])
+		}
+	}
+	return round.finish()
+}
+
+func (round *roundComplaint) NextRound() state.Round {
+	if round.Confirm {
+		return &roundConfirm{round.round2}
+	}
+	return nil
+}
+
+func (round *roundComplaint) MessageType() messages.MessageType {
+	return messages.MessageTypeKeyGenComplaint
+}
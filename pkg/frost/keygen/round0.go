@@ -1,10 +1,13 @@
 package keygen
 
 import (
+	"fmt"
+
 	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
 	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
 	"github.com/taurusgroup/frost-ed25519/pkg/internal/zk"
 	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 	"github.com/taurusgroup/frost-ed25519/pkg/state"
 )
 
@@ -14,28 +17,54 @@ func (round *round0) ProcessMessage(*messages.Message) *state.Error {
 
 func (round *round0) GenerateMessages() ([]*messages.Message, *state.Error) {
 	// Sample a_i,0 which is the constant factor of the polynomial
-	scalar.SetScalarRandom(&round.Secret)
+	if _, err := scalar.SetScalarRandomFrom(&round.Secret, round.Rand); err != nil {
+		return nil, state.NewError(0, fmt.Errorf("keygen: failed to sample secret: %w", err))
+	}
 
 	// Sample the remaining coefficients, and obtain a polynomial
 	// of degree t.
-	round.Polynomial = polynomial.NewPolynomial(round.Threshold, &round.Secret)
+	var err error
+	if round.Polynomial, err = polynomial.NewPolynomialFrom(round.Threshold, &round.Secret, round.Rand); err != nil {
+		return nil, state.NewError(0, fmt.Errorf("keygen: failed to sample polynomial: %w", err))
+	}
 
 	// Generate all commitments [a_{i j}] B for j = 0, 1, ..., t
 	// CommitmentsSum holds the sum of all commitments, so we initialize it to our commitment
 	round.CommitmentsSum = polynomial.NewPolynomialExponent(round.Polynomial)
 
-	// TODO we can use custom contexts to prevent replay attacks
-	ctx := make([]byte, 32)
+	// The session ID, the group parameters and the context of the ceremony are bound to the proof,
+	// to prevent it from being replayed in another execution
+	ctx := round.proofContext()
 	public := round.CommitmentsSum.Constant()
 	// Generate proof of knowledge of a_i,0 = f(0)
-	proof := zk.NewSchnorrProof(round.SelfID(), public, ctx, &round.Secret)
+	proof, err := zk.NewSchnorrProofFrom(round.SelfID(), public, ctx, &round.Secret, round.Rand)
+	if err != nil {
+		return nil, state.NewError(0, fmt.Errorf("keygen: %w", err))
+	}
 
 	// We use the variable Secret to hold the sum of all shares received.
 	// Therefore, we can set it to the share we would send to our selves.
 	// Bonus, we overwrite the original secret which is no longer needed.
 	round.Secret.Set(round.Polynomial.Evaluate(round.SelfID().Scalar()))
 
-	msg := messages.NewKeyGen1(round.SelfID(), proof, round.CommitmentsSum)
+	// The message has its own copy, since CommitmentsSum is modified as soon as the other commitments are received,
+	// possibly before the message was sent
+	msg := messages.NewKeyGen1(round.SelfID(), proof, round.CommitmentsSum.Copy())
+
+	if round.retainCommitments() {
+		// CommitmentsSum is modified when we receive the other commitments
+		round.Commitments[round.SelfID()] = round.CommitmentsSum.Copy()
+	}
+
+	if round.Encrypted {
+		// Sample the ephemeral key to which the shares sent to us will be encrypted
+		if _, err = scalar.SetScalarRandomFrom(&round.EncryptionSecret, round.Rand); err != nil {
+			return nil, state.NewError(0, fmt.Errorf("keygen: failed to sample encryption key: %w", err))
+		}
+		encryptionKey := new(ristretto.Element).ScalarBaseMult(&round.EncryptionSecret)
+		round.EncryptionKeys[round.SelfID()] = encryptionKey
+		msg.KeyGen1.EncryptionKey = encryptionKey
+	}
 	return []*messages.Message{msg}, nil
 }
 
@@ -4,43 +4,92 @@ import (
 	"errors"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 	"github.com/taurusgroup/frost-ed25519/pkg/state"
 )
 
+var ErrValidateProof = errors.New("ZK Schnorr failed")
+
 func (round *round1) ProcessMessage(msg *messages.Message) *state.Error {
-	// TODO we can use custom contexts to prevent replay attacks
-	ctx := make([]byte, 32)
+	ctx := round.proofContext()
 	from := msg.From
 
 	public := msg.KeyGen1.Commitments.Constant()
 	if !msg.KeyGen1.Proof.Verify(from, public, ctx) {
-		return state.NewError(from, errors.New("ZK Schnorr failed"))
+		return state.NewErrorWithKind(from, state.KindInvalidProof, ErrValidateProof)
 	}
 
-	round.Commitments[from] = msg.KeyGen1.Commitments
+	if round.Encrypted {
+		// The presence of the key is checked by the State, using the limits given by MessageLimits
+		key := msg.KeyGen1.EncryptionKey
+		if key == nil || key.Equal(ristretto.NewIdentityElement()) == 1 {
+			return state.NewError(from, ErrInvalidEncryptionKey)
+		}
+		round.EncryptionKeys[from] = key
+	}
+
+	commitments := msg.KeyGen1.Commitments
+	// The share sent by this party in the next round only needs to be checked against a single point
+	round.ShareChecks[from] = commitments.Evaluate(round.SelfID().Scalar())
+	if round.retainCommitments() {
+		round.Commitments[from] = commitments
+	}
 
 	// Add the commitments to our own, so that we can interpolate the final polynomial
-	_ = round.CommitmentsSum.Add(msg.KeyGen1.Commitments)
+	_ = round.CommitmentsSum.Add(commitments)
 	return nil
 }
 
 func (round *round1) GenerateMessages() ([]*messages.Message, *state.Error) {
+	if round.Echo {
+		// The shares are only sent once all parties have confirmed they received the same commitments
+		round.EchoDigest = round.echoDigest()
+		return []*messages.Message{messages.NewKeyGenEcho(round.SelfID(), &round.EchoDigest)}, nil
+	}
+	return round.generateShares()
+}
+
+// generateShares returns the KeyGen2 messages containing the shares of our polynomial for the other parties.
+// The shares are evaluated one at a time in the same scalar, which is cleared afterwards,
+// so that only the messages hold a copy of them.
+func (round *round1) generateShares() ([]*messages.Message, *state.Error) {
 	msgsOut := make([]*messages.Message, 0, len(round.PartyIDs())-1)
+	var x, share ristretto.Scalar
+	defer share.Set(ristretto.NewScalar())
 	for _, id := range round.PartyIDs() {
 		if id == round.SelfID() {
 			continue
 		}
-		msgsOut = append(msgsOut, messages.NewKeyGen2(round.SelfID(), id, round.Polynomial.Evaluate(id.Scalar())))
+		round.Polynomial.EvaluateTo(&share, id.ScalarTo(&x))
+		if !round.Encrypted {
+			msgsOut = append(msgsOut, messages.NewKeyGen2(round.SelfID(), id, &share))
+			continue
+		}
+		sealed, err := round.sealShare(id, &share)
+		if err != nil {
+			return nil, state.NewError(0, err)
+		}
+		msgsOut = append(msgsOut, messages.NewKeyGen2Sealed(round.SelfID(), id, sealed))
 	}
 
 	// Now that we have received the commitment from every one,
 	// we no longer require the original polynomial, so we reset it
 	round.Polynomial.Reset()
 
+	if round.Packed {
+		packed, err := messages.NewPacked(msgsOut)
+		if err != nil {
+			return nil, state.NewError(0, err)
+		}
+		return []*messages.Message{packed}, nil
+	}
 	return msgsOut, nil
 }
 
 func (round *round1) NextRound() state.Round {
+	if round.Echo {
+		return &roundEcho{round}
+	}
 	return &round2{round}
 }
 
@@ -5,22 +5,50 @@ import (
 
 	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
 	"github.com/taurusgroup/frost-ed25519/pkg/messages"
 	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 	"github.com/taurusgroup/frost-ed25519/pkg/state"
 )
 
-func (round *round2) ProcessMessage(msg *messages.Message) *state.Error {
-	var computedShareExp ristretto.Element
-	computedShareExp.ScalarBaseMult(&msg.KeyGen2.Share)
+var ErrValidateShare = errors.New("VSS failed to validate")
 
+func (round *round2) ProcessMessage(msg *messages.Message) *state.Error {
 	id := msg.From
-	shareExp := round.Commitments[id].Evaluate(round.SelfID().Scalar())
 
-	if computedShareExp.Equal(shareExp) != 1 {
-		return state.NewError(id, errors.New("VSS failed to validate"))
+	share := &msg.KeyGen2.Share
+	if round.Encrypted {
+		var err error
+		if share, err = round.openShare(id, msg.KeyGen2.SealedShare); err != nil {
+			if round.Robust {
+				// The sender is accused in the complaint round
+				return nil
+			}
+			return state.NewErrorWithKind(id, state.KindDecryptionFailure, err)
+		}
+		defer share.Set(ristretto.NewScalar())
 	}
-	round.Secret.Add(&round.Secret, &msg.KeyGen2.Share)
+
+	var computedShareExp ristretto.Element
+	computedShareExp.ScalarBaseMult(share)
+
+	if computedShareExp.Equal(round.ShareChecks[id]) != 1 {
+		if round.Blame {
+			// The share is revealed in the blame round, so that the other parties can check our complaint
+			round.Complaints[id] = new(ristretto.Scalar).Set(share)
+			return nil
+		}
+		if round.Robust {
+			return nil
+		}
+		return state.NewErrorWithKind(id, state.KindVSSFailure, ErrValidateShare)
+	}
+	if round.Robust {
+		// The share is only added once we know whether the sender was excluded
+		round.Shares[id] = new(ristretto.Scalar).Set(share)
+		return nil
+	}
+	round.Secret.Add(&round.Secret, share)
 
 	// We can reset the share in the message now
 	msg.KeyGen2.Share.Set(ristretto.NewScalar())
@@ -29,21 +57,69 @@ func (round *round2) ProcessMessage(msg *messages.Message) *state.Error {
 }
 
 func (round *round2) GenerateMessages() ([]*messages.Message, *state.Error) {
-	shares := make(map[party.ID]*ristretto.Element, round.PartyIDs().N())
-	for _, id := range round.PartyIDs() {
-		shares[id] = round.CommitmentsSum.Evaluate(id.Scalar())
+	if round.Blame {
+		// The output is only set once all parties have confirmed that they received valid shares
+		return []*messages.Message{messages.NewKeyGenBlame(round.SelfID(), round.complaints())}, nil
+	}
+	if round.Robust {
+		// The output is only set once the excluded parties are known
+		return []*messages.Message{round.accuse()}, nil
 	}
-	round.Output.Public = &eddsa.Public{
-		PartyIDs:  round.BaseRound.PartyIDs().Copy(),
-		Threshold: round.Threshold,
+	return round.finish()
+}
+
+// public returns the public shares of the qualified parties and the group key, computed from the sum of the commitments.
+func (round *round2) public() *eddsa.Public {
+	return computePublic(round.qualified(), round.Threshold, round.CommitmentsSum)
+}
+
+// computePublic returns the public shares of partyIDs and the group key, computed from the sum of their commitments.
+func computePublic(partyIDs party.IDSlice, threshold party.Size, sum *polynomial.Exponent) *eddsa.Public {
+	shares := make(map[party.ID]*ristretto.Element, partyIDs.N())
+	scalars := partyIDs.Scalars()
+	for i, id := range partyIDs {
+		shares[id] = sum.Evaluate(&scalars[i])
+	}
+	return &eddsa.Public{
+		PartyIDs:  partyIDs.Copy(),
+		Threshold: threshold,
 		Shares:    shares,
-		GroupKey:  eddsa.NewPublicKeyFromPoint(round.CommitmentsSum.Constant()),
+		GroupKey:  eddsa.NewPublicKeyFromPoint(sum.Constant()),
 	}
+}
+
+// setOutput sets the public shares and our secret share in the Output.
+func (round *round2) setOutput() {
+	round.Output.Public = round.public()
 	round.Output.SecretKey = eddsa.NewSecretShare(round.SelfID(), &round.Secret)
-	return nil, nil
+	round.Output.SecretKey.SetGroup(round.Output.Public.GroupKey)
+	round.Output.Excluded = round.excluded()
+
+	if round.ExportCommitments {
+		// The commitments of the round are reset when the protocol finishes
+		qualified := round.qualified()
+		parties := make(map[party.ID]*polynomial.Exponent, len(qualified))
+		for _, id := range qualified {
+			parties[id] = round.Commitments[id].Copy()
+		}
+		round.Output.Commitments = &eddsa.Commitments{
+			Threshold: round.Threshold,
+			Parties:   parties,
+			Sum:       round.CommitmentsSum.Copy(),
+		}
+	}
 }
 
 func (round *round2) NextRound() state.Round {
+	if round.Blame {
+		return &roundBlame{round}
+	}
+	if round.Robust {
+		return &roundComplaint{round}
+	}
+	if round.Confirm {
+		return &roundConfirm{round}
+	}
 	return nil
 }
 
@@ -0,0 +1,496 @@
+package keygen
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+var errSnapshotShort = errors.New("keygen: snapshot data is too short")
+
+// Flags of the options in a snapshot
+const (
+	snapshotEncrypted byte = 1 << iota
+	snapshotEcho
+	snapshotPacked
+	snapshotBlame
+	snapshotCommitments
+	snapshotContext
+	snapshotConfirm
+	snapshotRobust
+)
+
+// Snapshot implements state.Snapshotter.
+//
+// The result contains the secret polynomial as well as the sum of the shares received so far,
+// and must therefore be stored securely.
+//
+// The encoding is the following:
+//
+//	selfID ∥ threshold ∥ n ∥ partyIDs ∥ secret ∥ polynomial ∥ commitmentsSum ∥ len(commitments) ∥ (id ∥ commitment)...
+//
+// where polynomial and commitmentsSum are prefixed by their length (0 if absent),
+// and the commitments are sorted by ID.
+// If an Option was given, it is followed by a byte containing the flags snapshotEncrypted, snapshotEcho, snapshotPacked,
+// snapshotBlame, snapshotCommitments, snapshotContext, snapshotConfirm and snapshotRobust.
+// With encrypted shares, this byte is followed by
+//
+//	encryptionSecret ∥ len(encryptionKeys) ∥ (id ∥ encryptionKey)...
+//
+// where the keys are also sorted by ID.
+// With a blame round, the invalid shares we received are then appended as
+//
+//	len(complaints) ∥ (id ∥ share)...
+//
+// sorted by the ID of their sender.
+// With a context, it is then appended prefixed by its length.
+// With a robust keygen, the valid shares we received and the excluded parties are then appended as
+//
+//	len(shares) ∥ (id ∥ share)... ∥ len(excluded) ∥ excluded...
+//
+// sorted by ID.
+// Finally, the points against which the shares of the other parties are checked are appended as
+//
+//	len(shareChecks) ∥ (id ∥ shareCheck)...
+//
+// sorted by ID, in which case the options byte is always present.
+// Older snapshots without them are restored by computing them from the commitments.
+func (round *round0) Snapshot() ([]byte, error) {
+	var err error
+	partyIDs := round.PartyIDs()
+
+	data := make([]byte, 0, (3+len(partyIDs))*party.IDByteSize+32)
+	data = append(data, round.SelfID().Bytes()...)
+	data = append(data, round.Threshold.Bytes()...)
+	data = append(data, partyIDs.N().Bytes()...)
+	for _, id := range partyIDs {
+		data = append(data, id.Bytes()...)
+	}
+	data = append(data, round.Secret.Bytes()...)
+
+	if round.Polynomial != nil {
+		var polyData []byte
+		if polyData, err = round.Polynomial.MarshalBinary(); err != nil {
+			return nil, err
+		}
+		data = appendWithLength(data, polyData)
+	} else {
+		data = appendWithLength(data, nil)
+	}
+
+	if round.CommitmentsSum != nil {
+		var sumData []byte
+		if sumData, err = round.CommitmentsSum.MarshalBinary(); err != nil {
+			return nil, err
+		}
+		data = appendWithLength(data, sumData)
+	} else {
+		data = appendWithLength(data, nil)
+	}
+
+	data = append(data, party.Size(len(round.Commitments)).Bytes()...)
+	for _, id := range partyIDs {
+		commitments, ok := round.Commitments[id]
+		if !ok {
+			continue
+		}
+		var commitmentsData []byte
+		if commitmentsData, err = commitments.MarshalBinary(); err != nil {
+			return nil, err
+		}
+		data = append(data, id.Bytes()...)
+		data = appendWithLength(data, commitmentsData)
+	}
+
+	var options byte
+	if round.Encrypted {
+		options |= snapshotEncrypted
+	}
+	if round.Echo {
+		options |= snapshotEcho
+	}
+	if round.Packed {
+		options |= snapshotPacked
+	}
+	if round.Blame {
+		options |= snapshotBlame
+	}
+	if round.ExportCommitments {
+		options |= snapshotCommitments
+	}
+	if len(round.Context) > 0 {
+		options |= snapshotContext
+	}
+	if round.Confirm {
+		options |= snapshotConfirm
+	}
+	if round.Robust {
+		options |= snapshotRobust
+	}
+	if options != 0 || len(round.ShareChecks) > 0 {
+		data = append(data, options)
+	}
+	if round.Encrypted {
+		data = append(data, round.EncryptionSecret.Bytes()...)
+		data = append(data, party.Size(len(round.EncryptionKeys)).Bytes()...)
+		for _, id := range partyIDs {
+			if key, ok := round.EncryptionKeys[id]; ok {
+				data = append(data, id.Bytes()...)
+				data = append(data, key.Bytes()...)
+			}
+		}
+	}
+	if round.Blame {
+		data = append(data, party.Size(len(round.Complaints)).Bytes()...)
+		for _, id := range partyIDs {
+			if share, ok := round.Complaints[id]; ok {
+				data = append(data, id.Bytes()...)
+				data = append(data, share.Bytes()...)
+			}
+		}
+	}
+	if len(round.Context) > 0 {
+		data = appendWithLength(data, round.Context)
+	}
+	if round.Robust {
+		data = append(data, party.Size(len(round.Shares)).Bytes()...)
+		for _, id := range partyIDs {
+			if share, ok := round.Shares[id]; ok {
+				data = append(data, id.Bytes()...)
+				data = append(data, share.Bytes()...)
+			}
+		}
+		excluded := round.excluded()
+		data = append(data, excluded.N().Bytes()...)
+		for _, id := range excluded {
+			data = append(data, id.Bytes()...)
+		}
+	}
+	if len(round.ShareChecks) > 0 {
+		data = append(data, party.Size(len(round.ShareChecks)).Bytes()...)
+		for _, id := range partyIDs {
+			if check, ok := round.ShareChecks[id]; ok {
+				data = append(data, id.Bytes()...)
+				data = append(data, check.Bytes()...)
+			}
+		}
+	}
+	return data, nil
+}
+
+// RestoreRound returns the round with number roundNumber, from the data produced by Snapshot.
+// The returned Output will be filled in once the protocol has finished.
+func RestoreRound(roundNumber int, data []byte) (state.Round, *Output, error) {
+	var (
+		selfID, threshold, n party.ID
+		err                  error
+	)
+	if len(data) < 3*party.IDByteSize {
+		return nil, nil, errSnapshotShort
+	}
+	selfID, _ = party.FromBytes(data)
+	threshold, _ = party.FromBytes(data[party.IDByteSize:])
+	n, _ = party.FromBytes(data[2*party.IDByteSize:])
+	data = data[3*party.IDByteSize:]
+
+	if len(data) < int(n)*party.IDByteSize+32 {
+		return nil, nil, errSnapshotShort
+	}
+	ids := make([]party.ID, n)
+	for i := range ids {
+		ids[i], _ = party.FromBytes(data)
+		data = data[party.IDByteSize:]
+	}
+
+	r0, output, err := NewRound(selfID, party.NewIDSlice(ids), threshold)
+	if err != nil {
+		return nil, nil, fmt.Errorf("keygen.RestoreRound: %w", err)
+	}
+	round := r0.(*round0)
+
+	if _, err = round.Secret.SetCanonicalBytes(data[:32]); err != nil {
+		return nil, nil, fmt.Errorf("keygen.RestoreRound: secret: %w", err)
+	}
+	data = data[32:]
+
+	var polyData, sumData []byte
+	if polyData, data, err = readWithLength(data); err != nil {
+		return nil, nil, err
+	}
+	if len(polyData) > 0 {
+		round.Polynomial = &polynomial.Polynomial{}
+		if err = round.Polynomial.UnmarshalBinary(polyData); err != nil {
+			return nil, nil, fmt.Errorf("keygen.RestoreRound: polynomial: %w", err)
+		}
+	}
+	if sumData, data, err = readWithLength(data); err != nil {
+		return nil, nil, err
+	}
+	if len(sumData) > 0 {
+		round.CommitmentsSum = &polynomial.Exponent{}
+		if err = round.CommitmentsSum.UnmarshalBinary(sumData); err != nil {
+			return nil, nil, fmt.Errorf("keygen.RestoreRound: commitments sum: %w", err)
+		}
+	}
+
+	if len(data) < party.IDByteSize {
+		return nil, nil, errSnapshotShort
+	}
+	count, _ := party.FromBytes(data)
+	data = data[party.IDByteSize:]
+	for i := party.Size(0); i < count; i++ {
+		if len(data) < party.IDByteSize {
+			return nil, nil, errSnapshotShort
+		}
+		id, _ := party.FromBytes(data)
+		data = data[party.IDByteSize:]
+		if !round.PartyIDs().Contains(id) {
+			return nil, nil, fmt.Errorf("keygen.RestoreRound: commitments of party %d which is not a participant", id)
+		}
+
+		var commitmentsData []byte
+		if commitmentsData, data, err = readWithLength(data); err != nil {
+			return nil, nil, err
+		}
+		var commitments polynomial.Exponent
+		if err = commitments.UnmarshalBinary(commitmentsData); err != nil {
+			return nil, nil, fmt.Errorf("keygen.RestoreRound: commitments of party %d: %w", id, err)
+		}
+		round.Commitments[id] = &commitments
+	}
+
+	if len(data) != 0 {
+		options := data[0]
+		data = data[1:]
+		if options&^(snapshotEncrypted|snapshotEcho|snapshotPacked|snapshotBlame|snapshotCommitments|snapshotContext|snapshotConfirm|snapshotRobust) != 0 {
+			return nil, nil, fmt.Errorf("keygen.RestoreRound: unknown options %#x", options)
+		}
+		if options&snapshotEncrypted != 0 {
+			if data, err = restoreEncryption(round, data); err != nil {
+				return nil, nil, err
+			}
+		}
+		round.Echo = options&snapshotEcho != 0
+		round.Packed = options&snapshotPacked != 0
+		round.ExportCommitments = options&snapshotCommitments != 0
+		round.Confirm = options&snapshotConfirm != 0
+		if options&snapshotBlame != 0 {
+			if data, err = restoreComplaints(round, data); err != nil {
+				return nil, nil, err
+			}
+		}
+		if options&snapshotContext != 0 {
+			var context []byte
+			if context, data, err = readWithLength(data); err != nil {
+				return nil, nil, err
+			}
+			round.Context = append([]byte(nil), context...)
+		}
+		if options&snapshotRobust != 0 {
+			if data, err = restoreRobust(round, data); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if len(data) != 0 {
+		if data, err = restoreShareChecks(round, data); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		// Older snapshots always contain the commitments of the other parties
+		var x ristretto.Scalar
+		round.SelfID().ScalarTo(&x)
+		for id, commitments := range round.Commitments {
+			if id != round.SelfID() {
+				round.ShareChecks[id] = commitments.Evaluate(&x)
+			}
+		}
+	}
+	if len(data) != 0 {
+		return nil, nil, errors.New("keygen.RestoreRound: unexpected trailing data")
+	}
+
+	// The blame round comes after the second round, which is delayed by the echo round
+	blameRound := 3
+	if round.Echo {
+		blameRound = 4
+	}
+	// The complaint round of a robust keygen replaces the blame round, and the confirmation round is the last one
+	confirmRound := blameRound
+	if round.Blame || round.Robust {
+		confirmRound++
+	}
+	switch {
+	case roundNumber == blameRound && round.Blame:
+		return &roundBlame{&round2{&round1{round}}}, output, nil
+	case roundNumber == blameRound && round.Robust:
+		return &roundComplaint{&round2{&round1{round}}}, output, nil
+	case roundNumber == confirmRound && round.Confirm:
+		r2 := &round2{&round1{round}}
+		// The digest is not part of the snapshot, since it is determined by the commitments
+		round.ConfirmDigest = r2.confirmDigest()
+		return &roundConfirm{r2}, output, nil
+	case roundNumber == 0:
+		return round, output, nil
+	case roundNumber == 1:
+		return &round1{round}, output, nil
+	case roundNumber == 2 && round.Echo:
+		// The digest is not part of the snapshot, since it is determined by the commitments
+		round.EchoDigest = round.echoDigest()
+		return &roundEcho{&round1{round}}, output, nil
+	case roundNumber == 2 || roundNumber == 3 && round.Echo:
+		return &round2{&round1{round}}, output, nil
+	default:
+		return nil, nil, fmt.Errorf("keygen.RestoreRound: invalid round number %d", roundNumber)
+	}
+}
+
+// restoreEncryption restores the ephemeral keys of a keygen with encrypted shares, and returns the remaining data.
+func restoreEncryption(round *round0, data []byte) ([]byte, error) {
+	if len(data) < 32+party.IDByteSize {
+		return nil, errSnapshotShort
+	}
+	round.Encrypted = true
+	round.EncryptionKeys = make(map[party.ID]*ristretto.Element, round.PartyIDs().N())
+	if _, err := round.EncryptionSecret.SetCanonicalBytes(data[:32]); err != nil {
+		return nil, fmt.Errorf("keygen.RestoreRound: encryption secret: %w", err)
+	}
+	count, _ := party.FromBytes(data[32:])
+	data = data[32+party.IDByteSize:]
+	for i := party.Size(0); i < count; i++ {
+		if len(data) < party.IDByteSize+32 {
+			return nil, errSnapshotShort
+		}
+		id, _ := party.FromBytes(data)
+		if !round.PartyIDs().Contains(id) {
+			return nil, fmt.Errorf("keygen.RestoreRound: encryption key of party %d which is not a participant", id)
+		}
+		var key ristretto.Element
+		if _, err := key.SetCanonicalBytes(data[party.IDByteSize : party.IDByteSize+32]); err != nil {
+			return nil, fmt.Errorf("keygen.RestoreRound: encryption key of party %d: %w", id, err)
+		}
+		round.EncryptionKeys[id] = &key
+		data = data[party.IDByteSize+32:]
+	}
+	return data, nil
+}
+
+// restoreRobust restores the shares and excluded parties of a robust keygen, and returns the remaining data.
+func restoreRobust(round *round0, data []byte) ([]byte, error) {
+	if len(data) < party.IDByteSize {
+		return nil, errSnapshotShort
+	}
+	WithRobust()(round)
+	count, _ := party.FromBytes(data)
+	data = data[party.IDByteSize:]
+	for i := party.Size(0); i < count; i++ {
+		if len(data) < party.IDByteSize+32 {
+			return nil, errSnapshotShort
+		}
+		id, _ := party.FromBytes(data)
+		if !round.PartyIDs().Contains(id) || id == round.SelfID() {
+			return nil, fmt.Errorf("keygen.RestoreRound: share of party %d which is not another participant", id)
+		}
+		var share ristretto.Scalar
+		if _, err := share.SetCanonicalBytes(data[party.IDByteSize : party.IDByteSize+32]); err != nil {
+			return nil, fmt.Errorf("keygen.RestoreRound: share of party %d: %w", id, err)
+		}
+		round.Shares[id] = &share
+		data = data[party.IDByteSize+32:]
+	}
+
+	if len(data) < party.IDByteSize {
+		return nil, errSnapshotShort
+	}
+	count, _ = party.FromBytes(data)
+	data = data[party.IDByteSize:]
+	for i := party.Size(0); i < count; i++ {
+		if len(data) < party.IDByteSize {
+			return nil, errSnapshotShort
+		}
+		id, _ := party.FromBytes(data)
+		if !round.PartyIDs().Contains(id) {
+			return nil, fmt.Errorf("keygen.RestoreRound: excluded party %d which is not a participant", id)
+		}
+		round.Excluded[id] = true
+		data = data[party.IDByteSize:]
+	}
+	return data, nil
+}
+
+// restoreShareChecks restores the points against which the shares of the other parties are checked,
+// and returns the remaining data.
+func restoreShareChecks(round *round0, data []byte) ([]byte, error) {
+	if len(data) < party.IDByteSize {
+		return nil, errSnapshotShort
+	}
+	count, _ := party.FromBytes(data)
+	data = data[party.IDByteSize:]
+	for i := party.Size(0); i < count; i++ {
+		if len(data) < party.IDByteSize+32 {
+			return nil, errSnapshotShort
+		}
+		id, _ := party.FromBytes(data)
+		if !round.PartyIDs().Contains(id) || id == round.SelfID() {
+			return nil, fmt.Errorf("keygen.RestoreRound: share check of party %d which is not another participant", id)
+		}
+		var check ristretto.Element
+		if _, err := check.SetCanonicalBytes(data[party.IDByteSize : party.IDByteSize+32]); err != nil {
+			return nil, fmt.Errorf("keygen.RestoreRound: share check of party %d: %w", id, err)
+		}
+		round.ShareChecks[id] = &check
+		data = data[party.IDByteSize+32:]
+	}
+	return data, nil
+}
+
+// restoreComplaints restores the invalid shares received in a keygen with a blame round, and returns the remaining data.
+func restoreComplaints(round *round0, data []byte) ([]byte, error) {
+	if len(data) < party.IDByteSize {
+		return nil, errSnapshotShort
+	}
+	round.Blame = true
+	round.Complaints = make(map[party.ID]*ristretto.Scalar)
+	count, _ := party.FromBytes(data)
+	data = data[party.IDByteSize:]
+	for i := party.Size(0); i < count; i++ {
+		if len(data) < party.IDByteSize+32 {
+			return nil, errSnapshotShort
+		}
+		id, _ := party.FromBytes(data)
+		if !round.PartyIDs().Contains(id) || id == round.SelfID() {
+			return nil, fmt.Errorf("keygen.RestoreRound: complaint against party %d which is not another participant", id)
+		}
+		var share ristretto.Scalar
+		if _, err := share.SetCanonicalBytes(data[party.IDByteSize : party.IDByteSize+32]); err != nil {
+			return nil, fmt.Errorf("keygen.RestoreRound: complaint against party %d: %w", id, err)
+		}
+		round.Complaints[id] = &share
+		data = data[party.IDByteSize+32:]
+	}
+	return data, nil
+}
+
+func appendWithLength(data, b []byte) []byte {
+	var l [4]byte
+	binary.BigEndian.PutUint32(l[:], uint32(len(b)))
+	data = append(data, l[:]...)
+	return append(data, b...)
+}
+
+func readWithLength(data []byte) ([]byte, []byte, error) {
+	if len(data) < 4 {
+		return nil, nil, errSnapshotShort
+	}
+	l := binary.BigEndian.Uint32(data)
+	data = data[4:]
+	if uint64(l) > uint64(len(data)) {
+		return nil, nil, errSnapshotShort
+	}
+	return data[:l], data[l:], nil
+}
@@ -0,0 +1,189 @@
+package party
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrIDCollision is wrapped by a *CollisionError.
+var ErrIDCollision = errors.New("names are assigned the same ID")
+
+// CollisionError is returned when building a Directory in which two names would have the same ID.
+// It can be resolved by giving an explicit ID to one of the names.
+type CollisionError struct {
+	// ID is the ID assigned to both names.
+	ID ID
+	// Names are the two names, in increasing order.
+	Names [2]string
+}
+
+// Error implements error
+func (e *CollisionError) Error() string {
+	return fmt.Sprintf("party.Directory: %q and %q %s %d", e.Names[0], e.Names[1], ErrIDCollision.Error(), e.ID)
+}
+
+// Unwrap returns ErrIDCollision.
+func (e *CollisionError) Unwrap() error {
+	return ErrIDCollision
+}
+
+// IDFromName returns the ID derived from name, which is given by the first IDByteSize bytes of SHA-256(name)
+// interpreted as a big-endian integer.
+// The result may be 0, in which case the name requires an explicit ID in a Directory.
+func IDFromName(name string) ID {
+	digest := sha256.Sum256([]byte(name))
+	return ID(binary.BigEndian.Uint32(digest[:IDByteSize]))
+}
+
+// Directory is a bijection between display names and party IDs.
+// It is only used to present IDs to humans, the protocols always use IDs.
+//
+// A Directory is immutable once created, and is safe for concurrent use.
+type Directory struct {
+	ids   map[string]ID
+	names map[ID]string
+}
+
+// NewDirectory returns a Directory containing names.
+// A name is assigned the ID given in overrides if it exists, and IDFromName(name) otherwise.
+// Names in overrides which are not in names are also added.
+//
+// An error is returned if a name is empty, if a name would be assigned the ID 0,
+// or if two names would be assigned the same ID, in which case the error is a *CollisionError.
+// Since the result only depends on the arguments, all parties obtain the same Directory from the same configuration.
+func NewDirectory(names []string, overrides map[string]ID) (*Directory, error) {
+	ids := make(map[string]ID, len(names)+len(overrides))
+	for _, name := range names {
+		ids[name] = IDFromName(name)
+	}
+	for name, id := range overrides {
+		ids[name] = id
+	}
+	return newDirectory(ids)
+}
+
+// newDirectory checks that ids is a bijection between non-empty names and valid IDs.
+func newDirectory(ids map[string]ID) (*Directory, error) {
+	// Sort the names so that the error does not depend on the iteration order of the map
+	sorted := make([]string, 0, len(ids))
+	for name := range ids {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	d := &Directory{
+		ids:   ids,
+		names: make(map[ID]string, len(ids)),
+	}
+	for _, name := range sorted {
+		id := ids[name]
+		if name == "" {
+			return nil, errors.New("party.Directory: name is empty")
+		}
+		if id == 0 {
+			return nil, fmt.Errorf("party.Directory: name %q: %w", name, ErrZeroID)
+		}
+		if other, ok := d.names[id]; ok {
+			return nil, &CollisionError{ID: id, Names: [2]string{other, name}}
+		}
+		d.names[id] = name
+	}
+	return d, nil
+}
+
+// ID returns the ID assigned to name, and false if name is not in the Directory.
+func (d *Directory) ID(name string) (ID, bool) {
+	if d == nil {
+		return 0, false
+	}
+	id, ok := d.ids[name]
+	return id, ok
+}
+
+// Name returns the name assigned to id, and false if id is not in the Directory.
+func (d *Directory) Name(id ID) (string, bool) {
+	if d == nil {
+		return "", false
+	}
+	name, ok := d.names[id]
+	return name, ok
+}
+
+// IDs returns the sorted IDs of all names in the Directory.
+func (d *Directory) IDs() IDSlice {
+	if d == nil {
+		return nil
+	}
+	ids := make([]ID, 0, len(d.names))
+	for id := range d.names {
+		ids = append(ids, id)
+	}
+	return NewIDSlice(ids)
+}
+
+// Len returns the number of names in the Directory.
+func (d *Directory) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.ids)
+}
+
+// Format returns "name (id)" if id is in the Directory, and the base 10 representation of id otherwise.
+// A nil Directory is empty.
+func (d *Directory) Format(id ID) string {
+	if name, ok := d.Name(id); ok {
+		return fmt.Sprintf("%s (%d)", name, id)
+	}
+	return id.String()
+}
+
+// FormatIDs returns ids formatted with Format, in the same style as fmt.Sprint(ids).
+func (d *Directory) FormatIDs(ids IDSlice) string {
+	formatted := make([]string, len(ids))
+	for i, id := range ids {
+		formatted[i] = d.Format(id)
+	}
+	return "[" + strings.Join(formatted, " ") + "]"
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// The Directory is encoded as an object mapping names to IDs, with the names in increasing order.
+func (d *Directory) MarshalJSON() ([]byte, error) {
+	out := make(map[string]uint32, len(d.ids))
+	for name, id := range d.ids {
+		out[name] = uint32(id)
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The IDs are used as given, and must be valid and distinct.
+func (d *Directory) UnmarshalJSON(data []byte) error {
+	var out map[string]json.Number
+	if err := json.Unmarshal(data, &out); err != nil {
+		return fmt.Errorf("party.Directory: %w", err)
+	}
+	if out == nil {
+		return errors.New("party.Directory: expected an object")
+	}
+	ids := make(map[string]ID, len(out))
+	for name, n := range out {
+		var id ID
+		if err := id.UnmarshalText([]byte(n)); err != nil {
+			return fmt.Errorf("party.Directory: name %q: %w", name, err)
+		}
+		ids[name] = id
+	}
+	decoded, err := newDirectory(ids)
+	if err != nil {
+		return err
+	}
+	*d = *decoded
+	return nil
+}
@@ -0,0 +1,148 @@
+package party
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestIDFromName(t *testing.T) {
+	// The IDs are part of the configuration shared by all parties, so they must never change.
+	for name, want := range map[string]ID{
+		"alice": 735577801,
+		"bob":   2176202712,
+	} {
+		if got := IDFromName(name); got != want {
+			t.Errorf("IDFromName(%q) = %d, want %d", name, got, want)
+		}
+	}
+}
+
+func TestDirectory(t *testing.T) {
+	d, err := NewDirectory([]string{"bob", "alice", "treasury-hsm-2"}, map[string]ID{"treasury-hsm-2": 7, "carol": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Len() != 4 {
+		t.Errorf("Len() = %d, want 4", d.Len())
+	}
+	for name, want := range map[string]ID{"alice": 735577801, "bob": 2176202712, "treasury-hsm-2": 7, "carol": 3} {
+		id, ok := d.ID(name)
+		if !ok || id != want {
+			t.Errorf("ID(%q) = %d, %v, want %d", name, id, ok, want)
+		}
+		if got, ok := d.Name(want); !ok || got != name {
+			t.Errorf("Name(%d) = %q, %v, want %q", want, got, ok, name)
+		}
+	}
+	if _, ok := d.ID("dave"); ok {
+		t.Error("ID() of an unknown name should fail")
+	}
+	if _, ok := d.Name(4); ok {
+		t.Error("Name() of an unknown ID should fail")
+	}
+	if !d.IDs().Equal(IDSlice{3, 7, 735577801, 2176202712}) {
+		t.Errorf("IDs() = %v", d.IDs())
+	}
+
+	if got := d.Format(7); got != "treasury-hsm-2 (7)" {
+		t.Errorf("Format(7) = %q", got)
+	}
+	if got := d.Format(4); got != "4" {
+		t.Errorf("Format(4) = %q", got)
+	}
+	if got := d.FormatIDs(IDSlice{3, 4}); got != "[carol (3) 4]" {
+		t.Errorf("FormatIDs() = %q", got)
+	}
+
+	var nilDirectory *Directory
+	if got, want := nilDirectory.FormatIDs(IDSlice{3, 4}), "[3 4]"; got != want {
+		t.Errorf("FormatIDs() of a nil Directory = %q, want %q", got, want)
+	}
+	if _, ok := nilDirectory.ID("alice"); ok || nilDirectory.Len() != 0 || nilDirectory.IDs() != nil {
+		t.Error("a nil Directory should be empty")
+	}
+}
+
+func TestDirectory_Collisions(t *testing.T) {
+	// signer-68577 and signer-198210 have the same SHA-256 prefix.
+	colliding := []string{"signer-198210", "signer-68577"}
+	if IDFromName(colliding[0]) != IDFromName(colliding[1]) {
+		t.Fatal("the names should collide")
+	}
+
+	_, err := NewDirectory(colliding, nil)
+	var collisionErr *CollisionError
+	if !errors.As(err, &collisionErr) || !errors.Is(err, ErrIDCollision) {
+		t.Fatalf("NewDirectory() error = %v, want a *CollisionError", err)
+	}
+	if collisionErr.ID != 1075586909 || collisionErr.Names != [2]string{"signer-198210", "signer-68577"} {
+		t.Errorf("CollisionError = %+v", collisionErr)
+	}
+
+	// The collision is resolved by an override
+	d, err := NewDirectory(colliding, map[string]ID{"signer-68577": 68577})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := d.ID("signer-68577"); id != 68577 {
+		t.Errorf("ID() = %d, want the override", id)
+	}
+
+	// An override can collide with a derived ID, or with another override
+	if _, err = NewDirectory([]string{"alice"}, map[string]ID{"bob": 735577801}); !errors.Is(err, ErrIDCollision) {
+		t.Errorf("NewDirectory() error = %v, want ErrIDCollision", err)
+	}
+	if _, err = NewDirectory(nil, map[string]ID{"alice": 1, "bob": 1}); !errors.Is(err, ErrIDCollision) {
+		t.Errorf("NewDirectory() error = %v, want ErrIDCollision", err)
+	}
+
+	if _, err = NewDirectory(nil, map[string]ID{"alice": 0}); !errors.Is(err, ErrZeroID) {
+		t.Errorf("NewDirectory() error = %v, want ErrZeroID", err)
+	}
+	if _, err = NewDirectory([]string{""}, nil); err == nil {
+		t.Error("NewDirectory() should reject an empty name")
+	}
+}
+
+func TestDirectory_JSON(t *testing.T) {
+	d, err := NewDirectory([]string{"bob", "alice"}, map[string]ID{"carol": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"alice":735577801,"bob":2176202712,"carol":3}`; string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+
+	var decoded Directory
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.IDs().Equal(d.IDs()) {
+		t.Errorf("IDs() = %v, want %v", decoded.IDs(), d.IDs())
+	}
+	for _, id := range d.IDs() {
+		want, _ := d.Name(id)
+		if got, _ := decoded.Name(id); got != want {
+			t.Errorf("Name(%d) = %q, want %q", id, got, want)
+		}
+	}
+
+	for _, invalid := range []string{
+		`{"alice":1,"bob":1}`,
+		`{"alice":0}`,
+		`{"alice":4294967296}`,
+		`{"alice":-1}`,
+		`{"":1}`,
+		`[1]`,
+		`null`,
+	} {
+		if err = json.Unmarshal([]byte(invalid), new(Directory)); err == nil {
+			t.Errorf("json.Unmarshal(%s) should fail", invalid)
+		}
+	}
+}
@@ -11,17 +11,40 @@ import (
 	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 )
 
+var (
+	// ErrZeroID is wrapped by the error returned when decoding the ID 0, which is invalid.
+	ErrZeroID = errors.New("ID 0 is invalid")
+	// ErrIDOutOfRange is wrapped by the error returned when decoding an ID larger than MaxID.
+	ErrIDOutOfRange = errors.New("ID is out of range")
+)
+
+// ParseError is returned when a textual ID cannot be decoded.
+type ParseError struct {
+	// Text is the invalid text.
+	Text string
+	// Err is the reason why Text is invalid.
+	Err error
+}
+
+// Error implements error
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("party.ID: invalid ID %q: %v", e.Text, e.Err)
+}
+
+// Unwrap returns the reason why the text is invalid.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
 // IDByteSize is the number of bytes required to store and ID or Size
-const IDByteSize = 2
+const IDByteSize = 4
 
-// _MAX is the maximum integer that can represent a party.
-// It can be used to bound the number of parties, and the maximum integer value
-// an ID can be.
-const _MAX = uint64(math.MaxUint32)
+// MaxID is the largest valid ID.
+const MaxID ID = math.MaxUint32
 
-// ID represents the identifier of a particular party, encoded as a 16 bit unsigned integer.
+// ID represents the identifier of a particular party, encoded as a 32 bit unsigned integer.
 // The ID 0 is considered invalid.
-type ID uint16
+type ID uint32
 
 // Size is an alias for ID that allows us to differentiate between a party's ID and the threshold for example.
 type Size = ID
@@ -29,22 +52,26 @@ type Size = ID
 // Scalar returns the corresponding ristretto.Scalar
 func (id ID) Scalar() *ristretto.Scalar {
 	var s ristretto.Scalar
-	bytes := make([]byte, 32)
+	return id.ScalarTo(&s)
+}
 
-	binary.LittleEndian.PutUint16(bytes, uint16(id))
+// ScalarTo sets dst to the scalar corresponding to id, and returns dst.
+// Unlike Scalar, it does not allocate.
+func (id ID) ScalarTo(dst *ristretto.Scalar) *ristretto.Scalar {
+	var bytes [32]byte
 
-	_, err := s.SetCanonicalBytes(bytes[:])
-	if err != nil {
-		panic(fmt.Errorf("edwards25519: failed to set uint32 Scalar: %w", err))
-	}
-	return &s
+	binary.LittleEndian.PutUint32(bytes[:], uint32(id))
+
+	// An integer smaller than 2³² is always a canonical encoding, so this cannot fail for any ID.
+	_, _ = dst.SetCanonicalBytes(bytes[:])
+	return dst
 }
 
 // Bytes returns a []byte slice of length party.IDByteSize
 func (id ID) Bytes() []byte {
 	bytes := make([]byte, IDByteSize)
 
-	binary.BigEndian.PutUint16(bytes, uint16(id))
+	binary.BigEndian.PutUint32(bytes, uint32(id))
 	return bytes
 }
 
@@ -59,18 +86,19 @@ func FromBytes(b []byte) (ID, error) {
 	if len(b) < IDByteSize {
 		return 0, errors.New("party.FromBytes: b is not long enough to hold an ID")
 	}
-	id := ID(binary.BigEndian.Uint16(b))
+	id := ID(binary.BigEndian.Uint32(b))
 	return id, nil
 }
 
-// RandID returns a pseudo-random value as a ID
-// from the default Source.
+// randInt63n is the source of RandID. It is only replaced by tests.
+var randInt63n = rand.Int63n
+
+// RandID returns a pseudo-random non-zero ID from the default Source of math/rand.
+//
+// Deprecated: the IDs are predictable, and processes which seed math/rand identically return the same IDs.
+// Use RandomID or RandomSet instead.
 func RandID() ID {
-	id := rand.Int31n(math.MaxUint16 + 1)
-	if id == 0 {
-		return ID(id + 1)
-	}
-	return ID(id)
+	return ID(1 + randInt63n(int64(MaxID)))
 }
 
 // MarshalText implements encoding/TextMarshaler interface
@@ -79,14 +107,18 @@ func (id ID) MarshalText() (text []byte, err error) {
 }
 
 // UnmarshalText implements encoding/TextMarshaler interface
-// Returns an error when the encoded text is too large
+// Returns a *ParseError when the text is not the base 10 representation of a valid ID,
+// which wraps ErrZeroID if it is 0, and ErrIDOutOfRange if it is too large.
 func (id *ID) UnmarshalText(text []byte) error {
-	idUint, err := strconv.ParseUint(string(text), 10, 16)
-	if err != nil {
-		return fmt.Errorf("party.ID: UnmarshalText: %v", err)
+	idUint, err := strconv.ParseUint(string(text), 10, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return &ParseError{Text: string(text), Err: errors.New("not a base 10 integer")}
+	}
+	if err != nil || idUint > uint64(MaxID) {
+		return &ParseError{Text: string(text), Err: ErrIDOutOfRange}
 	}
-	if idUint > _MAX {
-		return errors.New("party.ID: UnmarshalText: party ID overflows")
+	if idUint == 0 {
+		return &ParseError{Text: string(text), Err: ErrZeroID}
 	}
 	*id = ID(idUint)
 	return nil
@@ -122,7 +154,7 @@ func (id ID) Lagrange(partyIDs IDSlice) (*ristretto.Scalar, error) {
 	num.Set(&one)
 	denum.Set(&one)
 
-	xJ = *id.Scalar()
+	id.ScalarTo(&xJ)
 
 	foundSelfInIDs := false
 	for _, partyID := range partyIDs {
@@ -131,7 +163,7 @@ func (id ID) Lagrange(partyIDs IDSlice) (*ristretto.Scalar, error) {
 			continue
 		}
 
-		xM = *partyID.Scalar()
+		partyID.ScalarTo(&xM)
 
 		// num = x₀ * ... * xₖ
 		num.Multiply(&num, &xM) // num * xM
@@ -1,6 +1,8 @@
 package party
 
 import (
+	"encoding/json"
+	"errors"
 	"reflect"
 	"testing"
 
@@ -20,31 +22,31 @@ func TestFromBytes(t *testing.T) {
 	}{
 		{
 			"1",
-			args{b: []byte{0, 1}},
+			args{b: []byte{0, 0, 0, 1}},
 			1,
 			false,
 		},
 		{
 			"max",
-			args{b: []byte{255, 255}},
-			65535,
+			args{b: []byte{255, 255, 255, 255}},
+			MaxID,
 			false,
 		},
 		{
 			"larger size",
-			args{b: []byte{0, 1, 0}},
+			args{b: []byte{0, 0, 0, 1, 0}},
 			1,
 			false,
 		},
 		{
 			"0",
-			args{b: []byte{0, 0, 1}},
+			args{b: []byte{0, 0, 0, 0, 1}},
 			0,
 			false,
 		},
 		{
-			"1 byte long",
-			args{b: []byte{1}},
+			"3 bytes long",
+			args{b: []byte{1, 0, 0}},
 			0,
 			true,
 		},
@@ -117,18 +119,18 @@ func TestID_UnmarshalText(t *testing.T) {
 			"0",
 			0,
 			args{text: []byte("0")},
-			false,
+			true,
 		},
 		{
 			"max",
-			65535,
-			args{text: []byte("65535")},
+			MaxID,
+			args{text: []byte("4294967295")},
 			false,
 		},
 		{
 			"max+1",
 			0,
-			args{text: []byte("65536")},
+			args{text: []byte("4294967296")},
 			true,
 		},
 		{
@@ -213,3 +215,79 @@ func TestID_Lagrange(t *testing.T) {
 		})
 	}
 }
+
+func TestID_UnmarshalText_Errors(t *testing.T) {
+	var id ID
+	var parseErr *ParseError
+	if err := id.UnmarshalText([]byte("0")); !errors.As(err, &parseErr) || !errors.Is(err, ErrZeroID) {
+		t.Errorf("UnmarshalText(0) error = %v, want ErrZeroID", err)
+	}
+	for _, text := range []string{"4294967296", "18446744073709551616"} {
+		if err := id.UnmarshalText([]byte(text)); !errors.As(err, &parseErr) || !errors.Is(err, ErrIDOutOfRange) {
+			t.Errorf("UnmarshalText(%s) error = %v, want ErrIDOutOfRange", text, err)
+		}
+	}
+	if err := id.UnmarshalText([]byte("-1")); !errors.As(err, &parseErr) || parseErr.Text != "-1" {
+		t.Errorf("UnmarshalText(-1) error = %v, want *ParseError", err)
+	}
+	if id != 0 {
+		t.Errorf("id was modified by invalid text: %v", id)
+	}
+}
+
+func TestID_JSONMapKey(t *testing.T) {
+	m := map[ID]string{1: "a", 65536: "b", MaxID: "c"}
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"1":"a","4294967295":"c","65536":"b"}` {
+		t.Errorf("json.Marshal() = %s", data)
+	}
+	var got map[ID]string
+	if err = json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, m) {
+		t.Errorf("json.Unmarshal() = %v, want %v", got, m)
+	}
+
+	for _, invalid := range []string{`{"0":"a"}`, `{"4294967296":"a"}`, `{"x":"a"}`} {
+		if err = json.Unmarshal([]byte(invalid), &got); !errors.As(err, new(*ParseError)) {
+			t.Errorf("json.Unmarshal(%s) error = %v, want *ParseError", invalid, err)
+		}
+	}
+}
+
+func TestID_ScalarTo(t *testing.T) {
+	for _, id := range []ID{1, 2, 1 << 16, MaxID} {
+		var s ristretto.Scalar
+		if got := id.ScalarTo(&s); got != &s {
+			t.Errorf("ScalarTo() should return dst")
+		}
+		if s.Equal(id.Scalar()) != 1 {
+			t.Errorf("ScalarTo(%d) != Scalar()", id)
+		}
+	}
+
+	var s ristretto.Scalar
+	id := ID(42)
+	if allocs := testing.AllocsPerRun(100, func() { id.ScalarTo(&s) }); allocs != 0 {
+		t.Errorf("ScalarTo() allocated %v times", allocs)
+	}
+}
+
+func BenchmarkID_Scalar(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = ID(i + 1).Scalar()
+	}
+}
+
+func BenchmarkID_ScalarTo(b *testing.B) {
+	b.ReportAllocs()
+	var s ristretto.Scalar
+	for i := 0; i < b.N; i++ {
+		ID(i + 1).ScalarTo(&s)
+	}
+}
@@ -1,7 +1,12 @@
 package party
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"sort"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 )
 
 // IDSlice is an alias for []ID
@@ -14,6 +19,40 @@ func NewIDSlice(partyIDs []ID) IDSlice {
 	return ids
 }
 
+// NewIDSliceRange returns the count consecutive IDs start, start+1, ..., start+count-1.
+// An error is returned if start is 0, count is 0, or the last ID would be larger than MaxID.
+func NewIDSliceRange(start ID, count Size) (IDSlice, error) {
+	if start == 0 {
+		return nil, fmt.Errorf("party.NewIDSliceRange: %w", ErrZeroID)
+	}
+	if count == 0 {
+		return nil, errors.New("party.NewIDSliceRange: count is 0")
+	}
+	if count-1 > MaxID-start {
+		return nil, fmt.Errorf("party.NewIDSliceRange: %d IDs starting at %d exceed MaxID", count, start)
+	}
+	ids := make(IDSlice, count)
+	for i := range ids {
+		ids[i] = start + ID(i)
+	}
+	return ids, nil
+}
+
+// MustIDSlice returns the sorted IDSlice containing ids, and panics if one of them is 0 or appears twice.
+// It is intended for tests and examples with fixed IDs.
+func MustIDSlice(ids ...ID) IDSlice {
+	result := NewIDSlice(ids)
+	for i, id := range result {
+		if id == 0 {
+			panic("party.MustIDSlice: " + ErrZeroID.Error())
+		}
+		if i > 0 && id == result[i-1] {
+			panic(fmt.Sprintf("party.MustIDSlice: duplicate ID %d", id))
+		}
+	}
+	return result
+}
+
 // Contains returns true if id is included in the slice.
 func (ids IDSlice) Contains(id ID) bool {
 	n := len(ids)
@@ -29,17 +68,29 @@ func (ids IDSlice) N() Size {
 	return Size(len(ids))
 }
 
-// IsSubsetOf is all elements in ids are in o
+// IsSubsetOf returns true if all elements of ids are in o.
+// ids and o must be sorted. A nil slice is the empty set, which is a subset of any set.
 func (ids IDSlice) IsSubsetOf(o IDSlice) bool {
+	j := 0
 	for _, id := range ids {
-		if !o.Contains(id) {
+		for j < len(o) && o[j] < id {
+			j++
+		}
+		if j == len(o) || o[j] != id {
 			return false
 		}
 	}
 	return true
 }
 
-// Equal returns true if ids == o
+// ContainsAll returns true if every ID given as argument is in ids, which must be sorted.
+// The arguments may be in any order.
+func (ids IDSlice) ContainsAll(others ...ID) bool {
+	return NewIDSlice(others).IsSubsetOf(ids)
+}
+
+// Equal returns true if ids == o.
+// A nil slice is equal to an empty one.
 func (ids IDSlice) Equal(o IDSlice) bool {
 	if len(ids) != len(o) {
 		return false
@@ -59,3 +110,331 @@ func (ids IDSlice) Copy() IDSlice {
 	copy(newIds, ids)
 	return newIds
 }
+
+// Add inserts id into the sorted slice ids, keeping it sorted.
+// An error is returned if id is 0 or is already in ids, in which case ids is unchanged.
+//
+// Add and Remove replace *ids by a new slice, and never modify the previous one.
+// A slice given to NewSignState or NewKeygenState therefore does not change when the application keeps updating its own copy.
+// They must not be called concurrently on the same *IDSlice.
+func (ids *IDSlice) Add(id ID) error {
+	if id == 0 {
+		return fmt.Errorf("party.IDSlice: Add: %w", ErrZeroID)
+	}
+	s := *ids
+	i := sort.Search(len(s), func(i int) bool { return s[i] >= id })
+	if i < len(s) && s[i] == id {
+		return fmt.Errorf("party.IDSlice: Add: %d is already in the set", id)
+	}
+	result := make(IDSlice, 0, len(s)+1)
+	result = append(result, s[:i]...)
+	result = append(result, id)
+	result = append(result, s[i:]...)
+	*ids = result
+	return nil
+}
+
+// Remove removes id from the sorted slice ids.
+// An error is returned if id is not in ids, in which case ids is unchanged.
+func (ids *IDSlice) Remove(id ID) error {
+	s := *ids
+	i := sort.Search(len(s), func(i int) bool { return s[i] >= id })
+	if i == len(s) || s[i] != id {
+		return fmt.Errorf("party.IDSlice: Remove: %d is not in the set", id)
+	}
+	result := make(IDSlice, 0, len(s)-1)
+	result = append(result, s[:i]...)
+	result = append(result, s[i+1:]...)
+	*ids = result
+	return nil
+}
+
+// Union returns a new sorted IDSlice containing the IDs which are in ids or in o.
+// ids and o must be sorted, and are not modified.
+func (ids IDSlice) Union(o IDSlice) IDSlice {
+	result := make(IDSlice, 0, len(ids)+len(o))
+	i, j := 0, 0
+	for i < len(ids) && j < len(o) {
+		switch {
+		case ids[i] < o[j]:
+			result = append(result, ids[i])
+			i++
+		case ids[i] > o[j]:
+			result = append(result, o[j])
+			j++
+		default:
+			result = append(result, ids[i])
+			i++
+			j++
+		}
+	}
+	result = append(result, ids[i:]...)
+	result = append(result, o[j:]...)
+	return result
+}
+
+// Intersect returns a new sorted IDSlice containing the IDs which are both in ids and in o.
+// ids and o must be sorted, and are not modified.
+func (ids IDSlice) Intersect(o IDSlice) IDSlice {
+	result := make(IDSlice, 0)
+	i, j := 0, 0
+	for i < len(ids) && j < len(o) {
+		switch {
+		case ids[i] < o[j]:
+			i++
+		case ids[i] > o[j]:
+			j++
+		default:
+			result = append(result, ids[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// Difference returns a new sorted IDSlice containing the IDs which are in ids but not in o.
+// ids and o must be sorted, and are not modified.
+func (ids IDSlice) Difference(o IDSlice) IDSlice {
+	result := make(IDSlice, 0, len(ids))
+	j := 0
+	for _, id := range ids {
+		for j < len(o) && o[j] < id {
+			j++
+		}
+		if j < len(o) && o[j] == id {
+			continue
+		}
+		result = append(result, id)
+	}
+	return result
+}
+
+// IsDisjoint returns true if no ID is both in ids and in o.
+// ids and o must be sorted.
+func (ids IDSlice) IsDisjoint(o IDSlice) bool {
+	i, j := 0, 0
+	for i < len(ids) && j < len(o) {
+		switch {
+		case ids[i] < o[j]:
+			i++
+		case ids[i] > o[j]:
+			j++
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// ids is encoded as an array of integers in increasing order.
+func (ids IDSlice) MarshalJSON() ([]byte, error) {
+	sorted := NewIDSlice(ids)
+	out := make([]int, len(sorted))
+	for i, id := range sorted {
+		out[i] = int(id)
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The IDs are sorted, and an error is returned if one of them is 0, too large, or appears twice.
+func (ids *IDSlice) UnmarshalJSON(data []byte) error {
+	var out []json.Number
+	if err := json.Unmarshal(data, &out); err != nil {
+		return fmt.Errorf("party.IDSlice: %w", err)
+	}
+	decoded := make([]ID, len(out))
+	for i, n := range out {
+		if err := decoded[i].UnmarshalText([]byte(n)); err != nil {
+			return fmt.Errorf("party.IDSlice: %w", err)
+		}
+	}
+	result := NewIDSlice(decoded)
+	for i := 1; i < len(result); i++ {
+		if result[i] == result[i-1] {
+			return fmt.Errorf("party.IDSlice: duplicate ID %d", result[i])
+		}
+	}
+	*ids = result
+	return nil
+}
+
+// Bytes returns the binary encoding of ids, which is
+//
+//	n ∥ id₁ ∥ ... ∥ idₙ
+//
+// where n and the IDs are IDByteSize bytes wide, and the IDs are sorted.
+// Unlike MarshalBinary, it does not check that the IDs are valid.
+func (ids IDSlice) Bytes() []byte {
+	sorted := NewIDSlice(ids)
+	data := make([]byte, 0, (len(sorted)+1)*IDByteSize)
+	data = append(data, sorted.N().Bytes()...)
+	for _, id := range sorted {
+		data = append(data, id.Bytes()...)
+	}
+	return data
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The encoding is the one returned by Bytes.
+// An error is returned if ids contains 0 or duplicates, since the result could not be decoded.
+func (ids IDSlice) MarshalBinary() ([]byte, error) {
+	sorted := NewIDSlice(ids)
+	for i, id := range sorted {
+		if id == 0 {
+			return nil, fmt.Errorf("party.IDSlice: %w", ErrZeroID)
+		}
+		if i > 0 && id == sorted[i-1] {
+			return nil, fmt.Errorf("party.IDSlice: duplicate ID %d", id)
+		}
+	}
+	return sorted.Bytes(), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// data must have exactly the length announced by its count, and the IDs must be non-zero and strictly increasing.
+func (ids *IDSlice) UnmarshalBinary(data []byte) error {
+	size, err := FromBytes(data)
+	if err != nil {
+		return errors.New("party.IDSlice: data is too short")
+	}
+	data = data[IDByteSize:]
+	if uint64(len(data)) != uint64(size)*IDByteSize {
+		return fmt.Errorf("party.IDSlice: data has %d bytes, but %d IDs need %d", len(data), size, uint64(size)*IDByteSize)
+	}
+	result := make(IDSlice, size)
+	for i := range result {
+		id, _ := FromBytes(data[i*IDByteSize:])
+		if id == 0 {
+			return fmt.Errorf("party.IDSlice: %w", ErrZeroID)
+		}
+		if i > 0 && id <= result[i-1] {
+			return fmt.Errorf("party.IDSlice: IDs must be unique and sorted, but %d follows %d", id, result[i-1])
+		}
+		result[i] = id
+	}
+	*ids = result
+	return nil
+}
+
+// Scalars returns the scalars of the IDs in ids, in the same order, so that Scalars()[i] is ids[i].Scalar().
+// They are stored in a single allocation, which is cheaper than calling ID.Scalar for each ID.
+// The result is not shared, and may be modified by the caller.
+func (ids IDSlice) Scalars() []ristretto.Scalar {
+	scalars := make([]ristretto.Scalar, len(ids))
+	for i, id := range ids {
+		id.ScalarTo(&scalars[i])
+	}
+	return scalars
+}
+
+// Lagrange returns the Lagrange coefficient lⱼ(0) of id, for the interpolation at 0 over the IDs in ids.
+// It is the same as id.Lagrange(ids).
+// An error is returned if ids is empty or does not contain id.
+func (ids IDSlice) Lagrange(id ID) (*ristretto.Scalar, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("party.IDSlice: Lagrange: set is empty")
+	}
+	if !ids.Contains(id) {
+		return nil, fmt.Errorf("party.IDSlice: Lagrange: set does not contain %d", id)
+	}
+	return id.Lagrange(ids)
+}
+
+// LagrangeAll returns the Lagrange coefficients lⱼ(0) of all IDs in ids, for the interpolation at 0 over ids.
+// A secret shared with a polynomial f of degree smaller than len(ids) is f(0) = ∑ⱼ lⱼ(0)•f(xⱼ).
+//
+// The product x₀ ... xₖ is only computed once, so that
+//
+//	        x₀ ... xₖ
+//	lⱼ(0) = -----------------------------------
+//	        xⱼ • (x₀ - xⱼ) ... (xₖ - xⱼ)
+//
+// where the factor (xⱼ - xⱼ) is omitted from the denominator.
+// An error is returned if ids is empty, or contains 0 or duplicates.
+func (ids IDSlice) LagrangeAll() (map[ID]*ristretto.Scalar, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("party.IDSlice: LagrangeAll: set is empty")
+	}
+	xs := ids.Scalars()
+	var product ristretto.Scalar
+	product.Set(&xs[0])
+	for i, id := range ids {
+		if id == 0 {
+			return nil, errors.New("party.IDSlice: LagrangeAll: set contains 0")
+		}
+		if i > 0 {
+			product.Multiply(&product, &xs[i])
+		}
+	}
+
+	coefficients := make(map[ID]*ristretto.Scalar, len(ids))
+	var denum, diff ristretto.Scalar
+	for j, id := range ids {
+		denum.Set(&xs[j])
+		for m := range ids {
+			if m == j {
+				continue
+			}
+			diff.Subtract(&xs[m], &xs[j])
+			denum.Multiply(&denum, &diff)
+		}
+		// the denominator is 0 only if an ID appears twice
+		if denum.Equal(ristretto.NewScalar()) == 1 {
+			return nil, fmt.Errorf("party.IDSlice: LagrangeAll: set contains %d twice", id)
+		}
+		denum.Invert(&denum)
+		var coefficient ristretto.Scalar
+		coefficient.Multiply(&product, &denum)
+		coefficients[id] = &coefficient
+	}
+	return coefficients, nil
+}
+
+// LagrangeAllAt returns the Lagrange coefficients lⱼ(x) of all IDs in ids, for the interpolation at the point x over ids,
+// so that f(x) = ∑ⱼ lⱼ(x)•f(xⱼ) for a polynomial f of degree smaller than len(ids):
+//
+//	        (x - x₀) ... (x - xₖ)
+//	lⱼ(x) = ---------------------
+//	        (xⱼ - x₀) ... (xⱼ - xₖ)
+//
+// where the factors for xⱼ are omitted.
+// An error is returned if ids is empty, contains 0 or duplicates, or if x is one of the ids.
+func (ids IDSlice) LagrangeAllAt(x ID) (map[ID]*ristretto.Scalar, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("party.IDSlice: LagrangeAllAt: set is empty")
+	}
+	if ids.Contains(x) {
+		return nil, fmt.Errorf("party.IDSlice: LagrangeAllAt: set contains %d", x)
+	}
+	xs := ids.Scalars()
+	var point, one ristretto.Scalar
+	x.ScalarTo(&point)
+	ID(1).ScalarTo(&one)
+
+	coefficients := make(map[ID]*ristretto.Scalar, len(ids))
+	var num, denum, diff ristretto.Scalar
+	for j, id := range ids {
+		if id == 0 {
+			return nil, errors.New("party.IDSlice: LagrangeAllAt: set contains 0")
+		}
+		num.Set(&one)
+		denum.Set(&one)
+		for m := range ids {
+			if m == j {
+				continue
+			}
+			num.Multiply(&num, diff.Subtract(&point, &xs[m]))
+			denum.Multiply(&denum, diff.Subtract(&xs[j], &xs[m]))
+		}
+		// the denominator is 0 only if an ID appears twice
+		if denum.Equal(ristretto.NewScalar()) == 1 {
+			return nil, fmt.Errorf("party.IDSlice: LagrangeAllAt: set contains %d twice", id)
+		}
+		denum.Invert(&denum)
+		coefficients[id] = new(ristretto.Scalar).Multiply(&num, &denum)
+	}
+	return coefficients, nil
+}
@@ -0,0 +1,503 @@
+package party
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// randomIDSlice returns a sorted IDSlice of random IDs in [1, 32], so that random sets often overlap.
+func randomIDSlice(r *rand.Rand) IDSlice {
+	n := r.Intn(12)
+	seen := make(map[ID]bool, n)
+	ids := make([]ID, 0, n)
+	for len(ids) < n {
+		id := ID(1 + r.Intn(32))
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return NewIDSlice(ids)
+}
+
+func isSorted(ids IDSlice) bool {
+	for i := 1; i < len(ids); i++ {
+		if ids[i-1] >= ids[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestIDSlice_SetOperations(t *testing.T) {
+	a, b := IDSlice{1, 3, 5, 7}, IDSlice{2, 3, 7, 8}
+	if got := a.Union(b); !got.Equal(IDSlice{1, 2, 3, 5, 7, 8}) {
+		t.Errorf("Union = %v", got)
+	}
+	if got := a.Intersect(b); !got.Equal(IDSlice{3, 7}) {
+		t.Errorf("Intersect = %v", got)
+	}
+	if got := a.Difference(b); !got.Equal(IDSlice{1, 5}) {
+		t.Errorf("Difference = %v", got)
+	}
+	if a.IsDisjoint(b) || !a.IsDisjoint(IDSlice{2, 4}) {
+		t.Error("IsDisjoint is wrong")
+	}
+
+	var empty IDSlice
+	if got := empty.Union(empty); got == nil || len(got) != 0 {
+		t.Errorf("Union of empty sets = %#v", got)
+	}
+	if !a.Union(empty).Equal(a) || !empty.Union(a).Equal(a) {
+		t.Error("empty set is not the identity of Union")
+	}
+	if len(a.Intersect(empty)) != 0 || len(empty.Difference(a)) != 0 || !a.Difference(empty).Equal(a) {
+		t.Error("operations with the empty set are wrong")
+	}
+	if !empty.IsDisjoint(empty) || !a.IsDisjoint(empty) {
+		t.Error("the empty set is disjoint from any set")
+	}
+}
+
+func TestIDSlice_Predicates(t *testing.T) {
+	var nilSet IDSlice
+	empty := IDSlice{}
+	a := IDSlice{1, 3, 5}
+	b := IDSlice{1, 2, 3, 4, 5}
+	c := IDSlice{3, 5, 7}
+
+	tests := []struct {
+		name          string
+		x, y          IDSlice
+		subset, equal bool
+	}{
+		{"nil nil", nilSet, nilSet, true, true},
+		{"nil empty", nilSet, empty, true, true},
+		{"nil set", nilSet, a, true, false},
+		{"set nil", a, nilSet, false, false},
+		{"identical", a, a.Copy(), true, true},
+		{"strict subset", a, b, true, false},
+		{"superset", b, a, false, false},
+		{"overlapping", a, c, false, false},
+		{"larger element", IDSlice{6}, a, false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.x.IsSubsetOf(tt.y); got != tt.subset {
+			t.Errorf("%s: %v.IsSubsetOf(%v) = %v", tt.name, tt.x, tt.y, got)
+		}
+		if got := tt.x.Equal(tt.y); got != tt.equal {
+			t.Errorf("%s: %v.Equal(%v) = %v", tt.name, tt.x, tt.y, got)
+		}
+		if got := tt.x.ContainsAll(tt.y...); got != tt.y.IsSubsetOf(tt.x) {
+			t.Errorf("%s: %v.ContainsAll(%v) = %v", tt.name, tt.x, tt.y, got)
+		}
+	}
+
+	if !a.ContainsAll() || !nilSet.ContainsAll() {
+		t.Error("ContainsAll() without arguments should be true")
+	}
+	if !a.ContainsAll(5, 1, 5) {
+		t.Error("ContainsAll should accept unsorted and repeated arguments")
+	}
+	if a.ContainsAll(5, 2) || nilSet.ContainsAll(1) {
+		t.Error("ContainsAll should be false when an argument is missing")
+	}
+}
+
+func TestIDSlice_AddRemove(t *testing.T) {
+	var ids IDSlice
+	for _, id := range []ID{5, 1, 3, MaxID} {
+		if err := ids.Add(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !ids.Equal(IDSlice{1, 3, 5, MaxID}) {
+		t.Fatalf("Add() = %v", ids)
+	}
+	if err := ids.Add(3); err == nil {
+		t.Error("Add() of an existing ID should fail")
+	}
+	if err := ids.Add(0); !errors.Is(err, ErrZeroID) {
+		t.Errorf("Add(0) error = %v, want ErrZeroID", err)
+	}
+	if err := ids.Remove(2); err == nil {
+		t.Error("Remove() of a missing ID should fail")
+	}
+	if !ids.Equal(IDSlice{1, 3, 5, MaxID}) {
+		t.Fatalf("failed calls modified the set: %v", ids)
+	}
+
+	// The previous slice is never modified, so it can be handed out while the set keeps changing.
+	previous := ids
+	if err := ids.Remove(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := ids.Add(2); err != nil {
+		t.Fatal(err)
+	}
+	if !ids.Equal(IDSlice{2, 3, 5, MaxID}) || !previous.Equal(IDSlice{1, 3, 5, MaxID}) {
+		t.Errorf("ids = %v, previous = %v", ids, previous)
+	}
+
+	// Random interleavings agree with a map
+	r := rand.New(rand.NewSource(1))
+	ids = nil
+	members := map[ID]bool{}
+	for i := 0; i < 2000; i++ {
+		id := ID(1 + r.Intn(32))
+		if r.Intn(2) == 0 {
+			err := ids.Add(id)
+			if (err == nil) == members[id] {
+				t.Fatalf("Add(%d) error = %v, member = %v", id, err, members[id])
+			}
+			members[id] = true
+		} else {
+			err := ids.Remove(id)
+			if (err == nil) != members[id] {
+				t.Fatalf("Remove(%d) error = %v, member = %v", id, err, members[id])
+			}
+			delete(members, id)
+		}
+		if len(ids) != len(members) || !sort.SliceIsSorted(ids, func(i, j int) bool { return ids[i] < ids[j] }) {
+			t.Fatalf("inconsistent set %v", ids)
+		}
+		for member := range members {
+			if !ids.Contains(member) {
+				t.Fatalf("%v does not contain %d", ids, member)
+			}
+		}
+	}
+}
+
+func TestIDSlice_SetLaws(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 2000; iter++ {
+		a, b, c := randomIDSlice(r), randomIDSlice(r), randomIDSlice(r)
+		aCopy, bCopy := a.Copy(), b.Copy()
+
+		union, inter, diff := a.Union(b), a.Intersect(b), a.Difference(b)
+		for _, s := range []IDSlice{union, inter, diff} {
+			if !isSorted(s) {
+				t.Fatalf("%v is not sorted", s)
+			}
+		}
+
+		// Membership
+		for id := ID(1); id <= 32; id++ {
+			inA, inB := a.Contains(id), b.Contains(id)
+			if union.Contains(id) != (inA || inB) || inter.Contains(id) != (inA && inB) || diff.Contains(id) != (inA && !inB) {
+				t.Fatalf("wrong membership of %d for a=%v b=%v", id, a, b)
+			}
+		}
+
+		// Commutativity and associativity
+		if !union.Equal(b.Union(a)) || !inter.Equal(b.Intersect(a)) {
+			t.Fatalf("operations are not commutative for a=%v b=%v", a, b)
+		}
+		if !union.Union(c).Equal(a.Union(b.Union(c))) || !inter.Intersect(c).Equal(a.Intersect(b.Intersect(c))) {
+			t.Fatalf("operations are not associative for a=%v b=%v c=%v", a, b, c)
+		}
+
+		// Distributivity and De Morgan's laws relative to c
+		if !a.Intersect(b.Union(c)).Equal(inter.Union(a.Intersect(c))) {
+			t.Fatalf("Intersect does not distribute over Union for a=%v b=%v c=%v", a, b, c)
+		}
+		if !c.Difference(union).Equal(c.Difference(a).Intersect(c.Difference(b))) ||
+			!c.Difference(inter).Equal(c.Difference(a).Union(c.Difference(b))) {
+			t.Fatalf("De Morgan's laws do not hold for a=%v b=%v c=%v", a, b, c)
+		}
+
+		// Relations between the operations
+		if !diff.Union(inter).Equal(a) || !diff.IsDisjoint(b) || a.IsDisjoint(b) != (len(inter) == 0) {
+			t.Fatalf("Difference and Intersect do not partition a=%v b=%v", a, b)
+		}
+		if !inter.IsSubsetOf(a) || !a.IsSubsetOf(union) || a.IsSubsetOf(b) != (len(diff) == 0) {
+			t.Fatalf("subset relations do not hold for a=%v b=%v", a, b)
+		}
+		if len(union) != len(a)+len(b)-len(inter) {
+			t.Fatalf("wrong size of Union for a=%v b=%v", a, b)
+		}
+
+		// Self-arguments
+		if !a.Union(a).Equal(a) || !a.Intersect(a).Equal(a) || len(a.Difference(a)) != 0 || a.IsDisjoint(a) != (len(a) == 0) {
+			t.Fatalf("operations with itself are wrong for a=%v", a)
+		}
+
+		// The receivers are not modified, and the results do not alias them
+		if !a.Equal(aCopy) || !b.Equal(bCopy) {
+			t.Fatalf("receivers were modified")
+		}
+		if len(union) > 0 {
+			union[0] = 0
+			if !a.Equal(aCopy) || !b.Equal(bCopy) {
+				t.Fatalf("Union aliases its arguments")
+			}
+		}
+	}
+}
+
+func TestIDSlice_JSON(t *testing.T) {
+	data, err := json.Marshal(IDSlice{5, 1, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `[1,3,5]` {
+		t.Errorf("json.Marshal() = %s", data)
+	}
+	var ids IDSlice
+	if err = json.Unmarshal([]byte(`[5,1,3]`), &ids); err != nil {
+		t.Fatal(err)
+	}
+	if !ids.Equal(IDSlice{1, 3, 5}) {
+		t.Errorf("json.Unmarshal() = %v", ids)
+	}
+
+	for _, invalid := range []string{`[1,0]`, `[1,4294967296]`, `[1,3,1]`, `[-1]`, `[1.5]`, `{}`} {
+		if err = json.Unmarshal([]byte(invalid), &ids); err == nil {
+			t.Errorf("json.Unmarshal(%s) should fail", invalid)
+		}
+	}
+	if err = json.Unmarshal([]byte(`[0]`), &ids); !errors.Is(err, ErrZeroID) {
+		t.Errorf("json.Unmarshal([0]) error = %v, want ErrZeroID", err)
+	}
+}
+
+func TestIDSlice_Binary(t *testing.T) {
+	ids := IDSlice{MaxID, 1, 300}
+	want := []byte{0, 0, 0, 3, 0, 0, 0, 1, 0, 0, 1, 44, 0xff, 0xff, 0xff, 0xff}
+	data, err := ids.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf("MarshalBinary() = %x, want %x", data, want)
+	}
+	if !bytes.Equal(ids.Bytes(), want) {
+		t.Errorf("Bytes() = %x, want %x", ids.Bytes(), want)
+	}
+	var decoded IDSlice
+	if err = decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Equal(IDSlice{1, 300, MaxID}) {
+		t.Errorf("UnmarshalBinary() = %v", decoded)
+	}
+
+	var empty IDSlice
+	if err = empty.UnmarshalBinary(IDSlice{}.Bytes()); err != nil || empty == nil || len(empty) != 0 {
+		t.Errorf("UnmarshalBinary(empty) = %v, %v", empty, err)
+	}
+
+	for _, invalid := range []IDSlice{{1, 0}, {2, 1, 2}} {
+		if _, err = invalid.MarshalBinary(); err == nil {
+			t.Errorf("%v.MarshalBinary() should fail", invalid)
+		}
+	}
+
+	for name, data := range map[string][]byte{
+		"empty":       {},
+		"short count": {0, 0, 1},
+		"truncated":   want[:len(want)-1],
+		"trailing":    append(append([]byte{}, want...), 0),
+		"missing IDs": {0, 0, 0, 4, 0, 0, 0, 1},
+		"huge count":  {0xff, 0xff, 0xff, 0xff, 0, 0, 0, 1},
+		"zero":        {0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 1},
+		"unsorted":    {0, 0, 0, 2, 0, 0, 0, 2, 0, 0, 0, 1},
+		"duplicate":   {0, 0, 0, 2, 0, 0, 0, 2, 0, 0, 0, 2},
+		"wrong count": {0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 2},
+	} {
+		decoded = IDSlice{7}
+		if err = decoded.UnmarshalBinary(data); err == nil {
+			t.Errorf("UnmarshalBinary(%s) should fail", name)
+		}
+		if !decoded.Equal(IDSlice{7}) {
+			t.Errorf("UnmarshalBinary(%s) modified the receiver", name)
+		}
+	}
+	if err = decoded.UnmarshalBinary([]byte{0, 0, 0, 1, 0, 0, 0, 0}); !errors.Is(err, ErrZeroID) {
+		t.Errorf("UnmarshalBinary(zero) error = %v, want ErrZeroID", err)
+	}
+}
+
+func TestIDSlice_LagrangeAll(t *testing.T) {
+	// f(X) = secret + a₁•X + a₂•X²
+	secret := scalar.NewScalarRandom()
+	a1, a2 := scalar.NewScalarRandom(), scalar.NewScalarRandom()
+	evaluate := func(id ID) *ristretto.Scalar {
+		var y ristretto.Scalar
+		x := id.Scalar()
+		y.MultiplyAdd(a2, x, a1)
+		y.MultiplyAdd(&y, x, secret)
+		return &y
+	}
+
+	for _, ids := range []IDSlice{{1, 2, 3}, {4, 17, 65536, MaxID}, {2, 3, 5, 7, 11, 13}} {
+		coefficients, err := ids.LagrangeAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(coefficients) != len(ids) {
+			t.Fatalf("LagrangeAll() returned %d coefficients, want %d", len(coefficients), len(ids))
+		}
+
+		reconstructed := ristretto.NewScalar()
+		for _, id := range ids {
+			expected, err := ids.Lagrange(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if coefficients[id].Equal(expected) != 1 {
+				t.Errorf("LagrangeAll() and Lagrange() differ for %d in %v", id, ids)
+			}
+			reconstructed.MultiplyAdd(coefficients[id], evaluate(id), reconstructed)
+		}
+		if reconstructed.Equal(secret) != 1 {
+			t.Errorf("the secret was not reconstructed from the shares of %v", ids)
+		}
+	}
+
+	// With fewer shares than the degree + 1, the secret cannot be reconstructed
+	ids := IDSlice{3, 8}
+	coefficients, err := ids.LagrangeAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reconstructed := ristretto.NewScalar()
+	for _, id := range ids {
+		reconstructed.MultiplyAdd(coefficients[id], evaluate(id), reconstructed)
+	}
+	if reconstructed.Equal(secret) == 1 {
+		t.Error("the secret was reconstructed from too few shares")
+	}
+}
+
+func TestIDSlice_LagrangeAllAt(t *testing.T) {
+	// f(X) = a₀ + a₁•X + a₂•X²
+	a0, a1, a2 := scalar.NewScalarRandom(), scalar.NewScalarRandom(), scalar.NewScalarRandom()
+	evaluate := func(id ID) *ristretto.Scalar {
+		var y ristretto.Scalar
+		x := id.Scalar()
+		y.MultiplyAdd(a2, x, a1)
+		y.MultiplyAdd(&y, x, a0)
+		return &y
+	}
+
+	for _, ids := range []IDSlice{{1, 2, 3}, {4, 17, 65536, MaxID}, {2, 3, 5, 7, 11, 13}} {
+		coefficients, err := ids.LagrangeAllAt(6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		interpolated := ristretto.NewScalar()
+		for _, id := range ids {
+			interpolated.MultiplyAdd(coefficients[id], evaluate(id), interpolated)
+		}
+		if interpolated.Equal(evaluate(6)) != 1 {
+			t.Errorf("f(6) was not interpolated from the shares of %v", ids)
+		}
+	}
+
+	for _, ids := range []IDSlice{nil, {0, 1, 2}, {1, 2, 2}, {1, 6, 7}} {
+		if _, err := ids.LagrangeAllAt(6); err == nil {
+			t.Errorf("LagrangeAllAt() should fail for %v", ids)
+		}
+	}
+}
+
+func TestIDSlice_Lagrange_Errors(t *testing.T) {
+	if _, err := (IDSlice{}).Lagrange(1); err == nil {
+		t.Error("Lagrange() should fail on an empty set")
+	}
+	if _, err := (IDSlice{1, 2}).Lagrange(3); err == nil {
+		t.Error("Lagrange() should fail for an ID which is not in the set")
+	}
+	for _, ids := range []IDSlice{nil, {0, 1, 2}, {1, 2, 2}} {
+		if _, err := ids.LagrangeAll(); err == nil {
+			t.Errorf("LagrangeAll() should fail for %v", ids)
+		}
+	}
+}
+
+func TestIDSlice_Scalars(t *testing.T) {
+	ids := IDSlice{1, 7, MaxID}
+	scalars := ids.Scalars()
+	if len(scalars) != len(ids) {
+		t.Fatalf("Scalars() has %d elements", len(scalars))
+	}
+	for i, id := range ids {
+		if scalars[i].Equal(id.Scalar()) != 1 {
+			t.Errorf("Scalars()[%d] != %d.Scalar()", i, id)
+		}
+	}
+	if len(IDSlice(nil).Scalars()) != 0 {
+		t.Error("Scalars() of an empty set should be empty")
+	}
+}
+
+// BenchmarkIDSlice_LagrangeAll128 computes the coefficients of a 128 party signing session.
+func BenchmarkIDSlice_LagrangeAll128(b *testing.B) {
+	ids := make(IDSlice, 128)
+	for i := range ids {
+		ids[i] = ID(i + 1)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ids.LagrangeAll(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestNewIDSliceRange(t *testing.T) {
+	tests := []struct {
+		start   ID
+		count   Size
+		want    IDSlice
+		wantErr bool
+	}{
+		{1, 1, IDSlice{1}, false},
+		{1, 4, IDSlice{1, 2, 3, 4}, false},
+		{MaxID, 1, IDSlice{MaxID}, false},
+		{MaxID - 2, 3, IDSlice{MaxID - 2, MaxID - 1, MaxID}, false},
+		{MaxID - 2, 4, nil, true},
+		{MaxID, 2, nil, true},
+		{2, MaxID, nil, true},
+		{1, 0, nil, true},
+		{0, 3, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := NewIDSliceRange(tt.start, tt.count)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewIDSliceRange(%d, %d) error = %v, wantErr %v", tt.start, tt.count, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("NewIDSliceRange(%d, %d) = %v, want %v", tt.start, tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestMustIDSlice(t *testing.T) {
+	if got := MustIDSlice(MaxID, 3, 1); !got.Equal(IDSlice{1, 3, MaxID}) {
+		t.Errorf("MustIDSlice() = %v", got)
+	}
+	if got := MustIDSlice(7); !got.Equal(IDSlice{7}) {
+		t.Errorf("MustIDSlice(7) = %v", got)
+	}
+	for _, invalid := range [][]ID{{0}, {1, 0}, {2, 1, 2}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("MustIDSlice(%v) should panic", invalid)
+				}
+			}()
+			MustIDSlice(invalid...)
+		}()
+	}
+}
@@ -0,0 +1,17 @@
+//go:build go1.23
+
+package party
+
+import "iter"
+
+// All returns an iterator over the IDs in ids, in increasing order if ids is sorted.
+// It does not allocate.
+func (ids IDSlice) All() iter.Seq[ID] {
+	return func(yield func(ID) bool) {
+		for _, id := range ids {
+			if !yield(id) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+//go:build go1.23
+
+package party
+
+import "testing"
+
+func TestIDSlice_All(t *testing.T) {
+	ids := IDSlice{1, 4, 6, 9}
+	var got IDSlice
+	for id := range ids.All() {
+		got = append(got, id)
+	}
+	if !got.Equal(ids) {
+		t.Errorf("All() yielded %v, want %v", got, ids)
+	}
+
+	got = got[:0]
+	for id := range ids.All() {
+		if id > 4 {
+			break
+		}
+		got = append(got, id)
+	}
+	if !got.Equal(IDSlice{1, 4}) {
+		t.Errorf("All() yielded %v after break, want [1 4]", got)
+	}
+
+	for range IDSlice(nil).All() {
+		t.Error("All() yielded an ID for an empty set")
+	}
+}
+
+func TestIDSlice_All_Allocs(t *testing.T) {
+	ids := make(IDSlice, 100)
+	for i := range ids {
+		ids[i] = ID(i + 1)
+	}
+	var sum ID
+	allocs := testing.AllocsPerRun(100, func() {
+		for id := range ids.All() {
+			sum += id
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("All() allocated %v times", allocs)
+	}
+}
+
+func BenchmarkIDSlice_All(b *testing.B) {
+	ids := make(IDSlice, 100)
+	for i := range ids {
+		ids[i] = ID(i + 1)
+	}
+	b.ReportAllocs()
+	var sum ID
+	for i := 0; i < b.N; i++ {
+		for id := range ids.All() {
+			sum += id
+		}
+	}
+	_ = sum
+}
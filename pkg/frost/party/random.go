@@ -0,0 +1,100 @@
+package party
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// randReader is the source of RandomID and RandomSet. It is only replaced by tests.
+var randReader io.Reader = cryptorand.Reader
+
+// RandomID returns a uniformly random non-zero ID drawn from crypto/rand.
+func RandomID() (ID, error) {
+	var buf [IDByteSize]byte
+	for {
+		if _, err := io.ReadFull(randReader, buf[:]); err != nil {
+			return 0, fmt.Errorf("party.RandomID: %w", err)
+		}
+		// 0 is rejected rather than mapped to another ID, so that all IDs are equally likely.
+		if id := ID(binary.BigEndian.Uint32(buf[:])); id != 0 {
+			return id, nil
+		}
+	}
+}
+
+// RandomSet returns n distinct, uniformly random, non-zero IDs drawn from crypto/rand, sorted in increasing order.
+// IDs equal to 0 or to a previously drawn ID are rejected and drawn again.
+func RandomSet(n Size) (IDSlice, error) {
+	if uint64(n) > uint64(MaxID) {
+		return nil, fmt.Errorf("party.RandomSet: %d IDs exceed the %d valid IDs", n, MaxID)
+	}
+	seen := make(map[ID]struct{}, n)
+	ids := make([]ID, 0, n)
+	buf := make([]byte, IDByteSize*int(n))
+	for len(ids) < int(n) {
+		// The IDs which are still missing are read at once, to limit the number of calls to the reader.
+		buf = buf[:IDByteSize*(int(n)-len(ids))]
+		if _, err := io.ReadFull(randReader, buf); err != nil {
+			return nil, fmt.Errorf("party.RandomSet: %w", err)
+		}
+		for i := 0; i < len(buf); i += IDByteSize {
+			id := ID(binary.BigEndian.Uint32(buf[i:]))
+			if _, ok := seen[id]; ok || id == 0 {
+				continue
+			}
+			seen[id] = struct{}{}
+			ids = append(ids, id)
+		}
+	}
+	return NewIDSlice(ids), nil
+}
+
+// Sample returns a uniformly random subset of k IDs of ids, sorted in increasing order.
+// The randomness is read from r, or from crypto/rand if r is nil.
+// ids is not modified. An error is returned if k is 0 or larger than the number of IDs.
+func (ids IDSlice) Sample(k int, r io.Reader) (IDSlice, error) {
+	if k <= 0 || k > len(ids) {
+		return nil, fmt.Errorf("party.IDSlice: Sample: cannot choose %d IDs out of %d", k, len(ids))
+	}
+	if r == nil {
+		r = cryptorand.Reader
+	}
+	// The first k steps of a Fisher–Yates shuffle of a sorted copy choose a uniformly random k-subset.
+	sample := NewIDSlice(ids)
+	for i := 0; i < k; i++ {
+		j, err := uniform(r, uint32(len(sample)-i))
+		if err != nil {
+			return nil, fmt.Errorf("party.IDSlice: Sample: %w", err)
+		}
+		sample[i], sample[i+int(j)] = sample[i+int(j)], sample[i]
+	}
+	return NewIDSlice(sample[:k]), nil
+}
+
+// Take returns the k smallest IDs of ids, in increasing order.
+// ids is not modified. An error is returned if k is 0 or larger than the number of IDs.
+func (ids IDSlice) Take(k int) (IDSlice, error) {
+	if k <= 0 || k > len(ids) {
+		return nil, fmt.Errorf("party.IDSlice: Take: cannot choose %d IDs out of %d", k, len(ids))
+	}
+	return NewIDSlice(ids)[:k], nil
+}
+
+// uniform returns a uniformly random integer in [0, n) read from r.
+// The values which would bias the result are rejected, so that all results are equally likely.
+func uniform(r io.Reader, n uint32) (uint32, error) {
+	// limit is the largest multiple of n not larger than 2³²
+	const values = uint64(1) << 32
+	limit := values - values%uint64(n)
+	var buf [4]byte
+	for {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return 0, err
+		}
+		if v := binary.BigEndian.Uint32(buf[:]); uint64(v) < limit {
+			return v % n, nil
+		}
+	}
+}
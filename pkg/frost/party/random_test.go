@@ -0,0 +1,140 @@
+package party
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	mathrand "math/rand"
+	"testing"
+)
+
+// stubReader replaces randReader with a reader returning data, until the test ends.
+func stubReader(t *testing.T, data ...byte) {
+	reader := randReader
+	t.Cleanup(func() { randReader = reader })
+	randReader = bytes.NewReader(data)
+}
+
+func TestRandomID(t *testing.T) {
+	stubReader(t, 0, 0, 0, 0, 1, 2, 3, 4)
+	id, err := RandomID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 0x01020304 {
+		t.Errorf("RandomID() = %d, want %d", id, 0x01020304)
+	}
+
+	if _, err = RandomID(); !errors.Is(err, io.EOF) {
+		t.Errorf("RandomID() error = %v, want io.EOF", err)
+	}
+}
+
+func TestRandomSet(t *testing.T) {
+	// 0 and the duplicate 5 are rejected, and the missing IDs are read again.
+	stubReader(t, 0, 0, 0, 9, 0, 0, 0, 0, 0, 0, 0, 5, 0, 0, 0, 5, 0, 0, 0, 2)
+	ids, err := RandomSet(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ids.Equal(IDSlice{2, 5, 9}) {
+		t.Errorf("RandomSet() = %v, want [2 5 9]", ids)
+	}
+
+	if _, err = RandomSet(1); !errors.Is(err, io.EOF) {
+		t.Errorf("RandomSet() error = %v, want io.EOF", err)
+	}
+	if ids, err = RandomSet(0); err != nil || len(ids) != 0 {
+		t.Errorf("RandomSet(0) = %v, %v", ids, err)
+	}
+}
+
+func TestRandomSet_Distinct(t *testing.T) {
+	const n = 1 << 17
+	ids, err := RandomSet(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != n || ids[0] == 0 || !isSorted(ids) {
+		t.Errorf("RandomSet() did not return %d distinct valid IDs", n)
+	}
+}
+
+func TestRandID(t *testing.T) {
+	source := randInt63n
+	t.Cleanup(func() { randInt63n = source })
+	for _, n := range []int64{0, int64(MaxID) - 1} {
+		randInt63n = func(int64) int64 { return n }
+		if id := RandID(); id != ID(n+1) {
+			t.Errorf("RandID() = %d, want %d", id, n+1)
+		}
+	}
+}
+
+func TestIDSlice_Sample(t *testing.T) {
+	ids := IDSlice{9, 2, 7, 4, 5}
+	original := ids.Copy()
+	r := mathrand.New(mathrand.NewSource(1))
+
+	// With 10 possible subsets of 2 IDs, each should be chosen about N/10 times.
+	const N = 20000
+	counts := make(map[[2]ID]int)
+	for i := 0; i < N; i++ {
+		sample, err := ids.Sample(2, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sample) != 2 || !isSorted(sample) || !sample.IsSubsetOf(NewIDSlice(ids)) {
+			t.Fatalf("Sample() = %v is not a sorted subset of %v", sample, ids)
+		}
+		counts[[2]ID{sample[0], sample[1]}]++
+	}
+	if len(counts) != 10 {
+		t.Fatalf("Sample() chose %d different subsets, want 10", len(counts))
+	}
+	expected := float64(N) / 10
+	chi2 := 0.0
+	for _, count := range counts {
+		d := float64(count) - expected
+		chi2 += d * d / expected
+	}
+	// 27.88 is the 0.999 quantile of the χ² distribution with 9 degrees of freedom.
+	if chi2 > 27.88 {
+		t.Errorf("Sample() is biased: χ² = %f", chi2)
+	}
+	if !ids.Equal(original) {
+		t.Error("Sample() modified the set")
+	}
+
+	all, err := ids.Sample(len(ids), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !all.Equal(NewIDSlice(ids)) {
+		t.Errorf("Sample() of all IDs = %v", all)
+	}
+	for _, k := range []int{0, -1, 6} {
+		if _, err = ids.Sample(k, nil); err == nil {
+			t.Errorf("Sample(%d) should fail", k)
+		}
+	}
+	if _, err = ids.Sample(2, bytes.NewReader(nil)); !errors.Is(err, io.EOF) {
+		t.Errorf("Sample() error = %v, want io.EOF", err)
+	}
+}
+
+func TestIDSlice_Take(t *testing.T) {
+	ids := IDSlice{9, 2, 7}
+	taken, err := ids.Take(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !taken.Equal(IDSlice{2, 7}) || !ids.Equal(IDSlice{9, 2, 7}) {
+		t.Errorf("Take(2) = %v", taken)
+	}
+	for _, k := range []int{0, 4} {
+		if _, err = ids.Take(k); err == nil {
+			t.Errorf("Take(%d) should fail", k)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package party
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidThreshold is wrapped by a *ThresholdError.
+var ErrInvalidThreshold = errors.New("invalid threshold")
+
+// ThresholdError is returned when a threshold is not between 1 and n-1 for a set of n parties.
+//
+// Throughout this module, the threshold t is the maximum number of parties which may be corrupted without
+// compromising the key, so that MinSigners(t) = t+1 parties are required to sign.
+// A threshold of 0 would let any single party sign alone, and a threshold of n or more would make signing impossible.
+type ThresholdError struct {
+	// Threshold is the rejected threshold.
+	Threshold Size
+	// N is the number of parties sharing the key.
+	N Size
+}
+
+// Error implements error
+func (e *ThresholdError) Error() string {
+	if e.N < 2 {
+		return fmt.Sprintf("%s t=%d: a key must be shared by at least 2 parties (got %d)", ErrInvalidThreshold.Error(), e.Threshold, e.N)
+	}
+	return fmt.Sprintf("%s t=%d for %d parties: the threshold is the number of tolerated corruptions, it must be between 1 and %d so that between 2 and %d parties sign",
+		ErrInvalidThreshold.Error(), e.Threshold, e.N, e.N-1, e.N)
+}
+
+// Unwrap returns ErrInvalidThreshold.
+func (e *ThresholdError) Unwrap() error {
+	return ErrInvalidThreshold
+}
+
+// MinSigners returns t+1, the number of parties required to sign with a key of threshold t.
+func MinSigners(threshold Size) Size {
+	return threshold + 1
+}
+
+// ValidateThreshold returns a *ThresholdError unless 1 ≤ threshold ≤ n-1.
+func ValidateThreshold(threshold, n Size) error {
+	if threshold == 0 || threshold >= n {
+		return &ThresholdError{Threshold: threshold, N: n}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package party
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateThreshold(t *testing.T) {
+	for _, tc := range []struct {
+		threshold, n Size
+		valid        bool
+	}{
+		{0, 0, false},
+		{0, 1, false},
+		{1, 1, false},
+		{0, 2, false},
+		{1, 2, true},
+		{2, 2, false},
+		{0, 5, false},
+		{1, 5, true},
+		{4, 5, true},
+		{5, 5, false},
+		{6, 5, false},
+		{MaxID - 1, MaxID, true},
+		{MaxID, MaxID, false},
+	} {
+		err := ValidateThreshold(tc.threshold, tc.n)
+		if tc.valid {
+			if err != nil {
+				t.Errorf("ValidateThreshold(%d, %d) = %v", tc.threshold, tc.n, err)
+			}
+			continue
+		}
+		var thresholdErr *ThresholdError
+		if !errors.As(err, &thresholdErr) || !errors.Is(err, ErrInvalidThreshold) {
+			t.Errorf("ValidateThreshold(%d, %d) = %v, want a *ThresholdError", tc.threshold, tc.n, err)
+			continue
+		}
+		if thresholdErr.Threshold != tc.threshold || thresholdErr.N != tc.n {
+			t.Errorf("ValidateThreshold(%d, %d): the error describes t=%d, n=%d", tc.threshold, tc.n, thresholdErr.Threshold, thresholdErr.N)
+		}
+	}
+}
+
+func TestMinSigners(t *testing.T) {
+	for threshold, want := range map[Size]Size{1: 2, 2: 3, 41: 42} {
+		if got := MinSigners(threshold); got != want {
+			t.Errorf("MinSigners(%d) = %d, want %d", threshold, got, want)
+		}
+	}
+}
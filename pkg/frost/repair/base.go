@@ -0,0 +1,186 @@
+// Package repair implements the recovery of the share of a party which lost it, for example with its disk,
+// without reconstructing the key nor changing the shares of the other parties.
+//
+// The key is shared with a polynomial f of degree t, and the party j which lost its share must recover f(j).
+// The helpers are at least t+1 other parties holding a share sᵢ = f(i), so that f(j) = ∑ λᵢ•sᵢ,
+// where λᵢ is the Lagrange coefficient of helper i for the interpolation at j over the helpers.
+// Sending wᵢ = λᵢ•sᵢ to party j would reveal sᵢ, so the helpers mask their contributions with a pairwise zero-sharing:
+// each helper i samples a random mask ρᵢₖ for every other helper k, broadcasts the commitments [ρᵢₖ]•B in a Repair1
+// message, and sends ρᵢₖ to k in a KeyGen2 message. Helper k checks each mask it receives against its commitment,
+// and sends party j the value
+//
+//	vₖ = λₖ•sₖ + ∑ᵢ ρᵢₖ - ∑ᵢ ρₖᵢ
+//
+// in a Repair2 message. The masks cancel out in the sum of the vₖ, which is f(j).
+// Since the public share Aₖ of each helper is known, party j checks that [vₖ]•B = λₖ•Aₖ + ∑ᵢ [ρᵢₖ]•B - ∑ᵢ [ρₖᵢ]•B,
+// so that a helper sending an inconsistent value is blamed, and finally checks the sum against its public share Aⱼ.
+// A single vₖ is uniformly random for party j, and the masks received by any t helpers reveal nothing about the share
+// of another one.
+package repair
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+type (
+	round0 struct {
+		*state.BaseRound
+
+		// Helpers are the parties contributing to the share of LostID.
+		Helpers party.IDSlice
+		LostID  party.ID
+
+		Public *eddsa.Public
+
+		// Expected maps each helper k to λₖ•Aₖ, the commitment to its contribution to the share of LostID,
+		// to which the commitments to the masks it receives are added, and those to the masks it sends are subtracted.
+		// Once all Repair1 messages were processed, it is the commitment to the value of its Repair2 message.
+		Expected map[party.ID]*ristretto.Element
+
+		// Masks maps each other helper to the mask we send it, if we are a helper.
+		Masks map[party.ID]*ristretto.Scalar
+
+		// Incoming maps each other helper to the commitment to the mask it sends us, if we are a helper.
+		Incoming map[party.ID]*ristretto.Element
+
+		// Secret is first set to our share multiplied by our Lagrange coefficient λ at LostID, if we are a helper,
+		// and then to the value of our Repair2 message.
+		// If we are the party recovering its share, it is set to the sum of the Repair2 messages, which is our share.
+		Secret ristretto.Scalar
+
+		// Rand is the source of the masks, as set by WithRandom. crypto/rand is used if it is nil.
+		Rand io.Reader
+
+		Output *Output
+	}
+	round1 struct {
+		*round0
+	}
+	round2 struct {
+		*round1
+	}
+	round3 struct {
+		*round2
+	}
+)
+
+// NewRound returns the first round of the repair of the share of lostID in the key of public by the helpers,
+// and the Output which is filled once it has finished.
+//
+// helpers must contain at least public.MinSigners() of public.PartyIDs, and lostID must be another party of the key.
+// All parties must know public, and secret is our share if we are a helper, or nil if we are the party recovering its share.
+// The parties of the protocol are the helpers and lostID.
+func NewRound(selfID party.ID, secret *eddsa.SecretShare, public *eddsa.Public, helpers party.IDSlice, lostID party.ID, opts ...Option) (state.Round, *Output, error) {
+	helpers = party.NewIDSlice(helpers)
+
+	if !public.PartyIDs.Contains(lostID) {
+		return nil, nil, fmt.Errorf("party %d does not have a share of the key", lostID)
+	}
+	if helpers.Contains(lostID) {
+		return nil, nil, errors.New("the party recovering its share cannot be a helper")
+	}
+	if !helpers.IsSubsetOf(public.PartyIDs) {
+		return nil, nil, errors.New("the helpers must be parties of the key")
+	}
+	if helpers.N() < public.MinSigners() {
+		return nil, nil, fmt.Errorf("there must be at least %d helpers (got %d)", public.MinSigners(), helpers.N())
+	}
+
+	partyIDs := helpers.Copy()
+	if err := partyIDs.Add(lostID); err != nil {
+		return nil, nil, err
+	}
+	baseRound, err := state.NewBaseRound(selfID, partyIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	coefficients, err := helpers.LagrangeAllAt(lostID)
+	if err != nil {
+		return nil, nil, err
+	}
+	r := round0{
+		BaseRound: baseRound,
+		Helpers:   helpers,
+		LostID:    lostID,
+		Public:    public,
+		Expected:  make(map[party.ID]*ristretto.Element, helpers.N()),
+		Output:    &Output{},
+	}
+	for _, id := range helpers {
+		r.Expected[id] = new(ristretto.Element).ScalarMult(coefficients[id], public.Shares[id])
+	}
+	for _, opt := range opts {
+		opt(&r)
+	}
+
+	if r.isHelper() {
+		if secret == nil || secret.ID != selfID {
+			return nil, nil, errors.New("the secret share of a helper must be given")
+		}
+		if err = secret.Validate(public); err != nil {
+			return nil, nil, err
+		}
+		r.Secret.Multiply(coefficients[selfID], &secret.Secret)
+		r.Masks = make(map[party.ID]*ristretto.Scalar, helpers.N()-1)
+		r.Incoming = make(map[party.ID]*ristretto.Element, helpers.N()-1)
+	}
+	return &r, r.Output, nil
+}
+
+// Reset is called by the State when the protocol finishes or is aborted.
+// It zeroes the secret Secret, which holds the recovered share once the protocol finished, and the masks we sent.
+// The SecretShare of the Output is a copy and is not wiped.
+func (round *round0) Reset() {
+	round.Secret.Set(ristretto.NewScalar())
+	for _, mask := range round.Masks {
+		mask.Set(ristretto.NewScalar())
+	}
+	round.Rand = nil
+	round.Output = nil
+}
+
+// isHelper returns true if we contribute to the share of the party recovering it.
+func (round *round0) isHelper() bool {
+	return round.Helpers.Contains(round.SelfID())
+}
+
+// isLostParty returns true if we are the party recovering its share.
+func (round *round0) isLostParty() bool {
+	return round.SelfID() == round.LostID
+}
+
+// ---
+// Messages
+// ---
+
+func (round *round0) AcceptedMessageTypes() []messages.MessageType {
+	return []messages.MessageType{messages.MessageTypeNone, messages.MessageTypeRepair1, messages.MessageTypeKeyGen2, messages.MessageTypeRepair2}
+}
+
+// Senders implements state.SenderFilter, since only the helpers send messages,
+// KeyGen2 messages are only sent to the other helpers, and Repair2 messages to the party recovering its share.
+func (round *round0) Senders(msgType messages.MessageType) party.IDSlice {
+	switch {
+	case msgType == messages.MessageTypeKeyGen2 && !round.isHelper():
+		return party.IDSlice{}
+	case msgType == messages.MessageTypeRepair2 && !round.isLostParty():
+		return party.IDSlice{}
+	}
+	return round.Helpers
+}
+
+// MessageLimits implements state.Limiter, since Repair1 messages contain a commitment for each other helper.
+func (round *round0) MessageLimits() messages.Limits {
+	return messages.Limits{
+		Parties: round.PartyIDs().N(),
+	}
+}
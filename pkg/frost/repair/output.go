@@ -0,0 +1,25 @@
+package repair
+
+import (
+	"errors"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+)
+
+// Output contains the public shares of the key, and the recovered share if we are the party which lost it.
+// Helpers only obtain Public, since their own share is unchanged, and SecretKey is nil.
+type Output struct {
+	Public    *eddsa.Public
+	SecretKey *eddsa.SecretShare
+}
+
+// KeyShare returns a copy of the output as an eddsa.KeyShare, once the protocol has finished.
+func (o *Output) KeyShare() (*eddsa.KeyShare, error) {
+	if o.Public == nil {
+		return nil, errors.New("repair.Output: the protocol has not finished")
+	}
+	if o.SecretKey == nil {
+		return nil, errors.New("repair.Output: the party did not recover its share")
+	}
+	return eddsa.NewKeyShare(o.SecretKey.Copy(), o.Public.Copy())
+}
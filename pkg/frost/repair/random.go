@@ -0,0 +1,18 @@
+package repair
+
+import "io"
+
+// Option modifies the repair protocol.
+type Option func(*round0)
+
+// WithRandom returns an Option which makes a helper read from r, instead of crypto/rand, the masks ρᵢₖ
+// it subtracts from its contribution λᵢ•sᵢ, one per other helper in increasing order of their IDs.
+// It can be used to obtain entropy from an HSM, or to produce deterministic test vectors.
+// The masks hide the contribution of the helper from the others, so r must be unpredictable outside of tests.
+// It has no effect on the party recovering its share, which samples nothing.
+// If r fails or returns too few bytes, the protocol aborts with an error whose culprit is 0.
+func WithRandom(r io.Reader) Option {
+	return func(round *round0) {
+		round.Rand = r
+	}
+}
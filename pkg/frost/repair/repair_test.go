@@ -0,0 +1,265 @@
+package repair
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	mathrand "math/rand"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+var (
+	partyIDs = party.IDSlice{1, 2, 3, 4, 5}
+	lostID   = party.ID(4)
+)
+
+// setup returns the states and rounds of the repair of the share of party 4 in a 3-of-5 key,
+// as well as the secret and public shares.
+func setup(t *testing.T, helperIDs party.IDSlice) (map[party.ID]*state.State, map[party.ID]*round0, map[party.ID]*Output,
+	map[party.ID]*eddsa.SecretShare, *eddsa.Public) {
+	return setupWithOptions(t, helperIDs, nil)
+}
+
+// setupWithOptions is like setup, and creates the round of each party with the options returned by options, if not nil.
+func setupWithOptions(t *testing.T, helperIDs party.IDSlice, options func(party.ID) []Option) (map[party.ID]*state.State,
+	map[party.ID]*round0, map[party.ID]*Output, map[party.ID]*eddsa.SecretShare, *eddsa.Public) {
+	_, secrets := helpers.GenerateSecrets(partyIDs, 2)
+	public := helpers.GeneratePublic(2, secrets)
+
+	ids := helperIDs.Union(party.IDSlice{lostID})
+	states := make(map[party.ID]*state.State, ids.N())
+	rounds := make(map[party.ID]*round0, ids.N())
+	outputs := make(map[party.ID]*Output, ids.N())
+	for _, id := range ids {
+		// The party recovering its share does not have it anymore
+		secret := secrets[id]
+		if id == lostID {
+			secret = nil
+		}
+		var opts []Option
+		if options != nil {
+			opts = options(id)
+		}
+		r, output, err := NewRound(id, secret, public, helperIDs, lostID, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rounds[id], outputs[id] = r.(*round0), output
+		if states[id], err = state.NewBaseState(r, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return states, rounds, outputs, secrets, public
+}
+
+// run executes the protocol with helpers.RunProtocol.
+func run(t *testing.T, states map[party.ID]*state.State, tamper func(*messages.Message)) {
+	if err := helpers.RunProtocol(states, tamper); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRepair(t *testing.T) {
+	for _, helperIDs := range []party.IDSlice{{1, 2, 3, 5}, {1, 3, 5}, {2, 5, 1}} {
+		states, rounds, outputs, secrets, public := setup(t, helperIDs)
+		run(t, states, nil)
+
+		for id, s := range states {
+			if err := s.WaitForError(); err != nil {
+				t.Fatalf("party %d: %v", id, err)
+			}
+			if rounds[id].Secret.Equal(scalar.NewScalarUInt32(0)) != 1 {
+				t.Errorf("party %d: the secret of the round was not zeroed", id)
+			}
+			if id != lostID && outputs[id].SecretKey != nil {
+				t.Errorf("party %d is a helper, but obtained a share", id)
+			}
+		}
+
+		recovered := outputs[lostID].SecretKey
+		if err := recovered.Validate(public); err != nil {
+			t.Fatal(err)
+		}
+		if !recovered.Equal(secrets[lostID]) {
+			t.Error("the recovered share differs from the lost one")
+		}
+		if _, err := outputs[lostID].KeyShare(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestRepair_Masked(t *testing.T) {
+	// The value sent by a helper to the party recovering its share is not its bare contribution λᵢ•sᵢ,
+	// and the masks it sends to the other helpers are not either.
+	helperIDs := party.IDSlice{1, 3, 5}
+	states, _, _, secrets, _ := setup(t, helperIDs)
+	coefficients, err := helperIDs.LagrangeAllAt(lostID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var repairs int
+	run(t, states, func(msg *messages.Message) {
+		var bare, value ristretto.Scalar
+		bare.Multiply(coefficients[msg.From], &secrets[msg.From].Secret)
+		switch msg.Type {
+		case messages.MessageTypeRepair2:
+			repairs++
+			value.Set(&msg.Repair2.Share)
+		case messages.MessageTypeKeyGen2:
+			value.Set(&msg.KeyGen2.Share)
+		default:
+			return
+		}
+		if value.Equal(&bare) == 1 || value.Equal(&secrets[msg.From].Secret) == 1 {
+			t.Errorf("party %d sent its bare contribution in a %s message", msg.From, msg.Type)
+		}
+	})
+	if repairs != 3 {
+		t.Fatalf("%d Repair2 messages were delivered, want 3", repairs)
+	}
+}
+
+func TestRepair_CheatingHelper(t *testing.T) {
+	helperIDs := party.IDSlice{1, 2, 3, 5}
+
+	// Helper 2 sends a wrong value to the party recovering its share, which aborts and blames it
+	states, _, _, _, _ := setup(t, helperIDs)
+	run(t, states, func(msg *messages.Message) {
+		if msg.Type == messages.MessageTypeRepair2 && msg.From == 2 {
+			msg.Repair2.Share.Add(&msg.Repair2.Share, scalar.NewScalarUInt32(1))
+		}
+	})
+	var stateErr *state.Error
+	if err := states[lostID].WaitForError(); !errors.As(err, &stateErr) || !errors.Is(err, ErrInvalidRepair) {
+		t.Fatalf("party %d: error = %v, want ErrInvalidRepair", lostID, err)
+	}
+	if !stateErr.Culprits().Equal(party.IDSlice{2}) || stateErr.Kind() != state.KindVSSFailure {
+		t.Errorf("culprits = %v, kind = %v", stateErr.Culprits(), stateErr.Kind())
+	}
+
+	// Helper 5 sends a mask to helper 1 which differs from its commitment, and helper 1 aborts and blames it
+	states, _, _, _, _ = setup(t, helperIDs)
+	run(t, states, func(msg *messages.Message) {
+		if msg.Type == messages.MessageTypeKeyGen2 && msg.From == 5 && msg.To == 1 {
+			msg.KeyGen2.Share.Add(&msg.KeyGen2.Share, scalar.NewScalarUInt32(1))
+		}
+	})
+	if err := states[1].WaitForError(); !errors.As(err, &stateErr) || !errors.Is(err, ErrInvalidMask) {
+		t.Fatalf("party 1: error = %v, want ErrInvalidMask", err)
+	}
+	if !stateErr.Culprits().Equal(party.IDSlice{5}) {
+		t.Errorf("culprits = %v", stateErr.Culprits())
+	}
+
+	// Helper 3 contributes another value than its share, and is blamed by the party recovering its share
+	states, rounds, _, _, _ := setup(t, helperIDs)
+	if _, err := scalar.SetScalarRandomFrom(&rounds[3].Secret, mathrand.New(mathrand.NewSource(3))); err != nil {
+		t.Fatal(err)
+	}
+	run(t, states, nil)
+	if err := states[lostID].WaitForError(); !errors.As(err, &stateErr) || !errors.Is(err, ErrInvalidRepair) {
+		t.Fatalf("party %d: error = %v, want ErrInvalidRepair", lostID, err)
+	}
+	if !stateErr.Culprits().Equal(party.IDSlice{3}) {
+		t.Errorf("culprits = %v", stateErr.Culprits())
+	}
+
+	// Helper 1 omits a commitment, and all other parties blame it
+	states, _, _, _, _ = setup(t, helperIDs)
+	run(t, states, func(msg *messages.Message) {
+		if msg.Type == messages.MessageTypeRepair1 && msg.From == 1 {
+			msg.Repair1.Masks = msg.Repair1.Masks[1:]
+		}
+	})
+	for id, s := range states {
+		if id == 1 {
+			continue
+		}
+		if err := s.WaitForError(); !errors.As(err, &stateErr) || !errors.Is(err, ErrInvalidMasks) {
+			t.Fatalf("party %d: error = %v, want ErrInvalidMasks", id, err)
+		}
+		if !stateErr.Culprits().Equal(party.IDSlice{1}) || stateErr.Kind() != state.KindInvalidCommitment {
+			t.Errorf("party %d: culprits = %v, kind = %v", id, stateErr.Culprits(), stateErr.Kind())
+		}
+	}
+}
+
+func TestRepair_Random(t *testing.T) {
+	helperIDs := party.IDSlice{1, 3, 5}
+	seeded := func(id party.ID) []Option {
+		return []Option{WithRandom(mathrand.New(mathrand.NewSource(int64(id))))}
+	}
+
+	// The masks of a helper, and so its Repair1 message, only depend on its source of randomness
+	var sent [][]byte
+	for i := 0; i < 2; i++ {
+		states, _, _, _, _ := setupWithOptions(t, helperIDs, seeded)
+		msgs := states[1].ProcessAll()
+		if len(msgs) != 1 || msgs[0].Type != messages.MessageTypeRepair1 {
+			t.Fatalf("helper 1 sent %v", msgs)
+		}
+		data, err := msgs[0].MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sent = append(sent, data)
+	}
+	if !bytes.Equal(sent[0], sent[1]) {
+		t.Error("two helpers with the same randomness sent different masks")
+	}
+
+	// A helper whose source of randomness fails aborts before sending anything
+	for name, r := range map[string]io.Reader{
+		"empty": bytes.NewReader(nil),
+		// 100 bytes are only enough for the first mask
+		"short": bytes.NewReader(bytes.Repeat([]byte{1}, 100)),
+	} {
+		states, _, _, _, _ := setupWithOptions(t, helperIDs, func(id party.ID) []Option {
+			if id == 1 {
+				return []Option{WithRandom(r)}
+			}
+			return nil
+		})
+		if msgs := states[1].ProcessAll(); len(msgs) != 0 {
+			t.Errorf("%s: %d messages were sent", name, len(msgs))
+		}
+		var stateErr *state.Error
+		if err := states[1].WaitForError(); !errors.As(err, &stateErr) || stateErr.Culprit() != 0 || !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("%s: error = %v, want a local error", name, err)
+		}
+	}
+}
+
+func TestNewRound_Invalid(t *testing.T) {
+	_, secrets := helpers.GenerateSecrets(partyIDs, 2)
+	public := helpers.GeneratePublic(2, secrets)
+
+	for name, tc := range map[string]struct {
+		selfID  party.ID
+		secret  *eddsa.SecretShare
+		helpers party.IDSlice
+		lostID  party.ID
+	}{
+		"unknown party":   {1, secrets[1], party.IDSlice{1, 2, 3}, 11},
+		"lost is helper":  {1, secrets[1], party.IDSlice{1, 2, 3, 4}, lostID},
+		"too few":         {1, secrets[1], party.IDSlice{1, 2}, lostID},
+		"unknown helper":  {1, secrets[1], party.IDSlice{1, 2, 11}, lostID},
+		"missing secret":  {1, nil, party.IDSlice{1, 2, 3}, lostID},
+		"other secret":    {1, secrets[2], party.IDSlice{1, 2, 3}, lostID},
+		"not a party":     {5, secrets[5], party.IDSlice{1, 2, 3}, lostID},
+		"zero lost party": {1, secrets[1], party.IDSlice{1, 2, 3}, 0},
+	} {
+		if _, _, err := NewRound(tc.selfID, tc.secret, public, tc.helpers, tc.lostID); err == nil {
+			t.Errorf("%s: NewRound() should fail", name)
+		}
+	}
+}
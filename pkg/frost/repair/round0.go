@@ -0,0 +1,44 @@
+package repair
+
+import (
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+func (round *round0) ProcessMessage(*messages.Message) *state.Error {
+	return nil
+}
+
+func (round *round0) GenerateMessages() ([]*messages.Message, *state.Error) {
+	if !round.isHelper() {
+		return nil, nil
+	}
+
+	// Sample a mask for each other helper, in increasing order of their IDs, and subtract it from our contribution.
+	// Secret then holds λᵢ•sᵢ - ∑ₖ ρᵢₖ, to which the masks we receive are added in the next rounds.
+	commitments := make([]ristretto.Element, 0, len(round.Helpers)-1)
+	for _, id := range round.Helpers {
+		if id == round.SelfID() {
+			continue
+		}
+		mask := new(ristretto.Scalar)
+		round.Masks[id] = mask
+		if _, err := scalar.SetScalarRandomFrom(mask, round.Rand); err != nil {
+			return nil, state.NewError(0, fmt.Errorf("repair: failed to sample mask: %w", err))
+		}
+		round.Secret.Subtract(&round.Secret, mask)
+
+		var commitment ristretto.Element
+		commitment.ScalarBaseMult(mask)
+		commitments = append(commitments, commitment)
+	}
+	return []*messages.Message{messages.NewRepair1(round.SelfID(), commitments)}, nil
+}
+
+func (round *round0) NextRound() state.Round {
+	return &round1{round}
+}
@@ -0,0 +1,57 @@
+package repair
+
+import (
+	"errors"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// ErrInvalidMasks is returned when a Repair1 message does not contain a commitment for each other helper.
+var ErrInvalidMasks = errors.New("the number of masks differs from the number of other helpers")
+
+func (round *round1) ProcessMessage(msg *messages.Message) *state.Error {
+	from := msg.From
+	masks := msg.Repair1.Masks
+	if len(masks) != len(round.Helpers)-1 {
+		return state.NewErrorWithKind(from, state.KindInvalidCommitment, ErrInvalidMasks)
+	}
+
+	// The masks are committed to in increasing order of the other helpers
+	i := 0
+	for _, id := range round.Helpers {
+		if id == from {
+			continue
+		}
+		commitment := &masks[i]
+		i++
+		if id == round.SelfID() {
+			round.Incoming[from] = commitment
+		}
+		round.Expected[id].Add(round.Expected[id], commitment)
+		round.Expected[from].Subtract(round.Expected[from], commitment)
+	}
+	return nil
+}
+
+func (round *round1) GenerateMessages() ([]*messages.Message, *state.Error) {
+	if !round.isHelper() {
+		return nil, nil
+	}
+	msgsOut := make([]*messages.Message, 0, len(round.Helpers)-1)
+	for _, id := range round.Helpers {
+		if id == round.SelfID() {
+			continue
+		}
+		msgsOut = append(msgsOut, messages.NewKeyGen2(round.SelfID(), id, round.Masks[id]))
+	}
+	return msgsOut, nil
+}
+
+func (round *round1) NextRound() state.Round {
+	return &round2{round}
+}
+
+func (round *round1) MessageType() messages.MessageType {
+	return messages.MessageTypeRepair1
+}
@@ -0,0 +1,47 @@
+package repair
+
+import (
+	"errors"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// ErrInvalidMask is returned when the mask sent by a helper does not match its commitment in the Repair1 message.
+var ErrInvalidMask = errors.New("mask does not match its commitment")
+
+func (round *round2) ProcessMessage(msg *messages.Message) *state.Error {
+	from := msg.From
+	mask := &msg.KeyGen2.Share
+
+	var commitment ristretto.Element
+	commitment.ScalarBaseMult(mask)
+	if commitment.Equal(round.Incoming[from]) != 1 {
+		return state.NewErrorWithKind(from, state.KindVSSFailure, ErrInvalidMask)
+	}
+	round.Secret.Add(&round.Secret, mask)
+	return nil
+}
+
+func (round *round2) GenerateMessages() ([]*messages.Message, *state.Error) {
+	if !round.isHelper() {
+		return nil, nil
+	}
+
+	// All masks were sent and received, so Secret is the value vᵢ expected by the party recovering its share
+	share := round.Secret
+	round.Secret.Set(ristretto.NewScalar())
+	for _, mask := range round.Masks {
+		mask.Set(ristretto.NewScalar())
+	}
+	return []*messages.Message{messages.NewRepair2(round.SelfID(), round.LostID, &share)}, nil
+}
+
+func (round *round2) NextRound() state.Round {
+	return &round3{round}
+}
+
+func (round *round2) MessageType() messages.MessageType {
+	return messages.MessageTypeKeyGen2
+}
@@ -0,0 +1,52 @@
+package repair
+
+import (
+	"errors"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// ErrInvalidRepair is returned when the value sent by a helper in its Repair2 message
+// does not match its contribution and the commitments to its masks.
+var ErrInvalidRepair = errors.New("repair share does not match the commitments")
+
+func (round *round3) ProcessMessage(msg *messages.Message) *state.Error {
+	from := msg.From
+	share := &msg.Repair2.Share
+
+	var computedShareExp ristretto.Element
+	computedShareExp.ScalarBaseMult(share)
+	if computedShareExp.Equal(round.Expected[from]) != 1 {
+		return state.NewErrorWithKind(from, state.KindVSSFailure, ErrInvalidRepair)
+	}
+	round.Secret.Add(&round.Secret, share)
+	return nil
+}
+
+func (round *round3) GenerateMessages() ([]*messages.Message, *state.Error) {
+	round.Output.Public = round.Public
+	if !round.isLostParty() {
+		return nil, nil
+	}
+
+	// This holds since each value was checked, unless public was not consistent.
+	var publicShare ristretto.Element
+	publicShare.ScalarBaseMult(&round.Secret)
+	if publicShare.Equal(round.Public.Shares[round.LostID]) != 1 {
+		return nil, state.NewError(0, errors.New("the recovered share differs from the public share"))
+	}
+	round.Output.SecretKey = eddsa.NewSecretShare(round.SelfID(), &round.Secret)
+	round.Output.SecretKey.SetGroup(round.Public.GroupKey)
+	return nil, nil
+}
+
+func (round *round3) NextRound() state.Round {
+	return nil
+}
+
+func (round *round3) MessageType() messages.MessageType {
+	return messages.MessageTypeRepair2
+}
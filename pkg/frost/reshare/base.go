@@ -0,0 +1,178 @@
+// Package reshare implements the resharing of a key between a new set of parties with a new threshold,
+// without changing the group key.
+//
+// The dealers are at least threshold+1 parties holding a share sᵢ of the key.
+// Each dealer i multiplies its share by its Lagrange coefficient λᵢ over the dealers, so that ∑ λᵢ•sᵢ = s,
+// and shares wᵢ = λᵢ•sᵢ with a polynomial fᵢ of the new threshold, as in the keygen protocol.
+// Since the public share Aᵢ of each dealer is known, the other parties check that the commitment to fᵢ(0) is λᵢ•Aᵢ.
+// Each new party j then verifies the shares fᵢ(j) against the commitments, and its new share is ∑ fᵢ(j).
+//
+// The protocol reuses the KeyGen1 and KeyGen2 messages: KeyGen1 messages are only sent by the dealers,
+// and KeyGen2 messages only to the new parties.
+// The dealers may also be new parties, and the new parties do not need a share of the key.
+// Dealers which are not new parties have nothing to keep once the protocol has finished.
+//
+// NewRemovalRound reshares the key between the remaining parties of the committee with the same threshold,
+// so that the shares of the removed parties no longer lie on the polynomial of the new shares.
+package reshare
+
+import (
+	"errors"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+type (
+	round0 struct {
+		*state.BaseRound
+
+		// Threshold is the degree of the polynomial used to share the key between the new parties.
+		Threshold party.Size
+
+		// Dealers are the parties resharing their share, and NewPartyIDs the parties receiving the new shares.
+		Dealers     party.IDSlice
+		NewPartyIDs party.IDSlice
+
+		// GroupKey is the key which is reshared.
+		GroupKey *eddsa.PublicKey
+
+		// DealerShares maps each dealer i to λᵢ•Aᵢ, the commitment to the constant of its polynomial.
+		DealerShares map[party.ID]*ristretto.Element
+
+		// Secret is first set to our share multiplied by our Lagrange coefficient, if we are a dealer.
+		// If we are a new party, it is then set to the sum of all shares received, which is our new share.
+		Secret ristretto.Scalar
+
+		// Polynomial used to sample shares, if we are a dealer
+		Polynomial *polynomial.Polynomial
+
+		// Commitments contains the commitment polynomials of all dealers, including ours.
+		Commitments map[party.ID]*polynomial.Exponent
+
+		// CommitmentsSum is the sum of all commitments, from which the new public shares are computed.
+		CommitmentsSum *polynomial.Exponent
+
+		Output *Output
+	}
+	round1 struct {
+		*round0
+	}
+	round2 struct {
+		*round1
+	}
+)
+
+// NewRound returns the first round of the resharing of the key of public by dealers to newPartyIDs,
+// and the Output which is filled once it has finished.
+//
+// dealers must contain at least public.Threshold+1 of public.PartyIDs, and threshold is the new threshold,
+// between 1 and newPartyIDs.N()-1, or the error is a *party.ThresholdError.
+// All parties must know public, and secret is our share if we are a dealer, or nil otherwise. The parties of the protocol are the dealers and the new parties.
+func NewRound(selfID party.ID, secret *eddsa.SecretShare, public *eddsa.Public, dealers, newPartyIDs party.IDSlice, threshold party.Size) (state.Round, *Output, error) {
+	dealers = party.NewIDSlice(dealers)
+	newPartyIDs = party.NewIDSlice(newPartyIDs)
+
+	if err := party.ValidateThreshold(threshold, newPartyIDs.N()); err != nil {
+		return nil, nil, err
+	}
+	if newPartyIDs.Contains(0) {
+		return nil, nil, party.ErrZeroID
+	}
+	if !dealers.IsSubsetOf(public.PartyIDs) {
+		return nil, nil, errors.New("the dealers must be parties of the key")
+	}
+	if dealers.N() < public.Threshold+1 {
+		return nil, nil, errors.New("there must be at least T+1 dealers")
+	}
+
+	baseRound, err := state.NewBaseRound(selfID, dealers.Union(newPartyIDs))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	coefficients, err := dealers.LagrangeAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	r := round0{
+		BaseRound:    baseRound,
+		Threshold:    threshold,
+		Dealers:      dealers,
+		NewPartyIDs:  newPartyIDs,
+		GroupKey:     public.GroupKey,
+		DealerShares: make(map[party.ID]*ristretto.Element, dealers.N()),
+		Commitments:  make(map[party.ID]*polynomial.Exponent, dealers.N()),
+		Output:       &Output{},
+	}
+	for _, id := range dealers {
+		r.DealerShares[id] = new(ristretto.Element).ScalarMult(coefficients[id], public.Shares[id])
+	}
+
+	if dealers.Contains(selfID) {
+		if secret == nil || secret.ID != selfID {
+			return nil, nil, errors.New("the secret share of a dealer must be given")
+		}
+		if err = secret.Validate(public); err != nil {
+			return nil, nil, err
+		}
+		r.Secret.Multiply(coefficients[selfID], &secret.Secret)
+	}
+	return &r, r.Output, nil
+}
+
+// Reset is called by the State when the protocol finishes or is aborted.
+// It zeroes the secret Secret, which holds our new share once the protocol finished,
+// and the coefficients of our Polynomial, and sets the commitments to the identity.
+// The SecretShare of the Output is a copy and is not wiped.
+func (round *round0) Reset() {
+	round.Secret.Set(ristretto.NewScalar())
+	if round.Polynomial != nil {
+		round.Polynomial.Reset()
+	}
+	if round.CommitmentsSum != nil {
+		round.CommitmentsSum.Reset()
+	}
+	for _, p := range round.Commitments {
+		p.Reset()
+	}
+	round.Output = nil
+}
+
+// isDealer returns true if we reshare our share.
+func (round *round0) isDealer() bool {
+	return round.Dealers.Contains(round.SelfID())
+}
+
+// isNewParty returns true if we receive a new share.
+func (round *round0) isNewParty() bool {
+	return round.NewPartyIDs.Contains(round.SelfID())
+}
+
+// ---
+// Messages
+// ---
+
+func (round *round0) AcceptedMessageTypes() []messages.MessageType {
+	return []messages.MessageType{messages.MessageTypeNone, messages.MessageTypeKeyGen1, messages.MessageTypeKeyGen2}
+}
+
+// Senders implements state.SenderFilter, since only the dealers send messages,
+// and KeyGen2 messages are only sent to the new parties.
+func (round *round0) Senders(msgType messages.MessageType) party.IDSlice {
+	if msgType == messages.MessageTypeKeyGen2 && !round.isNewParty() {
+		return party.IDSlice{}
+	}
+	return round.Dealers
+}
+
+// MessageLimits implements state.Limiter, since the commitments in KeyGen1 messages depend on the threshold.
+func (round *round0) MessageLimits() messages.Limits {
+	return messages.Limits{
+		Threshold: round.Threshold,
+	}
+}
@@ -0,0 +1,25 @@
+package reshare
+
+import (
+	"errors"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+)
+
+// Output contains the new public shares, and our new share if we are one of the new parties.
+// Dealers which are not new parties only obtain Public, and SecretKey is nil.
+type Output struct {
+	Public    *eddsa.Public
+	SecretKey *eddsa.SecretShare
+}
+
+// KeyShare returns a copy of the output as an eddsa.KeyShare, once the protocol has finished.
+func (o *Output) KeyShare() (*eddsa.KeyShare, error) {
+	if o.Public == nil {
+		return nil, errors.New("reshare.Output: the protocol has not finished")
+	}
+	if o.SecretKey == nil {
+		return nil, errors.New("reshare.Output: the party is not one of the new parties")
+	}
+	return eddsa.NewKeyShare(o.SecretKey.Copy(), o.Public.Copy())
+}
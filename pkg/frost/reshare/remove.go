@@ -0,0 +1,42 @@
+package reshare
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// ErrTooFewSurvivors is returned by NewRemovalRound when removing the parties would leave at most Threshold parties,
+// which could no longer sign.
+var ErrTooFewSurvivors = errors.New("at least T+1 parties must remain after the removal")
+
+// NewRemovalRound returns the first round of the removal of the parties removed from the key of key,
+// and the Output which is filled once it has finished.
+//
+// All remaining parties reshare the key between themselves with the same threshold, so that their shares lie
+// on a new random polynomial: the shares of the removed parties, which do not take part, become useless.
+// The remaining parties must all run the protocol with the same removed parties, and delete their old share
+// once they have stored the new one, since the old shares can still be combined with the removed ones.
+// The Output contains the public shares of the remaining parties, for the same group key and threshold.
+func NewRemovalRound(key *eddsa.KeyShare, removed party.IDSlice) (state.Round, *Output, error) {
+	removed = party.NewIDSlice(removed)
+	public := key.Public
+
+	if removed.N() == 0 {
+		return nil, nil, errors.New("no party to remove")
+	}
+	if !removed.IsSubsetOf(public.PartyIDs) {
+		return nil, nil, errors.New("the removed parties must be parties of the key")
+	}
+	if removed.Contains(key.ID()) {
+		return nil, nil, fmt.Errorf("party %d cannot take part in its own removal", key.ID())
+	}
+	survivors := public.PartyIDs.Difference(removed)
+	if survivors.N() <= public.Threshold {
+		return nil, nil, fmt.Errorf("%w: %d would remain", ErrTooFewSurvivors, survivors.N())
+	}
+	return NewRound(key.ID(), key.Secret, public, survivors, survivors, public.Threshold)
+}
@@ -0,0 +1,101 @@
+package reshare
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+func TestRemoval(t *testing.T) {
+	secret, secrets := helpers.GenerateSecrets(oldIDs, 2)
+	public := helpers.GeneratePublic(2, secrets)
+	removed := party.IDSlice{2}
+	survivors := party.IDSlice{1, 3, 4, 5}
+
+	states := make(map[party.ID]*state.State, survivors.N())
+	outputs := make(map[party.ID]*Output, survivors.N())
+	for _, id := range survivors {
+		key, err := eddsa.NewKeyShare(secrets[id], public)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, output, err := NewRemovalRound(key, removed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[id] = output
+		if states[id], err = state.NewBaseState(r, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run(t, states, nil)
+
+	newSecrets := make(map[party.ID]*eddsa.SecretShare, survivors.N())
+	for _, id := range survivors {
+		if err := states[id].WaitForError(); err != nil {
+			t.Fatalf("party %d: %v", id, err)
+		}
+		newSecrets[id] = outputs[id].SecretKey
+	}
+	newPublic := outputs[1].Public
+	if !newPublic.PartyIDs.Equal(survivors) || newPublic.Threshold != public.Threshold {
+		t.Fatalf("the new committee is %d-of-%v", newPublic.Threshold+1, newPublic.PartyIDs)
+	}
+	if !newPublic.GroupKey.Equal(public.GroupKey) {
+		t.Fatal("the group key changed")
+	}
+
+	// The remaining parties can still reconstruct the key from their new shares
+	if _, err := eddsa.Reconstruct(map[party.ID]*eddsa.SecretShare{1: newSecrets[1], 4: newSecrets[4], 5: newSecrets[5]}, newPublic); err != nil {
+		t.Fatal(err)
+	}
+
+	// The share of party 2, combined with the new shares of 2 other parties, interpolates to another key
+	ids := party.IDSlice{2, 3, 5}
+	coefficients, err := ids.LagrangeAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares := map[party.ID]*eddsa.SecretShare{2: secrets[2], 3: newSecrets[3], 5: newSecrets[5]}
+	var interpolated, term ristretto.Scalar
+	for _, id := range ids {
+		interpolated.Add(&interpolated, term.Multiply(coefficients[id], &shares[id].Secret))
+	}
+	if interpolated.Equal(secret) == 1 {
+		t.Error("the share of the removed party is still valid")
+	}
+	if _, err = eddsa.Reconstruct(shares, newPublic); err == nil {
+		t.Error("Reconstruct() should reject the share of the removed party")
+	}
+}
+
+func TestNewRemovalRound_Invalid(t *testing.T) {
+	_, secrets := helpers.GenerateSecrets(oldIDs, 2)
+	public := helpers.GeneratePublic(2, secrets)
+	key, err := eddsa.NewKeyShare(secrets[1], public)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, removed := range map[string]party.IDSlice{
+		"nobody":        {},
+		"unknown party": {11},
+		"ourselves":     {1, 2},
+	} {
+		if _, _, err = NewRemovalRound(key, removed); err == nil {
+			t.Errorf("%s: NewRemovalRound() should fail", name)
+		}
+	}
+	// Only 2 parties would remain, which is the threshold
+	if _, _, err = NewRemovalRound(key, party.IDSlice{3, 4, 5}); !errors.Is(err, ErrTooFewSurvivors) {
+		t.Errorf("NewRemovalRound() error = %v, want ErrTooFewSurvivors", err)
+	}
+	if _, _, err = NewRemovalRound(key, party.IDSlice{4, 5}); err != nil {
+		t.Errorf("NewRemovalRound() with T+1 remaining parties: %v", err)
+	}
+}
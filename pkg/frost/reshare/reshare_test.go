@@ -0,0 +1,199 @@
+package reshare
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/sign"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+var (
+	oldIDs       = party.IDSlice{1, 2, 3, 4, 5}
+	newIDs       = party.IDSlice{4, 5, 6, 7, 8, 9, 10}
+	newThreshold = party.Size(3)
+)
+
+// setup returns the states and rounds of the resharing from a 3-of-5 to a 4-of-7 committee, as well as the old public shares.
+func setup(t *testing.T, dealers party.IDSlice) (map[party.ID]*state.State, map[party.ID]*round0, map[party.ID]*Output, *eddsa.Public) {
+	_, secrets := helpers.GenerateSecrets(oldIDs, 2)
+	public := helpers.GeneratePublic(2, secrets)
+
+	partyIDs := dealers.Union(newIDs)
+	states := make(map[party.ID]*state.State, partyIDs.N())
+	rounds := make(map[party.ID]*round0, partyIDs.N())
+	outputs := make(map[party.ID]*Output, partyIDs.N())
+	for _, id := range partyIDs {
+		var secret *eddsa.SecretShare
+		if dealers.Contains(id) {
+			secret = secrets[id]
+		}
+		r, output, err := NewRound(id, secret, public, dealers, newIDs, newThreshold)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rounds[id], outputs[id] = r.(*round0), output
+		if states[id], err = state.NewBaseState(r, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return states, rounds, outputs, public
+}
+
+// run executes the protocol with helpers.RunProtocol.
+func run(t *testing.T, states map[party.ID]*state.State, tamper func(*messages.Message)) {
+	if err := helpers.RunProtocol(states, tamper); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReshare(t *testing.T) {
+	for _, dealers := range []party.IDSlice{oldIDs, {1, 3, 5}} {
+		states, rounds, outputs, public := setup(t, dealers)
+		run(t, states, nil)
+
+		var newPublic *eddsa.Public
+		for id, s := range states {
+			if err := s.WaitForError(); err != nil {
+				t.Fatalf("party %d: %v", id, err)
+			}
+			output := outputs[id]
+			if newPublic == nil {
+				newPublic = output.Public
+			} else if !newPublic.Equal(output.Public) {
+				t.Fatalf("party %d: the new public shares are different", id)
+			}
+			if !newIDs.Contains(id) {
+				if output.SecretKey != nil {
+					t.Errorf("party %d is not a new party, but obtained a share", id)
+				}
+				if _, err := output.KeyShare(); err == nil {
+					t.Errorf("party %d: KeyShare() should fail", id)
+				}
+				continue
+			}
+			if err := output.SecretKey.Validate(newPublic); err != nil {
+				t.Errorf("party %d: %v", id, err)
+			}
+			if rounds[id].Secret.Equal(scalar.NewScalarUInt32(0)) != 1 {
+				t.Errorf("party %d: the secret of the round was not zeroed", id)
+			}
+		}
+
+		if !newPublic.GroupKey.Equal(public.GroupKey) {
+			t.Fatal("the group key changed")
+		}
+		if newPublic.Threshold != newThreshold || !newPublic.PartyIDs.Equal(newIDs) {
+			t.Fatalf("the new committee is %d-of-%v", newPublic.Threshold+1, newPublic.PartyIDs)
+		}
+		if err := newPublic.Validate(); err != nil {
+			t.Fatal(err)
+		}
+
+		// 4 of the new parties, among which one was not a party of the key, sign for the unchanged group key
+		signers := party.IDSlice{4, 6, 8, 10}
+		message := []byte("signed by the new committee")
+		signStates := make(map[party.ID]*state.State, signers.N())
+		signOutputs := make(map[party.ID]*sign.Output, signers.N())
+		for _, id := range signers {
+			r, output, err := sign.NewRound(signers, outputs[id].SecretKey, newPublic, message)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signOutputs[id] = output
+			if signStates[id], err = state.NewBaseState(r, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+		run(t, signStates, nil)
+		for _, id := range signers {
+			if err := signStates[id].WaitForError(); err != nil {
+				t.Fatal(err)
+			}
+			if !ed25519.Verify(public.GroupKeyEd25519(), message, signOutputs[id].Signature.ToEd25519()) {
+				t.Errorf("party %d: the signature is invalid under the old group key", id)
+			}
+		}
+	}
+}
+
+func TestReshare_CheatingDealer(t *testing.T) {
+	// Dealer 2 sends a wrong share to party 6, which aborts and blames it
+	states, _, _, _ := setup(t, oldIDs)
+	run(t, states, func(msg *messages.Message) {
+		if msg.Type == messages.MessageTypeKeyGen2 && msg.From == 2 && msg.To == 6 {
+			msg.KeyGen2.Share.Add(&msg.KeyGen2.Share, scalar.NewScalarUInt32(1))
+		}
+	})
+	var stateErr *state.Error
+	if err := states[6].WaitForError(); !errors.As(err, &stateErr) || !errors.Is(err, ErrValidateShare) {
+		t.Fatalf("party 6: error = %v, want ErrValidateShare", err)
+	}
+	if !stateErr.Culprits().Equal(party.IDSlice{2}) || stateErr.Kind() != state.KindVSSFailure {
+		t.Errorf("party 6: culprits = %v, kind = %v", stateErr.Culprits(), stateErr.Kind())
+	}
+
+	// Dealer 3 reshares another secret than its share, and all other parties blame it
+	states, rounds, _, _ := setup(t, oldIDs)
+	scalar.SetScalarRandom(&rounds[3].Secret)
+	run(t, states, nil)
+	for id, s := range states {
+		if id == 3 {
+			continue
+		}
+		err := s.WaitForError()
+		if !errors.As(err, &stateErr) || !errors.Is(err, ErrInvalidCommitment) {
+			t.Fatalf("party %d: error = %v, want ErrInvalidCommitment", id, err)
+		}
+		if !stateErr.Culprits().Equal(party.IDSlice{3}) || stateErr.Kind() != state.KindInvalidCommitment {
+			t.Errorf("party %d: culprits = %v, kind = %v", id, stateErr.Culprits(), stateErr.Kind())
+		}
+	}
+}
+
+func TestReshare_UnexpectedSender(t *testing.T) {
+	states, _, _, _ := setup(t, oldIDs)
+	states[1].ProcessAll()
+
+	// Party 6 is not a dealer, so it cannot send commitments
+	msg := states[2].ProcessAll()[0]
+	msg.From = 6
+	if err := states[1].HandleMessage(msg); !errors.Is(err, state.ErrUnexpectedSender) {
+		t.Errorf("HandleMessage() error = %v, want ErrUnexpectedSender", err)
+	}
+	if missing, _ := states[1].MissingFrom(); !missing.Equal(party.IDSlice{2, 3, 4, 5}) {
+		t.Errorf("MissingFrom() = %v, want the other dealers", missing)
+	}
+}
+
+func TestNewRound_Invalid(t *testing.T) {
+	_, secrets := helpers.GenerateSecrets(oldIDs, 2)
+	public := helpers.GeneratePublic(2, secrets)
+
+	for name, tc := range map[string]struct {
+		selfID    party.ID
+		secret    *eddsa.SecretShare
+		dealers   party.IDSlice
+		newIDs    party.IDSlice
+		threshold party.Size
+	}{
+		"zero threshold":     {1, secrets[1], oldIDs, newIDs, 0},
+		"threshold too high": {1, secrets[1], oldIDs, newIDs, 7},
+		"too few dealers":    {1, secrets[1], party.IDSlice{1, 2}, newIDs, 3},
+		"unknown dealer":     {1, secrets[1], party.IDSlice{1, 2, 11}, newIDs, 3},
+		"zero ID":            {1, secrets[1], oldIDs, party.IDSlice{0, 6, 7, 8}, 3},
+		"missing secret":     {1, nil, oldIDs, newIDs, 3},
+		"other secret":       {1, secrets[2], oldIDs, newIDs, 3},
+		"not a party":        {11, nil, oldIDs, newIDs, 3},
+	} {
+		if _, _, err := NewRound(tc.selfID, tc.secret, public, tc.dealers, tc.newIDs, tc.threshold); err == nil {
+			t.Errorf("%s: NewRound() should fail", name)
+		}
+	}
+}
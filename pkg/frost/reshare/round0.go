@@ -0,0 +1,42 @@
+package reshare
+
+import (
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/zk"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+func (round *round0) ProcessMessage(*messages.Message) *state.Error {
+	return nil
+}
+
+func (round *round0) GenerateMessages() ([]*messages.Message, *state.Error) {
+	if !round.isDealer() {
+		return nil, nil
+	}
+
+	// Share λᵢ•sᵢ with a polynomial of degree t', and commit to it
+	round.Polynomial = polynomial.NewPolynomial(round.Threshold, &round.Secret)
+	commitments := polynomial.NewPolynomialExponent(round.Polynomial)
+	round.Commitments[round.SelfID()] = commitments
+
+	// The session ID is used as context, to prevent the proof from being replayed in another execution
+	sessionID := round.SessionID()
+	proof := zk.NewSchnorrProof(round.SelfID(), commitments.Constant(), sessionID[:], &round.Secret)
+
+	// As in the keygen, Secret now holds the sum of the shares we receive, starting with our own.
+	// This overwrites λᵢ•sᵢ, which is no longer needed.
+	if round.isNewParty() {
+		round.Secret.Set(round.Polynomial.Evaluate(round.SelfID().Scalar()))
+	} else {
+		round.Secret.Set(ristretto.NewScalar())
+	}
+
+	return []*messages.Message{messages.NewKeyGen1(round.SelfID(), proof, commitments.Copy())}, nil
+}
+
+func (round *round0) NextRound() state.Round {
+	return &round1{round}
+}
@@ -0,0 +1,71 @@
+package reshare
+
+import (
+	"errors"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+var (
+	ErrValidateProof = errors.New("ZK Schnorr failed")
+
+	// ErrInvalidCommitment is returned when the commitment of a dealer to the constant of its polynomial
+	// is not its public share multiplied by its Lagrange coefficient, so that it does not reshare its share.
+	ErrInvalidCommitment = errors.New("commitment does not match the public share of the dealer")
+)
+
+func (round *round1) ProcessMessage(msg *messages.Message) *state.Error {
+	sessionID := round.SessionID()
+	from := msg.From
+
+	public := msg.KeyGen1.Commitments.Constant()
+	if !msg.KeyGen1.Proof.Verify(from, public, sessionID[:]) {
+		return state.NewErrorWithKind(from, state.KindInvalidProof, ErrValidateProof)
+	}
+	if public.Equal(round.DealerShares[from]) != 1 {
+		return state.NewErrorWithKind(from, state.KindInvalidCommitment, ErrInvalidCommitment)
+	}
+
+	round.Commitments[from] = msg.KeyGen1.Commitments
+	return nil
+}
+
+func (round *round1) GenerateMessages() ([]*messages.Message, *state.Error) {
+	// The commitments are summed in the order of the dealers, and their constant is the group key.
+	commitments := make([]*polynomial.Exponent, 0, len(round.Dealers))
+	for _, id := range round.Dealers {
+		commitments = append(commitments, round.Commitments[id])
+	}
+	sum, err := polynomial.Sum(commitments)
+	if err != nil {
+		return nil, state.NewError(0, err)
+	}
+	round.CommitmentsSum = sum
+
+	if !round.isDealer() {
+		return nil, nil
+	}
+	msgsOut := make([]*messages.Message, 0, len(round.NewPartyIDs))
+	var x ristretto.Scalar
+	for _, id := range round.NewPartyIDs {
+		if id == round.SelfID() {
+			continue
+		}
+		msgsOut = append(msgsOut, messages.NewKeyGen2(round.SelfID(), id, round.Polynomial.Evaluate(id.ScalarTo(&x))))
+	}
+
+	// All shares were sent, so we no longer require the polynomial
+	round.Polynomial.Reset()
+	return msgsOut, nil
+}
+
+func (round *round1) NextRound() state.Round {
+	return &round2{round}
+}
+
+func (round *round1) MessageType() messages.MessageType {
+	return messages.MessageTypeKeyGen1
+}
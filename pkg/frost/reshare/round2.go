@@ -0,0 +1,61 @@
+package reshare
+
+import (
+	"errors"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+var ErrValidateShare = errors.New("VSS failed to validate")
+
+func (round *round2) ProcessMessage(msg *messages.Message) *state.Error {
+	id := msg.From
+	share := &msg.KeyGen2.Share
+
+	var computedShareExp ristretto.Element
+	computedShareExp.ScalarBaseMult(share)
+
+	shareExp := round.Commitments[id].Evaluate(round.SelfID().Scalar())
+
+	if computedShareExp.Equal(shareExp) != 1 {
+		return state.NewErrorWithKind(id, state.KindVSSFailure, ErrValidateShare)
+	}
+	round.Secret.Add(&round.Secret, share)
+	return nil
+}
+
+func (round *round2) GenerateMessages() ([]*messages.Message, *state.Error) {
+	// This holds since the constant of each dealer's commitments was checked, unless public was not consistent.
+	if round.CommitmentsSum.Constant().Equal(round.GroupKey.Point()) != 1 {
+		return nil, state.NewError(0, errors.New("the reshared key differs from the group key"))
+	}
+
+	shares := make(map[party.ID]*ristretto.Element, round.NewPartyIDs.N())
+	scalars := round.NewPartyIDs.Scalars()
+	for i, id := range round.NewPartyIDs {
+		shares[id] = round.CommitmentsSum.Evaluate(&scalars[i])
+	}
+	round.Output.Public = &eddsa.Public{
+		PartyIDs:  round.NewPartyIDs.Copy(),
+		Threshold: round.Threshold,
+		Shares:    shares,
+		GroupKey:  eddsa.NewPublicKeyFromPoint(round.CommitmentsSum.Constant()),
+	}
+	if round.isNewParty() {
+		round.Output.SecretKey = eddsa.NewSecretShare(round.SelfID(), &round.Secret)
+		round.Output.SecretKey.SetGroup(round.Output.Public.GroupKey)
+	}
+	return nil, nil
+}
+
+func (round *round2) NextRound() state.Round {
+	return nil
+}
+
+func (round *round2) MessageType() messages.MessageType {
+	return messages.MessageTypeKeyGen2
+}
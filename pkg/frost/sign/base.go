@@ -3,6 +3,7 @@ package sign
 import (
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
@@ -22,12 +23,48 @@ type (
 		Parties map[party.ID]*signer
 
 		// GroupKey is the GroupKey, i.e. the public key associated to the group of signers.
-		GroupKey       eddsa.PublicKey
+		GroupKey eddsa.PublicKey
+
+		// SecretKeyShare is a copy of our secret key share, used by the default ShareSigner.
 		SecretKeyShare ristretto.Scalar
 
-		// e and d are the scalars committed to in the first round
+		// Lambda is our Lagrange coefficient for the signers.
+		Lambda ristretto.Scalar
+
+		// e and d are the scalars committed to in the first round, when they are sampled by the default ShareSigner.
 		e, d ristretto.Scalar
 
+		// Signer computes our commitments and signature share.
+		Signer ShareSigner
+
+		// Tweak is added to the group key when it is set by WithTweak.
+		Tweak *ristretto.Scalar
+
+		// Prehashed is set by WithPrehash, in which case Message is the SHA-512 digest of the message.
+		Prehashed bool
+
+		// Context is the Ed25519ctx context set by WithContext.
+		Context []byte
+
+		// VerifyPolicy is the policy under which the signature is checked before it is output, set by WithVerifyPolicy.
+		VerifyPolicy eddsa.VerifyPolicy
+
+		// Rand is the source of the nonces d and e, as set by WithRandom. crypto/rand is used if it is nil.
+		Rand io.Reader
+
+		// Guard is the NonceGuard to which our nonces are bound, as set by WithNonceGuard. DefaultNonceGuard is used if it is nil.
+		Guard *NonceGuard
+
+		// Preprocessed is set by NewOnlineRound, in which case the binding of our nonces is recorded in the
+		// BindingStore of the Guard.
+		Preprocessed bool
+
+		// bound is set once our nonces were bound to the session by the Guard.
+		bound *boundNonces
+
+		// Precomputed holds the Lagrange coefficients and the public shares 𝛌ᵢ•Aᵢ of the signers, when set by WithPrecomputed.
+		Precomputed *eddsa.Precomputed
+
 		// C = H(R, GroupKey, Message)
 		C ristretto.Scalar
 		// R = ∑ Ri
@@ -43,17 +80,52 @@ type (
 	}
 )
 
-func NewRound(partyIDs party.IDSlice, secret *eddsa.SecretShare, shares *eddsa.Public, message []byte) (state.Round, *Output, error) {
+// NewRound returns the first round of the signing protocol between partyIDs.
+// If there are fewer than shares.MinSigners() signers, or more than shares.PartyIDs, the error is a *SignerCountError.
+// If some of partyIDs did not take part in the key generation of shares, the error is an *UnknownSignersError.
+// If secret does not belong to shares, the error is an *eddsa.ShareMismatchError,
+// instead of the signature share of this party being rejected by the others in the last round.
+// The options opts must be the same for all signers.
+func NewRound(partyIDs party.IDSlice, secret *eddsa.SecretShare, shares *eddsa.Public, message []byte, opts ...Option) (state.Round, *Output, error) {
+	partyIDs = party.NewIDSlice(partyIDs)
 	if !partyIDs.Contains(secret.ID) {
 		return nil, nil, errors.New("base.NewRound: owner of SecretShare is not contained in partyIDs")
 	}
+
+	round, coefficients, err := newRound(secret.ID, partyIDs, shares, message, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("base.NewRound: %w", err)
+	}
+	if err = secret.Validate(shares); err != nil {
+		return nil, nil, fmt.Errorf("base.NewRound: %w", err)
+	}
+
+	round.SecretKeyShare.Set(&secret.Secret)
+	round.Lambda.Set(coefficients[round.SelfID()])
+	round.Signer = localSigner{round}
+	round.applyTweak()
+
+	return round, round.Output, nil
+}
+
+// NewRoundFromKeyShare is like NewRound, with the secret and public shares of key.
+func NewRoundFromKeyShare(partyIDs party.IDSlice, key *eddsa.KeyShare, message []byte, opts ...Option) (state.Round, *Output, error) {
+	return NewRound(partyIDs, key.Secret, key.Public, message, opts...)
+}
+
+// newRound returns a round0 for selfID without its secret share, and the Lagrange coefficients of partyIDs.
+// It contains the public values of the session, and is also used by NewTranscript.
+func newRound(selfID party.ID, partyIDs party.IDSlice, shares *eddsa.Public, message []byte, opts []Option) (*round0, map[party.ID]*ristretto.Scalar, error) {
+	if n := partyIDs.N(); n < shares.MinSigners() || n > shares.PartyIDs.N() {
+		return nil, nil, &SignerCountError{Signers: n, MinSigners: shares.MinSigners(), MaxSigners: shares.PartyIDs.N()}
+	}
 	if !partyIDs.IsSubsetOf(shares.PartyIDs) {
-		return nil, nil, errors.New("base.NewRound: not all parties of partyIDs are contained in shares")
+		return nil, nil, &UnknownSignersError{IDs: partyIDs.Difference(shares.PartyIDs)}
 	}
 
-	baseRound, err := state.NewBaseRound(secret.ID, partyIDs)
+	baseRound, err := state.NewBaseRound(selfID, partyIDs)
 	if err != nil {
-		return nil, nil, fmt.Errorf("base.NewRound: %w", err)
+		return nil, nil, err
 	}
 
 	round := &round0{
@@ -64,42 +136,77 @@ func NewRound(partyIDs party.IDSlice, secret *eddsa.SecretShare, shares *eddsa.P
 		Output:    &Output{},
 	}
 
-	// Setup parties
-	for _, id := range partyIDs {
-		var s signer
-		if id == 0 {
-			return nil, nil, errors.New("base.NewRound: id 0 is not valid")
+	for _, opt := range opts {
+		opt(round)
+	}
+
+	var coefficients map[party.ID]*ristretto.Scalar
+	if round.Precomputed != nil {
+		if !round.Precomputed.Matches(partyIDs, shares) {
+			return nil, nil, ErrPrecomputedMismatch
+		}
+		coefficients = make(map[party.ID]*ristretto.Scalar, partyIDs.N())
+		for _, id := range partyIDs {
+			coefficients[id] = round.Precomputed.Coefficient(id)
+			round.Parties[id] = &signer{Public: *round.Precomputed.Share(id)}
 		}
-		originalShare := shares.Shares[id]
-		lagrange, err := id.Lagrange(partyIDs)
-		if err != nil {
-			return nil, nil, fmt.Errorf("base.NewRound: %w", err)
+	} else {
+		// LagrangeAll also rejects the ID 0
+		if coefficients, err = partyIDs.LagrangeAll(); err != nil {
+			return nil, nil, err
+		}
+
+		// Setup parties
+		for _, id := range partyIDs {
+			var s signer
+			originalShare := shares.Shares[id]
+			s.Public.ScalarMult(coefficients[id], originalShare)
+			round.Parties[id] = &s
 		}
-		s.Public.ScalarMult(lagrange, originalShare)
-		round.Parties[id] = &s
 	}
 
-	// Normalize secret share so that we can assume we are dealing with an additive sharing
-	lagrange, err := round.SelfID().Lagrange(partyIDs)
-	if err != nil {
-		return nil, nil, fmt.Errorf("base.NewRound: %w", err)
+	if err = round.validatePolicy(); err != nil {
+		return nil, nil, err
+	}
+	if round.Prehashed && len(message) != eddsa.PrehashSize {
+		return nil, nil, fmt.Errorf("the pre-hashed message has %d bytes instead of %d", len(message), eddsa.PrehashSize)
+	}
+	if round.Context != nil {
+		if err = eddsa.ValidateContext(round.Context); err != nil {
+			return nil, nil, err
+		}
+		if round.Prehashed {
+			return nil, nil, errors.New("WithContext cannot be combined with WithPrehash")
+		}
 	}
-	round.SecretKeyShare.Multiply(lagrange, &secret.Secret)
 
-	return round, round.Output, nil
+	return round, coefficients, nil
 }
 
+// Reset is called by the State when the protocol finishes or is aborted.
+// It zeroes the copy of our SecretKeyShare, the nonces d and e, the tweak, and the
+// intermediate values of all signers, including our signature share.
+// The SecretShare given to NewRound belongs to the caller and is not wiped.
 func (round *round0) Reset() {
 	zero := ristretto.NewScalar()
 	one := ristretto.NewIdentityElement()
 
 	round.Message = nil
+	round.Precomputed = nil
+	round.Rand = nil
+	round.releaseNonces()
+	round.Guard = nil
 	round.SecretKeyShare.Set(zero)
+	round.Lambda.Set(zero)
+	round.Signer = nil
 
 	round.e.Set(zero)
 	round.d.Set(zero)
 	round.C.Set(zero)
 	round.R.Set(one)
+	if round.Tweak != nil {
+		round.Tweak.Set(zero)
+	}
 
 	for id, p := range round.Parties {
 		p.Reset()
@@ -115,3 +222,13 @@ func (round *round0) AcceptedMessageTypes() []messages.MessageType {
 		messages.MessageTypeSign2,
 	}
 }
+
+// ErrSnapshotNonce is returned when trying to snapshot a signing session.
+// Restoring such a session could lead to the same nonces being used for two different signatures,
+// which would leak the secret key share.
+var ErrSnapshotNonce = errors.New("sign: signing sessions cannot be snapshotted since this could lead to nonce reuse")
+
+// Snapshot implements state.Snapshotter, and always returns ErrSnapshotNonce.
+func (round *round0) Snapshot() ([]byte, error) {
+	return nil, ErrSnapshotNonce
+}
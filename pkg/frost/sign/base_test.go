@@ -0,0 +1,147 @@
+package sign
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+func TestNewRound_UnknownSigners(t *testing.T) {
+	keygenIDs := party.IDSlice{1, 2, 3, 4}
+	_, secrets := helpers.GenerateSecrets(keygenIDs, 2)
+	public := helpers.GeneratePublic(2, secrets)
+
+	_, _, err := NewRound(party.IDSlice{9, 1, 2, 7}, secrets[1], public, []byte("message"))
+	if !errors.Is(err, ErrUnknownSigners) {
+		t.Fatalf("NewRound() error = %v, want ErrUnknownSigners", err)
+	}
+	var unknownErr *UnknownSignersError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("NewRound() error = %v, want *UnknownSignersError", err)
+	}
+	if !unknownErr.IDs.Equal(party.IDSlice{7, 9}) {
+		t.Errorf("UnknownSignersError.IDs = %v, want [7 9]", unknownErr.IDs)
+	}
+
+	if _, _, err = NewRound(party.IDSlice{3, 1, 2}, secrets[1], public, []byte("message")); err != nil {
+		t.Errorf("NewRound() with a subset of the key generation parties: %v", err)
+	}
+}
+
+func TestNewRound_ShareMismatch(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	_, secrets := helpers.GenerateSecrets(partyIDs, 1)
+	public := helpers.GeneratePublic(1, secrets)
+	_, otherSecrets := helpers.GenerateSecrets(partyIDs, 1)
+	otherPublic := helpers.GeneratePublic(1, otherSecrets)
+
+	var mismatchErr *eddsa.ShareMismatchError
+
+	// The share of party 2 restored as the one of party 1
+	swapped := eddsa.NewSecretShare(1, &secrets[2].Secret)
+	_, _, err := NewRound(partyIDs, swapped, public, []byte("message"))
+	if !errors.As(err, &mismatchErr) || mismatchErr.ID != 1 || !errors.Is(err, eddsa.ErrKeyShareMismatch) {
+		t.Fatalf("NewRound() with a swapped share: error = %v, want *eddsa.ShareMismatchError for party 1", err)
+	}
+
+	// The share of party 1 in another group
+	_, _, err = NewRound(partyIDs, otherSecrets[1], public, []byte("message"))
+	if !errors.Is(err, eddsa.ErrKeyShareMismatch) {
+		t.Fatalf("NewRound() with a share of another group: error = %v, want ErrKeyShareMismatch", err)
+	}
+
+	// The group is checked first when it is known
+	bound := otherSecrets[1].Copy()
+	bound.SetGroup(otherPublic.GroupKey)
+	_, _, err = NewRound(partyIDs, bound, public, []byte("message"))
+	if !errors.Is(err, eddsa.ErrWrongGroup) {
+		t.Fatalf("NewRound() with a share set to another group: error = %v, want ErrWrongGroup", err)
+	}
+}
+
+func TestReset_Finished(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	_, secrets := helpers.GenerateSecrets(partyIDs, 2)
+	public := helpers.GeneratePublic(2, secrets)
+	zero := ristretto.NewScalar()
+
+	states := make(map[party.ID]*state.State, partyIDs.N())
+	rounds := make(map[party.ID]*round0, partyIDs.N())
+	outputs := make(map[party.ID]*Output, partyIDs.N())
+	for _, id := range partyIDs {
+		r, output, err := NewRound(partyIDs, secrets[id], public, []byte("message"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rounds[id], outputs[id] = r.(*round0), output
+		if states[id], err = state.NewBaseState(r, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runRounds(t, partyIDs, states, 3)
+
+	for _, id := range partyIDs {
+		if err := states[id].WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+		r := rounds[id]
+		if r.d.Equal(zero) != 1 || r.e.Equal(zero) != 1 {
+			t.Errorf("party %d: nonces d and e were not zeroed", id)
+		}
+		if r.SecretKeyShare.Equal(zero) != 1 {
+			t.Errorf("party %d: secret key share was not zeroed", id)
+		}
+		if len(r.Parties) != 0 {
+			t.Errorf("party %d: the state of the signers was not deleted", id)
+		}
+		if secrets[id].Secret.Equal(zero) == 1 {
+			t.Errorf("party %d: the SecretShare given to NewRound was wiped", id)
+		}
+		if !public.GroupKey.Verify([]byte("message"), outputs[id].Signature) {
+			t.Errorf("party %d: the output signature is invalid", id)
+		}
+	}
+}
+
+func TestNewRound_SignerCount(t *testing.T) {
+	keygenIDs := party.IDSlice{1, 2, 3, 4, 5}
+	for _, tc := range []struct {
+		threshold party.Size
+		signers   party.IDSlice
+		err       error
+	}{
+		{1, party.IDSlice{1}, ErrTooFewSigners},
+		{1, party.IDSlice{1, 2}, nil},
+		{1, keygenIDs, nil},
+		{2, party.IDSlice{1, 2}, ErrTooFewSigners},
+		{2, party.IDSlice{1, 2, 3}, nil},
+		{4, party.IDSlice{1, 2, 3, 4}, ErrTooFewSigners},
+		{4, keygenIDs, nil},
+		{4, party.IDSlice{1, 2, 3, 4, 5, 6}, ErrTooManySigners},
+		{2, party.IDSlice{1, 2, 3, 4, 5, 6}, ErrTooManySigners},
+	} {
+		_, secrets := helpers.GenerateSecrets(keygenIDs, tc.threshold)
+		public := helpers.GeneratePublic(tc.threshold, secrets)
+
+		_, _, err := NewRound(tc.signers, secrets[1], public, []byte("message"))
+		if tc.err == nil {
+			if err != nil {
+				t.Errorf("t=%d, signers %v: %v", tc.threshold, tc.signers, err)
+			}
+			continue
+		}
+		var countErr *SignerCountError
+		if !errors.Is(err, tc.err) || !errors.As(err, &countErr) {
+			t.Errorf("t=%d, signers %v: error = %v, want %v", tc.threshold, tc.signers, err, tc.err)
+			continue
+		}
+		if countErr.Signers != tc.signers.N() || countErr.MinSigners != tc.threshold+1 || countErr.MaxSigners != keygenIDs.N() {
+			t.Errorf("t=%d, signers %v: %+v", tc.threshold, tc.signers, countErr)
+		}
+	}
+}
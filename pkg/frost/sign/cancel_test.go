@@ -0,0 +1,110 @@
+package sign
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// setupSign returns the states and first rounds of a signing session between partyIDs.
+func setupSign(t *testing.T, partyIDs party.IDSlice) (map[party.ID]*state.State, map[party.ID]*round0) {
+	_, secrets := helpers.GenerateSecrets(partyIDs, partyIDs.N()-1)
+	public := helpers.GeneratePublic(partyIDs.N()-1, secrets)
+
+	states := make(map[party.ID]*state.State, partyIDs.N())
+	rounds := make(map[party.ID]*round0, partyIDs.N())
+	for _, id := range partyIDs {
+		r, _, err := NewRound(partyIDs, secrets[id], public, []byte("message"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rounds[id] = r.(*round0)
+		if states[id], err = state.NewBaseState(r, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return states, rounds
+}
+
+// runRounds processes the first n rounds of the protocol for all parties.
+func runRounds(t *testing.T, partyIDs party.IDSlice, states map[party.ID]*state.State, n int) {
+	for i := 0; i < n; i++ {
+		var out []*messages.Message
+		for _, id := range partyIDs {
+			out = append(out, states[id].ProcessAll()...)
+		}
+		for _, msg := range out {
+			data, err := msg.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range partyIDs {
+				var msgCopy messages.Message
+				if err = msgCopy.UnmarshalBinary(data); err != nil {
+					t.Fatal(err)
+				}
+				if err = states[id].HandleMessage(&msgCopy); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+}
+
+func TestCancel(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	zero := ristretto.NewScalar()
+	reason := errors.New("message withdrawn")
+
+	for n := 0; n <= 2; n++ {
+		states, rounds := setupSign(t, partyIDs)
+		runRounds(t, partyIDs, states, n)
+
+		s, r := states[1], rounds[1]
+		if n > 0 && r.d.Equal(zero) == 1 {
+			t.Fatalf("round %d: nonce d should be set before canceling", n)
+		}
+
+		s.Cancel(reason)
+
+		err := s.WaitForError()
+		if !errors.Is(err, state.ErrCanceled) || !errors.Is(err, reason) {
+			t.Errorf("round %d: WaitForError() = %v, want an error wrapping ErrCanceled and the reason", n, err)
+		}
+		if r.d.Equal(zero) != 1 || r.e.Equal(zero) != 1 {
+			t.Errorf("round %d: nonces d and e were not zeroed", n)
+		}
+		if r.SecretKeyShare.Equal(zero) != 1 {
+			t.Errorf("round %d: secret key share was not zeroed", n)
+		}
+		if len(r.Parties) != 0 {
+			t.Errorf("round %d: the state of the other parties was not deleted", n)
+		}
+		if s.HandleMessage(messages.NewSign1(2, ristretto.NewIdentityElement(), ristretto.NewIdentityElement())) == nil {
+			t.Errorf("round %d: HandleMessage should fail after Cancel", n)
+		}
+		if out := s.ProcessAll(); len(out) != 0 {
+			t.Errorf("round %d: ProcessAll should not generate messages after Cancel", n)
+		}
+	}
+}
+
+func TestCancelFinished(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	states, _ := setupSign(t, partyIDs)
+	runRounds(t, partyIDs, states, 3)
+
+	s := states[1]
+	if err := s.WaitForError(); err != nil {
+		t.Fatal(err)
+	}
+	s.Cancel(errors.New("too late"))
+	if err := s.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil after canceling a finished protocol", err)
+	}
+}
@@ -0,0 +1,15 @@
+package sign
+
+// WithContext returns an Option which makes the signers produce an Ed25519ctx signature with the given context,
+// as defined in RFC 8032, Section 5.1, so that signatures made for one application cannot be used in another one.
+// The signature is accepted by eddsa.VerifyWithContext, and by ed25519.VerifyWithOptions with
+// ed25519.Options{Context: context}, but not by ed25519.Verify.
+//
+// The context must have between 1 and 255 bytes, otherwise NewRound returns an *eddsa.ContextError.
+// It cannot be combined with WithPrehash, and all signers must use the same context.
+func WithContext(context []byte) Option {
+	ctx := append([]byte{}, context...)
+	return func(round *round0) {
+		round.Context = ctx
+	}
+}
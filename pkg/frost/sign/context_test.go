@@ -0,0 +1,60 @@
+package sign
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+func TestWithContext(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3, 4}
+	publicKey, secrets, public := rfc8032PhKey(t, partyIDs, 2)
+	message := []byte("token")
+	context := []byte("billing")
+	withContext := func(party.ID) []Option { return []Option{WithContext(context)} }
+
+	output, err := signWith(t, party.IDSlice{2, 3, 4}, secrets, public, message, withContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !eddsa.VerifyWithContext(publicKey, message, output.Signature, context) {
+		t.Error("the signature is invalid for the context")
+	}
+	if eddsa.VerifyWithContext(publicKey, message, output.Signature, []byte("auth")) {
+		t.Error("the signature should be invalid for another context")
+	}
+	if ed25519.Verify(publicKey, message, output.Signature.ToEd25519()) {
+		t.Error("the signature should be invalid without a context")
+	}
+	verifyWithOptions(t, publicKey, message, output.Signature, false, string(context))
+
+	// Signers with different contexts cannot produce a signature
+	_, err = signWith(t, party.IDSlice{2, 3, 4}, secrets, public, message, func(id party.ID) []Option {
+		if id == 3 {
+			return []Option{WithContext([]byte("auth"))}
+		}
+		return []Option{WithContext(context)}
+	})
+	if !errors.Is(err, ErrValidateSigShare) {
+		t.Errorf("mixing contexts: error = %v, want ErrValidateSigShare", err)
+	}
+}
+
+func TestWithContext_Invalid(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	_, secrets, public := rfc8032PhKey(t, partyIDs, 2)
+	for _, context := range [][]byte{nil, {}, []byte(strings.Repeat("c", 256))} {
+		_, _, err := NewRound(partyIDs, secrets[1], public, []byte("message"), WithContext(context))
+		var contextErr *eddsa.ContextError
+		if !errors.As(err, &contextErr) || contextErr.Length != len(context) {
+			t.Errorf("NewRound() with a context of %d bytes: error = %v, want a *eddsa.ContextError", len(context), err)
+		}
+	}
+	if _, _, err := NewRound(partyIDs, secrets[1], public, []byte(strings.Repeat("m", 64)), WithContext([]byte("c")), WithPrehash()); err == nil {
+		t.Error("NewRound() should reject WithContext combined with WithPrehash")
+	}
+}
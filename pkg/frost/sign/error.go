@@ -0,0 +1,85 @@
+package sign
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+// ErrUnknownSigners is wrapped by an *UnknownSignersError.
+var ErrUnknownSigners = errors.New("signers did not take part in the key generation")
+
+// UnknownSignersError is returned by NewRound when some signers have no public share in the eddsa.Public.
+type UnknownSignersError struct {
+	// IDs are the signers which did not take part in the key generation, in increasing order.
+	IDs party.IDSlice
+}
+
+// Error implements error
+func (e UnknownSignersError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrUnknownSigners.Error(), e.IDs)
+}
+
+// Unwrap returns ErrUnknownSigners.
+func (e UnknownSignersError) Unwrap() error {
+	return ErrUnknownSigners
+}
+
+var (
+	// ErrTooFewSigners is wrapped by a *SignerCountError when there are fewer signers than MinSigners of the key.
+	ErrTooFewSigners = errors.New("not enough signers for the threshold of the key")
+	// ErrTooManySigners is wrapped by a *SignerCountError when there are more signers than parties in the key generation.
+	ErrTooManySigners = errors.New("more signers than parties in the key generation")
+)
+
+// SignerCountError is returned by NewRound when the number of signers is not between MinSigners of the key
+// and the number of parties of the key generation.
+type SignerCountError struct {
+	// Signers is the number of signers.
+	Signers party.Size
+	// MinSigners is Threshold+1, the minimum number of signers of the key.
+	MinSigners party.Size
+	// MaxSigners is the number of parties of the key generation.
+	MaxSigners party.Size
+}
+
+// Error implements error
+func (e *SignerCountError) Error() string {
+	return fmt.Sprintf("%s: got %d signers, but the key requires between %d and %d (threshold %d)",
+		e.Unwrap().Error(), e.Signers, e.MinSigners, e.MaxSigners, e.MinSigners-1)
+}
+
+// Unwrap returns ErrTooFewSigners or ErrTooManySigners.
+func (e *SignerCountError) Unwrap() error {
+	if e.Signers < e.MinSigners {
+		return ErrTooFewSigners
+	}
+	return ErrTooManySigners
+}
+
+var (
+	// ErrMissingCommitments is wrapped by a *CommitmentError when the commitments of a signer were not given.
+	ErrMissingCommitments = errors.New("missing commitments")
+	// ErrNonceIndexMismatch is wrapped by a *CommitmentError when the commitments of a signer were preprocessed
+	// for another nonce index than ours.
+	ErrNonceIndexMismatch = errors.New("commitments are for another nonce index")
+)
+
+// CommitmentError is returned by NewTranscript and NewOnlineRound when the commitments of a signer are missing or invalid.
+type CommitmentError struct {
+	// ID is the signer whose commitments are invalid.
+	ID party.ID
+	// Err is the reason, for instance ErrIdentityCommitment.
+	Err error
+}
+
+// Error implements error
+func (e *CommitmentError) Error() string {
+	return fmt.Sprintf("commitments of party %d: %v", e.ID, e.Err)
+}
+
+// Unwrap returns the reason.
+func (e *CommitmentError) Unwrap() error {
+	return e.Err
+}
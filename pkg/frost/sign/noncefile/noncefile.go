@@ -0,0 +1,452 @@
+// Package noncefile implements a sign.NonceStore backed by a file, which keeps track of the consumed nonce pairs
+// across restarts of the process. It is also a sign.BindingStore, so that a sign.NonceGuard remembers the sessions
+// to which the pairs were bound.
+//
+// The file is a sequence of records, which are either
+//
+//	Add       = 'A' ∥ index (4 bytes) ∥ d ∥ e ∥ CRC-32 (4 bytes)
+//	Consume   = 'C' ∥ index (4 bytes) ∥ CRC-32 (4 bytes)
+//	Binding   = 'B' ∥ nonce id ∥ message digest ∥ signers digest ∥ CRC-32 (4 bytes)
+//	Tombstone = 'U' ∥ nonce id ∥ CRC-32 (4 bytes)
+//
+// where integers are big endian, and the checksum covers all preceding fields of the record.
+// A pair is consumed by appending a Consume record, and then by overwriting d, e and the checksum of its Add record
+// with zeros and the matching checksum, so that the file does not keep the nonces of a signature.
+// A record which was only partially written when the process stopped is discarded when the file is opened,
+// as is the checksum of an Add record whose pair was being wiped.
+// When the file is opened, it is rewritten without the Binding and Tombstone records which were replaced by a later one,
+// so that it holds at most one of them per pair.
+//
+// The file contains the secret nonces of the unconsumed pairs, and must be protected like a secret key share.
+package noncefile
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/sign"
+)
+
+const (
+	recordAdd       = 'A'
+	recordConsume   = 'C'
+	recordBinding   = 'B'
+	recordTombstone = 'U'
+
+	indexSize    = 4
+	checksumSize = 4
+	pairSize     = indexSize + 32 + 32
+
+	addRecordSize       = 1 + pairSize + checksumSize
+	consumeRecordSize   = 1 + indexSize + checksumSize
+	bindingRecordSize   = 1 + 3*32 + checksumSize
+	tombstoneRecordSize = 1 + 32 + checksumSize
+)
+
+// ErrCorrupted is returned when a complete record of the file does not match its checksum,
+// or when the records are inconsistent.
+var ErrCorrupted = errors.New("noncefile: corrupted file")
+
+// Store is a sign.NonceStore which records the pairs in a file.
+// It is safe for concurrent use.
+type Store struct {
+	mtx  sync.Mutex
+	path string
+	file *os.File
+	// end is the offset at which the next record is written.
+	end int64
+
+	// pairs contains the unconsumed pairs.
+	pairs map[uint32]*sign.NoncePair
+	// offsets contains the offset of the Add record of every pair, including the consumed ones.
+	offsets map[uint32]int64
+	// consumed contains the indices of the consumed pairs.
+	consumed map[uint32]bool
+	// bindings contains the latest Binding or Tombstone record of every nonce pair.
+	bindings map[sign.NonceID]sign.BindingRecord
+	// replaced is the number of Binding and Tombstone records which were replaced by a later one.
+	replaced int
+}
+
+// Open opens the file at path, creating it if it does not exist, and returns a Store which records the pairs in it.
+// A partially written record at the end of the file is removed, and the consumed pairs which were not wiped yet are wiped.
+func Open(path string) (*Store, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("noncefile.Open: %w", err)
+	}
+	s := &Store{path: path, file: file}
+
+	err = s.load()
+	if err == nil {
+		err = file.Truncate(s.end)
+	}
+	if err == nil {
+		err = s.wipeConsumed()
+	}
+	if err == nil && s.replaced > 0 {
+		err = s.compact()
+	}
+	if err != nil {
+		_ = s.file.Close()
+		return nil, fmt.Errorf("noncefile.Open: %w", err)
+	}
+	return s, nil
+}
+
+// compact rewrites the file without the Binding and Tombstone records which were replaced,
+// and replaces it atomically.
+func (s *Store) compact() error {
+	data := make([]byte, s.end)
+	if _, err := s.file.ReadAt(data, 0); err != nil && err != io.EOF {
+		return err
+	}
+	compacted := make([]byte, 0, len(data))
+	for len(data) > 0 {
+		size := recordSize(data[0])
+		if data[0] == recordAdd || data[0] == recordConsume {
+			compacted = append(compacted, data[:size]...)
+		}
+		data = data[size:]
+	}
+	// The order of the pairs does not matter, since there is a single record per pair
+	for _, r := range s.bindings {
+		compacted = appendBinding(compacted, r)
+	}
+
+	tmp := s.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(compacted); err == nil {
+		err = file.Sync()
+	}
+	if err == nil {
+		err = os.Rename(tmp, s.path)
+	}
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if dir, err := os.Open(filepath.Dir(s.path)); err == nil {
+		_ = dir.Sync()
+		_ = dir.Close()
+	}
+
+	_ = s.file.Close()
+	s.file = file
+	return s.load()
+}
+
+// load reads all complete records of the file, and sets end to the offset following the last one.
+func (s *Store) load() error {
+	s.pairs = make(map[uint32]*sign.NoncePair)
+	s.offsets = make(map[uint32]int64)
+	s.consumed = make(map[uint32]bool)
+	s.bindings = make(map[sign.NonceID]sign.BindingRecord)
+	s.replaced = 0
+
+	info, err := s.file.Stat()
+	if err != nil {
+		return err
+	}
+	data := make([]byte, info.Size())
+	if _, err = s.file.ReadAt(data, 0); err != nil && err != io.EOF {
+		return err
+	}
+
+	// torn contains the indices of the Add records whose checksum is invalid,
+	// which is only possible if the pair was being wiped after it was consumed.
+	torn := make(map[uint32]bool)
+	var offset int64
+	for len(data) > 0 {
+		size := recordSize(data[0])
+		if size == 0 {
+			return fmt.Errorf("record at offset %d: %w", offset, ErrCorrupted)
+		}
+		if len(data) < size {
+			break
+		}
+		record := data[:size]
+		valid := crc32.ChecksumIEEE(record[:size-checksumSize]) == binary.BigEndian.Uint32(record[size-checksumSize:])
+		index := binary.BigEndian.Uint32(record[1:])
+
+		switch record[0] {
+		case recordAdd:
+			if _, ok := s.offsets[index]; ok {
+				return fmt.Errorf("record at offset %d: duplicate index %d: %w", offset, index, ErrCorrupted)
+			}
+			s.offsets[index] = offset
+			if !valid {
+				torn[index] = true
+				break
+			}
+			var pair sign.NoncePair
+			err = pair.UnmarshalBinary(record[1 : 1+pairSize])
+			switch {
+			case err == nil:
+				s.pairs[index] = &pair
+			case errors.Is(err, sign.ErrInvalidNonce):
+				// The pair was wiped, and its Consume record must follow
+				torn[index] = true
+			default:
+				return fmt.Errorf("record at offset %d: %v: %w", offset, err, ErrCorrupted)
+			}
+		case recordConsume:
+			if !valid {
+				return fmt.Errorf("record at offset %d: %w", offset, ErrCorrupted)
+			}
+			if _, ok := s.offsets[index]; !ok || s.consumed[index] {
+				return fmt.Errorf("record at offset %d: unexpected consumption of index %d: %w", offset, index, ErrCorrupted)
+			}
+			s.consumed[index] = true
+		case recordBinding, recordTombstone:
+			if !valid {
+				return fmt.Errorf("record at offset %d: %w", offset, ErrCorrupted)
+			}
+			r := decodeBinding(record)
+			if _, ok := s.bindings[r.ID]; ok {
+				s.replaced++
+			}
+			s.bindings[r.ID] = r
+		}
+		data = data[size:]
+		offset += int64(size)
+	}
+	s.end = offset
+
+	for index := range torn {
+		if !s.consumed[index] {
+			return fmt.Errorf("pair %d: %w", index, ErrCorrupted)
+		}
+	}
+	return nil
+}
+
+// wipeConsumed wipes the consumed pairs which are still in the file,
+// because the process stopped after their Consume record was written.
+func (s *Store) wipeConsumed() error {
+	for index := range s.consumed {
+		pair, ok := s.pairs[index]
+		if !ok {
+			continue
+		}
+		pair.Reset()
+		delete(s.pairs, index)
+		if err := s.wipe(index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wipe overwrites the nonces of the Add record of index with zeros.
+func (s *Store) wipe(index uint32) error {
+	var data [pairSize - indexSize + checksumSize]byte
+	offset := s.offsets[index] + 1 + indexSize
+
+	// The checksum is computed on the record with its nonces set to zero
+	var header [1 + indexSize]byte
+	header[0] = recordAdd
+	binary.BigEndian.PutUint32(header[1:], index)
+	checksum := crc32.Update(crc32.ChecksumIEEE(header[:]), crc32.IEEETable, data[:len(data)-checksumSize])
+	binary.BigEndian.PutUint32(data[len(data)-checksumSize:], checksum)
+
+	if _, err := s.file.WriteAt(data[:], offset); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Add implements sign.NonceStore.
+// If the process stops before Add returns, some of the pairs may have been added.
+func (s *Store) Add(pairs []*sign.NoncePair) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	seen := make(map[uint32]bool, len(pairs))
+	data := make([]byte, 0, len(pairs)*addRecordSize)
+	for _, p := range pairs {
+		if _, ok := s.offsets[p.Index]; ok || seen[p.Index] {
+			return fmt.Errorf("noncefile.Add: %w: index %d", sign.ErrDuplicateNonce, p.Index)
+		}
+		seen[p.Index] = true
+
+		raw, err := p.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("noncefile.Add: %w", err)
+		}
+		data = append(data, recordAdd)
+		data = append(data, raw...)
+		data = appendChecksum(data, addRecordSize)
+	}
+
+	if err := s.append(data); err != nil {
+		return fmt.Errorf("noncefile.Add: %w", err)
+	}
+	for i, p := range pairs {
+		pair := *p
+		s.pairs[p.Index] = &pair
+		s.offsets[p.Index] = s.end - int64((len(pairs)-i)*addRecordSize)
+	}
+	return nil
+}
+
+// Consume implements sign.NonceStore.
+func (s *Store) Consume(index uint32) (*sign.NoncePair, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.consume(index)
+}
+
+// ConsumeNext implements sign.NonceStore.
+func (s *Store) ConsumeNext() (*sign.NoncePair, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if len(s.pairs) == 0 {
+		return nil, sign.ErrNoncesExhausted
+	}
+	indices := make([]uint32, 0, len(s.pairs))
+	for index := range s.pairs {
+		indices = append(indices, index)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	return s.consume(indices[0])
+}
+
+// consume records the consumption of index, and should be called with the lock held.
+func (s *Store) consume(index uint32) (*sign.NoncePair, error) {
+	if s.consumed[index] {
+		return nil, fmt.Errorf("noncefile.Consume: %w: index %d", sign.ErrNonceConsumed, index)
+	}
+	pair, ok := s.pairs[index]
+	if !ok {
+		return nil, fmt.Errorf("noncefile.Consume: %w: index %d", sign.ErrUnknownNonce, index)
+	}
+
+	data := make([]byte, 1+indexSize, consumeRecordSize)
+	data[0] = recordConsume
+	binary.BigEndian.PutUint32(data[1:], index)
+	data = appendChecksum(data, consumeRecordSize)
+	if err := s.append(data); err != nil {
+		return nil, fmt.Errorf("noncefile.Consume: %w", err)
+	}
+	delete(s.pairs, index)
+	s.consumed[index] = true
+
+	// The pair is consumed even if it could not be wiped, and is wiped again when the file is opened
+	if err := s.wipe(index); err != nil {
+		pair.Reset()
+		return nil, fmt.Errorf("noncefile.Consume: failed to wipe consumed pair %d: %w", index, err)
+	}
+	return pair, nil
+}
+
+// append writes data at the end of the file, and only returns once it is on disk.
+func (s *Store) append(data []byte) error {
+	if _, err := s.file.WriteAt(data, s.end); err != nil {
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+	s.end += int64(len(data))
+	return nil
+}
+
+// appendChecksum appends the checksum of the last record of data, whose size includes the checksum.
+func appendChecksum(data []byte, size int) []byte {
+	record := data[len(data)-(size-checksumSize):]
+	var checksum [checksumSize]byte
+	binary.BigEndian.PutUint32(checksum[:], crc32.ChecksumIEEE(record))
+	return append(data, checksum[:]...)
+}
+
+// RecordBinding implements sign.BindingStore.
+func (s *Store) RecordBinding(r sign.BindingRecord) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if err := s.append(appendBinding(nil, r)); err != nil {
+		return fmt.Errorf("noncefile.RecordBinding: %w", err)
+	}
+	if _, ok := s.bindings[r.ID]; ok {
+		s.replaced++
+	}
+	s.bindings[r.ID] = r
+	return nil
+}
+
+// LoadBindings implements sign.BindingStore.
+func (s *Store) LoadBindings() ([]sign.BindingRecord, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	records := make([]sign.BindingRecord, 0, len(s.bindings))
+	for _, r := range s.bindings {
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// appendBinding appends the Binding record of r to data, or its Tombstone record if the pair was used.
+func appendBinding(data []byte, r sign.BindingRecord) []byte {
+	if r.Responded {
+		data = append(data, recordTombstone)
+		data = append(data, r.ID[:]...)
+		return appendChecksum(data, tombstoneRecordSize)
+	}
+	data = append(data, recordBinding)
+	data = append(data, r.ID[:]...)
+	data = append(data, r.Binding.Message[:]...)
+	data = append(data, r.Binding.Signers[:]...)
+	return appendChecksum(data, bindingRecordSize)
+}
+
+// decodeBinding returns the sign.BindingRecord encoded in a valid Binding or Tombstone record.
+func decodeBinding(record []byte) sign.BindingRecord {
+	r := sign.BindingRecord{Responded: record[0] == recordTombstone}
+	copy(r.ID[:], record[1:33])
+	if !r.Responded {
+		copy(r.Binding.Message[:], record[33:65])
+		copy(r.Binding.Signers[:], record[65:97])
+	}
+	return r
+}
+
+// recordSize returns the size of the records of type t, or 0 if t is unknown.
+func recordSize(t byte) int {
+	switch t {
+	case recordAdd:
+		return addRecordSize
+	case recordConsume:
+		return consumeRecordSize
+	case recordBinding:
+		return bindingRecordSize
+	case recordTombstone:
+		return tombstoneRecordSize
+	}
+	return 0
+}
+
+// Remaining returns the number of unconsumed pairs.
+func (s *Store) Remaining() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return len(s.pairs)
+}
+
+// Close closes the file.
+func (s *Store) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.file.Close()
+}
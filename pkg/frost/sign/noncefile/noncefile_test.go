@@ -0,0 +1,209 @@
+package noncefile_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/sign"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/sign/noncefile"
+)
+
+func generate(t *testing.T, count int) []*sign.NoncePair {
+	pairs, err := sign.GenerateNonces(count, nil)
+	require.NoError(t, err)
+	return pairs
+}
+
+func commitment(t *testing.T, p *sign.NoncePair) []byte {
+	data, err := sign.NewNonceCommitments(1, []*sign.NoncePair{p}).MarshalBinary()
+	require.NoError(t, err)
+	return data
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces")
+	pairs := generate(t, 3)
+	s, err := noncefile.Open(path)
+	require.NoError(t, err)
+	_, err = s.ConsumeNext()
+	assert.True(t, errors.Is(err, sign.ErrNoncesExhausted), "error = %v", err)
+	require.NoError(t, s.Add(pairs))
+	err = s.Add(pairs[2:])
+	assert.True(t, errors.Is(err, sign.ErrDuplicateNonce), "error = %v", err)
+
+	pair, err := s.Consume(1)
+	require.NoError(t, err)
+	assert.Equal(t, commitment(t, pairs[1]), commitment(t, pair))
+	_, err = s.Consume(1)
+	assert.True(t, errors.Is(err, sign.ErrNonceConsumed), "error = %v", err)
+	require.NoError(t, s.Close())
+
+	// The consumed pair is still consumed after a restart, and the others are kept
+	s, err = noncefile.Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.Remaining())
+	_, err = s.Consume(1)
+	assert.True(t, errors.Is(err, sign.ErrNonceConsumed), "error = %v", err)
+	err = s.Add(pairs[1:2])
+	assert.True(t, errors.Is(err, sign.ErrDuplicateNonce), "error = %v", err)
+	_, err = s.Consume(5)
+	assert.True(t, errors.Is(err, sign.ErrUnknownNonce), "error = %v", err)
+
+	pair, err = s.ConsumeNext()
+	require.NoError(t, err)
+	assert.Equal(t, commitment(t, pairs[0]), commitment(t, pair))
+	pair, err = s.ConsumeNext()
+	require.NoError(t, err)
+	assert.Equal(t, commitment(t, pairs[2]), commitment(t, pair))
+	_, err = s.ConsumeNext()
+	assert.True(t, errors.Is(err, sign.ErrNoncesExhausted), "error = %v", err)
+
+	// A new batch can be added
+	more, err := sign.GenerateNoncesFrom(3, 2, nil)
+	require.NoError(t, err)
+	require.NoError(t, s.Add(more))
+	require.NoError(t, s.Close())
+
+	s, err = noncefile.Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.Remaining())
+	pair, err = s.ConsumeNext()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), pair.Index)
+	require.NoError(t, s.Close())
+}
+
+func TestStore_Wiped(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces")
+	pairs := generate(t, 2)
+	encoded, err := pairs[0].MarshalBinary()
+	require.NoError(t, err)
+
+	s, err := noncefile.Open(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Add(pairs))
+	_, err = s.Consume(0)
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	// The nonces of the consumed pair are no longer in the file
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(data, encoded[4:36]))
+	assert.False(t, bytes.Contains(data, encoded[36:]))
+}
+
+func TestStore_Crash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces")
+	pairs := generate(t, 2)
+	s, err := noncefile.Open(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Add(pairs))
+	added, err := os.ReadFile(path)
+	require.NoError(t, err)
+	_, err = s.Consume(0)
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// The process stopped while the pair 0 was being wiped, after its Consume record was written
+	torn := append([]byte{}, data...)
+	copy(torn[5:37], bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, os.WriteFile(path, torn, 0600))
+	s, err = noncefile.Open(path)
+	require.NoError(t, err)
+	_, err = s.Consume(0)
+	assert.True(t, errors.Is(err, sign.ErrNonceConsumed), "error = %v", err)
+	assert.Equal(t, 1, s.Remaining())
+	require.NoError(t, s.Close())
+
+	// The process stopped while the Consume record was being written, so the pair was not returned
+	require.NoError(t, os.WriteFile(path, append(added, data[len(added):len(added)+5]...), 0600))
+	s, err = noncefile.Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.Remaining())
+	_, err = s.Consume(0)
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	// A pair whose nonces were modified without being consumed is reported
+	corrupted := append([]byte{}, added...)
+	corrupted[80] ^= 1
+	require.NoError(t, os.WriteFile(path, corrupted, 0600))
+	_, err = noncefile.Open(path)
+	assert.True(t, errors.Is(err, noncefile.ErrCorrupted), "error = %v", err)
+}
+
+func TestStore_Bindings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces")
+	pairs := generate(t, 2)
+	s, err := noncefile.Open(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Add(pairs))
+	guard, err := sign.NewNonceGuard(s)
+	require.NoError(t, err)
+
+	first := sign.NonceBinding{Message: [32]byte{1}, Signers: [32]byte{1}}
+	second := sign.NonceBinding{Message: [32]byte{2}, Signers: [32]byte{1}}
+	used, bound := pairs[0].ID(), pairs[1].ID()
+	require.NoError(t, guard.Bind(used, first, true))
+	require.NoError(t, guard.Respond(used, first))
+	require.NoError(t, guard.Bind(bound, first, true))
+	// The nonces sampled during a session are not recorded
+	require.NoError(t, guard.Bind(sign.NonceID{1}, first, false))
+	_, err = s.Consume(0)
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	// The bindings are remembered after a restart
+	s, err = noncefile.Open(path)
+	require.NoError(t, err)
+	records, err := s.LoadBindings()
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+	guard, err = sign.NewNonceGuard(s)
+	require.NoError(t, err)
+	err = guard.Respond(used, first)
+	assert.True(t, errors.Is(err, sign.ErrNonceReuse), "error = %v", err)
+	err = guard.Bind(bound, second, true)
+	assert.True(t, errors.Is(err, sign.ErrNonceReuse), "error = %v", err)
+	require.NoError(t, guard.Respond(bound, first))
+	require.NoError(t, s.Close())
+
+	// The replaced Binding records are removed when the file is opened, and only the tombstones are kept
+	s, err = noncefile.Open(path)
+	require.NoError(t, err)
+	records, err = s.LoadBindings()
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+	for _, r := range records {
+		assert.True(t, r.Responded)
+		assert.Equal(t, sign.NonceBinding{}, r.Binding)
+	}
+	guard, err = sign.NewNonceGuard(s)
+	require.NoError(t, err)
+	err = guard.Bind(used, first, true)
+	assert.True(t, errors.Is(err, sign.ErrNonceReuse), "error = %v", err)
+	require.NoError(t, s.Close())
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2*73+9+2*37), info.Size())
+
+	// A torn Tombstone record at the end of the file is removed, and a corrupted one is reported
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data[:len(data)-10], 0600))
+	s, err = noncefile.Open(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+	data[len(data)-10] ^= 1
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	_, err = noncefile.Open(path)
+	assert.True(t, errors.Is(err, noncefile.ErrCorrupted), "error = %v", err)
+}
@@ -0,0 +1,283 @@
+package sign
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/hashing"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// Signing two different sessions with the same nonces leaks the secret key share, which can happen when a session
+// is created twice from the same copy of a NoncePair, from a NonceStore restored from a backup,
+// or from a random source which repeats its output.
+// A NonceGuard prevents it at runtime: when a signer reveals its commitments in a session, the pair is bound to
+// the message and the signers of the session, and the signature share is only computed if the pair is still bound to
+// the same session and was never used for another share.
+
+// ErrNonceReuse is returned when a nonce pair is used for a second signature share, or for another session than
+// the one it is bound to. The nonces are then wiped, and the session aborts.
+var ErrNonceReuse = errors.New("sign: nonce pair was already used")
+
+// NonceID identifies a nonce pair by the hash of its commitments, as computed by hashing.NonceID.
+// The same nonces always have the same NonceID, including after they were copied or restored.
+type NonceID [32]byte
+
+// boundNonces are our nonces, once they were bound to the session.
+type boundNonces struct {
+	id      NonceID
+	binding NonceBinding
+}
+
+// NonceBinding describes the session to which a nonce pair is bound, as computed by hashing.NonceBinding.
+type NonceBinding struct {
+	// Message is the digest of the message, of the Ed25519 variant, and of the group key.
+	Message [32]byte
+	// Signers is the digest of the signer set.
+	Signers [32]byte
+}
+
+// BindingRecord is the state of a preprocessed nonce pair in a NonceGuard.
+type BindingRecord struct {
+	ID NonceID
+	// Binding is the session to which the pair is bound. It is zero once the pair was used.
+	Binding NonceBinding
+	// Responded is set once a signature share was computed with the pair, in which case the record is a tombstone.
+	Responded bool
+}
+
+// A BindingStore durably records the bindings of the preprocessed pairs of a NonceGuard,
+// so that they are remembered across restarts.
+//
+// The noncefile.Store implements it alongside NonceStore. A restored backup of a NonceStore is detected as long as
+// the bindings recorded since the backup are kept, so the bindings should not be restored with it.
+type BindingStore interface {
+	// RecordBinding records r, which replaces any earlier record with the same ID.
+	// It must only return once the record is durable.
+	RecordBinding(r BindingRecord) error
+
+	// LoadBindings returns the latest record of every pair.
+	LoadBindings() ([]BindingRecord, error)
+}
+
+// usedLimit is the number of used pairs which a NonceGuard remembers in memory only.
+// The oldest ones are forgotten beyond it, so that the memory of a long-running signer stays bounded.
+const usedLimit = 1 << 14
+
+// boundPair is the binding of a pair whose commitments were revealed, and which was not used yet.
+type boundPair struct {
+	binding NonceBinding
+	// durable is set if the binding was recorded in the BindingStore.
+	durable bool
+}
+
+// A NonceGuard records the session to which every nonce pair is bound, and refuses to use a pair for a different one.
+// A binding is dropped once its session has finished without a signature share, since the commitments alone
+// do not reveal anything, and only a small tombstone is kept once the pair was used.
+// It is safe for concurrent use.
+type NonceGuard struct {
+	mtx sync.Mutex
+	// bound contains the pairs bound to a session which has not computed its share yet.
+	bound map[NonceID]boundPair
+	// used contains the tombstones of the used pairs, which are set to true if they were recorded in the store.
+	used map[NonceID]bool
+	// recent contains the tombstones which were not recorded in the store, and recent[next] is the oldest one
+	// once limit is reached.
+	recent []NonceID
+	next   int
+	limit  int
+	store  BindingStore
+}
+
+// DefaultNonceGuard is the process-wide NonceGuard used by the signing sessions which were not given WithNonceGuard.
+// It is kept in memory only, and remembers a bounded number of used pairs.
+var DefaultNonceGuard = newNonceGuard(nil)
+
+func newNonceGuard(store BindingStore) *NonceGuard {
+	return &NonceGuard{
+		bound: make(map[NonceID]boundPair),
+		used:  make(map[NonceID]bool),
+		limit: usedLimit,
+		store: store,
+	}
+}
+
+// NewNonceGuard returns a NonceGuard which records the bindings of the preprocessed pairs in store,
+// and starts with the records it contains. If store is nil, the bindings are only kept in memory.
+// The bindings of the nonces sampled during a session are never recorded in store, since they do not outlive the process.
+func NewNonceGuard(store BindingStore) (*NonceGuard, error) {
+	g := newNonceGuard(store)
+	if store == nil {
+		return g, nil
+	}
+	records, err := store.LoadBindings()
+	if err != nil {
+		return nil, fmt.Errorf("sign.NewNonceGuard: %w", err)
+	}
+	for _, r := range records {
+		if r.Responded {
+			g.used[r.ID] = true
+		} else {
+			g.bound[r.ID] = boundPair{binding: r.Binding, durable: true}
+		}
+	}
+	return g, nil
+}
+
+// WithNonceGuard returns an Option which makes the signer record its nonce pairs in g instead of DefaultNonceGuard.
+// Unlike the other options, it does not change the messages, and signers may use it independently.
+func WithNonceGuard(g *NonceGuard) Option {
+	return func(round *round0) {
+		round.Guard = g
+	}
+}
+
+// Bind binds the pair id to binding, when its commitments are revealed in a session.
+// If preprocessed is set, the pair was read from a NonceStore, and the binding is recorded in the BindingStore of g,
+// since copies of the pair may outlive the process.
+// It fails with ErrNonceReuse if the pair is bound to another session, or was already used for a signature share.
+func (g *NonceGuard) Bind(id NonceID, binding NonceBinding, preprocessed bool) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if _, used := g.used[id]; used {
+		return fmt.Errorf("%w: nonce %x", ErrNonceReuse, id[:8])
+	}
+	if b, ok := g.bound[id]; ok {
+		if b.binding != binding {
+			return fmt.Errorf("%w: nonce %x", ErrNonceReuse, id[:8])
+		}
+		return nil
+	}
+	durable := preprocessed && g.store != nil
+	if durable {
+		if err := g.store.RecordBinding(BindingRecord{ID: id, Binding: binding}); err != nil {
+			g.markUsed(id, false)
+			return fmt.Errorf("sign: failed to record nonce binding: %w", err)
+		}
+	}
+	g.bound[id] = boundPair{binding: binding, durable: durable}
+	return nil
+}
+
+// Respond marks the pair id as used for a signature share in the session described by binding,
+// after which only its tombstone is kept.
+// It fails with ErrNonceReuse if the pair is not bound to this session, or was already used.
+func (g *NonceGuard) Respond(id NonceID, binding NonceBinding) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	b, ok := g.bound[id]
+	if !ok || b.binding != binding {
+		return fmt.Errorf("%w: nonce %x", ErrNonceReuse, id[:8])
+	}
+	delete(g.bound, id)
+	if !b.durable {
+		g.markUsed(id, false)
+		return nil
+	}
+	// If the store fails, the pair is still considered used, so that it cannot be used for another share.
+	if err := g.store.RecordBinding(BindingRecord{ID: id, Responded: true}); err != nil {
+		g.markUsed(id, false)
+		return fmt.Errorf("sign: failed to record nonce binding: %w", err)
+	}
+	g.markUsed(id, true)
+	return nil
+}
+
+// release drops the binding of the pair id to the session described by binding,
+// once the session has finished without using it. The binding recorded in the store, if any, is kept.
+func (g *NonceGuard) release(id NonceID, binding NonceBinding) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if b, ok := g.bound[id]; ok && b.binding == binding && !b.durable {
+		delete(g.bound, id)
+	}
+}
+
+// markUsed adds the tombstone of id, and should be called with the lock held.
+// The tombstones which are not recorded in the store are forgotten, oldest first, beyond the limit of g.
+func (g *NonceGuard) markUsed(id NonceID, durable bool) {
+	if durable {
+		g.used[id] = true
+		return
+	}
+	if _, ok := g.used[id]; ok {
+		return
+	}
+	g.used[id] = false
+	if len(g.recent) < g.limit {
+		g.recent = append(g.recent, id)
+		return
+	}
+	if !g.used[g.recent[g.next]] {
+		delete(g.used, g.recent[g.next])
+	}
+	g.recent[g.next] = id
+	g.next = (g.next + 1) % g.limit
+}
+
+// ID returns the identifier of the pair.
+func (p *NoncePair) ID() NonceID {
+	return p.Commitment().ID()
+}
+
+// ID returns the identifier of the pair with these commitments.
+func (c *NonceCommitment) ID() NonceID {
+	return hashing.NonceID(&c.D, &c.E)
+}
+
+// guard returns the NonceGuard of the round.
+func (round *round0) guard() *NonceGuard {
+	if round.Guard == nil {
+		return DefaultNonceGuard
+	}
+	return round.Guard
+}
+
+// nonceBinding returns the binding of our nonces to this session.
+func (round *round0) nonceBinding() NonceBinding {
+	var variant []byte
+	switch {
+	case round.Prehashed:
+		variant = []byte{1}
+	case round.Context != nil:
+		variant = append([]byte{2}, round.Context...)
+	}
+	message, signers := hashing.NonceBinding(round.GroupKey.ToEd25519(), variant, round.Message, round.PartyIDs())
+	return NonceBinding{Message: message, Signers: signers}
+}
+
+// nonceID returns the identifier of our nonces, once our commitments are set.
+func (round *round0) nonceID() NonceID {
+	selfParty := round.Parties[round.SelfID()]
+	return hashing.NonceID(&selfParty.Di, &selfParty.Ei)
+}
+
+// bindNonces binds our nonces to this session with the NonceGuard, when our commitments are revealed.
+// If they are bound to another session, they are wiped and the session aborts.
+func (round *round0) bindNonces() *state.Error {
+	id, binding := round.nonceID(), round.nonceBinding()
+	if err := round.guard().Bind(id, binding, round.Preprocessed); err != nil {
+		round.wipeNonces()
+		return state.NewError(0, err)
+	}
+	round.bound = &boundNonces{id: id, binding: binding}
+	return nil
+}
+
+// releaseNonces drops the binding of our nonces when the session has finished, so that the NonceGuard only keeps
+// the tombstone of the pairs which were used.
+func (round *round0) releaseNonces() {
+	if round.bound != nil {
+		round.guard().release(round.bound.id, round.bound.binding)
+		round.bound = nil
+	}
+}
+
+// wipeNonces zeroes the nonces d and e held by the round.
+func (round *round0) wipeNonces() {
+	zero := ristretto.NewScalar()
+	round.d.Set(zero)
+	round.e.Set(zero)
+}
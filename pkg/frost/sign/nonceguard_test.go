@@ -0,0 +1,382 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+func memoryNonceGuard(t *testing.T) *NonceGuard {
+	g, err := NewNonceGuard(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// checkNonceReuse checks that round refuses to reveal its commitments because its nonces were used,
+// and that it wiped them.
+func checkNonceReuse(t *testing.T, name string, r state.Round) {
+	msgs, err := r.GenerateMessages()
+	if len(msgs) != 0 {
+		t.Errorf("%s: %d messages were sent", name, len(msgs))
+	}
+	if err == nil || err.Culprit() != 0 || !errors.Is(err, ErrNonceReuse) {
+		t.Fatalf("%s: error = %v, want ErrNonceReuse", name, err)
+	}
+	var round *round0
+	switch r := r.(type) {
+	case *onlineRound:
+		round = r.round0
+	case *round0:
+		round = r
+	}
+	zero := ristretto.NewScalar()
+	if round.d.Equal(zero) != 1 || round.e.Equal(zero) != 1 {
+		t.Errorf("%s: the nonces were not wiped", name)
+	}
+}
+
+// signOnline runs a session of NewOnlineRound in which each signer uses the pair nonces[id] and the guard,
+// and returns the signature.
+func signOnline(t *testing.T, signers party.IDSlice, secrets map[party.ID]*eddsa.SecretShare, public *eddsa.Public, message []byte, nonces map[party.ID]*NoncePair, commitments map[party.ID]*NonceCommitment, guard *NonceGuard) *eddsa.Signature {
+	states := make(map[party.ID]*state.State, len(signers))
+	outputs := make(map[party.ID]*Output, len(signers))
+	for _, id := range signers {
+		r, output, err := NewOnlineRound(signers, secrets[id], public, message, nonces[id], commitments, WithNonceGuard(guard))
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[id] = output
+		if states[id], err = state.NewBaseState(r, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range signers {
+		for _, msg := range states[id].ProcessAll() {
+			for _, other := range signers {
+				if other != id {
+					if err := states[other].HandleMessage(msg); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+		}
+	}
+	for _, id := range signers {
+		states[id].ProcessAll()
+		if err := states[id].WaitForError(); err != nil {
+			t.Fatalf("party %d: %v", id, err)
+		}
+	}
+	return outputs[signers[0]].Signature
+}
+
+func TestNonceGuard(t *testing.T) {
+	g := memoryNonceGuard(t)
+	id := NonceID{1}
+	first := NonceBinding{Message: [32]byte{1}, Signers: [32]byte{1}}
+	second := NonceBinding{Message: [32]byte{2}, Signers: [32]byte{1}}
+	otherSigners := NonceBinding{Message: [32]byte{1}, Signers: [32]byte{2}}
+
+	if err := g.Respond(id, first); !errors.Is(err, ErrNonceReuse) {
+		t.Errorf("Respond() of an unbound pair: error = %v", err)
+	}
+	if err := g.Bind(id, first, false); err != nil {
+		t.Fatal(err)
+	}
+	// The same session may reveal the commitments again
+	if err := g.Bind(id, first, false); err != nil {
+		t.Error(err)
+	}
+	for _, b := range []NonceBinding{second, otherSigners} {
+		if err := g.Bind(id, b, false); !errors.Is(err, ErrNonceReuse) {
+			t.Errorf("Bind() to another session: error = %v", err)
+		}
+		if err := g.Respond(id, b); !errors.Is(err, ErrNonceReuse) {
+			t.Errorf("Respond() for another session: error = %v", err)
+		}
+	}
+	if err := g.Respond(id, first); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Respond(id, first); !errors.Is(err, ErrNonceReuse) {
+		t.Errorf("second Respond(): error = %v", err)
+	}
+	if err := g.Bind(id, first, false); !errors.Is(err, ErrNonceReuse) {
+		t.Errorf("Bind() of a used pair: error = %v", err)
+	}
+}
+
+// failingBindingStore is a BindingStore whose writes fail.
+type failingBindingStore struct {
+	loadErr error
+}
+
+func (s failingBindingStore) RecordBinding(BindingRecord) error {
+	return errors.New("disk full")
+}
+
+func (s failingBindingStore) LoadBindings() ([]BindingRecord, error) {
+	return nil, s.loadErr
+}
+
+func TestNonceGuard_Store(t *testing.T) {
+	loadErr := errors.New("unreadable")
+	if _, err := NewNonceGuard(failingBindingStore{loadErr: loadErr}); !errors.Is(err, loadErr) {
+		t.Errorf("NewNonceGuard() error = %v", err)
+	}
+
+	// A pair whose binding could not be recorded is never used, and only preprocessed pairs are recorded
+	g, err := NewNonceGuard(failingBindingStore{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, binding := NonceID{1}, NonceBinding{}
+	if err = g.Bind(id, binding, true); err == nil {
+		t.Fatal("Bind() should fail when the binding is not recorded")
+	}
+	if err = g.Bind(id, binding, true); !errors.Is(err, ErrNonceReuse) {
+		t.Errorf("Bind() error = %v", err)
+	}
+	if err = g.Bind(NonceID{2}, binding, false); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNonceGuard_Bounded(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	_, secrets := helpers.GenerateSecrets(partyIDs, 1)
+	public := helpers.GeneratePublic(1, secrets)
+	signers := party.IDSlice{1, 2}
+	guard := memoryNonceGuard(t)
+	guard.limit = 8
+
+	var first NonceID
+	for i := 0; i < 20; i++ {
+		message := []byte{byte(i)}
+		states, output := signWithHSMs(t, signers, secrets, public, message, nil, WithNonceGuard(guard))
+		for _, id := range signers {
+			if err := states[id].WaitForError(); err != nil {
+				t.Fatalf("session %d: party %d: %v", i, id, err)
+			}
+		}
+		if !public.GroupKey.Verify(message, output.Signature) {
+			t.Fatalf("session %d: invalid signature", i)
+		}
+		if i == 0 {
+			first = guard.recent[0]
+		}
+	}
+
+	// An aborted session releases its binding
+	r, _, err := NewRound(signers, secrets[1], public, []byte("aborted"), WithNonceGuard(guard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := state.NewBaseState(r, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.ProcessAll()
+	s.Cancel(errors.New("aborted"))
+
+	// Only the tombstones of the last sessions are kept
+	if len(guard.bound) != 0 || len(guard.used) != guard.limit || len(guard.recent) != guard.limit {
+		t.Errorf("%d bindings and %d tombstones are kept", len(guard.bound), len(guard.used))
+	}
+	if _, ok := guard.used[first]; ok {
+		t.Error("the oldest tombstone was not forgotten")
+	}
+}
+
+// TestNonceGuard_DoubleSession creates two sessions from copies of the same pair, for different messages.
+func TestNonceGuard_DoubleSession(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	_, secrets := helpers.GenerateSecrets(partyIDs, 1)
+	public := helpers.GeneratePublic(1, secrets)
+	signers := party.IDSlice{1, 2}
+	stores, published := preprocess(t, signers, 1)
+	pair, err := stores[1].ConsumeNext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitments := commitmentsAt(published, pair.Index)
+	guard := memoryNonceGuard(t)
+	// copyPair returns a copy of the pair, since NewOnlineRound wipes the pair it is given
+	copyPair := func() *NoncePair {
+		dup := *pair
+		return &dup
+	}
+
+	first, _, err := NewOnlineRound(signers, secrets[1], public, []byte("first"), copyPair(), commitments, WithNonceGuard(guard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.GenerateMessages(); err != nil {
+		t.Fatal(err)
+	}
+
+	others, err := GenerateNonces(1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, session := range map[string]struct {
+		signers     party.IDSlice
+		message     []byte
+		commitments map[party.ID]*NonceCommitment
+	}{
+		"message": {signers, []byte("second"), commitments},
+		"signers": {party.IDSlice{1, 3}, []byte("first"), map[party.ID]*NonceCommitment{3: others[0].Commitment()}},
+	} {
+		second, _, err := NewOnlineRound(session.signers, secrets[1], public, session.message, copyPair(), session.commitments, WithNonceGuard(guard))
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkNonceReuse(t, name, second)
+	}
+
+	// The share of a session which was created twice for the same message and signers is only sent once
+	stores, published = preprocess(t, signers, 1)
+	nonces := map[party.ID]*NoncePair{}
+	copies := map[party.ID]*NoncePair{}
+	for _, id := range signers {
+		if nonces[id], err = stores[id].ConsumeNext(); err != nil {
+			t.Fatal(err)
+		}
+		dup := *nonces[id]
+		copies[id] = &dup
+	}
+	message := []byte("message")
+	if sig := signOnline(t, signers, secrets, public, message, nonces, commitmentsAt(published, 0), guard); !public.GroupKey.Verify(message, sig) {
+		t.Error("invalid signature")
+	}
+	again, _, err := NewOnlineRound(signers, secrets[1], public, message, copies[1], commitmentsAt(published, 0), WithNonceGuard(guard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNonceReuse(t, "same session", again)
+}
+
+// TestNonceGuard_Snapshot restores a NonceStore from a backup taken before a pair was consumed,
+// and uses the pair again to sign another message.
+func TestNonceGuard_Snapshot(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	_, secrets := helpers.GenerateSecrets(partyIDs, 1)
+	public := helpers.GeneratePublic(1, secrets)
+	signers := party.IDSlice{1, 3}
+	stores, published := preprocess(t, signers, 2)
+	guard := memoryNonceGuard(t)
+
+	// The backup contains the encoded pairs of the store of party 1, which are read from the store and put back
+	var backup [][]byte
+	for index := uint32(0); index < 2; index++ {
+		pair, err := stores[1].Consume(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := pair.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		backup = append(backup, data)
+	}
+	restore := func() NonceStore {
+		pairs := make([]*NoncePair, len(backup))
+		for i, data := range backup {
+			pairs[i] = new(NoncePair)
+			if err := pairs[i].UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s := NewMemoryNonceStore()
+		if err := s.Add(pairs); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	stores[1] = restore()
+
+	nonces := map[party.ID]*NoncePair{}
+	for _, id := range signers {
+		var err error
+		if nonces[id], err = stores[id].Consume(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if sig := signOnline(t, signers, secrets, public, []byte("first"), nonces, commitmentsAt(published, 0), guard); !public.GroupKey.Verify([]byte("first"), sig) {
+		t.Fatal("invalid signature")
+	}
+
+	// The store is restored from the backup, and the pair 0 is available again
+	stores[1] = restore()
+	pair, err := stores[1].Consume(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _, err := NewOnlineRound(signers, secrets[1], public, []byte("second"), pair, commitmentsAt(published, 0), WithNonceGuard(guard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNonceReuse(t, "restored", r)
+
+	// The pairs which were not used are still available
+	if pair, err = stores[1].Consume(1); err != nil {
+		t.Fatal(err)
+	}
+	r, _, err = NewOnlineRound(signers, secrets[1], public, []byte("second"), pair, commitmentsAt(published, 1), WithNonceGuard(guard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.GenerateMessages(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestNonceGuard_RepeatedRandom creates two sessions with sources of randomness which return the same bytes.
+func TestNonceGuard_RepeatedRandom(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	_, secrets := helpers.GenerateSecrets(partyIDs, 1)
+	public := helpers.GeneratePublic(1, secrets)
+	signers := party.IDSlice{1, 2}
+	seed := bytes.Repeat([]byte{7}, 256)
+	guard := memoryNonceGuard(t)
+
+	first, _, err := NewRound(signers, secrets[1], public, []byte("first"), WithRandom(bytes.NewReader(seed)), WithNonceGuard(guard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.GenerateMessages(); err != nil {
+		t.Fatal(err)
+	}
+	second, _, err := NewRound(signers, secrets[1], public, []byte("second"), WithRandom(bytes.NewReader(seed)), WithNonceGuard(guard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNonceReuse(t, "repeated", second)
+
+	// The DefaultNonceGuard is used without WithNonceGuard, and is shared by all tests of the process
+	if _, err = rand.Read(seed); err != nil {
+		t.Fatal(err)
+	}
+	first, _, err = NewRound(signers, secrets[1], public, []byte("first"), WithRandom(bytes.NewReader(seed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.GenerateMessages(); err != nil {
+		t.Fatal(err)
+	}
+	second, _, err = NewRound(signers, secrets[1], public, []byte("first"), WithRandom(bytes.NewReader(seed)), WithTweak(scalar.NewScalarUInt32(42)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNonceReuse(t, "default", second)
+}
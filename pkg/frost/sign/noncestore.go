@@ -0,0 +1,122 @@
+package sign
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrNoncesExhausted is returned by NonceStore.ConsumeNext when all nonce pairs of the store were consumed.
+	ErrNoncesExhausted = errors.New("sign: all nonce pairs were consumed")
+	// ErrNonceConsumed is returned by NonceStore.Consume when the nonce pair was already consumed.
+	ErrNonceConsumed = errors.New("sign: nonce pair was already consumed")
+	// ErrUnknownNonce is returned by NonceStore.Consume when the store never contained the nonce pair.
+	ErrUnknownNonce = errors.New("sign: unknown nonce pair")
+	// ErrDuplicateNonce is returned by NonceStore.Add when the store already contains a pair with the same index.
+	ErrDuplicateNonce = errors.New("sign: duplicate nonce pair")
+)
+
+// A NonceStore holds the nonce pairs generated in the preprocessing stage until they are used to sign,
+// and guarantees that each pair is returned at most once.
+//
+// The package github.com/taurusgroup/frost-ed25519/pkg/frost/sign/noncefile provides an implementation
+// backed by a file, which keeps track of the consumed pairs across restarts of the process.
+type NonceStore interface {
+	// Add records pairs, whose indices must not be in the store, even if the pair was consumed.
+	// It must only return once the pairs are durable, and return an error wrapping ErrDuplicateNonce
+	// if an index is already in the store, in which case no pair is added.
+	Add(pairs []*NoncePair) error
+
+	// Consume atomically marks the pair with the given index as consumed, and returns it.
+	// The mark must be durable before the pair is returned, and the pair must never be returned again.
+	// It returns an error wrapping ErrNonceConsumed if the pair was already consumed,
+	// and ErrUnknownNonce if the store never contained it.
+	Consume(index uint32) (*NoncePair, error)
+
+	// ConsumeNext is like Consume, with the unconsumed pair of smallest index.
+	// It returns ErrNoncesExhausted if all pairs were consumed.
+	ConsumeNext() (*NoncePair, error)
+}
+
+// MemoryNonceStore is a NonceStore which keeps the pairs in memory.
+// The consumed pairs are forgotten when the process stops, so it must not be used with pairs whose commitments
+// were published to signers which outlive it.
+// It is safe for concurrent use.
+type MemoryNonceStore struct {
+	mtx sync.Mutex
+	// pairs contains the unconsumed pairs.
+	pairs map[uint32]*NoncePair
+	// consumed contains the indices of the consumed pairs.
+	consumed map[uint32]bool
+}
+
+// NewMemoryNonceStore returns an empty MemoryNonceStore.
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{
+		pairs:    make(map[uint32]*NoncePair),
+		consumed: make(map[uint32]bool),
+	}
+}
+
+// Add implements NonceStore. The store keeps copies of the pairs.
+func (s *MemoryNonceStore) Add(pairs []*NoncePair) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	seen := make(map[uint32]bool, len(pairs))
+	for _, p := range pairs {
+		if _, ok := s.pairs[p.Index]; ok || s.consumed[p.Index] || seen[p.Index] {
+			return fmt.Errorf("sign.MemoryNonceStore.Add: %w: index %d", ErrDuplicateNonce, p.Index)
+		}
+		seen[p.Index] = true
+	}
+	for _, p := range pairs {
+		pair := *p
+		s.pairs[p.Index] = &pair
+	}
+	return nil
+}
+
+// Consume implements NonceStore.
+func (s *MemoryNonceStore) Consume(index uint32) (*NoncePair, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.consume(index)
+}
+
+// ConsumeNext implements NonceStore.
+func (s *MemoryNonceStore) ConsumeNext() (*NoncePair, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if len(s.pairs) == 0 {
+		return nil, ErrNoncesExhausted
+	}
+	indices := make([]uint32, 0, len(s.pairs))
+	for index := range s.pairs {
+		indices = append(indices, index)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	return s.consume(indices[0])
+}
+
+// Remaining returns the number of unconsumed pairs.
+func (s *MemoryNonceStore) Remaining() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return len(s.pairs)
+}
+
+// consume removes the pair with the given index, and should be called with the lock held.
+func (s *MemoryNonceStore) consume(index uint32) (*NoncePair, error) {
+	if s.consumed[index] {
+		return nil, fmt.Errorf("sign.MemoryNonceStore.Consume: %w: index %d", ErrNonceConsumed, index)
+	}
+	pair, ok := s.pairs[index]
+	if !ok {
+		return nil, fmt.Errorf("sign.MemoryNonceStore.Consume: %w: index %d", ErrUnknownNonce, index)
+	}
+	delete(s.pairs, index)
+	s.consumed[index] = true
+	return pair, nil
+}
@@ -0,0 +1,54 @@
+package sign
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMemoryNonceStore(t *testing.T) {
+	pairs, err := GenerateNonces(3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewMemoryNonceStore()
+	if _, err = s.ConsumeNext(); !errors.Is(err, ErrNoncesExhausted) {
+		t.Errorf("error = %v, want ErrNoncesExhausted", err)
+	}
+	if err = s.Add(pairs); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Add(pairs[1:2]); !errors.Is(err, ErrDuplicateNonce) {
+		t.Errorf("error = %v, want ErrDuplicateNonce", err)
+	}
+
+	pair, err := s.Consume(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pair.Index != 1 || pair.d.Equal(&pairs[1].d) != 1 {
+		t.Error("Consume(1) returned another pair")
+	}
+	if _, err = s.Consume(1); !errors.Is(err, ErrNonceConsumed) {
+		t.Errorf("error = %v, want ErrNonceConsumed", err)
+	}
+	if _, err = s.Consume(7); !errors.Is(err, ErrUnknownNonce) {
+		t.Errorf("error = %v, want ErrUnknownNonce", err)
+	}
+
+	// A consumed pair cannot be added again
+	if err = s.Add(pairs[1:2]); !errors.Is(err, ErrDuplicateNonce) {
+		t.Errorf("error = %v, want ErrDuplicateNonce", err)
+	}
+
+	for _, want := range []uint32{0, 2} {
+		if pair, err = s.ConsumeNext(); err != nil || pair.Index != want {
+			t.Fatalf("ConsumeNext() = %v, %v, want the pair %d", pair, err, want)
+		}
+	}
+	if _, err = s.ConsumeNext(); !errors.Is(err, ErrNoncesExhausted) {
+		t.Errorf("error = %v, want ErrNoncesExhausted", err)
+	}
+	if s.Remaining() != 0 {
+		t.Errorf("%d remaining pairs", s.Remaining())
+	}
+}
@@ -0,0 +1,105 @@
+package sign
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// With preprocessed nonces, all signers know the commitments (D, E) of the others for the agreed nonce index
+// before the session starts, so that the Sign1 messages are not needed.
+// The online session then consists of a single round, in which every signer sends its Sign2 message.
+
+// onlineRound is the first round of a session whose commitments were preprocessed.
+// It computes the challenge and our signature share without receiving any message.
+type onlineRound struct {
+	*round0
+}
+
+// NewOnlineRound returns the first round of a signing session between partyIDs, in which the nonces were preprocessed.
+// nonces is our pair, consumed from a NonceStore, and commitments contains the commitments of the pair with the same index
+// of every other signer, taken from the NonceCommitments they published. If it contains ours, it must match nonces.
+// The session consists of a single round, in which each signer sends its Sign2 message.
+//
+// If the commitments of a signer are missing, invalid, or for another index, the error is a *CommitmentError,
+// and nothing is sent. Otherwise, the nonces are copied to the round and wiped from the given pair,
+// which must not be used again, even if the session aborts.
+// The other errors and the options are the same as for NewRound, except WithRandom, which is ignored.
+func NewOnlineRound(partyIDs party.IDSlice, secret *eddsa.SecretShare, shares *eddsa.Public, message []byte, nonces *NoncePair, commitments map[party.ID]*NonceCommitment, opts ...Option) (state.Round, *Output, error) {
+	partyIDs = party.NewIDSlice(partyIDs)
+	if !partyIDs.Contains(secret.ID) {
+		return nil, nil, errors.New("sign.NewOnlineRound: owner of SecretShare is not contained in partyIDs")
+	}
+
+	round, coefficients, err := newRound(secret.ID, partyIDs, shares, message, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sign.NewOnlineRound: %w", err)
+	}
+	if err = secret.Validate(shares); err != nil {
+		return nil, nil, fmt.Errorf("sign.NewOnlineRound: %w", err)
+	}
+
+	// All commitments are checked before any value depending on our secrets is computed
+	own := nonces.Commitment()
+	for _, id := range partyIDs {
+		c := commitments[id]
+		if id == round.SelfID() {
+			if c != nil && (c.Index != own.Index || c.D.Equal(&own.D) != 1 || c.E.Equal(&own.E) != 1) {
+				return nil, nil, fmt.Errorf("sign.NewOnlineRound: %w", &CommitmentError{ID: id, Err: errors.New("commitments do not match our nonces")})
+			}
+			c = own
+		}
+		if c == nil {
+			return nil, nil, fmt.Errorf("sign.NewOnlineRound: %w", &CommitmentError{ID: id, Err: ErrMissingCommitments})
+		}
+		if c.Index != nonces.Index {
+			return nil, nil, fmt.Errorf("sign.NewOnlineRound: %w", &CommitmentError{ID: id, Err: ErrNonceIndexMismatch})
+		}
+		if err = round.setCommitments(id, &messages.Sign1{Di: c.D, Ei: c.E}); err != nil {
+			return nil, nil, fmt.Errorf("sign.NewOnlineRound: %w", &CommitmentError{ID: id, Err: err})
+		}
+	}
+
+	round.d.Set(&nonces.d)
+	round.e.Set(&nonces.e)
+	nonces.Reset()
+	round.Preprocessed = true
+
+	round.SecretKeyShare.Set(&secret.Secret)
+	round.Lambda.Set(coefficients[round.SelfID()])
+	round.Signer = localSigner{round}
+	round.applyTweak()
+
+	return &onlineRound{round}, round.Output, nil
+}
+
+// AcceptedMessageTypes overrides the one of round0, since only Sign2 messages are exchanged.
+func (round *onlineRound) AcceptedMessageTypes() []messages.MessageType {
+	return []messages.MessageType{
+		messages.MessageTypeNone,
+		messages.MessageTypeSign2,
+	}
+}
+
+func (round *onlineRound) GenerateMessages() ([]*messages.Message, *state.Error) {
+	if err := round.bindNonces(); err != nil {
+		return nil, err
+	}
+	round.computeChallenge(round.SessionID())
+
+	z, err := round.respond()
+	if err != nil {
+		return nil, err
+	}
+	msg := messages.NewSign2(round.SelfID(), z)
+
+	return []*messages.Message{msg}, nil
+}
+
+func (round *onlineRound) NextRound() state.Round {
+	return &round2{&round1{round.round0}}
+}
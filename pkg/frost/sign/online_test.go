@@ -0,0 +1,170 @@
+package sign
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// preprocess generates a batch of count nonce pairs for each signer, and returns their stores and published commitments.
+func preprocess(t *testing.T, signers party.IDSlice, count int) (map[party.ID]NonceStore, map[party.ID]*NonceCommitments) {
+	stores := make(map[party.ID]NonceStore, len(signers))
+	published := make(map[party.ID]*NonceCommitments, len(signers))
+	for _, id := range signers {
+		pairs, err := GenerateNonces(count, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stores[id] = NewMemoryNonceStore()
+		if err = stores[id].Add(pairs); err != nil {
+			t.Fatal(err)
+		}
+		// The commitments are received through their encoding
+		data, err := NewNonceCommitments(id, pairs).MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var batch NonceCommitments
+		if err = batch.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		published[id] = &batch
+	}
+	return stores, published
+}
+
+// commitmentsAt returns the commitments of all signers for the nonce index.
+func commitmentsAt(published map[party.ID]*NonceCommitments, index uint32) map[party.ID]*NonceCommitment {
+	commitments := make(map[party.ID]*NonceCommitment, len(published))
+	for id, batch := range published {
+		commitments[id] = batch.Get(index)
+	}
+	return commitments
+}
+
+func TestNewOnlineRound(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3, 4, 5}
+	_, secrets := helpers.GenerateSecrets(partyIDs, 2)
+	public := helpers.GeneratePublic(2, secrets)
+	signers := party.IDSlice{1, 3, 5}
+	stores, published := preprocess(t, signers, 3)
+
+	for _, message := range [][]byte{[]byte("first"), []byte("second")} {
+		// The signers agree on the next index
+		states := make(map[party.ID]*state.State, len(signers))
+		outputs := make(map[party.ID]*Output, len(signers))
+		var index uint32
+		for _, id := range signers {
+			nonces, err := stores[id].ConsumeNext()
+			if err != nil {
+				t.Fatal(err)
+			}
+			index = nonces.Index
+			r, output, err := NewOnlineRound(signers, secrets[id], public, message, nonces, commitmentsAt(published, index))
+			if err != nil {
+				t.Fatal(err)
+			}
+			outputs[id] = output
+			if states[id], err = state.NewBaseState(r, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// A single message is sent by each signer
+		sent := make(map[party.ID]int, len(signers))
+		for _, id := range signers {
+			for _, msg := range states[id].ProcessAll() {
+				if msg.Type != messages.MessageTypeSign2 {
+					t.Fatalf("party %d sent a %s message", id, msg.Type)
+				}
+				sent[id]++
+				for _, other := range signers {
+					if other != id {
+						if err := states[other].HandleMessage(msg); err != nil {
+							t.Fatal(err)
+						}
+					}
+				}
+			}
+		}
+		for _, id := range signers {
+			if sent[id] != 1 {
+				t.Errorf("party %d sent %d messages", id, sent[id])
+			}
+			if msgs := states[id].ProcessAll(); len(msgs) != 0 {
+				t.Errorf("party %d sent %d messages after the first round", id, len(msgs))
+			}
+			if err := states[id].WaitForError(); err != nil {
+				t.Fatalf("party %d: %v", id, err)
+			}
+			if !public.GroupKey.Verify(message, outputs[id].Signature) {
+				t.Errorf("party %d: invalid signature at index %d", id, index)
+			}
+		}
+	}
+}
+
+func TestNewOnlineRound_Invalid(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	_, secrets := helpers.GenerateSecrets(partyIDs, 1)
+	public := helpers.GeneratePublic(1, secrets)
+	signers := party.IDSlice{1, 2}
+	_, published := preprocess(t, signers, 2)
+	message := []byte("message")
+
+	tests := map[string]struct {
+		commitments map[party.ID]*NonceCommitment
+		culprit     party.ID
+		err         error
+	}{
+		"missing": {
+			commitments: map[party.ID]*NonceCommitment{},
+			culprit:     2,
+			err:         ErrMissingCommitments,
+		},
+		"index": {
+			commitments: map[party.ID]*NonceCommitment{2: published[2].Get(1)},
+			culprit:     2,
+			err:         ErrNonceIndexMismatch,
+		},
+		"identity": {
+			commitments: map[party.ID]*NonceCommitment{2: {D: *ristretto.NewIdentityElement(), E: published[2].Get(0).E}},
+			culprit:     2,
+			err:         ErrIdentityCommitment,
+		},
+		"own": {
+			commitments: map[party.ID]*NonceCommitment{1: published[2].Get(0), 2: published[2].Get(0)},
+			culprit:     1,
+		},
+	}
+	for name, tc := range tests {
+		nonces, err := GenerateNonces(1, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = NewOnlineRound(signers, secrets[1], public, message, nonces[0], tc.commitments)
+		var commitmentErr *CommitmentError
+		if !errors.As(err, &commitmentErr) || commitmentErr.ID != tc.culprit || tc.err != nil && !errors.Is(err, tc.err) {
+			t.Errorf("%s: error = %v, want a CommitmentError of party %d", name, err, tc.culprit)
+		}
+	}
+
+	// The nonces are wiped once the round was created
+	nonces, _ := GenerateNonces(1, nil)
+	if _, _, err := NewOnlineRound(signers, secrets[1], public, message, nonces[0], map[party.ID]*NonceCommitment{2: published[2].Get(0)}); err != nil {
+		t.Fatal(err)
+	}
+	if nonces[0].d.Equal(ristretto.NewScalar()) != 1 || nonces[0].e.Equal(ristretto.NewScalar()) != 1 {
+		t.Error("the nonces were not wiped")
+	}
+
+	// The signers must be able to sign with the key
+	if _, _, err := NewOnlineRound(party.IDSlice{1}, secrets[1], public, message, nonces[0], nil); err == nil {
+		t.Error("NewOnlineRound() should fail with too few signers")
+	}
+}
@@ -4,4 +4,8 @@ import "github.com/taurusgroup/frost-ed25519/pkg/eddsa"
 
 type Output struct {
 	Signature *eddsa.Signature
+
+	// GroupKey is the key for which Signature is valid.
+	// It is the group key of the key generation, unless the protocol was run with WithTweak.
+	GroupKey *eddsa.PublicKey
 }
@@ -0,0 +1,28 @@
+package sign
+
+import (
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+)
+
+// WithVerifyPolicy returns an Option which makes the signers check the signature under policy before outputting it,
+// in addition to the verification in the Ristretto group, so that a signature is never output if the verifier
+// using policy would reject it. The default is eddsa.PolicyStandard, the policy of crypto/ed25519.
+//
+// The signatures of honest signers are accepted by every policy, except eddsa.PolicyStrict when the group key
+// has a small order, which does not happen for keys generated by the keygen protocol.
+// The option only changes the local check, so the signers may use different policies.
+func WithVerifyPolicy(policy eddsa.VerifyPolicy) Option {
+	return func(round *round0) {
+		round.VerifyPolicy = policy
+	}
+}
+
+// validatePolicy returns an error if the policy set by WithVerifyPolicy is unknown.
+func (round *round0) validatePolicy() error {
+	if round.VerifyPolicy > eddsa.PolicyZIP215 {
+		return fmt.Errorf("unknown verification policy %d", round.VerifyPolicy)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package sign
+
+import (
+	"crypto/sha512"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+func TestWithVerifyPolicy(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	publicKey, secrets, public := rfc8032PhKey(t, partyIDs, 1)
+	message := []byte("message")
+	digest := sha512.Sum512(message)
+
+	policies := []eddsa.VerifyPolicy{eddsa.PolicyStandard, eddsa.PolicyStrict, eddsa.PolicyCofactored, eddsa.PolicyZIP215}
+	for _, policy := range policies {
+		// The parties may use different policies
+		output, err := signWith(t, partyIDs, secrets, public, message, func(id party.ID) []Option {
+			if id == 1 {
+				return nil
+			}
+			return []Option{WithVerifyPolicy(policy)}
+		})
+		if err != nil {
+			t.Fatalf("%v: %v", policy, err)
+		}
+		for _, p := range policies {
+			if !p.Verify(publicKey, message, output.Signature.ToEd25519()) {
+				t.Errorf("signed with %v: the signature is rejected by %v", policy, p)
+			}
+		}
+
+		output, err = signWith(t, partyIDs, secrets, public, digest[:], func(party.ID) []Option {
+			return []Option{WithPrehash(), WithVerifyPolicy(policy)}
+		})
+		if err != nil {
+			t.Fatalf("%v: %v", policy, err)
+		}
+		if !policy.VerifyPrehashed(publicKey, digest[:], output.Signature.ToEd25519()) {
+			t.Errorf("%v: the Ed25519ph signature is rejected", policy)
+		}
+
+		output, err = signWith(t, partyIDs, secrets, public, message, func(party.ID) []Option {
+			return []Option{WithContext([]byte("context")), WithVerifyPolicy(policy)}
+		})
+		if err != nil {
+			t.Fatalf("%v: %v", policy, err)
+		}
+		if !policy.VerifyWithContext(publicKey, message, output.Signature.ToEd25519(), []byte("context")) {
+			t.Errorf("%v: the Ed25519ctx signature is rejected", policy)
+		}
+	}
+
+	if _, _, err := NewRound(partyIDs, secrets[1], public, message, WithVerifyPolicy(42)); err == nil {
+		t.Error("NewRound() should reject an unknown policy")
+	}
+}
@@ -0,0 +1,21 @@
+package sign
+
+import (
+	"errors"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+)
+
+// ErrPrecomputedMismatch is returned by NewRound when the values given to WithPrecomputed
+// were not computed for the signers, group key and public shares of the session.
+var ErrPrecomputedMismatch = errors.New("the precomputed values do not match the signers or the public shares")
+
+// WithPrecomputed returns an Option which makes NewRound use the Lagrange coefficients and public shares of p,
+// instead of computing them, which is useful when the same signers sign many messages.
+// p must have been returned by Public.Precompute, or by an eddsa.PrecomputeCache, for the signers of the session.
+// Unlike the other options, it does not change the protocol, so it can be used by some signers only.
+func WithPrecomputed(p *eddsa.Precomputed) Option {
+	return func(round *round0) {
+		round.Precomputed = p
+	}
+}
@@ -0,0 +1,108 @@
+package sign
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+func TestWithPrecomputed(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3, 4, 5}
+	_, secrets := helpers.GenerateSecrets(partyIDs, 2)
+	public := helpers.GeneratePublic(2, secrets)
+	signers := party.IDSlice{1, 3, 5}
+	message := []byte("message")
+
+	p, err := public.Precompute(signers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only some of the signers use the precomputed values
+	output, err := signWith(t, signers, secrets, public, message, func(id party.ID) []Option {
+		if id == 3 {
+			return nil
+		}
+		return []Option{WithPrecomputed(p)}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !public.GroupKey.Verify(message, output.Signature) {
+		t.Error("the signature is invalid")
+	}
+
+	// The precomputed values can be reused, and are not modified by a tweak
+	tweakScalar := scalar.NewScalarRandom()
+	tweak := func(party.ID) []Option { return []Option{WithPrecomputed(p), WithTweak(tweakScalar)} }
+	if _, err = signWith(t, signers, secrets, public, message, tweak); err != nil {
+		t.Fatal(err)
+	}
+	if output, err = signWith(t, signers, secrets, public, message, func(party.ID) []Option { return []Option{WithPrecomputed(p)} }); err != nil {
+		t.Fatal(err)
+	}
+	if !public.GroupKey.Verify(message, output.Signature) {
+		t.Error("the signature is invalid after reusing the precomputed values")
+	}
+
+	if _, _, err = NewRound(party.IDSlice{1, 2, 3}, secrets[1], public, message, WithPrecomputed(p)); !errors.Is(err, ErrPrecomputedMismatch) {
+		t.Errorf("NewRound() with other signers: error = %v, want ErrPrecomputedMismatch", err)
+	}
+	derived, err := public.Derive(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = NewRound(signers, secrets[1], derived, message, WithPrecomputed(p)); !errors.Is(err, ErrPrecomputedMismatch) {
+		t.Errorf("NewRound() with another group key: error = %v, want ErrPrecomputedMismatch", err)
+	}
+
+	// The same group key, with another public share for a signer
+	other := *public
+	other.Shares = make(map[party.ID]*ristretto.Element, len(public.Shares))
+	for id, share := range public.Shares {
+		other.Shares[id] = share
+	}
+	other.Shares[5] = new(ristretto.Element).Add(public.Shares[5], ristretto.NewGeneratorElement())
+	if _, _, err = NewRound(signers, secrets[1], &other, message, WithPrecomputed(p)); !errors.Is(err, ErrPrecomputedMismatch) {
+		t.Errorf("NewRound() with another public share: error = %v, want ErrPrecomputedMismatch", err)
+	}
+}
+
+// benchmarkNewRound measures the setup of a 3-of-5 signing session between the same three signers.
+func benchmarkNewRound(b *testing.B, precompute func(public *eddsa.Public, signers party.IDSlice) []Option) {
+	partyIDs := party.IDSlice{1, 2, 3, 4, 5}
+	_, secrets := helpers.GenerateSecrets(partyIDs, 2)
+	public := helpers.GeneratePublic(2, secrets)
+	signers := party.IDSlice{1, 3, 5}
+	message := []byte("message")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := NewRound(signers, secrets[1], public, message, precompute(public, signers)...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewRound(b *testing.B) {
+	benchmarkNewRound(b, func(*eddsa.Public, party.IDSlice) []Option { return nil })
+}
+
+func BenchmarkNewRound_PrecomputeCache(b *testing.B) {
+	cache, err := eddsa.NewPrecomputeCache(16)
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkNewRound(b, func(public *eddsa.Public, signers party.IDSlice) []Option {
+		p, err := cache.Precompute(public, signers)
+		if err != nil {
+			b.Fatal(err)
+		}
+		return []Option{WithPrecomputed(p)}
+	})
+}
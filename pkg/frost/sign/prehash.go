@@ -0,0 +1,52 @@
+package sign
+
+import (
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// WithPrehash returns an Option which makes the signers produce an Ed25519ph signature,
+// as defined in RFC 8032, Section 5.1, with an empty context.
+// The message given to NewRound must then be the 64 byte SHA-512 digest of the message,
+// which is useful when the message is too large to be sent to every signer.
+// The signature is accepted by eddsa.PublicKey.VerifyPrehashed, and by ed25519.VerifyWithOptions with
+// ed25519.Options{Hash: crypto.SHA512}, but not by ed25519.Verify.
+//
+// The mode is also part of the binding factors, so a signer which does not use this option
+// computes different nonces and challenge than the others. The session then aborts when the signature shares
+// are verified, and no signature is produced in either mode.
+func WithPrehash() Option {
+	return func(round *round0) {
+		round.Prehashed = true
+	}
+}
+
+// challenge returns c = H(R, GroupKey, Message), with the dom2 prefix in the Ed25519ph and Ed25519ctx modes.
+func (round *round0) challenge() *ristretto.Scalar {
+	switch {
+	case round.Prehashed:
+		return eddsa.ComputeChallengePrehashed(&round.R, &round.GroupKey, round.Message)
+	case round.Context != nil:
+		// The context was validated by NewRound
+		c, _ := eddsa.ComputeChallengeWithContext(&round.R, &round.GroupKey, round.Context, round.Message)
+		return c
+	default:
+		return eddsa.ComputeChallenge(&round.R, &round.GroupKey, round.Message)
+	}
+}
+
+// verify reports whether sig is valid for the message and the signing mode, under the VerifyPolicy of the round.
+func (round *round0) verify(sig *eddsa.Signature) bool {
+	groupKey, sigBytes := round.GroupKey.ToEd25519(), sig.ToEd25519()
+	switch {
+	case round.Prehashed:
+		return round.GroupKey.VerifyPrehashed(round.Message, sig) &&
+			round.VerifyPolicy.VerifyPrehashed(groupKey, round.Message, sigBytes)
+	case round.Context != nil:
+		return round.GroupKey.VerifyWithContext(round.Message, sig, round.Context) &&
+			round.VerifyPolicy.VerifyWithContext(groupKey, round.Message, sigBytes, round.Context)
+	default:
+		return round.GroupKey.Verify(round.Message, sig) &&
+			round.VerifyPolicy.Verify(groupKey, round.Message, sigBytes)
+	}
+}
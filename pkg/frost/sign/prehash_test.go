@@ -0,0 +1,124 @@
+package sign
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// rfc8032PhKey returns the key of the Ed25519ph test vector TEST abc of RFC 8032, Section 7.3,
+// shared between partyIDs with the given threshold.
+func rfc8032PhKey(t *testing.T, partyIDs party.IDSlice, threshold party.Size) (ed25519.PublicKey, map[party.ID]*eddsa.SecretShare, *eddsa.Public) {
+	seed, _ := hex.DecodeString("833fe62409237b9d62ec77587520911e9a759cec1d19755b7da901b96dca3d42")
+	publicKey := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+	if hex.EncodeToString(publicKey) != "ec172b93ad5e563bf4932c70e1245034c35467ef2efd4d64ebf819683467e2bf" {
+		t.Fatal("wrong public key")
+	}
+
+	expanded := sha512.Sum512(seed)
+	var secret ristretto.Scalar
+	_, _ = secret.SetBytesWithClamping(expanded[:32])
+	poly := polynomial.NewPolynomial(threshold, &secret)
+	secrets := make(map[party.ID]*eddsa.SecretShare, len(partyIDs))
+	shares := make(map[party.ID]*ristretto.Element, len(partyIDs))
+	for _, id := range partyIDs {
+		secrets[id] = eddsa.NewSecretShare(id, poly.Evaluate(id.Scalar()))
+		shares[id] = &secrets[id].Public
+	}
+	public, err := eddsa.NewPublic(shares, threshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return publicKey, secrets, public
+}
+
+// signWith runs the sign protocol between signers, where each signer uses the options returned by opts.
+func signWith(t *testing.T, signers party.IDSlice, secrets map[party.ID]*eddsa.SecretShare, public *eddsa.Public, message []byte, opts func(id party.ID) []Option) (*Output, error) {
+	states := make(map[party.ID]*state.State, len(signers))
+	outputs := make(map[party.ID]*Output, len(signers))
+	for _, id := range signers {
+		r, output, err := NewRound(signers, secrets[id], public, message, opts(id)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[id] = output
+		if states[id], err = state.NewBaseState(r, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runRounds(t, signers, states, 3)
+	for _, id := range signers {
+		if err := states[id].WaitForError(); err != nil {
+			return nil, err
+		}
+	}
+	return outputs[signers[0]], nil
+}
+
+func TestWithPrehash(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3, 4, 5}
+	publicKey, secrets, public := rfc8032PhKey(t, partyIDs, 2)
+	if !public.GroupKey.Equal(mustPublicKey(t, publicKey)) {
+		t.Fatal("the shares do not interpolate to the public key of the test vector")
+	}
+	digest := sha512.Sum512([]byte("abc"))
+	prehash := func(party.ID) []Option { return []Option{WithPrehash()} }
+
+	output, err := signWith(t, party.IDSlice{1, 3, 5}, secrets, public, digest[:], prehash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !public.GroupKey.VerifyPrehashed(digest[:], output.Signature) {
+		t.Error("the signature is not a valid Ed25519ph signature")
+	}
+	if ed25519.Verify(publicKey, []byte("abc"), output.Signature.ToEd25519()) || eddsa.Verify(publicKey, digest[:], output.Signature) {
+		t.Error("the signature should not be a valid Ed25519 signature")
+	}
+	verifyWithOptions(t, publicKey, digest[:], output.Signature, true, "")
+
+	// A signature of the digest without the option is a plain Ed25519 signature
+	output, err = signWith(t, party.IDSlice{1, 3, 5}, secrets, public, digest[:], func(party.ID) []Option { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(publicKey, digest[:], output.Signature.ToEd25519()) || public.GroupKey.VerifyPrehashed(digest[:], output.Signature) {
+		t.Error("the signature should be a plain Ed25519 signature")
+	}
+
+	if _, _, err = NewRound(partyIDs, secrets[1], public, []byte("abc"), WithPrehash()); err == nil {
+		t.Error("NewRound() should reject a message which is not a SHA-512 digest")
+	}
+}
+
+func TestWithPrehash_MixedModes(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	_, secrets, public := rfc8032PhKey(t, partyIDs, 2)
+	digest := sha512.Sum512([]byte("abc"))
+
+	// Party 2 signs the digest as a plain message
+	_, err := signWith(t, partyIDs, secrets, public, digest[:], func(id party.ID) []Option {
+		if id == 2 {
+			return nil
+		}
+		return []Option{WithPrehash()}
+	})
+	if !errors.Is(err, ErrValidateSigShare) {
+		t.Errorf("mixing signing modes: error = %v, want ErrValidateSigShare", err)
+	}
+}
+
+func mustPublicKey(t *testing.T, key ed25519.PublicKey) *eddsa.PublicKey {
+	pk, err := eddsa.NewPublicKeyFromEd25519(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pk
+}
@@ -0,0 +1,294 @@
+package sign
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// In the preprocessing stage of FROST, each signer generates a batch of nonce pairs (d, e) ahead of time,
+// keeps them in a NonceStore, and publishes the commitments (D, E) = ([d]•B, [e]•B) of the batch to the other signers
+// or to a coordinator, in a NonceCommitments.
+// Each pair has an index, and must be consumed from the store at most once, since signing two messages with the same
+// pair leaks the secret key share.
+
+const (
+	// sizeNoncePair is the size of the encoding of a NoncePair: index (4 bytes) ∥ d ∥ e.
+	sizeNoncePair = 4 + 32 + 32
+	// sizeNonceCommitment is the size of the encoding of a NonceCommitment: index (4 bytes) ∥ D ∥ E.
+	sizeNonceCommitment = 4 + 32 + 32
+)
+
+// ErrInvalidNonce is returned when decoding a NoncePair whose nonces are zero.
+var ErrInvalidNonce = errors.New("sign: nonce is zero")
+
+// NoncePair is a pair of secret nonces (d, e) generated in the preprocessing stage, with its index in the batch.
+// It must be used for a single signature, and wiped with Reset afterwards.
+type NoncePair struct {
+	// Index identifies the pair among the batches of the signer.
+	Index uint32
+
+	d, e ristretto.Scalar
+}
+
+// NonceCommitment contains the commitments D = [d]•B and E = [e]•B of a NoncePair, which are published to the other signers.
+type NonceCommitment struct {
+	Index uint32
+	D, E  ristretto.Element
+}
+
+// GenerateNonces returns count nonce pairs with the indices 0, …, count-1, whose nonces are read from r.
+// crypto/rand is used if r is nil.
+func GenerateNonces(count int, r io.Reader) ([]*NoncePair, error) {
+	return GenerateNoncesFrom(0, count, r)
+}
+
+// GenerateNoncesFrom is like GenerateNonces, with the indices first, …, first+count-1,
+// so that a signer can generate a new batch once the previous one was consumed.
+func GenerateNoncesFrom(first uint32, count int, r io.Reader) ([]*NoncePair, error) {
+	if count < 1 || uint64(count)-1 > math.MaxUint32-uint64(first) {
+		return nil, fmt.Errorf("sign.GenerateNonces: invalid number of nonces %d", count)
+	}
+	pairs := make([]*NoncePair, count)
+	for i := range pairs {
+		pair := &NoncePair{Index: first + uint32(i)}
+		if _, err := scalar.SetScalarRandomFrom(&pair.d, r); err != nil {
+			return nil, fmt.Errorf("sign.GenerateNonces: failed to sample nonce: %w", err)
+		}
+		if _, err := scalar.SetScalarRandomFrom(&pair.e, r); err != nil {
+			return nil, fmt.Errorf("sign.GenerateNonces: failed to sample nonce: %w", err)
+		}
+		pairs[i] = pair
+	}
+	return pairs, nil
+}
+
+// Commitment returns the commitments of the pair.
+func (p *NoncePair) Commitment() *NonceCommitment {
+	c := NonceCommitment{Index: p.Index}
+	c.D.ScalarBaseMult(&p.d)
+	c.E.ScalarBaseMult(&p.e)
+	return &c
+}
+
+// Reset zeroes the nonces of the pair.
+func (p *NoncePair) Reset() {
+	zero := ristretto.NewScalar()
+	p.d.Set(zero)
+	p.e.Set(zero)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The encoding contains the secret nonces, and is meant for a NonceStore.
+func (p *NoncePair) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 4, sizeNoncePair)
+	binary.BigEndian.PutUint32(data, p.Index)
+	data = append(data, p.d.Bytes()...)
+	data = append(data, p.e.Bytes()...)
+	return data, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// It returns ErrInvalidNonce if one of the nonces is zero.
+func (p *NoncePair) UnmarshalBinary(data []byte) error {
+	if len(data) != sizeNoncePair {
+		return fmt.Errorf("sign: nonce pair: expected %d bytes (got %d)", sizeNoncePair, len(data))
+	}
+	p.Index = binary.BigEndian.Uint32(data)
+	if _, err := p.d.SetCanonicalBytes(data[4:36]); err != nil {
+		return fmt.Errorf("sign: nonce pair: %w", err)
+	}
+	if _, err := p.e.SetCanonicalBytes(data[36:]); err != nil {
+		return fmt.Errorf("sign: nonce pair: %w", err)
+	}
+	zero := ristretto.NewScalar()
+	if p.d.Equal(zero) == 1 || p.e.Equal(zero) == 1 {
+		return ErrInvalidNonce
+	}
+	return nil
+}
+
+// NonceCommitments is a batch of commitments published by a signer in the preprocessing stage.
+//
+// Its binary encoding is
+//
+//	From (4 bytes) ∥ count (4 bytes) ∥ count × (Index (4 bytes) ∥ D ∥ E)
+//
+// where integers are big endian, and the commitments are sorted by strictly increasing index.
+type NonceCommitments struct {
+	// From is the signer which generated the nonces.
+	From party.ID
+	// Commitments are the commitments of the pairs, in increasing order of index.
+	Commitments []NonceCommitment
+}
+
+// NewNonceCommitments returns the batch of commitments of pairs, which from publishes to the other signers.
+// pairs must be sorted by strictly increasing index, as returned by GenerateNonces.
+func NewNonceCommitments(from party.ID, pairs []*NoncePair) *NonceCommitments {
+	commitments := make([]NonceCommitment, len(pairs))
+	for i, p := range pairs {
+		commitments[i] = *p.Commitment()
+	}
+	return &NonceCommitments{From: from, Commitments: commitments}
+}
+
+// Get returns the commitment with the given index, or nil if the batch does not contain it.
+func (b *NonceCommitments) Get(index uint32) *NonceCommitment {
+	for i := range b.Commitments {
+		if b.Commitments[i].Index == index {
+			return &b.Commitments[i]
+		}
+	}
+	return nil
+}
+
+// Validate checks that From is not 0, that the indices are strictly increasing,
+// and that no commitment is the identity.
+func (b *NonceCommitments) Validate() error {
+	if b.From == 0 {
+		return errors.New("sign: nonce commitments: sender is 0")
+	}
+	identity := ristretto.NewIdentityElement()
+	for i := range b.Commitments {
+		c := &b.Commitments[i]
+		if i > 0 && c.Index <= b.Commitments[i-1].Index {
+			return fmt.Errorf("sign: nonce commitments: index %d is not increasing", c.Index)
+		}
+		if c.D.Equal(identity) == 1 || c.E.Equal(identity) == 1 {
+			return fmt.Errorf("sign: nonce commitments: index %d: %w", c.Index, ErrIdentityCommitment)
+		}
+	}
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (b *NonceCommitments) MarshalBinary() ([]byte, error) {
+	if uint64(len(b.Commitments)) > math.MaxUint32 {
+		return nil, errors.New("sign: nonce commitments: too many commitments")
+	}
+	data := make([]byte, 0, party.IDByteSize+4+len(b.Commitments)*sizeNonceCommitment)
+	var index [4]byte
+	data = append(data, b.From.Bytes()...)
+	binary.BigEndian.PutUint32(index[:], uint32(len(b.Commitments)))
+	data = append(data, index[:]...)
+	for i := range b.Commitments {
+		c := &b.Commitments[i]
+		binary.BigEndian.PutUint32(index[:], c.Index)
+		data = append(data, index[:]...)
+		data = append(data, c.D.Bytes()...)
+		data = append(data, c.E.Bytes()...)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// The batch is checked with Validate.
+func (b *NonceCommitments) UnmarshalBinary(data []byte) error {
+	const header = party.IDByteSize + 4
+	if len(data) < header {
+		return errors.New("sign: nonce commitments: data is too short")
+	}
+	from, err := party.FromBytes(data)
+	if err != nil {
+		return fmt.Errorf("sign: nonce commitments: %w", err)
+	}
+	count := binary.BigEndian.Uint32(data[party.IDByteSize:])
+	data = data[header:]
+	if uint64(len(data)) != uint64(count)*sizeNonceCommitment {
+		return fmt.Errorf("sign: nonce commitments: expected %d commitments", count)
+	}
+
+	commitments := make([]NonceCommitment, count)
+	for i := range commitments {
+		c := &commitments[i]
+		c.Index = binary.BigEndian.Uint32(data)
+		if _, err = c.D.SetCanonicalBytes(data[4:36]); err != nil {
+			return fmt.Errorf("sign: nonce commitments: D: %w", err)
+		}
+		if _, err = c.E.SetCanonicalBytes(data[36:sizeNonceCommitment]); err != nil {
+			return fmt.Errorf("sign: nonce commitments: E: %w", err)
+		}
+		data = data[sizeNonceCommitment:]
+	}
+	batch := NonceCommitments{From: from, Commitments: commitments}
+	if err = batch.Validate(); err != nil {
+		return err
+	}
+	*b = batch
+	return nil
+}
+
+type jsonNonceCommitment struct {
+	Index uint32 `json:"index"`
+	D     string `json:"d"`
+	E     string `json:"e"`
+}
+
+type jsonNonceCommitments struct {
+	From        party.ID              `json:"from"`
+	Commitments []jsonNonceCommitment `json:"commitments"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// The commitments are encoded in hex.
+func (b *NonceCommitments) MarshalJSON() ([]byte, error) {
+	out := jsonNonceCommitments{
+		From:        b.From,
+		Commitments: make([]jsonNonceCommitment, len(b.Commitments)),
+	}
+	for i := range b.Commitments {
+		c := &b.Commitments[i]
+		out.Commitments[i] = jsonNonceCommitment{
+			Index: c.Index,
+			D:     hex.EncodeToString(c.D.Bytes()),
+			E:     hex.EncodeToString(c.E.Bytes()),
+		}
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The batch is checked with Validate.
+func (b *NonceCommitments) UnmarshalJSON(data []byte) error {
+	var out jsonNonceCommitments
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	commitments := make([]NonceCommitment, len(out.Commitments))
+	for i, c := range out.Commitments {
+		commitments[i].Index = c.Index
+		if err := decodeElement(&commitments[i].D, c.D); err != nil {
+			return fmt.Errorf("sign: nonce commitments: D: %w", err)
+		}
+		if err := decodeElement(&commitments[i].E, c.E); err != nil {
+			return fmt.Errorf("sign: nonce commitments: E: %w", err)
+		}
+	}
+	batch := NonceCommitments{From: out.From, Commitments: commitments}
+	if err := batch.Validate(); err != nil {
+		return err
+	}
+	*b = batch
+	return nil
+}
+
+// decodeElement sets e to the point encoded in hex by s.
+func decodeElement(e *ristretto.Element, s string) error {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	if len(data) != 32 {
+		return fmt.Errorf("expected 32 bytes (got %d)", len(data))
+	}
+	_, err = e.SetCanonicalBytes(data)
+	return err
+}
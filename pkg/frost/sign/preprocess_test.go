@@ -0,0 +1,129 @@
+package sign
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+func TestGenerateNonces(t *testing.T) {
+	pairs, err := GenerateNoncesFrom(10, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range pairs {
+		if p.Index != 10+uint32(i) {
+			t.Errorf("index %d, want %d", p.Index, 10+i)
+		}
+		var d, e ristretto.Element
+		c := p.Commitment()
+		if c.Index != p.Index || c.D.Equal(d.ScalarBaseMult(&p.d)) != 1 || c.E.Equal(e.ScalarBaseMult(&p.e)) != 1 {
+			t.Errorf("pair %d: invalid commitment", i)
+		}
+
+		data, err := p.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded NoncePair
+		if err = decoded.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Index != p.Index || decoded.d.Equal(&p.d) != 1 || decoded.e.Equal(&p.e) != 1 {
+			t.Errorf("pair %d: decoded pair differs", i)
+		}
+
+		p.Reset()
+		data, _ = p.MarshalBinary()
+		if err = decoded.UnmarshalBinary(data); !errors.Is(err, ErrInvalidNonce) {
+			t.Errorf("error = %v, want ErrInvalidNonce", err)
+		}
+	}
+
+	if _, err = GenerateNonces(0, nil); err == nil {
+		t.Error("GenerateNonces() should fail without nonces")
+	}
+	if _, err = GenerateNoncesFrom(^uint32(0), 2, nil); err == nil {
+		t.Error("GenerateNoncesFrom() should fail when the indices overflow")
+	}
+	if _, err = GenerateNonces(1, bytes.NewReader(make([]byte, 10))); err == nil {
+		t.Error("GenerateNonces() should fail with a short reader")
+	}
+}
+
+func TestNonceCommitments(t *testing.T) {
+	pairs, err := GenerateNonces(4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := NewNonceCommitments(3, pairs)
+	if err = batch.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if c := batch.Get(2); c == nil || c.D.Equal(&pairs[2].Commitment().D) != 1 {
+		t.Error("Get(2) did not return the commitment of the pair 2")
+	}
+	if batch.Get(4) != nil {
+		t.Error("Get(4) should return nil")
+	}
+
+	equal := func(a, b *NonceCommitments) bool {
+		if a.From != b.From || len(a.Commitments) != len(b.Commitments) {
+			return false
+		}
+		for i := range a.Commitments {
+			ca, cb := &a.Commitments[i], &b.Commitments[i]
+			if ca.Index != cb.Index || ca.D.Equal(&cb.D) != 1 || ca.E.Equal(&cb.E) != 1 {
+				return false
+			}
+		}
+		return true
+	}
+
+	data, err := batch.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded NonceCommitments
+	if err = decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !equal(batch, &decoded) {
+		t.Error("binary: decoded batch differs")
+	}
+	if err = decoded.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Error("UnmarshalBinary() should fail with truncated data")
+	}
+
+	data, err = json.Marshal(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded = NonceCommitments{}
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !equal(batch, &decoded) {
+		t.Error("json: decoded batch differs")
+	}
+
+	// The indices must be strictly increasing, and the commitments must not be the identity
+	invalid := NewNonceCommitments(3, pairs)
+	invalid.Commitments[1].Index = 0
+	data, _ = invalid.MarshalBinary()
+	if err = decoded.UnmarshalBinary(data); err == nil {
+		t.Error("UnmarshalBinary() should fail with a repeated index")
+	}
+	invalid = NewNonceCommitments(3, pairs)
+	invalid.Commitments[3].E.Set(ristretto.NewIdentityElement())
+	data, _ = json.Marshal(invalid)
+	if err = json.Unmarshal(data, &decoded); !errors.Is(err, ErrIdentityCommitment) {
+		t.Errorf("error = %v, want ErrIdentityCommitment", err)
+	}
+	if err = NewNonceCommitments(0, pairs).Validate(); err == nil {
+		t.Error("Validate() should fail without a sender")
+	}
+}
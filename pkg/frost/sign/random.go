@@ -0,0 +1,15 @@
+package sign
+
+import "io"
+
+// WithRandom returns an Option which makes the signer read its nonces d and e from r instead of crypto/rand.
+// It can be used to obtain entropy from an HSM, or to produce deterministic test vectors.
+// A reader which repeats its output across signing sessions leaks the secret key share,
+// since it makes the signer reuse its nonces for different messages, which the NonceGuard of the signer refuses.
+// If r fails or returns too few bytes, the protocol aborts with an error whose culprit is 0.
+// Unlike the other options, it does not change the messages, and signers may use it independently.
+func WithRandom(r io.Reader) Option {
+	return func(round *round0) {
+		round.Rand = r
+	}
+}
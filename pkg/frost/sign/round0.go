@@ -1,7 +1,6 @@
 package sign
 
 import (
-	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
 	"github.com/taurusgroup/frost-ed25519/pkg/messages"
 	"github.com/taurusgroup/frost-ed25519/pkg/state"
 )
@@ -11,16 +10,14 @@ func (round *round0) ProcessMessage(*messages.Message) *state.Error {
 }
 
 func (round *round0) GenerateMessages() ([]*messages.Message, *state.Error) {
+	if err := round.commit(); err != nil {
+		return nil, err
+	}
+	if err := round.bindNonces(); err != nil {
+		return nil, err
+	}
 	selfParty := round.Parties[round.SelfID()]
 
-	// Sample dᵢ, Dᵢ = [dᵢ] B
-	scalar.SetScalarRandom(&round.d)
-	selfParty.Di.ScalarBaseMult(&round.d)
-
-	// Sample eᵢ, Dᵢ = [eᵢ] B
-	scalar.SetScalarRandom(&round.e)
-	selfParty.Ei.ScalarBaseMult(&round.e)
-
 	msg := messages.NewSign1(round.SelfID(), &selfParty.Di, &selfParty.Ei)
 
 	return []*messages.Message{msg}, nil
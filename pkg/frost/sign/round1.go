@@ -1,84 +1,69 @@
 package sign
 
 import (
-	"crypto/sha512"
 	"errors"
 
-	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/hashing"
 	"github.com/taurusgroup/frost-ed25519/pkg/messages"
 	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 	"github.com/taurusgroup/frost-ed25519/pkg/state"
 )
 
-var hashDomainSeparation = []byte("FROST-SHA512")
+var ErrIdentityCommitment = errors.New("commitment Ei or Di was the identity")
 
 func (round *round1) ProcessMessage(msg *messages.Message) *state.Error {
-	id := msg.From
-	otherParty := round.Parties[id]
-	identity := ristretto.NewIdentityElement()
-	if msg.Sign1.Di.Equal(identity) == 1 || msg.Sign1.Ei.Equal(identity) == 1 {
-		return state.NewError(id, errors.New("commitment Ei or Di was the identity"))
+	if err := round.setCommitments(msg.From, msg.Sign1); err != nil {
+		return state.NewErrorWithKind(msg.From, state.KindInvalidCommitment, err)
 	}
-	otherParty.Di.Set(&msg.Sign1.Di)
-	otherParty.Ei.Set(&msg.Sign1.Ei)
 	return nil
 }
 
-func (round *round1) computeRhos() {
-	/*
-		While profiling, we noticed that using hash.Hash forces all values to be allocated on the heap.
-		To prevent this, we can simply create a big buffer on the stack and call sha512.Sum().
-
-		We need to compute a very simple hash N times, and Go's caching isn't great for hashing.
-		Therefore, we can simply change the buffer and rehash it many times.
-	*/
-	messageHash := sha512.Sum512(round.Message)
-
-	sizeB := int(round.PartyIDs().N() * (party.IDByteSize + 32 + 32))
-	bufferHeader := len(hashDomainSeparation) + party.IDByteSize + len(messageHash)
-	sizeBuffer := bufferHeader + sizeB
-	offsetID := len(hashDomainSeparation)
-
-	// We compute the binding factor 𝜌_{i} for each party as such:
-	//
-	//     𝜌_d = SHA-512 ("FROST-SHA512" ∥ i ∥ SHA-512(Message) ∥ B )
-	//
-	// For each party ID i.
-	//
-	// The list B is the concatenation of ( j ∥ Dⱼ ∥ Eⱼ ) for all signers j in sorted order.
-	//     B = (ID1 ∥ D₁ ∥ E₁) ∥ (ID_2 ∥ D₂ ∥ E₂) ∥ ... ∥ (ID_N ∥ D_N ∥ E_N)
-
-	// We compute the big buffer "FROST-SHA512" ∥ ... ∥ SHA-512(Message) ∥ B
-	// and remember the offset of ... . Later we will write the ID of each party at this place.
-	buffer := make([]byte, 0, sizeBuffer)
-	buffer = append(buffer, hashDomainSeparation...)
-	buffer = append(buffer, round.SelfID().Bytes()...)
-	buffer = append(buffer, messageHash[:]...)
+// setCommitments stores the commitments Dᵢ, Eᵢ of the signer id, after checking that they are not the identity.
+func (round *round0) setCommitments(id party.ID, commitments *messages.Sign1) error {
+	identity := ristretto.NewIdentityElement()
+	if commitments.Di.Equal(identity) == 1 || commitments.Ei.Equal(identity) == 1 {
+		return ErrIdentityCommitment
+	}
+	otherParty := round.Parties[id]
+	otherParty.Di.Set(&commitments.Di)
+	otherParty.Ei.Set(&commitments.Ei)
+	return nil
+}
 
-	// compute B
+// computeRhos computes the binding factor ρ of every signer, from the commitments Dᵢ, Eᵢ of all signers.
+func (round *round0) computeRhos(sessionID messages.SessionID) {
+	commitments := make([]hashing.Commitment, 0, len(round.PartyIDs()))
 	for _, id := range round.PartyIDs() {
 		otherParty := round.Parties[id]
-		buffer = append(buffer, id.Bytes()...)
-		buffer = append(buffer, otherParty.Di.Bytes()...)
-		buffer = append(buffer, otherParty.Ei.Bytes()...)
+		commitments = append(commitments, hashing.Commitment{ID: id, D: &otherParty.Di, E: &otherParty.Ei})
+	}
+	rhos := hashing.BindingFactors(round.Prehashed, sessionID, round.Message, commitments)
+	for i, id := range round.PartyIDs() {
+		round.Parties[id].Pi.Set(&rhos[i])
 	}
+}
 
-	for _, id := range round.PartyIDs() {
-		// Update the four bytes with the ID
-		copy(buffer[offsetID:], id.Bytes())
+func (round *round1) GenerateMessages() ([]*messages.Message, *state.Error) {
+	round.computeChallenge(round.SessionID())
 
-		// Pi = ρ = H ("FROST-SHA512" ∥ Message ∥ B ∥ ID )
-		digest := sha512.Sum512(buffer)
-		_, _ = round.Parties[id].Pi.SetUniformBytes(digest[:])
+	z, err := round.respond()
+	if err != nil {
+		return nil, err
 	}
+	msg := messages.NewSign2(round.SelfID(), z)
+
+	return []*messages.Message{msg}, nil
 }
 
-func (round *round1) GenerateMessages() ([]*messages.Message, *state.Error) {
-	round.computeRhos()
+// computeChallenge computes the binding factors ρᵢ, the commitments Rᵢ of every signer and their sum R,
+// and the challenge c = H(R, GroupKey, Message).
+func (round *round0) computeChallenge(sessionID messages.SessionID) {
+	round.computeRhos(sessionID)
 
 	round.R.Set(ristretto.NewIdentityElement())
-	for _, p := range round.Parties {
+	for _, id := range round.PartyIDs() {
+		p := round.Parties[id]
 		// TODO Find a way to do this faster since we don't need constant time
 		// Ri = D + [ρ] E
 		p.Ri.ScalarMult(&p.Pi, &p.Ei)
@@ -89,21 +74,7 @@ func (round *round1) GenerateMessages() ([]*messages.Message, *state.Error) {
 	}
 
 	// c = H(R, GroupKey, M)
-	round.C.Set(eddsa.ComputeChallenge(&round.R, &round.GroupKey, round.Message))
-
-	selfParty := round.Parties[round.SelfID()]
-
-	// Compute z = d + (e • ρ) + 𝛌 • s • c
-	// Note: since we multiply the secret by the Lagrange coefficient,
-	// can ignore 𝛌=1
-	secretShare := &selfParty.Zi
-	secretShare.Multiply(&round.SecretKeyShare, &round.C)         // s • c
-	secretShare.MultiplyAdd(&round.e, &selfParty.Pi, secretShare) // (e • ρ) + s • c
-	secretShare.Add(secretShare, &round.d)                        // d + (e • ρ) + 𝛌 • s • c
-
-	msg := messages.NewSign2(round.SelfID(), secretShare)
-
-	return []*messages.Message{msg}, nil
+	round.C.Set(round.challenge())
 }
 
 func (round *round1) NextRound() state.Round {
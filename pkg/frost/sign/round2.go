@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
 	"github.com/taurusgroup/frost-ed25519/pkg/messages"
 	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 	"github.com/taurusgroup/frost-ed25519/pkg/state"
@@ -16,26 +17,35 @@ var (
 
 func (round *round2) ProcessMessage(msg *messages.Message) *state.Error {
 	id := msg.From
+	if !round.verifyShare(id, &msg.Sign2.Zi) {
+		return state.NewErrorWithKind(id, state.KindInvalidSignatureShare, ErrValidateSigShare)
+	}
+	round.Parties[id].Zi.Set(&msg.Sign2.Zi)
+	return nil
+}
+
+// verifyShare reports whether z is a valid signature share of the signer id, that is
+//
+//	[z]•B = Rᵢ + [c]•(𝛌ᵢ•Aᵢ)
+//
+// where 𝛌ᵢ•Aᵢ also includes the tweak for the first signer.
+func (round *round0) verifyShare(id party.ID, z *ristretto.Scalar) bool {
 	otherParty := round.Parties[id]
 
 	var publicNeg, RPrime ristretto.Element
 	publicNeg.Negate(&otherParty.Public)
 
 	// RPrime = [c](-A) + [s]B
-	RPrime.VarTimeDoubleScalarBaseMult(&round.C, &publicNeg, &msg.Sign2.Zi)
-	if RPrime.Equal(&otherParty.Ri) != 1 {
-		return state.NewError(id, ErrValidateSigShare)
-	}
-	otherParty.Zi.Set(&msg.Sign2.Zi)
-	return nil
+	RPrime.VarTimeDoubleScalarBaseMult(&round.C, &publicNeg, z)
+	return RPrime.Equal(&otherParty.Ri) == 1
 }
 
 func (round *round2) GenerateMessages() ([]*messages.Message, *state.Error) {
 	// S = ∑ sᵢ
 	S := ristretto.NewScalar()
-	for _, otherParty := range round.Parties {
+	for _, id := range round.PartyIDs() {
 		// s += sᵢ
-		S.Add(S, &otherParty.Zi)
+		S.Add(S, &round.Parties[id].Zi)
 	}
 
 	sig := &eddsa.Signature{
@@ -43,11 +53,13 @@ func (round *round2) GenerateMessages() ([]*messages.Message, *state.Error) {
 		S: *S,
 	}
 
-	if !round.GroupKey.Verify(round.Message, sig) {
-		return nil, state.NewError(0, ErrValidateSignature)
+	if !round.verify(sig) {
+		return nil, state.NewErrorWithKind(0, state.KindInvalidSignature, ErrValidateSignature)
 	}
 
 	round.Output.Signature = sig
+	groupKey := round.GroupKey
+	round.Output.GroupKey = &groupKey
 
 	return nil, nil
 }
@@ -0,0 +1,156 @@
+package sign
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// A ShareSigner performs the operations of a signer which involve its nonces and its secret key share,
+// so that they can be kept outside of the process, for instance in an HSM or a remote enclave.
+// The rounds only handle the public values of the session.
+//
+// NewRound uses an implementation which keeps the nonces and the SecretShare in memory,
+// and NewRoundWithShareSigner uses the given one.
+type ShareSigner interface {
+	// Commit samples the nonces d and e of a new session, and returns their commitments D = [d]•B and E = [e]•B.
+	Commit() (D, E *ristretto.Element, err error)
+
+	// Respond returns the signature share z = d + (e • ρ) + 𝛌 • s • c for the nonces of the last call to Commit,
+	// where s is the secret key share, and deletes the nonces, so that they are never used for another response.
+	Respond(rho, lambda, c *ristretto.Scalar) (z *ristretto.Scalar, err error)
+}
+
+// ShareSignerError is wrapped by the error of a session which was aborted because its ShareSigner failed,
+// or returned an invalid value.
+type ShareSignerError struct {
+	// Err is the error returned by the ShareSigner, or the reason why its value is invalid.
+	Err error
+}
+
+// Error implements error
+func (e *ShareSignerError) Error() string {
+	return fmt.Sprintf("share signer: %v", e.Err)
+}
+
+// Unwrap returns the reason.
+func (e *ShareSignerError) Unwrap() error {
+	return e.Err
+}
+
+// localSigner is the ShareSigner used by NewRound, which keeps the nonces and the secret key share in the round,
+// so that they are wiped by Reset.
+type localSigner struct {
+	round *round0
+}
+
+// Commit implements ShareSigner by reading d and e from the Rand of the round.
+func (s localSigner) Commit() (*ristretto.Element, *ristretto.Element, error) {
+	round := s.round
+	// Sample dᵢ, Dᵢ = [dᵢ] B
+	if _, err := scalar.SetScalarRandomFrom(&round.d, round.Rand); err != nil {
+		return nil, nil, fmt.Errorf("failed to sample nonce: %w", err)
+	}
+	// Sample eᵢ, Eᵢ = [eᵢ] B
+	if _, err := scalar.SetScalarRandomFrom(&round.e, round.Rand); err != nil {
+		return nil, nil, fmt.Errorf("failed to sample nonce: %w", err)
+	}
+	return new(ristretto.Element).ScalarBaseMult(&round.d), new(ristretto.Element).ScalarBaseMult(&round.e), nil
+}
+
+// Respond implements ShareSigner. The nonces are wiped by Reset, once the session has finished.
+func (s localSigner) Respond(rho, lambda, c *ristretto.Scalar) (*ristretto.Scalar, error) {
+	round := s.round
+	var z ristretto.Scalar
+	z.Multiply(lambda, &round.SecretKeyShare) // 𝛌 • s
+	z.Multiply(&z, c)                         // 𝛌 • s • c
+	z.MultiplyAdd(&round.e, rho, &z)          // (e • ρ) + 𝛌 • s • c
+	z.Add(&z, &round.d)                       // d + (e • ρ) + 𝛌 • s • c
+	return &z, nil
+}
+
+// NewRoundWithShareSigner is like NewRound, for the signer selfID whose nonces and secret key share are held by signer.
+// Since the secret share is not available, it is not checked against shares. Instead, the signature share returned by
+// signer is verified against the public share of selfID before it is sent.
+// If signer returns an error or an invalid value, the session aborts with an error wrapping a *ShareSignerError,
+// whose culprit is 0.
+// WithRandom has no effect, since the nonces are sampled by signer.
+func NewRoundWithShareSigner(partyIDs party.IDSlice, selfID party.ID, shares *eddsa.Public, message []byte, signer ShareSigner, opts ...Option) (state.Round, *Output, error) {
+	partyIDs = party.NewIDSlice(partyIDs)
+	if !partyIDs.Contains(selfID) {
+		return nil, nil, errors.New("sign.NewRoundWithShareSigner: selfID is not contained in partyIDs")
+	}
+	if signer == nil {
+		return nil, nil, errors.New("sign.NewRoundWithShareSigner: no ShareSigner")
+	}
+
+	round, coefficients, err := newRound(selfID, partyIDs, shares, message, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sign.NewRoundWithShareSigner: %w", err)
+	}
+	round.Lambda.Set(coefficients[selfID])
+	round.Signer = signer
+	round.applyTweak()
+
+	return round, round.Output, nil
+}
+
+// commit obtains our commitments Dᵢ and Eᵢ from the ShareSigner.
+func (round *round0) commit() *state.Error {
+	D, E, err := round.Signer.Commit()
+	if err != nil {
+		return round.signerError(err)
+	}
+	identity := ristretto.NewIdentityElement()
+	if D == nil || E == nil || D.Equal(identity) == 1 || E.Equal(identity) == 1 {
+		return round.signerError(ErrIdentityCommitment)
+	}
+	selfParty := round.Parties[round.SelfID()]
+	selfParty.Di.Set(D)
+	selfParty.Ei.Set(E)
+	return nil
+}
+
+// respond computes our signature share zᵢ with the ShareSigner, once the challenge was computed,
+// after checking with the NonceGuard that our nonces were not used for another share.
+// The share of an external ShareSigner is verified before it is sent.
+func (round *round0) respond() (*ristretto.Scalar, *state.Error) {
+	if round.bound == nil {
+		return nil, state.NewError(0, fmt.Errorf("%w: nonces were not bound", ErrNonceReuse))
+	}
+	if err := round.guard().Respond(round.bound.id, round.bound.binding); err != nil {
+		round.wipeNonces()
+		return nil, state.NewError(0, err)
+	}
+	selfParty := round.Parties[round.SelfID()]
+	z, err := round.Signer.Respond(&selfParty.Pi, &round.Lambda, &round.C)
+	if err != nil {
+		return nil, round.signerError(err)
+	}
+	if z == nil {
+		return nil, round.signerError(errors.New("no signature share"))
+	}
+	selfParty.Zi.Set(z)
+	if round.Tweak != nil && round.SelfID() == round.PartyIDs()[0] {
+		// zᵢ += t • c
+		selfParty.Zi.MultiplyAdd(round.Tweak, &round.C, &selfParty.Zi)
+	}
+	if _, local := round.Signer.(localSigner); !local && !round.verifyShare(round.SelfID(), &selfParty.Zi) {
+		return nil, round.signerError(ErrValidateSigShare)
+	}
+	return &selfParty.Zi, nil
+}
+
+// signerError returns the error aborting the session when the ShareSigner failed.
+// The errors of the default ShareSigner are not wrapped in a *ShareSignerError.
+func (round *round0) signerError(err error) *state.Error {
+	if _, local := round.Signer.(localSigner); local {
+		return state.NewError(0, fmt.Errorf("sign: %w", err))
+	}
+	return state.NewError(0, fmt.Errorf("sign: %w", &ShareSignerError{Err: err}))
+}
@@ -0,0 +1,162 @@
+package sign
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// fakeHSM is a ShareSigner which keeps the secret share and the nonces away from the rounds,
+// and can be made to fail.
+type fakeHSM struct {
+	secret ristretto.Scalar
+	d, e   *ristretto.Scalar
+
+	commitErr, respondErr error
+	// corrupt makes Respond return an invalid share.
+	corrupt bool
+	// responses is the number of calls to Respond which returned a share.
+	responses int
+}
+
+func (h *fakeHSM) Commit() (*ristretto.Element, *ristretto.Element, error) {
+	if h.commitErr != nil {
+		return nil, nil, h.commitErr
+	}
+	h.d, h.e = scalar.NewScalarRandom(), scalar.NewScalarRandom()
+	return new(ristretto.Element).ScalarBaseMult(h.d), new(ristretto.Element).ScalarBaseMult(h.e), nil
+}
+
+func (h *fakeHSM) Respond(rho, lambda, c *ristretto.Scalar) (*ristretto.Scalar, error) {
+	if h.respondErr != nil {
+		return nil, h.respondErr
+	}
+	if h.d == nil {
+		return nil, errors.New("no nonces")
+	}
+	var z ristretto.Scalar
+	z.Multiply(lambda, &h.secret)
+	z.Multiply(&z, c)
+	z.MultiplyAdd(h.e, rho, &z)
+	z.Add(&z, h.d)
+	h.d, h.e = nil, nil
+	if h.corrupt {
+		z.Add(&z, scalar.NewScalarUInt32(1))
+	}
+	h.responses++
+	return &z, nil
+}
+
+// signWithHSMs runs a signing session in which the signers in hsms use them, and the others use NewRound.
+func signWithHSMs(t *testing.T, signers party.IDSlice, secrets map[party.ID]*eddsa.SecretShare, public *eddsa.Public, message []byte, hsms map[party.ID]*fakeHSM, opts ...Option) (map[party.ID]*state.State, *Output) {
+	states := make(map[party.ID]*state.State, len(signers))
+	outputs := make(map[party.ID]*Output, len(signers))
+	for _, id := range signers {
+		var (
+			r   state.Round
+			err error
+		)
+		if hsm, ok := hsms[id]; ok {
+			r, outputs[id], err = NewRoundWithShareSigner(signers, id, public, message, hsm, opts...)
+		} else {
+			r, outputs[id], err = NewRound(signers, secrets[id], public, message, opts...)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if states[id], err = state.NewBaseState(r, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Messages to a signer which aborted are rejected
+	for i := 0; i < 3; i++ {
+		var out []*messages.Message
+		for _, id := range signers {
+			out = append(out, states[id].ProcessAll()...)
+		}
+		for _, msg := range out {
+			for _, id := range signers {
+				if id != msg.From {
+					_ = states[id].HandleMessage(msg)
+				}
+			}
+		}
+	}
+	return states, outputs[signers[0]]
+}
+
+func TestNewRoundWithShareSigner(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3, 4}
+	_, secrets := helpers.GenerateSecrets(partyIDs, 2)
+	public := helpers.GeneratePublic(2, secrets)
+	signers := party.IDSlice{1, 2, 4}
+	message := []byte("message")
+
+	for name, opts := range map[string][]Option{
+		"plain": nil,
+		"tweak": {WithTweak(scalar.NewScalarUInt32(42))},
+	} {
+		hsms := map[party.ID]*fakeHSM{}
+		for _, id := range []party.ID{1, 4} {
+			hsms[id] = &fakeHSM{}
+			hsms[id].secret.Set(&secrets[id].Secret)
+		}
+		states, output := signWithHSMs(t, signers, secrets, public, message, hsms, opts...)
+		for _, id := range signers {
+			if err := states[id].WaitForError(); err != nil {
+				t.Fatalf("%s: party %d: %v", name, id, err)
+			}
+		}
+		if !output.GroupKey.Verify(message, output.Signature) {
+			t.Errorf("%s: invalid signature", name)
+		}
+		for id, hsm := range hsms {
+			if hsm.responses != 1 || hsm.d != nil {
+				t.Errorf("%s: party %d: %d responses", name, id, hsm.responses)
+			}
+		}
+	}
+}
+
+func TestNewRoundWithShareSigner_Errors(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	_, secrets := helpers.GenerateSecrets(partyIDs, 1)
+	public := helpers.GeneratePublic(1, secrets)
+	signers := party.IDSlice{1, 3}
+	message := []byte("message")
+	backendErr := errors.New("HSM unavailable")
+
+	for name, hsm := range map[string]*fakeHSM{
+		"commit":  {commitErr: backendErr},
+		"respond": {respondErr: backendErr},
+		"corrupt": {corrupt: true},
+	} {
+		hsm.secret.Set(&secrets[3].Secret)
+		states, _ := signWithHSMs(t, signers, secrets, public, message, map[party.ID]*fakeHSM{3: hsm})
+
+		// The session of the signer with the HSM aborts before its share is sent
+		err := states[3].WaitForError()
+		var stateErr *state.Error
+		var signerErr *ShareSignerError
+		if !errors.As(err, &stateErr) || !errors.As(err, &signerErr) || stateErr.Culprit() != 0 {
+			t.Fatalf("%s: error = %v, want a ShareSignerError", name, err)
+		}
+		if name != "corrupt" && !errors.Is(err, backendErr) || name == "corrupt" && !errors.Is(err, ErrValidateSigShare) {
+			t.Errorf("%s: error = %v", name, err)
+		}
+	}
+
+	if _, _, err := NewRoundWithShareSigner(signers, 2, public, message, &fakeHSM{}); err == nil {
+		t.Error("NewRoundWithShareSigner() should fail when selfID is not a signer")
+	}
+	if _, _, err := NewRoundWithShareSigner(signers, 1, public, message, nil); err == nil {
+		t.Error("NewRoundWithShareSigner() should fail without a ShareSigner")
+	}
+}
@@ -0,0 +1,85 @@
+package sign
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// A Transcript contains the public values of a signing session, which are computed from the Sign1 messages of all signers.
+// It is used to verify the signature shares of the Sign2 messages without taking part in the session,
+// for instance by a coordinator which relays the messages, and must point at the signer who caused an abort.
+//
+// The verification is the same as the one done by the signers in the last round.
+type Transcript struct {
+	round *round0
+}
+
+// NewTranscript returns the Transcript of the signing session between signers, with the given public shares and message.
+// commitments must contain the Sign1 message of every signer, and sessionID must be the one given to state.WithSessionID,
+// or the zero value if none was given. The options opts must be the ones given to NewRound by the signers.
+//
+// If a signer is missing from commitments, or sent the identity as commitment, the error is a *CommitmentError.
+func NewTranscript(signers party.IDSlice, public *eddsa.Public, message []byte, sessionID messages.SessionID, commitments map[party.ID]*messages.Sign1, opts ...Option) (*Transcript, error) {
+	signers = party.NewIDSlice(signers)
+	if len(signers) == 0 {
+		return nil, errors.New("sign.NewTranscript: no signers")
+	}
+	round, _, err := newRound(signers[0], signers, public, message, opts)
+	if err != nil {
+		return nil, fmt.Errorf("sign.NewTranscript: %w", err)
+	}
+	round.applyTweak()
+
+	for _, id := range signers {
+		c, ok := commitments[id]
+		if !ok || c == nil {
+			return nil, fmt.Errorf("sign.NewTranscript: %w", &CommitmentError{ID: id, Err: ErrMissingCommitments})
+		}
+		if err = round.setCommitments(id, c); err != nil {
+			return nil, fmt.Errorf("sign.NewTranscript: %w", &CommitmentError{ID: id, Err: err})
+		}
+	}
+	round.computeChallenge(sessionID)
+	return &Transcript{round: round}, nil
+}
+
+// Challenge returns the challenge c = H(R, GroupKey, Message) of the session.
+func (t *Transcript) Challenge() *ristretto.Scalar {
+	return ristretto.NewScalar().Set(&t.round.C)
+}
+
+// Commitment returns the commitment R of the signature, which is the sum of the commitments Rᵢ = Dᵢ + [ρᵢ]•Eᵢ of the signers.
+func (t *Transcript) Commitment() *ristretto.Element {
+	return new(ristretto.Element).Set(&t.round.R)
+}
+
+// VerifyShare reports whether z is a valid signature share of the signer id, that is
+//
+//	[z]•B = Rᵢ + [c]•(𝛌ᵢ•Aᵢ)
+//
+// where Rᵢ is computed from the binding factor ρᵢ, c is the challenge, 𝛌ᵢ is the Lagrange coefficient of id
+// for the signers, and Aᵢ is the public share of id. It returns false if id is not a signer.
+func (t *Transcript) VerifyShare(id party.ID, z *ristretto.Scalar) bool {
+	if !t.round.PartyIDs().Contains(id) {
+		return false
+	}
+	return t.round.verifyShare(id, z)
+}
+
+// Culprits returns the signers whose share in shares is invalid, or missing, in increasing order.
+// It returns nil if all shares are valid, in which case they can be combined into a valid signature.
+func (t *Transcript) Culprits(shares map[party.ID]*messages.Sign2) party.IDSlice {
+	var culprits party.IDSlice
+	for _, id := range t.round.PartyIDs() {
+		share, ok := shares[id]
+		if !ok || share == nil || !t.VerifyShare(id, &share.Zi) {
+			culprits = append(culprits, id)
+		}
+	}
+	return culprits
+}
@@ -0,0 +1,139 @@
+package sign
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// recordSession runs a signing session between signers, and returns the Sign1 and Sign2 messages they sent.
+func recordSession(t *testing.T, signers party.IDSlice, secrets map[party.ID]*eddsa.SecretShare, public *eddsa.Public, message []byte, sessionID messages.SessionID, opts ...Option) (map[party.ID]*messages.Sign1, map[party.ID]*messages.Sign2) {
+	states := make(map[party.ID]*state.State, len(signers))
+	for _, id := range signers {
+		r, _, err := NewRound(signers, secrets[id], public, message, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if states[id], err = state.NewBaseState(r, 0, state.WithSessionID(sessionID)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sign1 := make(map[party.ID]*messages.Sign1, len(signers))
+	sign2 := make(map[party.ID]*messages.Sign2, len(signers))
+	for round := 0; round < 3; round++ {
+		for _, id := range signers {
+			for _, msg := range states[id].ProcessAll() {
+				switch msg.Type {
+				case messages.MessageTypeSign1:
+					sign1[msg.From] = msg.Sign1
+				case messages.MessageTypeSign2:
+					sign2[msg.From] = msg.Sign2
+				}
+				for _, other := range signers {
+					if err := states[other].HandleMessage(msg); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+		}
+	}
+	for _, id := range signers {
+		if err := states[id].WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return sign1, sign2
+}
+
+func TestTranscript(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3, 4, 5}
+	_, secrets := helpers.GenerateSecrets(partyIDs, 2)
+	public := helpers.GeneratePublic(2, secrets)
+	signers := party.IDSlice{1, 3, 4}
+	message := []byte("message")
+	sessionID := messages.DeriveSessionID(signers, []byte("nonce"))
+
+	for name, opts := range map[string][]Option{
+		"plain":   nil,
+		"tweaked": {WithTweak(ristretto.NewScalar().Set(&secrets[5].Secret))},
+		"context": {WithContext([]byte("context"))},
+	} {
+		t.Run(name, func(t *testing.T) {
+			sign1, sign2 := recordSession(t, signers, secrets, public, message, sessionID, opts...)
+
+			transcript, err := NewTranscript(signers, public, message, sessionID, sign1, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range signers {
+				if !transcript.VerifyShare(id, &sign2[id].Zi) {
+					t.Errorf("the share of %d should be valid", id)
+				}
+			}
+			if culprits := transcript.Culprits(sign2); culprits != nil {
+				t.Errorf("Culprits() = %v, want none", culprits)
+			}
+			if transcript.VerifyShare(2, &sign2[1].Zi) {
+				t.Error("a party which is not a signer has no valid share")
+			}
+
+			// Flip a bit in the share of party 3
+			data := sign2[3].Zi.Bytes()
+			data[0] ^= 1
+			var flipped messages.Sign2
+			if _, err = flipped.Zi.SetCanonicalBytes(data); err != nil {
+				t.Fatal(err)
+			}
+			tampered := map[party.ID]*messages.Sign2{1: sign2[1], 3: &flipped, 4: sign2[4]}
+			if culprits := transcript.Culprits(tampered); !culprits.Equal(party.IDSlice{3}) {
+				t.Errorf("Culprits() = %v, want [3]", culprits)
+			}
+			delete(tampered, 4)
+			if culprits := transcript.Culprits(tampered); !culprits.Equal(party.IDSlice{3, 4}) {
+				t.Errorf("Culprits() = %v, want [3 4]", culprits)
+			}
+
+			// The transcript of another session rejects the shares
+			other, err := NewTranscript(signers, public, message, messages.SessionID{}, sign1, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if culprits := other.Culprits(sign2); !culprits.Equal(signers) {
+				t.Errorf("Culprits() with another session ID = %v, want all signers", culprits)
+			}
+		})
+	}
+}
+
+func TestNewTranscript_Invalid(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	_, secrets := helpers.GenerateSecrets(partyIDs, 1)
+	public := helpers.GeneratePublic(1, secrets)
+	sign1, _ := recordSession(t, party.IDSlice{1, 2}, secrets, public, []byte("message"), messages.SessionID{})
+
+	var commitmentErr *CommitmentError
+	_, err := NewTranscript(party.IDSlice{1, 2, 3}, public, []byte("message"), messages.SessionID{}, sign1)
+	if !errors.As(err, &commitmentErr) || commitmentErr.ID != 3 {
+		t.Errorf("NewTranscript() error = %v, want a *CommitmentError for 3", err)
+	}
+
+	identity := &messages.Sign1{Di: *ristretto.NewIdentityElement(), Ei: sign1[2].Ei}
+	_, err = NewTranscript(party.IDSlice{1, 2}, public, []byte("message"), messages.SessionID{}, map[party.ID]*messages.Sign1{1: sign1[1], 2: identity})
+	if !errors.As(err, &commitmentErr) || commitmentErr.ID != 2 || !errors.Is(err, ErrIdentityCommitment) {
+		t.Errorf("NewTranscript() error = %v, want ErrIdentityCommitment for 2", err)
+	}
+
+	_, err = NewTranscript(party.IDSlice{1, 7}, public, []byte("message"), messages.SessionID{}, sign1)
+	if !errors.Is(err, ErrUnknownSigners) {
+		t.Errorf("NewTranscript() error = %v, want ErrUnknownSigners", err)
+	}
+	if _, err = NewTranscript(nil, public, []byte("message"), messages.SessionID{}, sign1); err == nil {
+		t.Error("NewTranscript() without signers should fail")
+	}
+}
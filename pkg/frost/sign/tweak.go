@@ -0,0 +1,39 @@
+package sign
+
+import (
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// An Option modifies the sign protocol, and must be the same for all signers.
+type Option func(*round0)
+
+// WithTweak returns an Option which makes the signers produce a signature valid for the tweaked group key
+//
+//	Y' = Y + [t]•G
+//
+// instead of the group key Y of the key generation. The challenge is computed with Y',
+// and the signer with the smallest ID adds t to its additive share of the secret key,
+// so that its signature share is zᵢ = dᵢ + (eᵢ • ρᵢ) + (𝛌ᵢ • sᵢ + t) • c, and the others verify it against 𝛌ᵢ•Aᵢ + [t]•G.
+// The tweaked key is reported in Output.GroupKey. A zero tweak gives the same signature as without the option.
+func WithTweak(t *ristretto.Scalar) Option {
+	tweak := ristretto.NewScalar().Set(t)
+	return func(round *round0) {
+		// The tweak is copied since it is cleared by Reset, and the Option may be used for several rounds.
+		round.Tweak = ristretto.NewScalar().Set(tweak)
+	}
+}
+
+// applyTweak folds the tweak into the public share of the first signer, and into the group key.
+// The first signer adds t • c to its signature share when it computes it.
+func (round *round0) applyTweak() {
+	if round.Tweak == nil {
+		return
+	}
+	var tweakPublic ristretto.Element
+	tweakPublic.ScalarBaseMult(round.Tweak)
+
+	designated := round.PartyIDs()[0]
+	round.Parties[designated].Public.Add(&round.Parties[designated].Public, &tweakPublic)
+
+	round.GroupKey = *round.GroupKey.Tweak(round.Tweak)
+}
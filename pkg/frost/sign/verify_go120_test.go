@@ -0,0 +1,23 @@
+//go:build go1.20
+
+package sign
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+)
+
+// verifyWithOptions checks that sig is accepted by ed25519.VerifyWithOptions,
+// in the Ed25519ph mode if prehashed is set, and with the given context.
+func verifyWithOptions(t *testing.T, publicKey ed25519.PublicKey, message []byte, sig *eddsa.Signature, prehashed bool, context string) {
+	opts := &ed25519.Options{Context: context}
+	if prehashed {
+		opts.Hash = crypto.SHA512
+	}
+	if err := ed25519.VerifyWithOptions(publicKey, message, sig.ToEd25519(), opts); err != nil {
+		t.Errorf("ed25519.VerifyWithOptions: %v", err)
+	}
+}
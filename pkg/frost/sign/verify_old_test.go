@@ -0,0 +1,13 @@
+//go:build !go1.20
+
+package sign
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+)
+
+// verifyWithOptions is a no-op, since ed25519.VerifyWithOptions was added in Go 1.20.
+func verifyWithOptions(*testing.T, ed25519.PublicKey, []byte, *eddsa.Signature, bool, string) {}
@@ -0,0 +1,113 @@
+package frost
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/sign"
+)
+
+// ErrUnsupportedSignerOpts is returned by Signer.Sign when the options do not describe
+// Ed25519, Ed25519ctx, or Ed25519ph without a context.
+var ErrUnsupportedSignerOpts = errors.New("frost.Signer: unsupported signer options")
+
+// SignFunc runs a threshold signing session of message, in which every signer uses the options opts,
+// and returns the resulting signature.
+//
+// It usually creates a State with NewSignStateWithOptions, exchanges its messages with the other signers,
+// and waits for the result with State.WaitForErrorContext(ctx), so that the session is aborted when ctx is done.
+// If the session aborts, the error of the State should be returned, so that the caller can find the culprits.
+type SignFunc func(ctx context.Context, message []byte, opts []sign.Option) (*eddsa.Signature, error)
+
+// Signer implements crypto.Signer for a group key, so that it can be used by libraries which sign with
+// an ed25519.PrivateKey, such as crypto/x509. Every call to Sign runs a signing session with its SignFunc.
+//
+// The signing mode is chosen by the crypto.SignerOpts as for ed25519.PrivateKey.Sign:
+// crypto.Hash(0) gives an Ed25519 signature of the message, crypto.SHA512 an Ed25519ph signature of the digest,
+// and on Go 1.20 and later, *ed25519.Options with a Context gives an Ed25519ctx signature.
+type Signer struct {
+	ctx      context.Context
+	groupKey *eddsa.PublicKey
+	run      SignFunc
+}
+
+// NewSigner returns a Signer for groupKey, which uses run to produce the signatures.
+func NewSigner(groupKey *eddsa.PublicKey, run SignFunc) *Signer {
+	return &Signer{
+		ctx:      context.Background(),
+		groupKey: groupKey,
+		run:      run,
+	}
+}
+
+// WithContext returns a copy of s whose calls to Sign use ctx, since crypto.Signer does not take a context.
+// The signing sessions are then aborted when ctx is done.
+func (s *Signer) WithContext(ctx context.Context) *Signer {
+	s2 := *s
+	s2.ctx = ctx
+	return &s2
+}
+
+// Public returns the group key as an ed25519.PublicKey.
+func (s *Signer) Public() crypto.PublicKey {
+	return s.groupKey.ToEd25519()
+}
+
+// Sign implements crypto.Signer, and is SignContext with the context set by WithContext.
+// rand is ignored, since the nonces are generated by the signers.
+func (s *Signer) Sign(_ io.Reader, message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return s.SignContext(s.ctx, message, opts)
+}
+
+// SignContext runs a signing session of message in the mode given by opts, and returns the 64 byte signature.
+// The session is aborted when ctx is done, in which case the error wraps ctx.Err().
+// When the session aborts because of other signers, the error wraps the *state.Error listing them.
+func (s *Signer) SignContext(ctx context.Context, message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if opts == nil {
+		opts = crypto.Hash(0)
+	}
+	var signOpts []sign.Option
+	signerContext := signerOptsContext(opts)
+	switch opts.HashFunc() {
+	case crypto.Hash(0):
+		if signerContext != "" {
+			signOpts = append(signOpts, sign.WithContext([]byte(signerContext)))
+		}
+	case crypto.SHA512:
+		if signerContext != "" {
+			return nil, fmt.Errorf("%w: Ed25519ph with a context", ErrUnsupportedSignerOpts)
+		}
+		if len(message) != eddsa.PrehashSize {
+			return nil, fmt.Errorf("frost.Signer: the digest has %d bytes instead of %d", len(message), eddsa.PrehashSize)
+		}
+		signOpts = append(signOpts, sign.WithPrehash())
+	default:
+		return nil, fmt.Errorf("%w: hash %v", ErrUnsupportedSignerOpts, opts.HashFunc())
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("frost.Signer: %w", err)
+	}
+
+	sig, err := s.run(ctx, message, signOpts)
+	if err != nil {
+		return nil, fmt.Errorf("frost.Signer: %w", err)
+	}
+
+	var valid bool
+	switch {
+	case opts.HashFunc() == crypto.SHA512:
+		valid = s.groupKey.VerifyPrehashed(message, sig)
+	case signerContext != "":
+		valid = s.groupKey.VerifyWithContext(message, sig, []byte(signerContext))
+	default:
+		valid = s.groupKey.Verify(message, sig)
+	}
+	if !valid {
+		return nil, errors.New("frost.Signer: the signing session returned an invalid signature")
+	}
+	return sig.ToEd25519(), nil
+}
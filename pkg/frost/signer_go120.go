@@ -0,0 +1,16 @@
+//go:build go1.20
+
+package frost
+
+import (
+	"crypto"
+	"crypto/ed25519"
+)
+
+// signerOptsContext returns the context of opts if it is an *ed25519.Options, and "" otherwise.
+func signerOptsContext(opts crypto.SignerOpts) string {
+	if o, ok := opts.(*ed25519.Options); ok {
+		return o.Context
+	}
+	return ""
+}
@@ -0,0 +1,10 @@
+//go:build !go1.20
+
+package frost
+
+import "crypto"
+
+// signerOptsContext returns "", since ed25519.Options was added in Go 1.20.
+func signerOptsContext(crypto.SignerOpts) string {
+	return ""
+}
@@ -0,0 +1,259 @@
+// Package hashing contains every hash function used by the FROST protocols,
+// each with its own domain separation and typed inputs.
+//
+// External tools can use it to recompute the values exchanged or derived during a protocol execution,
+// for instance to audit a transcript without running the protocol.
+//
+// Challenge is the Ed25519 challenge of RFC 8032 and can never change, since it makes the signatures
+// compatible with crypto/ed25519. The other hashes are specific to this implementation,
+// and may change when Version is incremented.
+package hashing
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/binary"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// Version is the version of the hashes which are not constrained by Ed25519.
+// It follows messages.Version, since parties using different hashes cannot interoperate.
+const Version uint8 = 2
+
+var (
+	bindingDomainSeparation         = []byte("FROST-SHA512")
+	bindingPrehashDomainSeparation  = []byte("FROST-SHA512-ph")
+	schnorrDomainSeparation         = []byte("FROST-Ed25519 v2 PoK")
+	sessionDomainSeparation         = []byte("FROST-Ed25519 session")
+	groupSessionDomainSeparation    = []byte("FROST-Ed25519 group session")
+	derivationTweakDomainSeparation = []byte("FROST-Ed25519 derive")
+	backupDomainSeparation          = []byte("FROST-Ed25519 backup")
+	keygenContextDomainSeparation   = []byte("FROST-Ed25519 keygen context")
+	nonceIDDomainSeparation         = []byte("FROST-Ed25519 nonce")
+	nonceMessageDomainSeparation    = []byte("FROST-Ed25519 nonce message")
+	nonceSignersDomainSeparation    = []byte("FROST-Ed25519 nonce signers")
+)
+
+// Challenge returns the Ed25519 challenge c = SHA-512(prefix ∥ R ∥ A ∥ M) mod ℓ, where
+//
+//	prefix:   the dom2 prefix of the Ed25519ph and Ed25519ctx variants, and empty for Ed25519
+//	R:        the commitment of the signature
+//	A:        the Ed25519 encoding of the group key
+//	M:        the message, or its SHA-512 digest for Ed25519ph
+func Challenge(prefix []byte, R *ristretto.Element, groupKey ed25519.PublicKey, message []byte) *ristretto.Scalar {
+	return ChallengeBytes(prefix, R.BytesEd25519(), groupKey, message)
+}
+
+// ChallengeBytes is Challenge with R given by its encoding in the signature,
+// which may not be canonical when verifying signatures produced by other implementations.
+func ChallengeBytes(prefix, R []byte, groupKey ed25519.PublicKey, message []byte) *ristretto.Scalar {
+	data := make([]byte, 0, len(prefix)+len(R)+len(groupKey)+len(message))
+	data = append(data, prefix...)
+	data = append(data, R...)
+	data = append(data, groupKey...)
+	data = append(data, message...)
+	return scalarFromDigest(sha512.Sum512(data))
+}
+
+// Commitment is the pair of nonce commitments (D, E) sent by a signer in the first round of the sign protocol.
+type Commitment struct {
+	ID   party.ID
+	D, E *ristretto.Element
+}
+
+// BindingFactors returns the binding factor ρᵢ of every signer i in commitments, in the same order:
+//
+//	ρᵢ = SHA-512 ("FROST-SHA512" ∥ i ∥ SessionID ∥ SHA-512(M) ∥ B ) mod ℓ
+//
+// The list B is the concatenation of ( j ∥ Dⱼ ∥ Eⱼ ) for all signers j in the order of commitments,
+// which must be sorted by ID. When prehashed is true, the prefix is "FROST-SHA512-ph" instead,
+// so that the nonces of an Ed25519ph signature are never reused for an Ed25519 signature of the digest.
+func BindingFactors(prehashed bool, sessionID [32]byte, message []byte, commitments []Commitment) []ristretto.Scalar {
+	/*
+		While profiling, we noticed that using hash.Hash forces all values to be allocated on the heap.
+		To prevent this, we can simply create a big buffer on the stack and call sha512.Sum().
+
+		We need to compute a very simple hash N times, and Go's caching isn't great for hashing.
+		Therefore, we can simply change the buffer and rehash it many times.
+	*/
+	domainSeparation := bindingDomainSeparation
+	if prehashed {
+		domainSeparation = bindingPrehashDomainSeparation
+	}
+	messageHash := sha512.Sum512(message)
+
+	sizeB := len(commitments) * (party.IDByteSize + 32 + 32)
+	offsetID := len(domainSeparation)
+
+	// We compute the big buffer "FROST-SHA512" ∥ i ∥ SessionID ∥ SHA-512(Message) ∥ B
+	// and remember the offset of i. Later we will write the ID of each party at this place.
+	buffer := make([]byte, 0, offsetID+party.IDByteSize+len(sessionID)+len(messageHash)+sizeB)
+	buffer = append(buffer, domainSeparation...)
+	buffer = append(buffer, 0, 0, 0, 0)
+	buffer = append(buffer, sessionID[:]...)
+	buffer = append(buffer, messageHash[:]...)
+	for _, c := range commitments {
+		buffer = append(buffer, c.ID.Bytes()...)
+		buffer = append(buffer, c.D.Bytes()...)
+		buffer = append(buffer, c.E.Bytes()...)
+	}
+
+	rhos := make([]ristretto.Scalar, len(commitments))
+	for i, c := range commitments {
+		copy(buffer[offsetID:], c.ID.Bytes())
+		rhos[i].Set(scalarFromDigest(sha512.Sum512(buffer)))
+	}
+	return rhos
+}
+
+// SchnorrChallenge returns the challenge of the proof of knowledge of the discrete logarithm of public,
+// sent by each party in the key generation:
+//
+//	S = SHA-512 ("FROST-Ed25519 v2 PoK" ∥ ID ∥ SessionID ∥ public ∥ M ) mod ℓ
+//
+// where M = [k]•B is the commitment of the prover with ID.
+// The key generation uses KeygenContext as SessionID, and the resharing the session ID of the protocol execution.
+func SchnorrChallenge(id party.ID, sessionID [32]byte, public, M *ristretto.Element) *ristretto.Scalar {
+	data := make([]byte, 0, len(schnorrDomainSeparation)+party.IDByteSize+len(sessionID)+64)
+	data = append(data, schnorrDomainSeparation...)
+	data = append(data, id.Bytes()...)
+	data = append(data, sessionID[:]...)
+	data = append(data, public.Bytes()...)
+	data = append(data, M.Bytes()...)
+	return scalarFromDigest(sha512.Sum512(data))
+}
+
+// KeygenContext returns the context given as session ID to SchnorrChallenge in the key generation,
+// which binds the proofs of knowledge to the session, the group parameters, and the context of the ceremony:
+//
+//	KeygenContext = SHA-512/256("FROST-Ed25519 keygen context" ∥ SessionID ∥ t ∥ n ∥ ID₁ ∥ ... ∥ IDₙ ∥ context)
+//
+// where t is the threshold, and the n party IDs must be sorted.
+func KeygenContext(sessionID [32]byte, partyIDs party.IDSlice, threshold party.Size, context []byte) [32]byte {
+	buffer := make([]byte, 0, len(keygenContextDomainSeparation)+len(sessionID)+(len(partyIDs)+2)*party.IDByteSize+len(context))
+	buffer = append(buffer, keygenContextDomainSeparation...)
+	buffer = append(buffer, sessionID[:]...)
+	buffer = append(buffer, threshold.Bytes()...)
+	buffer = append(buffer, partyIDs.N().Bytes()...)
+	for _, id := range partyIDs {
+		buffer = append(buffer, id.Bytes()...)
+	}
+	buffer = append(buffer, context...)
+	return sha512.Sum512_256(buffer)
+}
+
+// SessionID returns a deterministic session ID for a protocol execution between partyIDs,
+// which must be sorted, and where the nonce is chosen by the application:
+//
+//	SessionID = SHA-512/256("FROST-Ed25519 session" ∥ n ∥ ID₁ ∥ ... ∥ IDₙ ∥ nonce)
+func SessionID(partyIDs party.IDSlice, nonce []byte) [32]byte {
+	buffer := make([]byte, 0, len(sessionDomainSeparation)+(len(partyIDs)+1)*party.IDByteSize+len(nonce))
+	buffer = append(buffer, sessionDomainSeparation...)
+	buffer = append(buffer, partyIDs.N().Bytes()...)
+	for _, id := range partyIDs {
+		buffer = append(buffer, id.Bytes()...)
+	}
+	buffer = append(buffer, nonce...)
+	return sha512.Sum512_256(buffer)
+}
+
+// GroupSessionID is SessionID for a protocol execution with the group key A, which is bound to the session ID:
+//
+//	SessionID = SHA-512/256("FROST-Ed25519 group session" ∥ A ∥ n ∥ ID₁ ∥ ... ∥ IDₙ ∥ nonce)
+//
+// where A is the Ed25519 encoding of the group key.
+func GroupSessionID(groupKey ed25519.PublicKey, partyIDs party.IDSlice, nonce []byte) [32]byte {
+	buffer := make([]byte, 0, len(groupSessionDomainSeparation)+len(groupKey)+(len(partyIDs)+1)*party.IDByteSize+len(nonce))
+	buffer = append(buffer, groupSessionDomainSeparation...)
+	buffer = append(buffer, groupKey...)
+	buffer = append(buffer, partyIDs.N().Bytes()...)
+	for _, id := range partyIDs {
+		buffer = append(buffer, id.Bytes()...)
+	}
+	buffer = append(buffer, nonce...)
+	return sha512.Sum512_256(buffer)
+}
+
+// DerivationTweak returns the tweak δ added to the group key A when deriving the child key at index:
+//
+//	δ = SHA-512("FROST-Ed25519 derive" ∥ A ∥ index) mod ℓ
+//
+// where A is the Ed25519 encoding of the group key, and index is encoded as 4 bytes in big endian.
+func DerivationTweak(groupKey ed25519.PublicKey, index uint32) *ristretto.Scalar {
+	data := make([]byte, 0, len(derivationTweakDomainSeparation)+32+4)
+	data = append(data, derivationTweakDomainSeparation...)
+	data = append(data, groupKey...)
+	data = append(data, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[len(data)-4:], index)
+	return scalarFromDigest(sha512.Sum512(data))
+}
+
+// BackupChallenge returns the challenge of the proof number index of a verifiable backup of the share of party id,
+// encrypted to the recovery key Y:
+//
+//	c = SHA-512("FROST-Ed25519 backup" ∥ A ∥ ID ∥ Y ∥ index ∥ P₁ ∥ ... ∥ Pₖ) mod ℓ
+//
+// where A is the Ed25519 encoding of the group key, index is encoded as 4 bytes in big endian,
+// and P₁, ..., Pₖ are the statement and commitments of the proof.
+func BackupChallenge(groupKey ed25519.PublicKey, id party.ID, recoveryKey *ristretto.Element, index uint32, points ...*ristretto.Element) *ristretto.Scalar {
+	data := make([]byte, 0, len(backupDomainSeparation)+len(groupKey)+party.IDByteSize+32+4+32*len(points))
+	data = append(data, backupDomainSeparation...)
+	data = append(data, groupKey...)
+	data = append(data, id.Bytes()...)
+	data = append(data, recoveryKey.Bytes()...)
+	data = append(data, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[len(data)-4:], index)
+	for _, p := range points {
+		data = append(data, p.Bytes()...)
+	}
+	return scalarFromDigest(sha512.Sum512(data))
+}
+
+// NonceID returns the identifier of a pair of nonces (d, e) of a signer, computed from their commitments D and E:
+//
+//	NonceID = SHA-512/256("FROST-Ed25519 nonce" ∥ D ∥ E)
+func NonceID(D, E *ristretto.Element) [32]byte {
+	data := make([]byte, 0, len(nonceIDDomainSeparation)+64)
+	data = append(data, nonceIDDomainSeparation...)
+	data = append(data, D.Bytes()...)
+	data = append(data, E.Bytes()...)
+	return sha512.Sum512_256(data)
+}
+
+// NonceBinding returns the digests of the message and of the signers of a signing session, to which the nonces
+// of each signer are bound:
+//
+//	message = SHA-512/256("FROST-Ed25519 nonce message" ∥ A ∥ len(variant) ∥ variant ∥ M)
+//	signers = SHA-512/256("FROST-Ed25519 nonce signers" ∥ n ∥ ID₁ ∥ ... ∥ IDₙ)
+//
+// where A is the Ed25519 encoding of the group key, variant identifies the Ed25519 variant and its context,
+// len(variant) is encoded as 4 bytes in big endian, and the n signer IDs must be sorted.
+func NonceBinding(groupKey ed25519.PublicKey, variant, message []byte, signers party.IDSlice) (messageDigest, signersDigest [32]byte) {
+	data := make([]byte, 0, len(nonceMessageDomainSeparation)+len(groupKey)+4+len(variant)+len(message))
+	data = append(data, nonceMessageDomainSeparation...)
+	data = append(data, groupKey...)
+	data = append(data, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[len(data)-4:], uint32(len(variant)))
+	data = append(data, variant...)
+	data = append(data, message...)
+	messageDigest = sha512.Sum512_256(data)
+
+	data = make([]byte, 0, len(nonceSignersDomainSeparation)+(len(signers)+1)*party.IDByteSize)
+	data = append(data, nonceSignersDomainSeparation...)
+	data = append(data, signers.N().Bytes()...)
+	for _, id := range signers {
+		data = append(data, id.Bytes()...)
+	}
+	signersDigest = sha512.Sum512_256(data)
+	return messageDigest, signersDigest
+}
+
+// scalarFromDigest reduces a SHA-512 digest modulo ℓ.
+func scalarFromDigest(digest [sha512.Size]byte) *ristretto.Scalar {
+	var s ristretto.Scalar
+	// SetUniformBytes only returns an error when the length is not 64
+	_, _ = s.SetUniformBytes(digest[:])
+	return &s
+}
@@ -0,0 +1,146 @@
+package hashing_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/hashing"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+func TestChallenge_Ed25519(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	sk := ed25519.NewKeyFromSeed(seed)
+	pub := sk.Public().(ed25519.PublicKey)
+
+	for _, message := range [][]byte{nil, []byte("message"), make([]byte, 1000)} {
+		sig := ed25519.Sign(sk, message)
+
+		var R, A, SB, RcA ristretto.Element
+		var S ristretto.Scalar
+		_, err := R.SetEd25519Bytes(sig[:32])
+		require.NoError(t, err)
+		_, err = A.SetEd25519Bytes(pub)
+		require.NoError(t, err)
+		_, err = S.SetCanonicalBytes(sig[32:])
+		require.NoError(t, err)
+
+		// [S]•B = R + [c]•A
+		c := hashing.Challenge(nil, &R, pub, message)
+		SB.ScalarBaseMult(&S)
+		RcA.ScalarMult(c, &A)
+		RcA.Add(&RcA, &R)
+		require.Equal(t, 1, SB.Equal(&RcA), "the challenge differs from crypto/ed25519")
+	}
+}
+
+// TestBindingFactors checks that the binding factors are the same as the ones computed by previous versions.
+func TestBindingFactors(t *testing.T) {
+	ids := party.IDSlice{1, 2, 3}
+	commitments := make([]hashing.Commitment, 0, len(ids))
+	for _, id := range ids {
+		var d, e ristretto.Scalar
+		_, _ = d.SetUniformBytes(append(make([]byte, 63), byte(id)))
+		_, _ = e.SetUniformBytes(append(make([]byte, 63), byte(id+10)))
+		commitments = append(commitments, hashing.Commitment{
+			ID: id,
+			D:  new(ristretto.Element).ScalarBaseMult(&d),
+			E:  new(ristretto.Element).ScalarBaseMult(&e),
+		})
+	}
+	sessionID := hashing.SessionID(ids, []byte("nonce"))
+
+	expected := map[bool][]string{
+		false: {
+			"8197e162dbf8473556a43ab0356859afb07f443d8140aa6c64f55dcdaf30f803",
+			"727fa6a1638fe80315af4ef04ae89dd16b01441ac0210bfd9d100af65c902800",
+			"933a81c4d5517a3aff20b15a6cd9753d070939bbcdeab16a1277213acc32f60f",
+		},
+		true: {
+			"5f86b35bde796d0b008668485343e0879ecd6753c122ac430fc33f7ceac8fb04",
+			"e4f4ff0ed7c7308b9f1cf0cb49c7782a23abddb9ff6ad3f402814b0721d50b0e",
+			"e8a66967cb86c92f9d7a158539d024303498ce3414f6afc422d6aa3f9c539904",
+		},
+	}
+	for prehashed, hexRhos := range expected {
+		rhos := hashing.BindingFactors(prehashed, sessionID, []byte("message"), commitments)
+		require.Len(t, rhos, len(ids))
+		for i := range rhos {
+			require.Equal(t, hexRhos[i], hex.EncodeToString(rhos[i].Bytes()), "prehashed: %v, ID: %d", prehashed, ids[i])
+		}
+	}
+}
+
+func TestSessionID(t *testing.T) {
+	sessionID := hashing.SessionID(party.IDSlice{1, 2, 3}, []byte("nonce"))
+	require.Equal(t, "beb43e2f387cf6fd9bbec93298761cc7f59a9dd10ed50962c16e4a427d005165", hex.EncodeToString(sessionID[:]))
+	require.Equal(t, messages.SessionID(sessionID), messages.DeriveSessionID(party.IDSlice{1, 2, 3}, []byte("nonce")))
+}
+
+func TestSchnorrChallenge(t *testing.T) {
+	var sessionID, otherSessionID [32]byte
+	otherSessionID[0] = 1
+	public := ristretto.NewGeneratorElement()
+	M := new(ristretto.Element).Add(public, public)
+
+	c := hashing.SchnorrChallenge(1, sessionID, public, M)
+	require.Equal(t, 1, c.Equal(hashing.SchnorrChallenge(1, sessionID, public, M)))
+	require.Equal(t, 0, c.Equal(hashing.SchnorrChallenge(2, sessionID, public, M)))
+	require.Equal(t, 0, c.Equal(hashing.SchnorrChallenge(1, otherSessionID, public, M)))
+	require.Equal(t, 0, c.Equal(hashing.SchnorrChallenge(1, sessionID, M, public)))
+	require.Equal(t, 0, c.Equal(ristretto.NewScalar()))
+}
+
+func TestVersion(t *testing.T) {
+	require.Equal(t, messages.Version, hashing.Version)
+}
+
+func TestGroupSessionID(t *testing.T) {
+	groupKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)).Public().(ed25519.PublicKey)
+	sessionID := hashing.GroupSessionID(groupKey, party.IDSlice{1, 2, 3}, []byte("nonce"))
+	require.Equal(t, "9d6d835fe12fbe442738282544f35e34f56f66c2a18cba8b74bdb93b32543a28", hex.EncodeToString(sessionID[:]))
+	require.Equal(t, messages.SessionID(sessionID), messages.DeriveGroupSessionID(groupKey, party.IDSlice{1, 2, 3}, []byte("nonce")))
+	require.NotEqual(t, sessionID, hashing.SessionID(party.IDSlice{1, 2, 3}, []byte("nonce")))
+
+	otherKey := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize)).Public().(ed25519.PublicKey)
+	require.NotEqual(t, sessionID, hashing.GroupSessionID(otherKey, party.IDSlice{1, 2, 3}, []byte("nonce")))
+}
+
+func TestKeygenContext(t *testing.T) {
+	var sessionID, otherSessionID [32]byte
+	otherSessionID[0] = 1
+	partyIDs := party.IDSlice{1, 2, 3}
+
+	c := hashing.KeygenContext(sessionID, partyIDs, 1, []byte("ceremony"))
+	require.Equal(t, c, hashing.KeygenContext(sessionID, partyIDs, 1, []byte("ceremony")))
+	require.NotEqual(t, c, hashing.KeygenContext(otherSessionID, partyIDs, 1, []byte("ceremony")))
+	require.NotEqual(t, c, hashing.KeygenContext(sessionID, party.IDSlice{1, 2, 4}, 1, []byte("ceremony")))
+	require.NotEqual(t, c, hashing.KeygenContext(sessionID, partyIDs, 2, []byte("ceremony")))
+	require.NotEqual(t, c, hashing.KeygenContext(sessionID, partyIDs, 1, []byte("other")))
+	require.NotEqual(t, c, hashing.KeygenContext(sessionID, partyIDs, 1, nil))
+}
+
+func TestNonceBinding(t *testing.T) {
+	groupKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)).Public().(ed25519.PublicKey)
+	signers := party.IDSlice{1, 2, 3}
+
+	message, signersDigest := hashing.NonceBinding(groupKey, nil, []byte("message"), signers)
+	otherMessage, otherSigners := hashing.NonceBinding(groupKey, []byte{1}, []byte("message"), party.IDSlice{1, 2})
+	require.NotEqual(t, message, otherMessage)
+	require.NotEqual(t, signersDigest, otherSigners)
+	otherMessage, _ = hashing.NonceBinding(groupKey, nil, []byte("other"), signers)
+	require.NotEqual(t, message, otherMessage)
+
+	D := ristretto.NewGeneratorElement()
+	E := new(ristretto.Element).Add(D, D)
+	require.Equal(t, hashing.NonceID(D, E), hashing.NonceID(D, E))
+	require.NotEqual(t, hashing.NonceID(D, E), hashing.NonceID(E, D))
+}
@@ -1,6 +1,7 @@
 package helpers
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
@@ -9,33 +10,40 @@ import (
 )
 
 // NewPartySlice returns n party.ID s in the range [1, ..., n].
+// It panics if n is 0.
 func NewPartySlice(n party.Size) party.IDSlice {
-	partyIDs := make([]party.ID, 0, n)
-	for i := party.ID(1); i <= n; i++ {
-		partyIDs = append(partyIDs, i)
+	partyIDs, err := party.NewIDSliceRange(1, n)
+	if err != nil {
+		panic(err)
 	}
 	return partyIDs
 }
 
+// GenerateSet returns the sorted party.IDSlice [1, ..., n].
+// It panics if n is 0.
 func GenerateSet(n party.Size) party.IDSlice {
-	return party.NewIDSlice(NewPartySlice(n))
+	return NewPartySlice(n)
 }
 
+// PartyRoutine handles the encoded messages in, processes the round, and returns the encoded messages it generated.
+// The messages are encoded with the codec of s.
 func PartyRoutine(in [][]byte, s *state.State) ([][]byte, error) {
+	codec := s.Codec()
 	for _, m := range in {
 		var msgTmp messages.Message
 
-		if err := msgTmp.UnmarshalBinary(m); err != nil {
+		if err := codec.Unmarshal(m, &msgTmp); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal message: %w", err)
 		}
-		if err := s.HandleMessage(&msgTmp); err != nil {
+		// Messages are given to all parties, so we skip the ones addressed to another party
+		if err := s.HandleMessage(&msgTmp); err != nil && !errors.Is(err, state.ErrWrongRecipient) {
 			return nil, fmt.Errorf("failed to handle message: %w", err)
 		}
 	}
 	msgsOut := s.ProcessAll()
 	out := make([][]byte, 0, len(msgsOut))
 	for _, msgOut := range msgsOut {
-		if b, err := msgOut.MarshalBinary(); err == nil {
+		if b, err := codec.Marshal(msgOut); err == nil {
 			out = append(out, b)
 		} else {
 			return nil, err
@@ -49,3 +57,43 @@ func PartyRoutine(in [][]byte, s *state.State) ([][]byte, error) {
 	}
 	return out, nil
 }
+
+// RunProtocol executes the protocol between states until no more messages are sent.
+// Each message is encoded, and decoded for every recipient, and tamper is called on the decoded message
+// before it is delivered, if not nil. The errors of the parties are returned by their WaitForError.
+func RunProtocol(states map[party.ID]*state.State, tamper func(*messages.Message)) error {
+	partyIDs := make(party.IDSlice, 0, len(states))
+	for id := range states {
+		partyIDs = append(partyIDs, id)
+	}
+	partyIDs = party.NewIDSlice(partyIDs)
+
+	for {
+		var out []*messages.Message
+		for _, id := range partyIDs {
+			out = append(out, states[id].ProcessAll()...)
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		for _, msg := range out {
+			data, err := msg.MarshalBinary()
+			if err != nil {
+				return fmt.Errorf("failed to marshal message: %w", err)
+			}
+			for _, id := range partyIDs {
+				if msg.From == id || msg.To != 0 && msg.To != id {
+					continue
+				}
+				var msgCopy messages.Message
+				if err = msgCopy.UnmarshalBinary(data); err != nil {
+					return fmt.Errorf("failed to unmarshal message: %w", err)
+				}
+				if tamper != nil {
+					tamper(&msgCopy)
+				}
+				_ = states[id].HandleMessage(&msgCopy)
+			}
+		}
+	}
+}
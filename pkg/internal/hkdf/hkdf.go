@@ -0,0 +1,24 @@
+// Package hkdf implements the HMAC-based key derivation function of RFC 5869 with SHA-256,
+// for the single output length used in this module.
+package hkdf
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+)
+
+// KeySize is the length of the keys returned by Key.
+const KeySize = sha256.Size
+
+// Key returns the first KeySize bytes of HKDF-SHA256 with input keying material secret, salt and info.
+// They are the output of the first block of HKDF-Expand.
+func Key(secret, salt, info []byte) []byte {
+	extract := hmac.New(sha256.New, salt)
+	_, _ = extract.Write(secret)
+	prk := extract.Sum(nil)
+
+	expand := hmac.New(sha256.New, prk)
+	_, _ = expand.Write(info)
+	_, _ = expand.Write([]byte{1})
+	return expand.Sum(nil)
+}
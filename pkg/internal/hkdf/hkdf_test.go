@@ -0,0 +1,38 @@
+package hkdf
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestKey uses the test cases of RFC 5869, Appendix A, whose output keying material starts with our key.
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name, secret, salt, info, okm string
+	}{
+		{
+			"A.1",
+			"0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b",
+			"000102030405060708090a0b0c",
+			"f0f1f2f3f4f5f6f7f8f9",
+			"3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865",
+		},
+		{
+			"A.3",
+			"0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b",
+			"",
+			"",
+			"8da4e775a563c18f715f802a063c5a31b8a11f5c5ee1879ec3454e5f3c738d2d9d201395faa4b61a96c8",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, _ := hex.DecodeString(tt.secret)
+			salt, _ := hex.DecodeString(tt.salt)
+			info, _ := hex.DecodeString(tt.info)
+			assert.Equal(t, tt.okm[:2*KeySize], hex.EncodeToString(Key(secret, salt, info)))
+		})
+	}
+}
@@ -105,8 +105,9 @@ func (p *Exponent) evaluateHorner(index *ristretto.Scalar, result *ristretto.Ele
 func (p *Exponent) EvaluateMulti(indices []party.ID) map[party.ID]*ristretto.Element {
 	evaluations := make(map[party.ID]*ristretto.Element, len(indices))
 
+	var x ristretto.Scalar
 	for _, id := range indices {
-		evaluations[id] = p.Evaluate(id.Scalar())
+		evaluations[id] = p.Evaluate(id.ScalarTo(&x))
 	}
 	return evaluations
 }
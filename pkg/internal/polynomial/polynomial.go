@@ -1,10 +1,12 @@
 package polynomial
 
 import (
-	"crypto/rand"
+	"errors"
 	"fmt"
+	"io"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
 	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 )
 
@@ -15,39 +17,53 @@ type Polynomial struct {
 // NewPolynomial generates a Polynomial f(X) = secret + a1*X + ... + at*X^t,
 // with coefficients in Z_q, and degree t.
 func NewPolynomial(degree party.Size, constant *ristretto.Scalar) *Polynomial {
+	polynomial, err := NewPolynomialFrom(degree, constant, nil)
+	if err != nil {
+		panic(fmt.Errorf("edwards25519: failed to generate random Scalar: %w", err))
+	}
+	return polynomial
+}
+
+// NewPolynomialFrom is like NewPolynomial, but samples the coefficients a1, ..., at with randomness read from r,
+// or from crypto/rand if r is nil. It fails if r does not return enough randomness, see scalar.SetScalarRandomFrom.
+func NewPolynomialFrom(degree party.Size, constant *ristretto.Scalar, r io.Reader) (*Polynomial, error) {
 	var polynomial Polynomial
 	polynomial.coefficients = make([]ristretto.Scalar, degree+1)
 
 	// SetWithoutSelf the constant term to the secret
 	polynomial.coefficients[0].Set(constant)
 
-	var err error
-	randomBytes := make([]byte, 64)
 	for i := party.Size(1); i <= degree; i++ {
-		_, err = rand.Read(randomBytes)
-		if err != nil {
-			panic(fmt.Errorf("edwards25519: failed to generate random Scalar: %w", err))
+		if _, err := scalar.SetScalarRandomFrom(&polynomial.coefficients[i], r); err != nil {
+			polynomial.Reset()
+			return nil, err
 		}
-		_, _ = polynomial.coefficients[i].SetUniformBytes(randomBytes)
 	}
 
-	return &polynomial
+	return &polynomial, nil
 }
 
 // Evaluate evaluates a polynomial in a given variable index
 // We use Horner's method: https://en.wikipedia.org/wiki/Horner%27s_method
 func (p *Polynomial) Evaluate(index *ristretto.Scalar) *ristretto.Scalar {
+	var result ristretto.Scalar
+	return p.EvaluateTo(&result, index)
+}
+
+// EvaluateTo sets dst to the evaluation of the polynomial in index, and returns dst.
+// Unlike Evaluate, it does not allocate.
+func (p *Polynomial) EvaluateTo(dst, index *ristretto.Scalar) *ristretto.Scalar {
 	if index.Equal(ristretto.NewScalar()) == 1 {
 		panic("attempt to leak secret")
 	}
 
-	var result ristretto.Scalar
+	dst.Set(ristretto.NewScalar())
 	// reverse order
 	for i := len(p.coefficients) - 1; i >= 0; i-- {
 		// b_n-1 = b_n * x + a_n-1
-		result.MultiplyAdd(&result, index, &p.coefficients[i])
+		dst.MultiplyAdd(dst, index, &p.coefficients[i])
 	}
-	return &result
+	return dst
 }
 
 func (p *Polynomial) Constant() *ristretto.Scalar {
@@ -74,3 +90,45 @@ func (p *Polynomial) Reset() {
 		p.coefficients[i].Set(zero)
 	}
 }
+
+//
+// Marshalling
+//
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The output contains the secret coefficients of the polynomial, and should be handled with care.
+func (p *Polynomial) MarshalBinary() (data []byte, err error) {
+	buf := make([]byte, 0, party.IDByteSize+32*len(p.coefficients))
+	return p.BytesAppend(buf)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (p *Polynomial) UnmarshalBinary(data []byte) error {
+	degree, err := party.FromBytes(data)
+	if err != nil {
+		return err
+	}
+	coefficientCount := int(degree) + 1
+	remaining := data[party.IDByteSize:]
+
+	if len(remaining) != coefficientCount*32 {
+		return errors.New("wrong number of coefficients embedded")
+	}
+
+	p.coefficients = make([]ristretto.Scalar, coefficientCount)
+	for i := range p.coefficients {
+		if _, err = p.coefficients[i].SetCanonicalBytes(remaining[:32]); err != nil {
+			return err
+		}
+		remaining = remaining[32:]
+	}
+	return nil
+}
+
+func (p *Polynomial) BytesAppend(existing []byte) (data []byte, err error) {
+	existing = append(existing, p.Degree().Bytes()...)
+	for i := range p.coefficients {
+		existing = append(existing, p.coefficients[i].Bytes()...)
+	}
+	return existing, nil
+}
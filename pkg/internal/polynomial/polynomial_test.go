@@ -27,3 +27,24 @@ func TestPolynomial_Evaluate(t *testing.T) {
 		}
 	}
 }
+
+func TestPolynomial_MarshalBinary(t *testing.T) {
+	secret := scalar.NewScalarRandom()
+	poly := NewPolynomial(10, secret)
+
+	data, err := poly.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var poly2 Polynomial
+	if err = poly2.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	index := scalar.NewScalarUInt32(42)
+	if poly.Evaluate(index).Equal(poly2.Evaluate(index)) != 1 {
+		t.Error("decoded polynomial is different")
+	}
+	if err = poly2.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Error("decoding truncated data should fail")
+	}
+}
@@ -2,24 +2,77 @@ package scalar
 
 import (
 	"crypto/rand"
+	"errors"
 	"fmt"
+	"io"
 
+	// The compiler only inlines ristretto.Scalar.Bytes in AppendBytes if edwards25519 is imported directly.
+	_ "filippo.io/edwards25519"
 	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 )
 
+// ErrZeroScalar is returned by SetScalarRandomFrom when the sampled scalar is zero,
+// which only happens with negligible probability unless the randomness source is broken.
+var ErrZeroScalar = errors.New("random scalar is zero")
+
 // SetScalarRandom sets s to a random ristretto.Scalar using the default randomness source from crypto/rand
 func SetScalarRandom(s *ristretto.Scalar) *ristretto.Scalar {
-	bytes := make([]byte, 64)
-
-	_, err := rand.Reader.Read(bytes)
-	if err != nil {
+	if _, err := SetScalarRandomFrom(s, nil); err != nil {
 		panic(fmt.Errorf("edwards25519: failed to generate random Scalar: %w", err))
 	}
-
-	_, _ = s.SetUniformBytes(bytes)
 	return s
 }
 
+// SetScalarRandomFrom sets s to a uniformly random ristretto.Scalar derived from 64 bytes read from r,
+// or from crypto/rand if r is nil.
+// It fails without modifying s if r does not return 64 bytes, or if the result is zero.
+func SetScalarRandomFrom(s *ristretto.Scalar, r io.Reader) (*ristretto.Scalar, error) {
+	if r == nil {
+		r = rand.Reader
+	}
+	var bytes [64]byte
+	defer func() {
+		for i := range bytes {
+			bytes[i] = 0
+		}
+	}()
+	if err := readFull(r, bytes[:]); err != nil {
+		return nil, fmt.Errorf("failed to read randomness: %w", err)
+	}
+
+	var tmp ristretto.Scalar
+	_, _ = tmp.SetUniformBytes(bytes[:])
+	if tmp.Equal(ristretto.NewScalar()) == 1 {
+		return nil, ErrZeroScalar
+	}
+	s.Set(&tmp)
+	tmp.Set(ristretto.NewScalar())
+	return s, nil
+}
+
+// readFull is like io.ReadFull, but returns io.ErrNoProgress instead of blocking
+// if r keeps returning no data without an error.
+func readFull(r io.Reader, buf []byte) error {
+	const maxEmptyReads = 100
+	for read, empty := 0, 0; read < len(buf); {
+		n, err := r.Read(buf[read:])
+		read += n
+		switch {
+		case read == len(buf):
+			return nil
+		case err == io.EOF:
+			return io.ErrUnexpectedEOF
+		case err != nil:
+			return err
+		case n == 0:
+			if empty++; empty == maxEmptyReads {
+				return io.ErrNoProgress
+			}
+		}
+	}
+	return nil
+}
+
 // NewScalarRandom generates a new ristretto.Scalar using the default randomness source from crypto/rand
 func NewScalarRandom() *ristretto.Scalar {
 	var s ristretto.Scalar
@@ -36,10 +89,8 @@ func SetScalarUInt32(s *ristretto.Scalar, x uint32) *ristretto.Scalar {
 	bytes[2] = byte(x >> 16)
 	bytes[3] = byte(x >> 24)
 
-	_, err := s.SetCanonicalBytes(bytes)
-	if err != nil {
-		panic(fmt.Errorf("edwards25519: failed to set uint32 Scalar: %w", err))
-	}
+	// An integer smaller than 2³² is always a canonical encoding, so this cannot fail.
+	_, _ = s.SetCanonicalBytes(bytes)
 	return s
 }
 
@@ -50,3 +101,9 @@ func NewScalarUInt32(x uint32) *ristretto.Scalar {
 
 	return SetScalarUInt32(&s, x)
 }
+
+// AppendBytes appends the 32 bytes canonical encoding of s to dst.
+// Unlike s.Bytes, it does not allocate if dst has a capacity of at least 32 additional bytes.
+func AppendBytes(dst []byte, s *ristretto.Scalar) []byte {
+	return append(dst, s.Bytes()...)
+}
@@ -1,6 +1,9 @@
 package scalar
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -26,3 +29,65 @@ func TestNewScalarUInt32(t *testing.T) {
 		assert.Equal(t, 1, computed.Equal(newScalar))
 	}
 }
+
+func TestAppendBytes(t *testing.T) {
+	s := NewScalarRandom()
+	prefix := []byte{1, 2, 3}
+	assert.Equal(t, append(prefix, s.Bytes()...), AppendBytes(prefix[:3:3], s))
+
+	buf := make([]byte, 0, 32)
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = AppendBytes(buf, s)
+	})
+	assert.Zero(t, allocs)
+}
+
+// stallingReader returns no data and no error.
+type stallingReader struct{}
+
+func (stallingReader) Read([]byte) (int, error) { return 0, nil }
+
+// oneByteReader returns a single byte at each call.
+type oneByteReader struct{ r io.Reader }
+
+func (o oneByteReader) Read(p []byte) (int, error) { return o.r.Read(p[:1]) }
+
+func TestSetScalarRandomFrom(t *testing.T) {
+	random := make([]byte, 64)
+	random[0] = 42
+	expected, err := ristretto.NewScalar().SetUniformBytes(random)
+	require.NoError(t, err)
+
+	for _, r := range []io.Reader{bytes.NewReader(random), oneByteReader{bytes.NewReader(random)}} {
+		var s ristretto.Scalar
+		_, err = SetScalarRandomFrom(&s, r)
+		require.NoError(t, err)
+		assert.Equal(t, 1, s.Equal(expected))
+	}
+
+	tests := []struct {
+		name string
+		r    io.Reader
+		err  error
+	}{
+		{"short", bytes.NewReader(random[:63]), io.ErrUnexpectedEOF},
+		{"empty", bytes.NewReader(nil), io.ErrUnexpectedEOF},
+		{"stalling", stallingReader{}, io.ErrNoProgress},
+		{"failing", io.MultiReader(bytes.NewReader(random[:10]), &failingReader{}), errFailingReader},
+		{"zero", bytes.NewReader(make([]byte, 64)), ErrZeroScalar},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScalarUInt32(7)
+			_, err := SetScalarRandomFrom(s, tt.r)
+			assert.True(t, errors.Is(err, tt.err), "expected %v, got %v", tt.err, err)
+			assert.Equal(t, 1, s.Equal(NewScalarUInt32(7)), "the scalar was modified")
+		})
+	}
+}
+
+var errFailingReader = errors.New("failing reader")
+
+type failingReader struct{}
+
+func (*failingReader) Read([]byte) (int, error) { return 0, errFailingReader }
@@ -2,12 +2,13 @@ package zk
 
 import (
 	"errors"
+	"fmt"
+	"io"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/hashing"
 	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
 	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
-
-	"crypto/sha512"
 )
 
 // Schnorr is a Non-Interactive Zero-Knowledge proof of knowledge of
@@ -36,20 +37,34 @@ type Schnorr struct {
 //
 // The proof returned is the tuple (S,R)
 func NewSchnorrProof(partyID party.ID, public *ristretto.Element, context []byte, private *ristretto.Scalar) *Schnorr {
+	proof, err := NewSchnorrProofFrom(partyID, public, context, private, nil)
+	if err != nil {
+		panic(err)
+	}
+	return proof
+}
+
+// NewSchnorrProofFrom is like NewSchnorrProof, but samples the nonce k with randomness read from r,
+// or from crypto/rand if r is nil. It fails if r does not return enough randomness.
+func NewSchnorrProofFrom(partyID party.ID, public *ristretto.Element, context []byte, private *ristretto.Scalar, r io.Reader) (*Schnorr, error) {
 	var proof Schnorr
 
 	// Compute commitment for random nonce
-	k := scalar.NewScalarRandom()
+	var k ristretto.Scalar
+	if _, err := scalar.SetScalarRandomFrom(&k, r); err != nil {
+		return nil, fmt.Errorf("zk: failed to sample nonce: %w", err)
+	}
+	defer k.Set(ristretto.NewScalar())
 
 	// M = [k] B
 	var M ristretto.Element
-	M.ScalarBaseMult(k)
+	M.ScalarBaseMult(&k)
 
 	S := challenge(partyID, context, public, &M)
 	proof.S.Set(S)
-	proof.R.MultiplyAdd(private, S, k)
+	proof.R.MultiplyAdd(private, S, &k)
 
-	return &proof
+	return &proof, nil
 }
 
 // Verify verifies that the zero knowledge proof is valid.
@@ -74,19 +89,9 @@ func (proof *Schnorr) Verify(partyID party.ID, public *ristretto.Element, contex
 //   public:  [secret] B
 //   M:       [k] B
 func challenge(partyID party.ID, context []byte, public, M *ristretto.Element) *ristretto.Scalar {
-	// S = H( ID || CTX || Public || M )
-	var S ristretto.Scalar
-
-	h := sha512.New()
-	_, _ = h.Write(partyID.Bytes())
-	_, _ = h.Write(context[:32])
-	_, _ = h.Write(public.Bytes())
-	_, _ = h.Write(M.Bytes())
-
-	buffer := make([]byte, 64)
-	// SetUniformBytes only returns an error when the length is wrong so we're okay here
-	_, _ = S.SetUniformBytes(h.Sum(buffer))
-	return &S
+	var sessionID [32]byte
+	copy(sessionID[:], context[:32])
+	return hashing.SchnorrChallenge(partyID, sessionID, public, M)
 }
 
 //
@@ -117,8 +122,8 @@ func (proof *Schnorr) UnmarshalBinary(data []byte) error {
 }
 
 func (proof *Schnorr) BytesAppend(existing []byte) (data []byte, err error) {
-	existing = append(existing, proof.S.Bytes()...)
-	existing = append(existing, proof.R.Bytes()...)
+	existing = scalar.AppendBytes(existing, &proof.S)
+	existing = scalar.AppendBytes(existing, &proof.R)
 	return existing, nil
 }
 
@@ -19,3 +19,23 @@ func TestSchnorrProof(t *testing.T) {
 	require.True(t, publicComputed.Equal(public) == 1)
 	require.True(t, proof.Verify(partyID, public, ctx[:]))
 }
+
+func TestSchnorrProof_Forged(t *testing.T) {
+	var ctx [32]byte
+	partyID := party.ID(42)
+	public := new(ristretto.Element).ScalarBaseMult(scalar.NewScalarRandom())
+
+	// With S = 0, the verification equation does not depend on public,
+	// so this proof must be rejected by the challenge.
+	var forged Schnorr
+	forged.R.Set(scalar.NewScalarRandom())
+	require.False(t, forged.Verify(partyID, public, ctx[:]))
+
+	// A valid proof cannot be used by another party, or in another session
+	private := scalar.NewScalarRandom()
+	public.ScalarBaseMult(private)
+	proof := NewSchnorrProof(partyID, public, ctx[:], private)
+	require.False(t, proof.Verify(partyID+1, public, ctx[:]))
+	ctx[0] = 1
+	require.False(t, proof.Verify(partyID, public, ctx[:]))
+}
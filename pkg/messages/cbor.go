@@ -0,0 +1,725 @@
+package messages
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+// The CBOR encoding of a Message is a map with integer keys:
+//
+//     {
+//       1: type,
+//       2: from,
+//       3: to,
+//       4: session ID (byte string),
+//       5: content (map),
+//     }
+//
+// The content of each message type is also a map with integer keys, in which points and scalars are 32 byte strings:
+//
+//     KeyGen1: { 1: proof S, 2: proof R, 3: [commitments...], 4: encryption key (only with encrypted shares) }
+//     KeyGen2: { 1: share } or { 2: sealed share (48 byte string) }
+//     Sign1:   { 1: D, 2: E }
+//     Sign2:   { 1: Z }
+//     KeyGenEcho: { 1: digest }
+//     Packed:  { 1: type, 2: [recipients...], 3: [payloads...] }
+//     KeyGenBlame: { 1: [accused...], 2: [shares...] }
+//     KeyGenConfirm: { 1: digest }
+//     KeyGenComplaint: { 1: [accused...] }
+//     Enroll:  { 1: share }
+//     Repair1: { 1: [masks...] }
+//     Repair2: { 1: share }
+//     KeyGenBatch1: { 1: [KeyGen1 contents without encryption key...] }
+//     KeyGenBatch2: { 1: [shares...] }
+//
+// The payloads of a Packed message are byte strings containing the binary encoding of the content for each recipient.
+//
+// The content of an Extension is instead the byte string returned by its MarshalBinary method.
+//
+// The encoding is deterministic as defined in RFC 8949 Section 4.2: all lengths are definite,
+// integers use the shortest form, and the keys of maps are sorted.
+// Decoding is strict, and rejects any other encoding, including unknown keys.
+
+// ErrInvalidCBOR is wrapped by the errors returned when decoding invalid CBOR.
+var ErrInvalidCBOR = errors.New("invalid CBOR")
+
+const (
+	cborMajorUint  byte = 0
+	cborMajorBytes byte = 2
+	cborMajorArray byte = 4
+	cborMajorMap   byte = 5
+)
+
+const (
+	cborKeyType uint64 = iota + 1
+	cborKeyFrom
+	cborKeyTo
+	cborKeySessionID
+	cborKeyContent
+)
+
+// MarshalCBOR returns the deterministic CBOR encoding of m.
+func (m *Message) MarshalCBOR() ([]byte, error) {
+	// Perform the same checks as MarshalBinary
+	data, err := m.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("message.MarshalCBOR: %w", err)
+	}
+	content := data[headerSize:]
+
+	buf := make([]byte, 0, len(data)+32)
+	buf = cborAppendHead(buf, cborMajorMap, 5)
+	buf = cborAppendUint(buf, cborKeyType, uint64(m.Type))
+	buf = cborAppendUint(buf, cborKeyFrom, uint64(m.From))
+	buf = cborAppendUint(buf, cborKeyTo, uint64(m.To))
+	buf = cborAppendBytes(buf, cborKeySessionID, m.SessionID[:])
+	buf = cborAppendHead(buf, cborMajorUint, cborKeyContent)
+
+	switch m.Type {
+	case MessageTypeKeyGen1:
+		buf = cborAppendKeyGen1(buf, content, m.KeyGen1.EncryptionKey != nil)
+	case MessageTypeKeyGenBatch1:
+		buf = cborAppendHead(buf, cborMajorMap, 1)
+		buf = cborAppendHead(buf, cborMajorUint, 1)
+		buf = cborAppendHead(buf, cborMajorArray, uint64(len(m.KeyGenBatch1.Keys)))
+		content = content[party.IDByteSize:]
+		for i := range m.KeyGenBatch1.Keys {
+			size := m.KeyGenBatch1.Keys[i].Size()
+			buf = cborAppendKeyGen1(buf, content[:size], false)
+			content = content[size:]
+		}
+	case MessageTypeKeyGenBatch2:
+		buf = cborAppendHead(buf, cborMajorMap, 1)
+		buf = cborAppendHead(buf, cborMajorUint, 1)
+		buf = cborAppendHead(buf, cborMajorArray, uint64(len(m.KeyGenBatch2.Shares)))
+		for i := range m.KeyGenBatch2.Shares {
+			buf = cborAppendHead(buf, cborMajorBytes, 32)
+			buf = append(buf, m.KeyGenBatch2.Shares[i].Bytes()...)
+		}
+	case MessageTypeKeyGen2:
+		buf = cborAppendHead(buf, cborMajorMap, 1)
+		if m.KeyGen2.SealedShare != nil {
+			buf = cborAppendBytes(buf, 2, content)
+		} else {
+			buf = cborAppendBytes(buf, 1, content)
+		}
+	case MessageTypeSign2, MessageTypeKeyGenEcho, MessageTypeKeyGenConfirm, MessageTypeEnroll, MessageTypeRepair2:
+		buf = cborAppendHead(buf, cborMajorMap, 1)
+		buf = cborAppendBytes(buf, 1, content)
+	case MessageTypeSign1:
+		buf = cborAppendHead(buf, cborMajorMap, 2)
+		buf = cborAppendBytes(buf, 1, content[:32])
+		buf = cborAppendBytes(buf, 2, content[32:])
+	case MessageTypePacked:
+		buf = cborAppendHead(buf, cborMajorMap, 3)
+		buf = cborAppendUint(buf, 1, uint64(m.Packed.Type))
+		buf = cborAppendHead(buf, cborMajorUint, 2)
+		buf = cborAppendHead(buf, cborMajorArray, uint64(len(m.Packed.To)))
+		for _, to := range m.Packed.To {
+			buf = cborAppendHead(buf, cborMajorUint, uint64(to))
+		}
+		buf = cborAppendHead(buf, cborMajorUint, 3)
+		buf = cborAppendHead(buf, cborMajorArray, uint64(len(m.Packed.Payloads)))
+		for _, payload := range m.Packed.Payloads {
+			buf = cborAppendHead(buf, cborMajorBytes, uint64(len(payload)))
+			buf = append(buf, payload...)
+		}
+	case MessageTypeKeyGenBlame:
+		buf = cborAppendHead(buf, cborMajorMap, 2)
+		buf = cborAppendHead(buf, cborMajorUint, 1)
+		buf = cborAppendHead(buf, cborMajorArray, uint64(len(m.KeyGenBlame.Complaints)))
+		for _, c := range m.KeyGenBlame.Complaints {
+			buf = cborAppendHead(buf, cborMajorUint, uint64(c.Accused))
+		}
+		buf = cborAppendHead(buf, cborMajorUint, 2)
+		buf = cborAppendHead(buf, cborMajorArray, uint64(len(m.KeyGenBlame.Complaints)))
+		for i := range m.KeyGenBlame.Complaints {
+			buf = cborAppendHead(buf, cborMajorBytes, 32)
+			buf = append(buf, m.KeyGenBlame.Complaints[i].Share.Bytes()...)
+		}
+	case MessageTypeKeyGenComplaint:
+		buf = cborAppendHead(buf, cborMajorMap, 1)
+		buf = cborAppendHead(buf, cborMajorUint, 1)
+		buf = cborAppendHead(buf, cborMajorArray, uint64(len(m.KeyGenComplaint.Accused)))
+		for _, id := range m.KeyGenComplaint.Accused {
+			buf = cborAppendHead(buf, cborMajorUint, uint64(id))
+		}
+	case MessageTypeRepair1:
+		buf = cborAppendHead(buf, cborMajorMap, 1)
+		buf = cborAppendHead(buf, cborMajorUint, 1)
+		buf = cborAppendHead(buf, cborMajorArray, uint64(len(m.Repair1.Masks)))
+		for i := range m.Repair1.Masks {
+			buf = cborAppendHead(buf, cborMajorBytes, 32)
+			buf = append(buf, m.Repair1.Masks[i].Bytes()...)
+		}
+	default:
+		// The content of an Extension is a byte string
+		buf = cborAppendHead(buf, cborMajorBytes, uint64(len(content)))
+		buf = append(buf, content...)
+	}
+	return buf, nil
+}
+
+// UnmarshalCBOR decodes the deterministic CBOR encoding of a message produced by MarshalCBOR.
+// The message is validated in the same way as in UnmarshalBinary.
+func (m *Message) UnmarshalCBOR(data []byte) (err error) {
+	defer recoverPanic(&err)
+
+	d := cborDecoder{data: data}
+
+	// The binary encoding of the message is reconstructed, and then decoded by UnmarshalBinary.
+	buf := make([]byte, 0, len(data))
+	if err := d.expectLength(cborMajorMap, 5); err != nil {
+		return fmt.Errorf("messages.UnmarshalCBOR: %w", err)
+	}
+	msgType, err := d.readUintField(cborKeyType, math.MaxUint8)
+	if err != nil {
+		return fmt.Errorf("messages.UnmarshalCBOR: type: %w", err)
+	}
+	from, err := d.readUintField(cborKeyFrom, uint64(party.MaxID))
+	if err != nil {
+		return fmt.Errorf("messages.UnmarshalCBOR: from: %w", err)
+	}
+	to, err := d.readUintField(cborKeyTo, uint64(party.MaxID))
+	if err != nil {
+		return fmt.Errorf("messages.UnmarshalCBOR: to: %w", err)
+	}
+	sessionID, err := d.readBytesField(cborKeySessionID, SessionIDSize)
+	if err != nil {
+		return fmt.Errorf("messages.UnmarshalCBOR: session ID: %w", err)
+	}
+	buf = append(buf, headerMagic...)
+	buf = append(buf, Version)
+	buf = append(buf, byte(msgType))
+	buf = append(buf, party.ID(from).Bytes()...)
+	buf = append(buf, party.ID(to).Bytes()...)
+	buf = append(buf, sessionID...)
+
+	if err = d.expectKey(cborKeyContent); err != nil {
+		return fmt.Errorf("messages.UnmarshalCBOR: %w", err)
+	}
+	switch MessageType(msgType) {
+	case MessageTypeKeyGen1:
+		buf, err = d.readKeyGen1(buf)
+	case MessageTypeKeyGen2:
+		buf, err = d.readKeyGen2(buf)
+	case MessageTypeSign2, MessageTypeKeyGenEcho, MessageTypeKeyGenConfirm, MessageTypeEnroll, MessageTypeRepair2:
+		buf, err = d.readPoints(buf, 1)
+	case MessageTypeSign1:
+		buf, err = d.readPoints(buf, 2)
+	case MessageTypePacked:
+		buf, err = d.readPacked(buf)
+	case MessageTypeKeyGenBlame:
+		buf, err = d.readKeyGenBlame(buf)
+	case MessageTypeKeyGenComplaint:
+		buf, err = d.readKeyGenComplaint(buf)
+	case MessageTypeRepair1:
+		buf, err = d.readRepair1(buf)
+	case MessageTypeKeyGenBatch1:
+		buf, err = d.readKeyGenBatch1(buf)
+	case MessageTypeKeyGenBatch2:
+		buf, err = d.readKeyGenBatch2(buf)
+	default:
+		if !MessageType(msgType).IsExtension() {
+			err = errors.New("invalid message type")
+			break
+		}
+		var content []byte
+		if content, err = d.readByteString(); err == nil {
+			buf = append(buf, content...)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("messages.UnmarshalCBOR: content: %w", err)
+	}
+
+	if len(d.data) != 0 {
+		return fmt.Errorf("messages.UnmarshalCBOR: trailing data: %w", ErrInvalidCBOR)
+	}
+
+	var msg Message
+	if err = msg.UnmarshalBinary(buf); err != nil {
+		return fmt.Errorf("messages.UnmarshalCBOR: %w", err)
+	}
+	*m = msg
+	return nil
+}
+
+// cborAppendKeyGen1 appends the map encoding the binary content of a KeyGen1 message,
+// which ends with an encryption key if encrypted is true.
+func cborAppendKeyGen1(buf, content []byte, encrypted bool) []byte {
+	// proof S ∥ proof R ∥ degree ∥ commitments [∥ encryption key]
+	commitments := content[64+party.IDByteSize:]
+	var encryptionKey []byte
+	if encrypted {
+		commitments, encryptionKey = commitments[:len(commitments)-32], commitments[len(commitments)-32:]
+		buf = cborAppendHead(buf, cborMajorMap, 4)
+	} else {
+		buf = cborAppendHead(buf, cborMajorMap, 3)
+	}
+	buf = cborAppendBytes(buf, 1, content[:32])
+	buf = cborAppendBytes(buf, 2, content[32:64])
+	buf = cborAppendHead(buf, cborMajorUint, 3)
+	buf = cborAppendHead(buf, cborMajorArray, uint64(len(commitments)/32))
+	for ; len(commitments) > 0; commitments = commitments[32:] {
+		buf = cborAppendHead(buf, cborMajorBytes, 32)
+		buf = append(buf, commitments[:32]...)
+	}
+	if encryptionKey != nil {
+		buf = cborAppendBytes(buf, 4, encryptionKey)
+	}
+	return buf
+}
+
+// cborAppendHead appends the head of a data item of the given major type, using the shortest form for n.
+func cborAppendHead(buf []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(buf, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(buf, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return append(buf, major|25, byte(n>>8), byte(n))
+	case n <= math.MaxUint32:
+		return append(buf, major|26, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	default:
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], n)
+		return append(append(buf, major|27), b[:]...)
+	}
+}
+
+// cborAppendUint appends the map entry key: n.
+func cborAppendUint(buf []byte, key, n uint64) []byte {
+	buf = cborAppendHead(buf, cborMajorUint, key)
+	return cborAppendHead(buf, cborMajorUint, n)
+}
+
+// cborAppendBytes appends the map entry key: b, where b is encoded as a byte string.
+func cborAppendBytes(buf []byte, key uint64, b []byte) []byte {
+	buf = cborAppendHead(buf, cborMajorUint, key)
+	buf = cborAppendHead(buf, cborMajorBytes, uint64(len(b)))
+	return append(buf, b...)
+}
+
+// cborDecoder reads data items from data, which is consumed as they are read.
+type cborDecoder struct {
+	data []byte
+}
+
+// readHead reads the head of a data item of the given major type, and returns its argument.
+// Indefinite lengths, and arguments which are not encoded in the shortest form are rejected.
+func (d *cborDecoder) readHead(major byte) (uint64, error) {
+	if len(d.data) == 0 {
+		return 0, fmt.Errorf("unexpected end of data: %w", ErrInvalidCBOR)
+	}
+	if d.data[0]>>5 != major {
+		return 0, fmt.Errorf("expected major type %d, got %d: %w", major, d.data[0]>>5, ErrInvalidCBOR)
+	}
+	info := d.data[0] & 0x1f
+	d.data = d.data[1:]
+
+	var size int
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info <= 27:
+		size = 1 << (info - 24)
+	default:
+		return 0, fmt.Errorf("unsupported additional information %d: %w", info, ErrInvalidCBOR)
+	}
+	if len(d.data) < size {
+		return 0, fmt.Errorf("unexpected end of data: %w", ErrInvalidCBOR)
+	}
+	var n, min uint64
+	switch size {
+	case 1:
+		n, min = uint64(d.data[0]), 24
+	case 2:
+		n, min = uint64(binary.BigEndian.Uint16(d.data)), math.MaxUint8+1
+	case 4:
+		n, min = uint64(binary.BigEndian.Uint32(d.data)), math.MaxUint16+1
+	case 8:
+		n, min = binary.BigEndian.Uint64(d.data), math.MaxUint32+1
+	}
+	d.data = d.data[size:]
+	if n < min {
+		return 0, fmt.Errorf("integer is not encoded in the shortest form: %w", ErrInvalidCBOR)
+	}
+	return n, nil
+}
+
+// expectLength reads the head of a map or array, and checks that it contains n elements.
+func (d *cborDecoder) expectLength(major byte, n uint64) error {
+	length, err := d.readHead(major)
+	if err != nil {
+		return err
+	}
+	if length != n {
+		return fmt.Errorf("expected length %d, got %d: %w", n, length, ErrInvalidCBOR)
+	}
+	return nil
+}
+
+// expectKey reads the next key of a map, and checks that it is equal to key.
+// Since the keys are expected in order, this rejects unknown, duplicate and unsorted keys.
+func (d *cborDecoder) expectKey(key uint64) error {
+	k, err := d.readHead(cborMajorUint)
+	if err != nil {
+		return err
+	}
+	if k != key {
+		return fmt.Errorf("expected key %d, got %d: %w", key, k, ErrInvalidCBOR)
+	}
+	return nil
+}
+
+// readUintField reads the map entry key: n, and checks that n is at most max.
+func (d *cborDecoder) readUintField(key, max uint64) (uint64, error) {
+	if err := d.expectKey(key); err != nil {
+		return 0, err
+	}
+	n, err := d.readHead(cborMajorUint)
+	if err != nil {
+		return 0, err
+	}
+	if n > max {
+		return 0, fmt.Errorf("value %d is too large: %w", n, ErrInvalidCBOR)
+	}
+	return n, nil
+}
+
+// readBytes reads a byte string of length size.
+func (d *cborDecoder) readBytes(size int) ([]byte, error) {
+	if err := d.expectLength(cborMajorBytes, uint64(size)); err != nil {
+		return nil, err
+	}
+	if len(d.data) < size {
+		return nil, fmt.Errorf("unexpected end of data: %w", ErrInvalidCBOR)
+	}
+	b := d.data[:size]
+	d.data = d.data[size:]
+	return b, nil
+}
+
+// readByteString reads a byte string of any length.
+func (d *cborDecoder) readByteString() ([]byte, error) {
+	size, err := d.readHead(cborMajorBytes)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(d.data)) < size {
+		return nil, fmt.Errorf("unexpected end of data: %w", ErrInvalidCBOR)
+	}
+	b := d.data[:size]
+	d.data = d.data[size:]
+	return b, nil
+}
+
+// readBytesField reads the map entry key: b, where b is a byte string of length size.
+func (d *cborDecoder) readBytesField(key uint64, size int) ([]byte, error) {
+	if err := d.expectKey(key); err != nil {
+		return nil, err
+	}
+	return d.readBytes(size)
+}
+
+// readPoints reads a map containing n points or scalars with the keys 1, ..., n, and appends them to buf.
+func (d *cborDecoder) readPoints(buf []byte, n int) ([]byte, error) {
+	if err := d.expectLength(cborMajorMap, uint64(n)); err != nil {
+		return nil, err
+	}
+	for key := 1; key <= n; key++ {
+		b, err := d.readBytesField(uint64(key), 32)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, b...)
+	}
+	return buf, nil
+}
+
+// readKeyGen1 reads the content of a KeyGen1 message, and appends its binary encoding to buf.
+func (d *cborDecoder) readKeyGen1(buf []byte) ([]byte, error) {
+	length, err := d.readHead(cborMajorMap)
+	if err != nil {
+		return nil, err
+	}
+	if length != 3 && length != 4 {
+		return nil, fmt.Errorf("expected a map of 3 or 4 items (got %d): %w", length, ErrInvalidCBOR)
+	}
+	for key := uint64(1); key <= 2; key++ {
+		b, err := d.readBytesField(key, 32)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, b...)
+	}
+	if err := d.expectKey(3); err != nil {
+		return nil, err
+	}
+	count, err := d.readHead(cborMajorArray)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 || count > math.MaxUint16+1 {
+		return nil, fmt.Errorf("invalid number of commitments: %w", ErrInvalidMessage)
+	}
+	buf = append(buf, party.Size(count-1).Bytes()...)
+	for i := uint64(0); i < count; i++ {
+		b, err := d.readBytes(32)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, b...)
+	}
+	if length == 4 {
+		b, err := d.readBytesField(4, sizeKeygen1EncryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, b...)
+	}
+	return buf, nil
+}
+
+// readKeyGen2 reads the content of a KeyGen2 message, containing either a share or a sealed share,
+// and appends its binary encoding to buf.
+func (d *cborDecoder) readKeyGen2(buf []byte) ([]byte, error) {
+	if err := d.expectLength(cborMajorMap, 1); err != nil {
+		return nil, err
+	}
+	key, err := d.readHead(cborMajorUint)
+	if err != nil {
+		return nil, err
+	}
+	var b []byte
+	switch key {
+	case 1:
+		b, err = d.readBytes(sizeKeygen2)
+	case 2:
+		b, err = d.readBytes(SizeSealedShare)
+	default:
+		return nil, fmt.Errorf("unexpected key %d: %w", key, ErrInvalidCBOR)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, b...), nil
+}
+
+// readPacked reads the content of a Packed message, and appends its binary encoding to buf.
+func (d *cborDecoder) readPacked(buf []byte) ([]byte, error) {
+	if err := d.expectLength(cborMajorMap, 3); err != nil {
+		return nil, err
+	}
+	t, err := d.readUintField(1, math.MaxUint8)
+	if err != nil {
+		return nil, err
+	}
+	if err = d.expectKey(2); err != nil {
+		return nil, err
+	}
+	n, err := d.readHead(cborMajorArray)
+	if err != nil {
+		return nil, err
+	}
+	// every recipient takes at least one byte, which bounds the allocation by the size of data
+	if n == 0 || n > math.MaxUint16 || n > uint64(len(d.data)) {
+		return nil, fmt.Errorf("invalid number of recipients: %w", ErrInvalidMessage)
+	}
+	to := make([]party.ID, n)
+	for i := range to {
+		id, err := d.readHead(cborMajorUint)
+		if err != nil {
+			return nil, err
+		}
+		if id > uint64(party.MaxID) {
+			return nil, fmt.Errorf("value %d is too large: %w", id, ErrInvalidCBOR)
+		}
+		to[i] = party.ID(id)
+	}
+	if err = d.expectKey(3); err != nil {
+		return nil, err
+	}
+	if err = d.expectLength(cborMajorArray, n); err != nil {
+		return nil, err
+	}
+	buf = append(buf, byte(t), byte(n>>8), byte(n))
+	for _, id := range to {
+		payload, err := d.readByteString()
+		if err != nil {
+			return nil, err
+		}
+		if len(payload) > math.MaxUint16 {
+			return nil, fmt.Errorf("payload is too long: %w", ErrInvalidMessage)
+		}
+		buf = appendID(buf, id)
+		buf = append(buf, byte(len(payload)>>8), byte(len(payload)))
+		buf = append(buf, payload...)
+	}
+	return buf, nil
+}
+
+// readKeyGenBlame reads the content of a KeyGenBlame message, and appends its binary encoding to buf.
+func (d *cborDecoder) readKeyGenBlame(buf []byte) ([]byte, error) {
+	if err := d.expectLength(cborMajorMap, 2); err != nil {
+		return nil, err
+	}
+	if err := d.expectKey(1); err != nil {
+		return nil, err
+	}
+	n, err := d.readHead(cborMajorArray)
+	if err != nil {
+		return nil, err
+	}
+	// every accused party takes at least one byte, which bounds the allocation by the size of data
+	if n > uint64(party.MaxID) || n > uint64(len(d.data)) {
+		return nil, fmt.Errorf("invalid number of complaints: %w", ErrInvalidMessage)
+	}
+	accused := make([]party.ID, n)
+	for i := range accused {
+		id, err := d.readHead(cborMajorUint)
+		if err != nil {
+			return nil, err
+		}
+		if id > uint64(party.MaxID) {
+			return nil, fmt.Errorf("value %d is too large: %w", id, ErrInvalidCBOR)
+		}
+		accused[i] = party.ID(id)
+	}
+	if err = d.expectKey(2); err != nil {
+		return nil, err
+	}
+	if err = d.expectLength(cborMajorArray, n); err != nil {
+		return nil, err
+	}
+	buf = append(buf, party.Size(n).Bytes()...)
+	for _, id := range accused {
+		share, err := d.readBytes(32)
+		if err != nil {
+			return nil, err
+		}
+		buf = appendID(buf, id)
+		buf = append(buf, share...)
+	}
+	return buf, nil
+}
+
+// readKeyGenComplaint reads the content of a KeyGenComplaint message, and appends its binary encoding to buf.
+func (d *cborDecoder) readKeyGenComplaint(buf []byte) ([]byte, error) {
+	if err := d.expectLength(cborMajorMap, 1); err != nil {
+		return nil, err
+	}
+	if err := d.expectKey(1); err != nil {
+		return nil, err
+	}
+	n, err := d.readHead(cborMajorArray)
+	if err != nil {
+		return nil, err
+	}
+	// every accused party takes at least one byte, which bounds the allocation by the size of data
+	if n > uint64(party.MaxID) || n > uint64(len(d.data)) {
+		return nil, fmt.Errorf("invalid number of complaints: %w", ErrInvalidMessage)
+	}
+	buf = append(buf, party.Size(n).Bytes()...)
+	for i := uint64(0); i < n; i++ {
+		id, err := d.readHead(cborMajorUint)
+		if err != nil {
+			return nil, err
+		}
+		if id > uint64(party.MaxID) {
+			return nil, fmt.Errorf("value %d is too large: %w", id, ErrInvalidCBOR)
+		}
+		buf = appendID(buf, party.ID(id))
+	}
+	return buf, nil
+}
+
+// readRepair1 reads the content of a Repair1 message, and appends its binary encoding to buf.
+func (d *cborDecoder) readRepair1(buf []byte) ([]byte, error) {
+	if err := d.expectLength(cborMajorMap, 1); err != nil {
+		return nil, err
+	}
+	if err := d.expectKey(1); err != nil {
+		return nil, err
+	}
+	n, err := d.readHead(cborMajorArray)
+	if err != nil {
+		return nil, err
+	}
+	// every commitment takes more than one byte, which bounds the allocation by the size of data
+	if n > uint64(party.MaxID) || n > uint64(len(d.data)) {
+		return nil, fmt.Errorf("invalid number of commitments: %w", ErrInvalidMessage)
+	}
+	buf = append(buf, party.Size(n).Bytes()...)
+	for i := uint64(0); i < n; i++ {
+		b, err := d.readBytes(32)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, b...)
+	}
+	return buf, nil
+}
+
+// readKeyGenBatch1 reads the content of a KeyGenBatch1 message, and appends its binary encoding to buf.
+func (d *cborDecoder) readKeyGenBatch1(buf []byte) ([]byte, error) {
+	if err := d.expectLength(cborMajorMap, 1); err != nil {
+		return nil, err
+	}
+	if err := d.expectKey(1); err != nil {
+		return nil, err
+	}
+	k, err := d.readHead(cborMajorArray)
+	if err != nil {
+		return nil, err
+	}
+	// every key takes more than one byte, which bounds the number of iterations by the size of data
+	if k > uint64(party.MaxID) || k > uint64(len(d.data)) {
+		return nil, fmt.Errorf("invalid number of keys: %w", ErrInvalidMessage)
+	}
+	buf = append(buf, party.Size(k).Bytes()...)
+	for i := uint64(0); i < k; i++ {
+		// the keys of a batch have no encryption key, so their map contains 3 items
+		if len(d.data) == 0 || d.data[0] != cborMajorMap<<5|3 {
+			return nil, fmt.Errorf("expected a map of 3 items: %w", ErrInvalidCBOR)
+		}
+		if buf, err = d.readKeyGen1(buf); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+// readKeyGenBatch2 reads the content of a KeyGenBatch2 message, and appends its binary encoding to buf.
+func (d *cborDecoder) readKeyGenBatch2(buf []byte) ([]byte, error) {
+	if err := d.expectLength(cborMajorMap, 1); err != nil {
+		return nil, err
+	}
+	if err := d.expectKey(1); err != nil {
+		return nil, err
+	}
+	k, err := d.readHead(cborMajorArray)
+	if err != nil {
+		return nil, err
+	}
+	// every share takes more than one byte, which bounds the allocation by the size of data
+	if k > uint64(party.MaxID) || k > uint64(len(d.data)) {
+		return nil, fmt.Errorf("invalid number of shares: %w", ErrInvalidMessage)
+	}
+	buf = append(buf, party.Size(k).Bytes()...)
+	for i := uint64(0); i < k; i++ {
+		b, err := d.readBytes(32)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, b...)
+	}
+	return buf, nil
+}
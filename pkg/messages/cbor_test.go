@@ -0,0 +1,159 @@
+package messages
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/zk"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+type cborVector struct {
+	name string
+	msg  *Message
+	cbor string
+}
+
+// cborVectors returns deterministic messages of each type, together with their expected CBOR encoding.
+// They can be used to check the interoperability with other implementations.
+func cborVectors(t *testing.T) []cborVector {
+	B := ristretto.NewGeneratorElement()
+	twoB := new(ristretto.Element).Add(B, B)
+
+	var proof zk.Schnorr
+	proof.S.Set(party.ID(1).Scalar())
+	proof.R.Set(party.ID(2).Scalar())
+	var commitments polynomial.Exponent
+	require.NoError(t, commitments.UnmarshalBinary(append(append(party.Size(1).Bytes(), B.Bytes()...), twoB.Bytes()...)))
+
+	keygen1 := NewKeyGen1(1, &proof, &commitments)
+	keygen1.SessionID = DeriveSessionID(party.IDSlice{1, 2}, []byte("vector"))
+
+	return []cborVector{
+		{
+			"KeyGen1",
+			keygen1,
+			"a50101020103000458206441e05766f6c93743bacd88eeda9f295628fdd8a748af9da9d3e365a8c7cb2d05a3015820010000" +
+				"0000000000000000000000000000000000000000000000000000000000025820020000000000000000000000000000000000" +
+				"000000000000000000000000000003825820e2f2ae0a6abc4e71a884a961c500515f58e30b6aa582dd8db6a65945e08d2d76" +
+				"58206a493210f7499cd17fecb510ae0cea23a110e8d5b901f8acadd3095c73a3b919",
+		},
+		{
+			"KeyGen2",
+			NewKeyGen2(1, 300, party.ID(7).Scalar()),
+			"a5010202010319012c0458200000000000000000000000000000000000000000000000000000000000000000" +
+				"05a10158200700000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			"Sign1",
+			NewSign1(3, B, twoB),
+			"a50103020303000458200000000000000000000000000000000000000000000000000000000000000000" +
+				"05a2015820e2f2ae0a6abc4e71a884a961c500515f58e30b6aa582dd8db6a65945e08d2d76" +
+				"0258206a493210f7499cd17fecb510ae0cea23a110e8d5b901f8acadd3095c73a3b919",
+		},
+		{
+			"Sign2",
+			NewSign2(4, party.ID(1).Scalar()),
+			"a50104020403000458200000000000000000000000000000000000000000000000000000000000000000" +
+				"05a10158200100000000000000000000000000000000000000000000000000000000000000",
+		},
+	}
+}
+
+func TestMessage_CBORVectors(t *testing.T) {
+	for _, v := range cborVectors(t) {
+		t.Run(v.name, func(t *testing.T) {
+			data, err := v.msg.MarshalCBOR()
+			require.NoError(t, err)
+			assert.Equal(t, v.cbor, hex.EncodeToString(data))
+
+			expected, err := hex.DecodeString(v.cbor)
+			require.NoError(t, err)
+			var msg Message
+			require.NoError(t, msg.UnmarshalCBOR(expected))
+			assert.True(t, v.msg.Equal(&msg), "messages are not equal")
+		})
+	}
+}
+
+func TestMessage_MarshalCBOR(t *testing.T) {
+	for _, msg := range testMessages() {
+		data, err := CBORCodec.Marshal(msg)
+		require.NoError(t, err)
+
+		var msg2 Message
+		require.NoError(t, CBORCodec.Unmarshal(data, &msg2))
+		assert.True(t, msg.Equal(&msg2), "messages are not equal")
+
+		bin, err := msg.MarshalBinary()
+		require.NoError(t, err)
+		bin2, err := msg2.MarshalBinary()
+		require.NoError(t, err)
+		assert.Equal(t, bin, bin2)
+	}
+}
+
+func TestMessage_UnmarshalCBOR_Invalid(t *testing.T) {
+	// Sign2 message from party 4
+	const valid = "a50104020403000458200000000000000000000000000000000000000000000000000000000000000000" +
+		"05a10158200100000000000000000000000000000000000000000000000000000000000000"
+	zero32 := strings.Repeat("00", 32)
+	session := "045820" + zero32
+
+	tests := []struct {
+		name  string
+		data  string
+		isErr error
+	}{
+		{"truncated", valid[:len(valid)-2], ErrInvalidCBOR},
+		{"trailing data", valid + "00", ErrInvalidCBOR},
+		{"indefinite map", "bf0104020403000458" + valid[18:] + "ff", ErrInvalidCBOR},
+		{"indefinite byte string", valid[:16] + "5f5820" + zero32 + "ff" + valid[16+len(session)-2:], ErrInvalidCBOR},
+		{"non shortest integer", "a5011804020403000458" + valid[18:], ErrInvalidCBOR},
+		{"missing key", "a401040204" + session + "05a1015820" + zero32, ErrInvalidCBOR},
+		{"unknown key", valid[:16+len(session)-2] + "06" + valid[16+len(session):], ErrInvalidCBOR},
+		{"unsorted keys", "a50204010403000458" + valid[18:], ErrInvalidCBOR},
+		{"unknown content key", valid[:len(valid)-72] + "a1025820" + zero32, ErrInvalidCBOR},
+		{"short scalar", valid[:len(valid)-72] + "a101581f" + zero32[2:], ErrInvalidCBOR},
+		{"text string", valid[:len(valid)-72] + "a1017820" + zero32, ErrInvalidCBOR},
+		{"large sender", "a50104021b0000000100000000" + valid[10:], ErrInvalidCBOR},
+		{"non canonical scalar", valid[:len(valid)-72] + "a1015820" + strings.Repeat("ff", 32), nil},
+		{"zero sender", "a501040200030004" + valid[16:], nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.data)
+			require.NoError(t, err)
+			var msg Message
+			err = msg.UnmarshalCBOR(data)
+			require.Error(t, err)
+			if tt.isErr != nil {
+				assert.True(t, errors.Is(err, tt.isErr), "error %v should wrap %v", err, tt.isErr)
+			}
+		})
+	}
+}
+
+func FuzzMessage_UnmarshalCBOR(f *testing.F) {
+	for _, msg := range testMessages() {
+		data, err := msg.MarshalCBOR()
+		require.NoError(f, err)
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var msg Message
+		if err := msg.UnmarshalCBOR(data); err != nil {
+			return
+		}
+		// The encoding is deterministic, so accepted messages are encoded identically
+		encoded, err := msg.MarshalCBOR()
+		require.NoError(t, err)
+		assert.Equal(t, data, encoded)
+	})
+}
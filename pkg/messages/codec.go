@@ -0,0 +1,50 @@
+package messages
+
+import "encoding/json"
+
+// A Codec converts messages to and from the encoding used for transport.
+// All parties of a protocol execution must use the same Codec.
+type Codec interface {
+	// Marshal returns the encoding of msg.
+	Marshal(msg *Message) ([]byte, error)
+	// Unmarshal decodes data into msg, and performs the same validation as Message.UnmarshalBinary.
+	Unmarshal(data []byte, msg *Message) error
+}
+
+var (
+	// BinaryCodec uses Message.MarshalBinary and Message.UnmarshalBinary.
+	BinaryCodec Codec = binaryCodec{}
+	// JSONCodec uses the JSON encoding of Message.
+	JSONCodec Codec = jsonCodec{}
+	// CBORCodec uses Message.MarshalCBOR and Message.UnmarshalCBOR.
+	CBORCodec Codec = cborCodec{}
+	// CompatBinaryCodec is like BinaryCodec, but also decodes messages in the legacy encoding without version
+	// using Message.UnmarshalBinaryLegacy.
+	// It should only be used while migrating from an earlier version of this library.
+	CompatBinaryCodec Codec = compatBinaryCodec{}
+)
+
+type binaryCodec struct{}
+
+func (binaryCodec) Marshal(msg *Message) ([]byte, error)      { return msg.MarshalBinary() }
+func (binaryCodec) Unmarshal(data []byte, msg *Message) error { return msg.UnmarshalBinary(data) }
+
+type compatBinaryCodec struct{}
+
+func (compatBinaryCodec) Marshal(msg *Message) ([]byte, error) { return msg.MarshalBinary() }
+func (compatBinaryCodec) Unmarshal(data []byte, msg *Message) error {
+	if len(data) > 0 && data[0] != headerMagic[0] {
+		return msg.UnmarshalBinaryLegacy(data)
+	}
+	return msg.UnmarshalBinary(data)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(msg *Message) ([]byte, error)      { return json.Marshal(msg) }
+func (jsonCodec) Unmarshal(data []byte, msg *Message) error { return json.Unmarshal(data, msg) }
+
+type cborCodec struct{}
+
+func (cborCodec) Marshal(msg *Message) ([]byte, error)      { return msg.MarshalCBOR() }
+func (cborCodec) Unmarshal(data []byte, msg *Message) error { return msg.UnmarshalCBOR(data) }
@@ -0,0 +1,94 @@
+package messages
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+const sizeEnroll = 32
+
+// Enroll is sent by each helper of the enrollment protocol to the party which is added to the key, in the last round.
+type Enroll struct {
+	// Share is the sender's contribution to the share of the recipient.
+	// It is masked, so that it reveals nothing about the share of the sender.
+	Share ristretto.Scalar
+}
+
+func NewEnroll(from, to party.ID, share *ristretto.Scalar) *Message {
+	return &Message{
+		Header: Header{
+			Type: MessageTypeEnroll,
+			From: from,
+			To:   to,
+		},
+		Enroll: &Enroll{Share: *share},
+	}
+}
+
+// AppendBinary appends the encoding of m to dst and returns the extended slice.
+// It does not allocate if dst has a capacity of at least Size() additional bytes.
+func (m *Enroll) AppendBinary(dst []byte) ([]byte, error) {
+	return scalar.AppendBytes(dst, &m.Share), nil
+}
+
+// BytesAppend is the same as AppendBinary.
+func (m *Enroll) BytesAppend(existing []byte) ([]byte, error) {
+	return m.AppendBinary(existing)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (m *Enroll) MarshalBinary() ([]byte, error) {
+	return m.AppendBinary(make([]byte, 0, sizeEnroll))
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (m *Enroll) UnmarshalBinary(data []byte) error {
+	if len(data) != sizeEnroll {
+		return &FieldError{Field: "Enroll.Share", Err: fmt.Errorf("expected %d bytes (got %d)", sizeEnroll, len(data))}
+	}
+	if _, err := m.Share.SetCanonicalBytes(data); err != nil {
+		return &FieldError{Field: "Enroll.Share", Err: err}
+	}
+	return nil
+}
+
+func (m *Enroll) Size() int {
+	return sizeEnroll
+}
+
+func (m *Enroll) Equal(other interface{}) bool {
+	otherMsg, ok := other.(*Enroll)
+	if !ok {
+		return false
+	}
+	return otherMsg.Share.Equal(&m.Share) == 1
+}
+
+type jsonEnroll struct {
+	Share string `json:"share"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m *Enroll) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonEnroll{
+		Share: encodeHex(m.Share.Bytes()),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The scalar is validated as in UnmarshalBinary.
+func (m *Enroll) UnmarshalJSON(data []byte) error {
+	var out jsonEnroll
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	share, err := decodeHex(out.Share, sizeEnroll)
+	if err != nil {
+		return fmt.Errorf("enroll.Share: %w", err)
+	}
+	return m.UnmarshalBinary(share)
+}
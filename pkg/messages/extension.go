@@ -0,0 +1,115 @@
+package messages
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+// FirstExtensionType is the smallest MessageType which applications can register with RegisterExtension.
+// Smaller values are reserved for the messages of this library.
+const FirstExtensionType MessageType = 128
+
+var (
+	// ErrReservedMessageType is returned by RegisterExtension for a type below FirstExtensionType.
+	ErrReservedMessageType = errors.New("message type is reserved")
+	// ErrExtensionRegistered is returned by RegisterExtension when the type or name is already registered.
+	ErrExtensionRegistered = errors.New("message type is already registered")
+)
+
+// An Extension describes an application defined message type, which can be exchanged alongside the messages of
+// the protocol, using the same encodings and the same session.
+// The content of such a message is stored in Message.Extension.
+type Extension struct {
+	// Name identifies the type in the JSON encoding, and is returned by MessageType.String.
+	Name string
+
+	// Broadcast indicates whether messages of this type are sent to all parties, in which case their To field is 0,
+	// or to the single party given by their To field.
+	Broadcast bool
+
+	// MaxSize is the maximum size of the encoding of the content, which must be positive.
+	// Larger messages are rejected by Limits.UnmarshalBinary.
+	MaxSize int
+
+	// Unmarshal decodes the content of a message, as encoded by its MarshalBinary method.
+	// It must return an error for any invalid data.
+	Unmarshal func(data []byte) (encoding.BinaryMarshaler, error)
+}
+
+var (
+	extensionsMtx sync.RWMutex
+	extensions    = map[MessageType]Extension{}
+)
+
+// RegisterExtension registers the application defined message type t, so that messages of this type can be
+// encoded and decoded with all encodings except Protocol Buffers.
+// It is meant to be called during initialization, for example in an init function.
+//
+// An error wrapping ErrReservedMessageType is returned if t is smaller than FirstExtensionType,
+// and one wrapping ErrExtensionRegistered if t or ext.Name were already registered.
+func RegisterExtension(t MessageType, ext Extension) error {
+	switch {
+	case t < FirstExtensionType:
+		return fmt.Errorf("messages.RegisterExtension: %w: %d", ErrReservedMessageType, uint8(t))
+	case ext.Name == "" || ext.Unmarshal == nil || ext.MaxSize <= 0:
+		return errors.New("messages.RegisterExtension: Name, MaxSize and Unmarshal must be set")
+	}
+
+	extensionsMtx.Lock()
+	defer extensionsMtx.Unlock()
+	if _, ok := extensions[t]; ok {
+		return fmt.Errorf("messages.RegisterExtension: %w: %d", ErrExtensionRegistered, uint8(t))
+	}
+	if _, ok := typeByName(ext.Name); ok {
+		return fmt.Errorf("messages.RegisterExtension: %w: %q", ErrExtensionRegistered, ext.Name)
+	}
+	extensions[t] = ext
+	return nil
+}
+
+// lookupExtension returns the Extension registered for t.
+func lookupExtension(t MessageType) (Extension, bool) {
+	extensionsMtx.RLock()
+	defer extensionsMtx.RUnlock()
+	ext, ok := extensions[t]
+	return ext, ok
+}
+
+// typeByName returns the MessageType with the given name, among the types of the protocol and the extensions.
+// It should be called with extensionsMtx held.
+func typeByName(name string) (MessageType, bool) {
+	for t, n := range messageTypeNames {
+		if n == name {
+			return t, true
+		}
+	}
+	for t, ext := range extensions {
+		if ext.Name == name {
+			return t, true
+		}
+	}
+	return MessageTypeNone, false
+}
+
+// IsExtension returns true if t was registered with RegisterExtension.
+func (t MessageType) IsExtension() bool {
+	_, ok := lookupExtension(t)
+	return ok
+}
+
+// NewExtension returns a message of the extension type t, whose content is payload.
+// The session ID should be set to the one of the protocol execution, so that the message is accepted by the State.
+func NewExtension(t MessageType, from, to party.ID, payload encoding.BinaryMarshaler) *Message {
+	return &Message{
+		Header: Header{
+			Type: t,
+			From: from,
+			To:   to,
+		},
+		Extension: payload,
+	}
+}
@@ -0,0 +1,112 @@
+package messages
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+// testPayload is the content of the extension messages used in the tests.
+type testPayload []byte
+
+func (p testPayload) MarshalBinary() ([]byte, error) {
+	return append([]byte(nil), p...), nil
+}
+
+func unmarshalTestPayload(data []byte) (encoding.BinaryMarshaler, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty payload")
+	}
+	return testPayload(append([]byte(nil), data...)), nil
+}
+
+// registerTestExtension registers t for the duration of the test.
+func registerTestExtension(t *testing.T, typ MessageType, name string, broadcast bool) {
+	require.NoError(t, RegisterExtension(typ, Extension{
+		Name:      name,
+		Broadcast: broadcast,
+		MaxSize:   16,
+		Unmarshal: unmarshalTestPayload,
+	}))
+	t.Cleanup(func() {
+		extensionsMtx.Lock()
+		delete(extensions, typ)
+		extensionsMtx.Unlock()
+	})
+}
+
+func TestRegisterExtension(t *testing.T) {
+	ext := Extension{Name: "test", MaxSize: 16, Unmarshal: unmarshalTestPayload}
+
+	assert.True(t, errors.Is(RegisterExtension(MessageTypeSign2, ext), ErrReservedMessageType))
+	assert.True(t, errors.Is(RegisterExtension(FirstExtensionType-1, ext), ErrReservedMessageType))
+	assert.Error(t, RegisterExtension(FirstExtensionType, Extension{Name: "test", Unmarshal: unmarshalTestPayload}))
+
+	registerTestExtension(t, FirstExtensionType, "test", true)
+	assert.True(t, errors.Is(RegisterExtension(FirstExtensionType, Extension{Name: "other", MaxSize: 16, Unmarshal: unmarshalTestPayload}), ErrExtensionRegistered))
+	assert.True(t, errors.Is(RegisterExtension(FirstExtensionType+1, ext), ErrExtensionRegistered))
+	assert.True(t, errors.Is(RegisterExtension(FirstExtensionType+1, Extension{Name: "keygen1", MaxSize: 16, Unmarshal: unmarshalTestPayload}), ErrExtensionRegistered))
+
+	assert.True(t, FirstExtensionType.IsValid())
+	assert.True(t, FirstExtensionType.IsExtension())
+	assert.True(t, FirstExtensionType.IsBroadcast())
+	assert.Equal(t, "test", FirstExtensionType.String())
+	assert.False(t, (FirstExtensionType + 1).IsValid())
+	assert.False(t, MessageTypeSign1.IsExtension())
+}
+
+func TestExtension_Encodings(t *testing.T) {
+	registerTestExtension(t, 200, "ack", true)
+	registerTestExtension(t, 201, "note", false)
+
+	for _, msg := range []*Message{
+		NewExtension(200, 1, 0, testPayload("round 1")),
+		NewExtension(201, 2, 3, testPayload{0, 1, 2}),
+	} {
+		msg.SessionID = DeriveSessionID(party.IDSlice{1, 2, 3}, []byte("extension"))
+
+		data, err := msg.MarshalBinary()
+		require.NoError(t, err)
+		assert.Len(t, data, msg.Size())
+		var decoded Message
+		require.NoError(t, decoded.UnmarshalBinary(data))
+		assert.True(t, msg.Equal(&decoded))
+
+		data, err = json.Marshal(msg)
+		require.NoError(t, err)
+		decoded = Message{}
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.True(t, msg.Equal(&decoded))
+
+		data, err = msg.MarshalCBOR()
+		require.NoError(t, err)
+		decoded = Message{}
+		require.NoError(t, decoded.UnmarshalCBOR(data))
+		assert.True(t, msg.Equal(&decoded))
+	}
+}
+
+func TestExtension_Invalid(t *testing.T) {
+	registerTestExtension(t, 200, "ack", true)
+
+	// empty content is rejected by Unmarshal
+	data, err := NewExtension(200, 1, 0, testPayload{}).MarshalBinary()
+	require.NoError(t, err)
+	var msg Message
+	assert.True(t, errors.Is(msg.UnmarshalBinary(data), ErrInvalidMessage))
+
+	// content larger than MaxSize
+	data, err = NewExtension(200, 1, 0, make(testPayload, 17)).MarshalBinary()
+	require.NoError(t, err)
+	var limits Limits
+	assert.True(t, errors.Is(limits.UnmarshalBinary(data, &msg), ErrInvalidMessage))
+
+	// unregistered types are still rejected
+	data[0] = 201
+	assert.True(t, errors.Is(msg.UnmarshalBinary(data), ErrInvalidMessage))
+}
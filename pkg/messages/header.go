@@ -1,13 +1,60 @@
 package messages
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
 )
 
-const headerSize = 1 + 2*party.IDByteSize
+// The binary encoding of a Header starts with the magic string "FROST" and the version of the encoding,
+// so that messages encoded with a different version can be detected and rejected.
+//
+//	Header = "FROST" ∥ version ∥ type ∥ from ∥ to ∥ SessionID
+//
+// Version 2 encodes party IDs in 4 bytes. Messages of version 1, in which party IDs, including the recipients of
+// a Packed message and the degree of the commitments of a KeyGen1 message, are encoded in 2 bytes, are still decoded.
+//
+// The legacy encoding used by earlier versions of this library has no magic string, no version and no session ID,
+//
+//	LegacyHeader = type ∥ from ∥ to
+//
+// encodes party IDs in 2 bytes, and can only be decoded with UnmarshalBinaryLegacy.
+const (
+	headerMagic = "FROST"
+
+	// Version is the version of the binary encoding of messages produced by MarshalBinary.
+	Version uint8 = 2
+
+	// versionShortIDs is the version of the encoding in which party IDs are encoded in shortIDSize bytes.
+	versionShortIDs uint8 = 1
+	shortIDSize           = 2
+
+	legacyHeaderSize    = 1 + 2*shortIDSize
+	headerSizeShortIDs  = len(headerMagic) + 1 + legacyHeaderSize + SessionIDSize
+	headerSize          = len(headerMagic) + 1 + 1 + 2*party.IDByteSize + SessionIDSize
+	headerSizeNoVersion = len(headerMagic) + 1
+)
+
+// ErrUnsupportedVersion is wrapped by a *VersionError when decoding a message with an unknown version.
+var ErrUnsupportedVersion = errors.New("unsupported message version")
+
+// VersionError is returned when decoding a message whose encoding version is not supported.
+type VersionError struct {
+	// Version is the version found in the encoding.
+	Version uint8
+}
+
+// Error implements error
+func (e VersionError) Error() string {
+	return fmt.Sprintf("%s %d (supported: %d and %d)", ErrUnsupportedVersion.Error(), e.Version, versionShortIDs, Version)
+}
+
+// Unwrap returns ErrUnsupportedVersion.
+func (e VersionError) Unwrap() error {
+	return ErrUnsupportedVersion
+}
 
 type Header struct {
 	// Type is the message type
@@ -21,6 +68,9 @@ type Header struct {
 	// If the message is intended for broadcast, the ID returned is 0 (invalid),
 	// therefore, you should call IsBroadcast() first.
 	To party.ID
+
+	// SessionID identifies the protocol execution this message belongs to.
+	SessionID SessionID
 }
 
 func (h *Header) MarshalBinary() (data []byte, err error) {
@@ -29,64 +79,180 @@ func (h *Header) MarshalBinary() (data []byte, err error) {
 }
 
 func (h *Header) UnmarshalBinary(data []byte) error {
-	if l := len(data); l < headerSize {
-		return fmt.Errorf("Header.UnmarshalBinary: data should be at least %d bytes (got %d)", headerSize, l)
-	}
+	_, err := h.unmarshal(data)
+	return err
+}
 
-	msgType := MessageType(data[0])
-	var (
-		from, to party.ID
-		err      error
-	)
-	if from, err = party.FromBytes(data[1:]); err != nil {
-		return fmt.Errorf("Header.UnmarshalBinary: from: %w", err)
+// unmarshal decodes the header at the start of data, and returns the content of the message which follows it.
+// The content of a message of version 1 is converted to the current encoding.
+func (h *Header) unmarshal(data []byte) ([]byte, error) {
+	// The version is checked first, since the size of the header depends on it.
+	if l := len(data); l < headerSizeNoVersion {
+		return nil, fmt.Errorf("Header.UnmarshalBinary: data should be at least %d bytes (got %d)", headerSize, l)
 	}
-	if to, err = party.FromBytes(data[1+party.IDByteSize:]); err != nil {
-		return fmt.Errorf("Header.UnmarshalBinary: from: %w", err)
+	if string(data[:len(headerMagic)]) != headerMagic {
+		return nil, fmt.Errorf("Header.UnmarshalBinary: missing magic string: %w", ErrInvalidMessage)
 	}
-
-	switch msgType {
-	case MessageTypeKeyGen1, MessageTypeSign1, MessageTypeSign2:
-		if to != 0 {
-			return errors.New("Header.UnmarshalBinary: .To field must be 0 to indicate broadcast")
+	switch version := data[len(headerMagic)]; version {
+	case Version:
+		if err := h.unmarshalFields(data[headerSizeNoVersion:], party.IDByteSize, true); err != nil {
+			return nil, err
 		}
-	case MessageTypeKeyGen2:
-		if to == 0 {
-			return errors.New("Header.UnmarshalBinary: MessageTypeKeyGen2 requires a sender (.To field)")
+		return data[headerSize:], nil
+	case versionShortIDs:
+		if err := h.unmarshalFields(data[headerSizeNoVersion:], shortIDSize, true); err != nil {
+			return nil, err
 		}
+		return widenContent(h.Type, data[headerSizeShortIDs:])
+	default:
+		return nil, fmt.Errorf("Header.UnmarshalBinary: %w", &VersionError{Version: version})
+	}
+}
+
+// unmarshalLegacy decodes a header without magic string, version and session ID.
+// The session ID of h is set to the zero SessionID.
+// Only the types of the keygen and sign protocols existed in the legacy encoding.
+func (h *Header) unmarshalLegacy(data []byte) error {
+	if err := h.unmarshalFields(data, shortIDSize, false); err != nil {
+		return err
+	}
+	switch h.Type {
+	case MessageTypeKeyGen1, MessageTypeKeyGen2, MessageTypeSign1, MessageTypeSign2:
+		return nil
 	default:
-		return errors.New("Header.UnmarshalBinary: invalid message type")
+		return &FieldError{Field: "Header.Type", Err: fmt.Errorf("%s does not exist in the legacy encoding", h.Type)}
+	}
+}
+
+// unmarshalFields decodes the fields of a header which follow the version, where party IDs are idSize bytes long.
+// The session ID is only decoded if withSessionID is true, and is zero otherwise.
+func (h *Header) unmarshalFields(data []byte, idSize int, withSessionID bool) error {
+	size := 1 + 2*idSize
+	if withSessionID {
+		size += SessionIDSize
+	}
+	if l := len(data); l < size {
+		return fmt.Errorf("Header.UnmarshalBinary: data should be at least %d bytes (got %d)", size, l)
+	}
+
+	msgType := MessageType(data[0])
+	from, to := readID(data[1:], idSize), readID(data[1+idSize:], idSize)
+	offsetSessionID := 1 + 2*idSize
+
+	switch {
+	case !msgType.IsValid():
+		return &FieldError{Field: "Header.Type", Err: fmt.Errorf("unknown message type %d", uint8(msgType))}
+	case msgType.IsBroadcast() && to != 0:
+		return &FieldError{Field: "Header.To", Err: errors.New("must be 0 to indicate broadcast")}
+	case !msgType.IsBroadcast() && to == 0:
+		return &FieldError{Field: "Header.To", Err: fmt.Errorf("%s requires a recipient", msgType)}
 	}
 	if from == 0 {
-		return errors.New("Header.UnmarshalBinary: message must include a non 0 From value")
+		return &FieldError{Field: "Header.From", Err: errors.New("must be non 0")}
 	}
 
 	h.Type = msgType
 	h.From = from
 	h.To = to
+	h.SessionID = SessionID{}
+	if withSessionID {
+		copy(h.SessionID[:], data[offsetSessionID:offsetSessionID+SessionIDSize])
+	}
 	return nil
 }
 
-func (h *Header) BytesAppend(existing []byte) (data []byte, err error) {
-	switch h.Type {
-	case MessageTypeKeyGen1, MessageTypeSign1, MessageTypeSign2:
-		if h.To != 0 {
-			return nil, errors.New("Header.BytesAppend: .To field must be 0 to indicate broadcast")
+// readID decodes a big-endian party ID of idSize bytes, which is either shortIDSize or party.IDByteSize.
+// data must be at least idSize bytes long.
+func readID(data []byte, idSize int) party.ID {
+	if idSize == shortIDSize {
+		return party.ID(binary.BigEndian.Uint16(data))
+	}
+	return party.ID(binary.BigEndian.Uint32(data))
+}
+
+// appendID appends the encoding of id in party.IDByteSize bytes to dst, without allocating as party.ID.Bytes does.
+func appendID(dst []byte, id party.ID) []byte {
+	return append(dst, byte(id>>24), byte(id>>16), byte(id>>8), byte(id))
+}
+
+// widenContent converts the content of a message of type t, in which party IDs are encoded in shortIDSize bytes,
+// to the current encoding. Only the contents of KeyGen1 and Packed messages contain party IDs or sizes,
+// and the other contents are returned unchanged. KeyGenBlame, KeyGenConfirm, KeyGenComplaint, Enroll, Repair1,
+// Repair2, KeyGenBatch1 and KeyGenBatch2 messages are rejected, since they are more recent.
+// A KeyGen1 content which is too short is returned unchanged, so that it is rejected when decoding it.
+func widenContent(t MessageType, data []byte) ([]byte, error) {
+	const widening = party.IDByteSize - shortIDSize
+	switch t {
+	case MessageTypeKeyGen1:
+		// proof ∥ degree ∥ commitments ∥ encryption key
+		if len(data) < sizeKeygen1Proof+shortIDSize {
+			return data, nil
 		}
-	case MessageTypeKeyGen2:
-		if h.To == 0 {
-			return nil, errors.New("Header.BytesAppend: MessageTypeKeyGen2 requires a sender (.To field)")
+		out := make([]byte, 0, len(data)+widening)
+		out = append(out, data[:sizeKeygen1Proof]...)
+		out = append(out, make([]byte, widening)...)
+		return append(out, data[sizeKeygen1Proof:]...), nil
+	case MessageTypePacked:
+		// type ∥ n ∥ n × (to ∥ length ∥ payload)
+		errShort := &FieldError{Field: "Packed.Payloads", Err: errors.New("data is too short")}
+		if len(data) < sizePackedHeader {
+			return nil, errShort
 		}
-	default:
-		return nil, errors.New("Header.BytesAppend: invalid message type")
+		n := int(binary.BigEndian.Uint16(data[1:]))
+		if n > len(data)/(shortIDSize+2) {
+			return nil, errShort
+		}
+		out := make([]byte, 0, len(data)+n*widening)
+		out = append(out, data[:sizePackedHeader]...)
+		rest := data[sizePackedHeader:]
+		for i := 0; i < n; i++ {
+			if len(rest) < shortIDSize+2 {
+				return nil, errShort
+			}
+			length := int(binary.BigEndian.Uint16(rest[shortIDSize:]))
+			if len(rest) < shortIDSize+2+length {
+				return nil, errShort
+			}
+			out = appendID(out, readID(rest, shortIDSize))
+			out = append(out, rest[shortIDSize:shortIDSize+2+length]...)
+			rest = rest[shortIDSize+2+length:]
+		}
+		return append(out, rest...), nil
+	case MessageTypeKeyGenBlame, MessageTypeKeyGenConfirm, MessageTypeKeyGenComplaint, MessageTypeEnroll,
+		MessageTypeRepair1, MessageTypeRepair2, MessageTypeKeyGenBatch1, MessageTypeKeyGenBatch2:
+		// Blame, confirmation and complaint rounds, and the enrollment, repair and batch keygen protocols,
+		// were added with the current encoding
+		return nil, &FieldError{Field: "Header.Type", Err: fmt.Errorf("%s did not exist in version 1", t)}
+	}
+	return data, nil
+}
+
+// AppendBinary appends the encoding of h to dst and returns the extended slice.
+// It does not allocate if dst has a capacity of at least Size() additional bytes.
+func (h *Header) AppendBinary(dst []byte) ([]byte, error) {
+	switch {
+	case !h.Type.IsValid():
+		return nil, errors.New("Header.AppendBinary: invalid message type")
+	case h.Type.IsBroadcast() && h.To != 0:
+		return nil, errors.New("Header.AppendBinary: .To field must be 0 to indicate broadcast")
+	case !h.Type.IsBroadcast() && h.To == 0:
+		return nil, fmt.Errorf("Header.AppendBinary: %s requires a recipient (.To field)", h.Type)
 	}
 	if h.From == 0 {
-		return nil, errors.New("Header.BytesAppend: message must include a non 0 From value")
+		return nil, errors.New("Header.AppendBinary: message must include a non 0 From value")
 	}
-	existing = append(existing, byte(h.Type))
-	existing = append(existing, h.From.Bytes()...)
-	existing = append(existing, h.To.Bytes()...)
-	return existing, nil
+	dst = append(dst, headerMagic...)
+	dst = append(dst, Version)
+	dst = append(dst, byte(h.Type))
+	dst = appendID(dst, h.From)
+	dst = appendID(dst, h.To)
+	dst = append(dst, h.SessionID[:]...)
+	return dst, nil
+}
+
+// BytesAppend is the same as AppendBinary.
+func (h *Header) BytesAppend(existing []byte) (data []byte, err error) {
+	return h.AppendBinary(existing)
 }
 
 func (h *Header) Size() int {
@@ -103,7 +269,8 @@ func (h *Header) Equal(other interface{}) bool {
 	return false
 }
 
-// IsBroadcast returns true if the message is intended to be broadcast
+// IsBroadcast returns true if the message is intended to be broadcast.
+// For a valid message, this is the same as h.Type.IsBroadcast().
 func (h *Header) IsBroadcast() bool {
 	return h.To == 0
 }
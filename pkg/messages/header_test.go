@@ -1,9 +1,15 @@
 package messages
 
 import (
+	"encoding/hex"
+	"errors"
 	"testing"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/zk"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 )
 
 func TestHeader_UnmarshalBinary(t *testing.T) {
@@ -120,7 +126,10 @@ func TestHeader_UnmarshalBinary(t *testing.T) {
 				To:   tt.fields.To,
 			}
 			h2 := &Header{}
-			err := h2.UnmarshalBinary(tt.args.data)
+			// The party IDs are 2 bytes long in version 1
+			data := append([]byte("FROST\x01"), tt.args.data...)
+			data = append(data, make([]byte, SessionIDSize)...)
+			err := h2.UnmarshalBinary(data)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("UnmarshalBinary() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -130,3 +139,309 @@ func TestHeader_UnmarshalBinary(t *testing.T) {
 		})
 	}
 }
+
+func TestHeader_SessionID(t *testing.T) {
+	sessionID := DeriveSessionID(party.IDSlice{1, 2, 3}, []byte("nonce"))
+	h := &Header{
+		Type:      MessageTypeKeyGen2,
+		From:      2,
+		To:        1,
+		SessionID: sessionID,
+	}
+	data, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != h.Size() {
+		t.Errorf("MarshalBinary() has length %d, want %d", len(data), h.Size())
+	}
+
+	h2 := &Header{}
+	if err = h2.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !h.Equal(h2) {
+		t.Errorf("UnmarshalBinary() got = %v, want %v", h2, h)
+	}
+
+	if err = h2.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Error("UnmarshalBinary() should fail on truncated data")
+	}
+
+	if sessionID == DeriveSessionID(party.IDSlice{1, 2, 3}, []byte("other nonce")) {
+		t.Error("DeriveSessionID() should depend on the nonce")
+	}
+	if sessionID == DeriveSessionID(party.IDSlice{1, 2, 4}, []byte("nonce")) {
+		t.Error("DeriveSessionID() should depend on the parties")
+	}
+}
+
+func TestHeader_Version(t *testing.T) {
+	h := &Header{Type: MessageTypeSign1, From: 2}
+	data, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data[:6]) != "FROST\x02" || len(data) != headerSize {
+		t.Errorf("MarshalBinary() = %x, should start with the magic string and version", data)
+	}
+
+	data[5] = 3
+	var versionErr *VersionError
+	err = (&Header{}).UnmarshalBinary(data)
+	if !errors.Is(err, ErrUnsupportedVersion) || !errors.As(err, &versionErr) {
+		t.Fatalf("UnmarshalBinary() error = %v, want a *VersionError", err)
+	}
+	if versionErr.Version != 3 {
+		t.Errorf("VersionError.Version = %d, want 3", versionErr.Version)
+	}
+
+	// The legacy encoding is rejected
+	if err = (&Header{}).UnmarshalBinary(data[6:]); err == nil {
+		t.Error("UnmarshalBinary() should reject the legacy encoding")
+	}
+}
+
+func TestHeader_Truncated(t *testing.T) {
+	h := &Header{Type: MessageTypeKeyGen2, From: 2, To: 1}
+	data, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for l := 0; l < len(data); l++ {
+		if err = (&Header{}).UnmarshalBinary(data[:l]); err == nil {
+			t.Errorf("UnmarshalBinary() should fail on a header truncated to %d bytes", l)
+		}
+	}
+	legacy := []byte{byte(MessageTypeKeyGen2), 0, 2, 0, 1}
+	if err = (&Header{}).unmarshalLegacy(legacy); err != nil {
+		t.Fatal(err)
+	}
+	for l := 0; l < len(legacy); l++ {
+		if err = (&Header{}).unmarshalLegacy(legacy[:l]); err == nil {
+			t.Errorf("unmarshalLegacy() should fail on a header truncated to %d bytes", l)
+		}
+	}
+}
+
+// legacyMessages are messages encoded by the version of this library before the magic string, the version and the
+// session ID were added to the header, in which party IDs are 2 bytes long.
+var legacyMessages = map[string]string{
+	// type = 1, from = 1, to = 0, proof = { S = 7, R = 11 }, degree = 1, commitments = { 2•B, 3•B }
+	"keygen1": "0100010000" +
+		"0700000000000000000000000000000000000000000000000000000000000000" +
+		"0b00000000000000000000000000000000000000000000000000000000000000" +
+		"0001" +
+		"6a493210f7499cd17fecb510ae0cea23a110e8d5b901f8acadd3095c73a3b919" +
+		"94741f5d5d52755ece4f23f044ee27d5d1ea1e2bd196b462166b16152a9d0259",
+	// type = 2, from = 1, to = 2, share = 5
+	"keygen2": "0200010002" +
+		"0500000000000000000000000000000000000000000000000000000000000000",
+	// type = 3, from = 3, to = 0, D = B, E = 2•B
+	"sign1": "0300030000" +
+		"e2f2ae0a6abc4e71a884a961c500515f58e30b6aa582dd8db6a65945e08d2d76" +
+		"6a493210f7499cd17fecb510ae0cea23a110e8d5b901f8acadd3095c73a3b919",
+	// type = 4, from = 3, to = 0, z = 5
+	"sign2": "0400030000" +
+		"0500000000000000000000000000000000000000000000000000000000000000",
+}
+
+func TestMessage_UnmarshalBinaryLegacy(t *testing.T) {
+	point := func(n uint32) *ristretto.Element {
+		return new(ristretto.Element).ScalarBaseMult(scalar.NewScalarUInt32(n))
+	}
+	proof := &zk.Schnorr{}
+	proof.S.Set(scalar.NewScalarUInt32(7))
+	proof.R.Set(scalar.NewScalarUInt32(11))
+	commitments := &polynomial.Exponent{}
+	data := appendID(nil, 1)
+	data = append(data, point(2).Bytes()...)
+	data = append(data, point(3).Bytes()...)
+	if err := commitments.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]*Message{
+		"keygen1": NewKeyGen1(1, proof, commitments),
+		"keygen2": NewKeyGen2(1, 2, scalar.NewScalarUInt32(5)),
+		"sign1":   NewSign1(3, point(1), point(2)),
+		"sign2":   NewSign2(3, scalar.NewScalarUInt32(5)),
+	}
+
+	for name, encoded := range legacyMessages {
+		legacy, err := hex.DecodeString(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var msg Message
+		if err = msg.UnmarshalBinary(legacy); err == nil {
+			t.Errorf("%s: UnmarshalBinary() should reject the legacy encoding", name)
+		}
+		if err = msg.UnmarshalBinaryLegacy(legacy); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !want[name].Equal(&msg) {
+			t.Errorf("%s: UnmarshalBinaryLegacy() returned a different message", name)
+		}
+
+		current, err := want[name].MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, data := range [][]byte{current, legacy} {
+			var msg2 Message
+			if err = CompatBinaryCodec.Unmarshal(data, &msg2); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if !want[name].Equal(&msg2) {
+				t.Errorf("%s: CompatBinaryCodec returned a different message", name)
+			}
+		}
+
+		for l := 0; l < len(legacy); l++ {
+			if err = (&Message{}).UnmarshalBinaryLegacy(legacy[:l]); err == nil {
+				t.Errorf("%s: UnmarshalBinaryLegacy() should fail on a message truncated to %d bytes", name, l)
+			}
+		}
+	}
+
+	// Types which did not exist in the legacy encoding are rejected
+	echo := append([]byte{byte(MessageTypeKeyGenEcho), 0, 1, 0, 0}, make([]byte, 32)...)
+	if err := (&Message{}).UnmarshalBinaryLegacy(echo); err == nil {
+		t.Error("UnmarshalBinaryLegacy() should reject a KeyGenEcho message")
+	}
+}
+
+func TestMessageType_IsBroadcast(t *testing.T) {
+	broadcast := map[MessageType]bool{
+		MessageTypeKeyGen1: true,
+		MessageTypeKeyGen2: false,
+		MessageTypeSign1:   true,
+		MessageTypeSign2:   true,
+
+		MessageTypeKeyGenEcho:      true,
+		MessageTypePacked:          true,
+		MessageTypeKeyGenBlame:     true,
+		MessageTypeKeyGenConfirm:   true,
+		MessageTypeKeyGenComplaint: true,
+		MessageTypeEnroll:          false,
+		MessageTypeRepair1:         true,
+		MessageTypeRepair2:         false,
+		MessageTypeKeyGenBatch1:    true,
+		MessageTypeKeyGenBatch2:    false,
+	}
+	for msgType, want := range broadcast {
+		if !msgType.IsValid() {
+			t.Errorf("%s should be valid", msgType)
+		}
+		if msgType.IsBroadcast() != want {
+			t.Errorf("%s.IsBroadcast() = %t, want %t", msgType, msgType.IsBroadcast(), want)
+		}
+	}
+	for _, msgType := range []MessageType{MessageTypeNone, 15, 255} {
+		if msgType.IsValid() || msgType.IsBroadcast() {
+			t.Errorf("%s should be neither valid nor broadcast", msgType)
+		}
+	}
+	if s := MessageType(42).String(); s != "MessageType(42)" {
+		t.Errorf("String() = %s, want MessageType(42)", s)
+	}
+}
+
+// shortIDs returns the encoding of version 1 of the message whose current encoding is data,
+// in which party IDs are 2 bytes long.
+func shortIDs(t *testing.T, data []byte) []byte {
+	var msg Message
+	if err := msg.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	short := func(id party.ID) []byte {
+		if id > 0xffff {
+			t.Fatalf("party %d does not fit in 2 bytes", id)
+		}
+		return []byte{byte(id >> 8), byte(id)}
+	}
+	out := append([]byte(headerMagic), versionShortIDs, byte(msg.Type))
+	out = append(out, short(msg.From)...)
+	out = append(out, short(msg.To)...)
+	out = append(out, msg.SessionID[:]...)
+	content := data[headerSize:]
+	switch msg.Type {
+	case MessageTypeKeyGen1:
+		out = append(out, content[:sizeKeygen1Proof]...)
+		out = append(out, content[sizeKeygen1Proof+party.IDByteSize-shortIDSize:]...)
+	case MessageTypePacked:
+		out = append(out, content[:sizePackedHeader]...)
+		for i, to := range msg.Packed.To {
+			payload := msg.Packed.Payloads[i]
+			out = append(out, short(to)...)
+			out = append(out, byte(len(payload)>>8), byte(len(payload)))
+			out = append(out, payload...)
+		}
+	default:
+		out = append(out, content...)
+	}
+	return out
+}
+
+func TestMessage_UnmarshalBinary_ShortIDs(t *testing.T) {
+	for _, msg := range testMessages() {
+		t.Run(msg.Type.String(), func(t *testing.T) {
+			data, err := msg.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			short := shortIDs(t, data)
+			if len(short) >= len(data) {
+				t.Errorf("version 1 encoding is not shorter")
+			}
+
+			var msg2 Message
+			if msg.Type == MessageTypeKeyGenBlame || msg.Type == MessageTypeKeyGenConfirm || msg.Type == MessageTypeKeyGenComplaint ||
+				msg.Type == MessageTypeEnroll || msg.Type == MessageTypeRepair1 || msg.Type == MessageTypeRepair2 ||
+				msg.Type == MessageTypeKeyGenBatch1 || msg.Type == MessageTypeKeyGenBatch2 {
+				// Blame, confirmation, complaint, enrollment, repair and batch messages were introduced after version 1
+				var fieldErr *FieldError
+				if err = msg2.UnmarshalBinary(short); !errors.As(err, &fieldErr) || fieldErr.Field != "Header.Type" {
+					t.Errorf("expected a FieldError for Header.Type, got %v", err)
+				}
+				return
+			}
+			if err = msg2.UnmarshalBinary(short); err != nil {
+				t.Fatal(err)
+			}
+			if !msg.Equal(&msg2) {
+				t.Error("UnmarshalBinary() returned a different message for version 1")
+			}
+			var msg3 Message
+			if err = testLimits.UnmarshalBinary(short, &msg3); err != nil {
+				t.Fatal(err)
+			}
+			if !msg.Equal(&msg3) {
+				t.Error("Limits.UnmarshalBinary() returned a different message for version 1")
+			}
+
+			// Truncated encodings are rejected
+			for l := 0; l < len(short); l++ {
+				if err = msg2.UnmarshalBinary(short[:l]); err == nil {
+					t.Fatalf("UnmarshalBinary() should fail on version 1 data truncated to %d bytes", l)
+				}
+			}
+		})
+	}
+}
+
+func TestHeader_MaxID(t *testing.T) {
+	h := &Header{Type: MessageTypeKeyGen2, From: party.MaxID, To: party.MaxID - 1}
+	data, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var h2 Header
+	if err = h2.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !h.Equal(&h2) {
+		t.Errorf("UnmarshalBinary() got = %v, want %v", h2, h)
+	}
+}
@@ -0,0 +1,206 @@
+package messages
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrInboxFull is returned by Inbox.Add when the messages of a session exceed the InboxLimits.
+var ErrInboxFull = errors.New("inbox is full for this session")
+
+// InboxLimits bound the memory used by an Inbox.
+// A zero value selects the default given in the description of each field.
+type InboxLimits struct {
+	// MaxSessions is the number of sessions for which messages are held (default 64).
+	// When a message for a new session is added while the limit is reached,
+	// the session whose first message arrived the earliest is evicted.
+	MaxSessions int
+
+	// MaxMessages is the number of messages held for a single session (default 1024).
+	MaxMessages int
+
+	// MaxBytes is the total size of the messages held for a single session (default 1 MiB).
+	// Messages which do not fit are rejected with ErrInboxFull.
+	MaxBytes int
+
+	// MaxAge is the time after which the messages of a session are discarded, counted from its first message.
+	// If it is 0, sessions are only evicted when MaxSessions is reached.
+	MaxAge time.Duration
+}
+
+const (
+	defaultInboxSessions = 64
+	defaultInboxMessages = 1024
+	defaultInboxBytes    = 1 << 20
+)
+
+// Handler consumes the messages of a protocol execution, and is implemented by *state.State.
+type Handler interface {
+	// SessionID returns the session ID of the execution.
+	SessionID() SessionID
+	// HandleMessage stores msg for the execution.
+	HandleMessage(msg *Message) error
+}
+
+// Inbox holds the messages received for protocol executions which have not been created yet,
+// for example when other parties start earlier than us.
+// Messages are given in the binary encoding, and are grouped by the session ID of their header.
+// Only the header is decoded by Add, and the messages are fully decoded and validated when they are drained.
+//
+// It is safe to use an Inbox concurrently.
+type Inbox struct {
+	limits InboxLimits
+
+	// now returns the current time, and is only replaced in tests
+	now func() time.Time
+
+	mtx      sync.Mutex
+	sessions map[SessionID]*inboxSession
+	// next is the sequence number of the next session, so that sessions created at the same time are ordered.
+	next uint64
+}
+
+type inboxSession struct {
+	seq     uint64
+	created time.Time
+	msgs    [][]byte
+	size    int
+}
+
+// NewInbox returns an empty Inbox whose memory is bounded by limits.
+func NewInbox(limits InboxLimits) *Inbox {
+	if limits.MaxSessions <= 0 {
+		limits.MaxSessions = defaultInboxSessions
+	}
+	if limits.MaxMessages <= 0 {
+		limits.MaxMessages = defaultInboxMessages
+	}
+	if limits.MaxBytes <= 0 {
+		limits.MaxBytes = defaultInboxBytes
+	}
+	return &Inbox{
+		limits:   limits,
+		now:      time.Now,
+		sessions: make(map[SessionID]*inboxSession),
+	}
+}
+
+// Add holds data until the messages of its session are drained.
+// It returns an error if the header cannot be decoded, or ErrInboxFull if the session has no room left.
+// data is copied, and may be reused by the caller.
+func (in *Inbox) Add(data []byte) (err error) {
+	defer recoverPanic(&err)
+
+	var h Header
+	if err = h.UnmarshalBinary(data); err != nil {
+		return fmt.Errorf("messages.Inbox: %w", err)
+	}
+
+	if len(data) > in.limits.MaxBytes {
+		return fmt.Errorf("messages.Inbox: message is %d bytes long: %w", len(data), ErrInboxFull)
+	}
+
+	in.mtx.Lock()
+	defer in.mtx.Unlock()
+
+	now := in.now()
+	in.expire(now)
+
+	session, ok := in.sessions[h.SessionID]
+	if !ok {
+		if len(in.sessions) >= in.limits.MaxSessions {
+			in.evictOldest()
+		}
+		session = &inboxSession{seq: in.next, created: now}
+		in.next++
+		in.sessions[h.SessionID] = session
+	}
+	if len(session.msgs) >= in.limits.MaxMessages || session.size+len(data) > in.limits.MaxBytes {
+		return fmt.Errorf("messages.Inbox: session %s: %w", shortHex(h.SessionID[:]), ErrInboxFull)
+	}
+	session.msgs = append(session.msgs, append([]byte(nil), data...))
+	session.size += len(data)
+	return nil
+}
+
+// Drain removes the messages held for the session of h, and gives them to h.HandleMessage in the order they were added.
+// All messages are given to h, even if some are rejected, and the number of accepted messages is returned.
+// If messages could not be decoded or were rejected by h, the first such error is returned.
+//
+// Messages for the session which arrive after Drain should be given to h directly.
+func (in *Inbox) Drain(h Handler) (int, error) {
+	sessionID := h.SessionID()
+
+	in.mtx.Lock()
+	in.expire(in.now())
+	session, ok := in.sessions[sessionID]
+	delete(in.sessions, sessionID)
+	in.mtx.Unlock()
+
+	if !ok {
+		return 0, nil
+	}
+
+	// h is called without holding the lock, so that other sessions can be used in the meantime.
+	var (
+		accepted int
+		firstErr error
+	)
+	for _, data := range session.msgs {
+		var msg Message
+		err := msg.UnmarshalBinary(data)
+		if err == nil {
+			err = h.HandleMessage(&msg)
+		}
+		if err == nil {
+			accepted++
+		} else if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return accepted, fmt.Errorf("messages.Inbox: %d of %d messages rejected: %w", len(session.msgs)-accepted, len(session.msgs), firstErr)
+	}
+	return accepted, nil
+}
+
+// Len returns the number of messages held for sessionID.
+func (in *Inbox) Len(sessionID SessionID) int {
+	in.mtx.Lock()
+	defer in.mtx.Unlock()
+	in.expire(in.now())
+	if session, ok := in.sessions[sessionID]; ok {
+		return len(session.msgs)
+	}
+	return 0
+}
+
+// expire removes the sessions older than MaxAge.
+// It should be called with the lock held.
+func (in *Inbox) expire(now time.Time) {
+	if in.limits.MaxAge <= 0 {
+		return
+	}
+	for id, session := range in.sessions {
+		if now.Sub(session.created) >= in.limits.MaxAge {
+			delete(in.sessions, id)
+		}
+	}
+}
+
+// evictOldest removes the session whose first message arrived the earliest.
+// It should be called with the lock held.
+func (in *Inbox) evictOldest() {
+	var (
+		oldestID SessionID
+		oldest   *inboxSession
+	)
+	for id, session := range in.sessions {
+		if oldest == nil || session.seq < oldest.seq {
+			oldestID, oldest = id, session
+		}
+	}
+	delete(in.sessions, oldestID)
+}
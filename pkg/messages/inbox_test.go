@@ -0,0 +1,153 @@
+package messages
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+// recordingHandler records the messages of a session, and rejects the ones sent by reject.
+type recordingHandler struct {
+	sessionID SessionID
+	reject    party.ID
+	msgs      []*Message
+}
+
+func (h *recordingHandler) SessionID() SessionID {
+	return h.sessionID
+}
+
+func (h *recordingHandler) HandleMessage(msg *Message) error {
+	if msg.From == h.reject {
+		return errors.New("rejected")
+	}
+	h.msgs = append(h.msgs, msg)
+	return nil
+}
+
+// inboxMessage returns the encoding of an echo message from from in the session sessionID.
+func inboxMessage(t *testing.T, sessionID SessionID, from party.ID) []byte {
+	msg := NewKeyGenEcho(from, &[SizeEchoDigest]byte{byte(from)})
+	msg.SessionID = sessionID
+	data, err := msg.MarshalBinary()
+	require.NoError(t, err)
+	return data
+}
+
+func TestInbox_Drain(t *testing.T) {
+	inbox := NewInbox(InboxLimits{})
+	session1, session2 := SessionID{1}, SessionID{2}
+
+	for _, from := range []party.ID{3, 1, 2} {
+		data := inboxMessage(t, session1, from)
+		require.NoError(t, inbox.Add(data))
+		// The data is copied
+		data[len(data)-1] = 0xff
+	}
+	require.NoError(t, inbox.Add(inboxMessage(t, session2, 1)))
+	assert.Equal(t, 3, inbox.Len(session1))
+
+	h := &recordingHandler{sessionID: session1}
+	n, err := inbox.Drain(h)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	// The messages are given in arrival order
+	require.Len(t, h.msgs, 3)
+	for i, from := range []party.ID{3, 1, 2} {
+		assert.Equal(t, from, h.msgs[i].From)
+		assert.Equal(t, byte(from), h.msgs[i].KeyGenEcho.Digest[0])
+	}
+
+	// The session is removed, and the other one is kept
+	assert.Equal(t, 0, inbox.Len(session1))
+	n, err = inbox.Drain(h)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 1, inbox.Len(session2))
+}
+
+func TestInbox_DrainRejected(t *testing.T) {
+	inbox := NewInbox(InboxLimits{})
+	sessionID := SessionID{1}
+	for _, from := range []party.ID{1, 2, 3} {
+		require.NoError(t, inbox.Add(inboxMessage(t, sessionID, from)))
+	}
+
+	// All messages are given to the handler, even after one was rejected
+	h := &recordingHandler{sessionID: sessionID, reject: 2}
+	n, err := inbox.Drain(h)
+	assert.Error(t, err)
+	assert.Equal(t, 2, n)
+	assert.Len(t, h.msgs, 2)
+}
+
+func TestInbox_AddInvalid(t *testing.T) {
+	inbox := NewInbox(InboxLimits{})
+	data := inboxMessage(t, SessionID{1}, 1)
+
+	assert.Error(t, inbox.Add(nil))
+	assert.Error(t, inbox.Add(data[:headerSize-1]))
+	// unknown type
+	data[len(headerMagic)+1] = 42
+	assert.True(t, errors.Is(inbox.Add(data), ErrInvalidMessage))
+	assert.Equal(t, 0, inbox.Len(SessionID{1}))
+}
+
+func TestInbox_Limits(t *testing.T) {
+	size := len(inboxMessage(t, SessionID{}, 1))
+
+	inbox := NewInbox(InboxLimits{MaxMessages: 2})
+	require.NoError(t, inbox.Add(inboxMessage(t, SessionID{1}, 1)))
+	require.NoError(t, inbox.Add(inboxMessage(t, SessionID{1}, 2)))
+	assert.True(t, errors.Is(inbox.Add(inboxMessage(t, SessionID{1}, 3)), ErrInboxFull))
+	// Other sessions are not affected
+	require.NoError(t, inbox.Add(inboxMessage(t, SessionID{2}, 3)))
+
+	inbox = NewInbox(InboxLimits{MaxBytes: 2*size + 1})
+	require.NoError(t, inbox.Add(inboxMessage(t, SessionID{1}, 1)))
+	require.NoError(t, inbox.Add(inboxMessage(t, SessionID{1}, 2)))
+	assert.True(t, errors.Is(inbox.Add(inboxMessage(t, SessionID{1}, 3)), ErrInboxFull))
+	assert.Equal(t, 2, inbox.Len(SessionID{1}))
+
+	inbox = NewInbox(InboxLimits{MaxBytes: size - 1})
+	assert.True(t, errors.Is(inbox.Add(inboxMessage(t, SessionID{1}, 1)), ErrInboxFull))
+	assert.Empty(t, inbox.sessions)
+}
+
+func TestInbox_Eviction(t *testing.T) {
+	inbox := NewInbox(InboxLimits{MaxSessions: 2})
+	require.NoError(t, inbox.Add(inboxMessage(t, SessionID{1}, 1)))
+	require.NoError(t, inbox.Add(inboxMessage(t, SessionID{2}, 1)))
+	require.NoError(t, inbox.Add(inboxMessage(t, SessionID{1}, 2)))
+
+	// The oldest session is evicted, even if it received a message recently
+	require.NoError(t, inbox.Add(inboxMessage(t, SessionID{3}, 1)))
+	assert.Equal(t, 0, inbox.Len(SessionID{1}))
+	assert.Equal(t, 1, inbox.Len(SessionID{2}))
+	assert.Equal(t, 1, inbox.Len(SessionID{3}))
+}
+
+func TestInbox_MaxAge(t *testing.T) {
+	now := time.Now()
+	inbox := NewInbox(InboxLimits{MaxAge: time.Minute})
+	inbox.now = func() time.Time { return now }
+
+	require.NoError(t, inbox.Add(inboxMessage(t, SessionID{1}, 1)))
+	now = now.Add(30 * time.Second)
+	require.NoError(t, inbox.Add(inboxMessage(t, SessionID{2}, 1)))
+	require.NoError(t, inbox.Add(inboxMessage(t, SessionID{1}, 2)))
+	assert.Equal(t, 2, inbox.Len(SessionID{1}))
+
+	// The age is counted from the first message of the session
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, 0, inbox.Len(SessionID{1}))
+	assert.Equal(t, 1, inbox.Len(SessionID{2}))
+
+	n, err := inbox.Drain(&recordingHandler{sessionID: SessionID{1}})
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
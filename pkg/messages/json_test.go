@@ -0,0 +1,135 @@
+package messages
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/zk"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// testMessages returns one message of each type.
+func testMessages() []*Message {
+	secret := scalar.NewScalarRandom()
+	poly := polynomial.NewPolynomial(3, secret)
+	comm := polynomial.NewPolynomialExponent(poly)
+	proof := zk.NewSchnorrProof(1, comm.Constant(), make([]byte, 32), poly.Constant())
+
+	D := new(ristretto.Element).ScalarBaseMult(scalar.NewScalarRandom())
+	E := new(ristretto.Element).ScalarBaseMult(scalar.NewScalarRandom())
+
+	msgs := []*Message{
+		NewKeyGen1(1, proof, comm),
+		NewKeyGen2(1, 2, scalar.NewScalarRandom()),
+		NewSign1(3, D, E),
+		NewSign2(4, scalar.NewScalarRandom()),
+		NewKeyGenEcho(5, &[SizeEchoDigest]byte{1, 2, 3}),
+		NewKeyGenBlame(7, []Complaint{{Accused: 2}, {Accused: 5}}),
+		NewKeyGenConfirm(8, &[SizeConfirmDigest]byte{4, 5, 6}),
+		NewKeyGenComplaint(9, party.IDSlice{1, 4}),
+		NewEnroll(3, 6, scalar.NewScalarRandom()),
+		NewRepair1(4, []ristretto.Element{*D, *E}),
+		NewRepair2(5, 2, scalar.NewScalarRandom()),
+		NewKeyGenBatch1(2, []KeyGen1{{Proof: proof, Commitments: comm}, {Proof: proof, Commitments: comm}}),
+		NewKeyGenBatch2(2, 3, []ristretto.Scalar{*scalar.NewScalarRandom(), *scalar.NewScalarRandom()}),
+	}
+	msgs[len(msgs)-8].KeyGenBlame.Complaints[1].Share.Set(scalar.NewScalarRandom())
+	msgs[0].SessionID = DeriveSessionID(party.IDSlice{1, 2, 3, 4}, []byte("nonce"))
+
+	packed, err := NewPacked([]*Message{
+		NewKeyGen2(6, 3, scalar.NewScalarRandom()),
+		NewKeyGen2(6, 1, scalar.NewScalarRandom()),
+	})
+	if err != nil {
+		panic(err)
+	}
+	return append(msgs, packed)
+}
+
+func TestMessage_MarshalJSON(t *testing.T) {
+	for _, msg := range testMessages() {
+		data, err := json.Marshal(msg)
+		require.NoError(t, err)
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &fields))
+		assert.Equal(t, messageTypeNames[msg.Type], fields["type"])
+		assert.EqualValues(t, msg.From, fields["from"])
+		assert.EqualValues(t, msg.To, fields["to"])
+
+		var msg2 Message
+		require.NoError(t, json.Unmarshal(data, &msg2))
+		assert.True(t, msg.Equal(&msg2), "messages are not equal")
+
+		// Both encodings round-trip byte for byte
+		data2, err := json.Marshal(&msg2)
+		require.NoError(t, err)
+		assert.Equal(t, data, data2)
+
+		bin, err := msg.MarshalBinary()
+		require.NoError(t, err)
+		bin2, err := msg2.MarshalBinary()
+		require.NoError(t, err)
+		assert.Equal(t, bin, bin2)
+	}
+}
+
+func TestMessage_UnmarshalJSON_Invalid(t *testing.T) {
+	zeroSession := strings.Repeat("00", SessionIDSize)
+	nonCanonical := strings.Repeat("ff", 32)
+	valid := encodeHex(new(ristretto.Element).ScalarBaseMult(scalar.NewScalarRandom()).Bytes())
+
+	tests := map[string]string{
+		"unknown type":         `{"type":"sign3","from":1,"to":0,"session_id":"` + zeroSession + `","sign2":{"z":"` + strings.Repeat("00", 32) + `"}}`,
+		"missing content":      `{"type":"sign2","from":1,"to":0,"session_id":"` + zeroSession + `"}`,
+		"wrong content":        `{"type":"sign2","from":1,"to":0,"session_id":"` + zeroSession + `","keygen2":{"share":"` + strings.Repeat("00", 32) + `"}}`,
+		"two contents":         `{"type":"sign1","from":1,"to":0,"session_id":"` + zeroSession + `","sign1":{"d":"` + valid + `","e":"` + valid + `"},"sign2":{"z":"` + strings.Repeat("00", 32) + `"}}`,
+		"zero sender":          `{"type":"sign2","from":0,"to":0,"session_id":"` + zeroSession + `","sign2":{"z":"` + strings.Repeat("00", 32) + `"}}`,
+		"broadcast keygen2":    `{"type":"keygen2","from":1,"to":0,"session_id":"` + zeroSession + `","keygen2":{"share":"` + strings.Repeat("00", 32) + `"}}`,
+		"large sender":         `{"type":"sign2","from":4294967296,"to":0,"session_id":"` + zeroSession + `","sign2":{"z":"` + strings.Repeat("00", 32) + `"}}`,
+		"short session":        `{"type":"sign2","from":1,"to":0,"session_id":"00","sign2":{"z":"` + strings.Repeat("00", 32) + `"}}`,
+		"uppercase hex":        `{"type":"sign1","from":1,"to":0,"session_id":"` + zeroSession + `","sign1":{"d":"` + strings.ToUpper(valid) + `","e":"` + valid + `"}}`,
+		"short point":          `{"type":"sign1","from":1,"to":0,"session_id":"` + zeroSession + `","sign1":{"d":"` + valid[:62] + `","e":"` + valid + `"}}`,
+		"non canonical point":  `{"type":"sign1","from":1,"to":0,"session_id":"` + zeroSession + `","sign1":{"d":"` + nonCanonical + `","e":"` + valid + `"}}`,
+		"non canonical scalar": `{"type":"sign2","from":1,"to":0,"session_id":"` + zeroSession + `","sign2":{"z":"` + nonCanonical + `"}}`,
+		"no commitments":       `{"type":"keygen1","from":1,"to":0,"session_id":"` + zeroSession + `","keygen1":{"proof_s":"` + strings.Repeat("00", 32) + `","proof_r":"` + strings.Repeat("00", 32) + `","commitments":[]}}`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			var msg Message
+			assert.Error(t, json.Unmarshal([]byte(data), &msg))
+		})
+	}
+}
+
+func FuzzMessage_UnmarshalJSON(f *testing.F) {
+	for _, msg := range testMessages() {
+		data, err := json.Marshal(msg)
+		require.NoError(f, err)
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return
+		}
+		// Any accepted message must round-trip through both encodings
+		encoded, err := json.Marshal(&msg)
+		require.NoError(t, err)
+		var msg2 Message
+		require.NoError(t, json.Unmarshal(encoded, &msg2))
+		assert.True(t, msg.Equal(&msg2), "messages are not equal")
+
+		bin, err := msg.MarshalBinary()
+		require.NoError(t, err)
+		var msg3 Message
+		require.NoError(t, msg3.UnmarshalBinary(bin))
+		assert.True(t, msg.Equal(&msg3), "messages are not equal")
+	})
+}
@@ -1,16 +1,34 @@
 package messages
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
 	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
 	"github.com/taurusgroup/frost-ed25519/pkg/internal/zk"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 )
 
+const (
+	sizeKeygen1Proof         = 32 + 32
+	sizeKeygen1EncryptionKey = 32
+)
+
+// sizeKeygen1Commitments returns the size of the encoding of the commitments to a polynomial of the given degree.
+func sizeKeygen1Commitments(degree party.Size) int {
+	return party.IDByteSize + 32*(int(degree)+1)
+}
+
 type KeyGen1 struct {
 	Proof       *zk.Schnorr
 	Commitments *polynomial.Exponent
+
+	// EncryptionKey is the sender's ephemeral public key, to which the shares of the KeyGen2 messages are encrypted.
+	// It is only set when the keygen is run with encrypted shares, and is then appended to the binary encoding.
+	EncryptionKey *ristretto.Element
 }
 
 func NewKeyGen1(from party.ID, proof *zk.Schnorr, commitments *polynomial.Exponent) *Message {
@@ -26,46 +44,82 @@ func NewKeyGen1(from party.ID, proof *zk.Schnorr, commitments *polynomial.Expone
 	}
 }
 
-func (m *KeyGen1) BytesAppend(existing []byte) ([]byte, error) {
+// AppendBinary appends the encoding of m to dst and returns the extended slice.
+// It does not allocate if dst has a capacity of at least Size() additional bytes.
+func (m *KeyGen1) AppendBinary(dst []byte) ([]byte, error) {
 	var err error
-	existing, err = m.Proof.BytesAppend(existing)
+	dst, err = m.Proof.BytesAppend(dst)
 	if err != nil {
 		return nil, err
 	}
-	existing, err = m.Commitments.BytesAppend(existing)
+	dst, err = m.Commitments.BytesAppend(dst)
 	if err != nil {
 		return nil, err
 	}
-	return existing, nil
+	if m.EncryptionKey != nil {
+		dst = append(dst, m.EncryptionKey.Bytes()...)
+	}
+	return dst, nil
+}
+
+// BytesAppend is the same as AppendBinary.
+func (m *KeyGen1) BytesAppend(existing []byte) ([]byte, error) {
+	return m.AppendBinary(existing)
 }
 
 // MarshalBinary implements the encoding.BinaryMarshaler interface.
 func (m *KeyGen1) MarshalBinary() (data []byte, err error) {
-	buf := make([]byte, 0, m.Size())
-	return m.BytesAppend(buf)
+	return m.AppendBinary(make([]byte, 0, m.Size()))
 }
 
 // UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
 func (m *KeyGen1) UnmarshalBinary(data []byte) error {
-	if len(data) < 64 {
-		return fmt.Errorf("msg1: %w", ErrInvalidMessage)
+	if len(data) < sizeKeygen1Proof {
+		return &FieldError{Field: "KeyGen1.Proof", Err: errors.New("data is too short")}
+	}
+
+	// The number of commitments is checked against the size of the data before any allocation
+	data, commitmentsData := data[:sizeKeygen1Proof], data[sizeKeygen1Proof:]
+	degree, err := party.FromBytes(commitmentsData)
+	if err != nil {
+		return &FieldError{Field: "KeyGen1.Commitments", Err: err}
+	}
+	var keyData []byte
+	switch size := sizeKeygen1Commitments(degree); len(commitmentsData) {
+	case size:
+	case size + sizeKeygen1EncryptionKey:
+		commitmentsData, keyData = commitmentsData[:size], commitmentsData[size:]
+	default:
+		return &FieldError{Field: "KeyGen1.Commitments", Err: fmt.Errorf("expected %d bytes for %d commitments (got %d)",
+			size, int(degree)+1, len(commitmentsData))}
 	}
 
 	m.Proof = &zk.Schnorr{}
 	m.Commitments = &polynomial.Exponent{}
+	m.EncryptionKey = nil
+	if keyData != nil {
+		m.EncryptionKey = new(ristretto.Element)
+		if _, err = m.EncryptionKey.SetCanonicalBytes(keyData); err != nil {
+			return &FieldError{Field: "KeyGen1.EncryptionKey", Err: err}
+		}
+	}
 
-	if err := m.Proof.UnmarshalBinary(data[:64]); err != nil {
-		return err
+	if err = m.Proof.UnmarshalBinary(data); err != nil {
+		return &FieldError{Field: "KeyGen1.Proof", Err: err}
 	}
-	if err := m.Commitments.UnmarshalBinary(data[64:]); err != nil {
-		return err
+	if err = m.Commitments.UnmarshalBinary(commitmentsData); err != nil {
+		return &FieldError{Field: "KeyGen1.Commitments", Err: err}
 	}
 
 	return nil
 }
 
 func (m *KeyGen1) Size() int {
-	return m.Proof.Size() + m.Commitments.Size()
+	size := m.Proof.Size() + m.Commitments.Size()
+	if m.EncryptionKey != nil {
+		size += sizeKeygen1EncryptionKey
+	}
+	return size
 }
 
 func (m *KeyGen1) Equal(other interface{}) bool {
@@ -79,5 +133,83 @@ func (m *KeyGen1) Equal(other interface{}) bool {
 	if !otherMsg.Commitments.Equal(m.Commitments) {
 		return false
 	}
+	if (otherMsg.EncryptionKey == nil) != (m.EncryptionKey == nil) {
+		return false
+	}
+	if m.EncryptionKey != nil && otherMsg.EncryptionKey.Equal(m.EncryptionKey) != 1 {
+		return false
+	}
 	return true
 }
+
+type jsonKeyGen1 struct {
+	ProofS        string   `json:"proof_s"`
+	ProofR        string   `json:"proof_r"`
+	Commitments   []string `json:"commitments"`
+	EncryptionKey string   `json:"encryption_key,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// The commitments are encoded as the list of their coefficients, starting with the constant one.
+func (m *KeyGen1) MarshalJSON() ([]byte, error) {
+	proof, err := m.Proof.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	commitments, err := m.Commitments.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	// skip the degree
+	commitments = commitments[party.IDByteSize:]
+
+	out := jsonKeyGen1{
+		ProofS:      encodeHex(proof[:32]),
+		ProofR:      encodeHex(proof[32:]),
+		Commitments: make([]string, 0, len(commitments)/32),
+	}
+	for ; len(commitments) > 0; commitments = commitments[32:] {
+		out.Commitments = append(out.Commitments, encodeHex(commitments[:32]))
+	}
+	if m.EncryptionKey != nil {
+		out.EncryptionKey = encodeHex(m.EncryptionKey.Bytes())
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The proof and commitments are validated as in UnmarshalBinary.
+func (m *KeyGen1) UnmarshalJSON(data []byte) error {
+	var out jsonKeyGen1
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	if len(out.Commitments) == 0 || len(out.Commitments) > math.MaxUint16+1 {
+		return fmt.Errorf("msg1.Commitments: %w", ErrInvalidMessage)
+	}
+
+	buf := make([]byte, 0, 64+party.IDByteSize+32*len(out.Commitments)+sizeKeygen1EncryptionKey)
+	for _, s := range []string{out.ProofS, out.ProofR} {
+		b, err := decodeHex(s, 32)
+		if err != nil {
+			return fmt.Errorf("msg1.Proof: %w", err)
+		}
+		buf = append(buf, b...)
+	}
+	buf = append(buf, party.Size(len(out.Commitments)-1).Bytes()...)
+	for _, s := range out.Commitments {
+		b, err := decodeHex(s, 32)
+		if err != nil {
+			return fmt.Errorf("msg1.Commitments: %w", err)
+		}
+		buf = append(buf, b...)
+	}
+	if out.EncryptionKey != "" {
+		b, err := decodeHex(out.EncryptionKey, sizeKeygen1EncryptionKey)
+		if err != nil {
+			return fmt.Errorf("msg1.EncryptionKey: %w", err)
+		}
+		buf = append(buf, b...)
+	}
+	return m.UnmarshalBinary(buf)
+}
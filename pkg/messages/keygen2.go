@@ -1,17 +1,27 @@
 package messages
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
 	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 )
 
 const sizeKeygen2 = 32
 
+// SizeSealedShare is the size of KeyGen2.SealedShare: the encryption of a share with an AEAD whose tag is 16 bytes long.
+const SizeSealedShare = sizeKeygen2 + 16
+
 type KeyGen2 struct {
 	// Share is a Shamir additive share for the destination party
 	Share ristretto.Scalar
+
+	// SealedShare is the encryption of Share to the destination party, when the keygen is run with encrypted shares.
+	// When it is set, Share is not sent and is left to zero.
+	SealedShare []byte
 }
 
 func NewKeyGen2(from, to party.ID, share *ristretto.Scalar) *Message {
@@ -25,27 +35,64 @@ func NewKeyGen2(from, to party.ID, share *ristretto.Scalar) *Message {
 	}
 }
 
+// NewKeyGen2Sealed returns a KeyGen2 message containing the encryption of a share to the party to,
+// which must be SizeSealedShare bytes long.
+func NewKeyGen2Sealed(from, to party.ID, sealedShare []byte) *Message {
+	return &Message{
+		Header: Header{
+			Type: MessageTypeKeyGen2,
+			From: from,
+			To:   to,
+		},
+		KeyGen2: &KeyGen2{SealedShare: sealedShare},
+	}
+}
+
+// AppendBinary appends the encoding of m to dst and returns the extended slice.
+// It does not allocate if dst has a capacity of at least Size() additional bytes.
+func (m *KeyGen2) AppendBinary(dst []byte) ([]byte, error) {
+	if m.SealedShare != nil {
+		if len(m.SealedShare) != SizeSealedShare {
+			return nil, fmt.Errorf("KeyGen2.AppendBinary: sealed share must be %d bytes", SizeSealedShare)
+		}
+		return append(dst, m.SealedShare...), nil
+	}
+	return scalar.AppendBytes(dst, &m.Share), nil
+}
+
+// BytesAppend is the same as AppendBinary.
 func (m *KeyGen2) BytesAppend(existing []byte) ([]byte, error) {
-	return append(existing, m.Share.Bytes()...), nil
+	return m.AppendBinary(existing)
 }
 
 // MarshalBinary implements the encoding.BinaryMarshaler interface.
 func (m *KeyGen2) MarshalBinary() ([]byte, error) {
-	buf := make([]byte, 0, sizeKeygen2)
-	return m.BytesAppend(buf)
+	return m.AppendBinary(make([]byte, 0, m.Size()))
 }
 
 // UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// The content is a sealed share if it is SizeSealedShare bytes long, and a share otherwise.
 func (m *KeyGen2) UnmarshalBinary(data []byte) error {
+	if len(data) == SizeSealedShare {
+		m.Share = ristretto.Scalar{}
+		m.SealedShare = append([]byte(nil), data...)
+		return nil
+	}
 	if len(data) != sizeKeygen2 {
-		return fmt.Errorf("msg2: %w", ErrInvalidMessage)
+		return &FieldError{Field: "KeyGen2.Share", Err: fmt.Errorf("expected %d bytes (got %d)", sizeKeygen2, len(data))}
 	}
 
-	_, err := m.Share.SetCanonicalBytes(data)
-	return err
+	if _, err := m.Share.SetCanonicalBytes(data); err != nil {
+		return &FieldError{Field: "KeyGen2.Share", Err: err}
+	}
+	m.SealedShare = nil
+	return nil
 }
 
 func (m *KeyGen2) Size() int {
+	if m.SealedShare != nil {
+		return SizeSealedShare
+	}
 	return sizeKeygen2
 }
 
@@ -57,5 +104,50 @@ func (m *KeyGen2) Equal(other interface{}) bool {
 	if otherMsg.Share.Equal(&m.Share) != 1 {
 		return false
 	}
+	if (otherMsg.SealedShare == nil) != (m.SealedShare == nil) || !bytes.Equal(otherMsg.SealedShare, m.SealedShare) {
+		return false
+	}
 	return true
 }
+
+// jsonKeyGen2 contains exactly one of Share and SealedShare.
+type jsonKeyGen2 struct {
+	Share       string `json:"share,omitempty"`
+	SealedShare string `json:"sealed_share,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m *KeyGen2) MarshalJSON() ([]byte, error) {
+	if m.SealedShare != nil {
+		return json.Marshal(jsonKeyGen2{
+			SealedShare: encodeHex(m.SealedShare),
+		})
+	}
+	return json.Marshal(jsonKeyGen2{
+		Share: encodeHex(m.Share.Bytes()),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The scalar is validated as in UnmarshalBinary.
+func (m *KeyGen2) UnmarshalJSON(data []byte) error {
+	var out jsonKeyGen2
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	if out.SealedShare != "" {
+		if out.Share != "" {
+			return fmt.Errorf("msg2: both share and sealed_share are set: %w", ErrInvalidMessage)
+		}
+		sealed, err := decodeHex(out.SealedShare, SizeSealedShare)
+		if err != nil {
+			return fmt.Errorf("msg2.SealedShare: %w", err)
+		}
+		return m.UnmarshalBinary(sealed)
+	}
+	share, err := decodeHex(out.Share, sizeKeygen2)
+	if err != nil {
+		return fmt.Errorf("msg2.Share: %w", err)
+	}
+	return m.UnmarshalBinary(share)
+}
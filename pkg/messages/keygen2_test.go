@@ -21,3 +21,14 @@ func TestKeyGen2_MarshalBinary(t *testing.T) {
 	require.NoError(t, CheckFROSTMarshaler(msg, &msg2))
 	assert.Equal(t, *msg, msg2, "messages are not equal")
 }
+
+func TestKeyGen2_MarshalBinary_Sealed(t *testing.T) {
+	sealed := make([]byte, SizeSealedShare)
+	_, _ = rand.Read(sealed)
+
+	msg := NewKeyGen2Sealed(party.ID(rand.Uint32()), party.ID(rand.Uint32()), sealed)
+
+	var msg2 Message
+	require.NoError(t, CheckFROSTMarshaler(msg, &msg2))
+	assert.True(t, msg2.Equal(msg), "messages are not equal")
+}
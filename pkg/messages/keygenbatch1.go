@@ -0,0 +1,166 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+// sizeKeygenBatch1MinEntry is the size of the smallest entry of a KeyGenBatch1 message, with a single commitment.
+const sizeKeygenBatch1MinEntry = sizeKeygen1Proof + party.IDByteSize + 32
+
+// KeyGenBatch1 is broadcast in the first round of a batch keygen, which generates several independent keys
+// in a single protocol execution. It contains the content of a KeyGen1 message for each key of the batch,
+// without encryption key.
+//
+// Its binary encoding is:
+//
+//	k (4 bytes) ∥ k × (proof ∥ degree ∥ commitments)
+//
+// where each entry is encoded as the content of a KeyGen1 message.
+type KeyGenBatch1 struct {
+	// Keys contains the proof and commitments of the sender for each key, in the order of the batch.
+	Keys []KeyGen1
+}
+
+// NewKeyGenBatch1 returns a KeyGenBatch1 message containing the proofs and commitments of the sender for each key.
+func NewKeyGenBatch1(from party.ID, keys []KeyGen1) *Message {
+	return &Message{
+		Header: Header{
+			Type: MessageTypeKeyGenBatch1,
+			From: from,
+		},
+		KeyGenBatch1: &KeyGenBatch1{Keys: keys},
+	}
+}
+
+// validate checks that all entries are set, and do not contain an encryption key.
+func (m *KeyGenBatch1) validate() error {
+	for i := range m.Keys {
+		if m.Keys[i].Proof == nil || m.Keys[i].Commitments == nil {
+			return fmt.Errorf("key %d is not set", i)
+		}
+		if m.Keys[i].EncryptionKey != nil {
+			return errors.New("batch keys cannot have an encryption key")
+		}
+	}
+	return nil
+}
+
+// AppendBinary appends the encoding of m to dst and returns the extended slice.
+// It does not allocate if dst has a capacity of at least Size() additional bytes.
+func (m *KeyGenBatch1) AppendBinary(dst []byte) ([]byte, error) {
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("KeyGenBatch1.AppendBinary: %w", err)
+	}
+	dst = append(dst, party.Size(len(m.Keys)).Bytes()...)
+	for i := range m.Keys {
+		var err error
+		if dst, err = m.Keys[i].AppendBinary(dst); err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
+
+// BytesAppend is the same as AppendBinary.
+func (m *KeyGenBatch1) BytesAppend(existing []byte) ([]byte, error) {
+	return m.AppendBinary(existing)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (m *KeyGenBatch1) MarshalBinary() ([]byte, error) {
+	return m.AppendBinary(make([]byte, 0, m.Size()))
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (m *KeyGenBatch1) UnmarshalBinary(data []byte) error {
+	if len(data) < party.IDByteSize {
+		return &FieldError{Field: "KeyGenBatch1.Keys", Err: fmt.Errorf("expected at least %d bytes (got %d)", party.IDByteSize, len(data))}
+	}
+	k, _ := party.FromBytes(data)
+	data = data[party.IDByteSize:]
+	// The number of keys is checked against the size of the data before any allocation
+	if uint64(len(data)) < uint64(k)*sizeKeygenBatch1MinEntry {
+		return &FieldError{Field: "KeyGenBatch1.Keys", Err: fmt.Errorf("expected %d keys", k)}
+	}
+
+	keys := make([]KeyGen1, k)
+	for i := range keys {
+		if len(data) < sizeKeygen1Proof+party.IDByteSize {
+			return &FieldError{Field: "KeyGenBatch1.Keys", Err: fmt.Errorf("expected %d keys", k)}
+		}
+		degree, _ := party.FromBytes(data[sizeKeygen1Proof:])
+		size := sizeKeygen1Proof + sizeKeygen1Commitments(degree)
+		if len(data) < size {
+			return &FieldError{Field: "KeyGenBatch1.Keys", Err: fmt.Errorf("expected %d bytes for key %d (got %d)", size, i, len(data))}
+		}
+		if err := keys[i].UnmarshalBinary(data[:size]); err != nil {
+			return err
+		}
+		data = data[size:]
+	}
+	if len(data) != 0 {
+		return &FieldError{Field: "KeyGenBatch1.Keys", Err: errors.New("trailing data")}
+	}
+	m.Keys = keys
+	return nil
+}
+
+func (m *KeyGenBatch1) Size() int {
+	size := party.IDByteSize
+	for i := range m.Keys {
+		size += m.Keys[i].Size()
+	}
+	return size
+}
+
+func (m *KeyGenBatch1) Equal(other interface{}) bool {
+	otherMsg, ok := other.(*KeyGenBatch1)
+	if !ok || len(otherMsg.Keys) != len(m.Keys) {
+		return false
+	}
+	for i := range m.Keys {
+		if !m.Keys[i].Equal(&otherMsg.Keys[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+type jsonKeyGenBatch1 struct {
+	Keys []*KeyGen1 `json:"keys"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// Each key is encoded as the content of a KeyGen1 message.
+func (m *KeyGenBatch1) MarshalJSON() ([]byte, error) {
+	out := jsonKeyGenBatch1{Keys: make([]*KeyGen1, 0, len(m.Keys))}
+	for i := range m.Keys {
+		out.Keys = append(out.Keys, &m.Keys[i])
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The keys are validated as in UnmarshalBinary.
+func (m *KeyGenBatch1) UnmarshalJSON(data []byte) error {
+	var out jsonKeyGenBatch1
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	batch := KeyGenBatch1{Keys: make([]KeyGen1, len(out.Keys))}
+	for i, key := range out.Keys {
+		if key == nil {
+			return fmt.Errorf("batch1.Keys: %w", ErrInvalidMessage)
+		}
+		batch.Keys[i] = *key
+	}
+	if err := batch.validate(); err != nil {
+		return &FieldError{Field: "KeyGenBatch1.Keys", Err: err}
+	}
+	*m = batch
+	return nil
+}
@@ -0,0 +1,71 @@
+package messages
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/zk"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+func testBatchKeys(k int, deg party.Size) []KeyGen1 {
+	keys := make([]KeyGen1, k)
+	for i := range keys {
+		poly := polynomial.NewPolynomial(deg, scalar.NewScalarRandom())
+		keys[i].Commitments = polynomial.NewPolynomialExponent(poly)
+		keys[i].Proof = zk.NewSchnorrProof(1, keys[i].Commitments.Constant(), make([]byte, 32), poly.Constant())
+	}
+	return keys
+}
+
+func TestKeyGenBatch1_MarshalBinary(t *testing.T) {
+	for k := 0; k < 4; k++ {
+		msg := NewKeyGenBatch1(2, testBatchKeys(k, party.Size(k+1)))
+
+		var msg2 Message
+		require.NoError(t, CheckFROSTMarshaler(msg, &msg2))
+		assert.True(t, msg2.Equal(msg), "messages are not equal")
+		assert.True(t, msg2.IsBroadcast())
+	}
+}
+
+func TestKeyGenBatch1_MarshalBinary_Invalid(t *testing.T) {
+	keys := testBatchKeys(2, 1)
+	keys[1].EncryptionKey = new(ristretto.Element).ScalarBaseMult(scalar.NewScalarRandom())
+	_, err := NewKeyGenBatch1(2, keys).MarshalBinary()
+	assert.Error(t, err)
+}
+
+func TestKeyGenBatch1_UnmarshalBinary_Invalid(t *testing.T) {
+	valid, err := (&KeyGenBatch1{Keys: testBatchKeys(2, 1)}).MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, new(KeyGenBatch1).UnmarshalBinary(valid))
+
+	huge := append([]byte{}, valid...)
+	copy(huge, party.Size(party.MaxID).Bytes())
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"short", []byte{0, 0}},
+		{"count mismatch", append(party.Size(3).Bytes(), valid[party.IDByteSize:]...)},
+		{"huge count", huge},
+		{"truncated", valid[:len(valid)-1]},
+		{"trailing data", append(append([]byte{}, valid...), 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m KeyGenBatch1
+			err := m.UnmarshalBinary(tt.data)
+			var fieldErr *FieldError
+			require.True(t, errors.As(err, &fieldErr), err)
+			assert.Contains(t, fieldErr.Field, "KeyGen")
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package messages
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// KeyGenBatch2 is sent to each other party in the second round of a batch keygen.
+// It contains the share of the recipient for each key of the batch.
+//
+// Its binary encoding is:
+//
+//	k (4 bytes) ∥ k × share (32 bytes)
+type KeyGenBatch2 struct {
+	// Shares contains the share of the recipient for each key, in the order of the batch.
+	Shares []ristretto.Scalar
+}
+
+// NewKeyGenBatch2 returns a KeyGenBatch2 message containing the shares of the party to for each key.
+func NewKeyGenBatch2(from, to party.ID, shares []ristretto.Scalar) *Message {
+	return &Message{
+		Header: Header{
+			Type: MessageTypeKeyGenBatch2,
+			From: from,
+			To:   to,
+		},
+		KeyGenBatch2: &KeyGenBatch2{Shares: shares},
+	}
+}
+
+// AppendBinary appends the encoding of m to dst and returns the extended slice.
+// It does not allocate if dst has a capacity of at least Size() additional bytes.
+func (m *KeyGenBatch2) AppendBinary(dst []byte) ([]byte, error) {
+	dst = append(dst, party.Size(len(m.Shares)).Bytes()...)
+	for i := range m.Shares {
+		dst = scalar.AppendBytes(dst, &m.Shares[i])
+	}
+	return dst, nil
+}
+
+// BytesAppend is the same as AppendBinary.
+func (m *KeyGenBatch2) BytesAppend(existing []byte) ([]byte, error) {
+	return m.AppendBinary(existing)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (m *KeyGenBatch2) MarshalBinary() ([]byte, error) {
+	return m.AppendBinary(make([]byte, 0, m.Size()))
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (m *KeyGenBatch2) UnmarshalBinary(data []byte) error {
+	if len(data) < party.IDByteSize {
+		return &FieldError{Field: "KeyGenBatch2.Shares", Err: fmt.Errorf("expected at least %d bytes (got %d)", party.IDByteSize, len(data))}
+	}
+	k, _ := party.FromBytes(data)
+	data = data[party.IDByteSize:]
+	if uint64(len(data)) != uint64(k)*32 {
+		return &FieldError{Field: "KeyGenBatch2.Shares", Err: fmt.Errorf("expected %d shares", k)}
+	}
+
+	shares := make([]ristretto.Scalar, k)
+	for i := range shares {
+		if _, err := shares[i].SetCanonicalBytes(data[:32]); err != nil {
+			return &FieldError{Field: "KeyGenBatch2.Shares", Err: err}
+		}
+		data = data[32:]
+	}
+	m.Shares = shares
+	return nil
+}
+
+func (m *KeyGenBatch2) Size() int {
+	return party.IDByteSize + 32*len(m.Shares)
+}
+
+func (m *KeyGenBatch2) Equal(other interface{}) bool {
+	otherMsg, ok := other.(*KeyGenBatch2)
+	if !ok || len(otherMsg.Shares) != len(m.Shares) {
+		return false
+	}
+	for i := range m.Shares {
+		if m.Shares[i].Equal(&otherMsg.Shares[i]) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+type jsonKeyGenBatch2 struct {
+	Shares []string `json:"shares"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m *KeyGenBatch2) MarshalJSON() ([]byte, error) {
+	out := jsonKeyGenBatch2{Shares: make([]string, 0, len(m.Shares))}
+	for i := range m.Shares {
+		out.Shares = append(out.Shares, encodeHex(m.Shares[i].Bytes()))
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The scalars are validated as in UnmarshalBinary.
+func (m *KeyGenBatch2) UnmarshalJSON(data []byte) error {
+	var out jsonKeyGenBatch2
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	shares := make([]ristretto.Scalar, len(out.Shares))
+	for i, s := range out.Shares {
+		b, err := decodeHex(s, 32)
+		if err != nil {
+			return fmt.Errorf("batch2.Shares: %w", err)
+		}
+		if _, err = shares[i].SetCanonicalBytes(b); err != nil {
+			return &FieldError{Field: "KeyGenBatch2.Shares", Err: err}
+		}
+	}
+	m.Shares = shares
+	return nil
+}
@@ -0,0 +1,61 @@
+package messages
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+func TestKeyGenBatch2_MarshalBinary(t *testing.T) {
+	for k := 0; k < 4; k++ {
+		shares := make([]ristretto.Scalar, k)
+		for i := range shares {
+			scalar.SetScalarRandom(&shares[i])
+		}
+		msg := NewKeyGenBatch2(2, 3, shares)
+
+		var msg2 Message
+		require.NoError(t, CheckFROSTMarshaler(msg, &msg2))
+		assert.True(t, msg2.Equal(msg), "messages are not equal")
+		assert.False(t, msg2.IsBroadcast())
+	}
+}
+
+func TestKeyGenBatch2_UnmarshalBinary_Invalid(t *testing.T) {
+	share := scalar.NewScalarRandom().Bytes()
+	invalid := make([]byte, 32)
+	for i := range invalid {
+		invalid[i] = 0xff
+	}
+	batch := func(k int, shares ...[]byte) []byte {
+		data := party.Size(k).Bytes()
+		for _, s := range shares {
+			data = append(data, s...)
+		}
+		return data
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"short", []byte{0, 0}},
+		{"count mismatch", batch(3, share, share)},
+		{"trailing data", append(batch(1, share), 0)},
+		{"invalid share", batch(1, invalid)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m KeyGenBatch2
+			err := m.UnmarshalBinary(tt.data)
+			var fieldErr *FieldError
+			require.True(t, errors.As(err, &fieldErr), err)
+			assert.Equal(t, "KeyGenBatch2.Shares", fieldErr.Field)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// sizeBlameComplaint is the size of the encoding of a Complaint: the ID of the accused party and the share.
+const sizeBlameComplaint = party.IDByteSize + 32
+
+// KeyGenBlame is sent in the optional blame round of the keygen protocol, after the KeyGen2 messages.
+// It contains the shares received by the sender which did not match the commitments of their sender,
+// so that all parties can check the accusations. It is sent even if it contains no complaint.
+//
+// Its binary encoding is:
+//
+//	n (4 bytes) ∥ n × (accused (4 bytes) ∥ share (32 bytes))
+type KeyGenBlame struct {
+	// Complaints are sorted by the ID of the accused party, which appear at most once.
+	Complaints []Complaint
+}
+
+// Complaint reveals the share received from the Accused party in its KeyGen2 message.
+// The share is only an evaluation of the polynomial of the accused party, so revealing it does not leak
+// anything about the group key, and the keygen aborts anyway.
+type Complaint struct {
+	Accused party.ID
+	Share   ristretto.Scalar
+}
+
+// NewKeyGenBlame returns a KeyGenBlame message containing complaints, which must be sorted by Accused.
+func NewKeyGenBlame(from party.ID, complaints []Complaint) *Message {
+	return &Message{
+		Header: Header{
+			Type: MessageTypeKeyGenBlame,
+			From: from,
+		},
+		KeyGenBlame: &KeyGenBlame{Complaints: complaints},
+	}
+}
+
+// validate checks that the accused parties are non-zero, and sorted without duplicates.
+func (m *KeyGenBlame) validate() error {
+	for i, c := range m.Complaints {
+		if c.Accused == 0 {
+			return party.ErrZeroID
+		}
+		if i > 0 && c.Accused <= m.Complaints[i-1].Accused {
+			return errors.New("complaints must be sorted by accused party, without duplicates")
+		}
+	}
+	return nil
+}
+
+// AppendBinary appends the encoding of m to dst and returns the extended slice.
+// It does not allocate if dst has a capacity of at least Size() additional bytes.
+func (m *KeyGenBlame) AppendBinary(dst []byte) ([]byte, error) {
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("KeyGenBlame.AppendBinary: %w", err)
+	}
+	dst = append(dst, party.Size(len(m.Complaints)).Bytes()...)
+	for i := range m.Complaints {
+		dst = append(dst, m.Complaints[i].Accused.Bytes()...)
+		dst = scalar.AppendBytes(dst, &m.Complaints[i].Share)
+	}
+	return dst, nil
+}
+
+// BytesAppend is the same as AppendBinary.
+func (m *KeyGenBlame) BytesAppend(existing []byte) ([]byte, error) {
+	return m.AppendBinary(existing)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (m *KeyGenBlame) MarshalBinary() ([]byte, error) {
+	return m.AppendBinary(make([]byte, 0, m.Size()))
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (m *KeyGenBlame) UnmarshalBinary(data []byte) error {
+	if len(data) < party.IDByteSize {
+		return &FieldError{Field: "KeyGenBlame.Complaints", Err: fmt.Errorf("expected at least %d bytes (got %d)", party.IDByteSize, len(data))}
+	}
+	n, _ := party.FromBytes(data)
+	data = data[party.IDByteSize:]
+	if uint64(len(data)) != uint64(n)*sizeBlameComplaint {
+		return &FieldError{Field: "KeyGenBlame.Complaints", Err: fmt.Errorf("expected %d complaints", n)}
+	}
+
+	complaints := make([]Complaint, n)
+	for i := range complaints {
+		complaints[i].Accused, _ = party.FromBytes(data)
+		if _, err := complaints[i].Share.SetCanonicalBytes(data[party.IDByteSize:sizeBlameComplaint]); err != nil {
+			return &FieldError{Field: "KeyGenBlame.Share", Err: err}
+		}
+		data = data[sizeBlameComplaint:]
+	}
+	blame := KeyGenBlame{Complaints: complaints}
+	if err := blame.validate(); err != nil {
+		return &FieldError{Field: "KeyGenBlame.Accused", Err: err}
+	}
+	*m = blame
+	return nil
+}
+
+func (m *KeyGenBlame) Size() int {
+	return party.IDByteSize + len(m.Complaints)*sizeBlameComplaint
+}
+
+func (m *KeyGenBlame) Equal(other interface{}) bool {
+	otherMsg, ok := other.(*KeyGenBlame)
+	if !ok || len(otherMsg.Complaints) != len(m.Complaints) {
+		return false
+	}
+	for i := range m.Complaints {
+		if m.Complaints[i].Accused != otherMsg.Complaints[i].Accused || m.Complaints[i].Share.Equal(&otherMsg.Complaints[i].Share) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+type jsonComplaint struct {
+	Accused uint32 `json:"accused"`
+	Share   string `json:"share"`
+}
+
+type jsonKeyGenBlame struct {
+	Complaints []jsonComplaint `json:"complaints"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m *KeyGenBlame) MarshalJSON() ([]byte, error) {
+	out := jsonKeyGenBlame{Complaints: make([]jsonComplaint, 0, len(m.Complaints))}
+	for i := range m.Complaints {
+		out.Complaints = append(out.Complaints, jsonComplaint{
+			Accused: uint32(m.Complaints[i].Accused),
+			Share:   encodeHex(m.Complaints[i].Share.Bytes()),
+		})
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The complaints are validated as in UnmarshalBinary.
+func (m *KeyGenBlame) UnmarshalJSON(data []byte) error {
+	var out jsonKeyGenBlame
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	blame := KeyGenBlame{Complaints: make([]Complaint, len(out.Complaints))}
+	for i, c := range out.Complaints {
+		share, err := decodeHex(c.Share, 32)
+		if err != nil {
+			return fmt.Errorf("blame.Share: %w", err)
+		}
+		blame.Complaints[i].Accused = party.ID(c.Accused)
+		if _, err = blame.Complaints[i].Share.SetCanonicalBytes(share); err != nil {
+			return &FieldError{Field: "KeyGenBlame.Share", Err: err}
+		}
+	}
+	if err := blame.validate(); err != nil {
+		return &FieldError{Field: "KeyGenBlame.Accused", Err: err}
+	}
+	*m = blame
+	return nil
+}
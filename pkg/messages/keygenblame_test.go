@@ -0,0 +1,78 @@
+package messages
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+)
+
+func TestKeyGenBlame_MarshalBinary(t *testing.T) {
+	for _, accused := range []party.IDSlice{nil, {2}, {1, 3, 300}} {
+		complaints := make([]Complaint, 0, len(accused))
+		for _, id := range accused {
+			c := Complaint{Accused: id}
+			c.Share.Set(scalar.NewScalarRandom())
+			complaints = append(complaints, c)
+		}
+		msg := NewKeyGenBlame(4, complaints)
+
+		var msg2 Message
+		require.NoError(t, CheckFROSTMarshaler(msg, &msg2))
+		assert.True(t, msg2.Equal(msg), "messages are not equal")
+		assert.True(t, msg2.IsBroadcast())
+	}
+}
+
+func TestKeyGenBlame_MarshalBinary_Unsorted(t *testing.T) {
+	msg := NewKeyGenBlame(4, []Complaint{{Accused: 3}, {Accused: 1}})
+	_, err := msg.MarshalBinary()
+	assert.Error(t, err)
+}
+
+func TestKeyGenBlame_UnmarshalBinary_Invalid(t *testing.T) {
+	share := scalar.AppendBytes(nil, scalar.NewScalarRandom())
+	entry := func(accused party.ID, share []byte) []byte {
+		return append(accused.Bytes(), share...)
+	}
+	blame := func(n int, entries ...[]byte) []byte {
+		data := party.Size(n).Bytes()
+		for _, e := range entries {
+			data = append(data, e...)
+		}
+		return data
+	}
+	nonCanonical := make([]byte, 32)
+	for i := range nonCanonical {
+		nonCanonical[i] = 0xff
+	}
+
+	var valid KeyGenBlame
+	require.NoError(t, valid.UnmarshalBinary(blame(2, entry(1, share), entry(3, share))))
+
+	tests := []struct {
+		name  string
+		data  []byte
+		field string
+	}{
+		{"short", []byte{0, 0}, "KeyGenBlame.Complaints"},
+		{"count mismatch", blame(3, entry(1, share), entry(3, share)), "KeyGenBlame.Complaints"},
+		{"trailing data", append(blame(1, entry(1, share)), 0), "KeyGenBlame.Complaints"},
+		{"zero accused", blame(1, entry(0, share)), "KeyGenBlame.Accused"},
+		{"duplicate accused", blame(2, entry(1, share), entry(1, share)), "KeyGenBlame.Accused"},
+		{"unsorted accused", blame(2, entry(3, share), entry(1, share)), "KeyGenBlame.Accused"},
+		{"non-canonical share", blame(1, entry(1, nonCanonical)), "KeyGenBlame.Share"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m KeyGenBlame
+			err := m.UnmarshalBinary(tt.data)
+			var fieldErr *FieldError
+			require.True(t, errors.As(err, &fieldErr), err)
+			assert.Equal(t, tt.field, fieldErr.Field)
+		})
+	}
+}
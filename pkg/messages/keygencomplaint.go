@@ -0,0 +1,135 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+// KeyGenComplaint is sent in the complaint round of a robust keygen, after the KeyGen2 messages.
+// It contains the parties from which the sender did not receive a valid share in time,
+// which are then excluded by all parties. It is sent even if it accuses no party.
+//
+// Its binary encoding is:
+//
+//	n (4 bytes) ∥ n × accused (4 bytes)
+type KeyGenComplaint struct {
+	// Accused is sorted, without duplicates.
+	Accused party.IDSlice
+}
+
+// NewKeyGenComplaint returns a KeyGenComplaint message accusing the parties in accused, which must be sorted.
+func NewKeyGenComplaint(from party.ID, accused party.IDSlice) *Message {
+	return &Message{
+		Header: Header{
+			Type: MessageTypeKeyGenComplaint,
+			From: from,
+		},
+		KeyGenComplaint: &KeyGenComplaint{Accused: accused},
+	}
+}
+
+// validate checks that the accused parties are non-zero, and sorted without duplicates.
+func (m *KeyGenComplaint) validate() error {
+	for i, id := range m.Accused {
+		if id == 0 {
+			return party.ErrZeroID
+		}
+		if i > 0 && id <= m.Accused[i-1] {
+			return errors.New("accused parties must be sorted, without duplicates")
+		}
+	}
+	return nil
+}
+
+// AppendBinary appends the encoding of m to dst and returns the extended slice.
+// It does not allocate if dst has a capacity of at least Size() additional bytes.
+func (m *KeyGenComplaint) AppendBinary(dst []byte) ([]byte, error) {
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("KeyGenComplaint.AppendBinary: %w", err)
+	}
+	dst = append(dst, party.Size(len(m.Accused)).Bytes()...)
+	for _, id := range m.Accused {
+		dst = append(dst, id.Bytes()...)
+	}
+	return dst, nil
+}
+
+// BytesAppend is the same as AppendBinary.
+func (m *KeyGenComplaint) BytesAppend(existing []byte) ([]byte, error) {
+	return m.AppendBinary(existing)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (m *KeyGenComplaint) MarshalBinary() ([]byte, error) {
+	return m.AppendBinary(make([]byte, 0, m.Size()))
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (m *KeyGenComplaint) UnmarshalBinary(data []byte) error {
+	if len(data) < party.IDByteSize {
+		return &FieldError{Field: "KeyGenComplaint.Accused", Err: fmt.Errorf("expected at least %d bytes (got %d)", party.IDByteSize, len(data))}
+	}
+	n, _ := party.FromBytes(data)
+	data = data[party.IDByteSize:]
+	if uint64(len(data)) != uint64(n)*party.IDByteSize {
+		return &FieldError{Field: "KeyGenComplaint.Accused", Err: fmt.Errorf("expected %d accused parties", n)}
+	}
+
+	accused := make(party.IDSlice, n)
+	for i := range accused {
+		accused[i], _ = party.FromBytes(data)
+		data = data[party.IDByteSize:]
+	}
+	complaint := KeyGenComplaint{Accused: accused}
+	if err := complaint.validate(); err != nil {
+		return &FieldError{Field: "KeyGenComplaint.Accused", Err: err}
+	}
+	*m = complaint
+	return nil
+}
+
+func (m *KeyGenComplaint) Size() int {
+	return party.IDByteSize * (1 + len(m.Accused))
+}
+
+func (m *KeyGenComplaint) Equal(other interface{}) bool {
+	otherMsg, ok := other.(*KeyGenComplaint)
+	if !ok {
+		return false
+	}
+	return otherMsg.Accused.Equal(m.Accused)
+}
+
+type jsonKeyGenComplaint struct {
+	Accused []uint32 `json:"accused"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m *KeyGenComplaint) MarshalJSON() ([]byte, error) {
+	out := jsonKeyGenComplaint{Accused: make([]uint32, 0, len(m.Accused))}
+	for _, id := range m.Accused {
+		out.Accused = append(out.Accused, uint32(id))
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The accused parties are validated as in UnmarshalBinary.
+func (m *KeyGenComplaint) UnmarshalJSON(data []byte) error {
+	var out jsonKeyGenComplaint
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	complaint := KeyGenComplaint{Accused: make(party.IDSlice, 0, len(out.Accused))}
+	for _, id := range out.Accused {
+		complaint.Accused = append(complaint.Accused, party.ID(id))
+	}
+	if err := complaint.validate(); err != nil {
+		return &FieldError{Field: "KeyGenComplaint.Accused", Err: err}
+	}
+	*m = complaint
+	return nil
+}
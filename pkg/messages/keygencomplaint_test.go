@@ -0,0 +1,55 @@
+package messages
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+func TestKeyGenComplaint_MarshalBinary(t *testing.T) {
+	for _, accused := range []party.IDSlice{nil, {2}, {1, 3, 300}} {
+		msg := NewKeyGenComplaint(4, accused)
+
+		var msg2 Message
+		require.NoError(t, CheckFROSTMarshaler(msg, &msg2))
+		assert.True(t, msg2.Equal(msg), "messages are not equal")
+		assert.True(t, msg2.IsBroadcast())
+	}
+}
+
+func TestKeyGenComplaint_UnmarshalBinary_Invalid(t *testing.T) {
+	complaint := func(n int, accused ...party.ID) []byte {
+		data := party.Size(n).Bytes()
+		for _, id := range accused {
+			data = append(data, id.Bytes()...)
+		}
+		return data
+	}
+
+	var valid KeyGenComplaint
+	require.NoError(t, valid.UnmarshalBinary(complaint(2, 1, 3)))
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"short", []byte{0, 0}},
+		{"count mismatch", complaint(3, 1, 3)},
+		{"trailing data", append(complaint(1, 1), 0)},
+		{"zero accused", complaint(1, 0)},
+		{"duplicate accused", complaint(2, 1, 1)},
+		{"unsorted accused", complaint(2, 3, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m KeyGenComplaint
+			err := m.UnmarshalBinary(tt.data)
+			var fieldErr *FieldError
+			require.True(t, errors.As(err, &fieldErr), err)
+			assert.Equal(t, "KeyGenComplaint.Accused", fieldErr.Field)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package messages
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+// SizeConfirmDigest is the size of KeyGenConfirm.Digest.
+const SizeConfirmDigest = 32
+
+// KeyGenConfirm is sent in the optional confirmation round of the keygen protocol, after the KeyGen2 messages.
+type KeyGenConfirm struct {
+	// Digest is the hash of the public key shares and group key computed by the sender.
+	// All parties must obtain the same digest.
+	Digest [SizeConfirmDigest]byte
+}
+
+func NewKeyGenConfirm(from party.ID, digest *[SizeConfirmDigest]byte) *Message {
+	return &Message{
+		Header: Header{
+			Type: MessageTypeKeyGenConfirm,
+			From: from,
+		},
+		KeyGenConfirm: &KeyGenConfirm{Digest: *digest},
+	}
+}
+
+// AppendBinary appends the encoding of m to dst and returns the extended slice.
+// It does not allocate if dst has a capacity of at least Size() additional bytes.
+func (m *KeyGenConfirm) AppendBinary(dst []byte) ([]byte, error) {
+	return append(dst, m.Digest[:]...), nil
+}
+
+// BytesAppend is the same as AppendBinary.
+func (m *KeyGenConfirm) BytesAppend(existing []byte) ([]byte, error) {
+	return m.AppendBinary(existing)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (m *KeyGenConfirm) MarshalBinary() ([]byte, error) {
+	return m.AppendBinary(make([]byte, 0, SizeConfirmDigest))
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (m *KeyGenConfirm) UnmarshalBinary(data []byte) error {
+	if len(data) != SizeConfirmDigest {
+		return &FieldError{Field: "KeyGenConfirm.Digest", Err: fmt.Errorf("expected %d bytes (got %d)", SizeConfirmDigest, len(data))}
+	}
+	copy(m.Digest[:], data)
+	return nil
+}
+
+func (m *KeyGenConfirm) Size() int {
+	return SizeConfirmDigest
+}
+
+func (m *KeyGenConfirm) Equal(other interface{}) bool {
+	otherMsg, ok := other.(*KeyGenConfirm)
+	if !ok {
+		return false
+	}
+	return otherMsg.Digest == m.Digest
+}
+
+type jsonKeyGenConfirm struct {
+	Digest string `json:"digest"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m *KeyGenConfirm) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonKeyGenConfirm{
+		Digest: encodeHex(m.Digest[:]),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (m *KeyGenConfirm) UnmarshalJSON(data []byte) error {
+	var out jsonKeyGenConfirm
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	digest, err := decodeHex(out.Digest, SizeConfirmDigest)
+	if err != nil {
+		return fmt.Errorf("confirm.Digest: %w", err)
+	}
+	return m.UnmarshalBinary(digest)
+}
@@ -0,0 +1,22 @@
+package messages
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+func TestKeyGenConfirm_MarshalBinary(t *testing.T) {
+	var digest [SizeConfirmDigest]byte
+	_, _ = rand.Read(digest[:])
+
+	msg := NewKeyGenConfirm(party.RandID(), &digest)
+
+	var msg2 Message
+	require.NoError(t, CheckFROSTMarshaler(msg, &msg2))
+	assert.True(t, msg2.Equal(msg), "messages are not equal")
+	assert.True(t, msg2.IsBroadcast())
+}
@@ -0,0 +1,90 @@
+package messages
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+// SizeEchoDigest is the size of KeyGenEcho.Digest.
+const SizeEchoDigest = 32
+
+// KeyGenEcho is sent in the optional echo round of the keygen protocol,
+// between the KeyGen1 and KeyGen2 messages.
+type KeyGenEcho struct {
+	// Digest is the hash of the commitments received by the sender in KeyGen1 messages, including its own.
+	// All parties must obtain the same digest.
+	Digest [SizeEchoDigest]byte
+}
+
+func NewKeyGenEcho(from party.ID, digest *[SizeEchoDigest]byte) *Message {
+	return &Message{
+		Header: Header{
+			Type: MessageTypeKeyGenEcho,
+			From: from,
+		},
+		KeyGenEcho: &KeyGenEcho{Digest: *digest},
+	}
+}
+
+// AppendBinary appends the encoding of m to dst and returns the extended slice.
+// It does not allocate if dst has a capacity of at least Size() additional bytes.
+func (m *KeyGenEcho) AppendBinary(dst []byte) ([]byte, error) {
+	return append(dst, m.Digest[:]...), nil
+}
+
+// BytesAppend is the same as AppendBinary.
+func (m *KeyGenEcho) BytesAppend(existing []byte) ([]byte, error) {
+	return m.AppendBinary(existing)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (m *KeyGenEcho) MarshalBinary() ([]byte, error) {
+	return m.AppendBinary(make([]byte, 0, SizeEchoDigest))
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (m *KeyGenEcho) UnmarshalBinary(data []byte) error {
+	if len(data) != SizeEchoDigest {
+		return &FieldError{Field: "KeyGenEcho.Digest", Err: fmt.Errorf("expected %d bytes (got %d)", SizeEchoDigest, len(data))}
+	}
+	copy(m.Digest[:], data)
+	return nil
+}
+
+func (m *KeyGenEcho) Size() int {
+	return SizeEchoDigest
+}
+
+func (m *KeyGenEcho) Equal(other interface{}) bool {
+	otherMsg, ok := other.(*KeyGenEcho)
+	if !ok {
+		return false
+	}
+	return otherMsg.Digest == m.Digest
+}
+
+type jsonKeyGenEcho struct {
+	Digest string `json:"digest"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m *KeyGenEcho) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonKeyGenEcho{
+		Digest: encodeHex(m.Digest[:]),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (m *KeyGenEcho) UnmarshalJSON(data []byte) error {
+	var out jsonKeyGenEcho
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	digest, err := decodeHex(out.Digest, SizeEchoDigest)
+	if err != nil {
+		return fmt.Errorf("echo.Digest: %w", err)
+	}
+	return m.UnmarshalBinary(digest)
+}
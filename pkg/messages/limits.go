@@ -0,0 +1,228 @@
+package messages
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+// Limits contains the parameters of a protocol execution which determine the size of its messages.
+//
+// Since the size of a KeyGen1 message grows with the degree of the committed polynomial,
+// a message can otherwise be up to 2MB long, which a malicious party could use to make us allocate memory needlessly.
+type Limits struct {
+	// Threshold is the degree of the polynomials committed to in KeyGen1 and KeyGenBatch1 messages,
+	// which must contain exactly Threshold+1 commitments.
+	// It is ignored for the other message types, whose size is constant.
+	Threshold party.Size
+
+	// Batch is the number of keys generated by a batch keygen, for each of which KeyGenBatch1 messages must contain
+	// commitments, and KeyGenBatch2 messages a share. If it is 0, they cannot contain any.
+	Batch int
+
+	// EncryptedShares indicates that the keygen is run with encrypted shares,
+	// in which case KeyGen1 messages must contain an encryption key, and KeyGen2 messages a sealed share.
+	// Otherwise, neither may be present.
+	EncryptedShares bool
+
+	// Parties is the number of parties of the execution, which bounds the number of recipients of a Packed message,
+	// the number of complaints of a KeyGenBlame or KeyGenComplaint message, and the number of masks of a Repair1 message,
+	// to Parties-1.
+	// If it is 0, Packed messages are not accepted, and KeyGenBlame, KeyGenComplaint and Repair1 messages cannot contain
+	// any complaint or mask.
+	Parties party.Size
+}
+
+// MaxSize returns the maximum size of the binary encoding of a message of type t, or 0 if t is not valid.
+func (l Limits) MaxSize(t MessageType) int {
+	var size int
+	switch t {
+	case MessageTypeKeyGen1:
+		size = sizeKeygen1Proof + sizeKeygen1Commitments(l.Threshold)
+		if l.EncryptedShares {
+			size += sizeKeygen1EncryptionKey
+		}
+	case MessageTypeKeyGen2:
+		size = sizeKeygen2
+		if l.EncryptedShares {
+			size = SizeSealedShare
+		}
+	case MessageTypeSign1:
+		size = sizeSign1
+	case MessageTypeSign2:
+		size = sizeSign2
+	case MessageTypeKeyGenEcho:
+		size = SizeEchoDigest
+	case MessageTypeKeyGenConfirm:
+		size = SizeConfirmDigest
+	case MessageTypeEnroll:
+		size = sizeEnroll
+	case MessageTypeRepair2:
+		size = sizeRepair2
+	case MessageTypeKeyGenBatch1:
+		size = party.IDByteSize + l.Batch*(sizeKeygen1Proof+sizeKeygen1Commitments(l.Threshold))
+	case MessageTypeKeyGenBatch2:
+		size = party.IDByteSize + l.Batch*sizeKeygen2
+	case MessageTypeRepair1:
+		// at most one mask for each other party
+		size = party.IDByteSize
+		if l.Parties > 1 {
+			size += int(l.Parties-1) * 32
+		}
+	case MessageTypeKeyGenComplaint:
+		// at most one complaint against each other party
+		size = party.IDByteSize
+		if l.Parties > 1 {
+			size += int(l.Parties-1) * party.IDByteSize
+		}
+	case MessageTypeKeyGenBlame:
+		// at most one complaint against each other party
+		size = party.IDByteSize
+		if l.Parties > 1 {
+			size += int(l.Parties-1) * sizeBlameComplaint
+		}
+	case MessageTypePacked:
+		if l.Parties < 2 {
+			return 0
+		}
+		var content int
+		for inner, broadcast := range broadcastTypes {
+			if !broadcast {
+				if size := l.MaxSize(inner) - headerSize; size > content {
+					content = size
+				}
+			}
+		}
+		size = sizePackedHeader + int(l.Parties-1)*(sizePackedEntry+content)
+	default:
+		ext, ok := lookupExtension(t)
+		if !ok {
+			return 0
+		}
+		size = ext.MaxSize
+	}
+	return headerSize + size
+}
+
+// MaxMessageSize returns the maximum size of the binary encoding of a message of any of the given types.
+func (l Limits) MaxMessageSize(types ...MessageType) int {
+	var max int
+	for _, t := range types {
+		if size := l.MaxSize(t); size > max {
+			max = size
+		}
+	}
+	return max
+}
+
+// Check returns a *FieldError if the content of msg does not respect the limits.
+// The messages contained in a Packed message are checked individually.
+func (l Limits) Check(msg *Message) error {
+	if msg.Type == MessageTypePacked && msg.Packed != nil {
+		if l.Parties < 2 {
+			return &FieldError{Field: "Packed", Err: errors.New("packed messages are not allowed")}
+		}
+		if len(msg.Packed.To) > int(l.Parties-1) {
+			return &FieldError{Field: "Packed.To", Err: fmt.Errorf("at most %d recipients are allowed (got %d)",
+				int(l.Parties-1), len(msg.Packed.To))}
+		}
+		msgs, err := msg.Split()
+		if err != nil {
+			return &FieldError{Field: "Packed.Payloads", Err: err}
+		}
+		for _, inner := range msgs {
+			if err = l.Check(inner); err != nil {
+				return err
+			}
+		}
+	}
+	if msg.Type == MessageTypeKeyGen1 && msg.KeyGen1 != nil {
+		if degree := msg.KeyGen1.Commitments.Degree(); degree != l.Threshold {
+			return &FieldError{Field: "KeyGen1.Commitments", Err: fmt.Errorf("expected %d commitments (got %d)",
+				int(l.Threshold)+1, int(degree)+1)}
+		}
+		if (msg.KeyGen1.EncryptionKey != nil) != l.EncryptedShares {
+			return &FieldError{Field: "KeyGen1.EncryptionKey", Err: l.encryptionError()}
+		}
+	}
+	if msg.Type == MessageTypeKeyGenBlame && msg.KeyGenBlame != nil {
+		var allowed int
+		if l.Parties > 1 {
+			allowed = int(l.Parties - 1)
+		}
+		if len(msg.KeyGenBlame.Complaints) > allowed {
+			return &FieldError{Field: "KeyGenBlame.Complaints", Err: fmt.Errorf("at most %d complaints are allowed (got %d)",
+				allowed, len(msg.KeyGenBlame.Complaints))}
+		}
+	}
+	if msg.Type == MessageTypeKeyGenComplaint && msg.KeyGenComplaint != nil {
+		var allowed int
+		if l.Parties > 1 {
+			allowed = int(l.Parties - 1)
+		}
+		if len(msg.KeyGenComplaint.Accused) > allowed {
+			return &FieldError{Field: "KeyGenComplaint.Accused", Err: fmt.Errorf("at most %d complaints are allowed (got %d)",
+				allowed, len(msg.KeyGenComplaint.Accused))}
+		}
+	}
+	if msg.Type == MessageTypeRepair1 && msg.Repair1 != nil {
+		var allowed int
+		if l.Parties > 1 {
+			allowed = int(l.Parties - 1)
+		}
+		if len(msg.Repair1.Masks) > allowed {
+			return &FieldError{Field: "Repair1.Masks", Err: fmt.Errorf("at most %d masks are allowed (got %d)",
+				allowed, len(msg.Repair1.Masks))}
+		}
+	}
+	if msg.Type == MessageTypeKeyGenBatch1 && msg.KeyGenBatch1 != nil {
+		if len(msg.KeyGenBatch1.Keys) != l.Batch {
+			return &FieldError{Field: "KeyGenBatch1.Keys", Err: fmt.Errorf("expected %d keys (got %d)", l.Batch, len(msg.KeyGenBatch1.Keys))}
+		}
+		for i := range msg.KeyGenBatch1.Keys {
+			if degree := msg.KeyGenBatch1.Keys[i].Commitments.Degree(); degree != l.Threshold {
+				return &FieldError{Field: "KeyGenBatch1.Keys", Err: fmt.Errorf("expected %d commitments for key %d (got %d)",
+					int(l.Threshold)+1, i, int(degree)+1)}
+			}
+		}
+	}
+	if msg.Type == MessageTypeKeyGenBatch2 && msg.KeyGenBatch2 != nil {
+		if len(msg.KeyGenBatch2.Shares) != l.Batch {
+			return &FieldError{Field: "KeyGenBatch2.Shares", Err: fmt.Errorf("expected %d shares (got %d)", l.Batch, len(msg.KeyGenBatch2.Shares))}
+		}
+	}
+	if msg.Type == MessageTypeKeyGen2 && msg.KeyGen2 != nil {
+		if (msg.KeyGen2.SealedShare != nil) != l.EncryptedShares {
+			return &FieldError{Field: "KeyGen2.SealedShare", Err: l.encryptionError()}
+		}
+	}
+	return nil
+}
+
+// encryptionError describes the error of a message whose encryption does not match l.EncryptedShares.
+func (l Limits) encryptionError() error {
+	if l.EncryptedShares {
+		return errors.New("shares must be encrypted")
+	}
+	return errors.New("shares must not be encrypted")
+}
+
+// UnmarshalBinary decodes data into msg as Message.UnmarshalBinary does,
+// but first checks that data is not larger than MaxSize for the type given in the header.
+// Larger messages are therefore rejected before any memory is allocated for their content.
+func (l Limits) UnmarshalBinary(data []byte, msg *Message) (err error) {
+	defer recoverPanic(&err)
+
+	content, err := msg.Header.unmarshal(data)
+	if err != nil {
+		return err
+	}
+	if max := l.MaxSize(msg.Type); len(data) > max {
+		return &FieldError{Field: "Message", Err: fmt.Errorf("message is %d bytes long, but at most %d are allowed", len(data), max)}
+	}
+	if err := msg.unmarshalContent(content); err != nil {
+		return err
+	}
+	return l.Check(msg)
+}
@@ -0,0 +1,208 @@
+package messages
+
+import (
+	"crypto/rand"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// testLimits are the limits respected by testMessages.
+var testLimits = Limits{Threshold: 3, Parties: 3, Batch: 2}
+
+func TestLimits_MaxSize(t *testing.T) {
+	for _, msg := range testMessages() {
+		data, err := msg.MarshalBinary()
+		require.NoError(t, err)
+		limits := testLimits
+		if msg.Type == MessageTypePacked {
+			// The packed messages are KeyGen2 messages, which are smaller than KeyGenBatch2 messages
+			limits.Batch = 0
+		}
+		assert.Equal(t, limits.MaxSize(msg.Type), len(data), msg.Type.String())
+		assert.LessOrEqual(t, len(data), testLimits.MaxMessageSize(MessageTypeKeyGen1, MessageTypeKeyGen2, MessageTypeKeyGenBatch1))
+	}
+	assert.Equal(t, 0, testLimits.MaxSize(MessageTypeNone))
+	assert.Equal(t, testLimits.MaxSize(MessageTypeSign1), testLimits.MaxMessageSize(MessageTypeSign1, MessageTypeSign2))
+}
+
+func TestLimits_UnmarshalBinary(t *testing.T) {
+	for _, msg := range testMessages() {
+		data, err := msg.MarshalBinary()
+		require.NoError(t, err)
+
+		var msg2 Message
+		require.NoError(t, testLimits.UnmarshalBinary(data, &msg2))
+		assert.True(t, msg.Equal(&msg2), "messages are not equal")
+	}
+
+	keygen1, err := testMessages()[0].MarshalBinary()
+	require.NoError(t, err)
+
+	var fieldErr *FieldError
+	var msg Message
+
+	// The commitments of a polynomial of a different degree are valid, but rejected by the limits
+	err = (Limits{Threshold: 2}).UnmarshalBinary(keygen1, &msg)
+	require.True(t, errors.As(err, &fieldErr), err)
+	assert.Equal(t, "Message", fieldErr.Field)
+	err = (Limits{Threshold: 4}).UnmarshalBinary(keygen1, &msg)
+	require.True(t, errors.As(err, &fieldErr), err)
+	assert.Equal(t, "KeyGen1.Commitments", fieldErr.Field)
+	assert.True(t, errors.Is(err, ErrInvalidMessage))
+
+	// A batch must contain exactly one entry per key, of the expected degree
+	msgs := testMessages()
+	batch1, err := msgs[len(msgs)-3].MarshalBinary()
+	require.NoError(t, err)
+	batch2, err := msgs[len(msgs)-2].MarshalBinary()
+	require.NoError(t, err)
+	for _, limits := range []Limits{{Threshold: 3, Batch: 3}, {Threshold: 4, Batch: 2}} {
+		err = limits.UnmarshalBinary(batch1, &msg)
+		require.True(t, errors.As(err, &fieldErr), err)
+		assert.Equal(t, "KeyGenBatch1.Keys", fieldErr.Field)
+	}
+	err = (Limits{Batch: 3}).UnmarshalBinary(batch2, &msg)
+	require.True(t, errors.As(err, &fieldErr), err)
+	assert.Equal(t, "KeyGenBatch2.Shares", fieldErr.Field)
+	err = (Limits{Batch: 1}).UnmarshalBinary(batch2, &msg)
+	require.True(t, errors.As(err, &fieldErr), err)
+	assert.Equal(t, "Message", fieldErr.Field)
+}
+
+func TestMessage_UnmarshalBinary_FieldError(t *testing.T) {
+	msgs := testMessages()
+	encoded := make([][]byte, len(msgs))
+	for i, msg := range msgs {
+		var err error
+		encoded[i], err = msg.MarshalBinary()
+		require.NoError(t, err)
+	}
+	withByte := func(data []byte, i int, b byte) []byte {
+		data = append([]byte{}, data...)
+		data[i] = b
+		return data
+	}
+	invalidElement := make([]byte, 32)
+	for i := range invalidElement {
+		invalidElement[i] = 0xff
+	}
+
+	tests := []struct {
+		name  string
+		data  []byte
+		field string
+	}{
+		{"unknown type", withByte(encoded[3], 6, 42), "Header.Type"},
+		{"zero sender", withByte(withByte(encoded[3], 9, 0), 10, 0), "Header.From"},
+		{"missing recipient", withByte(withByte(encoded[1], 13, 0), 14, 0), "Header.To"},
+		{"short proof", encoded[0][:headerSize+10], "KeyGen1.Proof"},
+		{"missing commitments", encoded[0][:headerSize+64], "KeyGen1.Commitments"},
+		{"truncated commitments", encoded[0][:len(encoded[0])-1], "KeyGen1.Commitments"},
+		{"huge degree", withByte(withByte(encoded[0], headerSize+64, 0xff), headerSize+65, 0xff), "KeyGen1.Commitments"},
+		{"huge degree low bits", withByte(withByte(encoded[0], headerSize+66, 0xff), headerSize+67, 0xff), "KeyGen1.Commitments"},
+		{"short share", encoded[1][:len(encoded[1])-1], "KeyGen2.Share"},
+		{"short commitments", encoded[2][:len(encoded[2])-1], "Sign1"},
+		{"invalid D", append(append(encoded[2][:headerSize:headerSize], invalidElement...), encoded[2][headerSize+32:]...), "Sign1.Di"},
+		{"long signature share", append(encoded[3][:len(encoded[3]):len(encoded[3])], 0), "Sign2.Zi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msg Message
+			err := msg.UnmarshalBinary(tt.data)
+			var fieldErr *FieldError
+			require.True(t, errors.As(err, &fieldErr), "expected a *FieldError, got %v", err)
+			assert.Equal(t, tt.field, fieldErr.Field)
+			assert.True(t, errors.Is(err, ErrInvalidMessage))
+		})
+	}
+}
+
+// allocated returns the number of bytes allocated while running f.
+func allocated(f func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+func FuzzLimits_UnmarshalBinary(f *testing.F) {
+	for _, msg := range testMessages() {
+		data, err := msg.MarshalBinary()
+		require.NoError(f, err)
+		f.Add(data)
+	}
+	// A KeyGen1 message announcing the maximum number of commitments
+	keygen1, err := testMessages()[0].MarshalBinary()
+	require.NoError(f, err)
+	keygen1[headerSize+64], keygen1[headerSize+65] = 0xff, 0xff
+	f.Add(append(keygen1, make([]byte, 1<<16)...))
+
+	// A decoded element takes more space in memory than its 32 bytes encoding,
+	// so the allocations are bounded by a multiple of the maximum size.
+	const allocationFactor = 8
+	maxAllocated := uint64(allocationFactor*testLimits.MaxMessageSize(MessageTypeKeyGen1, MessageTypeKeyGen2, MessageTypeSign1, MessageTypeSign2) + 4096)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var msg Message
+		var err error
+		if n := allocated(func() { err = testLimits.UnmarshalBinary(data, &msg) }); n > maxAllocated {
+			t.Fatalf("decoding %d bytes allocated %d bytes (maximum %d)", len(data), n, maxAllocated)
+		}
+		if err != nil {
+			return
+		}
+		assert.LessOrEqual(t, len(data), testLimits.MaxSize(msg.Type))
+		assert.NoError(t, testLimits.Check(&msg))
+	})
+}
+
+// testEncryptedMessages returns a KeyGen1 and a KeyGen2 message of a keygen with encrypted shares.
+func testEncryptedMessages() []*Message {
+	msgs := testMessages()[:2]
+	msgs[0].KeyGen1.EncryptionKey = new(ristretto.Element).ScalarBaseMult(scalar.NewScalarRandom())
+	sealed := make([]byte, SizeSealedShare)
+	_, _ = rand.Read(sealed)
+	msgs[1] = NewKeyGen2Sealed(1, 2, sealed)
+	return msgs
+}
+
+func TestLimits_EncryptedShares(t *testing.T) {
+	limits := Limits{Threshold: 3, EncryptedShares: true}
+	fields := map[MessageType]string{
+		MessageTypeKeyGen1: "KeyGen1.EncryptionKey",
+		MessageTypeKeyGen2: "KeyGen2.SealedShare",
+	}
+	for _, msg := range testEncryptedMessages() {
+		for _, codec := range []Codec{BinaryCodec, JSONCodec, CBORCodec} {
+			data, err := codec.Marshal(msg)
+			require.NoError(t, err)
+			var msg2 Message
+			require.NoError(t, codec.Unmarshal(data, &msg2))
+			assert.True(t, msg.Equal(&msg2), "messages are not equal")
+		}
+
+		data, err := msg.MarshalBinary()
+		require.NoError(t, err)
+		assert.Equal(t, limits.MaxSize(msg.Type), len(data), msg.Type.String())
+
+		var msg2 Message
+		require.NoError(t, limits.UnmarshalBinary(data, &msg2))
+
+		// Messages of the other mode are rejected
+		var fieldErr *FieldError
+		require.True(t, errors.As(testLimits.Check(msg), &fieldErr))
+		assert.Equal(t, fields[msg.Type], fieldErr.Field)
+	}
+	for _, msg := range testMessages()[:2] {
+		var fieldErr *FieldError
+		require.True(t, errors.As(limits.Check(msg), &fieldErr))
+		assert.Equal(t, fields[msg.Type], fieldErr.Field)
+	}
+}
@@ -3,9 +3,20 @@ package messages
 import (
 	"bytes"
 	"encoding"
+	"encoding/hex"
 	"fmt"
 )
 
+// recoverPanic converts a panic occurring while decoding a message into an error wrapping ErrInvalidMessage,
+// and should be deferred by the exported decoding functions.
+// Decoders validate their input and are not expected to panic,
+// but data sent by a malicious party must never be able to crash the process.
+func recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("messages: decoding panicked (%v): %w", r, ErrInvalidMessage)
+	}
+}
+
 type FROSTMarshaler interface {
 	encoding.BinaryMarshaler
 	encoding.BinaryUnmarshaler
@@ -61,5 +72,40 @@ func CheckFROSTMarshaler(input, output FROSTMarshaler) error {
 	if !bytes.Equal(firstData, thirdData) {
 		return fmt.Errorf("both byte outputs should be the same")
 	}
+
+	// AppendBinary should append to the existing data
+	if a, ok := input.(interface {
+		AppendBinary(dst []byte) ([]byte, error)
+	}); ok {
+		prefix := []byte{0xff}
+		fourthData, err := a.AppendBinary(prefix)
+		if err != nil {
+			return fmt.Errorf("failed to marshall struct: %w", err)
+		}
+		if !bytes.Equal(prefix, fourthData[:1]) || !bytes.Equal(firstData, fourthData[1:]) {
+			return fmt.Errorf("AppendBinary should append the same output")
+		}
+	}
 	return nil
 }
+
+// encodeHex returns the lowercase hexadecimal encoding of data, as used in the JSON encoding of messages.
+func encodeHex(data []byte) string {
+	return hex.EncodeToString(data)
+}
+
+// decodeHex decodes the lowercase hexadecimal string s, which must encode exactly size bytes.
+// Uppercase letters are rejected, so that every value has a unique encoding.
+func decodeHex(s string, size int) ([]byte, error) {
+	if len(s) != 2*size {
+		return nil, fmt.Errorf("hex string should encode %d bytes: %w", size, ErrInvalidMessage)
+	}
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrInvalidMessage)
+	}
+	if encodeHex(data) != s {
+		return nil, fmt.Errorf("hex string must be lowercase: %w", ErrInvalidMessage)
+	}
+	return data, nil
+}
@@ -1,8 +1,13 @@
 package messages
 
 import (
+	"bytes"
+	"encoding"
+	"encoding/json"
 	"errors"
 	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
 )
 
 type Message struct {
@@ -11,49 +16,226 @@ type Message struct {
 	KeyGen2 *KeyGen2
 	Sign1   *Sign1
 	Sign2   *Sign2
+
+	// KeyGenEcho is only sent when the keygen is run with an echo round.
+	KeyGenEcho *KeyGenEcho
+
+	// KeyGenBlame is only sent when the keygen is run with a blame round.
+	KeyGenBlame *KeyGenBlame
+
+	// KeyGenConfirm is only sent when the keygen is run with a confirmation round.
+	KeyGenConfirm *KeyGenConfirm
+
+	// KeyGenComplaint is only sent when the keygen is run in robust mode.
+	KeyGenComplaint *KeyGenComplaint
+
+	// Enroll is only sent in the last round of the enrollment of a new party.
+	Enroll *Enroll
+
+	// Repair1 and Repair2 are only sent during the repair of a lost share.
+	Repair1 *Repair1
+	Repair2 *Repair2
+
+	// KeyGenBatch1 and KeyGenBatch2 are only sent during a batch keygen, instead of KeyGen1 and KeyGen2.
+	KeyGenBatch1 *KeyGenBatch1
+	KeyGenBatch2 *KeyGenBatch2
+
+	// Packed contains the content of messages of the same type for several recipients.
+	Packed *Packed
+
+	// Extension is the content of a message whose type was registered with RegisterExtension.
+	Extension encoding.BinaryMarshaler
 }
 
 var ErrInvalidMessage = errors.New("invalid message")
 
+// FieldError is returned when a message cannot be decoded because of the value of one of its fields.
+// It matches ErrInvalidMessage with errors.Is.
+type FieldError struct {
+	// Field is the name of the field, such as "KeyGen1.Commitments".
+	Field string
+	// Err is the reason why the field is invalid.
+	Err error
+}
+
+// Error implements error
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", ErrInvalidMessage.Error(), e.Field, e.Err)
+}
+
+// Unwrap returns the reason why the field is invalid.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true if target is ErrInvalidMessage.
+func (e *FieldError) Is(target error) bool {
+	return target == ErrInvalidMessage
+}
+
 type MessageType uint8
 
-// MessageType s must be increasing.
+// The values of the MessageType s are part of the encoding, and must not be modified.
+// The order in which a protocol expects them is given by the AcceptedMessageTypes of its rounds.
 const (
 	MessageTypeNone MessageType = iota
 	MessageTypeKeyGen1
 	MessageTypeKeyGen2
 	MessageTypeSign1
 	MessageTypeSign2
+	MessageTypeKeyGenEcho
+	MessageTypePacked
+	MessageTypeKeyGenBlame
+	MessageTypeKeyGenConfirm
+	MessageTypeKeyGenComplaint
+	MessageTypeEnroll
+	MessageTypeRepair1
+	MessageTypeRepair2
+	MessageTypeKeyGenBatch1
+	MessageTypeKeyGenBatch2
 )
 
-func (m *Message) BytesAppend(existing []byte) (data []byte, err error) {
-	existing, err = m.Header.BytesAppend(existing)
+// broadcastTypes indicates, for every valid MessageType, whether its messages are broadcast.
+var broadcastTypes = map[MessageType]bool{
+	MessageTypeKeyGen1: true,
+	MessageTypeKeyGen2: false,
+	MessageTypeSign1:   true,
+	MessageTypeSign2:   true,
+
+	MessageTypeKeyGenEcho:      true,
+	MessageTypePacked:          true,
+	MessageTypeKeyGenBlame:     true,
+	MessageTypeKeyGenConfirm:   true,
+	MessageTypeKeyGenComplaint: true,
+	MessageTypeEnroll:          false,
+	MessageTypeRepair1:         true,
+	MessageTypeRepair2:         false,
+	MessageTypeKeyGenBatch1:    true,
+	MessageTypeKeyGenBatch2:    false,
+}
+
+// IsValid returns true if t is the type of messages sent during a protocol, or a registered Extension.
+func (t MessageType) IsValid() bool {
+	if _, ok := broadcastTypes[t]; ok {
+		return true
+	}
+	return t.IsExtension()
+}
+
+// IsBroadcast returns true if messages of type t are sent to all parties, in which case their To field is 0.
+// Otherwise, each message is sent to the single party given by its To field.
+// This allows transports to route messages without inspecting their content.
+func (t MessageType) IsBroadcast() bool {
+	if broadcast, ok := broadcastTypes[t]; ok {
+		return broadcast
+	}
+	if ext, ok := lookupExtension(t); ok {
+		return ext.Broadcast
+	}
+	return false
+}
+
+// String implements fmt.Stringer
+func (t MessageType) String() string {
+	if name, ok := messageTypeNames[t]; ok {
+		return name
+	}
+	if ext, ok := lookupExtension(t); ok {
+		return ext.Name
+	}
+	return fmt.Sprintf("MessageType(%d)", uint8(t))
+}
+
+// AppendBinary appends the binary encoding of m to dst and returns the extended slice.
+// Callers can reuse buffers across messages: no allocation is performed if dst has a capacity of
+// at least Size() additional bytes, except for the content of Extension messages.
+func (m *Message) AppendBinary(dst []byte) ([]byte, error) {
+	dst, err := m.Header.AppendBinary(dst)
 	if err != nil {
-		return nil, fmt.Errorf("message.BytesAppend: %w", err)
+		return nil, fmt.Errorf("message.AppendBinary: %w", err)
 	}
+	return m.appendContent(dst)
+}
 
+// appendContent appends the encoding of the content of m, whose type is given by the header, to dst.
+func (m *Message) appendContent(dst []byte) ([]byte, error) {
 	switch m.Type {
 	case MessageTypeKeyGen1:
 		if m.KeyGen1 != nil {
-			return m.KeyGen1.BytesAppend(existing)
+			return m.KeyGen1.AppendBinary(dst)
 		}
 	case MessageTypeKeyGen2:
 		if m.KeyGen2 != nil {
-			return m.KeyGen2.BytesAppend(existing)
+			return m.KeyGen2.AppendBinary(dst)
 		}
 	case MessageTypeSign1:
 		if m.Sign1 != nil {
-			return m.Sign1.BytesAppend(existing)
+			return m.Sign1.AppendBinary(dst)
 		}
 	case MessageTypeSign2:
 		if m.Sign2 != nil {
-			return m.Sign2.BytesAppend(existing)
+			return m.Sign2.AppendBinary(dst)
+		}
+	case MessageTypeKeyGenEcho:
+		if m.KeyGenEcho != nil {
+			return m.KeyGenEcho.AppendBinary(dst)
+		}
+	case MessageTypePacked:
+		if m.Packed != nil {
+			return m.Packed.AppendBinary(dst)
+		}
+	case MessageTypeKeyGenBlame:
+		if m.KeyGenBlame != nil {
+			return m.KeyGenBlame.AppendBinary(dst)
+		}
+	case MessageTypeKeyGenConfirm:
+		if m.KeyGenConfirm != nil {
+			return m.KeyGenConfirm.AppendBinary(dst)
+		}
+	case MessageTypeKeyGenComplaint:
+		if m.KeyGenComplaint != nil {
+			return m.KeyGenComplaint.AppendBinary(dst)
+		}
+	case MessageTypeEnroll:
+		if m.Enroll != nil {
+			return m.Enroll.AppendBinary(dst)
+		}
+	case MessageTypeRepair1:
+		if m.Repair1 != nil {
+			return m.Repair1.AppendBinary(dst)
+		}
+	case MessageTypeRepair2:
+		if m.Repair2 != nil {
+			return m.Repair2.AppendBinary(dst)
+		}
+	case MessageTypeKeyGenBatch1:
+		if m.KeyGenBatch1 != nil {
+			return m.KeyGenBatch1.AppendBinary(dst)
+		}
+	case MessageTypeKeyGenBatch2:
+		if m.KeyGenBatch2 != nil {
+			return m.KeyGenBatch2.AppendBinary(dst)
+		}
+	default:
+		if m.Extension != nil {
+			content, err := m.Extension.MarshalBinary()
+			if err != nil {
+				return nil, fmt.Errorf("message.AppendBinary: %s: %w", m.Type, err)
+			}
+			return append(dst, content...), nil
 		}
 	}
 
 	return nil, errors.New("message does not contain any data")
 }
 
+// BytesAppend is the same as AppendBinary.
+func (m *Message) BytesAppend(existing []byte) (data []byte, err error) {
+	return m.AppendBinary(existing)
+}
+
+// Size returns the exact length of the binary encoding of m,
+// so that transports can allocate or frame a buffer before calling AppendBinary.
 func (m *Message) Size() int {
 	var size int
 	switch m.Type {
@@ -73,24 +255,107 @@ func (m *Message) Size() int {
 		if m.Sign2 != nil {
 			size = m.Sign2.Size()
 		}
+	case MessageTypeKeyGenEcho:
+		if m.KeyGenEcho != nil {
+			size = m.KeyGenEcho.Size()
+		}
+	case MessageTypePacked:
+		if m.Packed != nil {
+			size = m.Packed.Size()
+		}
+	case MessageTypeKeyGenBlame:
+		if m.KeyGenBlame != nil {
+			size = m.KeyGenBlame.Size()
+		}
+	case MessageTypeKeyGenConfirm:
+		if m.KeyGenConfirm != nil {
+			size = m.KeyGenConfirm.Size()
+		}
+	case MessageTypeKeyGenComplaint:
+		if m.KeyGenComplaint != nil {
+			size = m.KeyGenComplaint.Size()
+		}
+	case MessageTypeEnroll:
+		if m.Enroll != nil {
+			size = m.Enroll.Size()
+		}
+	case MessageTypeRepair1:
+		if m.Repair1 != nil {
+			size = m.Repair1.Size()
+		}
+	case MessageTypeRepair2:
+		if m.Repair2 != nil {
+			size = m.Repair2.Size()
+		}
+	case MessageTypeKeyGenBatch1:
+		if m.KeyGenBatch1 != nil {
+			size = m.KeyGenBatch1.Size()
+		}
+	case MessageTypeKeyGenBatch2:
+		if m.KeyGenBatch2 != nil {
+			size = m.KeyGenBatch2.Size()
+		}
+	default:
+		if m.Extension != nil {
+			if content, err := m.Extension.MarshalBinary(); err == nil {
+				size = len(content)
+			}
+		}
 	}
 	return m.Header.Size() + size
 }
 
 // MarshalBinary implements the encoding.BinaryMarshaler interface.
 func (m *Message) MarshalBinary() ([]byte, error) {
-	buf := make([]byte, 0, m.Size())
-	return m.BytesAppend(buf)
+	return m.AppendBinary(make([]byte, 0, m.Size()))
 }
 
 // UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
-func (m *Message) UnmarshalBinary(data []byte) error {
-	var err error
+// Messages of version 1, whose party IDs are 2 bytes long, are also decoded.
+// Messages with another encoding version are rejected with an error wrapping a *VersionError.
+func (m *Message) UnmarshalBinary(data []byte) (err error) {
+	defer recoverPanic(&err)
+
+	content, err := m.Header.unmarshal(data)
+	if err != nil {
+		return err
+	}
+	return m.unmarshalContent(content)
+}
+
+// GobEncode implements the gob.GobEncoder interface, using the binary encoding,
+// since the content of messages contains unexported values which gob cannot encode.
+func (m *Message) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+// The message is validated in the same way as in UnmarshalBinary.
+func (m *Message) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
+}
 
-	if err = m.Header.UnmarshalBinary(data); err != nil {
+// UnmarshalBinaryLegacy decodes a message in the legacy encoding, whose header type ∥ from ∥ to has no magic string,
+// no version and no session ID, and in which party IDs are encoded in 2 bytes.
+// The session ID of the message is the zero SessionID, so it can only be handled by a State created without WithSessionID.
+// It allows receiving messages from parties using an earlier version of this library during a migration,
+// and will be removed in a future version.
+func (m *Message) UnmarshalBinaryLegacy(data []byte) (err error) {
+	defer recoverPanic(&err)
+
+	if err := m.Header.unmarshalLegacy(data); err != nil {
 		return err
 	}
-	data = data[m.Header.Size():]
+	content, err := widenContent(m.Type, data[legacyHeaderSize:])
+	if err != nil {
+		return err
+	}
+	return m.unmarshalContent(content)
+}
+
+// unmarshalContent decodes the content of the message, whose type is given by the header.
+func (m *Message) unmarshalContent(data []byte) error {
+	var err error
 
 	switch m.Type {
 	case MessageTypeKeyGen1:
@@ -115,11 +380,69 @@ func (m *Message) UnmarshalBinary(data []byte) error {
 		if err = sign2.UnmarshalBinary(data); err == nil {
 			m.Sign2 = &sign2
 		}
+	case MessageTypeKeyGenEcho:
+		var echo KeyGenEcho
+		if err = echo.UnmarshalBinary(data); err == nil {
+			m.KeyGenEcho = &echo
+		}
+	case MessageTypePacked:
+		var packed Packed
+		if err = packed.UnmarshalBinary(data); err == nil {
+			m.Packed = &packed
+		}
+	case MessageTypeKeyGenBlame:
+		var blame KeyGenBlame
+		if err = blame.UnmarshalBinary(data); err == nil {
+			m.KeyGenBlame = &blame
+		}
+	case MessageTypeKeyGenConfirm:
+		var confirm KeyGenConfirm
+		if err = confirm.UnmarshalBinary(data); err == nil {
+			m.KeyGenConfirm = &confirm
+		}
+	case MessageTypeKeyGenComplaint:
+		var complaint KeyGenComplaint
+		if err = complaint.UnmarshalBinary(data); err == nil {
+			m.KeyGenComplaint = &complaint
+		}
+	case MessageTypeEnroll:
+		var enroll Enroll
+		if err = enroll.UnmarshalBinary(data); err == nil {
+			m.Enroll = &enroll
+		}
+	case MessageTypeRepair1:
+		var repair1 Repair1
+		if err = repair1.UnmarshalBinary(data); err == nil {
+			m.Repair1 = &repair1
+		}
+	case MessageTypeRepair2:
+		var repair2 Repair2
+		if err = repair2.UnmarshalBinary(data); err == nil {
+			m.Repair2 = &repair2
+		}
+	case MessageTypeKeyGenBatch1:
+		var batch1 KeyGenBatch1
+		if err = batch1.UnmarshalBinary(data); err == nil {
+			m.KeyGenBatch1 = &batch1
+		}
+	case MessageTypeKeyGenBatch2:
+		var batch2 KeyGenBatch2
+		if err = batch2.UnmarshalBinary(data); err == nil {
+			m.KeyGenBatch2 = &batch2
+		}
 	default:
-		return errors.New("messages.UnmarshalBinary: invalid message type")
+		ext, ok := lookupExtension(m.Type)
+		if !ok {
+			return errors.New("messages.UnmarshalBinary: invalid message type")
+		}
+		var content encoding.BinaryMarshaler
+		if content, err = ext.Unmarshal(data); err != nil {
+			return &FieldError{Field: "Extension", Err: err}
+		}
+		m.Extension = content
 	}
 
-	return nil
+	return err
 }
 
 func (m *Message) Equal(other interface{}) bool {
@@ -149,6 +472,229 @@ func (m *Message) Equal(other interface{}) bool {
 		if m.Sign2 != nil && otherMsg.Sign2 != nil {
 			return m.Sign2.Equal(otherMsg.Sign2)
 		}
+	case MessageTypeKeyGenEcho:
+		if m.KeyGenEcho != nil && otherMsg.KeyGenEcho != nil {
+			return m.KeyGenEcho.Equal(otherMsg.KeyGenEcho)
+		}
+	case MessageTypePacked:
+		if m.Packed != nil && otherMsg.Packed != nil {
+			return m.Packed.Equal(otherMsg.Packed)
+		}
+	case MessageTypeKeyGenBlame:
+		if m.KeyGenBlame != nil && otherMsg.KeyGenBlame != nil {
+			return m.KeyGenBlame.Equal(otherMsg.KeyGenBlame)
+		}
+	case MessageTypeKeyGenConfirm:
+		if m.KeyGenConfirm != nil && otherMsg.KeyGenConfirm != nil {
+			return m.KeyGenConfirm.Equal(otherMsg.KeyGenConfirm)
+		}
+	case MessageTypeKeyGenComplaint:
+		if m.KeyGenComplaint != nil && otherMsg.KeyGenComplaint != nil {
+			return m.KeyGenComplaint.Equal(otherMsg.KeyGenComplaint)
+		}
+	case MessageTypeEnroll:
+		if m.Enroll != nil && otherMsg.Enroll != nil {
+			return m.Enroll.Equal(otherMsg.Enroll)
+		}
+	case MessageTypeRepair1:
+		if m.Repair1 != nil && otherMsg.Repair1 != nil {
+			return m.Repair1.Equal(otherMsg.Repair1)
+		}
+	case MessageTypeRepair2:
+		if m.Repair2 != nil && otherMsg.Repair2 != nil {
+			return m.Repair2.Equal(otherMsg.Repair2)
+		}
+	case MessageTypeKeyGenBatch1:
+		if m.KeyGenBatch1 != nil && otherMsg.KeyGenBatch1 != nil {
+			return m.KeyGenBatch1.Equal(otherMsg.KeyGenBatch1)
+		}
+	case MessageTypeKeyGenBatch2:
+		if m.KeyGenBatch2 != nil && otherMsg.KeyGenBatch2 != nil {
+			return m.KeyGenBatch2.Equal(otherMsg.KeyGenBatch2)
+		}
+	default:
+		if m.Extension != nil && otherMsg.Extension != nil {
+			content, err1 := m.Extension.MarshalBinary()
+			otherContent, err2 := otherMsg.Extension.MarshalBinary()
+			return err1 == nil && err2 == nil && bytes.Equal(content, otherContent)
+		}
 	}
 	return false
 }
+
+// messageTypeNames contains the names of the message types used in the JSON encoding.
+var messageTypeNames = map[MessageType]string{
+	MessageTypeKeyGen1: "keygen1",
+	MessageTypeKeyGen2: "keygen2",
+	MessageTypeSign1:   "sign1",
+	MessageTypeSign2:   "sign2",
+
+	MessageTypeKeyGenEcho:      "keygen_echo",
+	MessageTypePacked:          "packed",
+	MessageTypeKeyGenBlame:     "keygen_blame",
+	MessageTypeKeyGenConfirm:   "keygen_confirm",
+	MessageTypeKeyGenComplaint: "keygen_complaint",
+	MessageTypeEnroll:          "enroll",
+	MessageTypeRepair1:         "repair1",
+	MessageTypeRepair2:         "repair2",
+	MessageTypeKeyGenBatch1:    "keygen_batch1",
+	MessageTypeKeyGenBatch2:    "keygen_batch2",
+}
+
+type jsonMessage struct {
+	Type      string   `json:"type"`
+	From      uint32   `json:"from"`
+	To        uint32   `json:"to"`
+	SessionID string   `json:"session_id"`
+	KeyGen1   *KeyGen1 `json:"keygen1,omitempty"`
+	KeyGen2   *KeyGen2 `json:"keygen2,omitempty"`
+	Sign1     *Sign1   `json:"sign1,omitempty"`
+	Sign2     *Sign2   `json:"sign2,omitempty"`
+
+	KeyGenEcho      *KeyGenEcho      `json:"keygen_echo,omitempty"`
+	Packed          *Packed          `json:"packed,omitempty"`
+	KeyGenBlame     *KeyGenBlame     `json:"keygen_blame,omitempty"`
+	KeyGenConfirm   *KeyGenConfirm   `json:"keygen_confirm,omitempty"`
+	KeyGenComplaint *KeyGenComplaint `json:"keygen_complaint,omitempty"`
+	Enroll          *Enroll          `json:"enroll,omitempty"`
+	Repair1         *Repair1         `json:"repair1,omitempty"`
+	Repair2         *Repair2         `json:"repair2,omitempty"`
+	KeyGenBatch1    *KeyGenBatch1    `json:"keygen_batch1,omitempty"`
+	KeyGenBatch2    *KeyGenBatch2    `json:"keygen_batch2,omitempty"`
+	Extension       *string          `json:"extension,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// The type is given by name, and the content of the message is stored in the field with the same name.
+// The content of an Extension is stored in hexadecimal in the "extension" field.
+// Points and scalars are encoded in lowercase hexadecimal, using the same encoding as MarshalBinary.
+func (m *Message) MarshalJSON() ([]byte, error) {
+	// Perform the same checks as MarshalBinary
+	if _, err := m.MarshalBinary(); err != nil {
+		return nil, fmt.Errorf("message.MarshalJSON: %w", err)
+	}
+	out := jsonMessage{
+		Type:      m.Type.String(),
+		From:      uint32(m.From),
+		To:        uint32(m.To),
+		SessionID: encodeHex(m.SessionID[:]),
+	}
+	switch m.Type {
+	case MessageTypeKeyGen1:
+		out.KeyGen1 = m.KeyGen1
+	case MessageTypeKeyGen2:
+		out.KeyGen2 = m.KeyGen2
+	case MessageTypeSign1:
+		out.Sign1 = m.Sign1
+	case MessageTypeSign2:
+		out.Sign2 = m.Sign2
+	case MessageTypeKeyGenEcho:
+		out.KeyGenEcho = m.KeyGenEcho
+	case MessageTypePacked:
+		out.Packed = m.Packed
+	case MessageTypeKeyGenBlame:
+		out.KeyGenBlame = m.KeyGenBlame
+	case MessageTypeKeyGenConfirm:
+		out.KeyGenConfirm = m.KeyGenConfirm
+	case MessageTypeKeyGenComplaint:
+		out.KeyGenComplaint = m.KeyGenComplaint
+	case MessageTypeEnroll:
+		out.Enroll = m.Enroll
+	case MessageTypeRepair1:
+		out.Repair1 = m.Repair1
+	case MessageTypeRepair2:
+		out.Repair2 = m.Repair2
+	case MessageTypeKeyGenBatch1:
+		out.KeyGenBatch1 = m.KeyGenBatch1
+	case MessageTypeKeyGenBatch2:
+		out.KeyGenBatch2 = m.KeyGenBatch2
+	default:
+		content, err := m.Extension.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("message.MarshalJSON: %w", err)
+		}
+		extension := encodeHex(content)
+		out.Extension = &extension
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The message is validated in the same way as in UnmarshalBinary, so that the result can be marshalled back
+// to identical JSON and binary encodings.
+func (m *Message) UnmarshalJSON(data []byte) (err error) {
+	defer recoverPanic(&err)
+
+	var out jsonMessage
+	if err := json.Unmarshal(data, &out); err != nil {
+		return fmt.Errorf("messages.UnmarshalJSON: %w", err)
+	}
+
+	msg := Message{
+		Header: Header{
+			From: party.ID(out.From),
+			To:   party.ID(out.To),
+		},
+		KeyGen1: out.KeyGen1,
+		KeyGen2: out.KeyGen2,
+		Sign1:   out.Sign1,
+		Sign2:   out.Sign2,
+
+		KeyGenEcho:      out.KeyGenEcho,
+		Packed:          out.Packed,
+		KeyGenBlame:     out.KeyGenBlame,
+		KeyGenConfirm:   out.KeyGenConfirm,
+		KeyGenComplaint: out.KeyGenComplaint,
+		Enroll:          out.Enroll,
+		Repair1:         out.Repair1,
+		Repair2:         out.Repair2,
+		KeyGenBatch1:    out.KeyGenBatch1,
+		KeyGenBatch2:    out.KeyGenBatch2,
+	}
+	extensionsMtx.RLock()
+	msg.Type, _ = typeByName(out.Type)
+	extensionsMtx.RUnlock()
+	if out.Extension != nil {
+		if len(*out.Extension)%2 != 0 {
+			return fmt.Errorf("messages.UnmarshalJSON: extension: %w", ErrInvalidMessage)
+		}
+		content, err := decodeHex(*out.Extension, len(*out.Extension)/2)
+		if err != nil {
+			return fmt.Errorf("messages.UnmarshalJSON: extension: %w", err)
+		}
+		ext, ok := lookupExtension(msg.Type)
+		if !ok {
+			return fmt.Errorf("messages.UnmarshalJSON: extension content for type %q: %w", out.Type, ErrInvalidMessage)
+		}
+		if msg.Extension, err = ext.Unmarshal(content); err != nil {
+			return fmt.Errorf("messages.UnmarshalJSON: %w", &FieldError{Field: "Extension", Err: err})
+		}
+	}
+	sessionID, err := decodeHex(out.SessionID, SessionIDSize)
+	if err != nil {
+		return fmt.Errorf("messages.UnmarshalJSON: session ID: %w", err)
+	}
+	copy(msg.SessionID[:], sessionID)
+
+	// Only the content corresponding to the type may be present
+	var count int
+	for _, present := range []bool{msg.KeyGen1 != nil, msg.KeyGen2 != nil, msg.Sign1 != nil, msg.Sign2 != nil, msg.KeyGenEcho != nil, msg.Packed != nil, msg.KeyGenBlame != nil, msg.KeyGenConfirm != nil,
+		msg.KeyGenComplaint != nil, msg.Enroll != nil, msg.Repair1 != nil, msg.Repair2 != nil,
+		msg.KeyGenBatch1 != nil, msg.KeyGenBatch2 != nil, msg.Extension != nil} {
+		if present {
+			count++
+		}
+	}
+	if count != 1 {
+		return fmt.Errorf("messages.UnmarshalJSON: %w", ErrInvalidMessage)
+	}
+
+	// The header is validated when encoding it, and the content was already validated when decoding it.
+	// The encoding also fails if the content does not correspond to the type.
+	if _, err = msg.MarshalBinary(); err != nil {
+		return fmt.Errorf("messages.UnmarshalJSON: %w", err)
+	}
+
+	*m = msg
+	return nil
+}
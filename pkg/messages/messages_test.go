@@ -0,0 +1,58 @@
+package messages
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func FuzzMessage_UnmarshalBinary(f *testing.F) {
+	for _, msg := range testMessages() {
+		data, err := msg.MarshalBinary()
+		require.NoError(f, err)
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var msg Message
+		if err := msg.UnmarshalBinary(data); err != nil {
+			return
+		}
+		// The binary encoding is canonical
+		encoded, err := msg.MarshalBinary()
+		require.NoError(t, err)
+		assert.Equal(t, data, encoded)
+	})
+}
+
+func TestMessage_Gob(t *testing.T) {
+	type archive struct {
+		Round    int
+		Messages []*Message
+	}
+	in := archive{Round: 1, Messages: testMessages()}
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(&in))
+	data := buf.Bytes()
+
+	var out archive
+	require.NoError(t, gob.NewDecoder(bytes.NewReader(data)).Decode(&out))
+	assert.Equal(t, in.Round, out.Round)
+	require.Len(t, out.Messages, len(in.Messages))
+	for i, msg := range in.Messages {
+		assert.True(t, msg.Equal(out.Messages[i]), "message %d differs", i)
+	}
+
+	// The last message is a packed KeyGen2, whose last 32 bytes are a scalar
+	var single bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&single).Encode(in.Messages[len(in.Messages)-1]))
+	corrupted := single.Bytes()
+	for i := len(corrupted) - 32; i < len(corrupted); i++ {
+		corrupted[i] = 0xff
+	}
+	var msg Message
+	assert.Error(t, gob.NewDecoder(bytes.NewReader(corrupted)).Decode(&msg))
+}
@@ -0,0 +1,318 @@
+package messages
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+// ErrNotRecipient is returned by Message.Unpack when the party is not one of the recipients of a Packed message.
+var ErrNotRecipient = errors.New("party is not a recipient of the packed message")
+
+// sizePackedHeader is the size of the inner type and the number of recipients of a Packed message,
+// and sizePackedEntry the size of the ID and the length which precede each payload.
+const (
+	sizePackedHeader = 1 + 2
+	sizePackedEntry  = party.IDByteSize + 2
+)
+
+// Packed contains the content of several unicast messages of the same type, sent by the same party to different
+// recipients. It is broadcast, so that a transport can deliver a single message instead of one per recipient,
+// and each recipient extracts its own content with Message.Unpack.
+//
+// Its binary encoding is:
+//
+//	type (1 byte) ∥ n (2 bytes) ∥ n × (to (4 bytes) ∥ length (2 bytes) ∥ payload)
+//
+// where each payload is the encoding of the content of a message of the inner type.
+type Packed struct {
+	// Type is the type of the packed messages, which must be a unicast type of the protocol.
+	Type MessageType
+
+	// To contains the recipients in increasing order.
+	To []party.ID
+
+	// Payloads contains the encoded content of the message for each recipient in To.
+	Payloads [][]byte
+}
+
+// NewPacked packs msgs, which must be unicast messages of the same type, from the same party and in the same session,
+// into a single message. The recipients must be different, and are sorted.
+// The content of msgs is encoded, so that they can be modified afterwards.
+func NewPacked(msgs []*Message) (*Message, error) {
+	if len(msgs) == 0 {
+		return nil, errors.New("messages.NewPacked: no messages")
+	}
+	first := msgs[0]
+	sorted := make([]*Message, len(msgs))
+	copy(sorted, msgs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].To < sorted[j].To })
+
+	packed := &Packed{
+		Type:     first.Type,
+		To:       make([]party.ID, 0, len(msgs)),
+		Payloads: make([][]byte, 0, len(msgs)),
+	}
+	for i, msg := range sorted {
+		if msg.Type != first.Type || msg.From != first.From || msg.SessionID != first.SessionID {
+			return nil, errors.New("messages.NewPacked: messages must have the same type, sender and session")
+		}
+		if i > 0 && msg.To == sorted[i-1].To {
+			return nil, fmt.Errorf("messages.NewPacked: duplicate recipient %d", msg.To)
+		}
+		payload, err := msg.appendContent(nil)
+		if err != nil {
+			return nil, fmt.Errorf("messages.NewPacked: %w", err)
+		}
+		packed.To = append(packed.To, msg.To)
+		packed.Payloads = append(packed.Payloads, payload)
+	}
+	if err := packed.validate(); err != nil {
+		return nil, fmt.Errorf("messages.NewPacked: %w", err)
+	}
+	return &Message{
+		Header: Header{
+			Type:      MessageTypePacked,
+			From:      first.From,
+			SessionID: first.SessionID,
+		},
+		Packed: packed,
+	}, nil
+}
+
+// Unpack returns the message packed in m for the party to.
+// It returns an error wrapping ErrNotRecipient if to is not a recipient.
+func (m *Message) Unpack(to party.ID) (*Message, error) {
+	if m.Type != MessageTypePacked || m.Packed == nil {
+		return nil, errors.New("messages.Unpack: message is not packed")
+	}
+	i := sort.Search(len(m.Packed.To), func(i int) bool { return m.Packed.To[i] >= to })
+	if i == len(m.Packed.To) || m.Packed.To[i] != to {
+		return nil, fmt.Errorf("messages.Unpack: party %d: %w", to, ErrNotRecipient)
+	}
+	return m.Packed.unpack(m.Header, i)
+}
+
+// Split returns the messages packed in m, ordered by recipient.
+func (m *Message) Split() ([]*Message, error) {
+	if m.Type != MessageTypePacked || m.Packed == nil {
+		return nil, errors.New("messages.Split: message is not packed")
+	}
+	msgs := make([]*Message, len(m.Packed.To))
+	for i := range m.Packed.To {
+		msg, err := m.Packed.unpack(m.Header, i)
+		if err != nil {
+			return nil, fmt.Errorf("messages.Split: %w", err)
+		}
+		msgs[i] = msg
+	}
+	return msgs, nil
+}
+
+// unpack decodes the i-th packed message, whose header is derived from the header h of the Packed message.
+func (m *Packed) unpack(h Header, i int) (*Message, error) {
+	msg := &Message{Header: Header{
+		Type:      m.Type,
+		From:      h.From,
+		To:        m.To[i],
+		SessionID: h.SessionID,
+	}}
+	if err := msg.unmarshalContent(m.Payloads[i]); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// validate checks that the inner type is a unicast type of the protocol, that the recipients are valid and sorted,
+// and that every payload is a valid content for the inner type.
+func (m *Packed) validate() error {
+	if err := m.validateRecipients(); err != nil {
+		return err
+	}
+	for _, payload := range m.Payloads {
+		msg := Message{Header: Header{Type: m.Type}}
+		if err := msg.unmarshalContent(payload); err != nil {
+			return &FieldError{Field: "Packed.Payloads", Err: err}
+		}
+	}
+	return nil
+}
+
+// validateRecipients performs the checks of validate which do not require decoding the payloads.
+func (m *Packed) validateRecipients() error {
+	if _, ok := broadcastTypes[m.Type]; !ok || m.Type.IsBroadcast() {
+		return &FieldError{Field: "Packed.Type", Err: fmt.Errorf("%s is not a unicast message type", m.Type)}
+	}
+	if len(m.To) == 0 || len(m.To) > math.MaxUint16 {
+		return &FieldError{Field: "Packed.To", Err: fmt.Errorf("invalid number of recipients %d", len(m.To))}
+	}
+	if len(m.Payloads) != len(m.To) {
+		return &FieldError{Field: "Packed.Payloads", Err: fmt.Errorf("expected %d payloads (got %d)", len(m.To), len(m.Payloads))}
+	}
+	for i, to := range m.To {
+		if to == 0 {
+			return &FieldError{Field: "Packed.To", Err: errors.New("recipient cannot be 0")}
+		}
+		if i > 0 && to <= m.To[i-1] {
+			return &FieldError{Field: "Packed.To", Err: errors.New("recipients must be unique and in increasing order")}
+		}
+		if len(m.Payloads[i]) > math.MaxUint16 {
+			return &FieldError{Field: "Packed.Payloads", Err: errors.New("payload is too long")}
+		}
+	}
+	return nil
+}
+
+// AppendBinary appends the encoding of m to dst and returns the extended slice.
+// It does not allocate if dst has a capacity of at least Size() additional bytes.
+// The payloads are not decoded, since they are validated by NewPacked and when decoding.
+func (m *Packed) AppendBinary(dst []byte) ([]byte, error) {
+	if err := m.validateRecipients(); err != nil {
+		return nil, fmt.Errorf("Packed.AppendBinary: %w", err)
+	}
+	dst = append(dst, byte(m.Type))
+	dst = append(dst, byte(len(m.To)>>8), byte(len(m.To)))
+	for i, to := range m.To {
+		length := len(m.Payloads[i])
+		dst = appendID(dst, to)
+		dst = append(dst, byte(length>>8), byte(length))
+		dst = append(dst, m.Payloads[i]...)
+	}
+	return dst, nil
+}
+
+// BytesAppend is the same as AppendBinary.
+func (m *Packed) BytesAppend(existing []byte) ([]byte, error) {
+	return m.AppendBinary(existing)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (m *Packed) MarshalBinary() ([]byte, error) {
+	return m.AppendBinary(make([]byte, 0, m.Size()))
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (m *Packed) UnmarshalBinary(data []byte) error {
+	if len(data) < sizePackedHeader {
+		return &FieldError{Field: "Packed.To", Err: errors.New("data is too short")}
+	}
+	out := Packed{Type: MessageType(data[0])}
+	n := int(binary.BigEndian.Uint16(data[1:]))
+	data = data[sizePackedHeader:]
+	if n == 0 {
+		return &FieldError{Field: "Packed.To", Err: errors.New("no recipients")}
+	}
+	// every entry is at least sizePackedEntry bytes long, which bounds the allocation by the size of data
+	if n > len(data)/sizePackedEntry {
+		return &FieldError{Field: "Packed.Payloads", Err: fmt.Errorf("data is too short for %d recipients", n)}
+	}
+	out.To = make([]party.ID, 0, n)
+	out.Payloads = make([][]byte, 0, n)
+	for i := 0; i < n; i++ {
+		if len(data) < sizePackedEntry {
+			return &FieldError{Field: "Packed.Payloads", Err: errors.New("data is too short")}
+		}
+		to := readID(data, party.IDByteSize)
+		length := int(binary.BigEndian.Uint16(data[party.IDByteSize:]))
+		data = data[sizePackedEntry:]
+		if len(data) < length {
+			return &FieldError{Field: "Packed.Payloads", Err: errors.New("data is too short")}
+		}
+		out.To = append(out.To, to)
+		out.Payloads = append(out.Payloads, append([]byte(nil), data[:length]...))
+		data = data[length:]
+	}
+	if len(data) != 0 {
+		return &FieldError{Field: "Packed.Payloads", Err: fmt.Errorf("%d bytes of trailing data", len(data))}
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+	*m = out
+	return nil
+}
+
+func (m *Packed) Size() int {
+	size := sizePackedHeader
+	for _, payload := range m.Payloads {
+		size += sizePackedEntry + len(payload)
+	}
+	return size
+}
+
+func (m *Packed) Equal(other interface{}) bool {
+	otherMsg, ok := other.(*Packed)
+	if !ok {
+		return false
+	}
+	if m.Type != otherMsg.Type || len(m.To) != len(otherMsg.To) || len(m.Payloads) != len(otherMsg.Payloads) {
+		return false
+	}
+	for i := range m.To {
+		if m.To[i] != otherMsg.To[i] {
+			return false
+		}
+	}
+	for i := range m.Payloads {
+		if string(m.Payloads[i]) != string(otherMsg.Payloads[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+type jsonPacked struct {
+	Type     string     `json:"type"`
+	To       []party.ID `json:"to"`
+	Payloads []string   `json:"payloads"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// The payloads are encoded in lowercase hexadecimal.
+func (m *Packed) MarshalJSON() ([]byte, error) {
+	out := jsonPacked{
+		Type:     m.Type.String(),
+		To:       m.To,
+		Payloads: make([]string, len(m.Payloads)),
+	}
+	for i, payload := range m.Payloads {
+		out.Payloads[i] = encodeHex(payload)
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (m *Packed) UnmarshalJSON(data []byte) error {
+	var out jsonPacked
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	extensionsMtx.RLock()
+	t, _ := typeByName(out.Type)
+	extensionsMtx.RUnlock()
+	packed := Packed{
+		Type:     t,
+		To:       out.To,
+		Payloads: make([][]byte, len(out.Payloads)),
+	}
+	for i, payload := range out.Payloads {
+		if len(payload)%2 != 0 {
+			return fmt.Errorf("packed.Payloads: %w", ErrInvalidMessage)
+		}
+		b, err := decodeHex(payload, len(payload)/2)
+		if err != nil {
+			return fmt.Errorf("packed.Payloads: %w", err)
+		}
+		packed.Payloads[i] = b
+	}
+	if err := packed.validate(); err != nil {
+		return err
+	}
+	*m = packed
+	return nil
+}
@@ -0,0 +1,142 @@
+package messages
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+)
+
+func TestPacked_MarshalBinary(t *testing.T) {
+	from := party.ID(2)
+	sessionID := DeriveSessionID(party.IDSlice{1, 2, 3, 4}, []byte("nonce"))
+	msgs := []*Message{
+		NewKeyGen2(from, 4, scalar.NewScalarRandom()),
+		NewKeyGen2Sealed(from, 1, make([]byte, SizeSealedShare)),
+		NewKeyGen2(from, 3, scalar.NewScalarRandom()),
+	}
+	for _, msg := range msgs {
+		msg.SessionID = sessionID
+	}
+
+	packed, err := NewPacked(msgs)
+	require.NoError(t, err)
+	assert.True(t, packed.IsBroadcast())
+	assert.Equal(t, []party.ID{1, 3, 4}, packed.Packed.To)
+
+	var msg2 Message
+	require.NoError(t, CheckFROSTMarshaler(packed, &msg2))
+	assert.True(t, msg2.Equal(packed), "messages are not equal")
+
+	for _, msg := range msgs {
+		unpacked, err := msg2.Unpack(msg.To)
+		require.NoError(t, err)
+		assert.True(t, unpacked.Equal(msg), "unpacked message is not equal")
+	}
+	_, err = msg2.Unpack(2)
+	assert.True(t, errors.Is(err, ErrNotRecipient), err)
+
+	split, err := msg2.Split()
+	require.NoError(t, err)
+	require.Len(t, split, len(msgs))
+	for i, to := range []party.ID{1, 3, 4} {
+		assert.Equal(t, to, split[i].To)
+	}
+}
+
+func TestNewPacked_Invalid(t *testing.T) {
+	share := scalar.NewScalarRandom()
+	tests := []struct {
+		name string
+		msgs []*Message
+	}{
+		{"empty", nil},
+		{"duplicate recipient", []*Message{NewKeyGen2(1, 2, share), NewKeyGen2(1, 2, share)}},
+		{"different senders", []*Message{NewKeyGen2(1, 2, share), NewKeyGen2(3, 4, share)}},
+		{"broadcast type", []*Message{NewSign2(1, share)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPacked(tt.msgs)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPacked_UnmarshalBinary_Invalid(t *testing.T) {
+	share := scalar.AppendBytes(nil, scalar.NewScalarRandom())
+	entry := func(to party.ID, payload []byte) []byte {
+		return append(append(to.Bytes(), byte(len(payload)>>8), byte(len(payload))), payload...)
+	}
+	packed := func(t MessageType, n int, entries ...[]byte) []byte {
+		data := []byte{byte(t), byte(n >> 8), byte(n)}
+		for _, e := range entries {
+			data = append(data, e...)
+		}
+		return data
+	}
+
+	var valid Packed
+	require.NoError(t, valid.UnmarshalBinary(packed(MessageTypeKeyGen2, 2, entry(1, share), entry(3, share))))
+
+	tests := []struct {
+		name  string
+		data  []byte
+		field string
+	}{
+		{"no recipients", packed(MessageTypeKeyGen2, 0), "Packed.To"},
+		{"zero recipient", packed(MessageTypeKeyGen2, 1, entry(0, share)), "Packed.To"},
+		{"duplicate recipients", packed(MessageTypeKeyGen2, 2, entry(1, share), entry(1, share)), "Packed.To"},
+		{"unsorted recipients", packed(MessageTypeKeyGen2, 2, entry(3, share), entry(1, share)), "Packed.To"},
+		{"count mismatch", packed(MessageTypeKeyGen2, 3, entry(1, share), entry(3, share)), "Packed.Payloads"},
+		{"trailing data", append(packed(MessageTypeKeyGen2, 1, entry(1, share)), 0), "Packed.Payloads"},
+		{"invalid payload", packed(MessageTypeKeyGen2, 1, entry(1, share[:31])), "Packed.Payloads"},
+		{"broadcast type", packed(MessageTypeSign2, 1, entry(1, share)), "Packed.Type"},
+		{"nested", packed(MessageTypePacked, 1, entry(1, share)), "Packed.Type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Packed
+			err := m.UnmarshalBinary(tt.data)
+			var fieldErr *FieldError
+			require.True(t, errors.As(err, &fieldErr), err)
+			assert.Equal(t, tt.field, fieldErr.Field)
+		})
+	}
+}
+
+func TestLimits_Packed(t *testing.T) {
+	msgs := make([]*Message, 0, 3)
+	for _, to := range []party.ID{1, 3, 4} {
+		msgs = append(msgs, NewKeyGen2(2, to, scalar.NewScalarRandom()))
+	}
+	packed, err := NewPacked(msgs)
+	require.NoError(t, err)
+	data, err := packed.MarshalBinary()
+	require.NoError(t, err)
+
+	var msg Message
+	limits := Limits{Threshold: 1, Parties: 4}
+	assert.Equal(t, limits.MaxSize(MessageTypePacked), len(data))
+	require.NoError(t, limits.UnmarshalBinary(data, &msg))
+
+	// Too many recipients for the number of parties
+	var fieldErr *FieldError
+	err = (Limits{Threshold: 1, Parties: 3}).UnmarshalBinary(data, &msg)
+	require.True(t, errors.As(err, &fieldErr), err)
+	assert.Equal(t, "Message", fieldErr.Field)
+	assert.True(t, errors.As((Limits{Threshold: 1, Parties: 3}).Check(packed), &fieldErr))
+	assert.Equal(t, "Packed.To", fieldErr.Field)
+
+	// The packed messages are checked individually
+	err = (Limits{Threshold: 1, Parties: 4, EncryptedShares: true}).Check(packed)
+	require.True(t, errors.As(err, &fieldErr), err)
+	assert.Equal(t, "KeyGen2.SealedShare", fieldErr.Field)
+
+	// Packed messages are rejected if the number of parties is unknown
+	assert.Equal(t, 0, (Limits{Threshold: 1}).MaxSize(MessageTypePacked))
+	assert.Error(t, (Limits{Threshold: 1}).Check(packed))
+}
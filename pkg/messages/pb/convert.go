@@ -0,0 +1,154 @@
+package pb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+)
+
+// supportedTypes are the message types which the schema can represent.
+var supportedTypes = map[messages.MessageType]bool{
+	messages.MessageTypeKeyGen1:    true,
+	messages.MessageTypeKeyGen2:    true,
+	messages.MessageTypeSign1:      true,
+	messages.MessageTypeSign2:      true,
+	messages.MessageTypeKeyGenEcho: true,
+}
+
+// ToProto converts msg to its Protocol Buffers representation.
+// It fails if msg cannot be encoded with msg.MarshalBinary, or if it is not a KeyGen1, a KeyGen2, a Sign1, a Sign2
+// or a KeyGenEcho message, since the schema does not support the other types.
+// Packed messages can be sent as the messages returned by msg.Split.
+func ToProto(msg *messages.Message) (*Message, error) {
+	if !supportedTypes[msg.Type] {
+		return nil, fmt.Errorf("pb.ToProto: message type %s is not supported", msg.Type)
+	}
+	data, err := msg.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("pb.ToProto: %w", err)
+	}
+	content := data[msg.Header.Size():]
+
+	out := &Message{
+		Type: MessageType(msg.Type),
+		From: uint32(msg.From),
+		To:   uint32(msg.To),
+	}
+	// The default session ID is omitted
+	if msg.SessionID != (messages.SessionID{}) {
+		out.SessionId = append([]byte(nil), msg.SessionID[:]...)
+	}
+
+	switch msg.Type {
+	case messages.MessageTypeKeyGen1:
+		// proof S ∥ proof R ∥ degree ∥ commitments
+		commitments := content[64+party.IDByteSize:]
+		out.KeyGen1 = &KeyGen1{
+			ProofS:      content[:32],
+			ProofR:      content[32:64],
+			Commitments: make([][]byte, 0, len(commitments)/32),
+		}
+		if msg.KeyGen1.EncryptionKey != nil {
+			out.KeyGen1.EncryptionKey = commitments[len(commitments)-32:]
+			commitments = commitments[:len(commitments)-32]
+		}
+		for ; len(commitments) > 0; commitments = commitments[32:] {
+			out.KeyGen1.Commitments = append(out.KeyGen1.Commitments, commitments[:32])
+		}
+	case messages.MessageTypeKeyGen2:
+		if msg.KeyGen2.SealedShare != nil {
+			out.KeyGen2 = &KeyGen2{SealedShare: content}
+		} else {
+			out.KeyGen2 = &KeyGen2{Share: content}
+		}
+	case messages.MessageTypeSign1:
+		out.Sign1 = &Sign1{D: content[:32], E: content[32:]}
+	case messages.MessageTypeSign2:
+		out.Sign2 = &Sign2{Z: content}
+	case messages.MessageTypeKeyGenEcho:
+		out.KeyGenEcho = &KeyGenEcho{Digest: content}
+	}
+	return out, nil
+}
+
+// FromProto converts m to a messages.Message.
+// The message is validated in the same way as by messages.Message.UnmarshalBinary:
+// the type must be known and correspond to the content, points and scalars must be canonically encoded,
+// and the result is the same as decoding the binary encoding of the message.
+func FromProto(m *Message) (*messages.Message, error) {
+	if m.From > uint32(^party.ID(0)) || m.To > uint32(^party.ID(0)) {
+		return nil, errors.New("pb.FromProto: party ID is too large")
+	}
+	if len(m.SessionId) != 0 && len(m.SessionId) != messages.SessionIDSize {
+		return nil, fmt.Errorf("pb.FromProto: session ID must be %d bytes", messages.SessionIDSize)
+	}
+
+	// We reconstruct the binary encoding and decode it
+	header := messages.Header{
+		Type: messages.MessageType(m.Type),
+		From: party.ID(m.From),
+		To:   party.ID(m.To),
+	}
+	copy(header.SessionID[:], m.SessionId)
+	buf, err := header.BytesAppend(make([]byte, 0, 256))
+	if err != nil {
+		return nil, fmt.Errorf("pb.FromProto: %w", err)
+	}
+
+	var count int
+	for _, present := range []bool{m.KeyGen1 != nil, m.KeyGen2 != nil, m.Sign1 != nil, m.Sign2 != nil, m.KeyGenEcho != nil} {
+		if present {
+			count++
+		}
+	}
+	if count != 1 {
+		return nil, errors.New("pb.FromProto: exactly one content must be set")
+	}
+
+	var content [][]byte
+	switch {
+	case m.Type == MessageType_MESSAGE_TYPE_KEYGEN1 && m.KeyGen1 != nil:
+		commitments := m.KeyGen1.Commitments
+		if len(commitments) == 0 || len(commitments) > int(^party.Size(0))+1 {
+			return nil, errors.New("pb.FromProto: invalid number of commitments")
+		}
+		content = append(content, m.KeyGen1.ProofS, m.KeyGen1.ProofR, party.Size(len(commitments)-1).Bytes())
+		content = append(content, commitments...)
+		if len(m.KeyGen1.EncryptionKey) != 0 {
+			content = append(content, m.KeyGen1.EncryptionKey)
+		}
+	case m.Type == MessageType_MESSAGE_TYPE_KEYGEN2 && m.KeyGen2 != nil && len(m.KeyGen2.SealedShare) != 0:
+		if len(m.KeyGen2.Share) != 0 {
+			return nil, errors.New("pb.FromProto: both share and sealed_share are set")
+		}
+		if len(m.KeyGen2.SealedShare) != messages.SizeSealedShare {
+			return nil, fmt.Errorf("pb.FromProto: %w", messages.ErrInvalidMessage)
+		}
+		buf = append(buf, m.KeyGen2.SealedShare...)
+	case m.Type == MessageType_MESSAGE_TYPE_KEYGEN2 && m.KeyGen2 != nil:
+		content = append(content, m.KeyGen2.Share)
+	case m.Type == MessageType_MESSAGE_TYPE_SIGN1 && m.Sign1 != nil:
+		content = append(content, m.Sign1.D, m.Sign1.E)
+	case m.Type == MessageType_MESSAGE_TYPE_SIGN2 && m.Sign2 != nil:
+		content = append(content, m.Sign2.Z)
+	case m.Type == MessageType_MESSAGE_TYPE_KEYGEN_ECHO && m.KeyGenEcho != nil:
+		content = append(content, m.KeyGenEcho.Digest)
+	default:
+		return nil, fmt.Errorf("pb.FromProto: content does not match message type %d", m.Type)
+	}
+	for i, b := range content {
+		// Every element except the degree of the commitments is a point or a scalar
+		if len(b) != 32 && !(m.Type == MessageType_MESSAGE_TYPE_KEYGEN1 && i == 2) {
+			return nil, fmt.Errorf("pb.FromProto: %w", messages.ErrInvalidMessage)
+		}
+		buf = append(buf, b...)
+	}
+
+	var msg messages.Message
+	if err = msg.UnmarshalBinary(buf); err != nil {
+		return nil, fmt.Errorf("pb.FromProto: %w", err)
+	}
+	return &msg, nil
+}
@@ -0,0 +1,65 @@
+// Package pb provides the Protocol Buffers encoding of the protocol messages, defined in messages.proto.
+//
+// The types of this package mirror the schema, and implement the proto3 wire format without depending on
+// a Protocol Buffers runtime, so that users who do not need it are not forced to import one.
+// Applications using gRPC can generate their own types from messages.proto, which are compatible on the wire.
+// In both cases, ToProto and FromProto convert between these types and messages.Message.
+package pb
+
+// MessageType is the enum MessageType of messages.proto.
+type MessageType int32
+
+const (
+	MessageType_MESSAGE_TYPE_UNSPECIFIED MessageType = 0
+	MessageType_MESSAGE_TYPE_KEYGEN1     MessageType = 1
+	MessageType_MESSAGE_TYPE_KEYGEN2     MessageType = 2
+	MessageType_MESSAGE_TYPE_SIGN1       MessageType = 3
+	MessageType_MESSAGE_TYPE_SIGN2       MessageType = 4
+	MessageType_MESSAGE_TYPE_KEYGEN_ECHO MessageType = 5
+)
+
+// Message is the message Message of messages.proto.
+// At most one of KeyGen1, KeyGen2, Sign1, Sign2 and KeyGenEcho may be set, as they form the oneof content.
+type Message struct {
+	Type      MessageType
+	From      uint32
+	To        uint32
+	SessionId []byte
+
+	KeyGen1 *KeyGen1
+	KeyGen2 *KeyGen2
+	Sign1   *Sign1
+	Sign2   *Sign2
+
+	KeyGenEcho *KeyGenEcho
+}
+
+// KeyGen1 is the message KeyGen1 of messages.proto.
+type KeyGen1 struct {
+	ProofS        []byte
+	ProofR        []byte
+	Commitments   [][]byte
+	EncryptionKey []byte
+}
+
+// KeyGen2 is the message KeyGen2 of messages.proto.
+type KeyGen2 struct {
+	Share       []byte
+	SealedShare []byte
+}
+
+// Sign1 is the message Sign1 of messages.proto.
+type Sign1 struct {
+	D []byte
+	E []byte
+}
+
+// Sign2 is the message Sign2 of messages.proto.
+type Sign2 struct {
+	Z []byte
+}
+
+// KeyGenEcho is the message KeyGenEcho of messages.proto.
+type KeyGenEcho struct {
+	Digest []byte
+}
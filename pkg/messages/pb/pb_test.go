@@ -0,0 +1,173 @@
+package pb
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/polynomial"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/zk"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+func testMessages() []*messages.Message {
+	secret := scalar.NewScalarRandom()
+	poly := polynomial.NewPolynomial(3, secret)
+	comm := polynomial.NewPolynomialExponent(poly)
+	proof := zk.NewSchnorrProof(1, comm.Constant(), make([]byte, 32), poly.Constant())
+
+	D := new(ristretto.Element).ScalarBaseMult(scalar.NewScalarRandom())
+	E := new(ristretto.Element).ScalarBaseMult(scalar.NewScalarRandom())
+
+	msgs := []*messages.Message{
+		messages.NewKeyGen1(1, proof, comm),
+		messages.NewKeyGen2(1, 300, scalar.NewScalarRandom()),
+		messages.NewSign1(3, D, E),
+		messages.NewSign2(65535, scalar.NewScalarRandom()),
+		messages.NewKeyGenEcho(5, &[messages.SizeEchoDigest]byte{1, 2, 3}),
+	}
+	msgs[0].SessionID = messages.DeriveSessionID(party.IDSlice{1, 2, 3}, []byte("nonce"))
+	return msgs
+}
+
+// testEncryptedMessages returns a KeyGen1 and a KeyGen2 message of a keygen with encrypted shares.
+func testEncryptedMessages() []*messages.Message {
+	msgs := testMessages()[:2]
+	msgs[0].KeyGen1.EncryptionKey = new(ristretto.Element).ScalarBaseMult(scalar.NewScalarRandom())
+	sealed := bytes.Repeat([]byte{0xab}, messages.SizeSealedShare)
+	msgs[1] = messages.NewKeyGen2Sealed(1, 300, sealed)
+	return msgs
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, msg := range append(testMessages(), testEncryptedMessages()...) {
+		bin, err := msg.MarshalBinary()
+		require.NoError(t, err)
+
+		p, err := ToProto(msg)
+		require.NoError(t, err)
+		wire, err := p.Marshal()
+		require.NoError(t, err)
+
+		var p2 Message
+		require.NoError(t, p2.Unmarshal(wire))
+		assert.Equal(t, p, &p2)
+		wire2, err := p2.Marshal()
+		require.NoError(t, err)
+		assert.Equal(t, wire, wire2)
+
+		msg2, err := FromProto(&p2)
+		require.NoError(t, err)
+		assert.True(t, msg.Equal(msg2), "messages are not equal")
+
+		var expected messages.Message
+		require.NoError(t, expected.UnmarshalBinary(bin))
+		assert.Equal(t, &expected, msg2)
+		bin2, err := msg2.MarshalBinary()
+		require.NoError(t, err)
+		assert.Equal(t, bin, bin2)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	B := ristretto.NewGeneratorElement()
+	twoB := new(ristretto.Element).Add(B, B)
+	msg := messages.NewSign1(3, B, twoB)
+
+	// type = 3, from = 3, sign1 = { d = B, e = 2B }
+	golden := "08031003" + "6244" +
+		"0a20e2f2ae0a6abc4e71a884a961c500515f58e30b6aa582dd8db6a65945e08d2d76" +
+		"12206a493210f7499cd17fecb510ae0cea23a110e8d5b901f8acadd3095c73a3b919"
+
+	p, err := ToProto(msg)
+	require.NoError(t, err)
+	wire, err := p.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, golden, hex.EncodeToString(wire))
+
+	data, err := hex.DecodeString(golden)
+	require.NoError(t, err)
+	var p2 Message
+	require.NoError(t, p2.Unmarshal(data))
+	msg2, err := FromProto(&p2)
+	require.NoError(t, err)
+	assert.True(t, msg.Equal(msg2), "messages are not equal")
+}
+
+func TestUnmarshal_UnknownFields(t *testing.T) {
+	msg := testMessages()[3]
+	p, err := ToProto(msg)
+	require.NoError(t, err)
+	wire, err := p.Marshal()
+	require.NoError(t, err)
+
+	// field 100 (varint) and field 101 (bytes) are skipped
+	extended := append([]byte{0xa0, 0x06, 0x01, 0xaa, 0x06, 0x01, 0x00}, wire...)
+	var p2 Message
+	require.NoError(t, p2.Unmarshal(extended))
+	msg2, err := FromProto(&p2)
+	require.NoError(t, err)
+	assert.True(t, msg.Equal(msg2), "messages are not equal")
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	tests := map[string][]byte{
+		"truncated tag":    {0x80},
+		"truncated bytes":  {0x22, 0x05, 0x00},
+		"field 0":          {0x00, 0x01},
+		"wrong wire type":  {0x0a, 0x00},
+		"group wire type":  {0x0b},
+		"from overflows":   {0x10, 0x80, 0x80, 0x80, 0x80, 0x10},
+		"truncated fixed":  {0x29, 0x00},
+		"invalid content":  {0x62, 0x02, 0x0a, 0x05},
+		"wrong field type": {0x62, 0x02, 0x08, 0x01},
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			var p Message
+			err := p.Unmarshal(data)
+			assert.True(t, errors.Is(err, ErrInvalidWire), "error %v should wrap ErrInvalidWire", err)
+		})
+	}
+}
+
+func TestFromProto_Invalid(t *testing.T) {
+	point := ristretto.NewGeneratorElement().Bytes()
+	nonCanonical := bytes.Repeat([]byte{0xff}, 32)
+
+	tests := map[string]*Message{
+		"unknown type":        {Type: 7, From: 1, Sign2: &Sign2{Z: make([]byte, 32)}},
+		"unspecified type":    {From: 1, Sign2: &Sign2{Z: make([]byte, 32)}},
+		"mismatched content":  {Type: MessageType_MESSAGE_TYPE_SIGN1, From: 1, Sign2: &Sign2{Z: make([]byte, 32)}},
+		"two contents":        {Type: MessageType_MESSAGE_TYPE_SIGN2, From: 1, Sign1: &Sign1{D: point, E: point}, Sign2: &Sign2{Z: make([]byte, 32)}},
+		"no content":          {Type: MessageType_MESSAGE_TYPE_SIGN2, From: 1},
+		"zero sender":         {Type: MessageType_MESSAGE_TYPE_SIGN2, Sign2: &Sign2{Z: make([]byte, 32)}},
+		"broadcast share":     {Type: MessageType_MESSAGE_TYPE_KEYGEN2, From: 1, KeyGen2: &KeyGen2{Share: make([]byte, 32)}},
+		"short share":         {Type: MessageType_MESSAGE_TYPE_KEYGEN2, From: 1, To: 2, KeyGen2: &KeyGen2{Share: make([]byte, 31)}},
+		"long share":          {Type: MessageType_MESSAGE_TYPE_KEYGEN2, From: 1, To: 2, KeyGen2: &KeyGen2{Share: make([]byte, 33)}},
+		"non canonical share": {Type: MessageType_MESSAGE_TYPE_KEYGEN2, From: 1, To: 2, KeyGen2: &KeyGen2{Share: nonCanonical}},
+		"non canonical point": {Type: MessageType_MESSAGE_TYPE_SIGN1, From: 1, Sign1: &Sign1{D: nonCanonical, E: point}},
+		"missing point":       {Type: MessageType_MESSAGE_TYPE_SIGN1, From: 1, Sign1: &Sign1{D: point}},
+		"no commitments":      {Type: MessageType_MESSAGE_TYPE_KEYGEN1, From: 1, KeyGen1: &KeyGen1{ProofS: make([]byte, 32), ProofR: make([]byte, 32)}},
+		"short session ID":    {Type: MessageType_MESSAGE_TYPE_SIGN2, From: 1, SessionId: []byte{1}, Sign2: &Sign2{Z: make([]byte, 32)}},
+	}
+	for name, p := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := FromProto(p)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestToProto_Unsupported(t *testing.T) {
+	for msgType := messages.MessageTypePacked; msgType <= messages.MessageTypeKeyGenBatch2; msgType++ {
+		_, err := ToProto(&messages.Message{Header: messages.Header{Type: msgType, From: 1}})
+		assert.Error(t, err, msgType.String())
+	}
+}
@@ -0,0 +1,317 @@
+package pb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrInvalidWire is wrapped by the errors returned when decoding data which is not a valid proto3 encoding.
+var ErrInvalidWire = errors.New("invalid protobuf encoding")
+
+// Field numbers of messages.proto
+const (
+	fieldType      = 1
+	fieldFrom      = 2
+	fieldTo        = 3
+	fieldSessionID = 4
+	fieldKeyGen1   = 10
+	fieldKeyGen2   = 11
+	fieldSign1     = 12
+	fieldSign2     = 13
+	fieldEcho      = 14
+)
+
+// Wire types
+const (
+	wireVarint = 0
+	wireI64    = 1
+	wireBytes  = 2
+	wireI32    = 5
+)
+
+//
+// Encoding
+//
+
+// Marshal returns the proto3 encoding of m.
+// Fields are written in increasing order, and fields with a default value are omitted,
+// so the encoding is deterministic.
+func (m *Message) Marshal() ([]byte, error) {
+	var buf []byte
+	buf = appendVarintField(buf, fieldType, uint64(m.Type))
+	buf = appendVarintField(buf, fieldFrom, uint64(m.From))
+	buf = appendVarintField(buf, fieldTo, uint64(m.To))
+	buf = appendBytesField(buf, fieldSessionID, m.SessionId)
+
+	var count int
+	if m.KeyGen1 != nil {
+		count++
+		buf = appendMessageField(buf, fieldKeyGen1, m.KeyGen1.marshal())
+	}
+	if m.KeyGen2 != nil {
+		count++
+		buf = appendMessageField(buf, fieldKeyGen2, appendBytesField(appendBytesField(nil, 1, m.KeyGen2.Share), 2, m.KeyGen2.SealedShare))
+	}
+	if m.Sign1 != nil {
+		count++
+		buf = appendMessageField(buf, fieldSign1, appendBytesField(appendBytesField(nil, 1, m.Sign1.D), 2, m.Sign1.E))
+	}
+	if m.Sign2 != nil {
+		count++
+		buf = appendMessageField(buf, fieldSign2, appendBytesField(nil, 1, m.Sign2.Z))
+	}
+	if m.KeyGenEcho != nil {
+		count++
+		buf = appendMessageField(buf, fieldEcho, appendBytesField(nil, 1, m.KeyGenEcho.Digest))
+	}
+	if count > 1 {
+		return nil, errors.New("pb.Message.Marshal: more than one content is set")
+	}
+	return buf, nil
+}
+
+func (m *KeyGen1) marshal() []byte {
+	var buf []byte
+	buf = appendBytesField(buf, 1, m.ProofS)
+	buf = appendBytesField(buf, 2, m.ProofR)
+	for _, c := range m.Commitments {
+		// repeated fields are written even if they are empty
+		buf = appendTag(buf, 3, wireBytes)
+		buf = appendUvarint(buf, uint64(len(c)))
+		buf = append(buf, c...)
+	}
+	return appendBytesField(buf, 4, m.EncryptionKey)
+}
+
+func appendTag(buf []byte, field int, wireType int) []byte {
+	return appendUvarint(buf, uint64(field)<<3|uint64(wireType))
+}
+
+func appendVarintField(buf []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return buf
+	}
+	buf = appendTag(buf, field, wireVarint)
+	return appendUvarint(buf, v)
+}
+
+func appendBytesField(buf []byte, field int, b []byte) []byte {
+	if len(b) == 0 {
+		return buf
+	}
+	buf = appendTag(buf, field, wireBytes)
+	buf = appendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+// appendMessageField appends an embedded message, which is written even if it is empty
+// since it is part of a oneof.
+func appendMessageField(buf []byte, field int, b []byte) []byte {
+	buf = appendTag(buf, field, wireBytes)
+	buf = appendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+//
+// Decoding
+//
+
+// Unmarshal decodes the proto3 encoding of a Message into m.
+// As specified by proto3, fields may appear in any order, unknown fields are skipped,
+// and the last value of a repeated scalar or oneof field is used.
+// The content of the message is not validated, which is done by FromProto.
+func (m *Message) Unmarshal(data []byte) error {
+	var out Message
+	err := readFields(data, func(field, wireType int, v uint64, b []byte) error {
+		var err error
+		switch field {
+		case fieldType:
+			if wireType != wireVarint {
+				return wireTypeError(field)
+			}
+			out.Type = MessageType(int32(v))
+		case fieldFrom:
+			out.From, err = uint32Field(field, wireType, v)
+		case fieldTo:
+			out.To, err = uint32Field(field, wireType, v)
+		case fieldSessionID:
+			out.SessionId, err = bytesField(field, wireType, b)
+		case fieldKeyGen1, fieldKeyGen2, fieldSign1, fieldSign2, fieldEcho:
+			if wireType != wireBytes {
+				return wireTypeError(field)
+			}
+			out.KeyGen1, out.KeyGen2, out.Sign1, out.Sign2, out.KeyGenEcho = nil, nil, nil, nil, nil
+			switch field {
+			case fieldKeyGen1:
+				out.KeyGen1 = &KeyGen1{}
+				err = out.KeyGen1.unmarshal(b)
+			case fieldKeyGen2:
+				out.KeyGen2 = &KeyGen2{}
+				err = out.KeyGen2.unmarshal(b)
+			case fieldSign1:
+				out.Sign1 = &Sign1{}
+				err = out.Sign1.unmarshal(b)
+			case fieldSign2:
+				out.Sign2 = &Sign2{}
+				err = out.Sign2.unmarshal(b)
+			case fieldEcho:
+				out.KeyGenEcho = &KeyGenEcho{}
+				err = out.KeyGenEcho.unmarshal(b)
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("pb.Message.Unmarshal: %w", err)
+	}
+	*m = out
+	return nil
+}
+
+func (m *KeyGen1) unmarshal(data []byte) error {
+	return readFields(data, func(field, wireType int, _ uint64, b []byte) error {
+		var err error
+		switch field {
+		case 1:
+			m.ProofS, err = bytesField(field, wireType, b)
+		case 2:
+			m.ProofR, err = bytesField(field, wireType, b)
+		case 3:
+			var c []byte
+			if c, err = bytesField(field, wireType, b); err == nil {
+				m.Commitments = append(m.Commitments, c)
+			}
+		case 4:
+			m.EncryptionKey, err = bytesField(field, wireType, b)
+		}
+		return err
+	})
+}
+
+func (m *KeyGen2) unmarshal(data []byte) error {
+	return readFields(data, func(field, wireType int, _ uint64, b []byte) error {
+		var err error
+		switch field {
+		case 1:
+			m.Share, err = bytesField(field, wireType, b)
+		case 2:
+			m.SealedShare, err = bytesField(field, wireType, b)
+		}
+		return err
+	})
+}
+
+func (m *Sign1) unmarshal(data []byte) error {
+	return readFields(data, func(field, wireType int, _ uint64, b []byte) error {
+		var err error
+		switch field {
+		case 1:
+			m.D, err = bytesField(field, wireType, b)
+		case 2:
+			m.E, err = bytesField(field, wireType, b)
+		}
+		return err
+	})
+}
+
+func (m *Sign2) unmarshal(data []byte) error {
+	return readFields(data, func(field, wireType int, _ uint64, b []byte) error {
+		var err error
+		if field == 1 {
+			m.Z, err = bytesField(field, wireType, b)
+		}
+		return err
+	})
+}
+
+func (m *KeyGenEcho) unmarshal(data []byte) error {
+	return readFields(data, func(field, wireType int, _ uint64, b []byte) error {
+		var err error
+		if field == 1 {
+			m.Digest, err = bytesField(field, wireType, b)
+		}
+		return err
+	})
+}
+
+// readFields calls f for every field of the encoded message data.
+// For varint fields, v contains the value, and for length-delimited fields b contains the bytes.
+func readFields(data []byte, f func(field, wireType int, v uint64, b []byte) error) error {
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("invalid tag: %w", ErrInvalidWire)
+		}
+		data = data[n:]
+		field, wireType := tag>>3, int(tag&7)
+		if field == 0 || field > math.MaxInt32 {
+			return fmt.Errorf("invalid field number %d: %w", field, ErrInvalidWire)
+		}
+
+		var (
+			v uint64
+			b []byte
+		)
+		switch wireType {
+		case wireVarint:
+			if v, n = binary.Uvarint(data); n <= 0 {
+				return fmt.Errorf("invalid varint: %w", ErrInvalidWire)
+			}
+			data = data[n:]
+		case wireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || length > uint64(len(data)-n) {
+				return fmt.Errorf("invalid length: %w", ErrInvalidWire)
+			}
+			b = data[n : n+int(length)]
+			data = data[n+int(length):]
+		case wireI64:
+			if len(data) < 8 {
+				return fmt.Errorf("unexpected end of data: %w", ErrInvalidWire)
+			}
+			data = data[8:]
+		case wireI32:
+			if len(data) < 4 {
+				return fmt.Errorf("unexpected end of data: %w", ErrInvalidWire)
+			}
+			data = data[4:]
+		default:
+			return fmt.Errorf("unsupported wire type %d: %w", wireType, ErrInvalidWire)
+		}
+
+		if err := f(int(field), wireType, v, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func wireTypeError(field int) error {
+	return fmt.Errorf("field %d has the wrong wire type: %w", field, ErrInvalidWire)
+}
+
+func uint32Field(field, wireType int, v uint64) (uint32, error) {
+	if wireType != wireVarint {
+		return 0, wireTypeError(field)
+	}
+	// proto3 truncates larger values, but they can never be valid IDs
+	if v > math.MaxUint32 {
+		return 0, fmt.Errorf("field %d overflows uint32: %w", field, ErrInvalidWire)
+	}
+	return uint32(v), nil
+}
+
+func bytesField(field, wireType int, b []byte) ([]byte, error) {
+	if wireType != wireBytes {
+		return nil, wireTypeError(field)
+	}
+	return append([]byte(nil), b...), nil
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	return append(buf, b[:n]...)
+}
@@ -0,0 +1,368 @@
+package messages
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/zk"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// The String methods of messages are meant for logging, and never print secret values.
+// Scalars are either secret (KeyGen2.Share, Enroll.Share, Repair2.Share) or derived from secrets (KeyGen1.Proof, Sign2.Zi),
+// so they are all replaced by redacted, while public data such as points and session IDs is printed as truncated hex.
+// The content of Extension messages is unknown to this package, and only its size is printed.
+//
+// String, GoString and LogValue have value receivers, so that messages are also redacted when formatted by value.
+
+const (
+	redacted = "[REDACTED]"
+
+	// shortHexSize is the number of bytes printed by shortHex.
+	shortHexSize = 4
+)
+
+// shortHex returns the hex encoding of the first bytes of data, which is enough to tell public values apart.
+func shortHex(data []byte) string {
+	if len(data) <= shortHexSize {
+		return hex.EncodeToString(data)
+	}
+	return hex.EncodeToString(data[:shortHexSize]) + "..."
+}
+
+// String implements fmt.Stringer.
+func (h Header) String() string {
+	return "Header{" + h.fields() + "}"
+}
+
+// fields returns the fields of h, as printed by String.
+func (h Header) fields() string {
+	to := "all"
+	if !h.Type.IsBroadcast() {
+		to = h.To.String()
+	}
+	return fmt.Sprintf("Type: %s, From: %s, To: %s, SessionID: %s", h.Type, h.From, to, shortHex(h.SessionID[:]))
+}
+
+// String implements fmt.Stringer, without printing secret values.
+func (m KeyGen1) String() string {
+	if m.EncryptionKey != nil {
+		return fmt.Sprintf("KeyGen1{Proof: %s, Commitments: [%s], EncryptionKey: %s}",
+			redacted, strings.Join(m.commitments(), " "), shortHex(m.EncryptionKey.Bytes()))
+	}
+	return fmt.Sprintf("KeyGen1{Proof: %s, Commitments: [%s]}", redacted, strings.Join(m.commitments(), " "))
+}
+
+// commitments returns the truncated hex encodings of the coefficients of m.Commitments.
+func (m KeyGen1) commitments() []string {
+	if m.Commitments == nil {
+		return nil
+	}
+	data, err := m.Commitments.MarshalBinary()
+	if err != nil {
+		return nil
+	}
+	// skip the degree
+	data = data[party.IDByteSize:]
+	commitments := make([]string, 0, len(data)/32)
+	for ; len(data) >= 32; data = data[32:] {
+		commitments = append(commitments, shortHex(data[:32]))
+	}
+	return commitments
+}
+
+// GoString implements fmt.GoStringer, without printing secret values.
+func (m KeyGen1) GoString() string {
+	return m.String()
+}
+
+// String implements fmt.Stringer, without printing secret values.
+// A sealed share is encrypted, and is printed as truncated hex.
+func (m KeyGen2) String() string {
+	if m.SealedShare != nil {
+		return "KeyGen2{SealedShare: " + shortHex(m.SealedShare) + "}"
+	}
+	return "KeyGen2{Share: " + redacted + "}"
+}
+
+// GoString implements fmt.GoStringer, without printing secret values.
+func (m KeyGen2) GoString() string {
+	return m.String()
+}
+
+// String implements fmt.Stringer.
+func (m Sign1) String() string {
+	return fmt.Sprintf("Sign1{Di: %s, Ei: %s}", shortHex(m.Di.Bytes()), shortHex(m.Ei.Bytes()))
+}
+
+// GoString implements fmt.GoStringer.
+func (m Sign1) GoString() string {
+	return m.String()
+}
+
+// String implements fmt.Stringer, without printing secret values.
+func (m Sign2) String() string {
+	return "Sign2{Zi: " + redacted + "}"
+}
+
+// GoString implements fmt.GoStringer, without printing secret values.
+func (m Sign2) GoString() string {
+	return m.String()
+}
+
+// String implements fmt.Stringer, without printing secret values.
+func (m Message) String() string {
+	var content string
+	switch {
+	case m.Type == MessageTypeKeyGen1 && m.KeyGen1 != nil:
+		content = m.KeyGen1.String()
+	case m.Type == MessageTypeKeyGen2 && m.KeyGen2 != nil:
+		content = m.KeyGen2.String()
+	case m.Type == MessageTypeSign1 && m.Sign1 != nil:
+		content = m.Sign1.String()
+	case m.Type == MessageTypeSign2 && m.Sign2 != nil:
+		content = m.Sign2.String()
+	case m.Type == MessageTypeKeyGenEcho && m.KeyGenEcho != nil:
+		content = m.KeyGenEcho.String()
+	case m.Type == MessageTypePacked && m.Packed != nil:
+		content = m.Packed.String()
+	case m.Type == MessageTypeKeyGenBlame && m.KeyGenBlame != nil:
+		content = m.KeyGenBlame.String()
+	case m.Type == MessageTypeKeyGenConfirm && m.KeyGenConfirm != nil:
+		content = m.KeyGenConfirm.String()
+	case m.Type == MessageTypeKeyGenComplaint && m.KeyGenComplaint != nil:
+		content = m.KeyGenComplaint.String()
+	case m.Type == MessageTypeEnroll && m.Enroll != nil:
+		content = m.Enroll.String()
+	case m.Type == MessageTypeRepair1 && m.Repair1 != nil:
+		content = m.Repair1.String()
+	case m.Type == MessageTypeRepair2 && m.Repair2 != nil:
+		content = m.Repair2.String()
+	case m.Type == MessageTypeKeyGenBatch1 && m.KeyGenBatch1 != nil:
+		content = m.KeyGenBatch1.String()
+	case m.Type == MessageTypeKeyGenBatch2 && m.KeyGenBatch2 != nil:
+		content = m.KeyGenBatch2.String()
+	case m.Extension != nil:
+		content = "Extension{" + m.extensionSize() + "}"
+	default:
+		content = "<nil>"
+	}
+	return "Message{" + m.Header.fields() + ", " + content + "}"
+}
+
+// String implements fmt.Stringer.
+func (m KeyGenEcho) String() string {
+	return "KeyGenEcho{Digest: " + shortHex(m.Digest[:]) + "}"
+}
+
+// GoString implements fmt.GoStringer.
+func (m KeyGenEcho) GoString() string {
+	return m.String()
+}
+
+// String implements fmt.Stringer.
+func (m KeyGenConfirm) String() string {
+	return "KeyGenConfirm{Digest: " + shortHex(m.Digest[:]) + "}"
+}
+
+// GoString implements fmt.GoStringer.
+func (m KeyGenConfirm) GoString() string {
+	return m.String()
+}
+
+// String implements fmt.Stringer.
+func (m KeyGenComplaint) String() string {
+	return fmt.Sprintf("KeyGenComplaint{Accused: %v}", m.Accused)
+}
+
+// GoString implements fmt.GoStringer.
+func (m KeyGenComplaint) GoString() string {
+	return m.String()
+}
+
+// String implements fmt.Stringer, without printing secret values.
+func (m Enroll) String() string {
+	return "Enroll{Share: " + redacted + "}"
+}
+
+// GoString implements fmt.GoStringer, without printing secret values.
+func (m Enroll) GoString() string {
+	return m.String()
+}
+
+// String implements fmt.Stringer.
+func (m Repair1) String() string {
+	return fmt.Sprintf("Repair1{Masks: %v}", m.masks())
+}
+
+// masks returns the short hex encodings of the commitments to the masks.
+func (m Repair1) masks() []string {
+	masks := make([]string, 0, len(m.Masks))
+	for i := range m.Masks {
+		masks = append(masks, shortHex(m.Masks[i].Bytes()))
+	}
+	return masks
+}
+
+// GoString implements fmt.GoStringer.
+func (m Repair1) GoString() string {
+	return m.String()
+}
+
+// String implements fmt.Stringer, without printing secret values.
+func (m Repair2) String() string {
+	return "Repair2{Share: " + redacted + "}"
+}
+
+// GoString implements fmt.GoStringer, without printing secret values.
+func (m Repair2) GoString() string {
+	return m.String()
+}
+
+// String implements fmt.Stringer, without printing secret values.
+// Only the number of keys is printed, since the commitments of a large batch would not fit on a line.
+func (m KeyGenBatch1) String() string {
+	return fmt.Sprintf("KeyGenBatch1{Keys: %d, Proofs: %s}", len(m.Keys), redacted)
+}
+
+// GoString implements fmt.GoStringer, without printing secret values.
+func (m KeyGenBatch1) GoString() string {
+	return m.String()
+}
+
+// String implements fmt.Stringer, without printing secret values.
+func (m KeyGenBatch2) String() string {
+	return fmt.Sprintf("KeyGenBatch2{Shares: %d %s}", len(m.Shares), redacted)
+}
+
+// GoString implements fmt.GoStringer, without printing secret values.
+func (m KeyGenBatch2) GoString() string {
+	return m.String()
+}
+
+// String implements fmt.Stringer, without printing the payloads, which may contain secret values.
+func (m Packed) String() string {
+	return fmt.Sprintf("Packed{Type: %s, To: %v, Payloads: %s}", m.Type, m.To, redacted)
+}
+
+// GoString implements fmt.GoStringer, without printing the payloads.
+func (m Packed) GoString() string {
+	return m.String()
+}
+
+// String implements fmt.Stringer, without printing the revealed shares.
+func (m KeyGenBlame) String() string {
+	return fmt.Sprintf("KeyGenBlame{Accused: %v, Shares: %s}", m.accused(), redacted)
+}
+
+// accused returns the IDs of the parties accused in m.
+func (m KeyGenBlame) accused() []party.ID {
+	accused := make([]party.ID, 0, len(m.Complaints))
+	for _, c := range m.Complaints {
+		accused = append(accused, c.Accused)
+	}
+	return accused
+}
+
+// GoString implements fmt.GoStringer, without printing the revealed shares.
+func (m KeyGenBlame) GoString() string {
+	return m.String()
+}
+
+// GoString implements fmt.GoStringer, without printing secret values.
+func (m Message) GoString() string {
+	return m.String()
+}
+
+// extensionSize describes the size of the encoding of the Extension content.
+func (m Message) extensionSize() string {
+	data, err := m.Extension.MarshalBinary()
+	if err != nil {
+		return "invalid"
+	}
+	return fmt.Sprintf("%d bytes", len(data))
+}
+
+// Redacted returns a deep copy of m in which all scalars are set to zero, so that it can be archived safely.
+// The shares revealed in a KeyGenBlame message are kept, since they are the evidence of the accusations.
+// The points and the header are preserved, so that the copy can still be encoded.
+// The content of Extension messages is unknown to this package, and is shared with m.
+func (m *Message) Redacted() *Message {
+	r := &Message{
+		Header:    m.Header,
+		Extension: m.Extension,
+	}
+	if m.KeyGen1 != nil {
+		r.KeyGen1 = &KeyGen1{Proof: new(zk.Schnorr)}
+		if m.KeyGen1.Commitments != nil {
+			r.KeyGen1.Commitments = m.KeyGen1.Commitments.Copy()
+		}
+		if m.KeyGen1.EncryptionKey != nil {
+			r.KeyGen1.EncryptionKey = new(ristretto.Element).Set(m.KeyGen1.EncryptionKey)
+		}
+	}
+	if m.KeyGen2 != nil {
+		r.KeyGen2 = new(KeyGen2)
+		if m.KeyGen2.SealedShare != nil {
+			// a sealed share can only be decrypted with the ephemeral secret of the recipient, which is never stored
+			r.KeyGen2.SealedShare = append([]byte(nil), m.KeyGen2.SealedShare...)
+		}
+	}
+	if m.Sign1 != nil {
+		r.Sign1 = new(Sign1)
+		r.Sign1.Di.Set(&m.Sign1.Di)
+		r.Sign1.Ei.Set(&m.Sign1.Ei)
+	}
+	if m.Sign2 != nil {
+		r.Sign2 = new(Sign2)
+	}
+	if m.KeyGenEcho != nil {
+		r.KeyGenEcho = &KeyGenEcho{Digest: m.KeyGenEcho.Digest}
+	}
+	if m.KeyGenBlame != nil {
+		r.KeyGenBlame = &KeyGenBlame{Complaints: append([]Complaint(nil), m.KeyGenBlame.Complaints...)}
+	}
+	if m.KeyGenConfirm != nil {
+		r.KeyGenConfirm = &KeyGenConfirm{Digest: m.KeyGenConfirm.Digest}
+	}
+	if m.KeyGenComplaint != nil {
+		r.KeyGenComplaint = &KeyGenComplaint{Accused: m.KeyGenComplaint.Accused.Copy()}
+	}
+	if m.Enroll != nil {
+		r.Enroll = new(Enroll)
+	}
+	if m.Repair1 != nil {
+		r.Repair1 = &Repair1{Masks: append([]ristretto.Element(nil), m.Repair1.Masks...)}
+	}
+	if m.Repair2 != nil {
+		r.Repair2 = new(Repair2)
+	}
+	if m.KeyGenBatch1 != nil {
+		r.KeyGenBatch1 = &KeyGenBatch1{Keys: make([]KeyGen1, len(m.KeyGenBatch1.Keys))}
+		for i, key := range m.KeyGenBatch1.Keys {
+			r.KeyGenBatch1.Keys[i].Proof = new(zk.Schnorr)
+			if key.Commitments != nil {
+				r.KeyGenBatch1.Keys[i].Commitments = key.Commitments.Copy()
+			}
+		}
+	}
+	if m.KeyGenBatch2 != nil {
+		r.KeyGenBatch2 = &KeyGenBatch2{Shares: make([]ristretto.Scalar, len(m.KeyGenBatch2.Shares))}
+	}
+	if m.Packed != nil {
+		// the payloads are replaced by the encoding of their redacted content, so that the copy remains valid
+		r.Packed = &Packed{
+			Type:     m.Packed.Type,
+			To:       append([]party.ID(nil), m.Packed.To...),
+			Payloads: make([][]byte, len(m.Packed.Payloads)),
+		}
+		for i := range m.Packed.Payloads {
+			if msg, err := m.Packed.unpack(m.Header, i); err == nil {
+				r.Packed.Payloads[i], _ = msg.Redacted().appendContent(nil)
+			}
+		}
+	}
+	return r
+}
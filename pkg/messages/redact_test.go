@@ -0,0 +1,95 @@
+package messages
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// secretsOf returns the encodings of the scalars contained in msg.
+func secretsOf(msg *Message) [][]byte {
+	switch {
+	case msg.KeyGen1 != nil:
+		return [][]byte{msg.KeyGen1.Proof.S.Bytes(), msg.KeyGen1.Proof.R.Bytes()}
+	case msg.KeyGen2 != nil:
+		return [][]byte{msg.KeyGen2.Share.Bytes()}
+	case msg.Sign2 != nil:
+		return [][]byte{msg.Sign2.Zi.Bytes()}
+	case msg.Packed != nil:
+		var secrets [][]byte
+		msgs, _ := msg.Split()
+		for _, inner := range msgs {
+			secrets = append(secrets, secretsOf(inner)...)
+		}
+		return secrets
+	}
+	return nil
+}
+
+// assertNoSecrets checks that out contains none of the secrets, in hex or as printed by fmt for a byte slice.
+// Zero scalars are skipped, since they reveal nothing and would be found in any encoding.
+func assertNoSecrets(t *testing.T, out string, secrets [][]byte) {
+	t.Helper()
+	for _, secret := range secrets {
+		if bytes.Equal(secret, make([]byte, len(secret))) {
+			continue
+		}
+		assert.NotContains(t, out, hex.EncodeToString(secret[:shortHexSize]))
+		decimal := fmt.Sprint(secret[:shortHexSize])
+		assert.NotContains(t, out, decimal[1:len(decimal)-1])
+	}
+}
+
+func TestMessage_String(t *testing.T) {
+	for _, msg := range testMessages() {
+		secrets := secretsOf(msg)
+		for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+			for _, v := range []interface{}{msg, *msg, msg.KeyGen1, msg.KeyGen2, msg.Sign1, msg.Sign2} {
+				out := fmt.Sprintf(format, v)
+				assertNoSecrets(t, out, secrets)
+			}
+		}
+
+		out := msg.String()
+		assert.True(t, strings.HasPrefix(out, "Message{Type: "+msg.Type.String()), out)
+		assert.Contains(t, out, "From: "+msg.From.String())
+		assert.Contains(t, out, shortHex(msg.SessionID[:]))
+		if len(secrets) > 0 {
+			assert.Contains(t, out, redacted)
+		}
+	}
+
+	// public points are printed
+	msg := testMessages()[2]
+	assert.Contains(t, msg.String(), hex.EncodeToString(msg.Sign1.Di.Bytes()[:shortHexSize]))
+	assert.Contains(t, msg.String(), "To: all")
+}
+
+func TestMessage_Redacted(t *testing.T) {
+	for _, msg := range testMessages() {
+		original, err := msg.MarshalBinary()
+		require.NoError(t, err)
+
+		r := msg.Redacted()
+		data, err := r.MarshalBinary()
+		require.NoError(t, err)
+		assert.Len(t, data, len(original))
+		for _, secret := range secretsOf(r) {
+			assert.Equal(t, make([]byte, 32), secret)
+		}
+		assertNoSecrets(t, hex.EncodeToString(data), secretsOf(msg))
+
+		// the original is unchanged
+		data, err = msg.MarshalBinary()
+		require.NoError(t, err)
+		assert.Equal(t, original, data)
+		if msg.Sign1 != nil || msg.KeyGen1 != nil {
+			assert.Equal(t, msg.String(), r.String())
+		}
+	}
+}
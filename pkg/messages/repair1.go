@@ -0,0 +1,127 @@
+package messages
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// Repair1 is broadcast by each helper in the first round of the repair of a lost share.
+// It commits to the masks the sender then sends to the other helpers in KeyGen2 messages,
+// so that the helpers can check the masks they receive, and the party recovering its share can check the values
+// of the Repair2 messages.
+//
+// Its binary encoding is:
+//
+//	n (4 bytes) ∥ n × commitment (32 bytes)
+type Repair1 struct {
+	// Masks contains the commitment [ρ]•B to the mask ρ sent to each other helper, in increasing order of their IDs.
+	Masks []ristretto.Element
+}
+
+// NewRepair1 returns a Repair1 message containing the commitments to the masks sent to the other helpers,
+// in increasing order of their IDs.
+func NewRepair1(from party.ID, masks []ristretto.Element) *Message {
+	return &Message{
+		Header: Header{
+			Type: MessageTypeRepair1,
+			From: from,
+		},
+		Repair1: &Repair1{Masks: masks},
+	}
+}
+
+// AppendBinary appends the encoding of m to dst and returns the extended slice.
+// It does not allocate if dst has a capacity of at least Size() additional bytes.
+func (m *Repair1) AppendBinary(dst []byte) ([]byte, error) {
+	dst = append(dst, party.Size(len(m.Masks)).Bytes()...)
+	for i := range m.Masks {
+		dst = append(dst, m.Masks[i].Bytes()...)
+	}
+	return dst, nil
+}
+
+// BytesAppend is the same as AppendBinary.
+func (m *Repair1) BytesAppend(existing []byte) ([]byte, error) {
+	return m.AppendBinary(existing)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (m *Repair1) MarshalBinary() ([]byte, error) {
+	return m.AppendBinary(make([]byte, 0, m.Size()))
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (m *Repair1) UnmarshalBinary(data []byte) error {
+	if len(data) < party.IDByteSize {
+		return &FieldError{Field: "Repair1.Masks", Err: fmt.Errorf("expected at least %d bytes (got %d)", party.IDByteSize, len(data))}
+	}
+	n, _ := party.FromBytes(data)
+	data = data[party.IDByteSize:]
+	if uint64(len(data)) != uint64(n)*32 {
+		return &FieldError{Field: "Repair1.Masks", Err: fmt.Errorf("expected %d commitments", n)}
+	}
+
+	masks := make([]ristretto.Element, n)
+	for i := range masks {
+		if _, err := masks[i].SetCanonicalBytes(data[:32]); err != nil {
+			return &FieldError{Field: "Repair1.Masks", Err: err}
+		}
+		data = data[32:]
+	}
+	m.Masks = masks
+	return nil
+}
+
+func (m *Repair1) Size() int {
+	return party.IDByteSize + 32*len(m.Masks)
+}
+
+func (m *Repair1) Equal(other interface{}) bool {
+	otherMsg, ok := other.(*Repair1)
+	if !ok || len(otherMsg.Masks) != len(m.Masks) {
+		return false
+	}
+	for i := range m.Masks {
+		if m.Masks[i].Equal(&otherMsg.Masks[i]) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+type jsonRepair1 struct {
+	Masks []string `json:"masks"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m *Repair1) MarshalJSON() ([]byte, error) {
+	out := jsonRepair1{Masks: make([]string, 0, len(m.Masks))}
+	for i := range m.Masks {
+		out.Masks = append(out.Masks, encodeHex(m.Masks[i].Bytes()))
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The points are validated as in UnmarshalBinary.
+func (m *Repair1) UnmarshalJSON(data []byte) error {
+	var out jsonRepair1
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	masks := make([]ristretto.Element, len(out.Masks))
+	for i, s := range out.Masks {
+		b, err := decodeHex(s, 32)
+		if err != nil {
+			return fmt.Errorf("repair1.Masks: %w", err)
+		}
+		if _, err = masks[i].SetCanonicalBytes(b); err != nil {
+			return &FieldError{Field: "Repair1.Masks", Err: err}
+		}
+	}
+	m.Masks = masks
+	return nil
+}
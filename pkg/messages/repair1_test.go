@@ -0,0 +1,64 @@
+package messages
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+func TestRepair1_MarshalBinary(t *testing.T) {
+	for n := 0; n < 4; n++ {
+		masks := make([]ristretto.Element, n)
+		for i := range masks {
+			masks[i].ScalarBaseMult(scalar.NewScalarRandom())
+		}
+		msg := NewRepair1(4, masks)
+
+		var msg2 Message
+		require.NoError(t, CheckFROSTMarshaler(msg, &msg2))
+		assert.True(t, msg2.Equal(msg), "messages are not equal")
+		assert.True(t, msg2.IsBroadcast())
+	}
+}
+
+func TestRepair1_UnmarshalBinary_Invalid(t *testing.T) {
+	point := new(ristretto.Element).ScalarBaseMult(scalar.NewScalarRandom()).Bytes()
+	invalid := make([]byte, 32)
+	for i := range invalid {
+		invalid[i] = 0xff
+	}
+	repair := func(n int, points ...[]byte) []byte {
+		data := party.Size(n).Bytes()
+		for _, p := range points {
+			data = append(data, p...)
+		}
+		return data
+	}
+
+	var valid Repair1
+	require.NoError(t, valid.UnmarshalBinary(repair(2, point, point)))
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"short", []byte{0, 0}},
+		{"count mismatch", repair(3, point, point)},
+		{"trailing data", append(repair(1, point), 0)},
+		{"invalid point", repair(1, invalid)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Repair1
+			err := m.UnmarshalBinary(tt.data)
+			var fieldErr *FieldError
+			require.True(t, errors.As(err, &fieldErr), err)
+			assert.Equal(t, "Repair1.Masks", fieldErr.Field)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package messages
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+const sizeRepair2 = 32
+
+// Repair2 is sent by each helper to the party recovering its share, in the last round of the repair of a lost share.
+type Repair2 struct {
+	// Share is the sender's contribution to the share of the recipient.
+	// It is masked, so that it reveals nothing about the share of the sender.
+	Share ristretto.Scalar
+}
+
+func NewRepair2(from, to party.ID, share *ristretto.Scalar) *Message {
+	return &Message{
+		Header: Header{
+			Type: MessageTypeRepair2,
+			From: from,
+			To:   to,
+		},
+		Repair2: &Repair2{Share: *share},
+	}
+}
+
+// AppendBinary appends the encoding of m to dst and returns the extended slice.
+// It does not allocate if dst has a capacity of at least Size() additional bytes.
+func (m *Repair2) AppendBinary(dst []byte) ([]byte, error) {
+	return scalar.AppendBytes(dst, &m.Share), nil
+}
+
+// BytesAppend is the same as AppendBinary.
+func (m *Repair2) BytesAppend(existing []byte) ([]byte, error) {
+	return m.AppendBinary(existing)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (m *Repair2) MarshalBinary() ([]byte, error) {
+	return m.AppendBinary(make([]byte, 0, sizeRepair2))
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (m *Repair2) UnmarshalBinary(data []byte) error {
+	if len(data) != sizeRepair2 {
+		return &FieldError{Field: "Repair2.Share", Err: fmt.Errorf("expected %d bytes (got %d)", sizeRepair2, len(data))}
+	}
+	if _, err := m.Share.SetCanonicalBytes(data); err != nil {
+		return &FieldError{Field: "Repair2.Share", Err: err}
+	}
+	return nil
+}
+
+func (m *Repair2) Size() int {
+	return sizeRepair2
+}
+
+func (m *Repair2) Equal(other interface{}) bool {
+	otherMsg, ok := other.(*Repair2)
+	if !ok {
+		return false
+	}
+	return otherMsg.Share.Equal(&m.Share) == 1
+}
+
+type jsonRepair2 struct {
+	Share string `json:"share"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m *Repair2) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonRepair2{
+		Share: encodeHex(m.Share.Bytes()),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The scalar is validated as in UnmarshalBinary.
+func (m *Repair2) UnmarshalJSON(data []byte) error {
+	var out jsonRepair2
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	share, err := decodeHex(out.Share, sizeRepair2)
+	if err != nil {
+		return fmt.Errorf("repair2.Share: %w", err)
+	}
+	return m.UnmarshalBinary(share)
+}
@@ -0,0 +1,20 @@
+package messages
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
+)
+
+func TestRepair2_MarshalBinary(t *testing.T) {
+	msg := NewRepair2(party.ID(rand.Uint32()), party.ID(rand.Uint32()), scalar.NewScalarRandom())
+
+	var msg2 Message
+	require.NoError(t, CheckFROSTMarshaler(msg, &msg2))
+	assert.True(t, msg2.Equal(msg), "messages are not equal")
+	assert.False(t, msg2.IsBroadcast())
+}
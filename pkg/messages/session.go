@@ -0,0 +1,33 @@
+package messages
+
+import (
+	"crypto/ed25519"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/hashing"
+)
+
+// SessionIDSize is the size in bytes of a SessionID
+const SessionIDSize = 32
+
+// SessionID uniquely identifies a protocol execution.
+// It is included in the Header of every message, so that messages from one execution
+// cannot be replayed in another one.
+type SessionID [SessionIDSize]byte
+
+// DeriveSessionID returns a deterministic SessionID for a protocol execution between partyIDs.
+// The nonce is supplied by the application, and must be different for every execution with the same parties.
+//
+//     SessionID = SHA-512/256("FROST-Ed25519 session" ∥ n ∥ ID₁ ∥ ... ∥ IDₙ ∥ nonce)
+func DeriveSessionID(partyIDs party.IDSlice, nonce []byte) SessionID {
+	return hashing.SessionID(partyIDs, nonce)
+}
+
+// DeriveGroupSessionID is DeriveSessionID for a signing session of the group whose key is groupKey,
+// as returned by eddsa.Public.GroupKeyEd25519. Binding the group key to the session ID ensures that
+// the same nonce used by the same parties for two of their groups still yields different sessions.
+//
+//     SessionID = SHA-512/256("FROST-Ed25519 group session" ∥ groupKey ∥ n ∥ ID₁ ∥ ... ∥ IDₙ ∥ nonce)
+func DeriveGroupSessionID(groupKey ed25519.PublicKey, partyIDs party.IDSlice, nonce []byte) SessionID {
+	return hashing.GroupSessionID(groupKey, partyIDs, nonce)
+}
@@ -1,6 +1,7 @@
 package messages
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
@@ -28,16 +29,22 @@ func NewSign1(from party.ID, commitmentD, commitmentE *ristretto.Element) *Messa
 	}
 }
 
+// AppendBinary appends the encoding of m to dst and returns the extended slice.
+// It does not allocate if dst has a capacity of at least Size() additional bytes.
+func (m *Sign1) AppendBinary(dst []byte) ([]byte, error) {
+	dst = append(dst, m.Di.Bytes()...)
+	dst = append(dst, m.Ei.Bytes()...)
+	return dst, nil
+}
+
+// BytesAppend is the same as AppendBinary.
 func (m *Sign1) BytesAppend(existing []byte) ([]byte, error) {
-	existing = append(existing, m.Di.Bytes()...)
-	existing = append(existing, m.Ei.Bytes()...)
-	return existing, nil
+	return m.AppendBinary(existing)
 }
 
 // MarshalBinary implements the encoding.BinaryMarshaler interface.
 func (m *Sign1) MarshalBinary() ([]byte, error) {
-	buf := make([]byte, 0, sizeSign1)
-	return m.BytesAppend(buf)
+	return m.AppendBinary(make([]byte, 0, sizeSign1))
 }
 
 // UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
@@ -45,17 +52,17 @@ func (m *Sign1) UnmarshalBinary(data []byte) error {
 	var err error
 
 	if len(data) != sizeSign1 {
-		return fmt.Errorf("msg1: %w", ErrInvalidMessage)
+		return &FieldError{Field: "Sign1", Err: fmt.Errorf("expected %d bytes (got %d)", sizeSign1, len(data))}
 	}
 
 	_, err = m.Di.SetCanonicalBytes(data[:32])
 	if err != nil {
-		return fmt.Errorf("msg1.D: %w", err)
+		return &FieldError{Field: "Sign1.Di", Err: err}
 	}
 
 	_, err = m.Ei.SetCanonicalBytes(data[32:])
 	if err != nil {
-		return fmt.Errorf("msg1.E: %w", err)
+		return &FieldError{Field: "Sign1.Ei", Err: err}
 	}
 
 	return nil
@@ -78,3 +85,34 @@ func (m *Sign1) Equal(other interface{}) bool {
 	}
 	return true
 }
+
+type jsonSign1 struct {
+	Di string `json:"d"`
+	Ei string `json:"e"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m *Sign1) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonSign1{
+		Di: encodeHex(m.Di.Bytes()),
+		Ei: encodeHex(m.Ei.Bytes()),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The points are validated as in UnmarshalBinary.
+func (m *Sign1) UnmarshalJSON(data []byte) error {
+	var out jsonSign1
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	di, err := decodeHex(out.Di, 32)
+	if err != nil {
+		return fmt.Errorf("msg1.D: %w", err)
+	}
+	ei, err := decodeHex(out.Ei, 32)
+	if err != nil {
+		return fmt.Errorf("msg1.E: %w", err)
+	}
+	return m.UnmarshalBinary(append(di, ei...))
+}
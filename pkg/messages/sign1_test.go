@@ -23,3 +23,40 @@ func TestSign1_MarshalBinary(t *testing.T) {
 	require.NoError(t, CheckFROSTMarshaler(msg, &msgDec))
 	require.True(t, msg.Equal(&msgDec), "messages are not equal")
 }
+
+func TestSign1_AppendBinaryAllocs(t *testing.T) {
+	D := new(ristretto.Element).ScalarBaseMult(scalar.NewScalarRandom())
+	E := new(ristretto.Element).ScalarBaseMult(scalar.NewScalarRandom())
+	msg := NewSign1(42, D, E)
+
+	buf := make([]byte, 0, msg.Size())
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = msg.AppendBinary(buf)
+	})
+	require.Zero(t, allocs, "AppendBinary should not allocate")
+}
+
+func BenchmarkSign1_AppendBinary(b *testing.B) {
+	D := new(ristretto.Element).ScalarBaseMult(scalar.NewScalarRandom())
+	E := new(ristretto.Element).ScalarBaseMult(scalar.NewScalarRandom())
+	msg := NewSign1(42, D, E)
+
+	buf := make([]byte, 0, msg.Size())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf, _ = msg.AppendBinary(buf[:0])
+	}
+}
+
+func BenchmarkSign1_MarshalBinary(b *testing.B) {
+	D := new(ristretto.Element).ScalarBaseMult(scalar.NewScalarRandom())
+	E := new(ristretto.Element).ScalarBaseMult(scalar.NewScalarRandom())
+	msg := NewSign1(42, D, E)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = msg.MarshalBinary()
+	}
+}
@@ -1,9 +1,11 @@
 package messages
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/internal/scalar"
 	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 )
 
@@ -25,25 +27,31 @@ func NewSign2(from party.ID, signatureShare *ristretto.Scalar) *Message {
 	}
 }
 
+// AppendBinary appends the encoding of m to dst and returns the extended slice.
+// It does not allocate if dst has a capacity of at least Size() additional bytes.
+func (m *Sign2) AppendBinary(dst []byte) ([]byte, error) {
+	return scalar.AppendBytes(dst, &m.Zi), nil
+}
+
+// BytesAppend is the same as AppendBinary.
 func (m *Sign2) BytesAppend(existing []byte) ([]byte, error) {
-	return append(existing, m.Zi.Bytes()...), nil
+	return m.AppendBinary(existing)
 }
 
 // MarshalBinary implements the encoding.BinaryMarshaler interface.
 func (m *Sign2) MarshalBinary() ([]byte, error) {
-	buf := make([]byte, 0, sizeSign2)
-	return m.BytesAppend(buf)
+	return m.AppendBinary(make([]byte, 0, sizeSign2))
 }
 
 // UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
 func (m *Sign2) UnmarshalBinary(data []byte) error {
 	if len(data) != sizeSign2 {
-		return fmt.Errorf("msg2: %w", ErrInvalidMessage)
+		return &FieldError{Field: "Sign2.Zi", Err: fmt.Errorf("expected %d bytes (got %d)", sizeSign2, len(data))}
 	}
 
 	_, err := m.Zi.SetCanonicalBytes(data)
 	if err != nil {
-		return fmt.Errorf("msg2.Zi: %w", err)
+		return &FieldError{Field: "Sign2.Zi", Err: err}
 	}
 
 	return nil
@@ -63,3 +71,28 @@ func (m *Sign2) Equal(other interface{}) bool {
 	}
 	return true
 }
+
+type jsonSign2 struct {
+	Zi string `json:"z"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m *Sign2) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonSign2{
+		Zi: encodeHex(m.Zi.Bytes()),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The scalar is validated as in UnmarshalBinary.
+func (m *Sign2) UnmarshalJSON(data []byte) error {
+	var out jsonSign2
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	zi, err := decodeHex(out.Zi, sizeSign2)
+	if err != nil {
+		return fmt.Errorf("msg2.Zi: %w", err)
+	}
+	return m.UnmarshalBinary(zi)
+}
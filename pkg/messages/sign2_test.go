@@ -19,3 +19,34 @@ func TestSign2_MarshalBinary(t *testing.T) {
 	require.NoError(t, CheckFROSTMarshaler(msg, &msg2))
 	assert.Equal(t, *msg, msg2, "messages are not equal")
 }
+
+func TestSign2_AppendBinaryAllocs(t *testing.T) {
+	msg := NewSign2(42, scalar.NewScalarRandom())
+
+	buf := make([]byte, 0, msg.Size())
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = msg.AppendBinary(buf)
+	})
+	require.Zero(t, allocs, "AppendBinary should not allocate")
+}
+
+func BenchmarkSign2_AppendBinary(b *testing.B) {
+	msg := NewSign2(42, scalar.NewScalarRandom())
+
+	buf := make([]byte, 0, msg.Size())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf, _ = msg.AppendBinary(buf[:0])
+	}
+}
+
+func BenchmarkSign2_MarshalBinary(b *testing.B) {
+	msg := NewSign2(42, scalar.NewScalarRandom())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = msg.MarshalBinary()
+	}
+}
@@ -0,0 +1,158 @@
+//go:build go1.21
+
+package messages
+
+import "log/slog"
+
+// LogValue implements slog.LogValuer, and redacts secret values as String does.
+func (h Header) LogValue() slog.Value {
+	return slog.GroupValue(h.attrs()...)
+}
+
+// attrs returns the fields of h. The recipient is omitted for broadcast messages.
+func (h Header) attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("type", h.Type.String()),
+		slog.Uint64("from", uint64(h.From)),
+	}
+	if !h.Type.IsBroadcast() {
+		attrs = append(attrs, slog.Uint64("to", uint64(h.To)))
+	}
+	return append(attrs, slog.String("session_id", shortHex(h.SessionID[:])))
+}
+
+// LogValue implements slog.LogValuer, and redacts secret values as String does.
+func (m KeyGen1) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("proof", redacted),
+		slog.Any("commitments", m.commitments()),
+	}
+	if m.EncryptionKey != nil {
+		attrs = append(attrs, slog.String("encryption_key", shortHex(m.EncryptionKey.Bytes())))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements slog.LogValuer, and redacts secret values as String does.
+func (m KeyGen2) LogValue() slog.Value {
+	if m.SealedShare != nil {
+		return slog.GroupValue(slog.String("sealed_share", shortHex(m.SealedShare)))
+	}
+	return slog.GroupValue(slog.String("share", redacted))
+}
+
+// LogValue implements slog.LogValuer.
+func (m Sign1) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("d", shortHex(m.Di.Bytes())),
+		slog.String("e", shortHex(m.Ei.Bytes())),
+	)
+}
+
+// LogValue implements slog.LogValuer, and redacts secret values as String does.
+func (m Sign2) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("z", redacted))
+}
+
+// LogValue implements slog.LogValuer.
+func (m KeyGenEcho) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("digest", shortHex(m.Digest[:])))
+}
+
+// LogValue implements slog.LogValuer.
+func (m KeyGenConfirm) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("digest", shortHex(m.Digest[:])))
+}
+
+// LogValue implements slog.LogValuer.
+func (m KeyGenComplaint) LogValue() slog.Value {
+	return slog.GroupValue(slog.Any("accused", m.Accused))
+}
+
+// LogValue implements slog.LogValuer, and redacts secret values as String does.
+func (m Enroll) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("share", redacted))
+}
+
+// LogValue implements slog.LogValuer.
+func (m Repair1) LogValue() slog.Value {
+	return slog.GroupValue(slog.Any("masks", m.masks()))
+}
+
+// LogValue implements slog.LogValuer, and redacts secret values as String does.
+func (m Repair2) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("share", redacted))
+}
+
+// LogValue implements slog.LogValuer, and redacts secret values as String does.
+func (m KeyGenBatch1) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("keys", len(m.Keys)),
+		slog.String("proofs", redacted),
+	)
+}
+
+// LogValue implements slog.LogValuer, and redacts secret values as String does.
+func (m KeyGenBatch2) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("shares", len(m.Shares)),
+		slog.String("values", redacted),
+	)
+}
+
+// LogValue implements slog.LogValuer, without logging the payloads.
+func (m Packed) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("type", m.Type.String()),
+		slog.Any("to", m.To),
+		slog.String("payloads", redacted),
+	)
+}
+
+// LogValue implements slog.LogValuer, without logging the revealed shares.
+func (m KeyGenBlame) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Any("accused", m.accused()),
+		slog.String("shares", redacted),
+	)
+}
+
+// LogValue implements slog.LogValuer, and redacts secret values as String does.
+// The content is logged in a group named after the type of the message.
+func (m Message) LogValue() slog.Value {
+	attrs := m.Header.attrs()
+	key := m.Type.String()
+	switch {
+	case m.Type == MessageTypeKeyGen1 && m.KeyGen1 != nil:
+		attrs = append(attrs, slog.Any(key, m.KeyGen1))
+	case m.Type == MessageTypeKeyGen2 && m.KeyGen2 != nil:
+		attrs = append(attrs, slog.Any(key, m.KeyGen2))
+	case m.Type == MessageTypeSign1 && m.Sign1 != nil:
+		attrs = append(attrs, slog.Any(key, m.Sign1))
+	case m.Type == MessageTypeSign2 && m.Sign2 != nil:
+		attrs = append(attrs, slog.Any(key, m.Sign2))
+	case m.Type == MessageTypeKeyGenEcho && m.KeyGenEcho != nil:
+		attrs = append(attrs, slog.Any(key, m.KeyGenEcho))
+	case m.Type == MessageTypePacked && m.Packed != nil:
+		attrs = append(attrs, slog.Any(key, m.Packed))
+	case m.Type == MessageTypeKeyGenBlame && m.KeyGenBlame != nil:
+		attrs = append(attrs, slog.Any(key, m.KeyGenBlame))
+	case m.Type == MessageTypeKeyGenConfirm && m.KeyGenConfirm != nil:
+		attrs = append(attrs, slog.Any(key, m.KeyGenConfirm))
+	case m.Type == MessageTypeKeyGenComplaint && m.KeyGenComplaint != nil:
+		attrs = append(attrs, slog.Any(key, m.KeyGenComplaint))
+	case m.Type == MessageTypeEnroll && m.Enroll != nil:
+		attrs = append(attrs, slog.Any(key, m.Enroll))
+	case m.Type == MessageTypeRepair1 && m.Repair1 != nil:
+		attrs = append(attrs, slog.Any(key, m.Repair1))
+	case m.Type == MessageTypeRepair2 && m.Repair2 != nil:
+		attrs = append(attrs, slog.Any(key, m.Repair2))
+	case m.Type == MessageTypeKeyGenBatch1 && m.KeyGenBatch1 != nil:
+		attrs = append(attrs, slog.Any(key, m.KeyGenBatch1))
+	case m.Type == MessageTypeKeyGenBatch2 && m.KeyGenBatch2 != nil:
+		attrs = append(attrs, slog.Any(key, m.KeyGenBatch2))
+	case m.Extension != nil:
+		attrs = append(attrs, slog.String(key, m.extensionSize()))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,37 @@
+//go:build go1.21
+
+package messages
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessage_LogValue(t *testing.T) {
+	for _, msg := range testMessages() {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, nil))
+		logger.Info("received", "message", msg)
+		logger.Info("received", "message", *msg)
+		logger.Info("received", "keygen2", msg.KeyGen2, "sign2", msg.Sign2, "keygen1", msg.KeyGen1)
+		assertNoSecrets(t, buf.String(), secretsOf(msg))
+
+		line, _, _ := bytes.Cut(buf.Bytes(), []byte("\n"))
+		var record struct {
+			Msg map[string]interface{} `json:"message"`
+		}
+		require.NoError(t, json.Unmarshal(line, &record))
+		assert.Equal(t, msg.Type.String(), record.Msg["type"])
+		assert.EqualValues(t, msg.From, record.Msg["from"])
+		assert.Equal(t, shortHex(msg.SessionID[:]), record.Msg["session_id"])
+		assert.Contains(t, record.Msg, msg.Type.String())
+		if len(secretsOf(msg)) > 0 {
+			assert.Contains(t, buf.String(), redacted)
+		}
+	}
+}
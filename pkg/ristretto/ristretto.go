@@ -462,3 +462,25 @@ func (e *Element) BytesEd25519() []byte {
 
 	return p.Bytes()
 }
+
+// SetEd25519Bytes sets e to the element represented by the Ed25519 encoding in,
+// so that e.BytesEd25519() returns in.
+// It returns an error and leaves e unchanged if in is not a canonical encoding of a point in the prime order subgroup,
+// as is the case for any public key generated by crypto/ed25519.
+func (e *Element) SetEd25519Bytes(in []byte) (*Element, error) {
+	var p edwards25519.Point
+	if _, err := p.SetBytes(in); err != nil {
+		return nil, errInvalidEncoding
+	}
+	if !bytes.Equal(p.Bytes(), in) {
+		return nil, errInvalidEncoding
+	}
+	var tmp Element
+	tmp.r.Set(&p)
+	// BytesEd25519 clears the torsion component, so it only returns in if p has none.
+	if !bytes.Equal(tmp.BytesEd25519(), in) {
+		return nil, errInvalidEncoding
+	}
+	e.r.Set(&p)
+	return e, nil
+}
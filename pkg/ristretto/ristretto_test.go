@@ -9,6 +9,7 @@ import (
 	"math/big"
 	"testing"
 
+	"filippo.io/edwards25519"
 	"filippo.io/edwards25519/field"
 )
 
@@ -229,6 +230,47 @@ func TestMarshalElement(t *testing.T) {
 	}
 }
 
+func TestSetEd25519Bytes(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		x := new(Element)
+		xbytes := sha512.Sum512([]byte{byte(i)})
+		_, _ = x.SetUniformBytes(xbytes[:])
+
+		encoded := x.BytesEd25519()
+		y := new(Element)
+		if _, err := y.SetEd25519Bytes(encoded); err != nil {
+			t.Fatal(err)
+		}
+		if y.Equal(x) != 1 || !bytes.Equal(y.BytesEd25519(), encoded) {
+			t.Errorf("SetEd25519Bytes(%x) does not round trip", encoded)
+		}
+
+		// Adding a point of order 2 gives the same ristretto element, but a different Ed25519 point.
+		var torsion, p edwards25519.Point
+		_, _ = torsion.SetBytes([]byte{0xec, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f})
+		_, _ = p.SetBytes(encoded)
+		p.Add(&p, &torsion)
+		if _, err := y.SetEd25519Bytes(p.Bytes()); err == nil {
+			t.Error("SetEd25519Bytes should reject a point with a torsion component")
+		}
+	}
+
+	y := NewGeneratorElement()
+	for _, invalid := range [][]byte{
+		nil,
+		make([]byte, 31),
+		// y = 2, which is not on the curve
+		{2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+	} {
+		if _, err := y.SetEd25519Bytes(invalid); err == nil {
+			t.Errorf("SetEd25519Bytes(%x) should fail", invalid)
+		}
+	}
+	if y.Equal(NewGeneratorElement()) != 1 {
+		t.Error("SetEd25519Bytes modified the receiver after an error")
+	}
+}
+
 func TestElementSet(t *testing.T) {
 	// Test this, because the internal point type being hard-copyable isn't part of the spec.
 
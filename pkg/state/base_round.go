@@ -7,12 +7,20 @@ import (
 	"github.com/taurusgroup/frost-ed25519/pkg/messages"
 )
 
+// BaseRound contains the information common to all rounds of a protocol, namely the ID of the party
+// executing it and the IDs of all parties involved.
+// It is the only base implementation of Round, and should be embedded by the first round of every protocol.
 type BaseRound struct {
-	selfID   party.ID
-	partyIDs party.IDSlice
+	selfID    party.ID
+	partyIDs  party.IDSlice
+	sessionID messages.SessionID
 }
 
+// NewBaseRound returns a BaseRound for the party selfID, executing the protocol with partyIDs.
+// partyIDs is copied and sorted, so that PartyIDs returns the IDs in increasing order.
+// An error is returned if selfID is not included in partyIDs.
 func NewBaseRound(selfID party.ID, partyIDs party.IDSlice) (*BaseRound, error) {
+	partyIDs = party.NewIDSlice(partyIDs)
 	if !partyIDs.Contains(selfID) {
 		return nil, errors.New("PartyIDs should contain selfID")
 	}
@@ -22,14 +30,27 @@ func NewBaseRound(selfID party.ID, partyIDs party.IDSlice) (*BaseRound, error) {
 	}, nil
 }
 
+// ProcessMessage implements Round, and does nothing since the first round of a protocol receives no messages.
 func (r *BaseRound) ProcessMessage(*messages.Message) *Error {
 	return nil
 }
 
+// SelfID implements Round.
 func (r BaseRound) SelfID() party.ID {
 	return r.selfID
 }
 
+// PartyIDs implements Round.
 func (r BaseRound) PartyIDs() party.IDSlice {
 	return r.partyIDs
 }
+
+// SessionID implements Round.
+// It is the zero value unless the State was created with WithSessionID.
+func (r BaseRound) SessionID() messages.SessionID {
+	return r.sessionID
+}
+
+func (r *BaseRound) base() *BaseRound {
+	return r
+}
@@ -0,0 +1,53 @@
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+// Diagnostics is a snapshot of the progress of a State, which helps understanding why an execution is stuck.
+type Diagnostics struct {
+	// RoundNumber is the number of the current round.
+	RoundNumber int
+
+	// RoundState indicates whether we are waiting for other parties, for ProcessAll to be called,
+	// or for the round to be processed.
+	RoundState RoundState
+
+	// Missing contains the sorted IDs of the parties from which no message has been received for the current round.
+	Missing party.IDSlice
+
+	// Queued is the number of messages received for future rounds.
+	Queued int
+
+	// PendingOutgoing is the number of generated messages which have not yet been read from the channel
+	// returned by Outgoing.
+	PendingOutgoing int
+
+	// LastTransition is the time at which RoundState last changed.
+	LastTransition time.Time
+}
+
+// Diagnostics returns a snapshot of the current progress of the protocol.
+// It is safe to call concurrently, and does not wait for a round being processed.
+func (s *State) Diagnostics() Diagnostics {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return Diagnostics{
+		RoundNumber:     s.roundNumber,
+		RoundState:      s.roundState(),
+		Missing:         s.missing(),
+		Queued:          len(s.queue),
+		PendingOutgoing: s.pendingOutgoing,
+		LastTransition:  s.lastTransition,
+	}
+}
+
+// Describe returns a one line summary of d, with the missing parties formatted by dir.Format.
+// dir may be nil.
+func (d Diagnostics) Describe(dir *party.Directory) string {
+	return fmt.Sprintf("round %d: %s: missing %s, %d queued, %d pending outgoing",
+		d.RoundNumber, d.RoundState, dir.FormatIDs(d.Missing), d.Queued, d.PendingOutgoing)
+}
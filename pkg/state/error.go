@@ -1,28 +1,269 @@
 package state
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
 )
 
+// ErrRoundTimeout is wrapped by the error reported when a round did not receive all its messages in time.
+var ErrRoundTimeout = errors.New("round timeout")
+
+// ErrCanceled is wrapped by the error reported when the protocol was canceled with State.Cancel.
+var ErrCanceled = errors.New("protocol canceled")
+
+// cancelError is the error reported by State.Cancel.
+// It matches ErrCanceled with errors.Is, and unwraps to the reason given by the application.
+type cancelError struct {
+	reason error
+}
+
+// Error implement error
+func (e cancelError) Error() string {
+	if e.reason == nil {
+		return ErrCanceled.Error()
+	}
+	return fmt.Sprintf("%s: %s", ErrCanceled.Error(), e.reason.Error())
+}
+
+// Is returns true if target is ErrCanceled.
+func (e cancelError) Is(target error) bool {
+	return target == ErrCanceled
+}
+
+// Unwrap returns the reason given to State.Cancel.
+func (e cancelError) Unwrap() error {
+	return e.reason
+}
+
+// equivocationError is the type of ErrEquivocation, which matches ErrDuplicateMessage with errors.Is.
+type equivocationError struct{}
+
+// Error implement error
+func (equivocationError) Error() string {
+	return "a different message of the same type was already received"
+}
+
+// Is returns true if target is ErrDuplicateMessage.
+func (equivocationError) Is(target error) bool {
+	return target == ErrDuplicateMessage
+}
+
+// The following errors are returned by State.HandleMessage, wrapped in a MessageError.
+// They indicate that the message was rejected, but do not cause the protocol to abort.
+var (
+	// ErrDuplicateMessage indicates that a message of the same type was already received from the sender.
+	// Identical retransmissions are ignored, so it is only matched by the errors wrapping ErrEquivocation.
+	ErrDuplicateMessage = errors.New("message was already received")
+
+	// ErrEquivocation indicates that the sender already sent a different message of the same type.
+	// Since honest parties never do this, the caller may want to escalate it.
+	// It also matches ErrDuplicateMessage with errors.Is.
+	ErrEquivocation error = equivocationError{}
+
+	// ErrUnexpectedSender indicates that the sender is not a party of the protocol,
+	// or does not send messages of this type when the Round is a SenderFilter.
+	ErrUnexpectedSender = errors.New("sender is not a party")
+
+	// ErrWrongMessageType indicates that the message type is not expected in the current or a later round.
+	ErrWrongMessageType = errors.New("message type is not accepted for this round")
+
+	// ErrWrongRecipient indicates that the message is addressed to a different party.
+	ErrWrongRecipient = errors.New("message is addressed to a different party")
+
+	// ErrUnknownRecipient indicates that a Packed message is addressed to a party which is not a party of the protocol,
+	// or to its sender.
+	ErrUnknownRecipient = errors.New("packed message is addressed to an unknown party")
+)
+
+// MessageError is returned by State.HandleMessage when a message is rejected.
+// The reason can be checked with errors.Is against ErrEquivocation (which matches ErrDuplicateMessage), ErrUnexpectedSender,
+// ErrWrongMessageType, ErrWrongRecipient, ErrUnknownRecipient or ErrSessionMismatch.
+type MessageError struct {
+	// From is the ID of the party which sent the message.
+	From party.ID
+
+	err error
+}
+
+// Error implement error
+func (e MessageError) Error() string {
+	return fmt.Sprintf("message from party %d: %s", e.From, e.err.Error())
+}
+
+// Unwrap returns the reason the message was rejected.
+func (e MessageError) Unwrap() error {
+	return e.err
+}
+
+// ErrorKind indicates the cause of an Error in a machine-readable way.
+type ErrorKind uint8
+
+const (
+	// KindUnknown is used when no kind was given when creating the Error.
+	KindUnknown ErrorKind = iota
+	// KindInvalidProof indicates that a zero-knowledge proof failed to verify.
+	KindInvalidProof
+	// KindVSSFailure indicates that a share did not match the sender's commitments.
+	KindVSSFailure
+	// KindInvalidCommitment indicates that a nonce commitment, or the commitments of a resharing dealer, were invalid.
+	KindInvalidCommitment
+	// KindInvalidSignatureShare indicates that a signature share failed to verify.
+	KindInvalidSignatureShare
+	// KindInvalidSignature indicates that the final signature failed to verify.
+	KindInvalidSignature
+	// KindTimeout indicates that a round did not receive all its messages in time.
+	KindTimeout
+	// KindCanceled indicates that the protocol was canceled by the caller.
+	KindCanceled
+	// KindDecryptionFailure indicates that an encrypted share could not be decrypted.
+	KindDecryptionFailure
+	// KindInconsistentBroadcast indicates that parties received different versions of a broadcast message.
+	KindInconsistentBroadcast
+	// KindExpired indicates that the session was idle for longer than its TTL.
+	KindExpired
+	// KindFalseAccusation indicates that a party accused another one of sending an invalid share,
+	// although the share it revealed is valid.
+	KindFalseAccusation
+)
+
+// String implements fmt.Stringer
+func (k ErrorKind) String() string {
+	switch k {
+	case KindInvalidProof:
+		return "invalid proof"
+	case KindVSSFailure:
+		return "VSS failure"
+	case KindInvalidCommitment:
+		return "invalid commitment"
+	case KindInvalidSignatureShare:
+		return "invalid signature share"
+	case KindInvalidSignature:
+		return "invalid signature"
+	case KindTimeout:
+		return "timeout"
+	case KindCanceled:
+		return "canceled"
+	case KindDecryptionFailure:
+		return "decryption failure"
+	case KindInconsistentBroadcast:
+		return "inconsistent broadcast"
+	case KindExpired:
+		return "expired"
+	case KindFalseAccusation:
+		return "false accusation"
+	default:
+		return "unknown"
+	}
+}
+
 // Error represents an error related to the protocol execution, and requires an abort.
-// If PartyID is 0, then it was not possible to attribute the fault to one particular party.
+// It is the error returned by State.Err and State.WaitForError,
+// and can be retrieved from a wrapped error with errors.As.
+//
+// If the culprit is 0, then the abort was not caused by a misbehaving party,
+// but by a local failure, a timeout, or a cancellation.
+//
+// When several parties misbehaved in the same round, the Error contains all of them,
+// and wraps the error caused by the party with the smallest ID.
 type Error struct {
-	PartyID     party.ID
-	RoundNumber int
+	culprit     party.ID
+	others      party.IDSlice
+	roundNumber int
+	kind        ErrorKind
 	err         error
 }
 
-// NewError wraps err in an Error and attaches the culprit's ID
+// NewError wraps err in an Error and attaches the culprit's ID.
+// partyID should be 0 if the error is not caused by another party.
 func NewError(partyID party.ID, err error) *Error {
+	return NewErrorWithKind(partyID, KindUnknown, err)
+}
+
+// NewErrorWithKind is the same as NewError, but also sets the kind of the error.
+func NewErrorWithKind(partyID party.ID, kind ErrorKind, err error) *Error {
 	return &Error{
-		PartyID: partyID,
+		culprit: partyID,
+		kind:    kind,
 		err:     err,
 	}
 }
 
 // Error implement error
 func (e Error) Error() string {
-	return fmt.Sprintf("party %d: round %d: %s", e.PartyID, e.RoundNumber, e.err.Error())
+	if len(e.others) > 0 {
+		return fmt.Sprintf("parties %v: round %d: %s", e.Culprits(), e.roundNumber, e.err.Error())
+	}
+	return fmt.Sprintf("party %d: round %d: %s", e.culprit, e.roundNumber, e.err.Error())
+}
+
+// Describe returns the same message as Error, with the culprits formatted by dir.Format,
+// for example "party alice (42): round 1: ...". A nil dir gives the same result as Error.
+func (e Error) Describe(dir *party.Directory) string {
+	if len(e.others) > 0 {
+		return fmt.Sprintf("parties %s: round %d: %s", dir.FormatIDs(e.Culprits()), e.roundNumber, e.err.Error())
+	}
+	return fmt.Sprintf("party %s: round %d: %s", dir.Format(e.culprit), e.roundNumber, e.err.Error())
+}
+
+// Unwrap returns the underlying error, so that Error can be used with errors.Is and errors.As.
+func (e Error) Unwrap() error {
+	return e.err
+}
+
+// Culprit returns the ID of the party responsible for the abort, or 0 if it was caused locally.
+// If several parties are responsible, then it returns the smallest ID.
+func (e Error) Culprit() party.ID {
+	return e.culprit
+}
+
+// Culprits returns the sorted IDs of all parties responsible for the abort,
+// or nil if it was caused locally.
+func (e Error) Culprits() party.IDSlice {
+	if e.culprit == 0 {
+		return nil
+	}
+	culprits := make(party.IDSlice, 0, 1+len(e.others))
+	culprits = append(culprits, e.culprit)
+	return append(culprits, e.others...)
+}
+
+// mergeErrors returns an Error containing all culprits of errs, and wraps the error of the first culprit.
+// errs must be sorted by culprit.
+func mergeErrors(errs []*Error) *Error {
+	err := *errs[0]
+	for _, other := range errs[1:] {
+		if other.culprit != 0 && other.culprit != err.culprit {
+			err.others = append(err.others, other.culprit)
+		}
+	}
+	return &err
+}
+
+// RoundNumber returns the number of the round in which the error occurred.
+func (e Error) RoundNumber() int {
+	return e.roundNumber
+}
+
+// Kind returns the cause of the error.
+func (e Error) Kind() ErrorKind {
+	return e.kind
+}
+
+// TimeoutError is the cause of an abort due to a round timeout.
+// It can be retrieved from the protocol's error with errors.As.
+type TimeoutError struct {
+	// Missing contains the sorted IDs of the parties from which no message was received during the round.
+	Missing party.IDSlice
+}
+
+// Error implement error
+func (e TimeoutError) Error() string {
+	return fmt.Sprintf("%s: no message received from parties %v", ErrRoundTimeout, e.Missing)
+}
+
+// Unwrap returns ErrRoundTimeout
+func (e TimeoutError) Unwrap() error {
+	return ErrRoundTimeout
 }
@@ -0,0 +1,53 @@
+package state_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// This example displays a progress bar while party 1 receives the commitments of a 5 party keygen.
+func ExampleState_Notify() {
+	partyIDs := helpers.GenerateSet(5)
+	states := map[party.ID]*state.State{}
+	for _, id := range partyIDs {
+		states[id], _, _ = frost.NewKeygenState(id, partyIDs, 2, 0)
+	}
+	var commitments []*messages.Message
+	for _, id := range partyIDs {
+		commitments = append(commitments, states[id].ProcessAll()...)
+	}
+
+	s := states[1]
+	progress := s.Notify()
+	for _, msg := range commitments {
+		if msg.From == s.SelfID() {
+			continue
+		}
+		// The messages would usually be received by another goroutine
+		_ = s.HandleMessage(msg)
+
+		<-progress
+		received, _ := s.ReceivedFrom()
+		missing, _ := s.MissingFrom()
+		fmt.Printf("[%s%s] %d/%d\n", strings.Repeat("#", len(received)), strings.Repeat(" ", len(missing)),
+			len(received), len(received)+len(missing))
+	}
+
+	s.ProcessAll()
+	if _, ok := <-progress; !ok {
+		fmt.Println("round", s.RoundNumber()-1, "is over")
+	}
+
+	// Output:
+	// [#   ] 1/4
+	// [##  ] 2/4
+	// [### ] 3/4
+	// [####] 4/4
+	// round 1 is over
+}
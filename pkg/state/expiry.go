@@ -0,0 +1,153 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// ErrSessionExpired is wrapped by the error reported when a session was idle for longer than its TTL.
+var ErrSessionExpired = errors.New("session expired")
+
+// WithTTL returns an Option which lets the session expire when it has been idle for longer than ttl,
+// that is when no message was accepted by HandleMessage and no round started during this time.
+// An expired session aborts with an error of kind KindExpired wrapping ErrSessionExpired,
+// and releases the messages it holds.
+//
+// Sessions do not expire on their own: Expire must be called, for example by a Janitor.
+// Unlike the round timeout given at creation, the TTL is meant to reclaim the memory of sessions
+// whose parties vanished, and can be much longer.
+func WithTTL(ttl time.Duration) Option {
+	return func(s *State) {
+		s.ttl = ttl
+	}
+}
+
+// Expire aborts the protocol with ErrSessionExpired if it was created with WithTTL and has been idle for longer
+// than the TTL at time now. It returns true if the session expired.
+//
+// The shares contained in the messages held by the session are set to zero before they are released.
+// A session whose round is being processed by ProcessAll is not idle, and does not expire.
+func (s *State) Expire(now time.Time) bool {
+	defer s.notify()
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.done || s.processing || s.ttl <= 0 || now.Sub(s.lastActivity) < s.ttl {
+		return false
+	}
+	s.releaseMessages()
+	s.reportError(NewErrorWithKind(0, KindExpired, ErrSessionExpired))
+	return true
+}
+
+// releaseMessages zeroes the secrets of the received and sent messages, and releases them.
+// It should be called with the lock held, while the round is not being processed.
+func (s *State) releaseMessages() {
+	for id, msg := range s.receivedMessages {
+		zeroize(msg)
+		delete(s.receivedMessages, id)
+	}
+	for i, msg := range s.queue {
+		zeroize(msg)
+		s.queue[i] = nil
+	}
+	s.queue = nil
+	s.processed = nil
+	s.pending = nil
+	// The encoded messages we sent may contain the shares of other parties
+	for _, sent := range s.sent {
+		for _, data := range sent {
+			for i := range data {
+				data[i] = 0
+			}
+		}
+	}
+	s.sent = nil
+}
+
+// zeroize sets the secret shares contained in msg to zero.
+func zeroize(msg *messages.Message) {
+	if msg.KeyGen2 != nil {
+		msg.KeyGen2.Share.Set(ristretto.NewScalar())
+		for i := range msg.KeyGen2.SealedShare {
+			msg.KeyGen2.SealedShare[i] = 0
+		}
+	}
+}
+
+// A Janitor expires the idle sessions among a set of States, so that the memory of the sessions
+// whose parties vanished is eventually released.
+// Sessions are removed from the set once they have finished, for any reason.
+//
+// It is safe to use a Janitor concurrently.
+type Janitor struct {
+	mtx      sync.Mutex
+	sessions map[*State]struct{}
+}
+
+// NewJanitor returns a Janitor without sessions.
+func NewJanitor() *Janitor {
+	return &Janitor{sessions: make(map[*State]struct{})}
+}
+
+// Add adds s to the sessions swept by the janitor. s should be created with WithTTL.
+func (j *Janitor) Add(s *State) {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+	j.sessions[s] = struct{}{}
+}
+
+// Len returns the number of sessions which have not finished yet, as of the last Sweep.
+func (j *Janitor) Len() int {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+	return len(j.sessions)
+}
+
+// Sweep calls Expire on all sessions, and removes the ones which have finished.
+// It returns the sessions which expired during this call.
+func (j *Janitor) Sweep(now time.Time) []*State {
+	j.mtx.Lock()
+	sessions := make([]*State, 0, len(j.sessions))
+	for s := range j.sessions {
+		sessions = append(sessions, s)
+	}
+	j.mtx.Unlock()
+
+	// Sessions are expired without holding the lock, so that Add is not blocked by a busy session.
+	var expired, finished []*State
+	for _, s := range sessions {
+		if s.Expire(now) {
+			expired = append(expired, s)
+		}
+		if s.IsFinished() {
+			finished = append(finished, s)
+		}
+	}
+
+	j.mtx.Lock()
+	for _, s := range finished {
+		delete(j.sessions, s)
+	}
+	j.mtx.Unlock()
+	return expired
+}
+
+// Run calls Sweep every interval until ctx is done.
+func (j *Janitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			j.Sweep(now)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package state
+
+import "github.com/taurusgroup/frost-ed25519/pkg/messages"
+
+// An ExtensionHandler receives the messages of an application defined type accepted by HandleMessage.
+// It is called after the State has released its lock, so it may call methods of the State.
+type ExtensionHandler func(msg *messages.Message)
+
+// WithExtension returns an Option which makes HandleMessage accept messages of the type t,
+// which must have been registered with messages.RegisterExtension, and pass them to handler.
+//
+// These messages are subject to the same checks of sender, recipient and session ID as the messages of the protocol,
+// but they do not affect its progression: they can be received during any round,
+// are not checked for duplicates, and are not recorded in the MessageStore.
+func WithExtension(t messages.MessageType, handler ExtensionHandler) Option {
+	return func(s *State) {
+		if s.extensions == nil {
+			s.extensions = map[messages.MessageType]ExtensionHandler{}
+		}
+		s.extensions[t] = handler
+	}
+}
+
+// handleExtension queues the delivery of msg to its ExtensionHandler, and returns false if its type is not handled.
+// It should be called with the lock held.
+func (s *State) handleExtension(msg *messages.Message) bool {
+	handler, ok := s.extensions[msg.Type]
+	if !ok {
+		return false
+	}
+	s.events = append(s.events, func() { handler(msg) })
+	return true
+}
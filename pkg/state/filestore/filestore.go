@@ -0,0 +1,169 @@
+// Package filestore implements a state.MessageStore backed by an append-only file.
+//
+// Each record is encoded as
+//
+//	Record = round (4 bytes) ∥ from (4 bytes) ∥ length (4 bytes) ∥ raw ∥ CRC-32 (4 bytes)
+//
+// where integers are big endian, and the checksum covers all preceding fields of the record.
+// Earlier versions of this package encoded from in 2 bytes, so their files are reported as corrupted,
+// and should not be opened after an upgrade.
+// A record which was only partially written when the process stopped is discarded when the file is opened,
+// since the message it contains was not acted upon.
+package filestore
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+const (
+	recordHeaderSize = 4 + party.IDByteSize + 4
+	checksumSize     = 4
+)
+
+// ErrCorrupted is returned when a complete record of the file does not match its checksum.
+var ErrCorrupted = errors.New("filestore: corrupted record")
+
+// Store is a state.MessageStore which appends records to a file.
+// It is safe for concurrent use.
+type Store struct {
+	mtx  sync.Mutex
+	file *os.File
+	sync bool
+}
+
+// An Option modifies the configuration of a Store when it is opened.
+type Option func(*Store)
+
+// WithoutSync returns an Option which makes Append return without waiting for the record to be written to disk.
+// Records then survive a crash of the process, but not of the machine, unless Sync is called.
+func WithoutSync() Option {
+	return func(s *Store) {
+		s.sync = false
+	}
+}
+
+// Open opens the file at path, creating it if it does not exist, and returns a Store which appends records to it.
+// A partially written record at the end of the file is removed.
+func Open(path string, opts ...Option) (*Store, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("filestore.Open: %w", err)
+	}
+	s := &Store{
+		file: file,
+		sync: true,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	_, end, err := s.load()
+	if err == nil {
+		err = file.Truncate(end)
+	}
+	if err == nil {
+		_, err = file.Seek(end, io.SeekStart)
+	}
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("filestore.Open: %w", err)
+	}
+	return s, nil
+}
+
+// Append implements state.MessageStore.
+func (s *Store) Append(round int, from party.ID, raw []byte) error {
+	if round < 0 || uint64(round) > 0xffffffff || uint64(len(raw)) > 0xffffffff {
+		return errors.New("filestore.Append: record is too large")
+	}
+	data := make([]byte, recordHeaderSize, recordHeaderSize+len(raw)+checksumSize)
+	binary.BigEndian.PutUint32(data, uint32(round))
+	binary.BigEndian.PutUint32(data[4:], uint32(from))
+	binary.BigEndian.PutUint32(data[4+party.IDByteSize:], uint32(len(raw)))
+	data = append(data, raw...)
+	data = append(data, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[len(data)-checksumSize:], crc32.ChecksumIEEE(data[:len(data)-checksumSize]))
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if _, err := s.file.Write(data); err != nil {
+		return fmt.Errorf("filestore.Append: %w", err)
+	}
+	if s.sync {
+		if err := s.file.Sync(); err != nil {
+			return fmt.Errorf("filestore.Append: %w", err)
+		}
+	}
+	return nil
+}
+
+// Load implements state.MessageStore.
+func (s *Store) Load() ([]state.Record, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	records, _, err := s.load()
+	if err != nil {
+		return nil, fmt.Errorf("filestore.Load: %w", err)
+	}
+	return records, nil
+}
+
+// load reads all complete records of the file, and returns them with the offset following the last one.
+func (s *Store) load() ([]state.Record, int64, error) {
+	info, err := s.file.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	data := make([]byte, info.Size())
+	if _, err = s.file.ReadAt(data, 0); err != nil && err != io.EOF {
+		return nil, 0, err
+	}
+
+	var (
+		records []state.Record
+		offset  int64
+	)
+	for len(data) >= recordHeaderSize {
+		length := int64(binary.BigEndian.Uint32(data[4+party.IDByteSize:]))
+		size := recordHeaderSize + length + checksumSize
+		if int64(len(data)) < size {
+			break
+		}
+		checksum := binary.BigEndian.Uint32(data[size-checksumSize:])
+		if crc32.ChecksumIEEE(data[:size-checksumSize]) != checksum {
+			return nil, 0, fmt.Errorf("record at offset %d: %w", offset, ErrCorrupted)
+		}
+		records = append(records, state.Record{
+			Round: int(binary.BigEndian.Uint32(data)),
+			From:  party.ID(binary.BigEndian.Uint32(data[4:])),
+			Raw:   append([]byte{}, data[recordHeaderSize:size-checksumSize]...),
+		})
+		data = data[size:]
+		offset += size
+	}
+	return records, offset, nil
+}
+
+// Sync writes the records appended so far to disk.
+// It is only needed if the Store was opened with WithoutSync.
+func (s *Store) Sync() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.file.Sync()
+}
+
+// Close closes the file.
+func (s *Store) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.file.Close()
+}
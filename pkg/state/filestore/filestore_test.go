@@ -0,0 +1,92 @@
+package filestore_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+	"github.com/taurusgroup/frost-ed25519/pkg/state/filestore"
+)
+
+var testRecords = []state.Record{
+	{Round: 0, From: 2, Raw: []byte("first")},
+	{Round: 1, From: 3, Raw: []byte("second message")},
+	{Round: 1, From: 2, Raw: []byte{}},
+}
+
+func appendAll(t *testing.T, s *filestore.Store, records []state.Record) {
+	for _, r := range records {
+		require.NoError(t, s.Append(r.Round, r.From, r.Raw))
+	}
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.log")
+	s, err := filestore.Open(path)
+	require.NoError(t, err)
+	records, err := s.Load()
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	appendAll(t, s, testRecords[:2])
+	records, err = s.Load()
+	require.NoError(t, err)
+	assert.Equal(t, testRecords[:2], records)
+	require.NoError(t, s.Close())
+
+	// Records are kept when the file is opened again, and new ones are appended
+	s, err = filestore.Open(path, filestore.WithoutSync())
+	require.NoError(t, err)
+	appendAll(t, s, testRecords[2:])
+	require.NoError(t, s.Sync())
+	records, err = s.Load()
+	require.NoError(t, err)
+	assert.Equal(t, testRecords, records)
+	require.NoError(t, s.Close())
+}
+
+func TestStore_PartialRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.log")
+	s, err := filestore.Open(path)
+	require.NoError(t, err)
+	appendAll(t, s, testRecords[:2])
+	require.NoError(t, s.Close())
+
+	// Simulate a crash while the second record was being written
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()-3))
+
+	s, err = filestore.Open(path)
+	require.NoError(t, err)
+	records, err := s.Load()
+	require.NoError(t, err)
+	assert.Equal(t, testRecords[:1], records)
+
+	// The partial record was removed, so that new records can be read back
+	appendAll(t, s, testRecords[2:])
+	records, err = s.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []state.Record{testRecords[0], testRecords[2]}, records)
+	require.NoError(t, s.Close())
+}
+
+func TestStore_Corrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.log")
+	s, err := filestore.Open(path)
+	require.NoError(t, err)
+	appendAll(t, s, testRecords)
+	require.NoError(t, s.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data[12] ^= 1
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	_, err = filestore.Open(path)
+	assert.True(t, errors.Is(err, filestore.ErrCorrupted), err)
+}
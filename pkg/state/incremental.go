@@ -0,0 +1,106 @@
+package state
+
+import (
+	"crypto/sha256"
+	"sort"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+)
+
+// An IncrementalRound is a Round which can process some of its messages as soon as they are accepted by HandleMessage,
+// instead of waiting for the messages of all parties, such as the first round of a keygen whose messages contain
+// large commitments. The State then only keeps a digest of each processed message, to detect retransmissions
+// and equivocations, so that it does not hold the messages of all parties at the same time.
+//
+// ProcessMessage is then called while the State is locked, and must not call methods of the State.
+// The errors it returns are reported by ProcessAll once all messages of the round were received,
+// as if the messages had been processed at that time.
+type IncrementalRound interface {
+	// ProcessIncrementally returns true if the messages of type msgType may be processed as soon as they are accepted.
+	// It is called with the lock of the State held, and must not modify the round.
+	ProcessIncrementally(msgType messages.MessageType) bool
+}
+
+// processIncrementally gives msg to the current round if it is an IncrementalRound which accepts it,
+// and returns true if it did.
+// It should be called with the lock held, for a message of the current round.
+func (s *State) processIncrementally(msg *messages.Message) bool {
+	incremental, ok := s.round.(IncrementalRound)
+	if !ok || s.processing || s.partial || !incremental.ProcessIncrementally(msg.Type) {
+		return false
+	}
+	digest, ok := digestOf(msg)
+	if !ok {
+		return false
+	}
+	if err := s.round.ProcessMessage(msg); err != nil {
+		s.pending = append(s.pending, err)
+	}
+	s.processed[msg.From] = digest
+	return true
+}
+
+// processReceived gives the messages of the current round received so far to the round, if it is an IncrementalRound.
+// It should be called with the lock held, when a round starts.
+func (s *State) processReceived() {
+	for _, id := range s.round.PartyIDs() {
+		if msg, ok := s.receivedMessages[id]; ok && s.processIncrementally(msg) {
+			delete(s.receivedMessages, id)
+		}
+	}
+}
+
+// checkProcessed returns true if a message of the current round from the sender of msg was already processed.
+// The error is ErrEquivocation if msg differs from it.
+// It should be called with the lock held.
+func (s *State) checkProcessed(msg *messages.Message) (bool, error) {
+	if len(s.acceptedTypes) == 0 || msg.Type != s.acceptedTypes[0] {
+		return false, nil
+	}
+	digest, ok := s.processed[msg.From]
+	if !ok {
+		return false, nil
+	}
+	if other, ok := digestOf(msg); !ok || other != digest {
+		return true, ErrEquivocation
+	}
+	return true, nil
+}
+
+// received returns the number of messages received for the current round, including the processed ones.
+// It should be called with the lock held.
+func (s *State) received() int {
+	return len(s.receivedMessages) + len(s.processed)
+}
+
+// hasReceived returns true if a message from id was received for the current round.
+// It should be called with the lock held.
+func (s *State) hasReceived(id party.ID) bool {
+	if _, ok := s.receivedMessages[id]; ok {
+		return true
+	}
+	_, ok := s.processed[id]
+	return ok
+}
+
+// takePending returns the errors of the messages processed incrementally during the current round,
+// in increasing order of their culprits.
+// It should be called with the lock held.
+func (s *State) takePending() []*Error {
+	pending := s.pending
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].culprit < pending[j].culprit
+	})
+	s.pending = nil
+	return pending
+}
+
+// digestOf returns the SHA-256 digest of the binary encoding of msg.
+func digestOf(msg *messages.Message) ([sha256.Size]byte, bool) {
+	data, err := msg.MarshalBinary()
+	if err != nil {
+		return [sha256.Size]byte{}, false
+	}
+	return sha256.Sum256(data), true
+}
@@ -0,0 +1,34 @@
+//go:build go1.23
+
+package state
+
+import (
+	"iter"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+)
+
+// ReceivedMessages returns an iterator over the messages received for the current round,
+// with the ID of their sender, in increasing order of the senders.
+// It yields nothing if the current round does not expect any messages from other parties,
+// and skips the messages already processed by an IncrementalRound.
+//
+// The lock of the State is held during the iteration, so the loop body must not call methods of the State,
+// and must not modify the messages.
+func (s *State) ReceivedMessages() iter.Seq2[party.ID, *messages.Message] {
+	return func(yield func(party.ID, *messages.Message) bool) {
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+		if !s.expectsMessages() {
+			return
+		}
+		for _, id := range s.round.PartyIDs() {
+			if msg, ok := s.receivedMessages[id]; ok {
+				if !yield(id, msg) {
+					return
+				}
+			}
+		}
+	}
+}
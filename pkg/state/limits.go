@@ -0,0 +1,38 @@
+package state
+
+import "github.com/taurusgroup/frost-ed25519/pkg/messages"
+
+// A Limiter is a Round whose messages have a size depending on the parameters of the protocol,
+// such as the number of commitments in KeyGen1 messages.
+// Rounds which do not implement it are assumed to only use messages of constant size.
+type Limiter interface {
+	// MessageLimits returns the limits which the messages of the protocol must respect.
+	MessageLimits() messages.Limits
+}
+
+// limitsOf returns the messages.Limits of the protocol starting at round.
+func limitsOf(round Round) messages.Limits {
+	if limiter, ok := round.(Limiter); ok {
+		return limiter.MessageLimits()
+	}
+	return messages.Limits{}
+}
+
+// MaxMessageSize returns the maximum size of the binary encoding of a message of this protocol execution.
+// Transports can use it to reject larger frames before decoding them, for example with messages.Limits.UnmarshalBinary.
+// Note that the JSON and CBOR encodings of a message are larger than its binary encoding.
+func (s *State) MaxMessageSize() int {
+	// MaxSize is 0 for Packed messages if the protocol does not set messages.Limits.Parties
+	max := s.limits.MaxMessageSize(append(s.protocolTypes, messages.MessageTypePacked)...)
+	for t := range s.extensions {
+		if size := s.limits.MaxSize(t); size > max {
+			max = size
+		}
+	}
+	return max
+}
+
+// MessageLimits returns the limits which the messages of this protocol execution must respect.
+func (s *State) MessageLimits() messages.Limits {
+	return s.limits
+}
@@ -0,0 +1,114 @@
+package state
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+)
+
+// Metrics receives counters and timings from a protocol execution, and can be used to export them
+// to a monitoring system.
+//
+// As for the Observer, the methods are called after the State has released its internal lock,
+// and may be called concurrently.
+type Metrics interface {
+	// MessageStored is called when a message of type t from party from was accepted by HandleMessage
+	// during round.
+	MessageStored(round int, from party.ID, t messages.MessageType)
+
+	// MessageRejected is called when HandleMessage rejects a message from party from during round.
+	// reason is the cause of the rejection, such as ErrEquivocation or ErrWrongMessageType.
+	MessageRejected(round int, from party.ID, reason error)
+
+	// RoundProcessingStarted is called when all messages for round have been received,
+	// and the round starts being processed.
+	// waited is the time elapsed since the start of the round.
+	RoundProcessingStarted(round int, waited time.Duration)
+
+	// RoundProcessingFinished is called when round has been processed in d.
+	// err is nil if the round was processed successfully.
+	RoundProcessingFinished(round int, d time.Duration, err error)
+
+	// ProtocolFinished is called once when the protocol finishes successfully, d after it started.
+	ProtocolFinished(d time.Duration)
+
+	// ProtocolAborted is called once when the protocol aborts.
+	// culprits contains the parties responsible for the abort, and is empty if the error was caused locally.
+	ProtocolAborted(culprits []party.ID, err error)
+}
+
+// WithMetrics returns an Option which sets the Metrics updated during the protocol execution.
+// By default, no metrics are collected.
+func WithMetrics(metrics Metrics) Option {
+	return func(s *State) {
+		s.metrics = metrics
+	}
+}
+
+// record queues f so that it is applied to the Metrics by the next call to notify.
+// It should be called with the lock held.
+func (s *State) record(f func(Metrics)) {
+	if s.metrics == nil {
+		return
+	}
+	metrics := s.metrics
+	s.events = append(s.events, func() { f(metrics) })
+}
+
+// NopMetrics is a Metrics which ignores all calls.
+// It can be embedded in a struct which only implements some of the methods.
+type NopMetrics struct{}
+
+func (NopMetrics) MessageStored(int, party.ID, messages.MessageType) {}
+func (NopMetrics) MessageRejected(int, party.ID, error)              {}
+func (NopMetrics) RoundProcessingStarted(int, time.Duration)         {}
+func (NopMetrics) RoundProcessingFinished(int, time.Duration, error) {}
+func (NopMetrics) ProtocolFinished(time.Duration)                    {}
+func (NopMetrics) ProtocolAborted([]party.ID, error)                 {}
+
+// MetricsRecorder is a Metrics which records a description of every call, without the timings.
+// It is meant to be used in tests.
+type MetricsRecorder struct {
+	mtx   sync.Mutex
+	calls []string
+}
+
+// Calls returns the description of all calls recorded so far, in order.
+func (r *MetricsRecorder) Calls() []string {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+func (r *MetricsRecorder) add(format string, args ...interface{}) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.calls = append(r.calls, fmt.Sprintf(format, args...))
+}
+
+func (r *MetricsRecorder) MessageStored(round int, from party.ID, t messages.MessageType) {
+	r.add("MessageStored(%d, %d, %d)", round, from, t)
+}
+
+func (r *MetricsRecorder) MessageRejected(round int, from party.ID, reason error) {
+	r.add("MessageRejected(%d, %d, %v)", round, from, reason)
+}
+
+func (r *MetricsRecorder) RoundProcessingStarted(round int, _ time.Duration) {
+	r.add("RoundProcessingStarted(%d)", round)
+}
+
+func (r *MetricsRecorder) RoundProcessingFinished(round int, _ time.Duration, err error) {
+	r.add("RoundProcessingFinished(%d, %v)", round, err)
+}
+
+func (r *MetricsRecorder) ProtocolFinished(time.Duration) {
+	r.add("ProtocolFinished")
+}
+
+func (r *MetricsRecorder) ProtocolAborted(culprits []party.ID, _ error) {
+	r.add("ProtocolAborted(%v)", culprits)
+}
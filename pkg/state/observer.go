@@ -0,0 +1,67 @@
+package state
+
+import (
+	"time"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+)
+
+// An Observer is notified of the progress of a protocol execution, and can be used for logging or tracing.
+//
+// The methods are called after the State has released its internal lock,
+// so they may safely call methods of the State, and a slow Observer does not block other goroutines
+// interacting with the State.
+// Since the State can be used concurrently, the methods may also be called concurrently,
+// and the order of events triggered by different goroutines is not guaranteed.
+type Observer interface {
+	// OnRoundStart is called when the protocol starts waiting for the messages of round.
+	OnRoundStart(round int)
+
+	// OnMessageStored is called when a message of type t from party from was accepted by HandleMessage.
+	OnMessageStored(from party.ID, t messages.MessageType)
+
+	// OnRoundFinish is called when round has been processed successfully.
+	// d is the time elapsed since the start of the round.
+	OnRoundFinish(round int, d time.Duration)
+
+	// OnAbort is called once when the protocol aborts.
+	// culprits contains the parties responsible for the abort, and is empty if the error was caused locally.
+	OnAbort(culprits []party.ID, err error)
+}
+
+// WithObserver returns an Option which sets the Observer notified of the protocol's progress.
+func WithObserver(observer Observer) Option {
+	return func(s *State) {
+		s.observer = observer
+	}
+}
+
+// event is a notification for the Observer, which is delivered once the lock has been released.
+type event func(Observer)
+
+// emit queues e so that it is delivered by the next call to notify.
+// It should be called with the lock held.
+func (s *State) emit(e event) {
+	if s.observer == nil {
+		return
+	}
+	observer := s.observer
+	s.events = append(s.events, func() { e(observer) })
+}
+
+// notify delivers all pending events to the Observer, Metrics and ExtensionHandlers.
+// It must be called without holding the lock.
+func (s *State) notify() {
+	if s.observer == nil && s.metrics == nil && s.extensions == nil {
+		return
+	}
+	s.mtx.Lock()
+	events := s.events
+	s.events = nil
+	s.mtx.Unlock()
+
+	for _, e := range events {
+		e()
+	}
+}
@@ -0,0 +1,40 @@
+package state
+
+import (
+	"errors"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+)
+
+// An Option modifies the configuration of a State when it is created by NewBaseState.
+type Option func(*State)
+
+// ErrSessionMismatch is returned by HandleMessage when a message belongs to a different protocol execution.
+// The message is rejected, but the protocol is not aborted.
+var ErrSessionMismatch = errors.New("message has a different session ID")
+
+// WithSessionID returns an Option which binds the protocol execution to sessionID.
+// All outgoing messages contain sessionID, and incoming messages with a different one are rejected.
+// The session ID is also included in the proofs and hashes computed during the protocol.
+//
+// All parties must use the same sessionID, which should be unique for each execution.
+// It can be obtained with messages.DeriveSessionID.
+func WithSessionID(sessionID messages.SessionID) Option {
+	return func(s *State) {
+		s.round.base().sessionID = sessionID
+	}
+}
+
+// WithCodec returns an Option which sets the messages.Codec used to encode the messages of this execution
+// for transport. It is returned by State.Codec, and all parties must use the same one.
+// By default, messages.BinaryCodec is used.
+func WithCodec(codec messages.Codec) Option {
+	return func(s *State) {
+		s.codec = codec
+	}
+}
+
+// Codec returns the messages.Codec which should be used to encode and decode the messages of this execution.
+func (s *State) Codec() messages.Codec {
+	return s.codec
+}
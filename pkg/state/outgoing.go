@@ -0,0 +1,85 @@
+package state
+
+import (
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+)
+
+// Outgoing returns a channel on which the messages generated by the protocol are delivered,
+// as an alternative to calling ProcessAll.
+//
+// After the first call to Outgoing, the State processes the rounds by itself as soon as
+// all their messages have been received through HandleMessage, and ProcessAll should no longer be called.
+// Broadcast messages are delivered once, and point-to-point messages once per recipient.
+//
+// Messages are buffered internally, so a slow consumer does not block the protocol.
+// The channel is closed once the protocol has finished or aborted, and all messages were delivered.
+// The caller must read from the channel until it is closed, otherwise resources are leaked.
+func (s *State) Outgoing() <-chan *messages.Message {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.outgoing == nil {
+		s.outgoing = make(chan *messages.Message)
+		s.kick = make(chan struct{}, 1)
+		go s.drive(s.outgoing, s.kick)
+	}
+	return s.outgoing
+}
+
+// signalReceived notifies the goroutine started by Outgoing that a message was received.
+// It should be called with the lock held.
+func (s *State) signalReceived() {
+	if s.kick == nil {
+		return
+	}
+	select {
+	case s.kick <- struct{}{}:
+	default:
+	}
+}
+
+// drive processes the rounds when they are ready, and sends the generated messages to out.
+func (s *State) drive(out chan<- *messages.Message, kick <-chan struct{}) {
+	defer close(out)
+
+	var pending []*messages.Message
+	for {
+		pending = append(pending, s.ProcessAll()...)
+		s.setPendingOutgoing(len(pending))
+
+		select {
+		case <-s.Done():
+			// Deliver the remaining messages
+			for i, msg := range pending {
+				out <- msg
+				s.setPendingOutgoing(len(pending) - i - 1)
+			}
+			return
+		default:
+		}
+
+		// We only try to send if there is a message, since sending on a nil channel blocks.
+		var (
+			send chan<- *messages.Message
+			next *messages.Message
+		)
+		if len(pending) > 0 {
+			send, next = out, pending[0]
+		}
+		select {
+		case send <- next:
+			pending[0] = nil
+			pending = pending[1:]
+			s.setPendingOutgoing(len(pending))
+		case <-kick:
+		case <-s.Done():
+		}
+	}
+}
+
+// setPendingOutgoing records the number of messages waiting to be delivered on the Outgoing channel.
+func (s *State) setPendingOutgoing(n int) {
+	s.mtx.Lock()
+	s.pendingOutgoing = n
+	s.mtx.Unlock()
+}
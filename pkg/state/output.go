@@ -0,0 +1,47 @@
+package state
+
+import (
+	"context"
+	"sync"
+)
+
+// Output gives typed access to the result of a protocol execution.
+type Output[T any] struct {
+	s    *State
+	get  func() T
+	once sync.Once
+
+	value T
+	err   error
+}
+
+// NewOutput returns an Output for the protocol executed by s.
+// Once the protocol has finished successfully, get is called once to obtain the result.
+// It should return a deep copy, so that callers cannot modify the internal state of the protocol.
+func NewOutput[T any](s *State, get func() T) *Output[T] {
+	return &Output[T]{
+		s:   s,
+		get: get,
+	}
+}
+
+// WaitFor blocks until the protocol is done, and then returns its result.
+// If the protocol aborted, then the error is the same as the one returned by State.WaitForError.
+// If ctx is done first, then ctx.Err() is returned, but the protocol is not aborted.
+//
+// Every call returns the same value once the protocol is done.
+func (o *Output[T]) WaitFor(ctx context.Context) (T, error) {
+	select {
+	case <-o.s.Done():
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+
+	o.once.Do(func() {
+		if o.err = o.s.Err(); o.err == nil {
+			o.value = o.get()
+		}
+	})
+	return o.value, o.err
+}
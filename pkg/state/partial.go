@@ -0,0 +1,24 @@
+package state
+
+import (
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+)
+
+// A PartialRound is a Round which can be processed without the messages of some parties,
+// such as the rounds of a keygen which tolerates dropouts.
+// Rounds which do not implement it abort with an error wrapping ErrRoundTimeout when a message is missing.
+type PartialRound interface {
+	// AcceptMissing returns true if the round can be processed without messages from the parties in missing,
+	// which were not received before the round timed out.
+	// It is called with the lock of the State held, and must not modify the round.
+	AcceptMissing(missing party.IDSlice) bool
+}
+
+// acceptMissing returns true if the current round can be processed without the messages from missing.
+// It should be called with the lock held.
+func (s *State) acceptMissing(missing party.IDSlice) bool {
+	if partial, ok := s.round.(PartialRound); ok {
+		return partial.AcceptMissing(missing)
+	}
+	return false
+}
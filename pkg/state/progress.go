@@ -0,0 +1,50 @@
+package state
+
+// Notify returns a channel which receives a value whenever a message for the current round is accepted
+// by HandleMessage, and when all messages of the round have been received,
+// or when the round is a PartialRound which can be processed without the missing messages after a timeout.
+// It allows following the progress of a round with ReceivedFrom, for example to display how many parties
+// have sent their commitments.
+//
+// The channel is never blocking for HandleMessage: if the previous notification was not yet received,
+// the new one is merged with it, so the receiver should call ReceivedFrom and MissingFrom after each notification
+// rather than count them.
+// If messages were already received when Notify is called, a notification is pending immediately.
+//
+// The channel is closed once the round is over, either because the protocol moved on to the next round,
+// or because it finished. Notify must then be called again to follow the next round.
+func (s *State) Notify() <-chan struct{} {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.progress == nil {
+		s.progress = make(chan struct{}, 1)
+		if s.done {
+			close(s.progress)
+		} else if s.received() > 0 {
+			s.progress <- struct{}{}
+		}
+	}
+	return s.progress
+}
+
+// signalProgress notifies the channel returned by Notify that a message for the current round was received.
+// It should be called with the lock held.
+func (s *State) signalProgress() {
+	if s.progress == nil {
+		return
+	}
+	select {
+	case s.progress <- struct{}{}:
+	default:
+	}
+}
+
+// closeProgress closes the channel returned by Notify for the round which just ended.
+// It should be called with the lock held.
+func (s *State) closeProgress() {
+	if s.progress != nil {
+		close(s.progress)
+		s.progress = nil
+	}
+}
@@ -0,0 +1,38 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+)
+
+// ErrNoMessages is returned by ResendMessages when no messages are available for the requested round.
+var ErrNoMessages = errors.New("no messages available for this round")
+
+// ResendMessages returns copies of the messages which were returned by ProcessAll
+// when processing round roundNumber, so that they can be sent again to parties which did not receive them.
+// The messages are encoded identically to the original ones, and are ignored by parties which already received them.
+//
+// Only the messages of the last two processed rounds are available, and none once the protocol has finished,
+// since they may contain the shares of other parties.
+// Otherwise, the returned error wraps ErrNoMessages.
+func (s *State) ResendMessages(roundNumber int) ([]*messages.Message, error) {
+	// The messages are decoded while holding the lock, since Expire zeroes them
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	sent, ok := s.sent[roundNumber]
+	if !ok {
+		return nil, fmt.Errorf("round %d: %w", roundNumber, ErrNoMessages)
+	}
+
+	msgs := make([]*messages.Message, 0, len(sent))
+	for _, data := range sent {
+		var msg messages.Message
+		if err := msg.UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("round %d: %w", roundNumber, err)
+		}
+		msgs = append(msgs, &msg)
+	}
+	return msgs, nil
+}
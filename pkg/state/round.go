@@ -70,6 +70,14 @@ type Round interface {
 	// SelfID returns the ID of the round participant
 	SelfID() party.ID
 
-	// PartyIDs returns a set containing all parties participating in the round
+	// PartyIDs returns a set containing all parties participating in the round, in increasing order.
+	// Rounds iterate over it rather than over maps indexed by party.ID, so that all parties perform their
+	// computations and generate their messages in the same order.
 	PartyIDs() party.IDSlice
+
+	// SessionID returns the identifier of the protocol execution
+	SessionID() messages.SessionID
+
+	// base returns the embedded BaseRound, so that the State can configure it.
+	base() *BaseRound
 }
@@ -0,0 +1,70 @@
+package state
+
+import "fmt"
+
+// RoundState describes what the State is currently doing.
+type RoundState uint8
+
+const (
+	// RoundStateWaitingForMessages indicates that the current round has not yet received all its messages.
+	RoundStateWaitingForMessages RoundState = iota
+	// RoundStateReadyToProcess indicates that all messages for the current round were received,
+	// and that the round will be processed by the next call to ProcessAll.
+	RoundStateReadyToProcess
+	// RoundStateProcessing indicates that ProcessAll is currently processing the round.
+	RoundStateProcessing
+	// RoundStateFinished indicates that the protocol has finished successfully.
+	RoundStateFinished
+	// RoundStateAborted indicates that the protocol was aborted.
+	RoundStateAborted
+)
+
+// String implements fmt.Stringer
+func (rs RoundState) String() string {
+	switch rs {
+	case RoundStateWaitingForMessages:
+		return "WaitingForMessages"
+	case RoundStateReadyToProcess:
+		return "ReadyToProcess"
+	case RoundStateProcessing:
+		return "Processing"
+	case RoundStateFinished:
+		return "Finished"
+	case RoundStateAborted:
+		return "Aborted"
+	default:
+		return fmt.Sprintf("RoundState(%d)", uint8(rs))
+	}
+}
+
+// RoundState returns what the State is currently doing, which is useful for debugging a stuck execution.
+func (s *State) RoundState() RoundState {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.roundState()
+}
+
+// roundState returns the current RoundState.
+// It should be called with the lock held.
+func (s *State) roundState() RoundState {
+	switch {
+	case s.done && s.err != nil:
+		return RoundStateAborted
+	case s.done:
+		return RoundStateFinished
+	case s.processing:
+		return RoundStateProcessing
+	case s.receivedAll():
+		return RoundStateReadyToProcess
+	default:
+		return RoundStateWaitingForMessages
+	}
+}
+
+// RoundNumber returns the number of the current round, starting at 0.
+// Once the protocol is finished, it is the number of the last round.
+func (s *State) RoundNumber() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.roundNumber
+}
@@ -0,0 +1,31 @@
+package state
+
+import (
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+)
+
+// A SenderFilter is a Round of a protocol in which only some of the parties send messages of a given type,
+// such as the dealers of a resharing.
+// Rounds which do not implement it expect a message of each type from every other party.
+type SenderFilter interface {
+	// Senders returns the parties from which we expect a message of type msgType, in increasing order.
+	// It must not depend on the current round, and may include our own ID, which is ignored.
+	Senders(msgType messages.MessageType) party.IDSlice
+}
+
+// senders returns the parties other than ourselves from which we expect a message of type msgType.
+// It should be called with the lock held.
+func (s *State) senders(msgType messages.MessageType) party.IDSlice {
+	partyIDs := s.round.PartyIDs()
+	if filter, ok := s.round.(SenderFilter); ok {
+		partyIDs = filter.Senders(msgType)
+	}
+	senders := make(party.IDSlice, 0, len(partyIDs))
+	for _, id := range partyIDs {
+		if id != s.round.SelfID() {
+			senders = append(senders, id)
+		}
+	}
+	return senders
+}
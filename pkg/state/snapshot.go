@@ -0,0 +1,262 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+)
+
+// ErrSnapshotNotSupported is returned by State.Snapshot when the current round cannot be serialized.
+var ErrSnapshotNotSupported = errors.New("round does not support snapshots")
+
+// A Snapshotter is a Round whose internal state can be serialized,
+// so that the protocol can later be resumed with RestoreState.
+//
+// The serialized state will most likely contain secret values, and should therefore be stored securely.
+type Snapshotter interface {
+	// Snapshot returns the serialized state of the round.
+	// It may return an error if it is not safe to serialize the round at this point of the protocol.
+	Snapshot() ([]byte, error)
+}
+
+// Snapshot contains everything required to resume a protocol execution after a restart.
+type Snapshot struct {
+	// RoundNumber is the number of the round which is waiting for messages.
+	RoundNumber int
+
+	// SessionID is the session ID of the protocol execution.
+	SessionID messages.SessionID
+
+	// Round is the serialized state of the round, as returned by Snapshotter.Snapshot.
+	Round []byte
+
+	// Messages contains all messages received for the current and future rounds,
+	// which have not yet been processed.
+	Messages []*messages.Message
+
+	// Processed contains the digests of the messages of the current round which were already processed
+	// by an IncrementalRound, indexed by their sender. Their effect is contained in Round.
+	Processed map[party.ID][sha256.Size]byte
+}
+
+// Snapshot returns the current state of the protocol, so that it can be resumed later with RestoreState.
+// It should be called after the messages returned by ProcessAll have been sent.
+// An error is returned if the protocol is finished, or if the current round does not implement Snapshotter.
+func (s *State) Snapshot() (*Snapshot, error) {
+	s.processMtx.Lock()
+	defer s.processMtx.Unlock()
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.done {
+		return nil, errors.New("state.Snapshot: protocol already finished")
+	}
+	if len(s.pending) > 0 {
+		return nil, errors.New("state.Snapshot: invalid messages were received in the current round")
+	}
+
+	snapshotter, ok := s.round.(Snapshotter)
+	if !ok {
+		return nil, ErrSnapshotNotSupported
+	}
+	roundData, err := snapshotter.Snapshot()
+	if err != nil {
+		return nil, fmt.Errorf("state.Snapshot: %w", err)
+	}
+
+	msgs := make([]*messages.Message, 0, len(s.receivedMessages)+len(s.queue))
+	for _, id := range s.round.PartyIDs() {
+		if msg, ok := s.receivedMessages[id]; ok {
+			msgs = append(msgs, msg)
+		}
+	}
+	msgs = append(msgs, s.queue...)
+
+	var processed map[party.ID][sha256.Size]byte
+	if len(s.processed) > 0 {
+		processed = make(map[party.ID][sha256.Size]byte, len(s.processed))
+		for id, digest := range s.processed {
+			processed[id] = digest
+		}
+	}
+
+	return &Snapshot{
+		RoundNumber: s.roundNumber,
+		SessionID:   s.round.SessionID(),
+		Round:       roundData,
+		Messages:    msgs,
+		Processed:   processed,
+	}, nil
+}
+
+// RestoreState returns a State which resumes the execution of a protocol from a Snapshot.
+// round must be the round restored from snapshot.Round, and timeout and opts have the same meaning as in NewBaseState.
+// The messages contained in the snapshot are handled again, as if they were just received,
+// but they are not recorded in the MessageStore since they were already recorded when first received.
+// Messages received after the snapshot was taken can then be recovered with ReplayMessages.
+// The retransmissions of the messages in snapshot.Processed are ignored, since their effect is part of round.
+func RestoreState(round Round, snapshot *Snapshot, timeout time.Duration, opts ...Option) (*State, error) {
+	var err error
+	s := newState(round, timeout, opts)
+
+	if snapshot.RoundNumber < 0 || snapshot.RoundNumber >= len(s.acceptedTypes) {
+		return nil, fmt.Errorf("state.RestoreState: invalid round number %d", snapshot.RoundNumber)
+	}
+
+	s.mtx.Lock()
+	s.round.base().sessionID = snapshot.SessionID
+	s.roundNumber = snapshot.RoundNumber
+	s.acceptedTypes = s.acceptedTypes[snapshot.RoundNumber:]
+	for id, digest := range snapshot.Processed {
+		s.processed[id] = digest
+	}
+	s.startRound()
+	s.mtx.Unlock()
+	s.notify()
+
+	for _, msg := range snapshot.Messages {
+		if err = s.handleMessage(msg, false); err != nil {
+			return nil, fmt.Errorf("state.RestoreState: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (snap *Snapshot) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, 2+messages.SessionIDSize+4+len(snap.Round)+4)
+	data = appendUint16(data, uint16(snap.RoundNumber))
+	data = append(data, snap.SessionID[:]...)
+	data = appendUint32(data, uint32(len(snap.Round)))
+	data = append(data, snap.Round...)
+	data = appendUint32(data, uint32(len(snap.Messages)))
+	for _, msg := range snap.Messages {
+		msgData, err := msg.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("Snapshot.MarshalBinary: %w", err)
+		}
+		data = appendUint32(data, uint32(len(msgData)))
+		data = append(data, msgData...)
+	}
+	// The digests are only appended if some messages were processed, so that older snapshots can still be decoded
+	if len(snap.Processed) > 0 {
+		ids := make(party.IDSlice, 0, len(snap.Processed))
+		for id := range snap.Processed {
+			ids = append(ids, id)
+		}
+		ids = party.NewIDSlice(ids)
+		data = appendUint32(data, uint32(len(ids)))
+		for _, id := range ids {
+			digest := snap.Processed[id]
+			data = append(data, id.Bytes()...)
+			data = append(data, digest[:]...)
+		}
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (snap *Snapshot) UnmarshalBinary(data []byte) error {
+	var (
+		roundNumber uint16
+		sessionID   messages.SessionID
+		roundData   []byte
+		count       uint32
+		ok          bool
+	)
+	errShort := errors.New("Snapshot.UnmarshalBinary: data is too short")
+
+	if roundNumber, data, ok = readUint16(data); !ok {
+		return errShort
+	}
+	if len(data) < messages.SessionIDSize {
+		return errShort
+	}
+	copy(sessionID[:], data)
+	data = data[messages.SessionIDSize:]
+	if roundData, data, ok = readBytes(data); !ok {
+		return errShort
+	}
+	if count, data, ok = readUint32(data); !ok {
+		return errShort
+	}
+	// Each message is prefixed by its length
+	if uint64(count)*4 > uint64(len(data)) {
+		return errShort
+	}
+	msgs := make([]*messages.Message, 0, count)
+	for i := uint32(0); i < count; i++ {
+		var msgData []byte
+		if msgData, data, ok = readBytes(data); !ok {
+			return errShort
+		}
+		var msg messages.Message
+		if err := msg.UnmarshalBinary(msgData); err != nil {
+			return fmt.Errorf("Snapshot.UnmarshalBinary: %w", err)
+		}
+		msgs = append(msgs, &msg)
+	}
+	var processed map[party.ID][sha256.Size]byte
+	if len(data) != 0 {
+		if count, data, ok = readUint32(data); !ok {
+			return errShort
+		}
+		if uint64(count)*(party.IDByteSize+sha256.Size) != uint64(len(data)) {
+			return errors.New("Snapshot.UnmarshalBinary: invalid digests of the processed messages")
+		}
+		processed = make(map[party.ID][sha256.Size]byte, count)
+		for i := uint32(0); i < count; i++ {
+			id, err := party.FromBytes(data)
+			if err != nil {
+				return fmt.Errorf("Snapshot.UnmarshalBinary: %w", err)
+			}
+			var digest [sha256.Size]byte
+			copy(digest[:], data[party.IDByteSize:])
+			processed[id] = digest
+			data = data[party.IDByteSize+sha256.Size:]
+		}
+	}
+
+	snap.RoundNumber = int(roundNumber)
+	snap.SessionID = sessionID
+	snap.Round = append([]byte{}, roundData...)
+	snap.Messages = msgs
+	snap.Processed = processed
+	return nil
+}
+
+func appendUint16(data []byte, x uint16) []byte {
+	return append(data, byte(x>>8), byte(x))
+}
+
+func appendUint32(data []byte, x uint32) []byte {
+	return append(data, byte(x>>24), byte(x>>16), byte(x>>8), byte(x))
+}
+
+func readUint16(data []byte) (uint16, []byte, bool) {
+	if len(data) < 2 {
+		return 0, nil, false
+	}
+	return binary.BigEndian.Uint16(data), data[2:], true
+}
+
+func readUint32(data []byte) (uint32, []byte, bool) {
+	if len(data) < 4 {
+		return 0, nil, false
+	}
+	return binary.BigEndian.Uint32(data), data[4:], true
+}
+
+// readBytes reads a slice prefixed by its length encoded as a uint32.
+func readBytes(data []byte) ([]byte, []byte, bool) {
+	l, data, ok := readUint32(data)
+	if !ok || uint64(l) > uint64(len(data)) {
+		return nil, nil, false
+	}
+	return data[:l], data[l:], true
+}
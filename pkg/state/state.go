@@ -1,6 +1,8 @@
 package state
 
 import (
+	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"sync"
@@ -15,11 +17,60 @@ import (
 // It handles the initial message reception, by storing them internally and feeding them to
 // the the current round when all messages have been received
 type State struct {
+	// protocolTypes contains the message types accepted by all rounds of the protocol,
+	// and acceptedTypes those of the current and later rounds.
+	protocolTypes    []messages.MessageType
 	acceptedTypes    []messages.MessageType
 	receivedMessages map[party.ID]*messages.Message
 	queue            []*messages.Message
 
-	timer
+	// processed contains the digests of the messages of the current round already given to an IncrementalRound,
+	// and pending the errors it returned, which are reported by ProcessAll.
+	processed map[party.ID][sha256.Size]byte
+	pending   []*Error
+
+	// sent contains the encoded messages generated by the last two rounds, indexed by round number.
+	sent map[int][][]byte
+
+	timeout time.Duration
+	timer   *time.Timer
+
+	// partial is set when the current round timed out, and is a PartialRound which accepted the missing messages
+	partial bool
+
+	// codec and limits are set at creation and never modified
+	codec  messages.Codec
+	limits messages.Limits
+
+	// store is set at creation, and records the accepted messages if not nil
+	store MessageStore
+
+	// transcript is set at creation, and records the sent and accepted messages if not nil
+	transcript *Transcript
+
+	// extensions is set at creation, and contains the handlers of the application defined message types
+	extensions map[messages.MessageType]ExtensionHandler
+
+	observer   Observer
+	metrics    Metrics
+	events     []func()
+	start      time.Time
+	roundStart time.Time
+
+	// outgoing and kick are set by Outgoing, and pendingOutgoing is the number of messages not yet delivered
+	outgoing        chan *messages.Message
+	kick            chan struct{}
+	pendingOutgoing int
+
+	// progress is the channel returned by Notify for the current round
+	progress chan struct{}
+
+	// lastTransition is the time at which roundState last changed
+	lastTransition time.Time
+
+	// ttl is set at creation, and lastActivity is the time of the last accepted message or round transition
+	ttl          time.Duration
+	lastActivity time.Time
 
 	roundNumber int
 
@@ -29,39 +80,75 @@ type State struct {
 	done     bool
 	err      *Error
 
+	// mtx protects all fields above, and is only held for short periods of time.
 	mtx sync.Mutex
+
+	// processMtx is held while a round is being processed by ProcessAll, and processing is then true.
+	// The round must not be used by other methods while processing is true.
+	processMtx sync.Mutex
+	processing bool
 }
 
-func NewBaseState(round Round, timeout time.Duration) (*State, error) {
+// NewBaseState returns a State which executes the protocol starting at round.
+//
+// If timeout is positive, then the protocol aborts with an error wrapping ErrRoundTimeout
+// whenever a round does not receive all its messages within this duration,
+// unless the round is a PartialRound which accepts the missing messages.
+// The timer is reset every time the protocol moves on to the next round.
+func NewBaseState(round Round, timeout time.Duration, opts ...Option) (*State, error) {
+	s := newState(round, timeout, opts)
+
+	s.mtx.Lock()
+	s.startRound()
+	s.mtx.Unlock()
+	s.notify()
+
+	return s, nil
+}
+
+// newState returns a State for round, without starting the round.
+func newState(round Round, timeout time.Duration, opts []Option) *State {
 	N := round.PartyIDs().N()
+	protocolTypes := append([]messages.MessageType{}, round.AcceptedMessageTypes()...)
 	s := &State{
-		acceptedTypes:    append([]messages.MessageType{}, round.AcceptedMessageTypes()...),
+		protocolTypes:    protocolTypes,
+		acceptedTypes:    protocolTypes,
 		receivedMessages: make(map[party.ID]*messages.Message, N),
 		queue:            make([]*messages.Message, 0, N),
+		processed:        make(map[party.ID][sha256.Size]byte),
+		sent:             make(map[int][][]byte, 2),
 		round:            round,
 		doneChan:         make(chan struct{}),
+		timeout:          timeout,
+		codec:            messages.BinaryCodec,
+		limits:           limitsOf(round),
+		start:            time.Now(),
 	}
-
-	s.timer = newTimer(timeout, func() {
-		s.mtx.Lock()
-		s.reportError(NewError(0, errors.New("message timeout")))
-		s.mtx.Unlock()
-	})
-
-	for _, id := range round.PartyIDs() {
-		if id != round.SelfID() {
-			s.receivedMessages[id] = nil
-		}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
+}
 
-	return s, nil
+// startRound starts the timer for the current round, and notifies the observer.
+// It should be called with the lock held.
+func (s *State) startRound() {
+	s.partial = false
+	s.startTimer()
+	s.roundStart = time.Now()
+	s.lastTransition = s.roundStart
+	s.lastActivity = s.roundStart
+	roundNumber := s.roundNumber
+	s.emit(func(o Observer) { o.OnRoundStart(roundNumber) })
 }
 
-func (s *State) wrapError(err error, culprit party.ID) error {
-	if culprit == 0 {
-		return fmt.Errorf("party %d, round %d: %w", s.round.SelfID(), s.roundNumber, err)
-	}
-	return fmt.Errorf("party %d, round %d, culprit %d: %w", s.round.SelfID(), culprit, s.roundNumber, err)
+// wrapError returns err in a MessageError for a message sent by from, with information about the current round.
+// The rejection of the message is recorded in the Metrics.
+// It should be called with the lock held.
+func (s *State) wrapError(err error, from party.ID) error {
+	roundNumber := s.roundNumber
+	s.record(func(m Metrics) { m.MessageRejected(roundNumber, from, err) })
+	return fmt.Errorf("party %d, round %d: %w", s.round.SelfID(), s.roundNumber, &MessageError{From: from, err: err})
 }
 
 // HandleMessage should be called on an unmarshalled messages.Message appropriate for the protocol execution.
@@ -69,21 +156,64 @@ func (s *State) wrapError(err error, culprit party.ID) error {
 // - Is the protocol already done
 // - Is msg is valid for this round or a future one
 // - Is msg for us and not from us
-// - Is the sender a party in the protocol
+// - Is the sender a party in the protocol, which sends messages of this type
+// - Does the message have the same session ID
 // - Have we already received a message from the party for this round?
 //
 // If all these checks pass, then the message is either stored for the current round,
 // or put in a queue for later rounds.
 //
+// Messages sent by ourselves are ignored, and so are retransmissions: messages identical to one which is
+// waiting to be processed, and messages for rounds which were already processed.
+// This makes it safe for other parties to resend their messages (see ResendMessages) until they are sure
+// they were received.
+//
+// A Packed message is handled as the message it contains for us. It is rejected with ErrUnknownRecipient
+// if one of its recipients is not a party or is the sender, and with ErrWrongRecipient if we are not a recipient.
+//
+// Otherwise, if a check fails, the returned error is a *MessageError wrapping one of ErrEquivocation,
+// ErrUnexpectedSender, ErrWrongMessageType, ErrWrongRecipient, ErrUnknownRecipient or ErrSessionMismatch,
+// or a *messages.FieldError if the content of the message does not respect the MessageLimits of the protocol.
+// In these cases, the protocol is not aborted.
+// A different message of the same type from the same party results in ErrEquivocation.
+//
 // Note: the properties of the messages are checked in ProcessAll.
 // Therefore, the check here should be a quite fast.
+//
+// If a MessageStore was given with WithMessageStore, the message is recorded in it before being stored,
+// and an error is returned if recording fails.
 func (s *State) HandleMessage(msg *messages.Message) error {
+	return s.handleMessage(msg, true)
+}
+
+// handleMessage implements HandleMessage, and only records the message in the MessageStore if record is true.
+func (s *State) handleMessage(msg *messages.Message, record bool) error {
 	senderID := msg.From
 
+	defer s.notify()
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 
+	// received is recorded in the Transcript instead of our part of a packed message
+	received := msg
+
+	// Only our part of a packed message is handled, as if it had been sent on its own
+	if msg.Type == messages.MessageTypePacked {
+		if senderID == s.round.SelfID() {
+			return nil
+		}
+		unpacked, err := s.unpack(msg)
+		if err != nil {
+			return s.wrapError(err, senderID)
+		}
+		msg = unpacked
+	}
+
 	if s.done {
+		// Retransmissions are still ignored after a successful execution
+		if s.err == nil && s.isPastType(msg.Type) {
+			return nil
+		}
 		return s.wrapError(errors.New("protocol already finished"), senderID)
 	}
 
@@ -96,71 +226,198 @@ func (s *State) HandleMessage(msg *messages.Message) error {
 		return nil
 	}
 
-	// Ignore message not addressed to us
+	// Is the message addressed to us?
 	if !msg.IsBroadcast() && msg.To != s.round.SelfID() {
-		return nil
+		return s.wrapError(ErrWrongRecipient, senderID)
 	}
 	// Is the sender in our list of participants?
 	if !s.round.PartyIDs().Contains(senderID) {
-		return s.wrapError(errors.New("sender is not a party"), senderID)
+		return s.wrapError(ErrUnexpectedSender, senderID)
 	}
 
-	// Check if we have already received a message from this party.
-	// exists should never be false, but you never know
-	if _, exists := s.receivedMessages[senderID]; exists {
-		return s.wrapError(errors.New("message from this party was already received"), senderID)
+	// Does the message belong to this execution?
+	if msg.SessionID != s.round.SessionID() {
+		return s.wrapError(ErrSessionMismatch, senderID)
+	}
+
+	// Application defined messages do not belong to a round
+	if s.handleExtension(msg) {
+		return nil
+	}
+
+	// Does the content of the message have the expected size?
+	if err := s.limits.Check(msg); err != nil {
+		return s.wrapError(err, senderID)
 	}
 
 	if !s.isAcceptedType(msg.Type) {
-		return s.wrapError(errors.New("message type is not accepted for this type of round"), senderID)
+		// The message was sent for a round which we have already processed
+		if s.isPastType(msg.Type) {
+			return nil
+		}
+		return s.wrapError(ErrWrongMessageType, senderID)
+	}
+	// Does the sender send messages of this type?
+	if !s.senders(msg.Type).Contains(senderID) {
+		return s.wrapError(ErrUnexpectedSender, senderID)
 	}
 
-	s.ackMessage()
+	// Check if we have already received a message of this type from this party.
+	if seen, err := s.checkProcessed(msg); seen {
+		if err != nil {
+			return s.wrapError(err, senderID)
+		}
+		return nil
+	}
+	if previous := s.previousMessage(senderID, msg.Type); previous != nil {
+		if previous.Equal(msg) {
+			return nil
+		}
+		return s.wrapError(ErrEquivocation, senderID)
+	}
+
+	if record && s.store != nil {
+		if err := s.storeMessage(msg); err != nil {
+			return err
+		}
+	}
+	if err := s.recordReceived(received); err != nil {
+		return err
+	}
 
+	s.lastActivity = time.Now()
 	if msg.Type == s.acceptedTypes[0] {
-		s.receivedMessages[senderID] = msg
+		if !s.processIncrementally(msg) {
+			s.receivedMessages[senderID] = msg
+		}
+		if s.receivedAll() {
+			s.lastTransition = time.Now()
+		}
+		s.signalProgress()
 	} else {
 		s.queue = append(s.queue, msg)
 	}
+	roundNumber := s.roundNumber
+	s.emit(func(o Observer) { o.OnMessageStored(senderID, msg.Type) })
+	s.record(func(m Metrics) { m.MessageStored(roundNumber, senderID, msg.Type) })
+	s.signalReceived()
 
 	return nil
 }
 
+// unpack returns the message contained in the Packed message msg for us.
+// All recipients must be parties other than the sender.
+func (s *State) unpack(msg *messages.Message) (*messages.Message, error) {
+	if msg.Packed == nil {
+		return nil, ErrWrongMessageType
+	}
+	partyIDs := s.round.PartyIDs()
+	for _, to := range msg.Packed.To {
+		if to == msg.From || !partyIDs.Contains(to) {
+			return nil, ErrUnknownRecipient
+		}
+	}
+	unpacked, err := msg.Unpack(s.round.SelfID())
+	if errors.Is(err, messages.ErrNotRecipient) {
+		return nil, ErrWrongRecipient
+	}
+	return unpacked, err
+}
+
 // ProcessAll checks whether all messages for this round have been received.
 // If so then all messages are fed to Round.ProcessMessage.
 // If no error was detected, then the round is processed and new messages are generated.
 // These messages are returned to the caller and should be processed.
 // If all went correctly, we take the messages for the next round out of the queue,
 // and move on to the next round.
+//
+// It is safe to call ProcessAll concurrently, and a round is processed by exactly one of the calls.
+// The round is processed without holding the lock used by HandleMessage,
+// so that messages for later rounds can be received in the meantime.
 func (s *State) ProcessAll() []*messages.Message {
+	defer s.notify()
+
+	// Only one goroutine may process a round at a time
+	s.processMtx.Lock()
+	defer s.processMtx.Unlock()
+
+	s.mtx.Lock()
+	// Only continue if we received messages from all
+	if s.done || !s.receivedAll() {
+		s.mtx.Unlock()
+		return nil
+	}
+	// The messages are replaced by their digest, so that retransmissions can be detected while we process them,
+	// and the round may erase the secrets they contain.
+	// We use the order of the party IDs so that the reported error is deterministic.
+	round := s.round
+	msgs := make([]*messages.Message, 0, len(s.receivedMessages))
+	for _, id := range round.PartyIDs() {
+		msg, ok := s.receivedMessages[id]
+		if !ok {
+			continue
+		}
+		if digest, ok := digestOf(msg); ok {
+			s.processed[id] = digest
+			delete(s.receivedMessages, id)
+		}
+		msgs = append(msgs, msg)
+	}
+	pending := s.takePending()
+	s.processing = true
+	roundNumber, processingStart := s.roundNumber, time.Now()
+	s.lastTransition = processingStart
+	waited := processingStart.Sub(s.roundStart)
+	s.record(func(m Metrics) { m.RoundProcessingStarted(roundNumber, waited) })
+	s.mtx.Unlock()
+	s.notify()
+
+	newMessages, err := processRound(round, msgs, pending)
+	var sent [][]byte
+	if err == nil {
+		sent, err = encodeMessages(round, newMessages)
+	}
+
+	d := time.Since(processingStart)
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
+	s.processing = false
+	// err is only converted to the error interface when it is not nil
+	if err != nil {
+		s.record(func(m Metrics) { m.RoundProcessingFinished(roundNumber, d, err) })
+	} else {
+		s.record(func(m Metrics) { m.RoundProcessingFinished(roundNumber, d, nil) })
+	}
 
-	if s.done {
-		return nil
+	for _, msg := range msgs {
+		zeroize(msg)
 	}
 
-	// Only continue if we received messages from all
-	if len(s.receivedMessages) != int(s.round.PartyIDs().N()-1) {
+	// The protocol was aborted while we were processing the round, and the reset of the round was postponed.
+	if s.done {
+		s.round.Reset()
+		s.releaseMessages()
 		return nil
 	}
-
-	for _, msg := range s.receivedMessages {
-		if err := s.round.ProcessMessage(msg); err != nil {
-			s.reportError(err)
-			return nil
-		}
+	if err != nil {
+		s.reportError(err)
+		return nil
 	}
 
 	// remove all messages that have been processed
 	for id := range s.receivedMessages {
 		delete(s.receivedMessages, id)
 	}
+	for id := range s.processed {
+		delete(s.processed, id)
+	}
 
-	newMessages, err := s.round.GenerateMessages()
-	if err != nil {
-		s.reportError(err)
-		return nil
+	s.sent[roundNumber] = sent
+	s.recordSent(newMessages, sent)
+	delete(s.sent, roundNumber-2)
+	if s.observer != nil {
+		d := time.Since(s.roundStart)
+		s.emit(func(o Observer) { o.OnRoundFinish(roundNumber, d) })
 	}
 
 	// remove the messages for the next round from the queue
@@ -185,12 +442,63 @@ func (s *State) ProcessAll() []*messages.Message {
 	} else {
 		s.roundNumber++
 		s.round = nextRound
+		s.processReceived()
+		s.closeProgress()
+		s.startRound()
 	}
 
 	return newMessages
 }
 
+// processRound feeds msgs to round, and generates the messages for the next round.
+// All messages are processed, so that every misbehaving party is reported,
+// including those whose messages were processed incrementally with the errors pending.
+func processRound(round Round, msgs []*messages.Message, pending []*Error) ([]*messages.Message, *Error) {
+	errs := pending
+	for _, msg := range msgs {
+		if err := round.ProcessMessage(msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, mergeErrors(errs)
+	}
+	return round.GenerateMessages()
+}
+
+// encodeMessages sets the session ID of the messages generated by round, and returns their encoding.
+func encodeMessages(round Round, msgs []*messages.Message) ([][]byte, *Error) {
+	encoded := make([][]byte, 0, len(msgs))
+	for _, msg := range msgs {
+		msg.SessionID = round.SessionID()
+		data, err := msg.MarshalBinary()
+		if err != nil {
+			return nil, NewError(0, fmt.Errorf("failed to encode message: %w", err))
+		}
+		encoded = append(encoded, data)
+	}
+	return encoded, nil
+}
+
+// previousMessage returns the message of type msgType from party from which is waiting to be processed,
+// or nil if there is none.
+// It should be called with the lock held.
+func (s *State) previousMessage(from party.ID, msgType messages.MessageType) *messages.Message {
+	if msg, ok := s.receivedMessages[from]; ok && msg.Type == msgType {
+		return msg
+	}
+	for _, msg := range s.queue {
+		if msg.From == from && msg.Type == msgType {
+			return msg
+		}
+	}
+	return nil
+}
+
 func (s *State) isAcceptedType(msgType messages.MessageType) bool {
+	if msgType == messages.MessageTypeNone {
+		return false
+	}
 	for _, otherType := range s.acceptedTypes {
 		if otherType == msgType {
 			return true
@@ -199,17 +507,138 @@ func (s *State) isAcceptedType(msgType messages.MessageType) bool {
 	return false
 }
 
+// isPastType returns true if msgType was accepted by a round which has already been processed.
+// It should be called with the lock held.
+func (s *State) isPastType(msgType messages.MessageType) bool {
+	if msgType == messages.MessageTypeNone {
+		return false
+	}
+	for _, otherType := range s.protocolTypes[:len(s.protocolTypes)-len(s.acceptedTypes)] {
+		if otherType == msgType {
+			return true
+		}
+	}
+	return false
+}
+
+// SelfID returns the ID of the party executing the protocol.
+func (s *State) SelfID() party.ID {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.round.SelfID()
+}
+
+// PartyIDs returns the sorted IDs of all parties of the protocol, including ourselves.
+func (s *State) PartyIDs() party.IDSlice {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.round.PartyIDs()
+}
+
+// SessionID returns the session ID of this execution, set with WithSessionID.
+func (s *State) SessionID() messages.SessionID {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.round.SessionID()
+}
+
+//
+// Progress
+//
+
+// expectsMessages returns true if the current round requires messages from the other parties.
+// It should be called with the lock held.
+func (s *State) expectsMessages() bool {
+	return !s.done && len(s.acceptedTypes) > 0 && s.acceptedTypes[0] != messages.MessageTypeNone
+}
+
+// receivedAll returns true if all messages for the current round have been received.
+// It should be called with the lock held.
+func (s *State) receivedAll() bool {
+	if !s.expectsMessages() || s.partial {
+		return true
+	}
+	return s.received() == len(s.senders(s.acceptedTypes[0]))
+}
+
+// missing returns the sorted IDs of the parties from which we have not yet received a message
+// for the current round.
+// It should be called with the lock held.
+func (s *State) missing() party.IDSlice {
+	if !s.expectsMessages() {
+		return nil
+	}
+	senders := s.senders(s.acceptedTypes[0])
+	missing := make(party.IDSlice, 0, len(senders))
+	for _, id := range senders {
+		if !s.hasReceived(id) {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+// MissingFrom returns the sorted IDs of the parties from which no message has been received yet
+// for the current round. Once all messages have been received, the returned slice is empty.
+//
+// The boolean is false if the current round does not expect any messages from other parties
+// (for example the first round, or if the protocol has finished), in which case the slice is nil.
+func (s *State) MissingFrom() (party.IDSlice, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if !s.expectsMessages() {
+		return nil, false
+	}
+	return s.missing(), true
+}
+
+// ReceivedFrom returns the sorted IDs of the parties from which a message has been received
+// for the current round.
+//
+// The boolean is false if the current round does not expect any messages from other parties
+// (for example the first round, or if the protocol has finished), in which case the slice is nil.
+func (s *State) ReceivedFrom() (party.IDSlice, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if !s.expectsMessages() {
+		return nil, false
+	}
+	received := make(party.IDSlice, 0, s.received())
+	for _, id := range s.round.PartyIDs() {
+		if s.hasReceived(id) {
+			received = append(received, id)
+		}
+	}
+	return received, true
+}
+
 //
 // Output
 //
+
+// finish marks the protocol as done, and releases the resources held by the round.
+// It should be called with the lock held.
+// Since s.done is checked and set while holding the lock, doneChan is closed exactly once,
+// regardless of whether the protocol finished successfully or was aborted.
 func (s *State) finish() {
 	if s.done {
 		return
 	}
 	s.done = true
-	s.round.Reset()
+	s.lastTransition = time.Now()
+	// If the round is being processed, then ProcessAll will reset it and release the messages once it is done.
+	if !s.processing {
+		s.round.Reset()
+		s.releaseMessages()
+	}
 	s.stopTimer()
+	s.closeProgress()
 	close(s.doneChan)
+
+	if s.err == nil {
+		d := time.Since(s.start)
+		s.record(func(m Metrics) { m.ProtocolFinished(d) })
+	}
 }
 
 func (s *State) reportError(err *Error) {
@@ -221,22 +650,37 @@ func (s *State) reportError(err *Error) {
 	// We already got an error
 	// TODO chain the errors
 	if s.err == nil {
-		err.RoundNumber = s.roundNumber
+		err.roundNumber = s.roundNumber
 		s.err = err
 	}
+
+	culprits := err.Culprits()
+	s.emit(func(o Observer) { o.OnAbort(culprits, err) })
+	s.record(func(m Metrics) { m.ProtocolAborted(culprits, err) })
 }
 
-// Done should be called like context.Done:
+// Done returns a channel that is closed when the protocol has finished, either successfully or
+// because of an abort. It should be called like context.Done:
 //
 // select {
 //   case <-s.Done():
+//     err := s.Err()
 //   // other cases
+// }
 //
+// This makes it possible to wait on many protocol executions at the same time.
 func (s *State) Done() <-chan struct{} {
 	return s.doneChan
 }
 
+// Err mirrors context.Context.Err.
+// If Done is not yet closed, Err returns nil.
+// After Done is closed, Err returns nil if the protocol finished successfully,
+// or the *Error which caused the abort otherwise.
+// It is safe to call Err concurrently, and any number of times after Done is closed.
 func (s *State) Err() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
 	if s.err != nil {
 		return s.err
 	}
@@ -247,46 +691,116 @@ func (s *State) Err() error {
 // This happens either when the protocol has finished correctly,
 // or if an error has been detected.
 func (s *State) WaitForError() error {
-	if !s.done {
-		<-s.doneChan
-	}
+	<-s.doneChan
 	return s.Err()
 }
 
+// WaitForErrorContext is like WaitForError, but also returns when ctx is done.
+// In that case, the protocol is aborted so that further calls to HandleMessage fail,
+// and ctx.Err() is returned.
+// If the protocol finished before ctx was done, then the result is the same as WaitForError.
+//
+// It is safe to call this method concurrently from multiple goroutines.
+func (s *State) WaitForErrorContext(ctx context.Context) error {
+	select {
+	case <-s.doneChan:
+		return s.Err()
+	case <-ctx.Done():
+	}
+
+	defer s.notify()
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	// The protocol may have finished while we were acquiring the lock
+	if s.done {
+		if s.err != nil {
+			return s.err
+		}
+		return nil
+	}
+	s.reportError(NewErrorWithKind(0, KindCanceled, ctx.Err()))
+	return ctx.Err()
+}
+
+// Cancel aborts the protocol on behalf of the application, for example when the message to be signed was withdrawn.
+// The error returned by Err and WaitForError then wraps ErrCanceled and reason, which may be nil.
+// Further calls to HandleMessage fail, and no more messages are generated.
+//
+// The round is reset, so that all secret values are zeroed, including the nonces of a signing session.
+// If the round is currently being processed, this happens as soon as ProcessAll is done with it.
+//
+// Calling Cancel on a protocol that has already finished or aborted has no effect.
+func (s *State) Cancel(reason error) {
+	defer s.notify()
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.reportError(NewErrorWithKind(0, KindCanceled, cancelError{reason: reason}))
+}
+
 // IsFinished returns true if the protocol has aborted or successfully finished.
 func (s *State) IsFinished() bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
 	return s.done
 }
 
+// IsAborted returns true if the protocol has aborted.
+// In this case, the cause is returned by Err.
+func (s *State) IsAborted() bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.done && s.err != nil
+}
+
 //
 // Timeout
 //
 
-type timer struct {
-	t *time.Timer
-	d time.Duration
-}
-
-func newTimer(d time.Duration, f func()) timer {
-	var t *time.Timer
-	if d > 0 {
-		t = time.AfterFunc(d, f)
-	}
-	return timer{
-		t: t,
-		d: d,
+// startTimer (re)starts the timeout for the current round.
+// It should be called with the lock held.
+func (s *State) startTimer() {
+	s.stopTimer()
+	if s.timeout <= 0 {
+		return
 	}
+	roundNumber := s.roundNumber
+	s.timer = time.AfterFunc(s.timeout, func() {
+		s.onTimeout(roundNumber)
+	})
 }
 
-func (t *timer) ackMessage() {
-	if t.t != nil {
-		t.t.Stop()
-		t.t.Reset(t.d)
+// stopTimer stops the timeout for the current round.
+// It should be called with the lock held.
+func (s *State) stopTimer() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
 	}
 }
 
-func (t *timer) stopTimer() {
-	if t.t != nil {
-		t.t.Stop()
+// onTimeout aborts the protocol if round roundNumber is still waiting for messages.
+// If all messages were received, then we are either processing the round, or waiting for
+// ProcessAll to be called. In both cases, the delay is not attributable to the other parties.
+// If the round is a PartialRound which accepts the missing messages, then it is processed by the next call to
+// ProcessAll with the messages received until then.
+func (s *State) onTimeout(roundNumber int) {
+	defer s.notify()
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.done || s.roundNumber != roundNumber {
+		return
+	}
+	missing := s.missing()
+	if len(missing) == 0 {
+		return
+	}
+	if s.acceptMissing(missing) {
+		s.partial = true
+		s.signalProgress()
+		return
 	}
+	s.reportError(NewErrorWithKind(0, KindTimeout, &TimeoutError{Missing: missing}))
 }
@@ -0,0 +1,88 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+)
+
+// ErrNoMessageStore is returned by ReplayMessages when the State was created without a MessageStore.
+var ErrNoMessageStore = errors.New("no message store configured")
+
+// A MessageStore durably records the messages accepted by a State, before they are acted upon.
+// This allows auditing the messages of an execution, and recovering the messages which were received
+// after the last Snapshot when the protocol is restored after a crash.
+//
+// The package github.com/taurusgroup/frost-ed25519/pkg/state/filestore provides an implementation
+// backed by an append-only file.
+type MessageStore interface {
+	// Append records raw, the encoding with State.Codec of a message sent by from, which was accepted during round.
+	// It must only return once the record is durable, and an error if it could not be recorded.
+	Append(round int, from party.ID, raw []byte) error
+
+	// Load returns all records, in the order in which they were appended.
+	Load() ([]Record, error)
+}
+
+// Record is a message recorded in a MessageStore.
+type Record struct {
+	// Round is the number of the round during which the message was accepted.
+	Round int
+	// From is the ID of the sender of the message.
+	From party.ID
+	// Raw is the encoding of the message with the Codec of the State.
+	Raw []byte
+}
+
+// WithMessageStore returns an Option which makes HandleMessage record every accepted message in store,
+// before it is stored for processing.
+// Messages are not recorded by default.
+//
+// Since the message is recorded while the State is locked, a slow store delays the reception of other messages.
+func WithMessageStore(store MessageStore) Option {
+	return func(s *State) {
+		s.store = store
+	}
+}
+
+// storeMessage records msg in the MessageStore.
+// It should be called with the lock held.
+func (s *State) storeMessage(msg *messages.Message) error {
+	raw, err := s.codec.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("state: failed to encode message from party %d: %w", msg.From, err)
+	}
+	if err = s.store.Append(s.roundNumber, msg.From, raw); err != nil {
+		return fmt.Errorf("state: failed to record message from party %d: %w", msg.From, err)
+	}
+	return nil
+}
+
+// ReplayMessages handles all messages recorded in the MessageStore again, without recording them a second time.
+// It is meant to be called on a State restored with RestoreState after a crash,
+// so that the messages received after the snapshot was taken do not need to be sent again.
+// Messages for rounds which were already processed, or which are already included in the snapshot, are ignored.
+func (s *State) ReplayMessages() error {
+	if s.store == nil {
+		return ErrNoMessageStore
+	}
+	records, err := s.store.Load()
+	if err != nil {
+		return fmt.Errorf("state.ReplayMessages: %w", err)
+	}
+	for i, record := range records {
+		var msg messages.Message
+		if err = s.codec.Unmarshal(record.Raw, &msg); err != nil {
+			return fmt.Errorf("state.ReplayMessages: record %d: %w", i, err)
+		}
+		if msg.From != record.From {
+			return fmt.Errorf("state.ReplayMessages: record %d: sender %d does not match the message", i, record.From)
+		}
+		if err = s.handleMessage(&msg, false); err != nil {
+			return fmt.Errorf("state.ReplayMessages: record %d: %w", i, err)
+		}
+	}
+	return nil
+}
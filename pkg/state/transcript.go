@@ -0,0 +1,281 @@
+package state
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+)
+
+// Direction indicates whether a TranscriptEntry was sent or received by us.
+type Direction uint8
+
+const (
+	DirectionSent Direction = iota + 1
+	DirectionReceived
+)
+
+// String implements fmt.Stringer
+func (d Direction) String() string {
+	switch d {
+	case DirectionSent:
+		return "sent"
+	case DirectionReceived:
+		return "received"
+	}
+	return fmt.Sprintf("Direction(%d)", uint8(d))
+}
+
+// TranscriptEntry is a message recorded in a Transcript.
+type TranscriptEntry struct {
+	Direction Direction
+	// Round is the number of the round which processes the message.
+	// It is the position of the message type in the AcceptedMessageTypes of the first round,
+	// so that a message has the same round number for its sender and its recipients.
+	Round int
+	From  party.ID
+	// To is 0 for broadcast messages.
+	To party.ID
+	// Data is the binary encoding of the message.
+	Data []byte
+}
+
+// A Transcript records the messages sent and accepted by a State, for auditing purposes.
+//
+// Each party only observes the unicast messages it sends or receives, so Sum only covers the broadcast messages,
+// which all honest parties of a successful execution have seen identically.
+// All entries are included in Export.
+//
+// Messages of Extension types do not belong to a round, and are not recorded.
+// A Transcript is not included in a Snapshot, so the messages recorded before a restore are lost
+// unless the same Transcript is given to the restored State.
+//
+// It is safe to use a Transcript concurrently.
+type Transcript struct {
+	mtx     sync.Mutex
+	entries []TranscriptEntry
+}
+
+// transcriptVersion is the first byte of the encoding returned by Export.
+// In version 1, party IDs were encoded in transcriptShortIDSize bytes.
+const (
+	transcriptVersion         = 2
+	transcriptVersionShortIDs = 1
+	transcriptShortIDSize     = 2
+)
+
+// transcriptContext separates the hash computed by Sum from other uses of SHA-256.
+const transcriptContext = "FROST-Ed25519 transcript"
+
+// sizeTranscriptEntry is the size of the fields of an exported entry which precede the data.
+const sizeTranscriptEntry = 1 + 2 + 2*party.IDByteSize
+
+// sizeTranscriptEntryShortIDs is the size of the fields of an entry exported with version 1.
+const sizeTranscriptEntryShortIDs = 1 + 2 + 2*transcriptShortIDSize
+
+var errTranscriptShort = errors.New("state: transcript data is too short")
+
+// NewTranscript returns an empty Transcript.
+func NewTranscript() *Transcript {
+	return &Transcript{}
+}
+
+// WithTranscript returns an Option which records every message sent by the State, and every message accepted by
+// HandleMessage, in transcript.
+// Packed messages are recorded as received, before our part is extracted.
+func WithTranscript(transcript *Transcript) Option {
+	return func(s *State) {
+		s.transcript = transcript
+	}
+}
+
+// Add appends entry to the transcript.
+// Data is copied, and may be reused by the caller.
+func (t *Transcript) Add(entry TranscriptEntry) {
+	entry.Data = append([]byte(nil), entry.Data...)
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.entries = append(t.entries, entry)
+}
+
+// Entries returns a copy of the entries, in the order in which they were added.
+func (t *Transcript) Entries() []TranscriptEntry {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return append([]TranscriptEntry(nil), t.entries...)
+}
+
+// Sum returns the hash
+//
+//	SHA-256("FROST-Ed25519 transcript" ∥ (round ∥ from ∥ len(data) ∥ data)...)
+//
+// of the broadcast messages in the transcript, sorted by round, sender and data,
+// where round is 2 bytes long, and from and len(data) 4 bytes long.
+// Since the order does not depend on the direction or on the order of arrival,
+// all honest parties of a successful execution compute the same Sum.
+func (t *Transcript) Sum() [32]byte {
+	entries := t.Entries()
+	broadcast := entries[:0]
+	for _, entry := range entries {
+		if entry.To == 0 {
+			broadcast = append(broadcast, entry)
+		}
+	}
+	sort.Slice(broadcast, func(i, j int) bool {
+		a, b := broadcast[i], broadcast[j]
+		if a.Round != b.Round {
+			return a.Round < b.Round
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return bytes.Compare(a.Data, b.Data) < 0
+	})
+
+	h := sha256.New()
+	_, _ = h.Write([]byte(transcriptContext))
+	var buf [2 + party.IDByteSize + 4]byte
+	for _, entry := range broadcast {
+		binary.BigEndian.PutUint16(buf[0:], uint16(entry.Round))
+		binary.BigEndian.PutUint32(buf[2:], uint32(entry.From))
+		binary.BigEndian.PutUint32(buf[2+party.IDByteSize:], uint32(len(entry.Data)))
+		_, _ = h.Write(buf[:])
+		_, _ = h.Write(entry.Data)
+	}
+	var sum [32]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// Export returns the encoding of all entries, in the order in which they were added, so that it can be archived:
+//
+//	version ∥ (len ∥ direction ∥ round ∥ from ∥ to ∥ data)...
+//
+// where version is 1 byte long, len is the size of the rest of the entry in 4 bytes, direction is 1 byte long,
+// round 2 bytes long, and from and to 4 bytes long. It is decoded by ImportTranscript.
+func (t *Transcript) Export() []byte {
+	entries := t.Entries()
+	size := 1
+	for _, entry := range entries {
+		size += 4 + sizeTranscriptEntry + len(entry.Data)
+	}
+	data := make([]byte, 0, size)
+	data = append(data, transcriptVersion)
+	for _, entry := range entries {
+		var buf [4 + sizeTranscriptEntry]byte
+		binary.BigEndian.PutUint32(buf[0:], uint32(sizeTranscriptEntry+len(entry.Data)))
+		buf[4] = byte(entry.Direction)
+		binary.BigEndian.PutUint16(buf[5:], uint16(entry.Round))
+		binary.BigEndian.PutUint32(buf[7:], uint32(entry.From))
+		binary.BigEndian.PutUint32(buf[7+party.IDByteSize:], uint32(entry.To))
+		data = append(data, buf[:]...)
+		data = append(data, entry.Data...)
+	}
+	return data
+}
+
+// ImportTranscript decodes a Transcript from the encoding returned by Export.
+// Transcripts exported with version 1, in which party IDs are 2 bytes long, are also decoded.
+func ImportTranscript(data []byte) (*Transcript, error) {
+	if len(data) == 0 {
+		return nil, errTranscriptShort
+	}
+	idSize, headerSize := party.IDByteSize, sizeTranscriptEntry
+	switch data[0] {
+	case transcriptVersion:
+	case transcriptVersionShortIDs:
+		idSize, headerSize = transcriptShortIDSize, sizeTranscriptEntryShortIDs
+	default:
+		return nil, fmt.Errorf("state.ImportTranscript: unknown version %d", data[0])
+	}
+	readID := func(b []byte) party.ID {
+		if idSize == transcriptShortIDSize {
+			return party.ID(binary.BigEndian.Uint16(b))
+		}
+		return party.ID(binary.BigEndian.Uint32(b))
+	}
+	data = data[1:]
+
+	t := NewTranscript()
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, errTranscriptShort
+		}
+		length := binary.BigEndian.Uint32(data)
+		data = data[4:]
+		if length < uint32(headerSize) || uint64(length) > uint64(len(data)) {
+			return nil, fmt.Errorf("state.ImportTranscript: invalid entry length %d", length)
+		}
+		entry := TranscriptEntry{
+			Direction: Direction(data[0]),
+			Round:     int(binary.BigEndian.Uint16(data[1:])),
+			From:      readID(data[3:]),
+			To:        readID(data[3+idSize:]),
+			Data:      append([]byte(nil), data[headerSize:length]...),
+		}
+		if entry.Direction != DirectionSent && entry.Direction != DirectionReceived {
+			return nil, fmt.Errorf("state.ImportTranscript: invalid direction %d", entry.Direction)
+		}
+		t.entries = append(t.entries, entry)
+		data = data[length:]
+	}
+	return t, nil
+}
+
+// transcriptRound returns the number of the round which processes messages of type t,
+// or of the inner type of Packed messages.
+func (s *State) transcriptRound(msg *messages.Message) int {
+	t := msg.Type
+	if t == messages.MessageTypePacked && msg.Packed != nil {
+		t = msg.Packed.Type
+	}
+	for i, protocolType := range s.protocolTypes {
+		if protocolType == t {
+			return i
+		}
+	}
+	return 0
+}
+
+// recordReceived adds msg to the Transcript, if there is one.
+// It should be called with the lock held.
+func (s *State) recordReceived(msg *messages.Message) error {
+	if s.transcript == nil {
+		return nil
+	}
+	data, err := msg.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("state: failed to encode message from party %d: %w", msg.From, err)
+	}
+	s.transcript.Add(TranscriptEntry{
+		Direction: DirectionReceived,
+		Round:     s.transcriptRound(msg),
+		From:      msg.From,
+		To:        msg.To,
+		Data:      data,
+	})
+	return nil
+}
+
+// recordSent adds the messages generated by a round to the Transcript, if there is one.
+// encoded contains their binary encoding, as returned by encodeMessages.
+func (s *State) recordSent(msgs []*messages.Message, encoded [][]byte) {
+	if s.transcript == nil {
+		return
+	}
+	for i, msg := range msgs {
+		s.transcript.Add(TranscriptEntry{
+			Direction: DirectionSent,
+			Round:     s.transcriptRound(msg),
+			From:      msg.From,
+			To:        msg.To,
+			Data:      encoded[i],
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/keygen"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/sign"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+//go:generate go test -run TestGoldenTranscript -update-golden
+
+// updateGolden regenerates the golden transcript instead of checking it.
+// Any intentional change of the messages or of the derived keys requires running go generate in this directory.
+var updateGolden = flag.Bool("update-golden", false, "regenerate "+goldenPath)
+
+const goldenPath = "testdata/golden_transcript.json"
+
+// goldenMessage is a message of the golden transcript, sent in the given round of the protocol.
+// Data is the hex encoding of its binary encoding, Type is only given for readability, and To is omitted for broadcasts.
+type goldenMessage struct {
+	Round int      `json:"round"`
+	Type  string   `json:"type"`
+	From  party.ID `json:"from"`
+	To    party.ID `json:"to,omitempty"`
+	Data  string   `json:"data"`
+}
+
+// goldenFile contains a 2-of-3 keygen and a signature with the resulting key, in which every party reads its randomness
+// from a reader seeded with its ID.
+type goldenFile struct {
+	Keygen struct {
+		PartyIDs     party.IDSlice                   `json:"party_ids"`
+		Threshold    party.Size                      `json:"threshold"`
+		Messages     []goldenMessage                 `json:"messages"`
+		Public       *eddsa.Public                   `json:"public"`
+		SecretShares map[party.ID]*eddsa.SecretShare `json:"secret_shares"`
+	} `json:"keygen"`
+	Sign struct {
+		Signers   party.IDSlice   `json:"signers"`
+		Message   string          `json:"message"`
+		Messages  []goldenMessage `json:"messages"`
+		Signature string          `json:"signature"`
+	} `json:"sign"`
+}
+
+// goldenKeygenStates returns the states of the keygen of the golden transcript.
+func goldenKeygenStates(t *testing.T, partyIDs party.IDSlice, threshold party.Size) (map[party.ID]*state.State, map[party.ID]*keygen.Output) {
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*keygen.Output{}
+	for _, id := range partyIDs {
+		var err error
+		opts := []keygen.Option{keygen.WithRandom(seededReader(id, 0))}
+		if states[id], outputs[id], err = frost.NewKeygenStateWithOptions(id, partyIDs, threshold, 0, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return states, outputs
+}
+
+// goldenSignStates returns the states of the signature of the golden transcript.
+func goldenSignStates(t *testing.T, signers party.IDSlice, secrets map[party.ID]*eddsa.SecretShare, public *eddsa.Public, message []byte) (map[party.ID]*state.State, map[party.ID]*sign.Output) {
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*sign.Output{}
+	for _, id := range signers {
+		var err error
+		opts := seededSignOptions(t, id)
+		if states[id], outputs[id], err = frost.NewSignStateWithOptions(signers, secrets[id], public, message, 0, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return states, outputs
+}
+
+// recordRounds runs the protocol between states until they are finished, and returns all messages sent.
+func recordRounds(t *testing.T, partyIDs party.IDSlice, states map[party.ID]*state.State) []goldenMessage {
+	var recorded []goldenMessage
+	var in [][]byte
+	for round := 0; !states[partyIDs[0]].IsFinished(); round++ {
+		var out [][]byte
+		for _, id := range partyIDs {
+			msgsOut, err := helpers.PartyRoutine(in, states[id])
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, data := range msgsOut {
+				var msg messages.Message
+				if err = msg.UnmarshalBinary(data); err != nil {
+					t.Fatal(err)
+				}
+				recorded = append(recorded, goldenMessage{
+					Round: round,
+					Type:  msg.Type.String(),
+					From:  msg.From,
+					To:    msg.To,
+					Data:  hex.EncodeToString(data),
+				})
+			}
+			out = append(out, msgsOut...)
+		}
+		in = out
+	}
+	return recorded
+}
+
+// replayRounds gives each party the messages it received in the recorded transcript, and checks that it sends
+// the same messages byte for byte.
+func replayRounds(t *testing.T, partyIDs party.IDSlice, states map[party.ID]*state.State, recorded []goldenMessage) {
+	lastRound := recorded[len(recorded)-1].Round
+	for _, id := range partyIDs {
+		var in [][]byte
+		for round := 0; !states[id].IsFinished(); round++ {
+			if round > lastRound+1 {
+				t.Fatalf("party %d did not finish after %d rounds", id, round)
+			}
+			out, err := helpers.PartyRoutine(in, states[id])
+			if err != nil {
+				t.Fatalf("party %d, round %d: %v", id, round, err)
+			}
+
+			in = nil
+			var expected [][]byte
+			for _, msg := range recorded {
+				if msg.Round != round {
+					continue
+				}
+				data, err := hex.DecodeString(msg.Data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				switch {
+				case msg.From == id:
+					expected = append(expected, data)
+				case msg.To == 0 || msg.To == id:
+					in = append(in, data)
+				}
+			}
+			if len(out) != len(expected) {
+				t.Fatalf("party %d, round %d: %d messages were sent, want %d", id, round, len(out), len(expected))
+			}
+			for i := range out {
+				if !bytes.Equal(out[i], expected[i]) {
+					t.Errorf("party %d, round %d: message %d differs from the golden transcript", id, round, i)
+				}
+			}
+		}
+	}
+}
+
+// generateGolden runs the protocols of the golden transcript between live parties.
+func generateGolden(t *testing.T) *goldenFile {
+	var golden goldenFile
+	golden.Keygen.PartyIDs = party.IDSlice{1, 2, 3}
+	golden.Keygen.Threshold = 1
+	states, outputs := goldenKeygenStates(t, golden.Keygen.PartyIDs, golden.Keygen.Threshold)
+	golden.Keygen.Messages = recordRounds(t, golden.Keygen.PartyIDs, states)
+	golden.Keygen.Public = outputs[1].Public
+	golden.Keygen.SecretShares = make(map[party.ID]*eddsa.SecretShare, len(outputs))
+	for id, output := range outputs {
+		golden.Keygen.SecretShares[id] = output.SecretKey
+	}
+
+	golden.Sign.Signers = party.IDSlice{1, 3}
+	golden.Sign.Message = string(MESSAGE)
+	signStates, signOutputs := goldenSignStates(t, golden.Sign.Signers, golden.Keygen.SecretShares, golden.Keygen.Public, MESSAGE)
+	golden.Sign.Messages = recordRounds(t, golden.Sign.Signers, signStates)
+	golden.Sign.Signature = hex.EncodeToString(signOutputs[1].Signature.ToEd25519())
+	return &golden
+}
+
+func TestGoldenTranscript(t *testing.T) {
+	if *updateGolden {
+		data, err := json.MarshalIndent(generateGolden(t), "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(goldenPath, append(data, '\n'), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("wrote %s", goldenPath)
+	}
+
+	data, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("%v (run go generate to create it)", err)
+	}
+	var golden goldenFile
+	if err = json.Unmarshal(data, &golden); err != nil {
+		t.Fatal(err)
+	}
+
+	// Keygen
+	partyIDs := golden.Keygen.PartyIDs
+	states, outputs := goldenKeygenStates(t, partyIDs, golden.Keygen.Threshold)
+	replayRounds(t, partyIDs, states, golden.Keygen.Messages)
+	for _, id := range partyIDs {
+		if !outputs[id].Public.Equal(golden.Keygen.Public) {
+			t.Errorf("party %d: the public key differs from the golden transcript", id)
+		}
+		if !outputs[id].SecretKey.Equal(golden.Keygen.SecretShares[id]) {
+			t.Errorf("party %d: the secret share differs from the golden transcript", id)
+		}
+	}
+
+	// Sign, with the shares of the golden transcript
+	signers := golden.Sign.Signers
+	message := []byte(golden.Sign.Message)
+	signStates, signOutputs := goldenSignStates(t, signers, golden.Keygen.SecretShares, golden.Keygen.Public, message)
+	replayRounds(t, signers, signStates, golden.Sign.Messages)
+	for _, id := range signers {
+		signature := hex.EncodeToString(signOutputs[id].Signature.ToEd25519())
+		if signature != golden.Sign.Signature {
+			t.Errorf("party %d: signature %s, want %s", id, signature, golden.Sign.Signature)
+		}
+	}
+	if !golden.Keygen.Public.GroupKey.Verify(message, signOutputs[signers[0]].Signature) {
+		t.Error("the golden signature is invalid")
+	}
+}
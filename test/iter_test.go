@@ -0,0 +1,60 @@
+//go:build go1.23
+
+package main
+
+import (
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+func TestReceivedMessages(t *testing.T) {
+	// The messages of the first keygen round are processed as soon as they are received,
+	// so those of a signing protocol are used instead.
+	partyIDs := party.IDSlice{2, 5, 7, 9}
+	_, secrets := helpers.GenerateSecrets(partyIDs, 2)
+	public := helpers.GeneratePublic(2, secrets)
+	states := map[party.ID]*state.State{}
+	msgs1 := map[party.ID]*messages.Message{}
+	for _, id := range partyIDs {
+		var err error
+		if states[id], _, err = frost.NewSignState(partyIDs, secrets[id], public, []byte("message"), 0); err != nil {
+			t.Fatal(err)
+		}
+		msgs1[id] = states[id].ProcessAll()[0]
+	}
+
+	s := states[2]
+	for range s.ReceivedMessages() {
+		t.Error("ReceivedMessages() yielded a message before any was received")
+	}
+
+	// The messages are handled in decreasing order, and yielded in increasing order.
+	for _, id := range []party.ID{9, 5} {
+		if err := s.HandleMessage(msgs1[id]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var senders party.IDSlice
+	for id, msg := range s.ReceivedMessages() {
+		if msg != msgs1[id] {
+			t.Errorf("ReceivedMessages() yielded the wrong message for party %d", id)
+		}
+		senders = append(senders, id)
+	}
+	if !senders.Equal(party.IDSlice{5, 9}) {
+		t.Errorf("ReceivedMessages() yielded %v, want [5 9]", senders)
+	}
+
+	for range s.ReceivedMessages() {
+		break
+	}
+	// The lock was released after the break
+	if received, _ := s.ReceivedFrom(); !received.Equal(senders) {
+		t.Errorf("ReceivedFrom() = %v, want %v", received, senders)
+	}
+}
@@ -1,16 +1,28 @@
 package main
 
 import (
+	"context"
+	cryptorand "crypto/rand"
+	"encoding"
 	"errors"
+	mathrand "math/rand"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
 	"github.com/taurusgroup/frost-ed25519/pkg/frost"
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/keygen"
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/sign"
 	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
 	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 	"github.com/taurusgroup/frost-ed25519/pkg/state"
+	"github.com/taurusgroup/frost-ed25519/pkg/state/filestore"
 )
 
 func TestKeygen(t *testing.T) {
@@ -74,6 +86,9 @@ func TestKeygen(t *testing.T) {
 		if err := CompareOutput(groupKey1, groupKey2, publicShares1, publicShares2); err != nil {
 			t.Error(err)
 		}
+		if err := secrets[id2].Validate(publicShares1); err != nil {
+			t.Error(err)
+		}
 	}
 
 	if err := ValidateSecrets(secrets, groupKey1, publicShares1); err != nil {
@@ -81,6 +96,78 @@ func TestKeygen(t *testing.T) {
 	}
 }
 
+func TestKeygenConcurrentRetransmission(t *testing.T) {
+	N := party.Size(10)
+	T := N / 2
+
+	partyIDs := helpers.GenerateSet(N)
+
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*keygen.Output{}
+	for _, id := range partyIDs {
+		var err error
+		states[id], outputs[id], err = frost.NewKeygenState(id, partyIDs, T, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var in []*messages.Message
+	for _, s := range states {
+		in = append(in, s.ProcessAll()...)
+	}
+
+	// Every message is delivered twice, so that retransmissions are received while the round erases the shares.
+	for len(in) > 0 {
+		var (
+			wg  sync.WaitGroup
+			mtx sync.Mutex
+			out []*messages.Message
+		)
+		for id, s := range states {
+			for _, msg := range in {
+				if msg.From == id || (msg.To != 0 && msg.To != id) {
+					continue
+				}
+				data := marshal(t, msg)
+				for i := 0; i < 2; i++ {
+					wg.Add(1)
+					go func(s *state.State) {
+						defer wg.Done()
+						var msg messages.Message
+						if err := msg.UnmarshalBinary(data); err != nil {
+							t.Error(err)
+							return
+						}
+						if err := s.HandleMessage(&msg); err != nil {
+							t.Error(err)
+						}
+						if msgs := s.ProcessAll(); msgs != nil {
+							mtx.Lock()
+							defer mtx.Unlock()
+							out = append(out, msgs...)
+						}
+					}(s)
+				}
+			}
+		}
+		wg.Wait()
+		in = out
+	}
+
+	secrets := map[party.ID]*eddsa.SecretShare{}
+	public := outputs[partyIDs[0]].Public
+	for id, s := range states {
+		if err := s.WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+		secrets[id] = outputs[id].SecretKey
+	}
+	if err := ValidateSecrets(secrets, public.GroupKey, public); err != nil {
+		t.Error(err)
+	}
+}
+
 func CompareOutput(groupKey1, groupKey2 *eddsa.PublicKey, publicShares1, publicShares2 *eddsa.Public) error {
 	if !publicShares1.Equal(publicShares2) {
 		return errors.New("shares not equal")
@@ -143,3 +230,1784 @@ func ValidateSecrets(secrets map[party.ID]*eddsa.SecretShare, groupKey *eddsa.Pu
 
 	return nil
 }
+
+func TestKeygenRoundTimeout(t *testing.T) {
+	N := party.Size(4)
+	T := N - 1
+
+	partyIDs := helpers.GenerateSet(N)
+	offline := partyIDs[N-1]
+
+	states := map[party.ID]*state.State{}
+	for _, id := range partyIDs[:N-1] {
+		var err error
+		states[id], _, err = frost.NewKeygenState(id, partyIDs, T, 100*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msgsOut1 := make([][]byte, 0, N)
+	for _, s := range states {
+		msgs1, err := helpers.PartyRoutine(nil, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgsOut1 = append(msgsOut1, msgs1...)
+	}
+	for _, s := range states {
+		if _, err := helpers.PartyRoutine(msgsOut1, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, s := range states {
+		err := s.WaitForError()
+		if !errors.Is(err, state.ErrRoundTimeout) {
+			t.Fatalf("expected ErrRoundTimeout, got %v", err)
+		}
+		var timeoutErr *state.TimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Fatalf("expected a TimeoutError, got %v", err)
+		}
+		if !timeoutErr.Missing.Equal(party.IDSlice{offline}) {
+			t.Errorf("expected missing parties %v, got %v", party.IDSlice{offline}, timeoutErr.Missing)
+		}
+	}
+}
+
+func TestKeygenProgress(t *testing.T) {
+	N := party.Size(4)
+	T := N - 1
+
+	partyIDs := helpers.GenerateSet(N)
+
+	states := map[party.ID]*state.State{}
+	for _, id := range partyIDs {
+		var err error
+		states[id], _, err = frost.NewKeygenState(id, partyIDs, T, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	self := partyIDs[0]
+	s := states[self]
+
+	if missing, ok := s.MissingFrom(); ok || missing != nil {
+		t.Errorf("round 0 should not expect messages, got %v, %v", missing, ok)
+	}
+
+	msgs1 := map[party.ID]*messages.Message{}
+	for id, other := range states {
+		msgs1[id] = other.ProcessAll()[0]
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_, _ = s.MissingFrom()
+			_, _ = s.ReceivedFrom()
+		}
+	}()
+
+	for _, id := range partyIDs[1 : N-1] {
+		if err := s.HandleMessage(msgs1[id]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+
+	missing, ok := s.MissingFrom()
+	if !ok || !missing.Equal(partyIDs[N-1:]) {
+		t.Errorf("MissingFrom() = %v, %v, want %v", missing, ok, partyIDs[N-1:])
+	}
+	received, ok := s.ReceivedFrom()
+	if !ok || !received.Equal(partyIDs[1:N-1]) {
+		t.Errorf("ReceivedFrom() = %v, %v, want %v", received, ok, partyIDs[1:N-1])
+	}
+
+	if err := s.HandleMessage(msgs1[partyIDs[N-1]]); err != nil {
+		t.Fatal(err)
+	}
+	if missing, ok = s.MissingFrom(); !ok || missing == nil || len(missing) != 0 {
+		t.Errorf("MissingFrom() = %v, %v, want empty slice", missing, ok)
+	}
+}
+
+func TestKeygenSnapshot(t *testing.T) {
+	testKeygenSnapshot(t, frost.NewKeygenState)
+}
+
+func TestKeygenSnapshotEncrypted(t *testing.T) {
+	testKeygenSnapshot(t, frost.NewEncryptedKeygenState)
+}
+
+type newKeygenState func(party.ID, party.IDSlice, party.Size, time.Duration, ...state.Option) (*state.State, *keygen.Output, error)
+
+func testKeygenSnapshot(t *testing.T, newState newKeygenState) {
+	N := party.Size(3)
+	T := N - 1
+
+	partyIDs := helpers.GenerateSet(N)
+	restored := partyIDs[0]
+
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*keygen.Output{}
+	for _, id := range partyIDs {
+		var err error
+		states[id], outputs[id], err = newState(id, partyIDs, T, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Rounds keep references to the messages they receive, and modify them when they are reset.
+	// We therefore give each party its own copy, as if they were received over the network.
+	copyMessages := func(msgs []*messages.Message) []*messages.Message {
+		copies := make([]*messages.Message, 0, len(msgs))
+		for _, msg := range msgs {
+			var msgCopy messages.Message
+			data, err := msg.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = msgCopy.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			copies = append(copies, &msgCopy)
+		}
+		return copies
+	}
+
+	var msgs1, msgs2 []*messages.Message
+	for _, s := range states {
+		msgs1 = append(msgs1, copyMessages(s.ProcessAll())...)
+	}
+	for _, s := range states {
+		for _, msg := range copyMessages(msgs1) {
+			if err := s.HandleMessage(msg); err != nil {
+				t.Fatal(err)
+			}
+		}
+		msgs2 = append(msgs2, s.ProcessAll()...)
+	}
+
+	// The restored party only receives the message from one party before it is restarted
+	for id, s := range states {
+		for _, msg := range copyMessages(msgs2) {
+			if msg.To != id || id == restored && msg.From != partyIDs[1] {
+				continue
+			}
+			if err := s.HandleMessage(msg); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s.ProcessAll()
+	}
+
+	snapshot, err := states[restored].Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := snapshot.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	states[restored], outputs[restored], err = frost.RestoreKeygenState(data, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range msgs2 {
+		if msg.To != restored || msg.From == partyIDs[1] {
+			continue
+		}
+		if err = states[restored].HandleMessage(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	states[restored].ProcessAll()
+
+	secrets := map[party.ID]*eddsa.SecretShare{}
+	for _, id := range partyIDs {
+		if err = states[id].WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+		secrets[id] = outputs[id].SecretKey
+		if err = CompareOutput(outputs[restored].Public.GroupKey, outputs[id].Public.GroupKey, outputs[restored].Public, outputs[id].Public); err != nil {
+			t.Error(err)
+		}
+	}
+	if err = ValidateSecrets(secrets, outputs[restored].Public.GroupKey, outputs[restored].Public); err != nil {
+		t.Error(err)
+	}
+}
+
+type recordingObserver struct {
+	s *state.State
+
+	mtx         sync.Mutex
+	started     []int
+	finished    []int
+	stored      map[messages.MessageType]int
+	abortErrors []error
+}
+
+func (o *recordingObserver) OnRoundStart(round int) {
+	// Calling the State from an Observer must not deadlock
+	if o.s != nil {
+		_, _ = o.s.MissingFrom()
+	}
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	o.started = append(o.started, round)
+}
+
+func (o *recordingObserver) OnMessageStored(_ party.ID, t messages.MessageType) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	o.stored[t]++
+}
+
+func (o *recordingObserver) OnRoundFinish(round int, _ time.Duration) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	o.finished = append(o.finished, round)
+}
+
+func (o *recordingObserver) OnAbort(_ []party.ID, err error) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	o.abortErrors = append(o.abortErrors, err)
+}
+
+func TestKeygenObserver(t *testing.T) {
+	N := party.Size(4)
+	T := N - 1
+
+	partyIDs := helpers.GenerateSet(N)
+
+	states := map[party.ID]*state.State{}
+	observers := map[party.ID]*recordingObserver{}
+	for _, id := range partyIDs {
+		var err error
+		observers[id] = &recordingObserver{stored: map[messages.MessageType]int{}}
+		states[id], _, err = frost.NewKeygenState(id, partyIDs, T, 0, state.WithObserver(observers[id]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		observers[id].s = states[id]
+	}
+
+	msgsOut1 := make([][]byte, 0, N)
+	msgsOut2 := make([][]byte, 0, N*(N-1))
+	for _, s := range states {
+		msgs1, err := helpers.PartyRoutine(nil, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgsOut1 = append(msgsOut1, msgs1...)
+	}
+	for _, s := range states {
+		msgs2, err := helpers.PartyRoutine(msgsOut1, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgsOut2 = append(msgsOut2, msgs2...)
+	}
+	for _, s := range states {
+		if _, err := helpers.PartyRoutine(msgsOut2, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for id, s := range states {
+		if err := s.WaitForError(); err != nil {
+			t.Error(err)
+		}
+		o := observers[id]
+		o.mtx.Lock()
+		if len(o.abortErrors) != 0 {
+			t.Errorf("unexpected aborts %v", o.abortErrors)
+		}
+		if !reflect.DeepEqual(o.started, []int{0, 1, 2}) {
+			t.Errorf("started rounds %v", o.started)
+		}
+		if !reflect.DeepEqual(o.finished, []int{0, 1, 2}) {
+			t.Errorf("finished rounds %v", o.finished)
+		}
+		if o.stored[messages.MessageTypeKeyGen1] != int(N-1) || o.stored[messages.MessageTypeKeyGen2] != int(N-1) {
+			t.Errorf("stored messages %v", o.stored)
+		}
+		o.mtx.Unlock()
+	}
+
+	// A party which never receives any messages aborts with a timeout
+	o := &recordingObserver{stored: map[messages.MessageType]int{}}
+	s, _, err := frost.NewKeygenState(partyIDs[0], partyIDs, T, 50*time.Millisecond, state.WithObserver(o))
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.s = s
+	if _, err = helpers.PartyRoutine(nil, s); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.WaitForError(); !errors.Is(err, state.ErrRoundTimeout) {
+		t.Fatalf("expected ErrRoundTimeout, got %v", err)
+	}
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	if len(o.abortErrors) != 1 || !errors.Is(o.abortErrors[0], state.ErrRoundTimeout) {
+		t.Errorf("expected one abort with ErrRoundTimeout, got %v", o.abortErrors)
+	}
+}
+
+// keygenWithInvalidShares runs a keygen where each party in culprits sends an invalid share to victim,
+// and returns the error of the victim.
+func keygenWithInvalidShares(t *testing.T, partyIDs party.IDSlice, victim party.ID, culprits party.IDSlice) error {
+	T := partyIDs.N() - 1
+
+	states := map[party.ID]*state.State{}
+	for _, id := range partyIDs {
+		var err error
+		states[id], _, err = frost.NewKeygenState(id, partyIDs, T, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msgsOut1 := make([][]byte, 0, len(partyIDs))
+	msgsOut2 := make([][]byte, 0, len(partyIDs)*(len(partyIDs)-1))
+	for _, s := range states {
+		msgs1, err := helpers.PartyRoutine(nil, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgsOut1 = append(msgsOut1, msgs1...)
+	}
+	for _, s := range states {
+		msgs2, err := helpers.PartyRoutine(msgsOut1, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgsOut2 = append(msgsOut2, msgs2...)
+	}
+
+	for i, data := range msgsOut2 {
+		var msg messages.Message
+		if err := msg.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if !culprits.Contains(msg.From) || msg.To != victim {
+			continue
+		}
+		msg.KeyGen2.Share.Add(&msg.KeyGen2.Share, victim.Scalar())
+		var err error
+		if msgsOut2[i], err = msg.MarshalBinary(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := helpers.PartyRoutine(msgsOut2, states[victim]); err == nil {
+		t.Fatal("expected the protocol to abort")
+	}
+	return states[victim].WaitForError()
+}
+
+func TestKeygenInvalidShare(t *testing.T) {
+	partyIDs := helpers.GenerateSet(3)
+	victim, culprit := partyIDs[0], partyIDs[1]
+
+	err := keygenWithInvalidShares(t, partyIDs, victim, party.IDSlice{culprit})
+
+	if !errors.Is(err, keygen.ErrValidateShare) {
+		t.Errorf("expected ErrValidateShare, got %v", err)
+	}
+	var protocolErr *state.Error
+	if !errors.As(err, &protocolErr) {
+		t.Fatalf("expected a *state.Error, got %v", err)
+	}
+	if protocolErr.Culprit() != culprit {
+		t.Errorf("expected culprit %d, got %d", culprit, protocolErr.Culprit())
+	}
+	if protocolErr.RoundNumber() != 2 {
+		t.Errorf("expected round 2, got %d", protocolErr.RoundNumber())
+	}
+	if protocolErr.Kind() != state.KindVSSFailure {
+		t.Errorf("expected kind %v, got %v", state.KindVSSFailure, protocolErr.Kind())
+	}
+}
+
+func TestKeygenInvalidShareMultipleCulprits(t *testing.T) {
+	partyIDs := helpers.GenerateSet(5)
+	victim := partyIDs[0]
+	culprits := party.IDSlice{partyIDs[2], partyIDs[4]}
+
+	err := keygenWithInvalidShares(t, partyIDs, victim, culprits)
+
+	var protocolErr *state.Error
+	if !errors.As(err, &protocolErr) {
+		t.Fatalf("expected a *state.Error, got %v", err)
+	}
+	if !protocolErr.Culprits().Equal(culprits) {
+		t.Errorf("expected culprits %v, got %v", culprits, protocolErr.Culprits())
+	}
+	if !errors.Is(err, keygen.ErrValidateShare) {
+		t.Errorf("expected ErrValidateShare, got %v", err)
+	}
+
+	dir, err := party.NewDirectory(nil, map[string]party.ID{"carol": 3, "eve": 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	described := protocolErr.Describe(dir)
+	if !strings.HasPrefix(described, "parties [carol (3) eve (5)]: round 2: ") {
+		t.Errorf("Describe() = %q", described)
+	}
+	if protocolErr.Describe(nil) != protocolErr.Error() {
+		t.Errorf("Describe(nil) = %q, want %q", protocolErr.Describe(nil), protocolErr.Error())
+	}
+}
+
+func TestKeygenTypedOutput(t *testing.T) {
+	N := party.Size(3)
+	T := N - 1
+
+	partyIDs := helpers.GenerateSet(N)
+
+	states := map[party.ID]*state.State{}
+	rawOutputs := map[party.ID]*keygen.Output{}
+	outputs := map[party.ID]*state.Output[*keygen.Output]{}
+	for _, id := range partyIDs {
+		var err error
+		states[id], rawOutputs[id], err = frost.NewKeygenState(id, partyIDs, T, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[id] = frost.NewKeygenOutput(states[id], rawOutputs[id])
+	}
+
+	var in [][]byte
+	for round := 0; round < 3; round++ {
+		out := make([][]byte, 0, N*N)
+		for _, s := range states {
+			msgs, err := helpers.PartyRoutine(in, s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, msgs...)
+		}
+		in = out
+	}
+
+	secrets := map[party.ID]*eddsa.SecretShare{}
+	for id, output := range outputs {
+		result, err := output.WaitFor(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Public.Equal(rawOutputs[id].Public) || !result.SecretKey.Equal(rawOutputs[id].SecretKey) {
+			t.Error("WaitFor() should return the output of the protocol")
+		}
+		secrets[id] = result.SecretKey.Copy()
+
+		// Modifying the result does not affect the protocol's output
+		result.Public.Shares[id].Set(ristretto.NewIdentityElement())
+		result.SecretKey.Secret.Set(ristretto.NewScalar())
+		if result.Public.Equal(rawOutputs[id].Public) || result.SecretKey.Equal(rawOutputs[id].SecretKey) {
+			t.Error("WaitFor() should return a copy")
+		}
+
+		result2, err := output.WaitFor(context.Background())
+		if err != nil || result2 != result {
+			t.Errorf("WaitFor() should return the same value, got %v, %v", result2, err)
+		}
+	}
+
+	public := rawOutputs[partyIDs[0]].Public
+	if err := ValidateSecrets(secrets, public.GroupKey, public); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestKeygenRouting(t *testing.T) {
+	partyIDs := helpers.GenerateSet(4)
+
+	states := map[party.ID]*state.State{}
+	for _, id := range partyIDs {
+		var err error
+		if states[id], _, err = frost.NewKeygenState(id, partyIDs, 2, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Messages are routed using only the metadata of their type, and each party receives only its own messages.
+	var in []*messages.Message
+	for _, id := range partyIDs {
+		in = append(in, states[id].ProcessAll()...)
+	}
+	for round := 1; round <= 2; round++ {
+		received := map[party.ID]int{}
+		for _, msg := range in {
+			if msg.IsBroadcast() != msg.Type.IsBroadcast() {
+				t.Fatalf("message of type %s has recipient %d", msg.Type, msg.To)
+			}
+			recipients := party.IDSlice{msg.To}
+			if msg.Type.IsBroadcast() {
+				recipients = partyIDs
+			}
+			for _, id := range recipients {
+				if id == msg.From {
+					continue
+				}
+				received[id]++
+				var msgCopy messages.Message
+				if err := msgCopy.UnmarshalBinary(marshal(t, msg)); err != nil {
+					t.Fatal(err)
+				}
+				if err := states[id].HandleMessage(&msgCopy); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		for _, id := range partyIDs {
+			if received[id] != len(partyIDs)-1 {
+				t.Errorf("round %d: party %d received %d messages, want %d", round, id, received[id], len(partyIDs)-1)
+			}
+		}
+
+		var out []*messages.Message
+		for _, id := range partyIDs {
+			out = append(out, states[id].ProcessAll()...)
+		}
+		in = out
+	}
+	for _, s := range states {
+		if err := s.WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestKeygenRecovery(t *testing.T) {
+	N := party.Size(3)
+	T := N - 1
+
+	partyIDs := helpers.GenerateSet(N)
+	restored := partyIDs[0]
+	path := filepath.Join(t.TempDir(), "messages.log")
+
+	store, err := filestore.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*keygen.Output{}
+	for _, id := range partyIDs {
+		var opts []state.Option
+		if id == restored {
+			opts = append(opts, state.WithMessageStore(store))
+		}
+		if states[id], outputs[id], err = frost.NewKeygenState(id, partyIDs, T, 0, opts...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// deliver gives a copy of each message to its recipients accepted by filter
+	deliver := func(msgs []*messages.Message, filter func(to party.ID, msg *messages.Message) bool) {
+		for _, msg := range msgs {
+			for _, id := range partyIDs {
+				if id == msg.From || !msg.IsBroadcast() && msg.To != id || !filter(id, msg) {
+					continue
+				}
+				var msgCopy messages.Message
+				if err := msgCopy.UnmarshalBinary(marshal(t, msg)); err != nil {
+					t.Fatal(err)
+				}
+				if err := states[id].HandleMessage(&msgCopy); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+	all := func(party.ID, *messages.Message) bool { return true }
+
+	var msgs1, msgs2 []*messages.Message
+	for _, id := range partyIDs {
+		msgs1 = append(msgs1, states[id].ProcessAll()...)
+	}
+	deliver(msgs1, all)
+	for _, id := range partyIDs {
+		msgs2 = append(msgs2, states[id].ProcessAll()...)
+	}
+
+	// The restored party takes a snapshot after round 1, and only receives the message from one party before crashing
+	snapshot, err := states[restored].Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshotData, err := snapshot.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	receivedBeforeCrash := func(to party.ID, msg *messages.Message) bool {
+		return to != restored || msg.From == partyIDs[1]
+	}
+	deliver(msgs2, receivedBeforeCrash)
+	for _, id := range partyIDs[1:] {
+		states[id].ProcessAll()
+	}
+	if err = store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// After the restart, the message received after the snapshot is recovered from the log
+	if store, err = filestore.Open(path); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if states[restored], outputs[restored], err = frost.RestoreKeygenState(snapshotData, 0, state.WithMessageStore(store)); err != nil {
+		t.Fatal(err)
+	}
+	if err = states[restored].ReplayMessages(); err != nil {
+		t.Fatal(err)
+	}
+	if missing := states[restored].Diagnostics().Missing; !missing.Equal(party.IDSlice{partyIDs[2]}) {
+		t.Fatalf("expected to only miss the message from party %d, got %v", partyIDs[2], missing)
+	}
+	deliver(msgs2, func(to party.ID, msg *messages.Message) bool { return !receivedBeforeCrash(to, msg) })
+	states[restored].ProcessAll()
+
+	secrets := map[party.ID]*eddsa.SecretShare{}
+	for _, id := range partyIDs {
+		if err = states[id].WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+		secrets[id] = outputs[id].SecretKey
+	}
+	records, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two KeyGen1 and two KeyGen2 messages, the replayed one being recorded only once
+	if len(records) != 4 {
+		t.Errorf("expected 4 records, got %d", len(records))
+	}
+
+	// The recovered shares can be used to sign
+	public := outputs[restored].Public
+	signStates := map[party.ID]*state.State{}
+	signOutputs := map[party.ID]*sign.Output{}
+	for _, id := range partyIDs {
+		if signStates[id], signOutputs[id], err = frost.NewSignState(partyIDs, secrets[id], public, MESSAGE, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var in [][]byte
+	for round := 0; round < 3; round++ {
+		var out [][]byte
+		for _, s := range signStates {
+			msgs, err := helpers.PartyRoutine(in, s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, msgs...)
+		}
+		in = out
+	}
+	for id, s := range signStates {
+		if err = s.WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+		if !public.GroupKey.Verify(MESSAGE, signOutputs[id].Signature) {
+			t.Error("signature is invalid")
+		}
+	}
+}
+
+// messageTypeAck is an application defined message, which parties broadcast to acknowledge a round.
+const messageTypeAck messages.MessageType = 200
+
+// ack is the content of a messageTypeAck message.
+type ack struct{ round uint8 }
+
+func (a ack) MarshalBinary() ([]byte, error) {
+	return []byte{a.round}, nil
+}
+
+func init() {
+	err := messages.RegisterExtension(messageTypeAck, messages.Extension{
+		Name:      "ack",
+		Broadcast: true,
+		MaxSize:   1,
+		Unmarshal: func(data []byte) (encoding.BinaryMarshaler, error) {
+			if len(data) != 1 {
+				return nil, errors.New("ack must be a single byte")
+			}
+			return ack{data[0]}, nil
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestKeygenExtension(t *testing.T) {
+	N := party.Size(4)
+	T := N - 1
+
+	partyIDs := helpers.GenerateSet(N)
+
+	var mtx sync.Mutex
+	acks := map[party.ID][]ack{}
+	states := map[party.ID]*state.State{}
+	for _, id := range partyIDs {
+		id := id
+		handler := func(msg *messages.Message) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			acks[id] = append(acks[id], msg.Extension.(ack))
+		}
+		var err error
+		states[id], _, err = frost.NewKeygenState(id, partyIDs, T, 0, state.WithExtension(messageTypeAck, handler))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each party acknowledges the round it just finished, alongside its protocol messages
+	var msgs [][]byte
+	for round := uint8(0); round < 3; round++ {
+		var out [][]byte
+		for _, id := range partyIDs {
+			s := states[id]
+			msgsOut, err := helpers.PartyRoutine(msgs, s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg := messages.NewExtension(messageTypeAck, id, 0, ack{round})
+			msg.SessionID = s.SessionID()
+			data, err := s.Codec().Marshal(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, data)
+			out = append(out, msgsOut...)
+		}
+		msgs = out
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	for _, id := range partyIDs {
+		if err := states[id].WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+		// The acks of the last round are sent after the protocol is done
+		if expected := []ack{{0}, {0}, {0}, {1}, {1}, {1}}; !reflect.DeepEqual(acks[id], expected) {
+			t.Errorf("party %d received acks %v", id, acks[id])
+		}
+	}
+
+	// The State rejects messages of extension types without a handler
+	s, _, err := frost.NewKeygenState(1, partyIDs, T, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := messages.NewExtension(messageTypeAck, 2, 0, ack{0})
+	msg.SessionID = s.SessionID()
+	if err = s.HandleMessage(msg); err == nil {
+		t.Error("expected an error for an unhandled extension type")
+	}
+}
+
+// encryptedKeygenShares runs the first two rounds of an encrypted keygen,
+// and returns the states and the KeyGen2 messages which have yet to be delivered.
+func encryptedKeygenShares(t *testing.T, partyIDs party.IDSlice) (map[party.ID]*state.State, map[party.ID]*keygen.Output, [][]byte) {
+	T := partyIDs.N() - 1
+
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*keygen.Output{}
+	for _, id := range partyIDs {
+		var err error
+		states[id], outputs[id], err = frost.NewEncryptedKeygenState(id, partyIDs, T, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msgsOut1 := make([][]byte, 0, len(partyIDs))
+	msgsOut2 := make([][]byte, 0, len(partyIDs)*(len(partyIDs)-1))
+	for _, s := range states {
+		msgs1, err := helpers.PartyRoutine(nil, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgsOut1 = append(msgsOut1, msgs1...)
+	}
+	for _, s := range states {
+		msgs2, err := helpers.PartyRoutine(msgsOut1, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgsOut2 = append(msgsOut2, msgs2...)
+	}
+	return states, outputs, msgsOut2
+}
+
+func TestKeygenEncrypted(t *testing.T) {
+	partyIDs := helpers.GenerateSet(5)
+
+	states, outputs, msgsOut2 := encryptedKeygenShares(t, partyIDs)
+
+	for _, data := range msgsOut2 {
+		var msg messages.Message
+		if err := msg.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if msg.KeyGen2.SealedShare == nil {
+			t.Fatalf("share from %d to %d was not encrypted", msg.From, msg.To)
+		}
+		if msg.KeyGen2.Share.Equal(ristretto.NewScalar()) != 1 {
+			t.Errorf("plaintext share from %d to %d was sent", msg.From, msg.To)
+		}
+	}
+
+	for _, s := range states {
+		if _, err := helpers.PartyRoutine(msgsOut2, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	id1 := partyIDs[0]
+	secrets := map[party.ID]*eddsa.SecretShare{}
+	for _, id2 := range partyIDs {
+		if err := states[id2].WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+		secrets[id2] = outputs[id2].SecretKey
+		if err := CompareOutput(outputs[id1].Public.GroupKey, outputs[id2].Public.GroupKey, outputs[id1].Public, outputs[id2].Public); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := ValidateSecrets(secrets, outputs[id1].Public.GroupKey, outputs[id1].Public); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestKeygenEncryptedTampered(t *testing.T) {
+	partyIDs := helpers.GenerateSet(3)
+	victim, culprit := partyIDs[0], partyIDs[1]
+
+	states, _, msgsOut2 := encryptedKeygenShares(t, partyIDs)
+
+	for i, data := range msgsOut2 {
+		var msg messages.Message
+		if err := msg.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if msg.From != culprit || msg.To != victim {
+			continue
+		}
+		msg.KeyGen2.SealedShare[0] ^= 1
+		var err error
+		if msgsOut2[i], err = msg.MarshalBinary(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := helpers.PartyRoutine(msgsOut2, states[victim]); err == nil {
+		t.Fatal("expected the protocol to abort")
+	}
+	err := states[victim].WaitForError()
+
+	if !errors.Is(err, keygen.ErrDecryptShare) {
+		t.Errorf("expected ErrDecryptShare, got %v", err)
+	}
+	var protocolErr *state.Error
+	if !errors.As(err, &protocolErr) {
+		t.Fatalf("expected a *state.Error, got %v", err)
+	}
+	if protocolErr.Culprit() != culprit {
+		t.Errorf("expected culprit %d, got %d", culprit, protocolErr.Culprit())
+	}
+	if protocolErr.Kind() != state.KindDecryptionFailure {
+		t.Errorf("expected kind %v, got %v", state.KindDecryptionFailure, protocolErr.Kind())
+	}
+}
+
+func TestKeygenEncryptedMixed(t *testing.T) {
+	partyIDs := helpers.GenerateSet(3)
+	T := partyIDs.N() - 1
+
+	encrypted, _, err := frost.NewEncryptedKeygenState(partyIDs[0], partyIDs, T, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, _, err := frost.NewKeygenState(partyIDs[1], partyIDs, T, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msgsEncrypted, err := helpers.PartyRoutine(nil, encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgsPlain, err := helpers.PartyRoutine(nil, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both parties reject the KeyGen1 message of the other
+	var fieldErr *messages.FieldError
+	if _, err = helpers.PartyRoutine(msgsPlain, encrypted); !errors.As(err, &fieldErr) || fieldErr.Field != "KeyGen1.EncryptionKey" {
+		t.Errorf("expected an error for a message without encryption key, got %v", err)
+	}
+	if _, err = helpers.PartyRoutine(msgsEncrypted, plain); !errors.As(err, &fieldErr) || fieldErr.Field != "KeyGen1.EncryptionKey" {
+		t.Errorf("expected an error for a message with an encryption key, got %v", err)
+	}
+}
+
+// keygenEchoOptions are the keygen options used by the tests of the echo round.
+var keygenEchoOptions = []keygen.Option{keygen.WithEchoRound(), keygen.WithEncryptedShares()}
+
+// runKeygen runs the keygen protocol between states, broadcasting the messages of each round to all parties,
+// and returns the error of the first party which fails.
+// The party with ID restored is replaced by a State restored from its snapshot when it reaches restoreRound.
+func runKeygen(t *testing.T, partyIDs party.IDSlice, states map[party.ID]*state.State, outputs map[party.ID]*keygen.Output, restored party.ID, restoreRound int) error {
+	var msgs [][]byte
+	for round := 0; round < 6; round++ {
+		var out [][]byte
+		for _, id := range partyIDs {
+			if id == restored && round == restoreRound {
+				snapshot, err := states[id].Snapshot()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if snapshot.RoundNumber != restoreRound {
+					t.Fatalf("expected a snapshot of round %d, got %d", restoreRound, snapshot.RoundNumber)
+				}
+				data, err := snapshot.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if states[id], outputs[id], err = frost.RestoreKeygenState(data, 0); err != nil {
+					t.Fatal(err)
+				}
+			}
+			msgsOut, err := helpers.PartyRoutine(msgs, states[id])
+			if err != nil {
+				return err
+			}
+			out = append(out, msgsOut...)
+		}
+		msgs = out
+	}
+	return nil
+}
+
+func TestKeygenEcho(t *testing.T) {
+	partyIDs := helpers.GenerateSet(4)
+	T := partyIDs.N() - 1
+
+	// The party is restored before processing the echo round, and before processing the shares
+	for _, restoreRound := range []int{0, 2, 3} {
+		states := map[party.ID]*state.State{}
+		outputs := map[party.ID]*keygen.Output{}
+		for _, id := range partyIDs {
+			var err error
+			states[id], outputs[id], err = frost.NewKeygenStateWithOptions(id, partyIDs, T, 0, keygenEchoOptions)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := runKeygen(t, partyIDs, states, outputs, partyIDs[1], restoreRound); err != nil {
+			t.Fatal(err)
+		}
+
+		id1 := partyIDs[0]
+		secrets := map[party.ID]*eddsa.SecretShare{}
+		for _, id2 := range partyIDs {
+			if err := states[id2].WaitForError(); err != nil {
+				t.Fatal(err)
+			}
+			secrets[id2] = outputs[id2].SecretKey
+			if err := CompareOutput(outputs[id1].Public.GroupKey, outputs[id2].Public.GroupKey, outputs[id1].Public, outputs[id2].Public); err != nil {
+				t.Error(err)
+			}
+		}
+		if err := ValidateSecrets(secrets, outputs[id1].Public.GroupKey, outputs[id1].Public); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestKeygenEchoEquivocation(t *testing.T) {
+	partyIDs := helpers.GenerateSet(4)
+	T := partyIDs.N() - 1
+	dealer := partyIDs[3]
+	// The dealer sends its KeyGen1 message to the first party, and a different one to the others
+	deceived := party.IDSlice{partyIDs[1], partyIDs[2]}
+
+	states := map[party.ID]*state.State{}
+	for _, id := range partyIDs {
+		var err error
+		states[id], _, err = frost.NewKeygenStateWithOptions(id, partyIDs, T, 0, keygenEchoOptions)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A second execution of the dealer produces different, but valid, commitments
+	other, _, err := frost.NewKeygenStateWithOptions(dealer, partyIDs, T, 0, keygenEchoOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equivocation, err := other.ProcessAll()[0].MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Messages are delivered encoded, since the rounds keep references to the ones they send and receive
+	msgs1 := map[party.ID][]byte{}
+	for _, id := range partyIDs {
+		if msgs1[id], err = states[id].ProcessAll()[0].MarshalBinary(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range partyIDs {
+		for from, data := range msgs1 {
+			if from == dealer && deceived.Contains(id) {
+				data = equivocation
+			}
+			var msg messages.Message
+			if err = msg.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			if err = states[id].HandleMessage(&msg); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	var echoes []*messages.Message
+	for _, id := range partyIDs {
+		echoes = append(echoes, states[id].ProcessAll()...)
+	}
+	for _, id := range partyIDs {
+		for _, msg := range echoes {
+			if msg.Type != messages.MessageTypeKeyGenEcho {
+				t.Fatalf("expected an echo message, got %s", msg.Type)
+			}
+			if err = states[id].HandleMessage(msg); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// No shares are sent
+		if msgs := states[id].ProcessAll(); len(msgs) != 0 {
+			t.Errorf("party %d sent %d messages after the echo round", id, len(msgs))
+		}
+	}
+
+	for _, id := range partyIDs {
+		err = states[id].WaitForError()
+		if !errors.Is(err, keygen.ErrEchoMismatch) {
+			t.Fatalf("party %d: expected ErrEchoMismatch, got %v", id, err)
+		}
+		var protocolErr *state.Error
+		if !errors.As(err, &protocolErr) {
+			t.Fatalf("expected a *state.Error, got %v", err)
+		}
+		if protocolErr.Kind() != state.KindInconsistentBroadcast {
+			t.Errorf("expected kind %v, got %v", state.KindInconsistentBroadcast, protocolErr.Kind())
+		}
+		if protocolErr.RoundNumber() != 2 {
+			t.Errorf("expected round 2, got %d", protocolErr.RoundNumber())
+		}
+		// Each party names the parties whose view differs from its own
+		var expected party.IDSlice
+		if deceived.Contains(id) {
+			expected = party.IDSlice{partyIDs[0], dealer}
+		} else {
+			expected = deceived.Copy()
+		}
+		if !protocolErr.Culprits().Equal(expected) {
+			t.Errorf("party %d: expected culprits %v, got %v", id, expected, protocolErr.Culprits())
+		}
+	}
+}
+
+func TestKeygenInbox(t *testing.T) {
+	partyIDs := helpers.GenerateSet(4)
+	T := partyIDs.N() - 1
+	late := partyIDs[0]
+	sessionID := messages.DeriveSessionID(partyIDs, []byte("inbox"))
+
+	// The messages of the other parties arrive before the late party has created its state
+	inbox := messages.NewInbox(messages.InboxLimits{})
+
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*keygen.Output{}
+	for _, id := range partyIDs[1:] {
+		var err error
+		states[id], outputs[id], err = frost.NewKeygenState(id, partyIDs, T, 0, state.WithSessionID(sessionID))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	var msgsOut1 [][]byte
+	for _, id := range partyIDs[1:] {
+		msgs1, err := helpers.PartyRoutine(nil, states[id])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, data := range msgs1 {
+			if err = inbox.Add(data); err != nil {
+				t.Fatal(err)
+			}
+		}
+		msgsOut1 = append(msgsOut1, msgs1...)
+	}
+
+	var err error
+	states[late], outputs[late], err = frost.NewKeygenState(late, partyIDs, T, 0, state.WithSessionID(sessionID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := inbox.Drain(states[late])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(msgsOut1) {
+		t.Errorf("expected %d messages to be drained, got %d", len(msgsOut1), n)
+	}
+
+	// The late party sends its KeyGen1 message, and then processes the ones held by the inbox
+	lateMsgs1, err := helpers.PartyRoutine(nil, states[late])
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgsOut2, err := helpers.PartyRoutine(nil, states[late])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range partyIDs[1:] {
+		msgs2, err := helpers.PartyRoutine(append(msgsOut1, lateMsgs1...), states[id])
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgsOut2 = append(msgsOut2, msgs2...)
+	}
+	for _, id := range partyIDs {
+		if _, err = helpers.PartyRoutine(msgsOut2, states[id]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, id := range partyIDs {
+		if err = states[id].WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+		if err = CompareOutput(outputs[late].Public.GroupKey, outputs[id].Public.GroupKey, outputs[late].Public, outputs[id].Public); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestKeygenPacked(t *testing.T) {
+	partyIDs := helpers.GenerateSet(4)
+	T := partyIDs.N() - 1
+
+	// The party is restored before processing the packed shares
+	tests := []struct {
+		name         string
+		opts         []keygen.Option
+		restoreRound int
+	}{
+		{"plain", []keygen.Option{keygen.WithPackedShares()}, 2},
+		{"encrypted", []keygen.Option{keygen.WithPackedShares(), keygen.WithEncryptedShares()}, 2},
+		{"echo", append([]keygen.Option{keygen.WithPackedShares()}, keygenEchoOptions...), 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			states := map[party.ID]*state.State{}
+			outputs := map[party.ID]*keygen.Output{}
+			for _, id := range partyIDs {
+				var err error
+				states[id], outputs[id], err = frost.NewKeygenStateWithOptions(id, partyIDs, T, 0, tt.opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := runKeygen(t, partyIDs, states, outputs, partyIDs[2], tt.restoreRound); err != nil {
+				t.Fatal(err)
+			}
+
+			id1 := partyIDs[0]
+			secrets := map[party.ID]*eddsa.SecretShare{}
+			for _, id2 := range partyIDs {
+				if err := states[id2].WaitForError(); err != nil {
+					t.Fatal(err)
+				}
+				secrets[id2] = outputs[id2].SecretKey
+				if err := CompareOutput(outputs[id1].Public.GroupKey, outputs[id2].Public.GroupKey, outputs[id1].Public, outputs[id2].Public); err != nil {
+					t.Error(err)
+				}
+			}
+			if err := ValidateSecrets(secrets, outputs[id1].Public.GroupKey, outputs[id1].Public); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestKeygenPackedRecipients(t *testing.T) {
+	partyIDs := helpers.GenerateSet(3)
+	T := partyIDs.N() - 1
+	self, sender := partyIDs[0], partyIDs[1]
+
+	s, _, err := frost.NewKeygenState(self, partyIDs, T, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	share := ristretto.NewScalar()
+	pack := func(to ...party.ID) *messages.Message {
+		msgs := make([]*messages.Message, 0, len(to))
+		for _, id := range to {
+			msg := messages.NewKeyGen2(sender, id, share)
+			msg.SessionID = s.SessionID()
+			msgs = append(msgs, msg)
+		}
+		packed, err := messages.NewPacked(msgs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return packed
+	}
+
+	tests := []struct {
+		name string
+		msg  *messages.Message
+		err  error
+	}{
+		{"unknown recipient", pack(self, partyIDs[2], 42), state.ErrUnknownRecipient},
+		{"sender is a recipient", pack(self, sender), state.ErrUnknownRecipient},
+		{"not a recipient", pack(partyIDs[2]), state.ErrWrongRecipient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.HandleMessage(tt.msg); !errors.Is(err, tt.err) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+
+	// Our part of a valid packed message is stored for the second round
+	if err = s.HandleMessage(pack(self, partyIDs[2])); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// keygenMessages runs a keygen between partyIDs, drawing all randomness from a source seeded with seed,
+// and returns the encoded messages sent by the parties, in the order in which they were generated.
+// partyIDs is given unsorted to the states, but the parties are run one after the other in increasing order,
+// so that each one draws the same randomness in every run.
+func keygenMessages(t *testing.T, partyIDs party.IDSlice, seed int64, keygenOpts ...keygen.Option) [][]byte {
+	reader := cryptorand.Reader
+	defer func() { cryptorand.Reader = reader }()
+	cryptorand.Reader = mathrand.New(mathrand.NewSource(seed))
+
+	states := make(map[party.ID]*state.State, len(partyIDs))
+	for _, id := range partyIDs {
+		var err error
+		if states[id], _, err = frost.NewKeygenStateWithOptions(id, partyIDs, 2, 0, keygenOpts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	order := party.NewIDSlice(partyIDs)
+	var sent, msgs [][]byte
+	for !states[order[0]].IsFinished() {
+		var out [][]byte
+		for _, id := range order {
+			msgsOut, err := helpers.PartyRoutine(msgs, states[id])
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, msgsOut...)
+		}
+		sent = append(sent, out...)
+		msgs = out
+	}
+	return sent
+}
+
+func TestKeygenDeterministic(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []keygen.Option
+	}{
+		{"plain", nil},
+		{"encrypted", []keygen.Option{keygen.WithEncryptedShares()}},
+		{"echo", []keygen.Option{keygen.WithEchoRound()}},
+		{"blame", []keygen.Option{keygen.WithBlame()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := keygenMessages(t, party.IDSlice{1, 3, 4, 8, 12}, 42, tt.opts...)
+			for _, partyIDs := range []party.IDSlice{{1, 3, 4, 8, 12}, {12, 4, 1, 8, 3}} {
+				msgs := keygenMessages(t, partyIDs, 42, tt.opts...)
+				if !reflect.DeepEqual(msgs, first) {
+					t.Errorf("the messages sent with the parties %v differ", partyIDs)
+				}
+			}
+			if reflect.DeepEqual(keygenMessages(t, party.IDSlice{1, 3, 4, 8, 12}, 43, tt.opts...), first) {
+				t.Error("the messages do not depend on the randomness")
+			}
+		})
+	}
+}
+
+func TestKeygenBlame(t *testing.T) {
+	partyIDs := helpers.GenerateSet(4)
+	T := partyIDs.N() - 1
+
+	for _, opts := range [][]keygen.Option{
+		{keygen.WithBlame(), keygen.WithEncryptedShares()},
+		append([]keygen.Option{keygen.WithBlame()}, keygenEchoOptions...),
+	} {
+		// The party is restored before processing the shares, and before processing the complaints
+		blameRound := 3
+		if len(opts) > 2 {
+			blameRound = 4
+		}
+		for _, restoreRound := range []int{blameRound - 1, blameRound} {
+			states := map[party.ID]*state.State{}
+			outputs := map[party.ID]*keygen.Output{}
+			for _, id := range partyIDs {
+				var err error
+				states[id], outputs[id], err = frost.NewKeygenStateWithOptions(id, partyIDs, T, 0, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := runKeygen(t, partyIDs, states, outputs, partyIDs[2], restoreRound); err != nil {
+				t.Fatal(err)
+			}
+
+			id1 := partyIDs[0]
+			secrets := map[party.ID]*eddsa.SecretShare{}
+			for _, id2 := range partyIDs {
+				if err := states[id2].WaitForError(); err != nil {
+					t.Fatal(err)
+				}
+				secrets[id2] = outputs[id2].SecretKey
+				if err := CompareOutput(outputs[id1].Public.GroupKey, outputs[id2].Public.GroupKey, outputs[id1].Public, outputs[id2].Public); err != nil {
+					t.Error(err)
+				}
+			}
+			if err := ValidateSecrets(secrets, outputs[id1].Public.GroupKey, outputs[id1].Public); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+}
+
+// keygenWithBlame runs a keygen with a blame round between partyIDs, and returns the error of each party.
+// Before they are delivered, the messages sent in each round are given to tamper, which may modify them.
+func keygenWithBlame(t *testing.T, partyIDs party.IDSlice, tamper func(round int, msg *messages.Message)) map[party.ID]error {
+	errs, _ := keygenTampered(t, partyIDs, []keygen.Option{keygen.WithBlame()}, tamper)
+	return errs
+}
+
+// keygenTampered runs a keygen with keygenOpts between partyIDs, and returns the error and the output of each party.
+// Before they are delivered, the messages sent in each round are given to tamper, which may modify them.
+func keygenTampered(t *testing.T, partyIDs party.IDSlice, keygenOpts []keygen.Option, tamper func(round int, msg *messages.Message)) (map[party.ID]error, map[party.ID]*keygen.Output) {
+	T := partyIDs.N() - 1
+
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*keygen.Output{}
+	for _, id := range partyIDs {
+		var err error
+		states[id], outputs[id], err = frost.NewKeygenStateWithOptions(id, partyIDs, T, 0, keygenOpts)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	errs := map[party.ID]error{}
+	var msgs [][]byte
+	for round := 0; round < 5; round++ {
+		var out [][]byte
+		for _, id := range partyIDs {
+			if states[id].IsFinished() {
+				continue
+			}
+			msgsOut, err := helpers.PartyRoutine(msgs, states[id])
+			if err != nil {
+				errs[id] = states[id].WaitForError()
+				continue
+			}
+			out = append(out, msgsOut...)
+		}
+		for i, data := range out {
+			var msg messages.Message
+			if err := msg.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			tamper(round, &msg)
+			var err error
+			if out[i], err = msg.MarshalBinary(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		msgs = out
+	}
+	for _, id := range partyIDs {
+		if !states[id].IsFinished() {
+			t.Fatalf("party %d did not finish", id)
+		}
+	}
+	return errs, outputs
+}
+
+func TestKeygenBlameInvalidShare(t *testing.T) {
+	partyIDs := helpers.GenerateSet(4)
+	victim, culprit := partyIDs[0], partyIDs[2]
+
+	commitments := map[party.ID]*messages.KeyGen1{}
+	var validShare ristretto.Scalar
+	errs := keygenWithBlame(t, partyIDs, func(round int, msg *messages.Message) {
+		switch {
+		case round == 0:
+			commitments[msg.From] = msg.KeyGen1
+		case round == 1 && msg.From == culprit && msg.To == victim:
+			validShare.Set(&msg.KeyGen2.Share)
+			msg.KeyGen2.Share.Add(&msg.KeyGen2.Share, victim.Scalar())
+		}
+	})
+
+	// Every party reaches the same verdict, including the parties which only saw the complaint of the victim
+	for _, id := range partyIDs {
+		err := errs[id]
+		var protocolErr *state.Error
+		if !errors.As(err, &protocolErr) {
+			t.Fatalf("party %d: expected a *state.Error, got %v", id, err)
+		}
+		if !protocolErr.Culprits().Equal(party.IDSlice{culprit}) {
+			t.Errorf("party %d: expected culprit %d, got %v", id, culprit, protocolErr.Culprits())
+		}
+		if protocolErr.Kind() != state.KindVSSFailure {
+			t.Errorf("party %d: expected kind %v, got %v", id, state.KindVSSFailure, protocolErr.Kind())
+		}
+		if !errors.Is(err, keygen.ErrValidateShare) {
+			t.Errorf("party %d: expected ErrValidateShare, got %v", id, err)
+		}
+		var blameErr *keygen.BlameError
+		if !errors.As(err, &blameErr) {
+			t.Fatalf("party %d: expected a *keygen.BlameError, got %v", id, err)
+		}
+		blame := blameErr.Blame
+		if blame.Accuser != victim || blame.Accused != culprit {
+			t.Errorf("party %d: expected party %d to accuse party %d, got %d and %d", id, victim, culprit, blame.Accuser, blame.Accused)
+		}
+		if !blame.Verify(commitments[culprit].Commitments) {
+			t.Errorf("party %d: the blame does not verify with the commitments of the culprit", id)
+		}
+		valid := keygen.Blame{Accuser: victim, Accused: culprit, Share: validShare}
+		if valid.Verify(commitments[culprit].Commitments) {
+			t.Errorf("party %d: the blame verifies with the share which was sent before tampering", id)
+		}
+	}
+}
+
+func TestKeygenBlameFalseAccusation(t *testing.T) {
+	partyIDs := helpers.GenerateSet(4)
+	accuser, accused := partyIDs[1], partyIDs[3]
+
+	var share ristretto.Scalar
+	errs := keygenWithBlame(t, partyIDs, func(round int, msg *messages.Message) {
+		switch {
+		case round == 1 && msg.From == accused && msg.To == accuser:
+			share.Set(&msg.KeyGen2.Share)
+		case round == 2 && msg.From == accuser:
+			// The accuser reveals the valid share it received
+			msg.KeyGenBlame.Complaints = []messages.Complaint{{Accused: accused, Share: share}}
+		}
+	})
+
+	for _, id := range partyIDs {
+		err := errs[id]
+		if id == accuser {
+			if err != nil {
+				t.Errorf("the accuser does not receive its own complaint, but got %v", err)
+			}
+			continue
+		}
+		var protocolErr *state.Error
+		if !errors.As(err, &protocolErr) {
+			t.Fatalf("party %d: expected a *state.Error, got %v", id, err)
+		}
+		if !protocolErr.Culprits().Equal(party.IDSlice{accuser}) {
+			t.Errorf("party %d: expected culprit %d, got %v", id, accuser, protocolErr.Culprits())
+		}
+		if protocolErr.Kind() != state.KindFalseAccusation {
+			t.Errorf("party %d: expected kind %v, got %v", id, state.KindFalseAccusation, protocolErr.Kind())
+		}
+		if !errors.Is(err, keygen.ErrFalseAccusation) {
+			t.Errorf("party %d: expected ErrFalseAccusation, got %v", id, err)
+		}
+	}
+}
+
+func TestKeygenCommitments(t *testing.T) {
+	partyIDs := helpers.GenerateSet(4)
+	T := partyIDs.N() - 2
+	// Only the first parties export the commitments, which does not change the messages
+	exporting := partyIDs[:2]
+
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*keygen.Output{}
+	for _, id := range partyIDs {
+		var opts []keygen.Option
+		if exporting.Contains(id) {
+			opts = append(opts, keygen.WithCommitments())
+		}
+		var err error
+		states[id], outputs[id], err = frost.NewKeygenStateWithOptions(id, partyIDs, T, 0, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first party is restored before processing the shares
+	if err := runKeygen(t, partyIDs, states, outputs, partyIDs[0], 2); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range partyIDs {
+		if err := states[id].WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+		commitments := outputs[id].Commitments
+		if !exporting.Contains(id) {
+			if commitments != nil {
+				t.Errorf("party %d: the commitments were exported without WithCommitments", id)
+			}
+			continue
+		}
+		if commitments == nil {
+			t.Fatalf("party %d: the commitments were not exported", id)
+		}
+
+		// An auditor only has the encoded commitments
+		data, err := commitments.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded eddsa.Commitments
+		if err = decoded.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		public, err := eddsa.PublicFromCommitments(&decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !public.Equal(outputs[id].Public) {
+			t.Errorf("party %d: the Public computed from the commitments differs from the output", id)
+		}
+		if err = outputs[partyIDs[3]].Public.VerifyCommitments(&decoded); err != nil {
+			t.Errorf("party %d: the commitments do not match the output of another party: %v", id, err)
+		}
+
+		// The copy given by the typed output is not affected by the reset of the round
+		copied, err := frost.NewKeygenOutput(states[id], outputs[id]).WaitFor(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = outputs[id].Public.VerifyCommitments(copied.Commitments); err != nil {
+			t.Errorf("party %d: the copied commitments do not match the output: %v", id, err)
+		}
+	}
+}
+
+// keygenWithContexts starts a keygen in which each party uses the context given by contexts,
+// and returns the states and the KeyGen1 messages of the first round.
+func keygenWithContexts(t *testing.T, partyIDs party.IDSlice, contexts map[party.ID]string) (map[party.ID]*state.State, map[party.ID][]byte) {
+	states := map[party.ID]*state.State{}
+	msgs := map[party.ID][]byte{}
+	for _, id := range partyIDs {
+		var err error
+		opts := []keygen.Option{keygen.WithContext([]byte(contexts[id]))}
+		if states[id], _, err = frost.NewKeygenStateWithOptions(id, partyIDs, 1, 0, opts); err != nil {
+			t.Fatal(err)
+		}
+		out, err := helpers.PartyRoutine(nil, states[id])
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgs[id] = out[0]
+	}
+	return states, msgs
+}
+
+// checkInvalidProof checks that err blames culprit for an invalid proof of knowledge.
+func checkInvalidProof(t *testing.T, err error, culprit party.ID) {
+	var protocolErr *state.Error
+	if !errors.As(err, &protocolErr) {
+		t.Fatalf("expected a *state.Error, got %v", err)
+	}
+	if !protocolErr.Culprits().Equal(party.IDSlice{culprit}) {
+		t.Errorf("expected culprit %d, got %v", culprit, protocolErr.Culprits())
+	}
+	if protocolErr.Kind() != state.KindInvalidProof || !errors.Is(err, keygen.ErrValidateProof) {
+		t.Errorf("expected an invalid proof, got %v", err)
+	}
+}
+
+func TestKeygenContextReplay(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	replayer := party.ID(3)
+
+	// The replayer records its KeyGen1 message in a first ceremony, and sends it again in a second one
+	_, recorded := keygenWithContexts(t, partyIDs, map[party.ID]string{1: "ceremony 1", 2: "ceremony 1", 3: "ceremony 1"})
+	states, msgs := keygenWithContexts(t, partyIDs, map[party.ID]string{1: "ceremony 2", 2: "ceremony 2", 3: "ceremony 2"})
+	msgs[replayer] = recorded[replayer]
+
+	for _, id := range partyIDs[:2] {
+		var in [][]byte
+		for from, data := range msgs {
+			if from != id {
+				in = append(in, data)
+			}
+		}
+		if _, err := helpers.PartyRoutine(in, states[id]); err == nil {
+			t.Fatalf("party %d: expected the protocol to abort", id)
+		}
+		checkInvalidProof(t, states[id].WaitForError(), replayer)
+	}
+}
+
+func TestKeygenContextMismatch(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	// The session ID is the same, but the first party was told about another ceremony
+	states, msgs := keygenWithContexts(t, partyIDs, map[party.ID]string{1: "ceremony 1", 2: "ceremony 2", 3: "ceremony 2"})
+
+	if _, err := helpers.PartyRoutine([][]byte{msgs[1], msgs[3]}, states[2]); err == nil {
+		t.Fatal("expected the protocol to abort")
+	}
+	checkInvalidProof(t, states[2].WaitForError(), 1)
+}
+
+func TestKeygenContext(t *testing.T) {
+	partyIDs := helpers.GenerateSet(3)
+	opts := []keygen.Option{keygen.WithContext([]byte("ceremony"))}
+
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*keygen.Output{}
+	for _, id := range partyIDs {
+		var err error
+		if states[id], outputs[id], err = frost.NewKeygenStateWithOptions(id, partyIDs, 1, 0, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The restored party must verify the proofs with the context of the snapshot
+	if err := runKeygen(t, partyIDs, states, outputs, partyIDs[1], 1); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range partyIDs {
+		if err := states[id].WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestKeygenConfirmation(t *testing.T) {
+	partyIDs := helpers.GenerateSet(4)
+	T := partyIDs.N() - 1
+
+	for _, opts := range [][]keygen.Option{
+		{keygen.WithConfirmation()},
+		append([]keygen.Option{keygen.WithConfirmation(), keygen.WithBlame()}, keygenEchoOptions...),
+	} {
+		// The party is restored before processing the shares, and before processing the digests
+		confirmRound := 3
+		if len(opts) > 1 {
+			confirmRound = 5
+		}
+		for _, restoreRound := range []int{confirmRound - 1, confirmRound} {
+			states := map[party.ID]*state.State{}
+			outputs := map[party.ID]*keygen.Output{}
+			for _, id := range partyIDs {
+				var err error
+				states[id], outputs[id], err = frost.NewKeygenStateWithOptions(id, partyIDs, T, 0, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := runKeygen(t, partyIDs, states, outputs, partyIDs[3], restoreRound); err != nil {
+				t.Fatal(err)
+			}
+
+			id1 := partyIDs[0]
+			secrets := map[party.ID]*eddsa.SecretShare{}
+			for _, id2 := range partyIDs {
+				if err := states[id2].WaitForError(); err != nil {
+					t.Fatal(err)
+				}
+				secrets[id2] = outputs[id2].SecretKey
+				if err := CompareOutput(outputs[id1].Public.GroupKey, outputs[id2].Public.GroupKey, outputs[id1].Public, outputs[id2].Public); err != nil {
+					t.Error(err)
+				}
+			}
+			if err := ValidateSecrets(secrets, outputs[id1].Public.GroupKey, outputs[id1].Public); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+}
+
+func TestKeygenConfirmationMismatch(t *testing.T) {
+	partyIDs := helpers.GenerateSet(4)
+	// The digests of these parties are modified in transit, as if they had computed another group key
+	disagreeing := party.IDSlice{partyIDs[1], partyIDs[3]}
+
+	errs, outputs := keygenTampered(t, partyIDs, []keygen.Option{keygen.WithConfirmation()}, func(_ int, msg *messages.Message) {
+		if msg.Type == messages.MessageTypeKeyGenConfirm && disagreeing.Contains(msg.From) {
+			msg.KeyGenConfirm.Digest[0] ^= 1
+		}
+	})
+
+	for _, id := range partyIDs {
+		err := errs[id]
+		if !errors.Is(err, keygen.ErrConfirmMismatch) {
+			t.Fatalf("party %d: expected ErrConfirmMismatch, got %v", id, err)
+		}
+		var protocolErr *state.Error
+		if !errors.As(err, &protocolErr) {
+			t.Fatalf("party %d: expected a *state.Error, got %v", id, err)
+		}
+		if protocolErr.Kind() != state.KindInconsistentBroadcast {
+			t.Errorf("party %d: expected kind %v, got %v", id, state.KindInconsistentBroadcast, protocolErr.Kind())
+		}
+		if protocolErr.RoundNumber() != 3 {
+			t.Errorf("party %d: expected round 3, got %d", id, protocolErr.RoundNumber())
+		}
+		// All parties whose digest differs are named, except ourselves
+		var expected party.IDSlice
+		for _, other := range disagreeing {
+			if other != id {
+				expected = append(expected, other)
+			}
+		}
+		if !protocolErr.Culprits().Equal(expected) {
+			t.Errorf("party %d: expected culprits %v, got %v", id, expected, protocolErr.Culprits())
+		}
+		if outputs[id].Public != nil || outputs[id].SecretKey != nil {
+			t.Errorf("party %d: the output was set although the protocol aborted", id)
+		}
+	}
+}
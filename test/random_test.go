@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	mathrand "math/rand"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/keygen"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/sign"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// goldenTranscript is the SHA-256 digest of the messages and signature produced by randomTranscript.
+const goldenTranscript = "c3b93f368ded68d50fa718834c6c1f891c5b83a1f6d9dc2b36cfeb7003a29282"
+
+// seededReader returns a deterministic randomness source for party id.
+func seededReader(id party.ID, offset int64) io.Reader {
+	return mathrand.New(mathrand.NewSource(int64(id) + offset))
+}
+
+// seededSignOptions returns the options of a signer whose nonces are read from a seededReader.
+// Since the same nonces are sampled at every run, each session gets its own NonceGuard,
+// as the DefaultNonceGuard would refuse them after the first run.
+func seededSignOptions(t *testing.T, id party.ID) []sign.Option {
+	guard, err := sign.NewNonceGuard(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []sign.Option{sign.WithRandom(seededReader(id, 100)), sign.WithNonceGuard(guard)}
+}
+
+// runToCompletion runs the protocol between states until they are finished, and returns all messages sent.
+func runToCompletion(t *testing.T, partyIDs party.IDSlice, states map[party.ID]*state.State) [][]byte {
+	var sent, msgs [][]byte
+	for !states[partyIDs[0]].IsFinished() {
+		var out [][]byte
+		for _, id := range partyIDs {
+			msgsOut, err := helpers.PartyRoutine(msgs, states[id])
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, msgsOut...)
+		}
+		sent = append(sent, out...)
+		msgs = out
+	}
+	return sent
+}
+
+// randomTranscript runs a keygen with encrypted shares and a signature, in which every party reads its
+// randomness from a seeded reader, and returns the messages exchanged followed by the signature.
+func randomTranscript(t *testing.T) []byte {
+	partyIDs := party.IDSlice{1, 2, 3}
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*keygen.Output{}
+	for _, id := range partyIDs {
+		var err error
+		opts := []keygen.Option{keygen.WithEncryptedShares(), keygen.WithRandom(seededReader(id, 0))}
+		if states[id], outputs[id], err = frost.NewKeygenStateWithOptions(id, partyIDs, 1, 0, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	transcript := bytes.Join(runToCompletion(t, partyIDs, states), nil)
+
+	signers := party.IDSlice{1, 3}
+	signStates := map[party.ID]*state.State{}
+	signOutputs := map[party.ID]*sign.Output{}
+	for _, id := range signers {
+		var err error
+		opts := seededSignOptions(t, id)
+		if signStates[id], signOutputs[id], err = frost.NewSignStateWithOptions(signers, outputs[id].SecretKey, outputs[id].Public, []byte(MESSAGE), 0, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	transcript = append(transcript, bytes.Join(runToCompletion(t, signers, signStates), nil)...)
+
+	signature := signOutputs[signers[0]].Signature
+	if !outputs[1].Public.GroupKey.Verify([]byte(MESSAGE), signature) {
+		t.Fatal("the signature is invalid")
+	}
+	return append(transcript, signature.ToEd25519()...)
+}
+
+func TestRandomTranscript(t *testing.T) {
+	first := randomTranscript(t)
+	if !bytes.Equal(randomTranscript(t), first) {
+		t.Fatal("two runs with the same randomness produced different transcripts")
+	}
+	digest := sha256.Sum256(first)
+	if got := hex.EncodeToString(digest[:]); got != goldenTranscript {
+		t.Errorf("transcript digest %s, want %s", got, goldenTranscript)
+	}
+}
+
+// brokenReaders are randomness sources which must make the protocol abort before any secret is used.
+var brokenReaders = []struct {
+	name string
+	r    func() io.Reader
+	err  error
+}{
+	{"empty", func() io.Reader { return bytes.NewReader(nil) }, io.ErrUnexpectedEOF},
+	// 100 bytes are only enough for the first scalar
+	{"short", func() io.Reader { return bytes.NewReader(bytes.Repeat([]byte{1}, 100)) }, io.ErrUnexpectedEOF},
+	// A source of zeros would produce a zero secret or nonce
+	{"zero", func() io.Reader { return bytes.NewReader(make([]byte, 1024)) }, nil},
+	{"stalling", func() io.Reader { return stallingReader{} }, io.ErrNoProgress},
+}
+
+// stallingReader returns no data and no error.
+type stallingReader struct{}
+
+func (stallingReader) Read([]byte) (int, error) { return 0, nil }
+
+// checkLocalAbort checks that s aborted with an error whose culprit is 0 and which wraps target, without sending msgs.
+func checkLocalAbort(t *testing.T, s *state.State, msgs [][]byte, target error) {
+	if len(msgs) != 0 {
+		t.Errorf("%d messages were sent", len(msgs))
+	}
+	err := s.WaitForError()
+	var protocolErr *state.Error
+	if !errors.As(err, &protocolErr) {
+		t.Fatalf("expected a *state.Error, got %v", err)
+	}
+	if protocolErr.Culprit() != 0 {
+		t.Errorf("expected a local error, got culprit %d", protocolErr.Culprit())
+	}
+	if target != nil && !errors.Is(err, target) {
+		t.Errorf("expected %v, got %v", target, err)
+	}
+}
+
+func TestKeygenBrokenRandom(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	for _, tt := range brokenReaders {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []keygen.Option{keygen.WithEncryptedShares(), keygen.WithRandom(tt.r())}
+			s, _, err := frost.NewKeygenStateWithOptions(1, partyIDs, 2, 0, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			msgs, err := helpers.PartyRoutine(nil, s)
+			if err == nil {
+				t.Fatal("expected the protocol to abort")
+			}
+			checkLocalAbort(t, s, msgs, tt.err)
+		})
+	}
+}
+
+func TestSignBrokenRandom(t *testing.T) {
+	partyIDs := helpers.GenerateSet(3)
+	_, secrets := helpers.GenerateSecrets(partyIDs, 1)
+	public := helpers.GeneratePublic(1, secrets)
+	for _, tt := range brokenReaders {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []sign.Option{sign.WithRandom(tt.r())}
+			id := partyIDs[0]
+			s, output, err := frost.NewSignStateWithOptions(partyIDs, secrets[id], public, []byte(MESSAGE), 0, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			msgs, err := helpers.PartyRoutine(nil, s)
+			if err == nil {
+				t.Fatal("expected the protocol to abort")
+			}
+			checkLocalAbort(t, s, msgs, tt.err)
+			if output.Signature != nil {
+				t.Error("a signature was output")
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/keygen"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// robustTimeout is the round timeout after which the missing messages of a robust keygen are given up on.
+const robustTimeout = 100 * time.Millisecond
+
+// robustKeygen runs a keygen with keygenOpts and a round timeout between partyIDs,
+// in which the parties in dropped stop responding after sending their KeyGen1 message.
+// It returns the states and outputs of the other parties once they have finished.
+func robustKeygen(t *testing.T, partyIDs party.IDSlice, threshold party.Size, dropped party.IDSlice, keygenOpts []keygen.Option) (map[party.ID]*state.State, map[party.ID]*keygen.Output) {
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*keygen.Output{}
+	for _, id := range partyIDs {
+		var err error
+		if states[id], outputs[id], err = frost.NewKeygenStateWithOptions(id, partyIDs, threshold, robustTimeout, keygenOpts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Messages are delivered encoded, since the rounds keep references to the ones they send
+	encode := func(msgs []*messages.Message) [][]byte {
+		out := make([][]byte, 0, len(msgs))
+		for _, msg := range msgs {
+			data, err := msg.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, data)
+		}
+		return out
+	}
+
+	var msgs [][]byte
+	for _, id := range partyIDs {
+		msgs = append(msgs, encode(states[id].ProcessAll())...)
+	}
+	for _, id := range dropped {
+		states[id].Cancel(nil)
+		delete(states, id)
+		delete(outputs, id)
+	}
+
+	for {
+		var running []*state.State
+		for _, id := range partyIDs {
+			if s, ok := states[id]; ok && !s.IsFinished() {
+				running = append(running, s)
+			}
+		}
+		if len(running) == 0 {
+			break
+		}
+		for _, s := range running {
+			for _, data := range msgs {
+				var msg messages.Message
+				if err := msg.UnmarshalBinary(data); err != nil {
+					t.Fatal(err)
+				}
+				if err := s.HandleMessage(&msg); err != nil && !errors.Is(err, state.ErrWrongRecipient) {
+					t.Fatal(err)
+				}
+			}
+		}
+		var out [][]byte
+		for _, s := range running {
+			out = append(out, encode(s.ProcessAll())...)
+		}
+		if len(out) == 0 {
+			// All parties are waiting for the dropped parties, until the round times out
+			for _, s := range running {
+				for s.RoundState() == state.RoundStateWaitingForMessages {
+					<-s.Notify()
+				}
+				out = append(out, encode(s.ProcessAll())...)
+			}
+		}
+		msgs = out
+	}
+	return states, outputs
+}
+
+func TestKeygenRobust(t *testing.T) {
+	partyIDs := helpers.GenerateSet(5)
+	dropped := partyIDs[2]
+
+	for _, opts := range [][]keygen.Option{
+		{keygen.WithRobust(), keygen.WithConfirmation()},
+		{keygen.WithRobust(), keygen.WithConfirmation(), keygen.WithEncryptedShares()},
+	} {
+		states, outputs := robustKeygen(t, partyIDs, 1, party.IDSlice{dropped}, opts)
+
+		qualified := partyIDs.Copy()
+		qualified = append(qualified[:2], qualified[3:]...)
+		secrets := map[party.ID]*eddsa.SecretShare{}
+		var public *eddsa.Public
+		for _, id := range qualified {
+			if err := states[id].WaitForError(); err != nil {
+				t.Fatalf("party %d: %v", id, err)
+			}
+			out := outputs[id]
+			if !out.Public.PartyIDs.Equal(qualified) {
+				t.Errorf("party %d: expected the qualified parties %v, got %v", id, qualified, out.Public.PartyIDs)
+			}
+			if !out.Excluded.Equal(party.IDSlice{dropped}) {
+				t.Errorf("party %d: expected party %d to be excluded, got %v", id, dropped, out.Excluded)
+			}
+			if _, ok := out.Public.Shares[dropped]; ok {
+				t.Errorf("party %d: the public shares contain the excluded party", id)
+			}
+			if public == nil {
+				public = out.Public
+			}
+			if err := CompareOutput(public.GroupKey, out.Public.GroupKey, public, out.Public); err != nil {
+				t.Error(err)
+			}
+			secrets[id] = out.SecretKey
+		}
+		if err := ValidateSecrets(secrets, public.GroupKey, public); err != nil {
+			t.Fatal(err)
+		}
+
+		// The key is a 2-of-4 key between the qualified parties
+		signers := party.IDSlice{qualified[1], qualified[3]}
+		signer := frost.NewSigner(public.GroupKey, localSignFunc(signers, secrets, public, -1, nil))
+		sig, err := signer.Sign(nil, MESSAGE, crypto.Hash(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ed25519.Verify(public.GroupKey.ToEd25519(), MESSAGE, sig) {
+			t.Error("the signature of the qualified parties is not valid")
+		}
+	}
+}
+
+func TestKeygenRobustSnapshot(t *testing.T) {
+	partyIDs := helpers.GenerateSet(4)
+	opts := []keygen.Option{keygen.WithRobust(), keygen.WithConfirmation()}
+
+	// The party is restored before processing the complaints, and before processing the confirmations
+	for _, restoreRound := range []int{3, 4} {
+		states := map[party.ID]*state.State{}
+		outputs := map[party.ID]*keygen.Output{}
+		for _, id := range partyIDs {
+			var err error
+			if states[id], outputs[id], err = frost.NewKeygenStateWithOptions(id, partyIDs, 2, 0, opts); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := runKeygen(t, partyIDs, states, outputs, partyIDs[1], restoreRound); err != nil {
+			t.Fatal(err)
+		}
+		for _, id := range partyIDs {
+			if err := states[id].WaitForError(); err != nil {
+				t.Fatal(err)
+			}
+			if !outputs[id].Public.PartyIDs.Equal(partyIDs) || len(outputs[id].Excluded) != 0 {
+				t.Errorf("party %d: no party should be excluded, got %v", id, outputs[id].Excluded)
+			}
+		}
+	}
+}
+
+func TestKeygenRobustTooManyDropouts(t *testing.T) {
+	partyIDs := helpers.GenerateSet(5)
+	// Only 2 parties remain, which is not more than the threshold
+	dropped := party.IDSlice{partyIDs[0], partyIDs[2], partyIDs[3]}
+
+	states, _ := robustKeygen(t, partyIDs, 2, dropped, []keygen.Option{keygen.WithRobust(), keygen.WithConfirmation()})
+	for id, s := range states {
+		err := s.WaitForError()
+		if !errors.Is(err, state.ErrRoundTimeout) {
+			t.Fatalf("party %d: expected a timeout, got %v", id, err)
+		}
+		var timeoutErr *state.TimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Fatalf("party %d: expected a *state.TimeoutError, got %v", id, err)
+		}
+		if !timeoutErr.Missing.Equal(dropped) {
+			t.Errorf("party %d: expected the missing parties %v, got %v", id, dropped, timeoutErr.Missing)
+		}
+	}
+}
+
+func TestKeygenRobustOptions(t *testing.T) {
+	partyIDs := helpers.GenerateSet(3)
+	if _, _, err := keygen.NewRound(partyIDs[0], partyIDs, 1, keygen.WithRobust(), keygen.WithConfirmation(), keygen.WithBlame()); err == nil {
+		t.Error("a robust keygen with a blame round should be rejected")
+	}
+	if _, _, err := keygen.NewRound(partyIDs[0], partyIDs, 1, keygen.WithRobust()); err == nil {
+		t.Error("a robust keygen without confirmation round should be rejected")
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+)
+
+// TestShamirImport splits a crypto/ed25519 key with a plain Shamir secret sharing of its secret scalar,
+// imports the shares, and signs with a subset of the parties.
+func TestShamirImport(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3, 4, 5}
+	const threshold = 2
+
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		t.Fatal(err)
+	}
+	publicKey := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+
+	// The secret scalar is the clamped first half of SHA-512(seed), and not the seed.
+	digest := sha512.Sum512(seed)
+	secret, err := edwards25519.NewScalar().SetBytesWithClamping(digest[:32])
+	if err != nil {
+		t.Fatal(err)
+	}
+	coefficients := []*edwards25519.Scalar{secret}
+	for i := 0; i < threshold; i++ {
+		var random [64]byte
+		_, _ = rand.Read(random[:])
+		c, _ := edwards25519.NewScalar().SetUniformBytes(random[:])
+		coefficients = append(coefficients, c)
+	}
+	shares := map[party.ID]*ristretto.Scalar{}
+	for _, id := range partyIDs {
+		x := id.Scalar()
+		share := edwards25519.NewScalar()
+		for i := len(coefficients) - 1; i >= 0; i-- {
+			share.MultiplyAdd(share, x, coefficients[i])
+		}
+		shares[id] = share
+	}
+
+	// Only the public shares of threshold+1 parties were published by the tool
+	known := map[party.ID]ed25519.PublicKey{}
+	for _, id := range partyIDs[:threshold+1] {
+		known[id] = new(edwards25519.Point).ScalarBaseMult(shares[id]).Bytes()
+	}
+	publicShares, err := eddsa.InterpolatePublicShares(known, partyIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secrets := map[party.ID]*eddsa.SecretShare{}
+	var public *eddsa.Public
+	for _, id := range partyIDs {
+		key, err := eddsa.NewKeyShareFromShamir(id, shares[id], publicShares, threshold, publicKey)
+		if err != nil {
+			t.Fatalf("party %d: %v", id, err)
+		}
+		secrets[id], public = key.Secret, key.Public
+	}
+
+	signers := party.IDSlice{2, 4, 5}
+	signer := frost.NewSigner(public.GroupKey, localSignFunc(signers, secrets, public, -1, nil))
+	sig, err := signer.Sign(nil, MESSAGE, crypto.Hash(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(publicKey, MESSAGE, sig) {
+		t.Error("the signature of the imported shares is not valid for the original key")
+	}
+}
+
+// TestSplitEd25519 splits a crypto/ed25519 key as a trusted dealer, and signs with a subset of the parties.
+func TestSplitEd25519(t *testing.T) {
+	publicKey, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secrets, public, err := eddsa.SplitEd25519(key, party.IDSlice{1, 2, 3, 4, 5}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signers := party.IDSlice{1, 3, 4}
+	signer := frost.NewSigner(public.GroupKey, localSignFunc(signers, secrets, public, -1, nil))
+	sig, err := signer.Sign(nil, MESSAGE, crypto.Hash(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(publicKey, MESSAGE, sig) {
+		t.Error("the signature of the split key is not valid for the original key")
+	}
+}
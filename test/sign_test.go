@@ -2,15 +2,23 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
 	"github.com/taurusgroup/frost-ed25519/pkg/frost"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/keygen"
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/sign"
 	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
 	"github.com/taurusgroup/frost-ed25519/pkg/state"
 )
 
@@ -97,5 +105,729 @@ func TestSign(t *testing.T) {
 		if !bytes.Equal(sigBytes, comparedSigBytes) {
 			t.Error("sigs not the same")
 		}
+
+		// Every signature must be accepted by crypto/ed25519
+		if !ed25519.Verify(publicShares.GroupKeyEd25519(), MESSAGE, comparedSig.ToEd25519()) {
+			t.Errorf("party %d: signature rejected by ed25519.Verify", id)
+		}
+		if !eddsa.Verify(publicShares.GroupKeyEd25519(), MESSAGE, comparedSig) {
+			t.Errorf("party %d: signature rejected by eddsa.Verify", id)
+		}
+	}
+}
+
+func TestSignCancelRound2(t *testing.T) {
+	N := party.Size(3)
+	T := N - 1
+
+	_, signSet, secretShares, publicShares := setupParties(T, N)
+
+	states := map[party.ID]*state.State{}
+	for _, id := range signSet {
+		var err error
+		states[id], _, err = frost.NewSignState(signSet, secretShares[id], publicShares, MESSAGE, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msgsOut1 := make([][]byte, 0, N)
+	for _, s := range states {
+		msgs1, err := helpers.PartyRoutine(nil, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgsOut1 = append(msgsOut1, msgs1...)
+	}
+	msgsOut2 := make([][]byte, 0, N)
+	for _, s := range states {
+		msgs2, err := helpers.PartyRoutine(msgsOut1, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgsOut2 = append(msgsOut2, msgs2...)
+	}
+
+	// All parties are now waiting for the round 2 messages
+	s := states[signSet[0]]
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() { errs <- s.WaitForErrorContext(ctx) }()
+	}
+	cancel()
+	for i := 0; i < 2; i++ {
+		if err := <-errs; !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	}
+
+	if !s.IsFinished() {
+		t.Error("state should be aborted")
+	}
+	if err := s.WaitForError(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if _, err := helpers.PartyRoutine(msgsOut2, s); err == nil {
+		t.Error("handling messages after cancellation should fail")
+	}
+}
+
+func TestSignSelectDone(t *testing.T) {
+	N := party.Size(5)
+	T := party.Size(2)
+
+	_, signSet, secretShares, publicShares := setupParties(T, N)
+	messages := [][]byte{[]byte("first"), []byte("second")}
+
+	sessions := make([]map[party.ID]*state.State, len(messages))
+	outputs := make([]*sign.Output, len(messages))
+	for i, m := range messages {
+		sessions[i] = map[party.ID]*state.State{}
+		for _, id := range signSet {
+			s, out, err := frost.NewSignState(signSet, secretShares[id], publicShares, m, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sessions[i][id] = s
+			if id == signSet[0] {
+				outputs[i] = out
+			}
+		}
+	}
+
+	for _, states := range sessions {
+		go func(states map[party.ID]*state.State) {
+			var in [][]byte
+			for round := 0; round < 3; round++ {
+				out := make([][]byte, 0, len(states))
+				for _, s := range states {
+					msgs, _ := helpers.PartyRoutine(in, s)
+					out = append(out, msgs...)
+					// Concurrent accesses should be safe
+					_ = s.Err()
+				}
+				in = out
+			}
+		}(states)
+	}
+
+	done0 := sessions[0][signSet[0]].Done()
+	done1 := sessions[1][signSet[0]].Done()
+	for harvested := 0; harvested < len(messages); harvested++ {
+		var i int
+		select {
+		case <-done0:
+			i, done0 = 0, nil
+		case <-done1:
+			i, done1 = 1, nil
+		case <-time.After(10 * time.Second):
+			t.Fatal("sessions did not finish")
+		}
+		s := sessions[i][signSet[0]]
+		if err := s.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if !s.IsFinished() {
+			t.Error("closed Done channel should imply IsFinished")
+		}
+		if !publicShares.GroupKey.Verify(messages[i], outputs[i].Signature) {
+			t.Errorf("signature for session %d is invalid", i)
+		}
+	}
+}
+
+func TestSignSnapshot(t *testing.T) {
+	N := party.Size(3)
+	T := N - 1
+
+	_, signSet, secretShares, publicShares := setupParties(T, N)
+
+	s, _, err := frost.NewSignState(signSet, secretShares[signSet[0]], publicShares, MESSAGE, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.ProcessAll()
+
+	if _, err = s.Snapshot(); !errors.Is(err, sign.ErrSnapshotNonce) {
+		t.Errorf("expected ErrSnapshotNonce, got %v", err)
+	}
+}
+
+func TestSignSessionID(t *testing.T) {
+	N := party.Size(3)
+	T := N - 1
+
+	_, signSet, secretShares, publicShares := setupParties(T, N)
+	nonces := [][]byte{[]byte("first"), []byte("second")}
+
+	sessions := make([]map[party.ID]*state.State, len(nonces))
+	outputs := make([]map[party.ID]*sign.Output, len(nonces))
+	msgsOut1 := make([][][]byte, len(nonces))
+	for i, nonce := range nonces {
+		sessionID := messages.DeriveSessionID(signSet, nonce)
+		sessions[i] = map[party.ID]*state.State{}
+		outputs[i] = map[party.ID]*sign.Output{}
+		for _, id := range signSet {
+			var err error
+			sessions[i][id], outputs[i][id], err = frost.NewSignState(signSet, secretShares[id], publicShares, MESSAGE, 0, state.WithSessionID(sessionID))
+			if err != nil {
+				t.Fatal(err)
+			}
+			msgs1, err := helpers.PartyRoutine(nil, sessions[i][id])
+			if err != nil {
+				t.Fatal(err)
+			}
+			msgsOut1[i] = append(msgsOut1[i], msgs1...)
+		}
+	}
+
+	// Messages replayed from the first session are rejected by the second one, without aborting it
+	for _, data := range msgsOut1[0] {
+		var msg messages.Message
+		if err := msg.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		for id, s := range sessions[1] {
+			if msg.From == id {
+				continue
+			}
+			if err := s.HandleMessage(&msg); !errors.Is(err, state.ErrSessionMismatch) {
+				t.Errorf("expected ErrSessionMismatch, got %v", err)
+			}
+		}
+	}
+
+	in := msgsOut1[1]
+	for round := 1; round < 3; round++ {
+		out := make([][]byte, 0, N)
+		for _, s := range sessions[1] {
+			msgs, err := helpers.PartyRoutine(in, s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, msgs...)
+		}
+		in = out
+	}
+	for id, s := range sessions[1] {
+		if err := s.WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+		if !publicShares.GroupKey.Verify(MESSAGE, outputs[1][id].Signature) {
+			t.Error("signature is invalid")
+		}
+	}
+}
+
+func TestSignConcurrentDelivery(t *testing.T) {
+	N := party.Size(50)
+	T := N - 1
+
+	_, signSet, secretShares, publicShares := setupParties(T, N)
+
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*sign.Output{}
+	for _, id := range signSet {
+		var err error
+		states[id], outputs[id], err = frost.NewSignState(signSet, secretShares[id], publicShares, MESSAGE, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var in []*messages.Message
+	for _, s := range states {
+		in = append(in, s.ProcessAll()...)
+	}
+
+	for round := 1; round < 3; round++ {
+		var (
+			wg  sync.WaitGroup
+			mtx sync.Mutex
+			out []*messages.Message
+		)
+		// Each message is delivered to every party by its own goroutine, which then tries to process the round.
+		processed := map[party.ID]int{}
+		for id, s := range states {
+			for _, msg := range in {
+				if msg.From == id {
+					continue
+				}
+				wg.Add(1)
+				go func(id party.ID, s *state.State, data []byte) {
+					defer wg.Done()
+					var msg messages.Message
+					if err := msg.UnmarshalBinary(data); err != nil {
+						t.Error(err)
+						return
+					}
+					if err := s.HandleMessage(&msg); err != nil {
+						t.Error(err)
+					}
+					if msgs := s.ProcessAll(); msgs != nil {
+						mtx.Lock()
+						defer mtx.Unlock()
+						processed[id]++
+						out = append(out, msgs...)
+					}
+				}(id, s, marshal(t, msg))
+			}
+		}
+		wg.Wait()
+
+		if round == 1 {
+			for _, id := range signSet {
+				if processed[id] != 1 {
+					t.Fatalf("party %d processed round %d %d times", id, round, processed[id])
+				}
+			}
+		}
+		in = out
+	}
+
+	for id, s := range states {
+		if err := s.WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+		if !publicShares.GroupKey.Verify(MESSAGE, outputs[id].Signature) {
+			t.Errorf("party %d produced an invalid signature", id)
+		}
+	}
+}
+
+func marshal(t *testing.T, msg *messages.Message) []byte {
+	data, err := msg.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestSignOutgoing(t *testing.T) {
+	N := party.Size(2)
+	T := N - 1
+
+	_, signSet, secretShares, publicShares := setupParties(T, N)
+
+	states := make([]*state.State, N)
+	outputs := make([]*sign.Output, N)
+	for i, id := range signSet {
+		var err error
+		states[i], outputs[i], err = frost.NewSignState(signSet, secretShares[id], publicShares, MESSAGE, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each party's outgoing messages are given directly to the other party
+	forward := func(from, to *state.State) {
+		for msg := range from.Outgoing() {
+			if err := to.HandleMessage(msg); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+	go forward(states[0], states[1])
+	go forward(states[1], states[0])
+
+	for i, s := range states {
+		select {
+		case <-s.Done():
+		case <-time.After(10 * time.Second):
+			t.Fatal("protocol did not finish")
+		}
+		if err := s.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if !publicShares.GroupKey.Verify(MESSAGE, outputs[i].Signature) {
+			t.Errorf("party %d produced an invalid signature", signSet[i])
+		}
+	}
+}
+
+func TestSignTypedOutput(t *testing.T) {
+	N := party.Size(3)
+	T := N - 1
+
+	_, signSet, secretShares, publicShares := setupParties(T, N)
+
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*state.Output[*eddsa.Signature]{}
+	rawOutputs := map[party.ID]*sign.Output{}
+	for _, id := range signSet {
+		var err error
+		states[id], rawOutputs[id], err = frost.NewSignState(signSet, secretShares[id], publicShares, MESSAGE, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[id] = frost.NewSignOutput(states[id], rawOutputs[id])
+	}
+
+	// Waiting with a cancelled context does not abort the protocol
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := outputs[signSet[0]].WaitFor(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if states[signSet[0]].IsFinished() {
+		t.Error("protocol should not be aborted")
+	}
+
+	var in [][]byte
+	for round := 0; round < 3; round++ {
+		out := make([][]byte, 0, N)
+		for _, s := range states {
+			msgs, err := helpers.PartyRoutine(in, s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, msgs...)
+		}
+		in = out
+	}
+
+	for id, output := range outputs {
+		sig, err := output.WaitFor(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !publicShares.GroupKey.Verify(MESSAGE, sig) {
+			t.Error("signature is invalid")
+		}
+		sig2, err := output.WaitFor(context.Background())
+		if err != nil || sig2 != sig {
+			t.Errorf("WaitFor() should return the same value, got %v, %v", sig2, err)
+		}
+		if sig == rawOutputs[id].Signature {
+			t.Error("WaitFor() should return a copy")
+		}
+	}
+}
+
+func TestSignResend(t *testing.T) {
+	N := party.Size(3)
+	T := N - 1
+
+	_, signSet, secretShares, publicShares := setupParties(T, N)
+
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*sign.Output{}
+	for _, id := range signSet {
+		var err error
+		states[id], outputs[id], err = frost.NewSignState(signSet, secretShares[id], publicShares, MESSAGE, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deliver := func(msgs []*messages.Message, to party.IDSlice) {
+		t.Helper()
+		for _, msg := range msgs {
+			data := marshal(t, msg)
+			for _, id := range to {
+				var msgCopy messages.Message
+				if err := msgCopy.UnmarshalBinary(data); err != nil {
+					t.Fatal(err)
+				}
+				if err := states[id].HandleMessage(&msgCopy); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+	process := func() []*messages.Message {
+		var out []*messages.Message
+		for _, id := range signSet {
+			out = append(out, states[id].ProcessAll()...)
+		}
+		return out
+	}
+
+	// The last party does not receive the first messages, and is stuck in round 1
+	msgs1 := process()
+	deliver(msgs1, signSet[:N-1])
+	msgs2 := process()
+	if d := states[signSet[N-1]].Diagnostics(); d.RoundNumber != 1 || len(d.Missing) != int(N-1) {
+		t.Fatalf("Diagnostics() = %+v, want round 1 waiting for all other parties", d)
+	}
+
+	// All parties resend their first message, which is only used by the last party
+	var resent []*messages.Message
+	for _, id := range signSet {
+		msgs, err := states[id].ResendMessages(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resent = append(resent, msgs...)
+	}
+	if len(resent) != len(msgs1) {
+		t.Fatalf("ResendMessages returned %d messages, want %d", len(resent), len(msgs1))
+	}
+	for i := range resent {
+		if !bytes.Equal(marshal(t, resent[i]), marshal(t, msgs1[i])) {
+			t.Errorf("resent message %d differs from the original", i)
+		}
+	}
+	deliver(resent, signSet)
+	msgs2 = append(msgs2, process()...)
+
+	deliver(msgs2, signSet)
+	process()
+	for _, id := range signSet {
+		if err := states[id].WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+		if !publicShares.GroupKey.Verify(MESSAGE, outputs[id].Signature) {
+			t.Error("signature is invalid")
+		}
+	}
+
+	// Retransmissions received after the end of the protocol are ignored
+	deliver(msgs2, signSet)
+
+	if _, err := states[signSet[0]].ResendMessages(5); !errors.Is(err, state.ErrNoMessages) {
+		t.Errorf("ResendMessages() error = %v, want ErrNoMessages", err)
+	}
+}
+
+func TestSignCodecs(t *testing.T) {
+	N := party.Size(5)
+	T := N - 1
+
+	_, signSet, secretShares, publicShares := setupParties(T, N)
+
+	codecs := map[string]messages.Codec{
+		"binary": messages.BinaryCodec,
+		"JSON":   messages.JSONCodec,
+		"CBOR":   messages.CBORCodec,
+	}
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			states := map[party.ID]*state.State{}
+			outputs := map[party.ID]*sign.Output{}
+			for _, id := range signSet {
+				var err error
+				states[id], outputs[id], err = frost.NewSignState(signSet, secretShares[id], publicShares, MESSAGE, 0, state.WithCodec(codec))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var in [][]byte
+			for round := 0; round < 3; round++ {
+				var out [][]byte
+				for _, s := range states {
+					msgs, err := helpers.PartyRoutine(in, s)
+					if err != nil {
+						t.Fatal(err)
+					}
+					out = append(out, msgs...)
+				}
+				in = out
+			}
+			for id, s := range states {
+				if err := s.WaitForError(); err != nil {
+					t.Fatal(err)
+				}
+				if !publicShares.GroupKey.Verify(MESSAGE, outputs[id].Signature) {
+					t.Error("signature is invalid")
+				}
+			}
+		})
+	}
+}
+
+func TestSignSampledSigners(t *testing.T) {
+	T, N := party.Size(2), party.Size(7)
+	partyIDs, _, secretShares, publicShares := setupParties(T, N)
+
+	for i := 0; i < 5; i++ {
+		signers, err := partyIDs.Sample(int(T)+1, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		states := map[party.ID]*state.State{}
+		outputs := map[party.ID]*sign.Output{}
+		for _, id := range signers {
+			if states[id], outputs[id], err = frost.NewSignState(signers, secretShares[id], publicShares, MESSAGE, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+		runRounds(t, signers, states, 3)
+		if !publicShares.GroupKey.Verify(MESSAGE, outputs[signers[0]].Signature) {
+			t.Errorf("the signature of %v is invalid", signers)
+		}
+	}
+}
+
+func TestKeygenSignMaxID(t *testing.T) {
+	partyIDs := party.IDSlice{1, 1 << 16, party.MaxID - 1, party.MaxID}
+	T := party.Size(2)
+
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*keygen.Output{}
+	for _, id := range partyIDs {
+		var err error
+		if states[id], outputs[id], err = frost.NewKeygenStateWithOptions(id, partyIDs, T, 0, []keygen.Option{keygen.WithEncryptedShares()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runRounds(t, partyIDs, states, 3)
+	public := outputs[partyIDs[0]].Public
+	if !public.PartyIDs.Equal(partyIDs) {
+		t.Fatalf("the public shares belong to %v", public.PartyIDs)
+	}
+
+	signers := partyIDs[1:]
+	signStates := map[party.ID]*state.State{}
+	signOutputs := map[party.ID]*sign.Output{}
+	for _, id := range signers {
+		var err error
+		if signStates[id], signOutputs[id], err = frost.NewSignState(signers, outputs[id].SecretKey, public, MESSAGE, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runRounds(t, signers, signStates, 3)
+	if !ed25519.Verify(public.GroupKey.ToEd25519(), MESSAGE, signOutputs[party.MaxID].Signature.ToEd25519()) {
+		t.Error("the signature is invalid")
+	}
+}
+
+func TestSignDerived(t *testing.T) {
+	T, N := party.Size(2), party.Size(5)
+	partyIDs, _, secretShares, publicShares := setupParties(T, N)
+
+	path := []uint32{44, 0, 3}
+	derivedPublic, err := publicShares.Derive(path...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signers := party.IDSlice{partyIDs[0], partyIDs[2], partyIDs[4]}
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*sign.Output{}
+	for _, id := range signers {
+		derivedSecret, err := secretShares[id].Derive(publicShares.GroupKey, path...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if states[id], outputs[id], err = frost.NewSignState(signers, derivedSecret, derivedPublic, MESSAGE, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runRounds(t, signers, states, 3)
+
+	sig := outputs[signers[0]].Signature.ToEd25519()
+	if !ed25519.Verify(derivedPublic.GroupKeyEd25519(), MESSAGE, sig) {
+		t.Error("the signature is invalid for the derived key")
+	}
+	if ed25519.Verify(publicShares.GroupKeyEd25519(), MESSAGE, sig) {
+		t.Error("the signature should not be valid for the parent key")
+	}
+
+	if _, err = publicShares.Derive(eddsa.HardenedIndex); !errors.Is(err, eddsa.ErrHardenedDerivation) {
+		t.Errorf("Derive() error = %v, want ErrHardenedDerivation", err)
+	}
+}
+
+func TestSignTweak(t *testing.T) {
+	T, N := party.Size(2), party.Size(5)
+	partyIDs, _, secretShares, publicShares := setupParties(T, N)
+	signers := party.IDSlice{partyIDs[1], partyIDs[3], partyIDs[4]}
+
+	signWith := func(tweak *ristretto.Scalar) *sign.Output {
+		opts := []sign.Option{sign.WithTweak(tweak)}
+		states := map[party.ID]*state.State{}
+		outputs := map[party.ID]*sign.Output{}
+		for _, id := range signers {
+			var err error
+			if states[id], outputs[id], err = frost.NewSignStateWithOptions(signers, secretShares[id], publicShares, MESSAGE, 0, opts); err != nil {
+				t.Fatal(err)
+			}
+		}
+		runRounds(t, signers, states, 3)
+		for _, id := range signers {
+			if !outputs[id].GroupKey.Equal(outputs[signers[0]].GroupKey) {
+				t.Errorf("party %d reports a different group key", id)
+			}
+		}
+		return outputs[signers[0]]
+	}
+
+	var tweakBytes [64]byte
+	if _, err := rand.Read(tweakBytes[:]); err != nil {
+		t.Fatal(err)
+	}
+	tweak, _ := ristretto.NewScalar().SetUniformBytes(tweakBytes[:])
+	output := signWith(tweak)
+	tweaked := publicShares.GroupKey.Tweak(tweak)
+	if !output.GroupKey.Equal(tweaked) {
+		t.Error("Output.GroupKey is not the tweaked key")
+	}
+	if !ed25519.Verify(tweaked.ToEd25519(), MESSAGE, output.Signature.ToEd25519()) {
+		t.Error("the signature is invalid for the tweaked key")
+	}
+	if ed25519.Verify(publicShares.GroupKeyEd25519(), MESSAGE, output.Signature.ToEd25519()) {
+		t.Error("the signature should not be valid for the original key")
+	}
+
+	output = signWith(ristretto.NewScalar())
+	if !output.GroupKey.Equal(publicShares.GroupKey) {
+		t.Error("a zero tweak should not change the group key")
+	}
+	if !ed25519.Verify(publicShares.GroupKeyEd25519(), MESSAGE, output.Signature.ToEd25519()) {
+		t.Error("the signature with a zero tweak is invalid for the original key")
+	}
+}
+
+func TestSignKeyShare(t *testing.T) {
+	partyIDs := helpers.GenerateSet(4)
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*keygen.Output{}
+	for _, id := range partyIDs {
+		var err error
+		if states[id], outputs[id], err = frost.NewKeygenState(id, partyIDs, 2, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := outputs[partyIDs[0]].KeyShare(); err == nil {
+		t.Error("KeyShare() should fail before the keygen has finished")
+	}
+	if err := runKeygen(t, partyIDs, states, outputs, 0, -1); err != nil {
+		t.Fatal(err)
+	}
+
+	// The key shares are stored and loaded again before signing
+	keyShares := map[party.ID]*eddsa.KeyShare{}
+	for _, id := range partyIDs {
+		keyShare, err := outputs[id].KeyShare()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := keyShare.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyShares[id] = new(eddsa.KeyShare)
+		if err = keyShares[id].UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	signers := party.IDSlice{partyIDs[0], partyIDs[1], partyIDs[3]}
+	signStates := map[party.ID]*state.State{}
+	signOutputs := map[party.ID]*sign.Output{}
+	for _, id := range signers {
+		var err error
+		if signStates[id], signOutputs[id], err = frost.NewSignStateFromKeyShare(signers, keyShares[id], MESSAGE, 0, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runRounds(t, signers, signStates, 3)
+
+	groupKey := keyShares[signers[0]].GroupKey()
+	if !groupKey.Verify(MESSAGE, signOutputs[signers[0]].Signature) {
+		t.Error("the signature is invalid")
 	}
 }
@@ -0,0 +1,35 @@
+//go:build go1.20
+
+package main
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost"
+)
+
+func TestSignerOptions(t *testing.T) {
+	_, signers, secrets, public := setupParties(1, 3)
+	signer := frost.NewSigner(public.GroupKey, localSignFunc(signers, secrets, public, -1, nil))
+
+	opts := &ed25519.Options{Context: "frost-ed25519 signer"}
+	sig, err := signer.Sign(rand.Reader, MESSAGE, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ed25519.VerifyWithOptions(public.GroupKeyEd25519(), MESSAGE, sig, opts); err != nil {
+		t.Errorf("the Ed25519ctx signature is invalid: %v", err)
+	}
+	if ed25519.Verify(public.GroupKeyEd25519(), MESSAGE, sig) {
+		t.Error("the Ed25519ctx signature should not be a valid Ed25519 signature")
+	}
+
+	_, err = signer.Sign(rand.Reader, make([]byte, 64), &ed25519.Options{Hash: crypto.SHA512, Context: "ph"})
+	if !errors.Is(err, frost.ErrUnsupportedSignerOpts) {
+		t.Errorf("Sign() for Ed25519ph with a context: error = %v, want ErrUnsupportedSignerOpts", err)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/eddsa"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/sign"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// localSignFunc returns a SignFunc running a session between all signers in this process.
+// The messages of the rounds in skip are not delivered, and tamper is applied to every message before its delivery.
+func localSignFunc(signers party.IDSlice, secrets map[party.ID]*eddsa.SecretShare, public *eddsa.Public, skip int, tamper func(msg *messages.Message)) frost.SignFunc {
+	return func(ctx context.Context, message []byte, opts []sign.Option) (*eddsa.Signature, error) {
+		states := map[party.ID]*state.State{}
+		outputs := map[party.ID]*sign.Output{}
+		for _, id := range signers {
+			var err error
+			if states[id], outputs[id], err = frost.NewSignStateWithOptions(signers, secrets[id], public, message, 0, opts); err != nil {
+				return nil, err
+			}
+		}
+		go func() {
+			var in [][]byte
+			for round := 0; round < 3 && round != skip; round++ {
+				var out [][]byte
+				for _, id := range signers {
+					for _, data := range in {
+						var msg messages.Message
+						if err := msg.UnmarshalBinary(data); err == nil {
+							_ = states[id].HandleMessage(&msg)
+						}
+					}
+					for _, msg := range states[id].ProcessAll() {
+						if tamper != nil {
+							tamper(msg)
+						}
+						if data, err := msg.MarshalBinary(); err == nil {
+							out = append(out, data)
+						}
+					}
+				}
+				in = out
+			}
+		}()
+		self := signers[0]
+		if err := states[self].WaitForErrorContext(ctx); err != nil {
+			return nil, err
+		}
+		return outputs[self].Signature.Copy(), nil
+	}
+}
+
+func TestSignerCertificate(t *testing.T) {
+	_, _, secrets, public := setupParties(2, 4)
+	signers := party.IDSlice{public.PartyIDs[0], public.PartyIDs[2], public.PartyIDs[3]}
+	signer := frost.NewSigner(public.GroupKey, localSignFunc(signers, secrets, public, -1, nil))
+
+	var _ crypto.Signer = signer
+	if !public.GroupKeyEd25519().Equal(signer.Public()) {
+		t.Fatal("Public() should return the group key")
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "frost-ed25519"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, signer.Public(), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.SignatureAlgorithm != x509.PureEd25519 {
+		t.Errorf("SignatureAlgorithm = %v, want Ed25519", cert.SignatureAlgorithm)
+	}
+	if err = cert.CheckSignatureFrom(cert); err != nil {
+		t.Errorf("the self-signed certificate is invalid: %v", err)
+	}
+	if !public.GroupKeyEd25519().Equal(cert.PublicKey) {
+		t.Error("the certificate should contain the group key")
+	}
+
+	// Ed25519ph
+	digest := sha512.Sum512(MESSAGE)
+	sig, err := signer.Sign(rand.Reader, digest[:], crypto.SHA512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded eddsa.Signature
+	if err = decoded.Scan(sig); err != nil || !public.GroupKey.VerifyPrehashed(digest[:], &decoded) {
+		t.Error("the Ed25519ph signature is invalid")
+	}
+	if _, err = signer.Sign(rand.Reader, MESSAGE, crypto.SHA512); err == nil {
+		t.Error("Ed25519ph requires a digest")
+	}
+	if _, err = signer.Sign(rand.Reader, MESSAGE, crypto.SHA256); !errors.Is(err, frost.ErrUnsupportedSignerOpts) {
+		t.Errorf("Sign() with SHA-256: error = %v, want ErrUnsupportedSignerOpts", err)
+	}
+	sig, err = signer.Sign(rand.Reader, MESSAGE, nil)
+	if err != nil || !ed25519.Verify(public.GroupKeyEd25519(), MESSAGE, sig) {
+		t.Errorf("Sign() without options: %v", err)
+	}
+}
+
+func TestSignerContext(t *testing.T) {
+	_, signers, secrets, public := setupParties(1, 3)
+
+	// The second round is never delivered
+	signer := frost.NewSigner(public.GroupKey, localSignFunc(signers, secrets, public, 1, nil))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := signer.WithContext(ctx).Sign(rand.Reader, MESSAGE, crypto.Hash(0))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Sign() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	// A canceled context does not start a session
+	_, err = signer.WithContext(ctx).Sign(rand.Reader, MESSAGE, crypto.Hash(0))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Sign() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestSignerAbort(t *testing.T) {
+	_, signers, secrets, public := setupParties(2, 3)
+	culprit := signers[1]
+	tamper := func(msg *messages.Message) {
+		if msg.Type == messages.MessageTypeSign2 && msg.From == culprit {
+			msg.Sign2.Zi.Add(&msg.Sign2.Zi, &msg.Sign2.Zi)
+		}
+	}
+	signer := frost.NewSigner(public.GroupKey, localSignFunc(signers, secrets, public, -1, tamper))
+	_, err := signer.Sign(rand.Reader, MESSAGE, crypto.Hash(0))
+	var stateErr *state.Error
+	if !errors.As(err, &stateErr) {
+		t.Fatalf("Sign() error = %v, want a *state.Error", err)
+	}
+	if stateErr.Culprit() != culprit || stateErr.Kind() != state.KindInvalidSignatureShare {
+		t.Errorf("Sign() error = %v, want an invalid signature share from party %d", err, culprit)
+	}
+}
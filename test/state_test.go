@@ -0,0 +1,720 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/taurusgroup/frost-ed25519/pkg/frost"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/keygen"
+	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
+	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+	"github.com/taurusgroup/frost-ed25519/pkg/messages"
+	"github.com/taurusgroup/frost-ed25519/pkg/ristretto"
+	"github.com/taurusgroup/frost-ed25519/pkg/state"
+)
+
+// keygenRound1Message returns the first message sent by party id in a keygen between partyIDs.
+func keygenRound1Message(t *testing.T, id party.ID, partyIDs party.IDSlice) *messages.Message {
+	s, _, err := frost.NewKeygenState(id, partyIDs, partyIDs.N()-1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.ProcessAll()[0].MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg messages.Message
+	if err = msg.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	return &msg
+}
+
+func TestHandleMessageErrors(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+
+	s, _, err := frost.NewKeygenState(1, partyIDs, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.ProcessAll()
+
+	msg := keygenRound1Message(t, 2, partyIDs)
+	if err = s.HandleMessage(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := msg.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var retransmitted messages.Message
+	if err = retransmitted.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.HandleMessage(&retransmitted); err != nil {
+		t.Errorf("HandleMessage() error = %v, want a retransmission to be ignored", err)
+	}
+
+	tests := []struct {
+		name string
+		msg  *messages.Message
+		want error
+	}{
+		{
+			"equivocation",
+			keygenRound1Message(t, 2, partyIDs),
+			state.ErrEquivocation,
+		},
+		{
+			"unexpected sender",
+			keygenRound1Message(t, 4, party.IDSlice{1, 2, 4}),
+			state.ErrUnexpectedSender,
+		},
+		{
+			"wrong message type",
+			messages.NewSign1(3, ristretto.NewIdentityElement(), ristretto.NewIdentityElement()),
+			state.ErrWrongMessageType,
+		},
+		{
+			"wrong recipient",
+			messages.NewKeyGen2(3, 2, ristretto.NewScalar()),
+			state.ErrWrongRecipient,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.HandleMessage(tt.msg)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("HandleMessage() error = %v, want %v", err, tt.want)
+			}
+			if duplicate := errors.Is(err, state.ErrDuplicateMessage); duplicate != (tt.want == state.ErrEquivocation) {
+				t.Errorf("errors.Is(%v, ErrDuplicateMessage) = %t", err, duplicate)
+			}
+			var msgErr *state.MessageError
+			if !errors.As(err, &msgErr) {
+				t.Fatalf("HandleMessage() error = %v, want a *state.MessageError", err)
+			}
+			if msgErr.From != tt.msg.From {
+				t.Errorf("MessageError.From = %d, want %d", msgErr.From, tt.msg.From)
+			}
+		})
+	}
+
+	if s.IsFinished() {
+		t.Error("rejected messages should not abort the protocol")
+	}
+	if missing, _ := s.MissingFrom(); !missing.Equal(party.IDSlice{3}) {
+		t.Errorf("MissingFrom() = %v, want [3]", missing)
+	}
+}
+
+// blockingRound is a single round protocol, whose GenerateMessages blocks until release is closed.
+type blockingRound struct {
+	*state.BaseRound
+	release chan struct{}
+}
+
+func (r *blockingRound) GenerateMessages() ([]*messages.Message, *state.Error) {
+	<-r.release
+	return nil, nil
+}
+
+func (r *blockingRound) NextRound() state.Round { return nil }
+
+func (r *blockingRound) AcceptedMessageTypes() []messages.MessageType {
+	return []messages.MessageType{messages.MessageTypeNone}
+}
+
+func (r *blockingRound) Reset() {}
+
+func TestRoundState(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+
+	expectState := func(s *state.State, roundNumber int, rs state.RoundState) {
+		t.Helper()
+		if s.RoundNumber() != roundNumber {
+			t.Errorf("RoundNumber() = %d, want %d", s.RoundNumber(), roundNumber)
+		}
+		if s.RoundState() != rs {
+			t.Errorf("RoundState() = %v, want %v", s.RoundState(), rs)
+		}
+	}
+
+	states := map[party.ID]*state.State{}
+	for _, id := range partyIDs {
+		var err error
+		if states[id], _, err = frost.NewKeygenState(id, partyIDs, 2, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := states[1]
+
+	// The first round does not wait for messages
+	expectState(s, 0, state.RoundStateReadyToProcess)
+
+	var in []*messages.Message
+	for _, other := range states {
+		in = append(in, other.ProcessAll()...)
+	}
+	for round := 1; round <= 2; round++ {
+		expectState(s, round, state.RoundStateWaitingForMessages)
+		var out []*messages.Message
+		for id, other := range states {
+			for _, msg := range in {
+				if msg.From == id || !msg.IsBroadcast() && msg.To != id {
+					continue
+				}
+				var msgCopy messages.Message
+				if err := msgCopy.UnmarshalBinary(marshal(t, msg)); err != nil {
+					t.Fatal(err)
+				}
+				if err := other.HandleMessage(&msgCopy); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		expectState(s, round, state.RoundStateReadyToProcess)
+		for _, other := range states {
+			out = append(out, other.ProcessAll()...)
+		}
+		in = out
+	}
+	expectState(s, 2, state.RoundStateFinished)
+	if !s.IsFinished() || s.IsAborted() {
+		t.Errorf("IsFinished() = %t, IsAborted() = %t, want true, false", s.IsFinished(), s.IsAborted())
+	}
+
+	// Processing
+	baseRound, err := state.NewBaseRound(1, partyIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &blockingRound{BaseRound: baseRound, release: make(chan struct{})}
+	if s, err = state.NewBaseState(r, 0); err != nil {
+		t.Fatal(err)
+	}
+	go s.ProcessAll()
+	for s.RoundState() != state.RoundStateProcessing {
+		time.Sleep(time.Millisecond)
+	}
+	close(r.release)
+	<-s.Done()
+	expectState(s, 0, state.RoundStateFinished)
+
+	// Aborted
+	if s, _, err = frost.NewKeygenState(1, partyIDs, 2, 0); err != nil {
+		t.Fatal(err)
+	}
+	s.ProcessAll()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = s.WaitForErrorContext(ctx)
+	expectState(s, 1, state.RoundStateAborted)
+	if !s.IsFinished() || !s.IsAborted() {
+		t.Errorf("IsFinished() = %t, IsAborted() = %t, want true, true", s.IsFinished(), s.IsAborted())
+	}
+}
+
+func TestRoundState_String(t *testing.T) {
+	names := map[state.RoundState]string{
+		state.RoundStateWaitingForMessages: "WaitingForMessages",
+		state.RoundStateReadyToProcess:     "ReadyToProcess",
+		state.RoundStateProcessing:         "Processing",
+		state.RoundStateFinished:           "Finished",
+		state.RoundStateAborted:            "Aborted",
+		state.RoundState(42):               "RoundState(42)",
+	}
+	for rs, name := range names {
+		if rs.String() != name {
+			t.Errorf("String() = %s, want %s", rs.String(), name)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+
+	var recorder state.MetricsRecorder
+	states := map[party.ID]*state.State{}
+	for _, id := range partyIDs {
+		var opts []state.Option
+		if id == 1 {
+			opts = append(opts, state.WithMetrics(&recorder))
+		}
+		var err error
+		if states[id], _, err = frost.NewKeygenState(id, partyIDs, 2, 0, opts...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var in []*messages.Message
+	for _, id := range partyIDs {
+		in = append(in, states[id].ProcessAll()...)
+	}
+	for round := 1; round <= 2; round++ {
+		var out []*messages.Message
+		for _, id := range partyIDs {
+			for _, msg := range in {
+				if msg.From == id || !msg.IsBroadcast() && msg.To != id {
+					continue
+				}
+				var msgCopy messages.Message
+				if err := msgCopy.UnmarshalBinary(marshal(t, msg)); err != nil {
+					t.Fatal(err)
+				}
+				if err := states[id].HandleMessage(&msgCopy); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		// A retransmission is ignored, whereas a message of the wrong type is rejected
+		if round == 1 {
+			_ = states[1].HandleMessage(in[1])
+			_ = states[1].HandleMessage(messages.NewSign1(2, ristretto.NewIdentityElement(), ristretto.NewIdentityElement()))
+		}
+		for _, id := range partyIDs {
+			out = append(out, states[id].ProcessAll()...)
+		}
+		in = out
+	}
+	if err := states[1].WaitForError(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"RoundProcessingStarted(0)",
+		"RoundProcessingFinished(0, <nil>)",
+		"MessageStored(1, 2, 1)",
+		"MessageStored(1, 3, 1)",
+		"MessageRejected(1, 2, " + state.ErrWrongMessageType.Error() + ")",
+		"RoundProcessingStarted(1)",
+		"RoundProcessingFinished(1, <nil>)",
+		"MessageStored(2, 2, 2)",
+		"MessageStored(2, 3, 2)",
+		"RoundProcessingStarted(2)",
+		"RoundProcessingFinished(2, <nil>)",
+		"ProtocolFinished",
+	}
+	if calls := recorder.Calls(); !reflect.DeepEqual(calls, want) {
+		t.Errorf("Calls() = %q, want %q", calls, want)
+	}
+
+	// An abort is recorded with its culprits
+	var aborted state.MetricsRecorder
+	s, _, err := frost.NewKeygenState(1, partyIDs, 2, 0, state.WithMetrics(&aborted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.ProcessAll()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = s.WaitForErrorContext(ctx)
+	want = []string{
+		"RoundProcessingStarted(0)",
+		"RoundProcessingFinished(0, <nil>)",
+		"ProtocolAborted([])",
+	}
+	if calls := aborted.Calls(); !reflect.DeepEqual(calls, want) {
+		t.Errorf("Calls() = %q, want %q", calls, want)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+
+	states := map[party.ID]*state.State{}
+	for _, id := range partyIDs {
+		var err error
+		if states[id], _, err = frost.NewKeygenState(id, partyIDs, 2, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := states[1]
+	start := time.Now()
+
+	round1 := map[party.ID]*messages.Message{}
+	for _, id := range partyIDs {
+		round1[id] = states[id].ProcessAll()[0]
+	}
+	deliver := func(to party.ID, msg *messages.Message) {
+		t.Helper()
+		var msgCopy messages.Message
+		if err := msgCopy.UnmarshalBinary(marshal(t, msg)); err != nil {
+			t.Fatal(err)
+		}
+		if err := states[to].HandleMessage(&msgCopy); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := s.Diagnostics()
+	if d.RoundNumber != 1 || d.RoundState != state.RoundStateWaitingForMessages || !d.Missing.Equal((party.IDSlice{2, 3})) {
+		t.Errorf("Diagnostics() = %+v, want round 1 waiting for [2 3]", d)
+	}
+	if d.LastTransition.Before(start) {
+		t.Errorf("LastTransition = %v, want after %v", d.LastTransition, start)
+	}
+
+	// Parties 2 and 3 move on to the next round, but party 1 does not receive the first message of party 3.
+	others := party.IDSlice{2, 3}
+	deliver(1, round1[2])
+	for _, from := range partyIDs {
+		for _, to := range others {
+			if from != to {
+				deliver(to, round1[from])
+			}
+		}
+	}
+	for _, id := range others {
+		for _, msg := range states[id].ProcessAll() {
+			if msg.To == 1 {
+				deliver(1, msg)
+			}
+		}
+	}
+
+	d = s.Diagnostics()
+	if d.RoundNumber != 1 || d.RoundState != state.RoundStateWaitingForMessages || !d.Missing.Equal((party.IDSlice{3})) {
+		t.Errorf("Diagnostics() = %+v, want round 1 waiting for [3]", d)
+	}
+	if d.Queued != 2 {
+		t.Errorf("Queued = %d, want 2", d.Queued)
+	}
+	if d.PendingOutgoing != 0 {
+		t.Errorf("PendingOutgoing = %d, want 0", d.PendingOutgoing)
+	}
+
+	dir, err := party.NewDirectory(nil, map[string]party.ID{"alice": 1, "carol": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := d.Describe(dir), "round 1: WaitingForMessages: missing [carol (3)], 2 queued, 0 pending outgoing"; got != want {
+		t.Errorf("Describe() = %q, want %q", got, want)
+	}
+	if got, want := d.Describe(nil), "round 1: WaitingForMessages: missing [3], 2 queued, 0 pending outgoing"; got != want {
+		t.Errorf("Describe(nil) = %q, want %q", got, want)
+	}
+}
+
+func TestMessageLimits(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	s, _, err := frost.NewKeygenState(1, partyIDs, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.ProcessAll()
+
+	// The message of a party using a different threshold commits to a polynomial of another degree
+	msg := keygenRound1Message(t, 2, partyIDs)
+	err = s.HandleMessage(msg)
+	var fieldErr *messages.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "KeyGen1.Commitments" {
+		t.Fatalf("expected a FieldError for the commitments, got %v", err)
+	}
+	if !errors.Is(err, messages.ErrInvalidMessage) {
+		t.Errorf("expected ErrInvalidMessage, got %v", err)
+	}
+	if s.RoundState() != state.RoundStateWaitingForMessages {
+		t.Errorf("the protocol should continue, got %s", s.RoundState())
+	}
+
+	// Messages of the right size are accepted, and fit in MaxMessageSize
+	other, _, err := frost.NewKeygenState(2, partyIDs, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := other.ProcessAll()[0].MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != s.MaxMessageSize() {
+		t.Errorf("KeyGen1 message is %d bytes, expected MaxMessageSize %d", len(data), s.MaxMessageSize())
+	}
+	var msgCopy messages.Message
+	if err = s.MessageLimits().UnmarshalBinary(data, &msgCopy); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.HandleMessage(&msgCopy); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.MessageLimits().UnmarshalBinary(append(data, 0), &msgCopy); !errors.Is(err, messages.ErrInvalidMessage) {
+		t.Errorf("expected ErrInvalidMessage for a message larger than MaxMessageSize, got %v", err)
+	}
+}
+
+type failingStore struct{}
+
+var errStoreFull = errors.New("store is full")
+
+func (failingStore) Append(int, party.ID, []byte) error { return errStoreFull }
+func (failingStore) Load() ([]state.Record, error)      { return nil, nil }
+
+func TestMessageStoreFailure(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2}
+	s, _, err := frost.NewKeygenState(1, partyIDs, 1, 0, state.WithMessageStore(failingStore{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.ProcessAll()
+
+	// Messages which could not be recorded are not acted upon
+	if err = s.HandleMessage(keygenRound1Message(t, 2, partyIDs)); !errors.Is(err, errStoreFull) {
+		t.Fatalf("expected the error of the store, got %v", err)
+	}
+	if s.RoundState() != state.RoundStateWaitingForMessages {
+		t.Errorf("the message should not be stored, got %s", s.RoundState())
+	}
+	if err = s.ReplayMessages(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	s, _, err := frost.NewKeygenState(1, partyIDs, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.ProcessAll()
+
+	// Notifications are merged when they are not received, so HandleMessage never blocks
+	progress := s.Notify()
+	for _, id := range partyIDs[1:] {
+		if err = s.HandleMessage(keygenRound1Message(t, id, partyIDs)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(progress) != 1 {
+		t.Fatalf("expected a single pending notification, got %d", len(progress))
+	}
+	if s.Notify() != progress {
+		t.Error("Notify should return the same channel during a round")
+	}
+
+	s.ProcessAll()
+	<-progress
+	if _, ok := <-progress; ok {
+		t.Error("the channel should be closed after the round was processed")
+	}
+
+	next := s.Notify()
+	if len(next) != 0 {
+		t.Error("no message was received for the next round")
+	}
+	s.Cancel(nil)
+	if _, ok := <-next; ok {
+		t.Error("the channel should be closed when the protocol finishes")
+	}
+	if _, ok := <-s.Notify(); ok {
+		t.Error("the channel should be closed when the protocol is finished")
+	}
+}
+
+// runRounds delivers the messages of each round to all states, until the protocol has finished.
+func runRounds(t *testing.T, partyIDs party.IDSlice, states map[party.ID]*state.State, rounds int) {
+	var msgs [][]byte
+	for round := 0; round < rounds; round++ {
+		var out [][]byte
+		for _, id := range partyIDs {
+			msgsOut, err := helpers.PartyRoutine(msgs, states[id])
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, msgsOut...)
+		}
+		msgs = out
+	}
+	for _, id := range partyIDs {
+		if err := states[id].WaitForError(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// checkTranscripts checks that all transcripts have the same Sum, which survives Export.
+func checkTranscripts(t *testing.T, transcripts map[party.ID]*state.Transcript) [32]byte {
+	var sum [32]byte
+	first := true
+	for id, transcript := range transcripts {
+		s := transcript.Sum()
+		if first {
+			sum, first = s, false
+		} else if s != sum {
+			t.Errorf("party %d computed a different transcript sum", id)
+		}
+
+		imported, err := state.ImportTranscript(transcript.Export())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if imported.Sum() != s {
+			t.Errorf("party %d: the sum of the exported transcript differs", id)
+		}
+		if !reflect.DeepEqual(imported.Entries(), transcript.Entries()) {
+			t.Errorf("party %d: the exported entries differ", id)
+		}
+	}
+	return sum
+}
+
+func TestTranscript(t *testing.T) {
+	partyIDs := helpers.GenerateSet(5)
+	T := party.Size(2)
+
+	states := map[party.ID]*state.State{}
+	outputs := map[party.ID]*keygen.Output{}
+	transcripts := map[party.ID]*state.Transcript{}
+	for _, id := range partyIDs {
+		var err error
+		transcripts[id] = state.NewTranscript()
+		states[id], outputs[id], err = frost.NewKeygenState(id, partyIDs, T, 0, state.WithTranscript(transcripts[id]))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	runRounds(t, partyIDs, states, 3)
+	keygenSum := checkTranscripts(t, transcripts)
+
+	// Each party records the 5 KeyGen1 messages, and the 4 shares it sent and the 4 it received
+	for id, transcript := range transcripts {
+		entries := transcript.Entries()
+		if len(entries) != 13 {
+			t.Errorf("party %d recorded %d messages, expected 13", id, len(entries))
+		}
+		for _, entry := range entries {
+			if entry.To != 0 && entry.From != id && entry.To != id {
+				t.Errorf("party %d recorded a share from %d to %d", id, entry.From, entry.To)
+			}
+		}
+	}
+
+	signIDs := partyIDs[:T+1]
+	signStates := map[party.ID]*state.State{}
+	signTranscripts := map[party.ID]*state.Transcript{}
+	for _, id := range signIDs {
+		var err error
+		signTranscripts[id] = state.NewTranscript()
+		signStates[id], _, err = frost.NewSignState(signIDs, outputs[id].SecretKey, outputs[id].Public, []byte("transcript"), 0,
+			state.WithTranscript(signTranscripts[id]))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	runRounds(t, signIDs, signStates, 3)
+	if checkTranscripts(t, signTranscripts) == keygenSum {
+		t.Error("keygen and sign transcripts have the same sum")
+	}
+}
+
+func TestExpire(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	s, _, err := frost.NewKeygenState(1, partyIDs, 2, 0, state.WithTTL(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.ProcessAll()
+
+	// A share is queued for the next round
+	share := messages.NewKeyGen2(2, 1, party.ID(42).Scalar())
+	share.SessionID = s.SessionID()
+	if err = s.HandleMessage(share); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Expire(time.Now()) {
+		t.Fatal("session expired before its TTL")
+	}
+	if !s.Expire(time.Now().Add(2 * time.Minute)) {
+		t.Fatal("session did not expire after its TTL")
+	}
+
+	var protocolErr *state.Error
+	if err = s.WaitForError(); !errors.As(err, &protocolErr) || !errors.Is(err, state.ErrSessionExpired) {
+		t.Fatalf("expected ErrSessionExpired, got %v", err)
+	}
+	if protocolErr.Kind() != state.KindExpired {
+		t.Errorf("expected kind %v, got %v", state.KindExpired, protocolErr.Kind())
+	}
+	if share.KeyGen2.Share.Equal(ristretto.NewScalar()) != 1 {
+		t.Error("the stored share was not zeroed")
+	}
+	if s.Expire(time.Now().Add(time.Hour)) {
+		t.Error("session expired twice")
+	}
+
+	// A session without TTL never expires
+	s, _, err = frost.NewKeygenState(1, partyIDs, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Expire(time.Now().Add(time.Hour)) {
+		t.Error("session without TTL expired")
+	}
+}
+
+func TestJanitor(t *testing.T) {
+	partyIDs := party.IDSlice{1, 2, 3}
+	janitor := state.NewJanitor()
+
+	states := make([]*state.State, 0, len(partyIDs))
+	for _, id := range partyIDs {
+		s, _, err := frost.NewKeygenState(id, partyIDs, 2, 0, state.WithTTL(time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		janitor.Add(s)
+		states = append(states, s)
+	}
+	// A session which finished for another reason is only removed
+	states[0].Cancel(nil)
+
+	// Messages are delivered while the sessions are swept
+	msg := keygenRound1Message(t, 2, partyIDs)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, s := range states {
+			_ = s.HandleMessage(msg)
+		}
+	}()
+	if expired := janitor.Sweep(time.Now()); len(expired) != 0 {
+		t.Errorf("%d sessions expired before their TTL", len(expired))
+	}
+	<-done
+	if janitor.Len() != 2 {
+		t.Errorf("expected 2 sessions, got %d", janitor.Len())
+	}
+
+	if expired := janitor.Sweep(time.Now().Add(2 * time.Minute)); len(expired) != 2 {
+		t.Errorf("expected 2 expired sessions, got %d", len(expired))
+	}
+	if janitor.Len() != 0 {
+		t.Errorf("expected no sessions, got %d", janitor.Len())
+	}
+}
+
+func TestImportTranscriptShortIDs(t *testing.T) {
+	// version 1 ∥ len ∥ direction ∥ round ∥ from (2 bytes) ∥ to (2 bytes) ∥ data
+	data := []byte{1, 0, 0, 0, 10, 2, 0, 1, 0, 3, 0, 4, 0xaa, 0xbb, 0xcc}
+	transcript, err := state.ImportTranscript(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []state.TranscriptEntry{{Direction: state.DirectionReceived, Round: 1, From: 3, To: 4, Data: []byte{0xaa, 0xbb, 0xcc}}}
+	if !reflect.DeepEqual(transcript.Entries(), want) {
+		t.Errorf("ImportTranscript() = %v, want %v", transcript.Entries(), want)
+	}
+
+	// Once exported again, the IDs are 4 bytes long
+	reimported, err := state.ImportTranscript(transcript.Export())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reimported.Entries(), want) || len(transcript.Export()) != len(data)+4 {
+		t.Error("the transcript was not exported with 4 bytes IDs")
+	}
+}